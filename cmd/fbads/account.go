@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// handleAccount processes account subcommands
+func handleAccount(cfg *config.Config, subCmd string) {
+	switch subCmd {
+	case "status":
+		accountStatus(cfg)
+	default:
+		fmt.Printf("Unknown account subcommand: %s\n", subCmd)
+		fmt.Println("Available subcommands: status")
+		os.Exit(1)
+	}
+}
+
+// accountStatus prints the account object counts against the configured limits
+func accountStatus(cfg *config.Config) {
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create API client
+	client := api.NewClient(authClient, cfg.AccountID)
+
+	counts, err := client.GetObjectCounts()
+	if err != nil {
+		fmt.Printf("Error counting account objects: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Ad account: act_%s\n\n", cfg.AccountID)
+	renderAccountStatus(os.Stdout, *counts, cfg.Limits)
+}
+
+// renderAccountStatus writes the object counts, limits and remaining headroom
+func renderAccountStatus(w io.Writer, counts api.ObjectCounts, limits config.AccountLimits) {
+	fmt.Fprintf(w, "%-10s | %-8s | %-8s | %s\n", "OBJECT", "COUNT", "LIMIT", "AVAILABLE")
+	fmt.Fprintln(w, "-----------+----------+----------+----------")
+
+	rows := []struct {
+		name  string
+		count int
+		limit int
+	}{
+		{"Campaigns", counts.Campaigns, limits.MaxCampaigns},
+		{"Ad sets", counts.AdSets, limits.MaxAdSets},
+	}
+
+	for _, row := range rows {
+		if row.limit <= 0 {
+			fmt.Fprintf(w, "%-10s | %-8d | %-8s | %s\n", row.name, row.count, "none", "-")
+			continue
+		}
+
+		available := row.limit - row.count
+		if available < 0 {
+			available = 0
+		}
+		fmt.Fprintf(w, "%-10s | %-8d | %-8d | %d (%.0f%% used)\n",
+			row.name, row.count, row.limit, available, float64(row.count)/float64(row.limit)*100)
+	}
+}
+
+// plannedObjects counts the campaigns and ad sets a set of configurations will create
+func plannedObjects(configs []*models.CampaignConfig) api.ObjectCounts {
+	planned := api.ObjectCounts{Campaigns: len(configs)}
+	for _, campaignConfig := range configs {
+		planned.AdSets += len(campaignConfig.AdSets)
+	}
+	return planned
+}
+
+// ensureAccountCapacity stops the command when the planned objects would exceed the account limits
+func ensureAccountCapacity(client *api.Client, cfg *config.Config, planned api.ObjectCounts) {
+	err := client.EnsureCapacity(planned, cfg.Limits)
+
+	var limitErr *api.AccountLimitError
+	switch {
+	case errors.As(err, &limitErr):
+		fmt.Printf("\nRefusing to continue: %v\n", err)
+		fmt.Println("Check the current counts with: fbads account status")
+		os.Exit(1)
+	case err != nil:
+		fmt.Printf("Error checking account limits: %v\n", err)
+		os.Exit(1)
+	}
+}
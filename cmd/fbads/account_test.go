@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestPlannedObjects(t *testing.T) {
+	configs := []*models.CampaignConfig{
+		{Name: "A", AdSets: []models.AdSetConfig{{Name: "A1"}, {Name: "A2"}}},
+		{Name: "B", AdSets: []models.AdSetConfig{{Name: "B1"}}},
+	}
+
+	planned := plannedObjects(configs)
+	if planned.Campaigns != 2 || planned.AdSets != 3 {
+		t.Errorf("Expected 2 campaigns and 3 ad sets, got %+v", planned)
+	}
+}
+
+func TestRenderAccountStatus(t *testing.T) {
+	var out bytes.Buffer
+	renderAccountStatus(&out, api.ObjectCounts{Campaigns: 4500, AdSets: 10200}, config.DefaultAccountLimits())
+
+	output := out.String()
+	if !strings.Contains(output, "Campaigns  | 4500     | 5000     | 500 (90% used)") {
+		t.Errorf("Expected campaign headroom in output, got:\n%s", output)
+	}
+	// Counts above the limit show no headroom
+	if !strings.Contains(output, "Ad sets    | 10200    | 10000    | 0 (102% used)") {
+		t.Errorf("Expected ad sets over the limit in output, got:\n%s", output)
+	}
+}
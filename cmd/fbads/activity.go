@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// activityFeed prints the changes made in the account, optionally limited to one campaign
+func activityFeed(cfg *config.Config, args []string) {
+	since := "7d"
+	campaignID := ""
+
+	// Handle flags
+	fs := newCommandFlags("activity [options]")
+	fs.StringVar(&since, "since", since, "Period to show: a date (YYYY-MM-DD) or days like 7d")
+	fs.StringVar(&campaignID, "campaign", "", "Only show changes to this campaign")
+	parseCommandArgs(fs, args, 0, 0)
+
+	until := time.Now()
+	startDate, err := parseSinceFlag(since, until)
+	if err != nil {
+		fmt.Printf("Invalid --since value: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create API client
+	client := api.NewClient(authClient, cfg.AccountID)
+
+	activities, err := client.GetActivitiesContext(cmdContext, startDate, until)
+	if err != nil {
+		fmt.Printf("Error fetching account activity: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Changes to a campaign's ad sets and ads belong to the campaign too
+	if campaignID != "" {
+		details, err := client.GetCampaignDetailsContext(cmdContext, campaignID)
+		if err != nil {
+			fmt.Printf("Error fetching campaign details: %v\n", err)
+			os.Exit(1)
+		}
+		activities = filterCampaignActivities(activities, campaignObjectIDs(details))
+	}
+
+	if len(activities) == 0 {
+		fmt.Printf("No changes found since %s.\n", startDate.Format("2006-01-02"))
+		return
+	}
+
+	renderActivities(os.Stdout, activities)
+}
+
+// campaignObjectIDs returns the IDs of a campaign and its ad sets and ads
+func campaignObjectIDs(details *models.CampaignDetails) map[string]bool {
+	ids := map[string]bool{details.ID: true}
+	for _, adSet := range details.AdSets {
+		ids[adSet.ID] = true
+	}
+	for _, ad := range details.Ads {
+		ids[ad.ID] = true
+	}
+	return ids
+}
+
+// filterCampaignActivities keeps the activities on the given objects
+func filterCampaignActivities(activities []api.Activity, ids map[string]bool) []api.Activity {
+	var filtered []api.Activity
+	for _, activity := range activities {
+		if ids[activity.ObjectID] {
+			filtered = append(filtered, activity)
+		}
+	}
+	return filtered
+}
+
+// renderActivities writes a chronological table of changes
+func renderActivities(w io.Writer, activities []api.Activity) {
+	fmt.Fprintf(w, "%-16s | %-8s | %-16s | %-30s | %-28s | %s\n",
+		"TIME", "SOURCE", "ACTOR", "OBJECT", "EVENT", "CHANGE")
+	fmt.Fprintf(w, "%s-+-%s-+-%s-+-%s-+-%s-+-%s\n",
+		strings.Repeat("-", 16),
+		strings.Repeat("-", 8),
+		strings.Repeat("-", 16),
+		strings.Repeat("-", 30),
+		strings.Repeat("-", 28),
+		strings.Repeat("-", 20))
+
+	for _, activity := range activities {
+		change := "-"
+		if activity.OldValue != "" || activity.NewValue != "" {
+			change = fmt.Sprintf("%s -> %s", valueOrDash(activity.OldValue), valueOrDash(activity.NewValue))
+		}
+
+		fmt.Fprintf(w, "%-16s | %-8s | %-16s | %-30s | %-28s | %s\n",
+			activity.Time.Local().Format("2006-01-02 15:04"),
+			activity.Source,
+			truncateString(activity.ActorName, 16),
+			truncateString(activity.ObjectName, 30),
+			truncateString(activity.EventType, 28),
+			change)
+	}
+
+	fmt.Fprintf(w, "\n%d changes\n", len(activities))
+}
+
+// valueOrDash returns the value, or "-" when it is empty
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestFilterCampaignActivities(t *testing.T) {
+	details := &models.CampaignDetails{
+		ID:     "111",
+		AdSets: []models.AdSetDetails{{ID: "222"}},
+		Ads:    []models.AdDetails{{ID: "333"}},
+	}
+	activities := []api.Activity{
+		{ObjectID: "111", EventType: "update_campaign_budget"},
+		{ObjectID: "999", EventType: "update_campaign_budget"},
+		{ObjectID: "222", EventType: "update_ad_set_bid_amount"},
+		{ObjectID: "333", EventType: "create_ad"},
+	}
+
+	filtered := filterCampaignActivities(activities, campaignObjectIDs(details))
+
+	if len(filtered) != 3 {
+		t.Fatalf("Expected 3 activities, got %d", len(filtered))
+	}
+	for _, activity := range filtered {
+		if activity.ObjectID == "999" {
+			t.Errorf("Expected changes to other campaigns to be filtered out")
+		}
+	}
+}
+
+func TestRenderActivities(t *testing.T) {
+	activities := []api.Activity{
+		{
+			Time:       time.Date(2024, 3, 4, 16, 0, 0, 0, time.UTC),
+			EventType:  "update_campaign_budget",
+			ActorName:  "John Doe",
+			ObjectName: "Spring Sale",
+			OldValue:   "5000 USD",
+			NewValue:   "7500 USD",
+			Source:     api.ActivitySourceAccount,
+		},
+		{
+			Time:       time.Date(2024, 3, 5, 9, 30, 0, 0, time.UTC),
+			EventType:  "create_ad",
+			ActorName:  "Jane Smith",
+			ObjectName: "Spring Sale - Ad",
+			Source:     api.ActivitySourceAccount,
+		},
+	}
+
+	var buf bytes.Buffer
+	renderActivities(&buf, activities)
+	output := buf.String()
+
+	for _, s := range []string{"5000 USD -> 7500 USD", "John Doe", "create_ad", "account", "2 changes"} {
+		if !strings.Contains(output, s) {
+			t.Errorf("Expected output to contain %q, got:\n%s", s, output)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/models"
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+// adSetRow is an ad set listed by the adsets command, with its metrics when requested
+type adSetRow struct {
+	models.AdSetDetails
+	Metrics *utils.CampaignPerformance `json:"metrics,omitempty"`
+}
+
+// listAdSets lists the ad sets of a campaign
+func listAdSets(cfg *config.Config, args []string) {
+	format := "table"
+	status := ""
+	since := "30d"
+	withMetrics := false
+
+	// Handle flags
+	fs := newCommandFlags("adsets <campaign_id> [options]")
+	fs.StringVar(&format, "format", format, "Output format: table, json or csv")
+	alias(fs, "f", "format")
+	fs.StringVar(&status, "status", "", "Only list ad sets with this status")
+	alias(fs, "s", "status")
+	fs.StringVar(&since, "since", since, "Period of the metrics: a date (YYYY-MM-DD) or days like 30d")
+	fs.BoolVar(&withMetrics, "with-metrics", false, "Add spend, impressions and CPC")
+	campaignID := parseCommandArgs(fs, args, 1, 1)[0]
+
+	if format != "table" && format != "json" && format != "csv" {
+		fmt.Printf("Unsupported format: %s (use table, json or csv)\n", format)
+		os.Exit(1)
+	}
+	if status != "" {
+		parsed, err := models.ParseCampaignStatus(status)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		status = string(parsed)
+	}
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create API client
+	client := api.NewClient(authClient, cfg.AccountID)
+
+	adSets, err := client.GetAdSetsContext(cmdContext, campaignID)
+	if err != nil {
+		fmt.Printf("Error fetching ad sets: %v\n", err)
+		os.Exit(1)
+	}
+
+	rows := make([]adSetRow, 0, len(adSets))
+	for _, adSet := range adSets {
+		if status == "" || adSet.Status == status {
+			rows = append(rows, adSetRow{AdSetDetails: adSet})
+		}
+	}
+
+	if withMetrics && len(rows) > 0 {
+		metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+		clock := metricsCollector.Clock()
+
+		startDate, err := parseSinceFlag(since, clock.Today())
+		if err != nil {
+			fmt.Printf("Invalid --since value: %v\n", err)
+			os.Exit(1)
+		}
+
+		metrics, err := metricsCollector.CollectAdSetMetricsContext(cmdContext, campaignID, api.TimeRange{
+			Since: startDate.Format("2006-01-02"),
+			Until: clock.Yesterday().Format("2006-01-02"),
+		})
+		if err != nil {
+			fmt.Printf("Error collecting ad set metrics: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Ad sets without delivery get zero metrics
+		for i := range rows {
+			perf := metrics[rows[i].ID]
+			rows[i].Metrics = &perf
+		}
+	}
+
+	switch format {
+	case "json":
+		displayAdSetsJSON(os.Stdout, rows)
+	case "csv":
+		displayAdSetsCSV(os.Stdout, rows, withMetrics)
+	default:
+		displayAdSetsTable(os.Stdout, rows, withMetrics)
+	}
+}
+
+// adSetBudget describes the budget of an ad set, which is in cents
+func adSetBudget(adSet models.AdSetDetails) string {
+	if adSet.DailyBudget > 0 {
+		return fmt.Sprintf("$%.2f/day", adSet.DailyBudget/100)
+	} else if adSet.LifetimeBudget > 0 {
+		return fmt.Sprintf("$%.2f total", adSet.LifetimeBudget/100)
+	}
+	return "campaign"
+}
+
+// displayAdSetsTable writes the ad sets as a table, with spend, impressions and CPC when
+// metrics were collected
+func displayAdSetsTable(w io.Writer, rows []adSetRow, withMetrics bool) {
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "No ad sets found.")
+		return
+	}
+
+	// Calculate column widths
+	idWidth := 10
+	nameWidth := 30
+	statusWidth := 10
+	budgetWidth := 15
+	bidWidth := 10
+
+	for _, row := range rows {
+		if len(row.ID) > idWidth {
+			idWidth = len(row.ID)
+		}
+		if len(row.Name) > nameWidth {
+			nameWidth = len(row.Name)
+		}
+		if len(row.Status) > statusWidth {
+			statusWidth = len(row.Status)
+		}
+	}
+
+	// Print header
+	fmt.Fprintf(w, "%-*s | %-*s | %-*s | %-*s | %-*s",
+		idWidth, "ID",
+		nameWidth, "NAME",
+		statusWidth, "STATUS",
+		budgetWidth, "BUDGET",
+		bidWidth, "BID")
+	if withMetrics {
+		fmt.Fprintf(w, " | %-10s | %-11s | %s", "SPEND", "IMPRESSIONS", "CPC")
+	}
+	fmt.Fprintln(w)
+
+	// Print separator
+	fmt.Fprintf(w, "%s-+-%s-+-%s-+-%s-+-%s",
+		strings.Repeat("-", idWidth),
+		strings.Repeat("-", nameWidth),
+		strings.Repeat("-", statusWidth),
+		strings.Repeat("-", budgetWidth),
+		strings.Repeat("-", bidWidth))
+	if withMetrics {
+		fmt.Fprintf(w, "-+-%s-+-%s-+-%s", strings.Repeat("-", 10), strings.Repeat("-", 11), strings.Repeat("-", 6))
+	}
+	fmt.Fprintln(w)
+
+	// Print rows
+	for _, row := range rows {
+		bid := "auto"
+		if row.BidAmount > 0 {
+			bid = fmt.Sprintf("$%.2f", row.BidAmount/100)
+		}
+
+		fmt.Fprintf(w, "%-*s | %-*s | %-*s | %-*s | %-*s",
+			idWidth, row.ID,
+			nameWidth, truncateString(row.Name, nameWidth),
+			statusWidth, row.Status,
+			budgetWidth, adSetBudget(row.AdSetDetails),
+			bidWidth, bid)
+		if withMetrics && row.Metrics != nil {
+			fmt.Fprintf(w, " | %-10s | %-11d | $%.2f",
+				fmt.Sprintf("$%.2f", row.Metrics.Spend), row.Metrics.Impressions, row.Metrics.CPC)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// displayAdSetsJSON writes the ad sets as JSON
+func displayAdSetsJSON(w io.Writer, rows []adSetRow) {
+	response := struct {
+		AdSets []adSetRow `json:"adsets"`
+		Count  int        `json:"count"`
+	}{
+		AdSets: rows,
+		Count:  len(rows),
+	}
+
+	data, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		fmt.Printf("Error encoding to JSON: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintln(w, string(data))
+}
+
+// displayAdSetsCSV writes the ad sets as CSV; budgets and bids are in cents like in the API
+func displayAdSetsCSV(w io.Writer, rows []adSetRow, withMetrics bool) {
+	header := "id,name,status,budget_type,budget,bid_strategy,bid_amount,optimization_goal,billing_event"
+	if withMetrics {
+		header += ",spend,impressions,clicks,cpc"
+	}
+	fmt.Fprintln(w, header)
+
+	for _, row := range rows {
+		budgetType := "none"
+		var budget float64
+		if row.DailyBudget > 0 {
+			budgetType = "daily"
+			budget = row.DailyBudget
+		} else if row.LifetimeBudget > 0 {
+			budgetType = "lifetime"
+			budget = row.LifetimeBudget
+		}
+
+		fmt.Fprintf(w, "%s,%s,%s,%s,%.2f,%s,%.2f,%s,%s",
+			row.ID,
+			escapeCSV(row.Name),
+			row.Status,
+			budgetType,
+			budget,
+			row.BidStrategy,
+			row.BidAmount,
+			row.OptimizationGoal,
+			row.BillingEvent)
+		if withMetrics && row.Metrics != nil {
+			fmt.Fprintf(w, ",%.2f,%d,%d,%.2f", row.Metrics.Spend, row.Metrics.Impressions, row.Metrics.Clicks, row.Metrics.CPC)
+		}
+		fmt.Fprintln(w)
+	}
+}
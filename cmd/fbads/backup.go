@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	internal_campaign "github.com/user/fb-ads/internal/campaign"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// BackupManifest is the index written next to the campaign files of a backup
+type BackupManifest struct {
+	AccountID string                `json:"account_id"`
+	CreatedAt time.Time             `json:"created_at"`
+	Profile   string                `json:"profile,omitempty"`
+	Campaigns []BackupManifestEntry `json:"campaigns"`
+}
+
+// BackupManifestEntry describes one campaign stored in a backup
+type BackupManifestEntry struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	File         string `json:"file"`
+	InsightsFile string `json:"insights_file,omitempty"`
+}
+
+// backupManifestFile is the name of the index file inside a backup directory
+const backupManifestFile = "index.json"
+
+// backupCreativesFile is the creative library of a backup made with the structure profile
+const backupCreativesFile = "creatives.json"
+
+// backupAccount writes the configuration of every campaign in the account to a directory
+func backupAccount(cfg *config.Config, args []string) {
+	var (
+		dir             string
+		includeInsights bool
+		profileName     string
+	)
+
+	// Handle flags
+	fs := newCommandFlags("backup [options]")
+	fs.StringVar(&dir, "dir", "", "Backup directory (default: backups/<today>)")
+	fs.BoolVar(&includeInsights, "include-insights", false, "Also save the lifetime insights of each campaign")
+	fs.StringVar(&profileName, "profile", "", "Export profile that selects the fields to keep")
+	parseCommandArgs(fs, args, 0, 0)
+
+	profile, err := internal_campaign.ParseExportProfile(profileName)
+	if err != nil {
+		fmt.Printf("Invalid --profile value: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Default to a dated directory so repeated backups don't overwrite each other
+	if dir == "" {
+		dir = filepath.Join("backups", time.Now().Format("2006-01-02"))
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Printf("Error creating backup directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create API clients
+	client := api.NewClient(authClient, cfg.AccountID)
+	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+
+	fmt.Printf("Backing up campaigns of account %s to %s\n", cfg.AccountID, dir)
+
+	result, err := runBackup(cmdContext, os.Stdout, client, metricsCollector, cfg.AccountID, dir, includeInsights, profile)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if result.Interrupted {
+		fmt.Printf("\nBackup interrupted: %d written, %d skipped, %d failed. Run the command again to resume.\n", result.Written, result.Skipped, result.Failed)
+		fmt.Printf("Manifest: %s\n", filepath.Join(dir, backupManifestFile))
+		os.Exit(1)
+	}
+
+	fmt.Printf("\nBackup completed: %d written, %d skipped, %d failed\n", result.Written, result.Skipped, result.Failed)
+	fmt.Printf("Manifest: %s\n", filepath.Join(dir, backupManifestFile))
+
+	if result.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// backupResult counts the campaigns of a backup run
+type backupResult struct {
+	Written     int
+	Skipped     int // Already backed up by an earlier run
+	Failed      int
+	Interrupted bool // ctx was cancelled before every campaign was backed up
+}
+
+// runBackup writes the configuration of every campaign in the account to dir, followed by
+// the manifest. Campaigns whose files exist from an earlier, interrupted run are skipped,
+// so running it again resumes the backup.
+func runBackup(ctx context.Context, w io.Writer, client *api.Client, metricsCollector *api.MetricsCollector, accountID, dir string,
+	includeInsights bool, profile internal_campaign.ExportProfile) (backupResult, error) {
+	var result backupResult
+	manifest := BackupManifest{
+		AccountID: accountID,
+		CreatedAt: time.Now(),
+		Profile:   string(profile),
+	}
+
+	// Creatives of structure backups are shared in one library, kept across resumed runs
+	var creatives internal_campaign.CreativeLibrary
+	if profile == internal_campaign.ExportProfileStructure {
+		var err error
+		creatives, err = internal_campaign.LoadCreativeLibrary(filepath.Join(dir, backupCreativesFile))
+		if os.IsNotExist(err) {
+			creatives = make(internal_campaign.CreativeLibrary)
+		} else if err != nil {
+			return result, fmt.Errorf("error reading creative library: %w", err)
+		}
+	}
+
+	var nextCursor string
+
+	// Stream campaigns page by page instead of loading the whole account first
+pages:
+	for {
+		resp, err := client.GetCampaignsContext(ctx, 100, nextCursor)
+		if ctx.Err() != nil {
+			result.Interrupted = true
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("error fetching campaigns: %w", err)
+		}
+
+		for _, campaign := range resp.Data {
+			entry := BackupManifestEntry{
+				ID:     campaign.ID,
+				Name:   campaign.Name,
+				Status: campaign.Status,
+				File:   campaign.ID + ".json",
+			}
+			if includeInsights {
+				entry.InsightsFile = campaign.ID + "_insights.json"
+			}
+
+			// Resume support: files from an interrupted run are kept as they are
+			if fileExists(filepath.Join(dir, entry.File)) &&
+				(!includeInsights || fileExists(filepath.Join(dir, entry.InsightsFile))) {
+				if fileExists(filepath.Join(dir, campaign.ID+"_insights.json")) {
+					entry.InsightsFile = campaign.ID + "_insights.json"
+				}
+				fmt.Fprintf(w, "  Skipping %s (%s): already backed up\n", campaign.ID, campaign.Name)
+				manifest.Campaigns = append(manifest.Campaigns, entry)
+				result.Skipped++
+				continue
+			}
+
+			if err := backupCampaign(ctx, client, metricsCollector, dir, campaign, entry, profile, creatives); err != nil {
+				// Ctrl-C: keep what was backed up so far so the next run resumes from there
+				if ctx.Err() != nil {
+					result.Interrupted = true
+					break pages
+				}
+				fmt.Fprintf(w, "  Error backing up %s (%s): %v\n", campaign.ID, campaign.Name, err)
+				result.Failed++
+				continue
+			}
+
+			fmt.Fprintf(w, "  Backed up %s (%s)\n", campaign.ID, campaign.Name)
+			manifest.Campaigns = append(manifest.Campaigns, entry)
+			result.Written++
+		}
+
+		// Check if there are more pages
+		if resp.Paging.Next == "" || resp.Paging.Cursors.After == "" {
+			break
+		}
+		nextCursor = resp.Paging.Cursors.After
+	}
+
+	// Write the manifest last so it only lists completed campaigns
+	if err := writeJSONFile(filepath.Join(dir, backupManifestFile), manifest); err != nil {
+		return result, fmt.Errorf("error writing backup manifest: %w", err)
+	}
+	return result, nil
+}
+
+// backupCampaign writes the configuration (and optionally insights) of one campaign.
+// With the structure profile its creatives are added to the backup's creative library.
+func backupCampaign(ctx context.Context, client *api.Client, metricsCollector *api.MetricsCollector, dir string, campaign models.Campaign, entry BackupManifestEntry,
+	profile internal_campaign.ExportProfile, creatives internal_campaign.CreativeLibrary) error {
+	details, err := client.GetCampaignDetailsContext(ctx, campaign.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching campaign details: %w", err)
+	}
+
+	campaignConfig := internal_campaign.ConfigFromDetails(details)
+
+	if entry.InsightsFile != "" {
+		since := campaign.Created
+		if since.IsZero() {
+			since = time.Now().AddDate(-1, 0, 0)
+		}
+
+		insights, err := metricsCollector.CollectCampaignMetricsContext(ctx, api.InsightsRequest{
+			Level: "campaign",
+			TimeRange: api.TimeRange{
+				Since: since.Format("2006-01-02"),
+				Until: time.Now().Format("2006-01-02"),
+			},
+			Filtering: []api.Filter{
+				{Field: "campaign.id", Operator: "IN", Value: []string{campaign.ID}},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("error fetching insights: %w", err)
+		}
+
+		if err := writeJSONFile(filepath.Join(dir, entry.InsightsFile), insights); err != nil {
+			return err
+		}
+	}
+
+	profiled, campaignCreatives := internal_campaign.ApplyExportProfile(campaignConfig, profile)
+	if len(campaignCreatives) > 0 {
+		creatives.Merge(campaignCreatives)
+		if err := writeJSONFile(filepath.Join(dir, backupCreativesFile), creatives); err != nil {
+			return err
+		}
+	}
+
+	data, err := internal_campaign.MarshalExportConfig(profiled, profile)
+	if err != nil {
+		return fmt.Errorf("error serializing %s: %w", entry.File, err)
+	}
+
+	// The config file is written last; its presence marks the campaign as done
+	return writeFileAtomic(filepath.Join(dir, entry.File), data)
+}
+
+// writeJSONFile writes a value as indented JSON through a temporary file
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing %s: %w", filepath.Base(path), err)
+	}
+
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to path through a temporary file
+func writeFileAtomic(path string, data []byte) error {
+	// Write to a temp file first so an interrupted run never leaves half a file behind
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", filepath.Base(path), err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("error writing %s: %w", filepath.Base(path), err)
+	}
+
+	return nil
+}
+
+// fileExists reports whether a regular file exists at path
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/internal/api"
+	internal_campaign "github.com/user/fb-ads/internal/campaign"
+	"github.com/user/fb-ads/internal/demo"
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+// interruptingTransport cancels the backup, like Ctrl-C, once a campaign is requested
+type interruptingTransport struct {
+	provider *demo.Provider
+	campaign string
+	cancel   context.CancelFunc
+}
+
+func (t *interruptingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.campaign != "" && strings.Contains(req.URL.Path, t.campaign) {
+		t.cancel()
+		return nil, context.Canceled
+	}
+	return t.provider.RoundTrip(req)
+}
+
+func readBackupManifest(t *testing.T, dir string) BackupManifest {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, backupManifestFile))
+	if err != nil {
+		t.Fatalf("Error reading manifest: %v", err)
+	}
+	var manifest BackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Error parsing manifest: %v", err)
+	}
+	return manifest
+}
+
+func TestRunBackup_ResumesInterruptedBackup(t *testing.T) {
+	transport := &interruptingTransport{provider: demo.NewProvider()}
+	authClient := auth.NewFacebookAuth("", "", "", "v22.0")
+	authClient.Transport = transport
+	client := api.NewClient(authClient, demo.AccountID)
+	metricsCollector := api.NewMetricsCollector(authClient, demo.AccountID)
+
+	campaigns, err := client.GetAllCampaigns()
+	if err != nil {
+		t.Fatalf("GetAllCampaigns failed: %v", err)
+	}
+	if len(campaigns) < 4 {
+		t.Fatalf("Expected at least 4 demo campaigns, got %d", len(campaigns))
+	}
+	dir := t.TempDir()
+
+	// The first run is interrupted while backing up the third campaign
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	transport.campaign = campaigns[2].ID
+	transport.cancel = cancel
+
+	result, err := runBackup(ctx, io.Discard, client, metricsCollector, demo.AccountID, dir, false, internal_campaign.ExportProfileFull)
+	if err != nil {
+		t.Fatalf("runBackup failed: %v", err)
+	}
+	if !result.Interrupted || result.Written != 2 || result.Skipped != 0 || result.Failed != 0 {
+		t.Errorf("Expected an interrupted run with 2 written, got %+v", result)
+	}
+	manifest := readBackupManifest(t, dir)
+	if len(manifest.Campaigns) != 2 {
+		t.Errorf("Expected the manifest to list the 2 completed campaigns, got %d", len(manifest.Campaigns))
+	}
+	if fileExists(filepath.Join(dir, campaigns[2].ID+".json")) {
+		t.Errorf("Expected no file for the interrupted campaign")
+	}
+
+	// A file already in the directory is kept as it is
+	existing := filepath.Join(dir, campaigns[3].ID+".json")
+	if err := os.WriteFile(existing, []byte(`{"name":"kept"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second run resumes and backs up the remaining campaigns
+	transport.campaign = ""
+	result, err = runBackup(context.Background(), io.Discard, client, metricsCollector, demo.AccountID, dir, false, internal_campaign.ExportProfileFull)
+	if err != nil {
+		t.Fatalf("runBackup failed: %v", err)
+	}
+	expected := backupResult{Written: len(campaigns) - 3, Skipped: 3}
+	if result != expected {
+		t.Errorf("Expected %+v on resume, got %+v", expected, result)
+	}
+
+	manifest = readBackupManifest(t, dir)
+	if len(manifest.Campaigns) != len(campaigns) {
+		t.Fatalf("Expected the manifest to list %d campaigns, got %d", len(campaigns), len(manifest.Campaigns))
+	}
+	for i, entry := range manifest.Campaigns {
+		if entry.ID != campaigns[i].ID {
+			t.Errorf("Expected campaign %s at position %d of the manifest, got %s", campaigns[i].ID, i, entry.ID)
+		}
+		if !fileExists(filepath.Join(dir, entry.File)) {
+			t.Errorf("Expected %s to be written", entry.File)
+		}
+	}
+
+	data, err := os.ReadFile(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"name":"kept"}` {
+		t.Errorf("Expected the existing file to be skipped, got %s", data)
+	}
+}
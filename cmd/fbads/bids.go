@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/internal/optimization"
+)
+
+// bidCooldownHours is how long a campaign's bid is left alone after it was adjusted
+const bidCooldownHours = 48
+
+// applyBids adjusts the CPM bids of the account's campaigns towards the optimal CPM and sets
+// them on their ad sets. Applied adjustments are kept in the stats directory so a campaign is
+// not adjusted again within the cooldown, even by a later run.
+func applyBids(cfg *config.Config, args []string) {
+	since := "7d"
+	maxCPM := 15.0
+	minCPM := 1.0
+	minImpressions := 1000
+	dryRun := false
+
+	// Handle flags
+	fs := newCommandFlags("optimize apply-bids [options]")
+	fs.StringVar(&since, "since", since, "Period the CPM is measured over: a date (YYYY-MM-DD) or days like 7d")
+	fs.Float64Var(&maxCPM, "max-cpm", maxCPM, "Maximum CPM in dollars")
+	fs.Float64Var(&minCPM, "min-cpm", minCPM, "Minimum CPM in dollars")
+	fs.IntVar(&minImpressions, "min-impressions", minImpressions, "Impressions a campaign needs before its bid is adjusted")
+	fs.BoolVar(&dryRun, "dry-run", false, "Show the proposed bids without changing them")
+	alias(fs, "d", "dry-run")
+	parseCommandArgs(fs, args, 0, 0)
+
+	if minCPM <= 0 || maxCPM < minCPM {
+		fmt.Println("--min-cpm must be positive and not above --max-cpm")
+		os.Exit(1)
+	}
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+	client := api.NewClient(authClient, cfg.AccountID)
+	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+	clock := metricsCollector.Clock()
+
+	// Today is incomplete, so the period ends yesterday
+	startDate, err := parseSinceFlag(since, clock.Today())
+	if err != nil {
+		fmt.Printf("Invalid --since value: %v\n", err)
+		os.Exit(1)
+	}
+	timeRange := api.TimeRange{
+		Since: startDate.Format("2006-01-02"),
+		Until: clock.Yesterday().Format("2006-01-02"),
+	}
+
+	performances, err := metricsCollector.CollectCampaignMetricsContext(cmdContext, api.InsightsRequest{TimeRange: timeRange})
+	if err != nil {
+		fmt.Printf("Error collecting campaign metrics: %v\n", err)
+		os.Exit(1)
+	}
+
+	names := make(map[string]string, len(performances))
+	var campaigns []optimization.CampaignPerformance
+	var campaignIDs []string
+	for _, perf := range performances {
+		if perf.Impressions < minImpressions {
+			continue
+		}
+		names[perf.CampaignID] = perf.Name
+		campaigns = append(campaigns, optimization.FromModel(perf))
+		campaignIDs = append(campaignIDs, perf.CampaignID)
+	}
+	if len(campaigns) == 0 {
+		fmt.Printf("No campaign has %d impressions in the specified date range.\n", minImpressions)
+		return
+	}
+
+	// Demo campaigns don't outlive the command, so their adjustments are not recorded
+	historyPath := filepath.Join(cfg.ConfigDir, "stats", "cpm_adjustments.json")
+	if demoMode {
+		historyPath = ""
+	}
+	history, err := optimization.LoadAdjustmentHistory(historyPath)
+	if err != nil {
+		fmt.Printf("Error loading adjustment history: %v\n", err)
+		os.Exit(1)
+	}
+	previous := history.Latest()
+
+	adjuster := optimization.NewAdjuster(maxCPM, minCPM, 10, 10, bidCooldownHours)
+	adjustments := adjuster.CalculateAdjustments(campaigns, previous)
+
+	eligible := make(map[string]bool)
+	for _, campaignID := range adjuster.GetEligibleCampaigns(campaignIDs, previous) {
+		eligible[campaignID] = true
+	}
+
+	fmt.Printf("CPM bids from %s to %s (max $%.2f, min $%.2f)\n\n", timeRange.Since, timeRange.Until, maxCPM, minCPM)
+	changes := renderBidAdjustments(os.Stdout, adjustments, previous, eligible, names)
+
+	if len(changes) == 0 {
+		fmt.Println("\nNo bids to change.")
+		return
+	}
+	if dryRun {
+		fmt.Printf("\n%d bids would change (dry run, nothing was changed)\n", len(changes))
+		return
+	}
+
+	fmt.Println()
+	applied, applyErr := client.ApplyAdjustmentsContext(cmdContext, changes)
+	for _, result := range applied {
+		adjustment := result.Adjustment
+		fmt.Printf("Set the bid of %s (%s) to $%.2f on %d ad sets\n",
+			names[adjustment.CampaignID], adjustment.CampaignID, adjustment.AdjustedCPM, len(result.AdSetIDs))
+		history.Add(adjustment)
+	}
+	if len(applied) < len(changes) && applyErr == nil {
+		fmt.Printf("%d campaigns have no active ad set with a bid cap\n", len(changes)-len(applied))
+	}
+
+	if err := history.Save(); err != nil {
+		fmt.Printf("Error saving adjustment history: %v\n", err)
+		os.Exit(1)
+	}
+	if applyErr != nil {
+		fmt.Printf("Error applying bids: %v\n", applyErr)
+		os.Exit(1)
+	}
+}
+
+// renderBidAdjustments writes the current and proposed CPM of every campaign with the time
+// of its last adjustment, and returns the adjustments that change a bid
+func renderBidAdjustments(w io.Writer, adjustments, previous []optimization.CampaignAdjustment, eligible map[string]bool, names map[string]string) []optimization.CampaignAdjustment {
+	lastAdjusted := make(map[string]optimization.CampaignAdjustment, len(previous))
+	for _, adjustment := range previous {
+		lastAdjusted[adjustment.CampaignID] = adjustment
+	}
+
+	fmt.Fprintf(w, "%-20s | %-30s | %-11s | %-12s | %s\n", "ID", "NAME", "CURRENT CPM", "PROPOSED CPM", "LAST ADJUSTED")
+	fmt.Fprintln(w, strings.Repeat("-", 105))
+
+	var changes []optimization.CampaignAdjustment
+	for _, adjustment := range adjustments {
+		last := "never"
+		if previous, ok := lastAdjusted[adjustment.CampaignID]; ok {
+			last = previous.AdjustmentTS.Local().Format("2006-01-02 15:04")
+		}
+
+		proposed := fmt.Sprintf("$%.2f", adjustment.AdjustedCPM)
+		if !eligible[adjustment.CampaignID] {
+			proposed = "-"
+			last += " (cooldown)"
+		} else if math.Abs(adjustment.AdjustedCPM-adjustment.CurrentCPM) >= 0.01 {
+			changes = append(changes, adjustment)
+		}
+
+		fmt.Fprintf(w, "%-20s | %-30s | $%-10.2f | %-12s | %s\n",
+			adjustment.CampaignID,
+			truncateString(names[adjustment.CampaignID], 30),
+			adjustment.CurrentCPM,
+			proposed,
+			last)
+	}
+	return changes
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/internal/optimization"
+)
+
+func TestRenderBidAdjustments(t *testing.T) {
+	lastWeek := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	yesterday := time.Date(2024, 1, 7, 12, 0, 0, 0, time.Local)
+
+	adjustments := []optimization.CampaignAdjustment{
+		{CampaignID: "100", CurrentCPM: 8, AdjustedCPM: 8.8},
+		{CampaignID: "200", CurrentCPM: 12, AdjustedCPM: 12, AdjustmentTS: yesterday},
+		{CampaignID: "300", CurrentCPM: 10, AdjustedCPM: 10},
+	}
+	previous := []optimization.CampaignAdjustment{
+		{CampaignID: "100", CurrentCPM: 7.5, AdjustedCPM: 8, AdjustmentTS: lastWeek},
+		{CampaignID: "200", CurrentCPM: 13, AdjustedCPM: 12, AdjustmentTS: yesterday},
+	}
+	eligible := map[string]bool{"100": true, "300": true}
+	names := map[string]string{"100": "Prospecting", "200": "Retargeting", "300": "Brand"}
+
+	var buf bytes.Buffer
+	changes := renderBidAdjustments(&buf, adjustments, previous, eligible, names)
+	out := buf.String()
+
+	if len(changes) != 1 || changes[0].CampaignID != "100" {
+		t.Errorf("Expected only campaign 100 to change, got %+v", changes)
+	}
+	for _, expected := range []string{
+		"$8.80        | 2024-01-01 12:00",
+		"-            | 2024-01-07 12:00 (cooldown)",
+		"$10.00       | never",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// bulkStatusFilter selects the campaigns a bulk status change applies to; a campaign has
+// to match every filter given
+type bulkStatusFilter struct {
+	namePrefix string
+	ids        []string
+	objective  string
+}
+
+// empty reports whether no filter is given, which would select every campaign
+func (f bulkStatusFilter) empty() bool {
+	return f.namePrefix == "" && len(f.ids) == 0 && f.objective == ""
+}
+
+// matches reports whether a campaign passes the filters
+func (f bulkStatusFilter) matches(campaign models.Campaign) bool {
+	if f.namePrefix != "" && !strings.HasPrefix(campaign.Name, f.namePrefix) {
+		return false
+	}
+	if len(f.ids) > 0 {
+		listed := false
+		for _, id := range f.ids {
+			listed = listed || id == campaign.ID
+		}
+		if !listed {
+			return false
+		}
+	}
+	// Legacy objectives match their outcome-based equivalents
+	if f.objective != "" && !strings.EqualFold(models.NormalizeObjective(campaign.ObjectiveType), models.NormalizeObjective(f.objective)) {
+		return false
+	}
+	return true
+}
+
+// bulkStatusResult is the outcome of changing the status of one campaign
+type bulkStatusResult struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	PreviousStatus string `json:"previous_status"`
+	Status         string `json:"status"`
+	Success        bool   `json:"success"`
+	Error          string `json:"error,omitempty"`
+}
+
+// bulkStatusSummary is the outcome of a bulk status change, printed with --format json
+type bulkStatusSummary struct {
+	Status  string             `json:"status"`
+	DryRun  bool               `json:"dry_run,omitempty"`
+	Matched int                `json:"matched"`
+	Updated int                `json:"updated"`
+	Failed  int                `json:"failed"`
+	Results []bulkStatusResult `json:"results"`
+}
+
+// bulkStatus sets the status of every campaign matching the filters, after showing them
+// and asking for confirmation. A failed campaign doesn't stop the others.
+func bulkStatus(cfg *config.Config, args []string) {
+	var (
+		statusValue, ids, format string
+		filter                   bulkStatusFilter
+		dryRun, force            bool
+	)
+	format = "table"
+
+	fs := newCommandFlags("bulk-status --status STATUS [--name-prefix PREFIX] [--ids ID,ID,...] [--objective OBJECTIVE] [options]")
+	fs.StringVar(&statusValue, "status", "", "Status to set: ACTIVE, PAUSED or ARCHIVED")
+	fs.StringVar(&filter.namePrefix, "name-prefix", "", "Only campaigns whose name starts with this prefix")
+	fs.StringVar(&ids, "ids", "", "Only these comma-separated campaign IDs")
+	fs.StringVar(&filter.objective, "objective", "", "Only campaigns with this objective, e.g. OUTCOME_SALES")
+	fs.BoolVar(&dryRun, "dry-run", false, "Show the campaigns that would change without changing them")
+	alias(fs, "d", "dry-run")
+	fs.BoolVar(&force, "force", false, "Change the campaigns without asking for confirmation")
+	fs.StringVar(&format, "format", format, "Format of the result summary: table or json")
+	parseCommandArgs(fs, args, 0, 0)
+
+	status := models.CampaignStatus(mustParseSettableStatus(statusValue))
+	filter.ids = splitList(ids)
+	if filter.empty() {
+		fmt.Println("Error: give at least one of --name-prefix, --ids or --objective to select campaigns")
+		os.Exit(1)
+	}
+	if format != "table" && format != "json" {
+		fmt.Printf("Error: unknown format %q (use table or json)\n", format)
+		os.Exit(1)
+	}
+
+	// With JSON the summary alone goes to stdout, so it can be piped; the banner and the
+	// demo notice are written to stderr by main and newAuthClient
+	out := io.Writer(os.Stdout)
+	if format == "json" {
+		out = os.Stderr
+	}
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create API client
+	client := api.NewClient(authClient, cfg.AccountID)
+
+	var campaigns []models.Campaign
+	var after string
+	for {
+		resp, err := client.GetCampaignsContext(cmdContext, 100, after)
+		if err != nil {
+			fmt.Fprintf(out, "Error fetching campaigns: %v\n", err)
+			os.Exit(1)
+		}
+		campaigns = append(campaigns, resp.Data...)
+		if resp.Paging.Next == "" || resp.Paging.Cursors.After == "" {
+			break
+		}
+		after = resp.Paging.Cursors.After
+	}
+
+	targets, skipped, missing := bulkStatusTargets(campaigns, filter, status)
+	for _, id := range missing {
+		fmt.Fprintf(out, "Warning: campaign %s not found in account act_%s\n", id, cfg.AccountID)
+	}
+	if skipped > 0 {
+		fmt.Fprintf(out, "Skipping %d matching campaigns that are already %s or archived\n", skipped, status)
+	}
+	if len(targets) == 0 {
+		fmt.Fprintf(out, "No campaigns to set to %s.\n", status)
+		if format == "json" {
+			writeBulkStatusJSON(bulkStatusSummary{Status: string(status), DryRun: dryRun, Results: []bulkStatusResult{}})
+		}
+		return
+	}
+
+	fmt.Fprintf(out, "This will set %d campaigns to %s:\n", len(targets), status)
+	for _, campaign := range targets {
+		fmt.Fprintf(out, "  %s  %s (%s, %s)\n", campaign.ID, campaign.Name, campaign.Status, campaign.ObjectiveType)
+	}
+
+	if dryRun {
+		fmt.Fprintln(out, "\nDry run: no campaign was changed")
+		if format == "json" {
+			summary := bulkStatusSummary{Status: string(status), DryRun: true, Matched: len(targets)}
+			for _, campaign := range targets {
+				summary.Results = append(summary.Results, bulkStatusResult{ID: campaign.ID, Name: campaign.Name, PreviousStatus: campaign.Status, Status: campaign.Status})
+			}
+			writeBulkStatusJSON(summary)
+		}
+		return
+	}
+
+	if !force {
+		fmt.Fprintf(out, "\nSet %d campaigns to %s? (y/n): ", len(targets), status)
+		var confirm string
+		fmt.Scanln(&confirm)
+
+		if confirm != "y" && confirm != "Y" && confirm != "yes" && confirm != "Yes" {
+			fmt.Fprintln(out, "Status change cancelled.")
+			return
+		}
+	}
+
+	// Rate limited updates are retried by the client with backoff before they count as failed
+	fmt.Fprintln(out)
+	summary := applyBulkStatus(out, targets, status, func(campaignID string) error {
+		return setCampaignStatus(client, campaignID, status)
+	})
+
+	if format == "json" {
+		writeBulkStatusJSON(summary)
+	} else {
+		renderBulkStatusSummary(out, summary)
+	}
+	if summary.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// bulkStatusTargets returns the campaigns matching the filter that the status change
+// applies to, the number of matching campaigns skipped because they already have the
+// status or are archived, and the IDs given with --ids that aren't in the account
+func bulkStatusTargets(campaigns []models.Campaign, filter bulkStatusFilter, status models.CampaignStatus) ([]models.Campaign, int, []string) {
+	var targets []models.Campaign
+	skipped := 0
+	found := make(map[string]bool)
+	for _, campaign := range campaigns {
+		found[campaign.ID] = true
+		if !filter.matches(campaign) {
+			continue
+		}
+
+		// Archived campaigns can only be deleted
+		current := models.CampaignStatus(strings.ToUpper(campaign.Status))
+		if current == status || current == models.CampaignStatusArchived || current == models.CampaignStatusDeleted {
+			skipped++
+			continue
+		}
+		targets = append(targets, campaign)
+	}
+
+	var missing []string
+	for _, id := range filter.ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	return targets, skipped, missing
+}
+
+// applyBulkStatus sets the status of each campaign with update, writing the progress to w.
+// Failures are recorded and the remaining campaigns are still updated.
+func applyBulkStatus(w io.Writer, targets []models.Campaign, status models.CampaignStatus, update func(campaignID string) error) bulkStatusSummary {
+	summary := bulkStatusSummary{Status: string(status), Matched: len(targets)}
+	for i, campaign := range targets {
+		result := bulkStatusResult{ID: campaign.ID, Name: campaign.Name, PreviousStatus: campaign.Status, Status: campaign.Status}
+		if err := update(campaign.ID); err != nil {
+			result.Error = err.Error()
+			summary.Failed++
+			fmt.Fprintf(w, "[%d/%d] Error updating %s (%s): %v\n", i+1, len(targets), campaign.Name, campaign.ID, err)
+		} else {
+			result.Status = string(status)
+			result.Success = true
+			summary.Updated++
+			fmt.Fprintf(w, "[%d/%d] Set %s (%s) to %s\n", i+1, len(targets), campaign.Name, campaign.ID, status)
+		}
+		summary.Results = append(summary.Results, result)
+	}
+	return summary
+}
+
+// renderBulkStatusSummary writes the number of updated campaigns and the failed ones
+func renderBulkStatusSummary(w io.Writer, summary bulkStatusSummary) {
+	fmt.Fprintf(w, "\nSet %d of %d campaigns to %s", summary.Updated, summary.Matched, summary.Status)
+	if summary.Failed == 0 {
+		fmt.Fprintln(w)
+		return
+	}
+	fmt.Fprintf(w, ", %d failed:\n", summary.Failed)
+	for _, result := range summary.Results {
+		if !result.Success {
+			fmt.Fprintf(w, "  %s  %s: %s\n", result.ID, result.Name, result.Error)
+		}
+	}
+}
+
+// writeBulkStatusJSON prints the summary of a bulk status change as JSON
+func writeBulkStatusJSON(summary bulkStatusSummary) {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error marshaling results: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
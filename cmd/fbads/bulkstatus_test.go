@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/internal/demo"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestBulkStatusTargets(t *testing.T) {
+	campaigns := []models.Campaign{
+		{ID: "1", Name: "Test - Hiking", Status: "ACTIVE", ObjectiveType: "OUTCOME_SALES"},
+		{ID: "2", Name: "Test - Trekking", Status: "ACTIVE", ObjectiveType: "CONVERSIONS"},
+		{ID: "3", Name: "Test - Knitting", Status: "PAUSED", ObjectiveType: "OUTCOME_SALES"},
+		{ID: "4", Name: "Test - Old", Status: "ARCHIVED", ObjectiveType: "OUTCOME_SALES"},
+		{ID: "5", Name: "Evergreen", Status: "ACTIVE", ObjectiveType: "OUTCOME_TRAFFIC"},
+	}
+
+	tests := []struct {
+		name            string
+		filter          bulkStatusFilter
+		status          models.CampaignStatus
+		expectedIDs     []string
+		expectedSkipped int
+		expectedMissing []string
+	}{
+		{name: "name prefix", filter: bulkStatusFilter{namePrefix: "Test -"}, status: models.CampaignStatusPaused, expectedIDs: []string{"1", "2"}, expectedSkipped: 2},
+		{name: "ids", filter: bulkStatusFilter{ids: []string{"3", "5", "9"}}, status: models.CampaignStatusActive, expectedIDs: []string{"3"}, expectedSkipped: 1, expectedMissing: []string{"9"}},
+		{name: "legacy objective", filter: bulkStatusFilter{objective: "conversions"}, status: models.CampaignStatusPaused, expectedIDs: []string{"1", "2"}, expectedSkipped: 2},
+		{name: "all filters", filter: bulkStatusFilter{namePrefix: "Test -", ids: []string{"1", "5"}, objective: "OUTCOME_SALES"}, status: models.CampaignStatusPaused, expectedIDs: []string{"1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, skipped, missing := bulkStatusTargets(campaigns, tt.filter, tt.status)
+			var ids []string
+			for _, campaign := range targets {
+				ids = append(ids, campaign.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expectedIDs) || skipped != tt.expectedSkipped || !reflect.DeepEqual(missing, tt.expectedMissing) {
+				t.Errorf("bulkStatusTargets = %v, %d, %v, want %v, %d, %v", ids, skipped, missing, tt.expectedIDs, tt.expectedSkipped, tt.expectedMissing)
+			}
+		})
+	}
+
+	if !(bulkStatusFilter{}).empty() {
+		t.Error("Expected a filter without values to be empty")
+	}
+}
+
+func TestApplyBulkStatus(t *testing.T) {
+	targets := []models.Campaign{
+		{ID: "1", Name: "Hiking", Status: "ACTIVE"},
+		{ID: "2", Name: "Trekking", Status: "ACTIVE"},
+		{ID: "3", Name: "Knitting", Status: "ACTIVE"},
+	}
+
+	var updated []string
+	var out strings.Builder
+	summary := applyBulkStatus(&out, targets, models.CampaignStatusPaused, func(campaignID string) error {
+		updated = append(updated, campaignID)
+		if campaignID == "2" {
+			return errors.New("API error: rate limited")
+		}
+		return nil
+	})
+
+	if !reflect.DeepEqual(updated, []string{"1", "2", "3"}) {
+		t.Errorf("Expected every campaign to be updated despite the failure, got %v", updated)
+	}
+	if summary.Matched != 3 || summary.Updated != 2 || summary.Failed != 1 {
+		t.Errorf("Expected 2 updated and 1 failed of 3, got %+v", summary)
+	}
+	failed := summary.Results[1]
+	if failed.Success || failed.Status != "ACTIVE" || failed.Error != "API error: rate limited" {
+		t.Errorf("Expected the failed campaign to keep its status, got %+v", failed)
+	}
+	if !summary.Results[2].Success || summary.Results[2].Status != "PAUSED" || summary.Results[2].PreviousStatus != "ACTIVE" {
+		t.Errorf("Expected the last campaign to be paused, got %+v", summary.Results[2])
+	}
+
+	for _, expected := range []string{"[1/3] Set Hiking (1) to PAUSED", "[2/3] Error updating Trekking (2): API error: rate limited"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in the progress:\n%s", expected, out.String())
+		}
+	}
+
+	out.Reset()
+	renderBulkStatusSummary(&out, summary)
+	for _, expected := range []string{"Set 2 of 3 campaigns to PAUSED, 1 failed:", "2  Trekking: API error: rate limited"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in the summary:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestBulkStatus_JSONOutput(t *testing.T) {
+	oldDemoMode, oldProvider, oldStdout := demoMode, demoProvider, os.Stdout
+	t.Cleanup(func() { demoMode, demoProvider, os.Stdout = oldDemoMode, oldProvider, oldStdout })
+	demoMode, demoProvider = true, nil
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = writer
+
+	cfg := config.DefaultConfig()
+	cfg.AccountID = demo.AccountID
+	bulkStatus(cfg, []string{"--status", "PAUSED", "--name-prefix", "Spring Sale", "--dry-run", "--format", "json"})
+	writer.Close()
+	os.Stdout = oldStdout
+
+	// Progress and notices go to stderr, so stdout holds the JSON summary alone
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary bulkStatusSummary
+	if err := json.Unmarshal(output, &summary); err != nil {
+		t.Fatalf("Expected stdout to be valid JSON, got %v:\n%s", err, output)
+	}
+	if !summary.DryRun || summary.Matched != 2 {
+		t.Errorf("Expected a dry run matching the two Spring Sale campaigns, got %+v", summary)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+// defaultCollectInterval is the time between two collections of the collect command
+const defaultCollectInterval = 6 * time.Hour
+
+// collectSleep waits between retries and collections; tests replace it
+var collectSleep = func(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// collectDaemon collects today's statistics on a schedule until it is interrupted
+func collectDaemon(cfg *config.Config, args []string) {
+	interval := defaultCollectInterval
+	once := false
+
+	// Handle flags
+	fs := newCommandFlags("collect [options]")
+	fs.DurationVar(&interval, "interval", interval, "Time between collections, e.g. 30m or 6h")
+	fs.BoolVar(&once, "once", false, "Collect once and exit")
+	parseCommandArgs(fs, args, 0, 0)
+
+	if interval <= 0 {
+		fmt.Println("Error: --interval must be positive")
+		os.Exit(1)
+	}
+
+	storageType, err := api.ParseStorageType(cfg.Statistics.Storage)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	statsDir := cfg.StatisticsDir()
+
+	// Two collectors writing the same files would overwrite each other's days
+	unlock := func() error { return nil }
+	if storageType == api.StorageTypeFile {
+		unlock, err = api.LockStatisticsDir(statsDir)
+		var lockErr *api.LockedError
+		if errors.As(err, &lockErr) {
+			fmt.Printf("Another collector is already running (process %d).\n", lockErr.PID)
+			fmt.Printf("If it is not, remove %s and try again.\n", lockErr.Path)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	authClient := newAuthClient(cfg)
+	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+	statsManager := api.NewStatisticsManager(metricsCollector, storageType, statsDir)
+
+	if once {
+		fmt.Printf("Collecting statistics into %s\n", statsDir)
+	} else {
+		fmt.Printf("Collecting statistics into %s every %s. Press Ctrl-C to stop.\n", statsDir, interval)
+	}
+
+	err = runCollector(cmdContext, statsManager, metricsCollector.Clock(), authClient.Retry, interval, once)
+	if unlockErr := unlock(); unlockErr != nil {
+		fmt.Printf("Warning: could not remove the lock file: %v\n", unlockErr)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// runCollector collects today's statistics every interval until ctx is cancelled, or
+// once. Failed collections are logged and tried again at the next interval; a single
+// collection returns its error.
+func runCollector(ctx context.Context, statsManager *api.StatisticsManager, clock *api.AccountClock, policy auth.RetryPolicy, interval time.Duration, once bool) error {
+	for {
+		today := clock.Today().Format("2006-01-02")
+		timeRange := api.TimeRange{Since: today, Until: today}
+
+		result, err := collectWithRetry(ctx, policy, func() (*api.CollectResult, error) {
+			return statsManager.CollectAndStoreStatistics(timeRange, api.CollectOptions{})
+		})
+		stamp := time.Now().Format("2006-01-02 15:04:05")
+		switch {
+		case ctx.Err() != nil:
+			fmt.Println("Collector stopped.")
+			return nil
+		case err != nil:
+			fmt.Printf("[%s] Error collecting statistics for %s: %v\n", stamp, today, err)
+		default:
+			fmt.Printf("[%s] Stored statistics of %d campaigns for %s (%d stopped since the previous day)\n",
+				stamp, result.ActiveCampaigns, today, result.ZeroRows)
+		}
+
+		if once {
+			return err
+		}
+
+		fmt.Printf("Next collection at %s\n", time.Now().Add(interval).Format("2006-01-02 15:04:05"))
+		if collectSleep(ctx, interval) != nil {
+			fmt.Println("Collector stopped.")
+			return nil
+		}
+	}
+}
+
+// collectWithRetry runs a collection, retrying it with a doubling delay while it fails
+// with a transient error, up to the retries of the policy
+func collectWithRetry(ctx context.Context, policy auth.RetryPolicy, collect func() (*api.CollectResult, error)) (*api.CollectResult, error) {
+	delay := policy.BaseDelay
+	for retry := 0; ; retry++ {
+		result, err := collect()
+		if err == nil || retry >= policy.MaxRetries || !isTransientError(err) {
+			return result, err
+		}
+
+		fmt.Printf("Collection failed with a transient error, retrying in %s (%d/%d): %v\n",
+			delay, retry+1, policy.MaxRetries, err)
+		if err := collectSleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		delay *= 2
+		if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+	}
+}
+
+// isTransientError reports whether an error may go away when the request is sent again:
+// network failures and API errors Facebook reports as temporary
+func isTransientError(err error) bool {
+	var apiErr *auth.FacebookAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IsTransient()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
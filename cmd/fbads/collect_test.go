@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestCollectWithRetry(t *testing.T) {
+	var sleeps []time.Duration
+	original := collectSleep
+	collectSleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	defer func() { collectSleep = original }()
+
+	policy := auth.RetryPolicy{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 3 * time.Second}
+	transient := &auth.FacebookAPIError{Code: auth.ErrorCodeService, HTTPStatus: 503, Message: "Service temporarily unavailable"}
+	permanent := &auth.FacebookAPIError{Code: 100, HTTPStatus: 400, Message: "Invalid parameter"}
+
+	tests := []struct {
+		name     string
+		errs     []error
+		wantErr  bool
+		wantRuns int
+		sleeps   []time.Duration
+	}{
+		{"succeeds first time", nil, false, 1, nil},
+		{"retries transient errors", []error{transient, transient}, false, 3, []time.Duration{time.Second, 2 * time.Second}},
+		{"gives up after the retries", []error{transient, transient, transient, transient}, true, 4, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}},
+		{"does not retry other errors", []error{permanent}, true, 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sleeps = nil
+			runs := 0
+			_, err := collectWithRetry(context.Background(), policy, func() (*api.CollectResult, error) {
+				runs++
+				if runs <= len(tt.errs) {
+					return nil, tt.errs[runs-1]
+				}
+				return &api.CollectResult{ActiveCampaigns: 2}, nil
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if runs != tt.wantRuns {
+				t.Errorf("Expected %d runs, got %d", tt.wantRuns, runs)
+			}
+			if len(sleeps) != len(tt.sleeps) {
+				t.Fatalf("Expected delays %v, got %v", tt.sleeps, sleeps)
+			}
+			for i := range sleeps {
+				if sleeps[i] != tt.sleeps[i] {
+					t.Errorf("Expected delays %v, got %v", tt.sleeps, sleeps)
+				}
+			}
+		})
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	plain := errors.New("error collecting metrics")
+	if isTransientError(plain) {
+		t.Errorf("Expected a plain error not to be transient")
+	}
+	if !isTransientError(&auth.FacebookAPIError{HTTPStatus: 500}) {
+		t.Errorf("Expected a server error to be transient")
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+// tokenExpiryWarning is how long before its expiry a token is reported as expiring soon
+const tokenExpiryWarning = 7 * 24 * time.Hour
+
+// configCheck is the outcome of checking the credentials of a profile against Facebook
+type configCheck struct {
+	Profile    string
+	APIVersion string
+	Token      auth.TokenInfo
+	Account    *api.AccountInfo
+	AccountErr error
+}
+
+// testConfiguration checks that the access token of the selected profile is valid and
+// can read the configured ad account
+func testConfiguration(cfg *config.Config) {
+	authClient := newAuthClient(cfg)
+	if err := authClient.CheckAccessToken(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Testing profile %s against Graph API %s...\n\n", cfg.Profile, cfg.APIVersion)
+
+	info, err := authClient.DebugTokenContext(cmdContext)
+	if err != nil {
+		fmt.Printf("Error checking the access token: %v\n", err)
+		os.Exit(1)
+	}
+
+	check := configCheck{Profile: cfg.Profile, APIVersion: cfg.APIVersion, Token: info}
+	switch {
+	case !info.IsValid:
+		// An invalid token cannot read the account
+	case cfg.AccountID == "":
+		check.AccountErr = errors.New("no ad account configured")
+	default:
+		check.Account, check.AccountErr = api.NewClient(authClient, cfg.AccountID).GetAccountInfoContext(cmdContext)
+	}
+
+	if !renderConfigCheck(os.Stdout, check, time.Now()) {
+		os.Exit(1)
+	}
+}
+
+// renderConfigCheck writes whether the token is valid, its permissions and expiry, and
+// the ad account it reads. It returns false when the profile cannot be used.
+func renderConfigCheck(w io.Writer, check configCheck, now time.Time) bool {
+	token := check.Token
+	if !token.IsValid {
+		reason := token.Error
+		if reason == "" {
+			reason = "rejected by Facebook"
+		}
+		fmt.Fprintf(w, "Access token: INVALID (%s)\n", reason)
+		fmt.Fprintf(w, "\nRun fbads config --profile %s to enter a new access token.\n", check.Profile)
+		return false
+	}
+
+	fmt.Fprintln(w, "Access token: valid")
+	if token.Application != "" {
+		fmt.Fprintf(w, "  App:         %s (%s), %s token\n", token.Application, token.AppID, strings.ToLower(token.Type))
+	}
+	switch {
+	case token.ExpiresAt.IsZero():
+		fmt.Fprintln(w, "  Expires:     never")
+	case token.ExpiresAt.Sub(now) < tokenExpiryWarning:
+		fmt.Fprintf(w, "  Expires:     %s (in %s, run fbads config to renew it)\n", token.ExpiresAt.Format("2006-01-02 15:04"), formatDuration(token.ExpiresAt.Sub(now)))
+	default:
+		fmt.Fprintf(w, "  Expires:     %s (in %d days)\n", token.ExpiresAt.Format("2006-01-02"), int(token.ExpiresAt.Sub(now).Hours()/24))
+	}
+	if len(token.Scopes) > 0 {
+		fmt.Fprintf(w, "  Permissions: %s\n", strings.Join(token.Scopes, ", "))
+	}
+	missing := token.MissingScopes(auth.RequiredScopes...)
+	if len(missing) > 0 {
+		fmt.Fprintf(w, "  Missing:     %s (needed to manage campaigns)\n", strings.Join(missing, ", "))
+	}
+
+	if check.AccountErr != nil {
+		fmt.Fprintf(w, "\nAd account:   ERROR (%v)\n", check.AccountErr)
+		return false
+	}
+	account := check.Account
+	fmt.Fprintf(w, "\nAd account:   act_%s %s\n", account.ID, account.Name)
+	fmt.Fprintf(w, "  Status:      %s\n", account.StatusName())
+	fmt.Fprintf(w, "  Currency:    %s\n", account.Currency)
+	fmt.Fprintf(w, "  Timezone:    %s\n", account.Timezone)
+
+	if len(missing) > 0 || !account.IsActive() {
+		fmt.Fprintln(w, "\nThe credentials work, but campaigns may not be manageable; see above.")
+		return true
+	}
+	fmt.Fprintln(w, "\nThe configuration works.")
+	return true
+}
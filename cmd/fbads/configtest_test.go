@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestRenderConfigCheck(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	valid := auth.TokenInfo{IsValid: true, Type: "USER", AppID: "42", Application: "Shop", Scopes: []string{"ads_management", "ads_read"}}
+	active := &api.AccountInfo{ID: "123", Name: "Shop", Status: 1, Currency: "EUR", Timezone: "Europe/Berlin"}
+
+	tests := []struct {
+		name       string
+		check      configCheck
+		expectedOK bool
+		expected   []string
+	}{
+		{
+			name:       "working configuration",
+			check:      configCheck{Profile: "default", Token: valid, Account: active},
+			expectedOK: true,
+			expected:   []string{"Access token: valid", "Shop (42), user token", "Expires:     never", "act_123 Shop", "Status:      ACTIVE", "The configuration works."},
+		},
+		{
+			name:       "invalid token",
+			check:      configCheck{Profile: "work", Token: auth.TokenInfo{Error: "Session has expired"}},
+			expectedOK: false,
+			expected:   []string{"INVALID (Session has expired)", "fbads config --profile work"},
+		},
+		{
+			name: "missing permission and expiring soon",
+			check: configCheck{Profile: "default", Account: active, Token: auth.TokenInfo{IsValid: true, Scopes: []string{"ads_read"},
+				ExpiresAt: now.Add(50 * time.Hour)}},
+			expectedOK: true,
+			expected:   []string{"in 2d 2h, run fbads config to renew it", "Missing:     ads_management", "may not be manageable"},
+		},
+		{
+			name:       "expiry far out",
+			check:      configCheck{Profile: "default", Account: active, Token: auth.TokenInfo{IsValid: true, Scopes: valid.Scopes, ExpiresAt: now.Add(45 * 24 * time.Hour)}},
+			expectedOK: true,
+			expected:   []string{"2026-04-15 (in 45 days)", "The configuration works."},
+		},
+		{
+			name:       "unreadable account",
+			check:      configCheck{Profile: "default", Token: valid, AccountErr: errors.New("Unsupported get request")},
+			expectedOK: false,
+			expected:   []string{"Ad account:   ERROR (Unsupported get request)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if ok := renderConfigCheck(&out, tt.check, now); ok != tt.expectedOK {
+				t.Errorf("Expected ok %v, got %v", tt.expectedOK, ok)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("Expected %q in the output:\n%s", expected, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// creativeRankingSize is the number of creatives listed as best and worst
+const creativeRankingSize = 3
+
+// handleCreatives handles the creatives subcommands
+func handleCreatives(cfg *config.Config, subCmd string, args []string) {
+	switch subCmd {
+	case "list":
+		listCreatives(cfg, args)
+	default:
+		fmt.Printf("Unknown creatives subcommand: %s\n", subCmd)
+		fmt.Println("Available subcommands: list")
+		os.Exit(1)
+	}
+}
+
+// listCreatives lists a page of the account's ad creatives, whose IDs campaign
+// configurations can reuse as creative_id
+func listCreatives(cfg *config.Config, args []string) {
+	limit := 25
+	after := ""
+	format := "table"
+
+	// Handle flags
+	fs := newCommandFlags("creatives list [options]")
+	fs.IntVar(&limit, "limit", limit, "Number of creatives to list")
+	alias(fs, "l", "limit")
+	fs.StringVar(&after, "after", "", "Cursor of the next page, printed below the list")
+	fs.StringVar(&format, "format", format, "Output format: table or json")
+	alias(fs, "f", "format")
+	parseCommandArgs(fs, args, 0, 0)
+
+	if limit <= 0 {
+		fmt.Println("--limit must be a positive number")
+		os.Exit(1)
+	}
+	if format != "table" && format != "json" {
+		fmt.Printf("Unsupported format: %s (use table or json)\n", format)
+		os.Exit(1)
+	}
+
+	client := api.NewClient(newAuthClient(cfg), cfg.AccountID)
+	response, err := client.GetAdCreativesContext(cmdContext, limit, after)
+	if err != nil {
+		fmt.Printf("Error fetching ad creatives: %v\n", err)
+		os.Exit(1)
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(response); err != nil {
+			fmt.Printf("Error encoding to JSON: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	renderCreatives(os.Stdout, response)
+}
+
+// renderCreatives writes a table of creatives and the command listing the next page, if any
+func renderCreatives(w io.Writer, response *models.CreativeResponse) {
+	if len(response.Data) == 0 {
+		fmt.Fprintln(w, "No ad creatives found.")
+		return
+	}
+
+	fmt.Fprintf(w, "%-20s | %-30s | %-40s | %s\n", "ID", "NAME", "TITLE", "PAGE")
+	fmt.Fprintf(w, "%s-+-%s-+-%s-+-%s\n",
+		strings.Repeat("-", 20),
+		strings.Repeat("-", 30),
+		strings.Repeat("-", 40),
+		strings.Repeat("-", 16))
+	for _, creative := range response.Data {
+		title := creative.Title
+		if title == "" {
+			title = creative.Body
+		}
+		fmt.Fprintf(w, "%-20s | %-30s | %-40s | %s\n",
+			creative.ID,
+			truncateString(creative.Name, 30),
+			truncateString(title, 40),
+			creative.PageID)
+	}
+
+	if response.Paging.Next != "" && response.Paging.Cursors.After != "" {
+		fmt.Fprintf(w, "\nMore creatives: fbads creatives list --after %s\n", response.Paging.Cursors.After)
+	}
+	fmt.Fprintln(w, "\nReuse a creative in a campaign configuration with \"creative\": {\"creative_id\": \"<id>\"}.")
+}
+
+// creativesReport rolls up ad performance per distinct creative across campaigns
+func creativesReport(cfg *config.Config, args []string) {
+	since := "30d"
+	format := "table"
+	outputFile := ""
+
+	// Handle flags
+	fs := newCommandFlags("report creatives [options]")
+	fs.StringVar(&since, "since", since, "Period to report: a date (YYYY-MM-DD) or days like 30d")
+	fs.StringVar(&format, "format", format, "Output format: table or json")
+	alias(fs, "f", "format")
+	fs.StringVar(&outputFile, "output", "", "Write the report to a file")
+	alias(fs, "o", "output")
+	parseCommandArgs(fs, args, 0, 0)
+
+	if format != "table" && format != "json" {
+		fmt.Printf("Unsupported format: %s (use table or json)\n", format)
+		os.Exit(1)
+	}
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create metrics collector
+	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+	clock := metricsCollector.Clock()
+
+	startDate, err := parseSinceFlag(since, clock.Today())
+	if err != nil {
+		fmt.Printf("Invalid --since value: %v\n", err)
+		os.Exit(1)
+	}
+
+	timeRange := api.TimeRange{
+		Since: startDate.Format("2006-01-02"),
+		Until: clock.Yesterday().Format("2006-01-02"),
+	}
+
+	fmt.Printf("Rolling up creative performance from %s to %s...\n\n", timeRange.Since, timeRange.Until)
+
+	report, err := metricsCollector.GenerateCreativeRollup(timeRange)
+	if err != nil {
+		fmt.Printf("Error generating creative report: %v\n", err)
+		os.Exit(1)
+	}
+
+	out := io.Writer(os.Stdout)
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			fmt.Printf("Error creating output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Printf("Error writing report: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		renderCreativeRollup(out, report)
+	}
+
+	if outputFile != "" {
+		fmt.Printf("Creative report saved to: %s\n", outputFile)
+	}
+}
+
+// renderCreativeRollup writes a table of distinct creatives followed by the best and worst ones
+func renderCreativeRollup(w io.Writer, report *api.CreativeRollupReport) {
+	if len(report.Creatives) == 0 {
+		fmt.Fprintln(w, "No ad delivery found for the specified date range.")
+		return
+	}
+
+	fmt.Fprintf(w, "%-4s | %-16s | %-30s | %-9s | %-11s | %-8s | %-6s | %-6s | %-9s | %s\n",
+		"RANK", "CREATIVE", "TITLE", "CAMPAIGNS", "IMPRESSIONS", "CLICKS", "CTR", "CONV", "CPA", "SPEND")
+	fmt.Fprintf(w, "%s-+-%s-+-%s-+-%s-+-%s-+-%s-+-%s-+-%s-+-%s-+-%s\n",
+		strings.Repeat("-", 4),
+		strings.Repeat("-", 16),
+		strings.Repeat("-", 30),
+		strings.Repeat("-", 9),
+		strings.Repeat("-", 11),
+		strings.Repeat("-", 8),
+		strings.Repeat("-", 6),
+		strings.Repeat("-", 6),
+		strings.Repeat("-", 9),
+		strings.Repeat("-", 10))
+
+	for _, creative := range report.Creatives {
+		cpa := "-"
+		if creative.Conversions > 0 {
+			cpa = fmt.Sprintf("$%.2f", creative.CPA)
+		}
+
+		fmt.Fprintf(w, "%-4d | %-16s | %-30s | %-9d | %-11d | %-8d | %5.2f%% | %-6d | %-9s | $%.2f\n",
+			creative.Rank,
+			creative.Hash,
+			truncateString(creativeLabel(creative), 30),
+			len(creative.CampaignIDs),
+			creative.Impressions,
+			creative.Clicks,
+			creative.CTR,
+			creative.Conversions,
+			cpa,
+			creative.Spend)
+	}
+
+	fmt.Fprintln(w, "\nBest creatives:")
+	for _, creative := range report.Best(creativeRankingSize) {
+		fmt.Fprintf(w, "  #%d %s - runs in: %s\n", creative.Rank, creativeLabel(creative), strings.Join(creative.Campaigns, ", "))
+	}
+
+	fmt.Fprintln(w, "\nWorst creatives:")
+	for _, creative := range report.Worst(creativeRankingSize) {
+		fmt.Fprintf(w, "  #%d %s - runs in: %s\n", creative.Rank, creativeLabel(creative), strings.Join(creative.Campaigns, ", "))
+	}
+}
+
+// creativeLabel returns a short human readable name for a creative
+func creativeLabel(creative api.CreativeRollup) string {
+	if creative.Content.Title != "" {
+		return creative.Content.Title
+	}
+	if creative.Content.Body != "" {
+		return creative.Content.Body
+	}
+	return "creative " + strings.Join(creative.CreativeIDs, ", ")
+}
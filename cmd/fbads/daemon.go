@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/internal/scheduler"
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+// scheduleDaemon runs the jobs of the scheduler config section until it is interrupted.
+// A failing job is logged and runs again at its next time.
+func scheduleDaemon(cfg *config.Config, args []string) {
+	fs := newCommandFlags("schedule daemon")
+	parseCommandArgs(fs, args, 0, 0)
+
+	if len(cfg.Scheduler.Jobs) == 0 {
+		fmt.Println("No jobs configured. Add them to the scheduler section of the config file, e.g.:")
+		fmt.Println(`  "scheduler": {"jobs": [{"name": "daily-report", "task": "report", "at": "07:00", "email": true}]}`)
+		os.Exit(1)
+	}
+
+	jobs, err := scheduledJobs(cfg)
+	if err != nil {
+		fmt.Printf("Invalid scheduler config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Daily times are in the ad account timezone, like the dates of reports
+	location := api.NewAccountClock(newAuthClient(cfg), cfg.AccountID).Location()
+	s := scheduler.NewScheduler(location)
+	for _, job := range jobs {
+		if err := s.Add(job); err != nil {
+			fmt.Printf("Invalid scheduler config: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Running %d scheduled jobs (times in %s). Press Ctrl-C to stop.\n", len(jobs), location)
+	nextRuns := s.NextRuns()
+	for i, job := range cfg.Scheduler.Jobs {
+		fmt.Printf("  %-20s %-30s next run at %s\n", jobs[i].Name, describeScheduledJob(job),
+			nextRuns[jobs[i].Name].In(location).Format("2006-01-02 15:04"))
+	}
+	fmt.Println()
+
+	s.Run(cmdContext)
+	fmt.Println("Scheduler stopped.")
+}
+
+// scheduledJobs builds the jobs of the scheduler config section. Everything a job can get
+// wrong before it runs, such as an unknown report type or an invalid rules file, is
+// reported here so the daemon doesn't start with a job that can only fail.
+func scheduledJobs(cfg *config.Config) ([]scheduler.Job, error) {
+	var jobs []scheduler.Job
+	names := make(map[string]bool)
+
+	for i, settings := range cfg.Scheduler.Jobs {
+		name := settings.Name
+		if name == "" {
+			name = settings.Task
+		}
+		if names[name] {
+			return nil, fmt.Errorf("jobs[%d]: another job is named %s; give each job a unique name", i, name)
+		}
+		names[name] = true
+
+		job := scheduler.Job{Name: name, At: settings.At}
+		if settings.Every != "" {
+			every, err := time.ParseDuration(settings.Every)
+			if err != nil || every <= 0 {
+				return nil, fmt.Errorf("jobs[%d].every: %q is not an interval like 30m or 6h", i, settings.Every)
+			}
+			job.Every = every
+		}
+
+		run, err := scheduledTask(cfg, settings)
+		if err != nil {
+			return nil, fmt.Errorf("jobs[%d] (%s): %w", i, name, err)
+		}
+		job.Run = run
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// scheduledTask returns the function running the task of a scheduled job
+func scheduledTask(cfg *config.Config, settings config.ScheduledJob) (func(ctx context.Context) error, error) {
+	switch settings.Task {
+	case config.JobTaskReport:
+		reportType := settings.Report
+		if reportType == "" {
+			reportType = "daily"
+		}
+		if reportType != "daily" && reportType != "weekly" {
+			return nil, fmt.Errorf("unknown report %q (use daily or weekly)", settings.Report)
+		}
+		format := settings.Format
+		if format == "" {
+			format = api.ReportFormatJSON
+		}
+		format, err := api.ParseReportFormat(format)
+		if err != nil {
+			return nil, err
+		}
+		var mailer *api.ReportMailer
+		if settings.Email {
+			if mailer, err = api.NewReportMailer(cfg.Email); err != nil {
+				return nil, fmt.Errorf("cannot mail the report: %w", err)
+			}
+		}
+
+		return func(ctx context.Context) error {
+			reportGenerator := newReportGenerator(cfg, mailer)
+			generate := reportGenerator.GenerateDailyReport
+			if reportType == "weekly" {
+				generate = reportGenerator.GenerateWeeklyReport
+			}
+			reportPath, err := generate(format)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Report generated: %s\n", reportPath)
+			if mailer != nil {
+				fmt.Printf("Report mailed to %s\n", strings.Join(mailer.Recipients(), ", "))
+			}
+			return nil
+		}, nil
+
+	case config.JobTaskPrune:
+		options := defaultPruneOptions()
+		options.rulesPath = settings.Rules
+		options.dryRun = settings.DryRun
+		if settings.Since != "" {
+			options.since = settings.Since
+		}
+		if _, err := parseSinceFlag(options.since, time.Now()); err != nil {
+			return nil, fmt.Errorf("invalid since: %w", err)
+		}
+		if options.rulesPath != "" {
+			if err := new(utils.Deactivator).LoadRules(options.rulesPath); err != nil {
+				return nil, err
+			}
+		}
+
+		return func(ctx context.Context) error {
+			return runPrune(ctx, cfg, options)
+		}, nil
+
+	case config.JobTaskResume:
+		return func(ctx context.Context) error {
+			return runScheduledResumes(cfg)
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown task %q (use %s, %s or %s)", settings.Task,
+			config.JobTaskReport, config.JobTaskPrune, config.JobTaskResume)
+	}
+}
+
+// describeScheduledJob describes the task and schedule of a job in a few words
+func describeScheduledJob(job config.ScheduledJob) string {
+	task := job.Task
+	switch job.Task {
+	case config.JobTaskReport:
+		report := job.Report
+		if report == "" {
+			report = "daily"
+		}
+		task = report + " report"
+		if job.Email {
+			task += " by email"
+		}
+	case config.JobTaskPrune:
+		if job.DryRun {
+			task = "prune (dry run)"
+		}
+	}
+
+	if job.At != "" {
+		return fmt.Sprintf("%s at %s", task, job.At)
+	}
+	return fmt.Sprintf("%s every %s", task, job.Every)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/internal/config"
+)
+
+func TestScheduledJobs(t *testing.T) {
+	rules := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(rules, []byte(`[{"id":"cpa","name":"High CPA","metric_type":"CPA","comparison_operator":">","threshold":80}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Scheduler.Jobs = []config.ScheduledJob{
+		{Name: "daily-report", Task: config.JobTaskReport, At: "07:00", Format: "html"},
+		{Task: config.JobTaskPrune, Every: "6h", Rules: rules},
+		{Task: config.JobTaskResume, Every: "15m"},
+	}
+
+	jobs, err := scheduledJobs(cfg)
+	if err != nil {
+		t.Fatalf("scheduledJobs failed: %v", err)
+	}
+	if len(jobs) != 3 || jobs[0].Name != "daily-report" || jobs[1].Name != "prune" || jobs[2].Every != 15*time.Minute {
+		t.Errorf("Unexpected jobs: %+v", jobs)
+	}
+
+	tests := []struct {
+		name     string
+		job      config.ScheduledJob
+		expected string
+	}{
+		{"unknown task", config.ScheduledJob{Task: "collect", Every: "1h"}, "unknown task"},
+		{"bad interval", config.ScheduledJob{Task: config.JobTaskResume, Every: "daily"}, "jobs[0].every"},
+		{"unknown report", config.ScheduledJob{Task: config.JobTaskReport, Report: "monthly", At: "07:00"}, "unknown report"},
+		{"unknown format", config.ScheduledJob{Task: config.JobTaskReport, Format: "pdf", At: "07:00"}, "pdf"},
+		{"email without SMTP", config.ScheduledJob{Task: config.JobTaskReport, Email: true, At: "07:00"}, "cannot mail the report"},
+		{"invalid since", config.ScheduledJob{Task: config.JobTaskPrune, Since: "a week", Every: "6h"}, "invalid since"},
+		{"missing rules", config.ScheduledJob{Task: config.JobTaskPrune, Rules: "missing.yaml", Every: "6h"}, "error reading rules file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Scheduler.Jobs = []config.ScheduledJob{tt.job}
+			if _, err := scheduledJobs(cfg); err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected an error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	cfg.Scheduler.Jobs = []config.ScheduledJob{
+		{Task: config.JobTaskResume, Every: "15m"},
+		{Task: config.JobTaskResume, Every: "1h"},
+	}
+	if _, err := scheduledJobs(cfg); err == nil || !strings.Contains(err.Error(), "unique name") {
+		t.Errorf("Expected duplicate names to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/user/fb-ads/internal/api"
+	internal_campaign "github.com/user/fb-ads/internal/campaign"
+	"github.com/user/fb-ads/internal/config"
+)
+
+// deleteUsage is printed with --help and after an invalid argument
+const deleteUsage = "Use: fbads delete <campaign_id> | --adset <id> | --ad <id> [--dry-run] [--force] [--mine]"
+
+// deleteArgs holds the arguments of the delete command
+type deleteArgs struct {
+	objectType string
+	objectID   string
+	dryRun     bool
+	force      bool
+	mineOnly   bool
+}
+
+// parseDeleteArgs parses a campaign ID or one of --adset and --ad, plus --dry-run, --force and --mine
+func parseDeleteArgs(args []string) (deleteArgs, error) {
+	var parsed deleteArgs
+	targets := 0
+	setTarget := func(objectType, objectID string) {
+		parsed.objectType = objectType
+		parsed.objectID = objectID
+		targets++
+	}
+
+	// The caller prints the error with the usage
+	fs := newCommandFlags("delete")
+	fs.SetOutput(io.Discard)
+	fs.Func("adset", "Ad set to delete", func(id string) error {
+		setTarget(api.ObjectTypeAdSet, id)
+		return nil
+	})
+	fs.Func("ad", "Ad to delete", func(id string) error {
+		setTarget(api.ObjectTypeAd, id)
+		return nil
+	})
+	fs.BoolVar(&parsed.dryRun, "dry-run", false, "Show what would be deleted")
+	alias(fs, "d", "dry-run")
+	fs.BoolVar(&parsed.force, "force", false, "Delete without asking for confirmation")
+	alias(fs, "f", "force")
+	fs.BoolVar(&parsed.mineOnly, "mine", false, "Only delete campaigns created by this tool")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return parsed, err
+	}
+	for _, id := range positional {
+		setTarget(api.ObjectTypeCampaign, id)
+	}
+
+	if targets == 0 || parsed.objectID == "" {
+		return parsed, fmt.Errorf("missing campaign ID")
+	}
+	if targets > 1 {
+		return parsed, fmt.Errorf("give either a campaign ID, --adset or --ad, not several")
+	}
+	return parsed, nil
+}
+
+// deleteObject deletes a campaign, ad set or ad after showing what goes with it
+func deleteObject(cfg *config.Config, args []string) {
+	parsed, err := parseDeleteArgs(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Println(deleteUsage)
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println(deleteUsage)
+		os.Exit(1)
+	}
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create API client
+	client := api.NewClient(authClient, cfg.AccountID)
+
+	// Verify the object exists and collect its children before deleting
+	plan, err := client.PlanDeletion(parsed.objectType, parsed.objectID)
+	if err != nil {
+		fmt.Printf("Error: %s not found or cannot be accessed: %v\n", objectTypeName(parsed.objectType), err)
+		fmt.Println("Please check that the ID is correct and you have permission to access it.")
+		os.Exit(1)
+	}
+
+	if parsed.mineOnly && !internal_campaign.IsOwned(plan.Labels) {
+		fmt.Printf("Error: %s %s was not created by fbads (no %s* ad label); refusing to delete it with --mine\n",
+			objectTypeName(plan.ObjectType), plan.ID, internal_campaign.OwnershipLabelPrefix)
+		os.Exit(1)
+	}
+
+	renderDeletionPlan(os.Stdout, plan)
+	kind := strings.ToLower(objectTypeName(plan.ObjectType))
+
+	if parsed.dryRun {
+		fmt.Println("\nDry run: nothing was deleted")
+		return
+	}
+
+	// Ask for confirmation before proceeding
+	if !parsed.force {
+		fmt.Printf("\nWARNING: This will permanently delete the %s. This action cannot be undone.\n", kind)
+		fmt.Printf("Are you sure you want to delete this %s? (y/n): ", kind)
+		var confirm string
+		fmt.Scanln(&confirm)
+
+		if confirm != "y" && confirm != "Y" && confirm != "yes" && confirm != "Yes" {
+			fmt.Println("Deletion cancelled.")
+			return
+		}
+	}
+
+	fmt.Printf("Deleting %s %s...\n", kind, plan.ID)
+	if err := client.Delete(plan.ObjectType, plan.ID); err != nil {
+		fmt.Printf("Error deleting %s: %v\n", kind, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s %s deleted successfully%s\n", objectTypeName(plan.ObjectType), plan.ID, deletedChildrenSummary(plan))
+}
+
+// deletedChildrenSummary describes the ad sets and ads deleted along with the object
+func deletedChildrenSummary(plan *api.DeletionPlan) string {
+	switch plan.ObjectType {
+	case api.ObjectTypeCampaign:
+		return fmt.Sprintf(", along with %s and %s", countNoun(len(plan.AdSets), "ad set"), countNoun(len(plan.Ads), "ad"))
+	case api.ObjectTypeAdSet:
+		return fmt.Sprintf(", along with %s", countNoun(len(plan.Ads), "ad"))
+	}
+	return ""
+}
+
+// countNoun formats a count followed by the singular or plural form of noun
+func countNoun(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// objectTypeName returns the display name of an object type
+func objectTypeName(objectType string) string {
+	switch objectType {
+	case api.ObjectTypeAdSet:
+		return "Ad set"
+	case api.ObjectTypeAd:
+		return "Ad"
+	}
+	return "Campaign"
+}
+
+// renderDeletionPlan lists the object and the ad sets and ads deleted with it
+func renderDeletionPlan(w io.Writer, plan *api.DeletionPlan) {
+	fmt.Fprintf(w, "%s: %s (%s, Status: %s)\n", objectTypeName(plan.ObjectType), plan.Name, plan.ID, plan.Status)
+
+	if plan.ObjectType == api.ObjectTypeAd {
+		return
+	}
+
+	if plan.ObjectType == api.ObjectTypeCampaign {
+		fmt.Fprintf(w, "\nAd sets deleted with it (%d):\n", len(plan.AdSets))
+		for _, adSet := range plan.AdSets {
+			fmt.Fprintf(w, "  %s (%s, Status: %s)\n", adSet.Name, adSet.ID, adSet.Status)
+		}
+	}
+
+	fmt.Fprintf(w, "\nAds deleted with it (%d):\n", len(plan.Ads))
+	for _, ad := range plan.Ads {
+		fmt.Fprintf(w, "  %s (%s, Status: %s)\n", ad.Name, ad.ID, ad.Status)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestParseDeleteArgs(t *testing.T) {
+	tests := []struct {
+		args       []string
+		objectType string
+		objectID   string
+		dryRun     bool
+		force      bool
+		wantErr    bool
+	}{
+		{args: []string{"101"}, objectType: api.ObjectTypeCampaign, objectID: "101"},
+		{args: []string{"101", "--dry-run", "--mine"}, objectType: api.ObjectTypeCampaign, objectID: "101", dryRun: true},
+		{args: []string{"--adset", "111", "--force"}, objectType: api.ObjectTypeAdSet, objectID: "111", force: true},
+		{args: []string{"--ad=121"}, objectType: api.ObjectTypeAd, objectID: "121"},
+		{args: []string{}, wantErr: true},
+		{args: []string{"--ad="}, wantErr: true},
+		{args: []string{"101", "--ad", "121"}, wantErr: true},
+		{args: []string{"101", "--yes"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		parsed, err := parseDeleteArgs(tt.args)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseDeleteArgs(%v): expected an error", tt.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseDeleteArgs(%v): unexpected error: %v", tt.args, err)
+			continue
+		}
+		if parsed.objectType != tt.objectType || parsed.objectID != tt.objectID || parsed.dryRun != tt.dryRun || parsed.force != tt.force {
+			t.Errorf("parseDeleteArgs(%v) = %+v", tt.args, parsed)
+		}
+	}
+}
+
+func TestRenderDeletionPlan(t *testing.T) {
+	var buf bytes.Buffer
+	renderDeletionPlan(&buf, &api.DeletionPlan{
+		ObjectType: api.ObjectTypeCampaign,
+		ID:         "101",
+		Name:       "Spring",
+		Status:     "ACTIVE",
+		AdSets:     []models.AdSetDetails{{ID: "111", Name: "Broad", Status: "ACTIVE"}},
+		Ads:        []models.AdDetails{{ID: "121", Name: "Hero", Status: "PAUSED"}},
+	})
+
+	output := buf.String()
+	for _, want := range []string{"Campaign: Spring (101", "Ad sets deleted with it (1)", "Broad (111", "Hero (121, Status: PAUSED)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestDeletedChildrenSummary(t *testing.T) {
+	tests := []struct {
+		plan     *api.DeletionPlan
+		expected string
+	}{
+		{
+			plan: &api.DeletionPlan{
+				ObjectType: api.ObjectTypeCampaign,
+				AdSets:     []models.AdSetDetails{{ID: "111"}, {ID: "112"}},
+				Ads:        []models.AdDetails{{ID: "121"}},
+			},
+			expected: ", along with 2 ad sets and 1 ad",
+		},
+		{
+			plan: &api.DeletionPlan{
+				ObjectType: api.ObjectTypeCampaign,
+				AdSets:     []models.AdSetDetails{{ID: "111"}},
+			},
+			expected: ", along with 1 ad set and 0 ads",
+		},
+		{
+			plan:     &api.DeletionPlan{ObjectType: api.ObjectTypeAdSet, Ads: []models.AdDetails{{ID: "121"}}},
+			expected: ", along with 1 ad",
+		},
+		{
+			plan:     &api.DeletionPlan{ObjectType: api.ObjectTypeAdSet, Ads: []models.AdDetails{{ID: "121"}, {ID: "122"}}},
+			expected: ", along with 2 ads",
+		},
+		{
+			plan:     &api.DeletionPlan{ObjectType: api.ObjectTypeAd},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		if summary := deletedChildrenSummary(tt.plan); summary != tt.expected {
+			t.Errorf("deletedChildrenSummary(%s) = %q, expected %q", tt.plan.ObjectType, summary, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/user/fb-ads/internal/api"
+	internal_campaign "github.com/user/fb-ads/internal/campaign"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/internal/optimization"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// handleExperiment handles the experiment subcommands
+func handleExperiment(cfg *config.Config, subCmd string, args []string) {
+	switch subCmd {
+	case "report":
+		experimentReport(cfg, args)
+	case "label":
+		labelExperiment(cfg, args)
+	default:
+		fmt.Printf("Unknown experiment subcommand: %s\n", subCmd)
+		fmt.Println("Available subcommands: report, label")
+		os.Exit(1)
+	}
+}
+
+// experimentReport compares the arms of an experiment across every labeled campaign
+func experimentReport(cfg *config.Config, args []string) {
+	since := "30d"
+	until := ""
+
+	// Handle flags
+	fs := newCommandFlags("experiment report <name> [options]")
+	fs.StringVar(&since, "since", since, "Start of the report: a date (YYYY-MM-DD) or days like 30d")
+	fs.StringVar(&until, "until", "", "End of the report (YYYY-MM-DD, default: yesterday)")
+	name := parseCommandArgs(fs, args, 1, 1)[0]
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+	client := api.NewClient(authClient, cfg.AccountID)
+	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+	clock := metricsCollector.Clock()
+
+	// Today is incomplete, so the report ends yesterday unless told otherwise
+	startDate, err := parseSinceFlag(since, clock.Today())
+	if err != nil {
+		fmt.Printf("Invalid --since value: %v\n", err)
+		os.Exit(1)
+	}
+	timeRange := api.TimeRange{
+		Since: startDate.Format("2006-01-02"),
+		Until: clock.Today().AddDate(0, 0, -1).Format("2006-01-02"),
+	}
+	if until != "" {
+		endDate, err := parseSinceFlag(until, clock.Today())
+		if err != nil {
+			fmt.Printf("Invalid --until value: %v\n", err)
+			os.Exit(1)
+		}
+		timeRange.Until = endDate.Format("2006-01-02")
+	}
+
+	campaigns, err := client.GetAllCampaignsContext(cmdContext)
+	if err != nil {
+		fmt.Printf("Error getting campaigns: %v\n", err)
+		os.Exit(1)
+	}
+
+	arms, err := experimentArms(campaigns, name)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(arms) == 0 {
+		fmt.Printf("No campaigns are labeled for experiment %s. Label them with: fbads experiment label %s <arm> <campaign_id>...\n", name, name)
+		os.Exit(1)
+	}
+
+	ids := make([]string, 0, len(arms))
+	for id := range arms {
+		ids = append(ids, id)
+	}
+	performances, err := metricsCollector.CollectCampaignMetricsContext(cmdContext, api.InsightsRequest{
+		Level:     "campaign",
+		TimeRange: timeRange,
+		Filtering: []api.Filter{
+			{Field: "campaign.id", Operator: "IN", Value: ids},
+		},
+	})
+	if err != nil {
+		fmt.Printf("Error collecting campaign metrics: %v\n", err)
+		os.Exit(1)
+	}
+
+	report := optimization.AnalyzeExperiment(name, experimentCampaigns(campaigns, arms, performances), optimization.DefaultExperimentConfidence)
+
+	fmt.Printf("Experiment %s from %s to %s\n\n", name, timeRange.Since, timeRange.Until)
+	renderExperimentReport(os.Stdout, report)
+}
+
+// experimentArms maps the IDs of the campaigns labeled for an experiment to their arm.
+// A campaign labeled with several arms of the same experiment is an error.
+func experimentArms(campaigns []models.Campaign, experiment string) (map[string]string, error) {
+	arms := make(map[string]string)
+	for _, campaign := range campaigns {
+		labeled := internal_campaign.ExperimentArms(campaign.Labels, experiment)
+		switch {
+		case len(labeled) == 1:
+			arms[campaign.ID] = labeled[0]
+		case len(labeled) > 1:
+			return nil, fmt.Errorf("campaign %s (%s) is labeled with several arms of experiment %s: %s",
+				campaign.Name, campaign.ID, experiment, strings.Join(labeled, ", "))
+		}
+	}
+	return arms, nil
+}
+
+// experimentCampaigns combines the labeled campaigns with their delivery. Campaigns
+// without delivery in the period are included with zeros.
+func experimentCampaigns(campaigns []models.Campaign, arms map[string]string, performances []models.CampaignPerformance) []optimization.ExperimentCampaign {
+	byCampaign := make(map[string]*optimization.ExperimentCampaign)
+	var result []*optimization.ExperimentCampaign
+	for _, campaign := range campaigns {
+		arm, ok := arms[campaign.ID]
+		if !ok {
+			continue
+		}
+		c := &optimization.ExperimentCampaign{CampaignID: campaign.ID, Name: campaign.Name, Arm: arm}
+		byCampaign[campaign.ID] = c
+		result = append(result, c)
+	}
+
+	for _, perf := range performances {
+		c, ok := byCampaign[perf.CampaignID]
+		if !ok {
+			continue
+		}
+		c.Spend += perf.Spend
+		c.Impressions += perf.Impressions
+		c.Clicks += perf.Clicks
+		c.Conversions += perf.Conversions
+	}
+
+	combined := make([]optimization.ExperimentCampaign, 0, len(result))
+	for _, c := range result {
+		combined = append(combined, *c)
+	}
+	return combined
+}
+
+// renderExperimentReport writes the per-arm totals and the verdict
+func renderExperimentReport(w io.Writer, report optimization.ExperimentReport) {
+	fmt.Fprintf(w, "%-16s | %-9s | %-10s | %-11s | %-8s | %-11s | %-9s | %s\n",
+		"ARM", "CAMPAIGNS", "SPEND", "IMPRESSIONS", "CLICKS", "CONVERSIONS", "CPA", "CONV. RATE")
+	for _, arm := range report.Arms {
+		cpa := "-"
+		if arm.CPA > 0 {
+			cpa = fmt.Sprintf("$%.2f", arm.CPA)
+		}
+
+		fmt.Fprintf(w, "%-16s | %-9d | $%-9.2f | %-11d | %-8d | %-11d | %-9s | %.2f%%\n",
+			truncateString(arm.Arm, 16),
+			len(arm.CampaignIDs),
+			arm.Spend,
+			arm.Impressions,
+			arm.Clicks,
+			arm.Conversions,
+			cpa,
+			arm.ConversionRate*100)
+	}
+
+	fmt.Fprintf(w, "\n%s\n", report.Verdict)
+}
+
+// labelExperiment assigns campaigns to an arm of an experiment
+func labelExperiment(cfg *config.Config, args []string) {
+	fs := newCommandFlags("experiment label <name> <arm> <campaign_id>...")
+	positional := parseCommandFlags(fs, args)
+	if len(positional) < 3 {
+		fmt.Println("Missing arguments. Use: fbads experiment label <name> <arm> <campaign_id>...")
+		os.Exit(1)
+	}
+
+	name, arm := positional[0], positional[1]
+	label, err := internal_campaign.ExperimentLabel(name, arm)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var campaignIDs []string
+	for _, arg := range positional[2:] {
+		for _, id := range strings.Split(arg, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				campaignIDs = append(campaignIDs, id)
+			}
+		}
+	}
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+	client := api.NewClient(authClient, cfg.AccountID)
+
+	// A campaign belongs to one arm of an experiment at a time
+	campaigns, err := client.GetAllCampaignsContext(cmdContext)
+	if err != nil {
+		fmt.Printf("Error getting campaigns: %v\n", err)
+		os.Exit(1)
+	}
+	if conflicts := armConflicts(campaigns, campaignIDs, name, arm); len(conflicts) > 0 {
+		for _, conflict := range conflicts {
+			fmt.Printf("Error: %s\n", conflict)
+		}
+		os.Exit(1)
+	}
+
+	creator := newCampaignCreator(authClient, cfg)
+	if err := creator.AttachLabel(label, campaignIDs...); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Labeled %d campaigns with %s\n", len(campaignIDs), label)
+}
+
+// armConflicts describes the campaigns that are unknown or already in another arm of the experiment
+func armConflicts(campaigns []models.Campaign, campaignIDs []string, experiment, arm string) []string {
+	byID := make(map[string]models.Campaign, len(campaigns))
+	for _, campaign := range campaigns {
+		byID[campaign.ID] = campaign
+	}
+
+	var conflicts []string
+	for _, id := range campaignIDs {
+		campaign, ok := byID[id]
+		if !ok {
+			conflicts = append(conflicts, fmt.Sprintf("campaign %s not found", id))
+			continue
+		}
+		for _, existing := range internal_campaign.ExperimentArms(campaign.Labels, experiment) {
+			if existing != arm {
+				conflicts = append(conflicts, fmt.Sprintf("campaign %s (%s) is already in arm %s of experiment %s",
+					campaign.Name, id, existing, experiment))
+			}
+		}
+	}
+	return conflicts
+}
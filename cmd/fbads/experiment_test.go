@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestExperimentArms(t *testing.T) {
+	campaigns := []models.Campaign{
+		{ID: "1", Labels: []string{"spring-sale", "exp:landing-page:a"}},
+		{ID: "2", Labels: []string{"exp:Landing-Page:b"}},
+		{ID: "3", Labels: []string{"exp:other:a"}},
+		{ID: "4"},
+	}
+
+	arms, err := experimentArms(campaigns, "landing-page")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(arms) != 2 || arms["1"] != "a" || arms["2"] != "b" {
+		t.Errorf("Expected campaigns 1 and 2 in arms a and b, got %v", arms)
+	}
+
+	campaigns = append(campaigns, models.Campaign{ID: "5", Labels: []string{"exp:landing-page:a", "exp:landing-page:b"}})
+	if _, err := experimentArms(campaigns, "landing-page"); err == nil {
+		t.Errorf("Expected an error for a campaign in two arms")
+	}
+}
+
+func TestExperimentCampaigns(t *testing.T) {
+	campaigns := []models.Campaign{{ID: "1", Name: "A"}, {ID: "2", Name: "B"}, {ID: "3", Name: "Other"}}
+	arms := map[string]string{"1": "a", "2": "b"}
+	performances := []models.CampaignPerformance{
+		{CampaignID: "1", Spend: 10, Clicks: 100, Conversions: 2},
+		{CampaignID: "1", Spend: 5, Clicks: 50, Conversions: 1},
+		{CampaignID: "3", Spend: 99},
+	}
+
+	combined := experimentCampaigns(campaigns, arms, performances)
+	if len(combined) != 2 {
+		t.Fatalf("Expected two labeled campaigns, got %+v", combined)
+	}
+	if combined[0].Arm != "a" || combined[0].Spend != 15 || combined[0].Clicks != 150 || combined[0].Conversions != 3 {
+		t.Errorf("Expected the rows of campaign 1 to be summed, got %+v", combined[0])
+	}
+	if combined[1].Arm != "b" || combined[1].Spend != 0 {
+		t.Errorf("Expected campaign 2 without delivery to be included with zeros, got %+v", combined[1])
+	}
+}
+
+func TestArmConflicts(t *testing.T) {
+	campaigns := []models.Campaign{
+		{ID: "1", Labels: []string{"exp:landing-page:a"}},
+		{ID: "2"},
+	}
+
+	if conflicts := armConflicts(campaigns, []string{"1", "2"}, "landing-page", "a"); len(conflicts) != 0 {
+		t.Errorf("Expected relabeling with the same arm to be fine, got %v", conflicts)
+	}
+	if conflicts := armConflicts(campaigns, []string{"1", "9"}, "landing-page", "b"); len(conflicts) != 2 {
+		t.Errorf("Expected a conflict for campaign 1 and an unknown campaign 9, got %v", conflicts)
+	}
+}
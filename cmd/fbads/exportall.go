@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/user/fb-ads/internal/api"
+	internal_campaign "github.com/user/fb-ads/internal/campaign"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// defaultExportConcurrency is how many campaigns export --all fetches at once
+const defaultExportConcurrency = 4
+
+// maxExportConcurrency caps the workers of export --all; more only runs into rate limits sooner
+const maxExportConcurrency = 16
+
+// campaignDetailsFetcher is what export --all needs from the Facebook API
+type campaignDetailsFetcher interface {
+	GetCampaignDetailsContext(ctx context.Context, campaignID string) (*models.CampaignDetails, error)
+}
+
+// exportResult is the outcome of exporting one campaign
+type exportResult struct {
+	Campaign  models.Campaign
+	File      string
+	Creatives internal_campaign.CreativeLibrary // Creatives moved out by the structure profile
+	Err       error
+}
+
+// exportAll writes the configuration of every campaign in the account to its own file
+func exportAll(cfg *config.Config, dir string, concurrency int, profile internal_campaign.ExportProfile, format internal_campaign.ConfigFormat, creativeLib string) {
+	if concurrency < 1 || concurrency > maxExportConcurrency {
+		fmt.Printf("Invalid --concurrency value: %d (use 1 to %d)\n", concurrency, maxExportConcurrency)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Printf("Error creating export directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	authClient := newAuthClient(cfg)
+	client := api.NewClient(authClient, cfg.AccountID)
+
+	fmt.Println("Fetching campaigns...")
+	campaigns, err := client.GetAllCampaignsContext(cmdContext)
+	if err != nil {
+		fmt.Printf("Error fetching campaigns: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Exporting %d campaigns to %s with %d workers\n", len(campaigns), dir, concurrency)
+	results := exportCampaigns(cmdContext, client, campaigns, dir, concurrency, profile, format)
+
+	var failed []exportResult
+	creatives := make(internal_campaign.CreativeLibrary)
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+			continue
+		}
+		creatives.Merge(result.Creatives)
+	}
+
+	if profile == internal_campaign.ExportProfileStructure && creativeLib == "" {
+		fmt.Println("Note: creatives were replaced with creative_ref placeholders; use --creative-lib <file> to keep them")
+	} else if profile == internal_campaign.ExportProfileStructure && len(creatives) > 0 {
+		if err := mergeCreativeLibrary(creativeLib, creatives); err != nil {
+			fmt.Printf("Error updating creative library: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Stored %d creatives in: %s\n", len(creatives), creativeLib)
+	}
+
+	fmt.Printf("\nExport completed: %d exported, %d failed\n", len(results)-len(failed), len(failed))
+	for _, result := range failed {
+		fmt.Printf("  %s (%s): %v\n", result.Campaign.ID, result.Campaign.Name, result.Err)
+	}
+	if len(failed) > 0 {
+		os.Exit(1)
+	}
+}
+
+// exportCampaigns fetches the details of the campaigns with up to concurrency requests at
+// a time and writes each configuration to <dir>/<id>_<slug>.json, or .yaml. A failed campaign doesn't
+// stop the others. App rate limits are retried by each request; when the ad account is
+// throttled no further campaigns are started, since every request would fail until the
+// throttle lifts. The results are in the order of the campaigns.
+func exportCampaigns(ctx context.Context, client campaignDetailsFetcher, campaigns []models.Campaign, dir string, concurrency int,
+	profile internal_campaign.ExportProfile, format internal_campaign.ConfigFormat) []exportResult {
+	results := make([]exportResult, len(campaigns))
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					results[i] = notExported(ctx, campaigns[i])
+					continue
+				}
+				results[i] = exportOne(ctx, client, campaigns[i], dir, profile, format)
+
+				var throttle *auth.ThrottleError
+				if errors.As(results[i].Err, &throttle) && throttle.Scope == auth.ThrottleScopeAccount {
+					cancel(throttle)
+				}
+				if results[i].Err == nil {
+					fmt.Printf("  Exported %s (%s)\n", campaigns[i].ID, campaigns[i].Name)
+				}
+			}
+		}()
+	}
+
+	for i, campaign := range campaigns {
+		if ctx.Err() != nil {
+			results[i] = notExported(ctx, campaign)
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// notExported is the result of a campaign skipped because the export was stopped
+func notExported(ctx context.Context, campaign models.Campaign) exportResult {
+	return exportResult{Campaign: campaign, Err: fmt.Errorf("not exported: %w", context.Cause(ctx))}
+}
+
+// exportOne fetches one campaign and writes its configuration
+func exportOne(ctx context.Context, client campaignDetailsFetcher, campaign models.Campaign, dir string,
+	profile internal_campaign.ExportProfile, format internal_campaign.ConfigFormat) exportResult {
+	result := exportResult{Campaign: campaign, File: filepath.Join(dir, campaign.ID+"_"+fileSlug(campaign.Name)+format.Extension())}
+
+	details, err := client.GetCampaignDetailsContext(ctx, campaign.ID)
+	if err != nil {
+		result.Err = fmt.Errorf("error fetching campaign details: %w", err)
+		return result
+	}
+
+	config, creatives := internal_campaign.ApplyExportProfile(internal_campaign.ConfigFromDetails(details), profile)
+	data, err := internal_campaign.MarshalExportConfigFormat(config, profile, format)
+	if err != nil {
+		result.Err = fmt.Errorf("error serializing configuration: %w", err)
+		return result
+	}
+	if err := writeFileAtomic(result.File, data); err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Creatives = creatives
+	return result
+}
+
+// fileSlug reduces a name to lowercase letters, digits and dashes for use in a file name
+func fileSlug(name string) string {
+	slug := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, name)
+	slug = strings.Trim(slug, "-")
+	for strings.Contains(slug, "--") {
+		slug = strings.ReplaceAll(slug, "--", "-")
+	}
+	if slug == "" {
+		slug = "unnamed"
+	}
+	return slug
+}
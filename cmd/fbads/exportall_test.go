@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	internal_campaign "github.com/user/fb-ads/internal/campaign"
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// fakeDetailsFetcher returns campaign details or the error set for a campaign
+type fakeDetailsFetcher struct {
+	mu      sync.Mutex
+	errs    map[string]error
+	fetched []string
+}
+
+func (f *fakeDetailsFetcher) GetCampaignDetailsContext(ctx context.Context, campaignID string) (*models.CampaignDetails, error) {
+	f.mu.Lock()
+	f.fetched = append(f.fetched, campaignID)
+	f.mu.Unlock()
+
+	if err := f.errs[campaignID]; err != nil {
+		return nil, err
+	}
+	return &models.CampaignDetails{
+		ID: campaignID, Name: "Campaign " + campaignID, Status: "PAUSED", ObjectiveType: "OUTCOME_TRAFFIC",
+	}, nil
+}
+
+func TestFileSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Summer Sale 2024", "summer-sale-2024"},
+		{"  Black/Friday -- US  ", "black-friday-us"},
+		{"Café Ünïcode", "café-ünïcode"},
+		{"???", "unnamed"},
+		{"", "unnamed"},
+	}
+
+	for _, tt := range tests {
+		if got := fileSlug(tt.name); got != tt.want {
+			t.Errorf("fileSlug(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExportCampaigns(t *testing.T) {
+	dir := t.TempDir()
+	campaigns := []models.Campaign{
+		{ID: "1", Name: "Summer Sale"},
+		{ID: "2", Name: "Broken"},
+		{ID: "3", Name: "Winter Sale"},
+		{ID: "4", Name: ""},
+	}
+	fetcher := &fakeDetailsFetcher{errs: map[string]error{"2": errors.New("campaign not found")}}
+
+	results := exportCampaigns(context.Background(), fetcher, campaigns, dir, 3, internal_campaign.ExportProfileFull, internal_campaign.ConfigFormatJSON)
+	if len(results) != len(campaigns) {
+		t.Fatalf("got %d results, want %d", len(results), len(campaigns))
+	}
+
+	wantFiles := []string{"1_summer-sale.json", "", "3_winter-sale.json", "4_unnamed.json"}
+	for i, result := range results {
+		if result.Campaign.ID != campaigns[i].ID {
+			t.Errorf("result %d is for campaign %s, want %s", i, result.Campaign.ID, campaigns[i].ID)
+		}
+		if wantFiles[i] == "" {
+			if result.Err == nil {
+				t.Errorf("campaign %s: expected an error", result.Campaign.ID)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Errorf("campaign %s: unexpected error: %v", result.Campaign.ID, result.Err)
+			continue
+		}
+		if result.File != filepath.Join(dir, wantFiles[i]) {
+			t.Errorf("campaign %s written to %s, want %s", result.Campaign.ID, result.File, wantFiles[i])
+		}
+		if _, err := os.Stat(result.File); err != nil {
+			t.Errorf("campaign %s: %v", result.Campaign.ID, err)
+		}
+	}
+}
+
+func TestExportCampaigns_AccountThrottle(t *testing.T) {
+	throttle := &auth.ThrottleError{
+		AccountID: "123",
+		Scope:     auth.ThrottleScopeAccount,
+		Until:     time.Now().Add(5 * time.Minute),
+	}
+	campaigns := []models.Campaign{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+	fetcher := &fakeDetailsFetcher{errs: map[string]error{"1": throttle}}
+
+	// One worker, so the throttle is seen before any other campaign is started
+	results := exportCampaigns(context.Background(), fetcher, campaigns, t.TempDir(), 1, internal_campaign.ExportProfileFull, internal_campaign.ConfigFormatJSON)
+
+	for _, result := range results {
+		if !errors.Is(result.Err, throttle) {
+			t.Errorf("campaign %s: error = %v, want the account throttle", result.Campaign.ID, result.Err)
+		}
+	}
+	if len(fetcher.fetched) != 1 {
+		t.Errorf("fetched %v after the account was throttled, want only the first campaign", fetcher.fetched)
+	}
+}
+
+func TestExportCampaigns_YAML(t *testing.T) {
+	campaigns := []models.Campaign{{ID: "1", Name: "Summer Sale"}}
+	results := exportCampaigns(context.Background(), &fakeDetailsFetcher{}, campaigns, t.TempDir(), 1,
+		internal_campaign.ExportProfileFull, internal_campaign.ConfigFormatYAML)
+
+	if results[0].Err != nil {
+		t.Fatal(results[0].Err)
+	}
+	if filepath.Base(results[0].File) != "1_summer-sale.yaml" {
+		t.Errorf("Expected 1_summer-sale.yaml, got %s", results[0].File)
+	}
+	data, err := os.ReadFile(results[0].File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := internal_campaign.ParseCampaignConfig(data, internal_campaign.ConfigFormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "Campaign 1" {
+		t.Errorf("Expected the exported campaign, got %q", config.Name)
+	}
+}
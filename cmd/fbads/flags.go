@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// shorthandPrefix starts the usage of flags registered with alias, so help lists them
+// next to their long name
+const shorthandPrefix = "Shorthand for --"
+
+// newCommandFlags returns the flag set of a command. usage is the synopsis shown by
+// --help and after a parse error, e.g. "list [options]".
+// Flags can be given as --name value or --name=value.
+func newCommandFlags(usage string) *flag.FlagSet {
+	name := strings.Fields(usage)[0]
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stdout)
+	fs.Usage = func() { printCommandUsage(fs.Output(), fs, usage) }
+	return fs
+}
+
+// alias registers short as another name of the already defined flag long
+func alias(fs *flag.FlagSet, short, long string) {
+	fs.Var(fs.Lookup(long).Value, short, shorthandPrefix+long)
+}
+
+// parseCommandFlags parses the arguments of a command and returns its positional arguments.
+// Positional arguments may appear between flags, e.g. "export <id> out.json --profile slim".
+// --help prints the command usage and exits; an unknown flag or invalid value exits with
+// status 1 after printing the error and the usage.
+func parseCommandFlags(fs *flag.FlagSet, args []string) []string {
+	positional, err := parseInterspersed(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+	return positional
+}
+
+// parseCommandArgs parses the arguments of a command taking between min and max positional
+// arguments. With too few or too many the usage is printed and the command exits.
+func parseCommandArgs(fs *flag.FlagSet, args []string, min, max int) []string {
+	positional := parseCommandFlags(fs, args)
+	if len(positional) < min || len(positional) > max {
+		if len(positional) > max {
+			fmt.Fprintf(fs.Output(), "Unexpected argument: %s\n", positional[max])
+		} else {
+			fmt.Fprintln(fs.Output(), "Missing arguments")
+		}
+		fs.Usage()
+		os.Exit(1)
+	}
+	return positional
+}
+
+// mustParseSettableStatus returns the campaign status given with a flag in its canonical
+// form, or exits. Statuses are validated before anything is created, so a typo doesn't
+// create campaigns with the default status.
+func mustParseSettableStatus(value string) string {
+	status, err := models.ParseSettableCampaignStatus(value)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return string(status)
+}
+
+// parseInterspersed parses flags anywhere in args. Everything after "--" is positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		rest := fs.Args()
+		consumed := len(args) - len(rest)
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// printCommandUsage writes the synopsis of a command and its flags, with shorthands
+// listed next to their long names
+func printCommandUsage(w io.Writer, fs *flag.FlagSet, usage string) {
+	fmt.Fprintf(w, "Usage: fbads %s\n", usage)
+
+	shorthands := make(map[string][]string)
+	var flags []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) {
+		if long, ok := strings.CutPrefix(f.Usage, shorthandPrefix); ok {
+			shorthands[long] = append(shorthands[long], "-"+f.Name)
+			return
+		}
+		flags = append(flags, f)
+	})
+	if len(flags) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\nOptions:")
+	for _, f := range flags {
+		names := strings.Join(append([]string{"--" + f.Name}, shorthands[f.Name]...), ", ")
+		if !isBoolFlag(f) {
+			names += " <value>"
+		}
+
+		help := f.Usage
+		if f.DefValue != "" && f.DefValue != "0" && !isBoolFlag(f) {
+			help += fmt.Sprintf(" (default: %s)", f.DefValue)
+		}
+		fmt.Fprintf(w, "  %-28s %s\n", names, help)
+	}
+}
+
+// isBoolFlag reports whether a flag takes no value
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseInterspersed(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		limit      int
+		format     string
+		dryRun     bool
+		positional []string
+		wantErr    bool
+	}{
+		{name: "separate values", args: []string{"--limit", "5", "-f", "json"}, limit: 5, format: "json"},
+		{name: "equals form", args: []string{"--limit=5", "--format=csv"}, limit: 5, format: "csv"},
+		{name: "positional between flags", args: []string{"123", "--dry-run", "out.json", "-l=2"}, limit: 2, format: "table", dryRun: true, positional: []string{"123", "out.json"}},
+		{name: "after double dash", args: []string{"--", "--limit"}, limit: 10, format: "table", positional: []string{"--limit"}},
+		{name: "unknown flag", args: []string{"--yes"}, wantErr: true},
+		{name: "invalid number", args: []string{"--limit", "five"}, wantErr: true},
+		{name: "missing value", args: []string{"--limit"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var limit int
+			var format string
+			var dryRun bool
+			fs := newCommandFlags("test [options]")
+			fs.SetOutput(io.Discard)
+			fs.IntVar(&limit, "limit", 10, "Limit")
+			alias(fs, "l", "limit")
+			fs.StringVar(&format, "format", "table", "Format")
+			alias(fs, "f", "format")
+			fs.BoolVar(&dryRun, "dry-run", false, "Dry run")
+
+			positional, err := parseInterspersed(fs, tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error for %v", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if limit != tt.limit || format != tt.format || dryRun != tt.dryRun {
+				t.Errorf("Unexpected flags: limit=%d format=%s dry-run=%v", limit, format, dryRun)
+			}
+			if !reflect.DeepEqual(positional, tt.positional) {
+				t.Errorf("Expected positional arguments %v, got %v", tt.positional, positional)
+			}
+		})
+	}
+}
+
+func TestPrintCommandUsage(t *testing.T) {
+	var format string
+	fs := newCommandFlags("adsets <campaign_id> [options]")
+	fs.StringVar(&format, "format", "table", "Output format")
+	alias(fs, "f", "format")
+	fs.Bool("with-metrics", false, "Add metrics")
+
+	var buf bytes.Buffer
+	printCommandUsage(&buf, fs, "adsets <campaign_id> [options]")
+	out := buf.String()
+
+	for _, expected := range []string{
+		"Usage: fbads adsets <campaign_id> [options]",
+		"--format, -f <value>",
+		"Output format (default: table)",
+		"--with-metrics",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected usage to contain %q, got:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out, "Shorthand") {
+		t.Errorf("Expected shorthands to be listed with their long names, got:\n%s", out)
+	}
+}
+
+func TestParseGlobalFlags(t *testing.T) {
+	t.Cleanup(func() {
+		accountOverride, configOverride, profileOverride, demoMode, noNormalize = "", "", "", false, false
+	})
+
+	args, err := parseGlobalFlags([]string{"fbads", "--account", "act_42", "list", "--config=/tmp/fbads.json", "--no-normalize", "--profile=work", "--limit", "5"})
+	if err != nil {
+		t.Fatalf("parseGlobalFlags failed: %v", err)
+	}
+	if !reflect.DeepEqual(args, []string{"fbads", "list", "--limit", "5"}) {
+		t.Errorf("Expected the global flags to be removed, got %v", args)
+	}
+	if accountOverride != "act_42" || configOverride != "/tmp/fbads.json" || profileOverride != "work" {
+		t.Errorf("Unexpected overrides: account=%q config=%q profile=%q", accountOverride, configOverride, profileOverride)
+	}
+	if !noNormalize {
+		t.Error("Expected --no-normalize to turn off objective normalization")
+	}
+
+	args, err = parseGlobalFlags([]string{"fbads", "export", "--", "--account", "act_7"})
+	if err != nil {
+		t.Fatalf("parseGlobalFlags failed: %v", err)
+	}
+	if !reflect.DeepEqual(args, []string{"fbads", "export", "--", "--account", "act_7"}) || accountOverride != "act_42" {
+		t.Errorf("Expected arguments after -- to be left to the command, got %v", args)
+	}
+
+	// Export and backup have their own --profile flag
+	args, err = parseGlobalFlags([]string{"fbads", "--profile", "work", "export", "120200000000101", "out.json", "--profile", "slim", "--demo"})
+	if err != nil {
+		t.Fatalf("parseGlobalFlags failed: %v", err)
+	}
+	if !reflect.DeepEqual(args, []string{"fbads", "export", "120200000000101", "out.json", "--profile", "slim"}) || profileOverride != "work" || !demoMode {
+		t.Errorf("Expected export --profile to be left to the command, got %v with profile %q", args, profileOverride)
+	}
+	args, err = parseGlobalFlags([]string{"fbads", "backup", "dir", "--profile=structure"})
+	if err != nil {
+		t.Fatalf("parseGlobalFlags failed: %v", err)
+	}
+	if !reflect.DeepEqual(args, []string{"fbads", "backup", "dir", "--profile=structure"}) || profileOverride != "work" {
+		t.Errorf("Expected backup --profile to be left to the command, got %v with profile %q", args, profileOverride)
+	}
+
+	// --mock is the old name of --demo
+	demoMode = false
+	args, err = parseGlobalFlags([]string{"fbads", "list", "--mock"})
+	if err != nil {
+		t.Fatalf("parseGlobalFlags failed: %v", err)
+	}
+	if !reflect.DeepEqual(args, []string{"fbads", "list"}) || !demoMode {
+		t.Errorf("Expected --mock to turn on demo mode, got %v with demo %v", args, demoMode)
+	}
+
+	for _, args := range [][]string{{"fbads", "list", "--account"}, {"fbads", "--config=", "list"}, {"fbads", "--demo=maybe", "list"}} {
+		if _, err := parseGlobalFlags(args); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+)
+
+// insightsTableWidth is the widest a column of the insights table gets
+const insightsTableWidth = 40
+
+// insightsReport prints raw insights rows at a level, optionally broken down by age, gender,
+// country and the like
+func insightsReport(cfg *config.Config, args []string) {
+	level := "campaign"
+	since := "30d"
+	until := ""
+	breakdown := ""
+	fields := ""
+	format := "table"
+	outputFile := ""
+
+	// Handle flags
+	fs := newCommandFlags("insights [options]")
+	fs.StringVar(&level, "level", level, "Level of the rows: "+strings.Join(api.InsightsLevels, ", "))
+	fs.StringVar(&since, "since", since, "Start of the period: a date (YYYY-MM-DD) or days like 30d")
+	fs.StringVar(&until, "until", "", "End of the period (YYYY-MM-DD, default: yesterday)")
+	fs.StringVar(&breakdown, "breakdown", "", "Comma-separated breakdowns, e.g. age,gender")
+	fs.StringVar(&fields, "fields", "", "Comma-separated insights fields (default: the performance fields)")
+	fs.StringVar(&format, "format", format, "Output format: table, csv or json")
+	alias(fs, "f", "format")
+	fs.StringVar(&outputFile, "output", "", "Write the rows to a file")
+	alias(fs, "o", "output")
+	parseCommandArgs(fs, args, 0, 0)
+
+	validLevel := false
+	for _, l := range api.InsightsLevels {
+		validLevel = validLevel || level == l
+	}
+	if !validLevel {
+		fmt.Printf("Unsupported level: %s (use %s)\n", level, strings.Join(api.InsightsLevels, ", "))
+		os.Exit(1)
+	}
+	if format != "table" && format != "csv" && format != "json" {
+		fmt.Printf("Unsupported format: %s (use table, csv or json)\n", format)
+		os.Exit(1)
+	}
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create metrics collector
+	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+	clock := metricsCollector.Clock()
+
+	// Today is incomplete, so the period ends yesterday unless told otherwise
+	startDate, err := parseSinceFlag(since, clock.Today())
+	if err != nil {
+		fmt.Printf("Invalid --since value: %v\n", err)
+		os.Exit(1)
+	}
+	endDate := clock.Yesterday()
+	if until != "" {
+		endDate, err = parseSinceFlag(until, clock.Today())
+		if err != nil {
+			fmt.Printf("Invalid --until value: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if endDate.Before(startDate) {
+		fmt.Println("End date must not be before start date")
+		os.Exit(1)
+	}
+
+	request := api.InsightsRequest{
+		Level: level,
+		TimeRange: api.TimeRange{
+			Since: startDate.Format("2006-01-02"),
+			Until: endDate.Format("2006-01-02"),
+		},
+		Fields:         splitList(fields),
+		BreakdownsType: strings.Join(splitList(breakdown), ","),
+	}
+
+	out := io.Writer(os.Stdout)
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			fmt.Printf("Error creating output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	columns := api.InsightsColumns(request)
+	count := 0
+	if format == "table" {
+		// The table sizes its columns to the widest value, so it needs every row first
+		rows, err := metricsCollector.CollectInsightsContext(cmdContext, request)
+		if err != nil {
+			fmt.Printf("Error collecting insights: %v\n", err)
+			os.Exit(1)
+		}
+		writeInsightsTable(out, columns, rows)
+		count = len(rows)
+	} else {
+		// CSV and JSON are written page by page, so large accounts are never held in memory
+		stream := newInsightsStreamWriter(out, format, columns)
+		err := metricsCollector.StreamInsightsContext(cmdContext, request, stream.WritePage)
+		if err == nil {
+			err = stream.Close()
+		}
+		if err != nil {
+			fmt.Printf("Error collecting insights: %v\n", err)
+			os.Exit(1)
+		}
+		count = stream.rows
+	}
+
+	if outputFile != "" {
+		fmt.Printf("%d rows saved to: %s\n", count, outputFile)
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// writeInsightsCSV writes the rows as CSV with a header of the columns
+func writeInsightsCSV(w io.Writer, columns []string, rows []api.InsightsRow) {
+	fmt.Fprintln(w, strings.Join(columns, ","))
+	writeInsightsCSVRows(w, columns, rows)
+}
+
+// writeInsightsCSVRows writes the rows as CSV lines without a header
+func writeInsightsCSVRows(w io.Writer, columns []string, rows []api.InsightsRow) {
+	values := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			values[i] = escapeCSV(row.Value(column))
+		}
+		fmt.Fprintln(w, strings.Join(values, ","))
+	}
+}
+
+// insightsStreamWriter writes insights rows as CSV or as a JSON array one page at a time,
+// flushing after every page
+type insightsStreamWriter struct {
+	out     *bufio.Writer
+	format  string
+	columns []string
+	rows    int
+	started bool
+}
+
+// newInsightsStreamWriter returns a writer of the csv or json format
+func newInsightsStreamWriter(w io.Writer, format string, columns []string) *insightsStreamWriter {
+	return &insightsStreamWriter{out: bufio.NewWriter(w), format: format, columns: columns}
+}
+
+// start writes the CSV header or opens the JSON array
+func (s *insightsStreamWriter) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+	if s.format == "csv" {
+		fmt.Fprintln(s.out, strings.Join(s.columns, ","))
+	} else {
+		s.out.WriteString("[")
+	}
+}
+
+// WritePage writes the rows of one page and flushes them
+func (s *insightsStreamWriter) WritePage(page []api.InsightsRow) error {
+	s.start()
+	if s.format == "csv" {
+		writeInsightsCSVRows(s.out, s.columns, page)
+		s.rows += len(page)
+		return s.out.Flush()
+	}
+
+	for _, row := range page {
+		data, err := json.MarshalIndent(row, "  ", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding insights row: %w", err)
+		}
+		if s.rows > 0 {
+			s.out.WriteString(",")
+		}
+		s.out.WriteString("\n  ")
+		s.out.Write(data)
+		s.rows++
+	}
+	return s.out.Flush()
+}
+
+// Close ends the JSON array and flushes the output. It doesn't close the underlying writer.
+func (s *insightsStreamWriter) Close() error {
+	s.start()
+	if s.format == "json" {
+		if s.rows > 0 {
+			s.out.WriteString("\n")
+		}
+		s.out.WriteString("]\n")
+	}
+	return s.out.Flush()
+}
+
+// writeInsightsTable writes the rows as a table, truncating long values such as action lists
+func writeInsightsTable(w io.Writer, columns []string, rows []api.InsightsRow) {
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "No insights found for the specified date range.")
+		return
+	}
+
+	// Calculate column widths
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = len(column)
+		for _, row := range rows {
+			if n := len(row.Value(column)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+		if widths[i] > insightsTableWidth {
+			widths[i] = insightsTableWidth
+		}
+	}
+
+	cells := make([]string, len(columns))
+	separators := make([]string, len(columns))
+	for i, column := range columns {
+		cells[i] = fmt.Sprintf("%-*s", widths[i], strings.ToUpper(column))
+		separators[i] = strings.Repeat("-", widths[i])
+	}
+	fmt.Fprintln(w, strings.Join(cells, " | "))
+	fmt.Fprintln(w, strings.Join(separators, "-+-"))
+
+	for _, row := range rows {
+		for i, column := range columns {
+			cells[i] = fmt.Sprintf("%-*s", widths[i], truncateString(row.Value(column), widths[i]))
+		}
+		fmt.Fprintln(w, strings.Join(cells, " | "))
+	}
+
+	fmt.Fprintf(w, "\nTotal: %d rows\n", len(rows))
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/internal/api"
+)
+
+func TestWriteInsightsCSV(t *testing.T) {
+	rows := []api.InsightsRow{
+		{"date_start": "2024-01-01", "ad_name": "Hero, blue", "spend": "12.50",
+			"actions": []interface{}{map[string]interface{}{"action_type": "link_click", "value": "4"}}},
+		{"date_start": "2024-01-01", "ad_name": "Lifestyle", "spend": "3"},
+	}
+
+	var buf bytes.Buffer
+	writeInsightsCSV(&buf, []string{"date_start", "ad_name", "spend", "actions"}, rows)
+
+	expected := "date_start,ad_name,spend,actions\n" +
+		`2024-01-01,"Hero, blue",12.50,"[{""action_type"":""link_click"",""value"":""4""}]"` + "\n" +
+		"2024-01-01,Lifestyle,3,\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected CSV:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	if got := strings.Join(splitList(" age, gender,,"), "|"); got != "age|gender" {
+		t.Errorf("Expected age|gender, got %s", got)
+	}
+	if splitList("") != nil {
+		t.Errorf("Expected no items for an empty value")
+	}
+}
+
+func TestInsightsStreamWriter(t *testing.T) {
+	columns := []string{"date_start", "ad_name", "spend"}
+	pages := [][]api.InsightsRow{
+		{{"date_start": "2024-01-01", "ad_name": "Hero, blue", "spend": "12.50"}},
+		{{"date_start": "2024-01-01", "ad_name": "Lifestyle", "spend": "3"}, {"date_start": "2024-01-02", "ad_name": "Lifestyle", "spend": "4"}},
+	}
+
+	// Each page is flushed before the next one is fetched
+	var buf bytes.Buffer
+	stream := newInsightsStreamWriter(&buf, "csv", columns)
+	if err := stream.WritePage(pages[0]); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "date_start,ad_name,spend\n2024-01-01,\"Hero, blue\",12.50\n"; buf.String() != expected {
+		t.Errorf("Expected the first page to be written, got:\n%s", buf.String())
+	}
+	if err := stream.WritePage(pages[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var expected bytes.Buffer
+	writeInsightsCSV(&expected, columns, append(pages[0], pages[1]...))
+	if buf.String() != expected.String() || stream.rows != 3 {
+		t.Errorf("Unexpected CSV (%d rows):\n%s\nexpected:\n%s", stream.rows, buf.String(), expected.String())
+	}
+
+	// The JSON pages form one array, the same as encoding all rows at once
+	for _, pageSets := range [][][]api.InsightsRow{pages, nil} {
+		buf.Reset()
+		stream = newInsightsStreamWriter(&buf, "json", columns)
+		var all []api.InsightsRow
+		for _, page := range pageSets {
+			if err := stream.WritePage(page); err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, page...)
+		}
+		if err := stream.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		var rows []api.InsightsRow
+		if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+			t.Fatalf("Expected a JSON array, got %v:\n%s", err, buf.String())
+		}
+		if len(rows) != len(all) {
+			t.Errorf("Expected %d rows, got %d", len(all), len(rows))
+		}
+		if len(all) > 0 {
+			encoded, _ := json.MarshalIndent(all, "", "  ")
+			if buf.String() != string(encoded)+"\n" {
+				t.Errorf("Expected the indented array:\n%s\ngot:\n%s", encoded, buf.String())
+			}
+		}
+	}
+}
@@ -3,26 +3,78 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/user/fb-ads/internal/api"
 	"github.com/user/fb-ads/internal/audience"
 	internal_campaign "github.com/user/fb-ads/internal/campaign"
 	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/internal/demo"
 	"github.com/user/fb-ads/internal/optimization"
 	"github.com/user/fb-ads/pkg/auth"
 	"github.com/user/fb-ads/pkg/models"
 	"github.com/user/fb-ads/pkg/utils"
 )
 
+// demoMode answers every API request from the sample account, set with the global
+// --demo flag or FBADS_DEMO=1
+var demoMode bool
+
+// accountOverride is the ad account set with the global --account flag; it takes
+// precedence over the configuration file
+var accountOverride string
+
+// configOverride is the configuration file set with the global --config flag
+var configOverride string
+
+// profileOverride is the configuration profile set with the global --profile flag
+var profileOverride string
+
+// noNormalize sends campaign objectives as configured instead of translating legacy
+// objectives to outcome-based ones, set with the global --no-normalize flag
+var noNormalize bool
+
+// demoProvider is the sample account shared by all API clients of the process
+var demoProvider *demo.Provider
+
+// cmdContext is cancelled on Ctrl-C, so long-running commands abort the API call in
+// flight and stop paginating
+var cmdContext = context.Background()
+
 func main() {
-	fmt.Println("Facebook Ads Manager CLI")
-	fmt.Println("------------------------")
+	// The banner goes to stderr, so the output of commands such as --format json can be piped
+	fmt.Fprintln(os.Stderr, "Facebook Ads Manager CLI")
+	fmt.Fprintln(os.Stderr, "------------------------")
+
+	// Strip global flags so commands only see their own arguments
+	args, err := parseGlobalFlags(os.Args)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		printUsage()
+		os.Exit(1)
+	}
+	os.Args = args
+
+	// The first Ctrl-C cancels the command; a second one terminates immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	cmdContext = ctx
 
 	if len(os.Args) < 2 {
 		printUsage()
@@ -38,6 +90,9 @@ func main() {
 
 	// Set default config path
 	configPath := filepath.Join(homeDir, ".fbads", "config.json")
+	if configOverride != "" {
+		configPath = configOverride
+	}
 
 	// Load configuration
 	cfg, err := config.LoadConfig(configPath)
@@ -46,6 +101,26 @@ func main() {
 		fmt.Println("Using default configuration...")
 		cfg = config.DefaultConfig()
 	}
+	// A config file given explicitly must exist, except for the config command that creates it
+	editsConfig := os.Args[1] == "config" && (len(os.Args) < 3 || os.Args[2] != "test")
+	if os.IsNotExist(err) && configOverride != "" && !editsConfig {
+		fmt.Printf("Configuration file not found: %s\n", configPath)
+		os.Exit(1)
+	}
+	profile := profileOverride
+	if profile == "" {
+		profile = os.Getenv("FBADS_PROFILE")
+	}
+	if err := cfg.UseProfile(profile); err != nil && !editsConfig {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if accountOverride != "" {
+		cfg.AccountID = strings.TrimPrefix(accountOverride, "act_")
+	}
+	if demoMode && cfg.AccountID == "" {
+		cfg.AccountID = demo.AccountID
+	}
 
 	// Process commands
 	cmd := os.Args[1]
@@ -53,56 +128,98 @@ func main() {
 	switch cmd {
 	case "list":
 		listCampaigns(cfg)
+	case "adsets":
+		listAdSets(cfg, os.Args[2:])
 	case "create":
 		createCampaign(cfg)
 	case "update":
 		updateCampaign(cfg)
 	case "delete":
+		deleteObject(cfg, os.Args[2:])
+	case "status":
+		campaignStatus(cfg, os.Args[2:])
+	case "activate":
+		activateCampaign(cfg, os.Args[2:])
+	case "pause":
+		pauseCampaigns(cfg, os.Args[2:])
+	case "resume":
+		resumeCampaigns(cfg, os.Args[2:])
+	case "bulk-status":
+		bulkStatus(cfg, os.Args[2:])
+	case "schedule":
 		if len(os.Args) < 3 {
-			fmt.Println("Missing campaign ID. Use: fbads delete <campaign_id>")
+			fmt.Println("Missing schedule subcommand. Use: fbads schedule [list|run|daemon]")
 			os.Exit(1)
 		}
-		deleteCampaign(cfg, os.Args[2])
+		handleSchedule(cfg, os.Args[2], os.Args[3:])
 	case "duplicate":
-		if len(os.Args) < 3 {
-			fmt.Println("Missing campaign ID. Use: fbads duplicate <campaign_id> [options]")
-			os.Exit(1)
-		}
-		duplicateCampaign(cfg, os.Args[2], os.Args[3:])
+		duplicateCampaign(cfg, os.Args[2:])
 	case "export":
-		if len(os.Args) < 3 {
-			fmt.Println("Missing campaign ID. Use: fbads export <campaign_id> [output_file]")
-			os.Exit(1)
-		}
-		exportCampaign(cfg, os.Args[2], os.Args[3:])
+		exportCampaign(cfg, os.Args[2:])
 	case "exportyaml":
+		exportCampaignYAML(cfg, os.Args[2:])
+	case "split-geo":
+		splitGeo(cfg, os.Args[2:])
+	case "backup":
+		backupAccount(cfg, os.Args[2:])
+	case "restore":
+		restoreAccount(cfg, os.Args[2:])
+	case "pages":
+		listPages(cfg)
+	case "account":
 		if len(os.Args) < 3 {
-			fmt.Println("Missing campaign ID. Use: fbads exportyaml <campaign_id> [output_file] [options]")
+			fmt.Println("Missing account subcommand. Use: fbads account [status]")
 			os.Exit(1)
 		}
-		exportCampaignYAML(cfg, os.Args[2], os.Args[3:])
-	case "pages":
-		listPages(cfg)
+		handleAccount(cfg, os.Args[2])
+	case "activity":
+		activityFeed(cfg, os.Args[2:])
+	case "insights":
+		insightsReport(cfg, os.Args[2:])
 	case "audience":
 		analyzeAudience(cfg)
 	case "stats":
 		if len(os.Args) < 3 {
-			fmt.Println("Missing stats subcommand. Use: fbads stats [collect|analyze|export]")
+			fmt.Println("Missing stats subcommand. Use: fbads stats [collect|analyze|export|insights|validate|backfill]")
 			os.Exit(1)
 		}
 		handleStatistics(cfg, os.Args[2], os.Args[3:])
+	case "collect":
+		collectDaemon(cfg, os.Args[2:])
+	case "creatives":
+		if len(os.Args) < 3 {
+			fmt.Println("Missing creatives subcommand. Use: fbads creatives [list]")
+			os.Exit(1)
+		}
+		handleCreatives(cfg, os.Args[2], os.Args[3:])
 	case "report":
 		if len(os.Args) < 3 {
-			fmt.Println("Missing report type. Use: fbads report [daily|weekly|monthly|custom]")
+			fmt.Println("Missing report type. Use: fbads report [daily|weekly|custom|creatives|explain-recommendations]")
 			os.Exit(1)
 		}
 		generateReport(cfg, os.Args[2], os.Args[3:])
+	case "experiment":
+		if len(os.Args) < 3 {
+			fmt.Println("Missing experiment subcommand. Use: fbads experiment [report|label]")
+			os.Exit(1)
+		}
+		handleExperiment(cfg, os.Args[2], os.Args[3:])
 	case "optimize":
 		optimizeCampaigns(cfg)
 	case "dashboard":
 		startDashboard(cfg)
+	case "serve":
+		serveAPI(cfg, os.Args[2:])
 	case "config":
-		configureApp(cfg, configPath)
+		if len(os.Args) > 2 && os.Args[2] == "test" {
+			testConfiguration(cfg)
+			break
+		}
+		if len(os.Args) > 2 && (os.Args[2] == "list" || os.Args[2] == "list-profiles") {
+			listProfiles(configPath)
+			break
+		}
+		configureApp(profile, configPath)
 	case "help":
 		printUsage()
 	default:
@@ -112,51 +229,149 @@ func main() {
 	}
 }
 
-func listCampaigns(cfg *config.Config) {
-	// Parse flags
-	var (
-		limit  int
-		status string
-		format string
-	)
+// newGlobalFlags returns the flag set of the global flags, which may appear anywhere
+// on the command line
+func newGlobalFlags() *flag.FlagSet {
+	fs := flag.NewFlagSet("fbads", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&demoMode, "demo", demoMode, "Answer every API request from the sample account")
+	// --mock was the name of demo mode before the sample account; scripts still use it
+	fs.Var(fs.Lookup("demo").Value, "mock", "Alias of --demo")
+	fs.BoolVar(&noNormalize, "no-normalize", noNormalize, "Send campaign objectives as configured")
+	fs.StringVar(&accountOverride, "account", accountOverride, "Ad account to use instead of the configured one")
+	fs.StringVar(&configOverride, "config", configOverride, "Configuration file to use")
+	fs.StringVar(&profileOverride, "profile", profileOverride, "Configuration profile to use")
+	return fs
+}
+
+// commandOwnFlags lists the flags of commands that share a name with a global flag.
+// After the name of such a command, the flag belongs to the command, so the global
+// flag has to be given before it, e.g. "fbads --profile work export 123 out.json --profile slim".
+var commandOwnFlags = map[string][]string{
+	"export": {"profile"},
+	"backup": {"profile"},
+}
 
-	// Check for flags
-	args := os.Args[2:]
+// parseGlobalFlags removes global flags from the argument list and applies them.
+// Global flags are parsed by the flag package, so --name value and --name=value are
+// both accepted; arguments after "--" and flags of the command in commandOwnFlags
+// are left to the command.
+func parseGlobalFlags(args []string) ([]string, error) {
+	fs := newGlobalFlags()
+	filtered := make([]string, 0, len(args))
+	command := ""
 	for i := 0; i < len(args); i++ {
-		switch args[i] {
-		case "--limit", "-l":
-			if i+1 < len(args) {
-				fmt.Sscanf(args[i+1], "%d", &limit)
-				i++
-			}
-		case "--status", "-s":
-			if i+1 < len(args) {
-				status = args[i+1]
-				i++
-			}
-		case "--format", "-f":
-			if i+1 < len(args) {
-				format = args[i+1]
-				i++
+		if args[i] == "--" {
+			filtered = append(filtered, args[i:]...)
+			break
+		}
+
+		name, _, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		f := fs.Lookup(name)
+		if f == nil || !strings.HasPrefix(args[i], "-") || isCommandFlag(command, name) {
+			if command == "" && i > 0 && !strings.HasPrefix(args[i], "-") {
+				command = args[i]
 			}
+			filtered = append(filtered, args[i])
+			continue
 		}
-	}
 
-	// Set defaults
-	if limit <= 0 {
-		limit = 10
+		// A value given as a separate argument belongs to the flag
+		flagArgs := args[i : i+1]
+		if !hasValue && !isBoolFlag(f) && i+1 < len(args) {
+			flagArgs = args[i : i+2]
+			i++
+		}
+		if err := fs.Parse(flagArgs); err != nil {
+			return nil, err
+		}
+		if !isBoolFlag(f) && f.Value.String() == "" {
+			return nil, fmt.Errorf("flag needs a non-empty argument: --%s", name)
+		}
 	}
-	if format == "" {
-		format = "table" // Default to table format
+	demoMode = demo.Enabled(demoMode)
+	return filtered, nil
+}
+
+// isCommandFlag reports whether a flag name belongs to the command rather than to the global flags
+func isCommandFlag(command, name string) bool {
+	for _, own := range commandOwnFlags[command] {
+		if own == name {
+			return true
+		}
 	}
+	return false
+}
 
-	// Create auth client
+// newAuthClient creates the Facebook auth client for the loaded configuration
+func newAuthClient(cfg *config.Config) *auth.FacebookAuth {
 	authClient := auth.NewFacebookAuth(
 		cfg.AppID,
 		cfg.AppSecret,
 		cfg.AccessToken,
 		cfg.APIVersion,
 	)
+	authClient.MinAPIVersion = cfg.MinAPIVersion
+	if err := authClient.Validate(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	authClient.Retry = auth.RetryPolicy{
+		MaxRetries: cfg.Retry.MaxRetries,
+		BaseDelay:  time.Duration(cfg.Retry.BaseDelaySeconds * float64(time.Second)),
+		MaxDelay:   time.Duration(cfg.Retry.MaxDelaySeconds * float64(time.Second)),
+	}
+	if demoMode {
+		if demoProvider == nil {
+			demoProvider = demo.NewProvider()
+			fmt.Fprintln(os.Stderr, "Demo mode: using a sample ad account, no requests are sent to Facebook")
+		}
+		authClient.Transport = demoProvider
+	}
+	return authClient
+}
+
+// newCampaignCreator creates the campaign creator for the loaded configuration
+func newCampaignCreator(authClient *auth.FacebookAuth, cfg *config.Config) *internal_campaign.CampaignCreator {
+	creator := internal_campaign.NewCampaignCreator(authClient, cfg.AccountID)
+	creator.SetNormalizeObjectives(!noNormalize)
+	return creator
+}
+
+func listCampaigns(cfg *config.Config) {
+	// Parse flags
+	var (
+		limit        int
+		status       string
+		format       string
+		showWarnings bool
+		mine         bool
+		withInsights bool
+		since        = "30d"
+		until        string
+	)
+
+	fs := newCommandFlags("list [options]")
+	fs.IntVar(&limit, "limit", 10, "Limit the number of results")
+	alias(fs, "l", "limit")
+	fs.StringVar(&status, "status", "", "Filter by status (ACTIVE, PAUSED, etc.)")
+	alias(fs, "s", "status")
+	fs.StringVar(&format, "format", "table", "Output format (table, json, csv)")
+	alias(fs, "f", "format")
+	fs.BoolVar(&showWarnings, "show-warnings", false, "List fields that could not be parsed")
+	fs.BoolVar(&mine, "mine", false, "Only campaigns created by fbads (labeled fbads:v<version>)")
+	fs.BoolVar(&withInsights, "with-insights", false, "Add spend, impressions, clicks, CTR and conversions")
+	fs.StringVar(&since, "since", since, "Start of the insights period: a date (YYYY-MM-DD) or days like 30d")
+	fs.StringVar(&until, "until", "", "End of the insights period (YYYY-MM-DD, default: yesterday)")
+	parseCommandArgs(fs, os.Args[2:], 0, 0)
+
+	if limit <= 0 {
+		fmt.Println("--limit must be a positive number")
+		os.Exit(1)
+	}
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
 
 	// Create API client
 	client := api.NewClient(authClient, cfg.AccountID)
@@ -164,7 +379,7 @@ func listCampaigns(cfg *config.Config) {
 	fmt.Println("Fetching campaigns...")
 
 	// Get campaigns
-	campaigns, err := client.GetAllCampaigns()
+	campaigns, err := client.GetAllCampaignsContext(cmdContext)
 	if err != nil {
 		fmt.Printf("Error fetching campaigns: %v\n", err)
 		os.Exit(1)
@@ -182,29 +397,130 @@ func listCampaigns(cfg *config.Config) {
 		campaigns = filteredCampaigns
 	}
 
+	// Keep only campaigns created by fbads
+	if mine {
+		campaigns = ownedCampaigns(campaigns)
+	}
+
 	// Limit results
 	if limit > 0 && limit < len(campaigns) {
 		campaigns = campaigns[:limit]
 	}
 
+	rows := make([]campaignRow, len(campaigns))
+	for i, campaign := range campaigns {
+		rows[i] = campaignRow{Campaign: campaign}
+	}
+
+	if withInsights && len(rows) > 0 {
+		metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+		clock := metricsCollector.Clock()
+
+		// Today is incomplete, so the period ends yesterday unless told otherwise
+		startDate, err := parseSinceFlag(since, clock.Today())
+		if err != nil {
+			fmt.Printf("Invalid --since value: %v\n", err)
+			os.Exit(1)
+		}
+		endDate := clock.Yesterday()
+		if until != "" {
+			endDate, err = parseSinceFlag(until, clock.Today())
+			if err != nil {
+				fmt.Printf("Invalid --until value: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if endDate.Before(startDate) {
+			fmt.Println("End date must not be before start date")
+			os.Exit(1)
+		}
+
+		err = addCampaignInsights(cmdContext, metricsCollector, rows, api.TimeRange{
+			Since: startDate.Format("2006-01-02"),
+			Until: endDate.Format("2006-01-02"),
+		})
+		if err != nil {
+			fmt.Printf("Error collecting campaign insights: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Display results based on format
 	switch format {
 	case "json":
-		displayCampaignsJSON(campaigns)
+		displayCampaignsJSON(rows)
 	case "csv":
-		displayCampaignsCSV(campaigns)
+		displayCampaignsCSV(rows, withInsights)
 	case "table":
-		displayCampaignsTable(campaigns)
+		displayCampaignsTable(rows, withInsights)
 	default:
 		fmt.Printf("Unknown format: %s. Supported formats: table, json, csv\n", format)
 		os.Exit(1)
 	}
 
 	fmt.Printf("\nTotal: %d campaigns\n", len(campaigns))
+
+	// Report fields that could not be parsed
+	withWarnings := 0
+	for _, campaign := range campaigns {
+		if len(campaign.Warnings) == 0 {
+			continue
+		}
+		withWarnings++
+		if showWarnings {
+			printParseWarnings(campaign.ID, campaign.Warnings)
+		}
+	}
+	if withWarnings > 0 && !showWarnings {
+		fmt.Fprintf(os.Stderr, "Note: %d campaigns have fields that could not be parsed; use --show-warnings to list them\n", withWarnings)
+	}
+}
+
+// campaignRow is a campaign listed by the list command, with its insights when requested
+type campaignRow struct {
+	models.Campaign
+	Insights *utils.CampaignPerformance `json:"insights,omitempty"`
 }
 
-// displayCampaignsTable displays campaigns in a formatted table
-func displayCampaignsTable(campaigns []models.Campaign) {
+// addCampaignInsights sets the insights of every row from a single campaign-level insights
+// call for the time range. Campaigns without delivery in the range get zero insights.
+func addCampaignInsights(ctx context.Context, collector *api.MetricsCollector, rows []campaignRow, timeRange api.TimeRange) error {
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+
+	performances, err := collector.CollectCampaignMetricsContext(ctx, api.InsightsRequest{
+		Level:     "campaign",
+		IDs:       ids,
+		TimeRange: timeRange,
+	})
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]utils.CampaignPerformance, len(performances))
+	for _, perf := range performances {
+		byID[perf.CampaignID] = perf
+	}
+	for i := range rows {
+		perf := byID[rows[i].ID]
+		perf.CampaignID, perf.Name = rows[i].ID, rows[i].Name
+		rows[i].Insights = &perf
+	}
+	return nil
+}
+
+// printParseWarnings prints the fields of an object that could not be parsed
+func printParseWarnings(objectID string, warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", objectID, warning)
+	}
+}
+
+// displayCampaignsTable displays campaigns in a formatted table, with spend, impressions,
+// clicks, CTR and conversions when insights were collected
+func displayCampaignsTable(campaigns []campaignRow, withInsights bool) {
 	if len(campaigns) == 0 {
 		fmt.Println("No campaigns found.")
 		return
@@ -233,20 +549,29 @@ func displayCampaignsTable(campaigns []models.Campaign) {
 	}
 
 	// Print header
-	fmt.Printf("%-*s | %-*s | %-*s | %-*s | %-*s\n",
+	fmt.Printf("%-*s | %-*s | %-*s | %-*s | %-*s",
 		idWidth, "ID",
 		nameWidth, "NAME",
 		statusWidth, "STATUS",
 		budgetWidth, "BUDGET",
 		objectiveWidth, "OBJECTIVE")
+	if withInsights {
+		fmt.Printf(" | %-10s | %-11s | %-8s | %-6s | %s", "SPEND", "IMPRESSIONS", "CLICKS", "CTR", "CONVERSIONS")
+	}
+	fmt.Println()
 
 	// Print separator
-	fmt.Printf("%s-+-%s-+-%s-+-%s-+-%s\n",
+	fmt.Printf("%s-+-%s-+-%s-+-%s-+-%s",
 		strings.Repeat("-", idWidth),
 		strings.Repeat("-", nameWidth),
 		strings.Repeat("-", statusWidth),
 		strings.Repeat("-", budgetWidth),
 		strings.Repeat("-", objectiveWidth))
+	if withInsights {
+		fmt.Printf("-+-%s-+-%s-+-%s-+-%s-+-%s", strings.Repeat("-", 10), strings.Repeat("-", 11),
+			strings.Repeat("-", 8), strings.Repeat("-", 6), strings.Repeat("-", 11))
+	}
+	fmt.Println()
 
 	// Print rows
 	for _, campaign := range campaigns {
@@ -260,21 +585,27 @@ func displayCampaignsTable(campaigns []models.Campaign) {
 			budget = "N/A"
 		}
 
-		fmt.Printf("%-*s | %-*s | %-*s | %-*s | %-*s\n",
+		fmt.Printf("%-*s | %-*s | %-*s | %-*s | %-*s",
 			idWidth, campaign.ID,
 			nameWidth, truncateString(campaign.Name, nameWidth),
 			statusWidth, campaign.Status,
 			budgetWidth, budget,
 			objectiveWidth, campaign.ObjectiveType)
+		if withInsights && campaign.Insights != nil {
+			fmt.Printf(" | %-10s | %-11d | %-8d | %-6s | %d",
+				fmt.Sprintf("$%.2f", campaign.Insights.Spend), campaign.Insights.Impressions, campaign.Insights.Clicks,
+				fmt.Sprintf("%.2f%%", campaign.Insights.CTR), campaign.Insights.Conversions)
+		}
+		fmt.Println()
 	}
 }
 
 // displayCampaignsJSON displays campaigns in JSON format
-func displayCampaignsJSON(campaigns []models.Campaign) {
+func displayCampaignsJSON(campaigns []campaignRow) {
 	// Create a response structure to wrap the campaigns
 	response := struct {
-		Campaigns []models.Campaign `json:"campaigns"`
-		Count     int               `json:"count"`
+		Campaigns []campaignRow `json:"campaigns"`
+		Count     int           `json:"count"`
 	}{
 		Campaigns: campaigns,
 		Count:     len(campaigns),
@@ -291,9 +622,13 @@ func displayCampaignsJSON(campaigns []models.Campaign) {
 }
 
 // displayCampaignsCSV displays campaigns in CSV format
-func displayCampaignsCSV(campaigns []models.Campaign) {
+func displayCampaignsCSV(campaigns []campaignRow, withInsights bool) {
 	// Print header
-	fmt.Println("id,name,status,objective,budget_type,budget,bid_strategy,buying_type,created,updated")
+	header := "id,name,status,objective,budget_type,budget,bid_strategy,buying_type,created,updated"
+	if withInsights {
+		header += ",spend,impressions,clicks,ctr,conversions"
+	}
+	fmt.Println(header)
 
 	// Print rows
 	for _, campaign := range campaigns {
@@ -313,7 +648,7 @@ func displayCampaignsCSV(campaigns []models.Campaign) {
 		updated := campaign.Updated.Format("2006-01-02T15:04:05")
 
 		// Print the campaign as a CSV row
-		fmt.Printf("%s,%s,%s,%s,%s,%.2f,%s,%s,%s,%s\n",
+		fmt.Printf("%s,%s,%s,%s,%s,%.2f,%s,%s,%s,%s",
 			campaign.ID,
 			escapeCSV(campaign.Name),
 			campaign.Status,
@@ -324,6 +659,11 @@ func displayCampaignsCSV(campaigns []models.Campaign) {
 			campaign.BuyingType,
 			created,
 			updated)
+		if withInsights && campaign.Insights != nil {
+			fmt.Printf(",%.2f,%d,%d,%.2f,%d", campaign.Insights.Spend, campaign.Insights.Impressions,
+				campaign.Insights.Clicks, campaign.Insights.CTR, campaign.Insights.Conversions)
+		}
+		fmt.Println()
 	}
 }
 
@@ -344,19 +684,31 @@ func escapeCSV(s string) string {
 }
 
 func createCampaign(cfg *config.Config) {
-	if len(os.Args) < 3 {
-		fmt.Println("Missing campaign configuration file. Use: fbads create <config_file.json>")
+	// Check for dry run and creative library flags
+	dryRun := false
+	creativeLib := ""
+	formatName := ""
+	skipRemoteValidation := false
+	verbose := false
+	fs := newCommandFlags("create <config_file> [options]")
+	fs.BoolVar(&dryRun, "dry-run", false, "Preview the campaign without creating it")
+	alias(fs, "d", "dry-run")
+	fs.BoolVar(&verbose, "verbose", false, "With --dry-run, print every API request that would be sent")
+	fs.StringVar(&creativeLib, "creative-lib", "", "Creative library for configurations with creative_ref placeholders")
+	fs.StringVar(&formatName, "format", "", "File format: json or yaml (default: by the file extension, else json)")
+	fs.BoolVar(&skipRemoteValidation, "skip-remote-validation", false, "Don't check the targeted interests with Facebook")
+	configFile := parseCommandArgs(fs, os.Args[2:], 1, 1)[0]
+	if verbose && !dryRun {
+		fmt.Println("--verbose is only supported with --dry-run")
 		os.Exit(1)
 	}
 
-	configFile := os.Args[2]
-
-	// Check for dry run flag
-	dryRun := false
-	for _, arg := range os.Args {
-		if arg == "--dry-run" || arg == "-d" {
-			dryRun = true
-			break
+	format := internal_campaign.ConfigFormatFromPath(configFile)
+	if formatName != "" {
+		var err error
+		if format, err = internal_campaign.ParseConfigFormat(formatName); err != nil {
+			fmt.Printf("Invalid --format value: %v\n", err)
+			os.Exit(1)
 		}
 	}
 
@@ -370,23 +722,45 @@ func createCampaign(cfg *config.Config) {
 	}
 
 	// Parse the configuration
-	var campaignConfig models.CampaignConfig
-	if err := json.Unmarshal(configData, &campaignConfig); err != nil {
-		fmt.Printf("Error parsing configuration: %v\n", err)
+	campaignConfig, err := internal_campaign.ParseCampaignConfig(configData, format)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Configurations exported with the structure profile reference their creatives
+	if err := resolveCreativeRefs(campaignConfig, creativeLib); err != nil {
+		fmt.Printf("Error resolving creatives: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Validate the configuration
-	if err := validateCampaignConfig(&campaignConfig); err != nil {
-		fmt.Printf("Invalid campaign configuration: %v\n", err)
+	// Image files are looked up next to the configuration file
+	resolveImagePaths(campaignConfig, filepath.Dir(configFile))
+
+	// Validate the configuration, reporting every problem at once
+	problems := checkCampaignConfig(campaignConfig)
+	if problems.Err() != nil {
+		printValidationProblems(problems)
 		os.Exit(1)
 	}
+	printValidationWarnings(problems)
+	if !skipRemoteValidation {
+		checkInterests(cfg, models.AdSetTargeting(campaignConfig))
+	}
 
 	// Print configuration summary
-	printCampaignConfigSummary(&campaignConfig)
+	printCampaignConfigSummary(campaignConfig)
 
 	// If dry run, just print configuration summary and exit
 	if dryRun {
+		if verbose {
+			plan, err := newCampaignCreator(newAuthClient(cfg), cfg).Plan(campaignConfig)
+			if err != nil {
+				fmt.Printf("Error planning the API requests: %v\n", err)
+				os.Exit(1)
+			}
+			printPlannedRequests(plan)
+		}
 		fmt.Println("\nDry run: No campaigns will be created.")
 		return
 	}
@@ -402,20 +776,15 @@ func createCampaign(cfg *config.Config) {
 	}
 
 	// Create auth client
-	authClient := auth.NewFacebookAuth(
-		cfg.AppID,
-		cfg.AppSecret,
-		cfg.AccessToken,
-		cfg.APIVersion,
-	)
+	authClient := newAuthClient(cfg)
 
 	// Create campaign creator from the internal/campaign package
-	creator := internal_campaign.NewCampaignCreator(authClient, cfg.AccountID)
+	creator := newCampaignCreator(authClient, cfg)
 
 	fmt.Println("Creating campaign...")
 
 	// Create the campaign
-	err = creator.CreateFromConfig(&campaignConfig)
+	err = creator.CreateFromConfig(campaignConfig)
 	if err != nil {
 		fmt.Printf("Error creating campaign: %v\n", err)
 		os.Exit(1)
@@ -424,72 +793,146 @@ func createCampaign(cfg *config.Config) {
 	fmt.Println("Campaign created successfully!")
 }
 
-// validateCampaignConfig validates the campaign configuration
-func validateCampaignConfig(config *models.CampaignConfig) error {
-	if config.Name == "" {
-		return fmt.Errorf("campaign name is required")
+// resolveCreativeRefs fills in creative_ref placeholders from the creative library at path
+func resolveCreativeRefs(config *models.CampaignConfig, path string) error {
+	if !internal_campaign.HasCreativeRefs(config) {
+		return nil
 	}
 
-	if config.Objective == "" {
-		return fmt.Errorf("campaign objective is required")
+	if path == "" {
+		return fmt.Errorf("the configuration uses creative_ref placeholders; pass --creative-lib <file>")
 	}
 
-	if config.BuyingType == "" {
-		return fmt.Errorf("campaign buying type is required")
+	library, err := internal_campaign.LoadCreativeLibrary(path)
+	if err != nil {
+		return fmt.Errorf("error reading creative library: %w", err)
 	}
 
-	if config.DailyBudget == 0 && config.LifetimeBudget == 0 {
-		return fmt.Errorf("either daily budget or lifetime budget is required")
+	return library.Resolve(config)
+}
+
+// resolveImagePaths makes the relative image file paths of the creatives and their carousel
+// cards relative to dir
+func resolveImagePaths(config *models.CampaignConfig, dir string) {
+	resolve := func(image *string, hash string) {
+		if *image == "" || hash != "" || internal_campaign.IsRemoteImage(*image) || filepath.IsAbs(*image) {
+			return
+		}
+		*image = filepath.Join(dir, *image)
 	}
 
-	if len(config.AdSets) == 0 {
-		return fmt.Errorf("at least one ad set is required")
+	for _, ad := range config.AllAds() {
+		resolve(&ad.Creative.ImageURL, ad.Creative.ImageHash)
+		for i := range ad.Creative.Cards {
+			resolve(&ad.Creative.Cards[i].ImageURL, ad.Creative.Cards[i].ImageHash)
+		}
 	}
+}
+
+// validateCampaignConfig validates the campaign configuration.
+// It returns every error at once as models.ValidationErrors; warnings don't fail validation.
+func validateCampaignConfig(config *models.CampaignConfig) error {
+	return checkCampaignConfig(config).Err()
+}
 
+// checkCampaignConfig returns every problem in the campaign configuration, including warnings.
+// Besides the checks of models.ValidateCampaignConfig, the local image files must exist.
+func checkCampaignConfig(config *models.CampaignConfig) models.ValidationErrors {
+	problems := models.ValidateCampaignConfig(config)
+
+	checkImages := func(path string, creative models.CreativeConfig) {
+		validateImageFile(&problems, path+".creative.image_url", creative.ImageURL, creative.ImageHash)
+		for i, card := range creative.Cards {
+			validateImageFile(&problems, fmt.Sprintf("%s.creative.cards[%d].image_url", path, i), card.ImageURL, card.ImageHash)
+		}
+	}
 	for i, adSet := range config.AdSets {
-		if adSet.Name == "" {
-			return fmt.Errorf("ad set #%d: name is required", i+1)
+		for j, ad := range adSet.Ads {
+			checkImages(fmt.Sprintf("adsets[%d].ads[%d]", i, j), ad.Creative)
 		}
+	}
+	for i, ad := range config.Ads {
+		checkImages(fmt.Sprintf("ads[%d]", i), ad.Creative)
+	}
 
-		if adSet.OptimizationGoal == "" {
-			return fmt.Errorf("ad set #%d: optimization goal is required", i+1)
-		}
+	return problems
+}
 
-		if adSet.BillingEvent == "" {
-			return fmt.Errorf("ad set #%d: billing event is required", i+1)
-		}
+// checkInterests checks the interests of targeting specs with Facebook and exits when one
+// can't be targeted. Interests are checked even on dry runs, as a retired interest would
+// otherwise only fail the creation; --skip-remote-validation turns the check off.
+func checkInterests(cfg *config.Config, specs []models.TargetingSpec) {
+	hasInterests := false
+	for _, spec := range specs {
+		hasInterests = hasInterests || len(models.TargetingInterests(spec.Targeting)) > 0
+	}
+	if !hasInterests {
+		return
+	}
 
-		if len(adSet.Targeting) == 0 {
-			return fmt.Errorf("ad set #%d: targeting is required", i+1)
-		}
+	analyzer := audience.NewAudienceAnalyzer(newAuthClient(cfg), cfg.AccountID)
+	problems, err := analyzer.CheckTargetingInterestsContext(cmdContext, specs)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Run with --skip-remote-validation to create the campaign without the check.")
+		os.Exit(1)
+	}
+	if problems.Err() != nil {
+		printValidationProblems(problems)
+		os.Exit(1)
 	}
+}
 
-	if len(config.Ads) == 0 {
-		return fmt.Errorf("at least one ad is required")
+// validateImageFile reports a local image file that doesn't exist; image files are uploaded
+// when the campaign is created, so they must exist
+func validateImageFile(problems *models.ValidationErrors, path, image, hash string) {
+	if image == "" || hash != "" || internal_campaign.IsRemoteImage(image) {
+		return
+	}
+	if _, err := os.Stat(image); err != nil {
+		problems.Add(path, "image file %s not found", image)
 	}
+}
 
-	for i, ad := range config.Ads {
-		if ad.Name == "" {
-			return fmt.Errorf("ad #%d: name is required", i+1)
-		}
+// printValidationProblems prints the errors of a configuration as a numbered list
+func printValidationProblems(problems models.ValidationErrors) {
+	errs := problems.Errors()
+	fmt.Printf("Invalid campaign configuration: %d problems found\n", len(errs))
+	for i, problem := range errs {
+		fmt.Printf("  %d. %s\n", i+1, problem)
+	}
+}
 
-		// Check for title or name in the creative
-		// Different templates might use Name instead of Title field
-		if ad.Creative.Title == "" && ad.Creative.Name == "" {
-			return fmt.Errorf("ad #%d: creative title/name is required", i+1)
-		}
+// printValidationWarnings prints the warnings of a configuration
+func printValidationWarnings(problems models.ValidationErrors) {
+	for _, problem := range problems.Warnings() {
+		fmt.Printf("Warning: %s\n", problem)
+	}
+}
 
-		if ad.Creative.LinkURL == "" {
-			return fmt.Errorf("ad #%d: creative link URL is required", i+1)
+// printPlannedRequests prints the endpoint and parameters of each planned API request,
+// with the access token redacted
+func printPlannedRequests(plan []internal_campaign.PlannedRequest) {
+	fmt.Printf("\nPlanned API requests: %d\n", len(plan))
+	for i, request := range plan {
+		request = request.Redacted()
+		fmt.Printf("  %d. %s %s (%s %q)", i+1, request.Method, request.Endpoint, request.Object, request.Name)
+		if request.Ref != "" {
+			fmt.Printf(" -> %s", request.Ref)
 		}
+		fmt.Println()
 
-		// Now validate the Page ID as well, which is required
-		if ad.Creative.PageID == "" {
-			return fmt.Errorf("ad #%d: creative page_id is required", i+1)
+		keys := make([]string, 0, len(request.Params))
+		for key := range request.Params {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			for _, value := range request.Params[key] {
+				fmt.Printf("     %s=%s\n", key, value)
+			}
 		}
 	}
-
-	return nil
 }
 
 // printCampaignConfigSummary prints a summary of the campaign configuration
@@ -521,6 +964,9 @@ func printCampaignConfigSummary(config *models.CampaignConfig) {
 		fmt.Printf("  %d. %s (Status: %s)\n", i+1, adSet.Name, adSet.Status)
 		fmt.Printf("     Optimization Goal: %s\n", adSet.OptimizationGoal)
 		fmt.Printf("     Billing Event: %s\n", adSet.BillingEvent)
+		if adSet.DestinationType != "" {
+			fmt.Printf("     Destination: %s\n", adSet.DestinationType)
+		}
 
 		// Print targeting summary (simplified)
 		if targeting, ok := adSet.Targeting["geo_locations"].(map[string]interface{}); ok {
@@ -536,9 +982,14 @@ func printCampaignConfigSummary(config *models.CampaignConfig) {
 		}
 	}
 
-	fmt.Printf("\nAds: %d\n", len(config.Ads))
-	for i, ad := range config.Ads {
+	ads := config.AllAds()
+	fmt.Printf("\nAds: %d\n", len(ads))
+	for i, ad := range ads {
 		fmt.Printf("  %d. %s (Status: %s)\n", i+1, ad.Name, ad.Status)
+		if ad.Creative.CreativeID != "" {
+			fmt.Printf("     Creative: %s (reused)\n", ad.Creative.CreativeID)
+			continue
+		}
 		// Display either Title or Name
 		titleValue := ad.Creative.Title
 		if titleValue == "" {
@@ -551,30 +1002,49 @@ func printCampaignConfigSummary(config *models.CampaignConfig) {
 			fmt.Printf("     Body: %s\n", ad.Creative.Body)
 		}
 		fmt.Printf("     Link URL: %s\n", ad.Creative.LinkURL)
+		if ad.Creative.VideoID != "" {
+			fmt.Printf("     Video ID: %s\n", ad.Creative.VideoID)
+		}
+		for j, card := range ad.Creative.Cards {
+			fmt.Printf("     Card %d: %s (%s)\n", j+1, card.Title, card.LinkURL)
+		}
+		if ad.Creative.ImageHash != "" {
+			fmt.Printf("     Image Hash: %s\n", ad.Creative.ImageHash)
+		} else if ad.Creative.ImageURL != "" {
+			fmt.Printf("     Image: %s (uploaded on creation)\n", ad.Creative.ImageURL)
+		}
 		if ad.Creative.CallToAction != "" {
 			fmt.Printf("     Call to Action: %s\n", ad.Creative.CallToAction)
 		}
 		fmt.Printf("     Page ID: %s\n", ad.Creative.PageID)
+		if ad.Creative.WhatsAppNumber != "" {
+			fmt.Printf("     WhatsApp Number: %s\n", ad.Creative.WhatsAppNumber)
+		}
 	}
 }
 
 func analyzeAudience(cfg *config.Config) {
 	// Parse flags and subcommands
 	if len(os.Args) < 3 {
-		fmt.Println("Missing audience subcommand. Available commands: search, filter, stats")
+		fmt.Println("Missing audience subcommand. Available commands: search, filter, stats, overlap")
 		os.Exit(1)
 	}
 
 	// Create auth client
-	authClient := auth.NewFacebookAuth(
-		cfg.AppID,
-		cfg.AppSecret,
-		cfg.AccessToken,
-		cfg.APIVersion,
-	)
+	authClient := newAuthClient(cfg)
 
-	// Create audience analyzer
+	// Create audience analyzer with dates in the account timezone
 	analyzer := audience.NewAudienceAnalyzer(authClient, cfg.AccountID)
+	analyzer.SetLocation(api.NewAccountClock(authClient, cfg.AccountID).Location())
+
+	// Reuse the segments researched by earlier runs; demo segments are not kept
+	analyzer.SetCacheLimits(audience.DefaultSegmentCacheSize, time.Duration(cfg.AudienceCache.TTLHours*float64(time.Hour)))
+	cachePath := audience.DefaultSegmentCachePath(cfg.ConfigDir)
+	if !demoMode {
+		if err := analyzer.LoadCache(cachePath); err != nil {
+			fmt.Printf("Warning: ignoring the audience cache: %v\n", err)
+		}
+	}
 
 	// Process subcommand
 	subCmd := os.Args[2]
@@ -585,17 +1055,77 @@ func analyzeAudience(cfg *config.Config) {
 	case "filter":
 		filterAudience(analyzer, os.Args[3:])
 	case "stats":
-		audienceStats(analyzer, os.Args[3:])
+		// Demo statistics are not kept, like the demo segments
+		statsPath := ""
+		if !demoMode {
+			statsPath = audience.DefaultStatsPath(cfg.ConfigDir)
+		}
+		audienceStats(analyzer, statsPath, os.Args[3:])
+	case "overlap":
+		audienceOverlap(analyzer, os.Args[3:])
 	default:
 		fmt.Printf("Unknown audience subcommand: %s\n", subCmd)
-		fmt.Println("Available subcommands: search, filter, stats")
+		fmt.Println("Available subcommands: search, filter, stats, overlap")
 		os.Exit(1)
 	}
+
+	if !demoMode {
+		if err := analyzer.SaveCache(cachePath); err != nil {
+			fmt.Printf("Warning: could not save the audience cache: %v\n", err)
+		}
+	}
 }
 
 // searchAudience handles searching for audience segments
 func searchAudience(analyzer *audience.AudienceAnalyzer, args []string) {
-	if len(args) < 1 {
+	searchType := "adinterest" // Default to interests
+
+	var outputFile string
+
+	var class string
+
+	// Parse flags
+	fs := newCommandFlags("audience search <query> [options]")
+	fs.StringVar(&searchType, "type", searchType, "Search type, see the list below")
+	alias(fs, "t", "type")
+	fs.StringVar(&class, "class", "", "Targeting category class: interests, behaviors or demographics")
+	alias(fs, "c", "class")
+	fs.StringVar(&outputFile, "output", "", "Export the segments to a file")
+	alias(fs, "o", "output")
+	var exportOptions audience.ExportOptions
+	fs.StringVar(&exportOptions.Format, "format", "", "Export format: json or csv (default: by the file extension)")
+	fs.BoolVar(&exportOptions.Merge, "merge", false, "Add the segments to the file, replacing those with the same ID")
+	var countries, ages string
+	var debug bool
+	fs.StringVar(&countries, "country", "", "Estimate the audience of each result in these comma-separated countries, e.g. GB,DE")
+	fs.StringVar(&ages, "age", "", "Estimate the audience of each result in an age range, e.g. 25-44 or 45+")
+	fs.BoolVar(&debug, "debug", false, "Print the raw API responses to stderr")
+	positional := parseCommandArgs(fs, args, 0, 1)
+
+	// Results are estimated within the targeting when a country or an age range is given
+	var targeting *audience.TargetingSpec
+	if countries != "" || ages != "" {
+		targeting = &audience.TargetingSpec{Countries: splitList(strings.ToUpper(countries))}
+		if ages != "" {
+			var err error
+			if targeting.AgeMin, targeting.AgeMax, err = audience.ParseAgeRange(ages); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+	if debug {
+		analyzer.SetDebug(os.Stderr)
+	}
+
+	// The class browses a targeting category, so the query is optional
+	query := ""
+	if class != "" {
+		searchType = "adTargetingCategory"
+	}
+	if len(positional) == 1 {
+		query = positional[0]
+	} else if class == "" {
 		fmt.Println("Missing search query. Use: fbads audience search <query> [--type TYPE] [--output FILE] [--class CLASS]")
 		fmt.Println(`Available type options:
 	adTargetingCategory: Search for interests, behaviors, demographics to use in ad targeting:
@@ -617,41 +1147,6 @@ func searchAudience(analyzer *audience.AudienceAnalyzer, args []string) {
 		os.Exit(1)
 	}
 
-	index := 1
-	query := args[0]
-	if args[0] == "--class" || args[0] == "-c" {
-		index = 0
-		query = ""
-	}
-
-	searchType := "adinterest" // Default to interests
-
-	var outputFile string
-
-	var class string
-
-	// Parse flags
-	for i := index; i < len(args); i++ {
-		switch args[i] {
-		case "--type", "-t":
-			if i+1 < len(args) {
-				searchType = args[i+1]
-				i++
-			}
-		case "--class", "-c":
-			searchType = "adTargetingCategory"
-			if i+1 < len(args) {
-				class = args[i+1]
-				i++
-			}
-		case "--output", "-o":
-			if i+1 < len(args) {
-				outputFile = args[i+1]
-				i++
-			}
-		}
-	}
-
 	var segments []audience.AudienceSegment
 	var err error
 
@@ -678,90 +1173,90 @@ func searchAudience(analyzer *audience.AudienceAnalyzer, args []string) {
 		if segment.Type != "" {
 			fmt.Printf("   Type: %s\n", segment.Type)
 		}
-		if segment.Path != "" {
-			fmt.Printf("   Category: %s\n", segment.Path)
+		if path := audience.FlattenPath(segment.Path); path != "" {
+			fmt.Printf("   Category: %s\n", path)
 		}
 		if segment.LowerBound > 0 || segment.UpperBound > 0 {
 			fmt.Printf("   Audience size: %s\n", audience.FormatAudienceRange(segment.LowerBound, segment.UpperBound))
 		}
+		if targeting != nil {
+			printTargetedAudienceSize(analyzer, *targeting, segment)
+		}
 		fmt.Println()
 	}
 
 	// Export to file if requested
 	if outputFile != "" {
-		err = analyzer.ExportAudienceData(outputFile, segments)
-		if err != nil {
-			fmt.Printf("Error exporting to file: %v\n", err)
-			return
-		}
-		fmt.Printf("Exported %d segments to %s\n", len(segments), outputFile)
+		exportAudienceSegments(analyzer, outputFile, segments, exportOptions)
 	}
 }
 
-// filterAudience handles filtering audience segments
-func filterAudience(analyzer *audience.AudienceAnalyzer, args []string) {
-	var query string
-	var minSize, maxSize int64
-	var types, keywords string
-	var outputFile string
+// printTargetedAudienceSize prints the estimated audience of a search result within the
+// countries and ages of the --country and --age flags
+func printTargetedAudienceSize(analyzer *audience.AudienceAnalyzer, targeting audience.TargetingSpec, segment audience.AudienceSegment) {
+	spec, ok := targeting.ForSegment(segment)
+	if !ok {
+		return
+	}
 
-	// Parse flags
-	for i := 0; i < len(args); i++ {
-		switch args[i] {
-		case "--query", "-q":
-			if i+1 < len(args) {
-				query = args[i+1]
-				i++
-			}
-		case "--min-size":
-			if i+1 < len(args) {
-				fmt.Sscanf(args[i+1], "%d", &minSize)
-				i++
-			}
-		case "--max-size":
-			if i+1 < len(args) {
-				fmt.Sscanf(args[i+1], "%d", &maxSize)
-				i++
-			}
-		case "--types", "-t":
-			if i+1 < len(args) {
-				types = args[i+1]
-				i++
-			}
-		case "--keywords", "-k":
-			if i+1 < len(args) {
-				keywords = args[i+1]
-				i++
-			}
-		case "--output", "-o":
-			if i+1 < len(args) {
-				outputFile = args[i+1]
-				i++
-			}
-		}
+	countries := spec.Countries
+	if len(countries) == 0 {
+		countries = audience.DefaultAudienceCountries
+	}
+	description := "in " + strings.Join(countries, ", ")
+	switch {
+	case spec.AgeMin != 0 && spec.AgeMax != 0:
+		description += fmt.Sprintf(", ages %d-%d", spec.AgeMin, spec.AgeMax)
+	case spec.AgeMin != 0:
+		description += fmt.Sprintf(", ages %d+", spec.AgeMin)
 	}
 
-	// First, we need to load some audience segments to filter
-	// For simplicity, we'll search for a default term if no query is provided
-	if query == "" {
-		query = "shopping"
+	lower, upper, err := analyzer.GetAudienceSizeContext(cmdContext, spec)
+	if err != nil {
+		fmt.Printf("   Audience size %s: unavailable (%v)\n", description, err)
+		return
 	}
+	fmt.Printf("   Audience size %s: %s\n", description, audience.FormatAudienceRange(lower, upper))
+}
 
-	// For now, we'll just log what we would do in a full implementation
-	fmt.Printf("Loading audience segments for '%s'...\n", query)
+// filterAudience handles filtering audience segments
+func filterAudience(analyzer *audience.AudienceAnalyzer, args []string) {
+	var query string
+	var minSize, maxSize int64
+	var types, keywords string
+	var outputFile string
 
-	// In a real implementation, we would search for both interests and behaviors
-	// For example:
-	// interests, err := analyzer.Search(query)
-	// if err != nil {
-	//     fmt.Printf("Error searching for interests: %v\n", err)
-	//     os.Exit(1)
-	// }
-	//
-	// behaviors, err := analyzer.GetBehaviors(query)
-	// if err != nil {
-	//     fmt.Printf("Error searching for behaviors: %v\n", err)
-	// }
+	// Parse flags
+	fs := newCommandFlags("audience filter [options]")
+	fs.StringVar(&query, "query", "", "Search term for the segments to filter (default: the cached segments, or shopping)")
+	alias(fs, "q", "query")
+	fs.Int64Var(&minSize, "min-size", 0, "Minimum audience size")
+	fs.Int64Var(&maxSize, "max-size", 0, "Maximum audience size")
+	fs.StringVar(&types, "types", "", "Comma-separated segment types")
+	alias(fs, "t", "types")
+	fs.StringVar(&keywords, "keywords", "", "Comma-separated keywords")
+	alias(fs, "k", "keywords")
+	fs.StringVar(&outputFile, "output", "", "Export the segments to a file")
+	alias(fs, "o", "output")
+	var exportOptions audience.ExportOptions
+	fs.StringVar(&exportOptions.Format, "format", "", "Export format: json or csv (default: by the file extension)")
+	fs.BoolVar(&exportOptions.Merge, "merge", false, "Add the segments to the file, replacing those with the same ID")
+	parseCommandArgs(fs, args, 0, 0)
+
+	// Segments cached by earlier runs are filtered as they are; a query or an empty
+	// cache loads interests for it first
+	if query != "" || len(analyzer.Segments()) == 0 {
+		if query == "" {
+			query = "shopping"
+		}
+		fmt.Printf("Loading audience segments for '%s'...\n", query)
+		if _, err := analyzer.Search("adinterest", "", query); err != nil {
+			fmt.Printf("Error searching for interests: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		fmt.Printf("Using %d cached audience segments\n", len(analyzer.Segments()))
+	}
 
 	// Create filter options
 	options := make(map[string]interface{})
@@ -812,85 +1307,144 @@ func filterAudience(analyzer *audience.AudienceAnalyzer, args []string) {
 
 	// Export to file if requested
 	if outputFile != "" {
-		err = analyzer.ExportAudienceData(outputFile, filtered)
-		if err != nil {
-			fmt.Printf("Error exporting to file: %v\n", err)
-			return
-		}
-		fmt.Printf("Exported filtered audience segments to %s\n", outputFile)
+		exportAudienceSegments(analyzer, outputFile, filtered, exportOptions)
 	}
 }
 
-// audienceStats handles collecting audience statistics
-func audienceStats(analyzer *audience.AudienceAnalyzer, args []string) {
-	var campaignID string
+// exportAudienceSegments writes segments to the --output file of audience search and filter
+func exportAudienceSegments(analyzer *audience.AudienceAnalyzer, outputFile string, segments []audience.AudienceSegment, options audience.ExportOptions) {
+	total, err := analyzer.ExportAudienceDataWithOptions(outputFile, segments, options)
+	if err != nil {
+		fmt.Printf("Error exporting to file: %v\n", err)
+		return
+	}
+
+	if options.Merge {
+		fmt.Printf("Merged %d segments into %s, which now holds %d\n", len(segments), outputFile, total)
+		return
+	}
+	fmt.Printf("Exported %d segments to %s\n", total, outputFile)
+}
+
+// audienceStats collects the performance of a campaign by age and gender, prints it and
+// saves it to statsPath, unless statsPath is empty
+func audienceStats(analyzer *audience.AudienceAnalyzer, statsPath string, args []string) {
+	var campaignID, outputFile string
 	days := 30 // Default to 30 days
 
 	// Parse flags
-	for i := 0; i < len(args); i++ {
-		switch args[i] {
-		case "--campaign", "-c":
-			if i+1 < len(args) {
-				campaignID = args[i+1]
-				i++
-			}
-		case "--days", "-d":
-			if i+1 < len(args) {
-				fmt.Sscanf(args[i+1], "%d", &days)
-				i++
-			}
-		}
-	}
+	fs := newCommandFlags("audience stats --campaign CAMPAIGN_ID [options]")
+	fs.StringVar(&campaignID, "campaign", "", "Campaign to collect statistics for")
+	alias(fs, "c", "campaign")
+	fs.IntVar(&days, "days", days, "Number of days to collect")
+	alias(fs, "d", "days")
+	fs.StringVar(&outputFile, "output", "", "Also write the statistics to this JSON file")
+	alias(fs, "o", "output")
+	parseCommandArgs(fs, args, 0, 0)
 
 	// Check if campaign ID is provided
 	if campaignID == "" {
-		fmt.Println("Missing campaign ID. Use: fbads audience stats --campaign CAMPAIGN_ID [--days DAYS]")
+		fmt.Println("Missing campaign ID. Use: fbads audience stats --campaign CAMPAIGN_ID [--days DAYS] [--output FILE]")
 		os.Exit(1)
 	}
 
 	fmt.Printf("Collecting audience statistics for campaign %s over the last %d days...\n", campaignID, days)
-	err := analyzer.CollectSegmentStatistics(campaignID, days)
+	stats, err := analyzer.CollectSegmentStatisticsContext(cmdContext, campaignID, days)
 	if err != nil {
 		fmt.Printf("Error collecting audience statistics: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Println("Successfully collected audience statistics.")
+	if len(stats.Rows) == 0 {
+		fmt.Printf("No delivery between %s and %s.\n", stats.Since, stats.Until)
+	} else {
+		printAudienceStats(stats)
+	}
+
+	if statsPath != "" {
+		if err := audience.SaveStats(statsPath, stats); err != nil {
+			fmt.Printf("Error saving audience statistics: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Saved audience statistics to %s\n", statsPath)
+	}
+
+	if outputFile != "" {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err == nil {
+			err = os.WriteFile(outputFile, data, 0644)
+		}
+		if err != nil {
+			fmt.Printf("Error writing audience statistics to file: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Exported audience statistics to %s\n", outputFile)
+	}
+}
+
+// printAudienceStats prints the performance of each breakdown bucket as a table
+func printAudienceStats(stats *audience.CampaignAudienceStats) {
+	fmt.Printf("\nPerformance by %s, %s to %s:\n\n", strings.ReplaceAll(stats.Breakdowns, ",", " and "), stats.Since, stats.Until)
+	fmt.Printf("%-16s | %12s | %8s | %10s | %7s | %8s\n", "BREAKDOWN", "IMPRESSIONS", "CLICKS", "SPEND", "CTR", "CPM")
+	fmt.Println(strings.Repeat("-", 16+12+8+10+7+8+15))
+	for _, row := range stats.Rows {
+		p := row.Performance
+		fmt.Printf("%-16s | %12d | %8d | %10.2f | %6.2f%% | %8.2f\n",
+			truncateString(row.Label(), 16), p.Impressions, p.Clicks, p.Spend, p.CTR, p.CPM)
+	}
 }
 
 func generateReport(cfg *config.Config, reportType string, args []string) {
-	// Create auth client
-	authClient := auth.NewFacebookAuth(
-		cfg.AppID,
-		cfg.AppSecret,
-		cfg.AccessToken,
-		cfg.APIVersion,
-	)
+	// Explaining the thresholds doesn't need the API
+	if reportType == "explain-recommendations" {
+		explainRecommendations(cfg)
+		return
+	}
+	if reportType == "creatives" {
+		creativesReport(cfg, args)
+		return
+	}
 
-	// Create metrics collector
-	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+	format := api.ReportFormatJSON
+	email := false
 
-	// Create audience analyzer
-	audienceAnalyzer := audience.NewAudienceAnalyzer(authClient, cfg.AccountID)
+	// Handle flags
+	usage, maxArgs := "report "+reportType+" [options]", 0
+	if reportType == "custom" {
+		usage, maxArgs = "report custom <start_date> <end_date> [options]", 2
+	}
+	fs := newCommandFlags(usage)
+	fs.StringVar(&format, "format", format, "Report file format: json, csv or html")
+	alias(fs, "f", "format")
+	fs.BoolVar(&email, "email", false, "Mail the report to the recipients of the email config section")
+	args = parseCommandArgs(fs, args, 0, maxArgs)
 
-	// Create performance analyzer
-	analyzer := api.NewPerformanceAnalyzer(metricsCollector, audienceAnalyzer)
+	format, err := api.ParseReportFormat(format)
+	if err != nil {
+		fmt.Printf("Invalid --format value: %v\n", err)
+		os.Exit(1)
+	}
 
-	// Set default reports directory
-	reportsDir := filepath.Join(cfg.ConfigDir, "reports")
+	// Check the mail settings before spending API calls on the report
+	var mailer *api.ReportMailer
+	if email {
+		if mailer, err = api.NewReportMailer(cfg.Email); err != nil {
+			fmt.Printf("Cannot mail the report: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	// Create report generator
-	reportGenerator := api.NewReportGenerator(analyzer, metricsCollector, reportsDir)
+	reportGenerator := newReportGenerator(cfg, mailer)
 
-	var err error
+	var reportPath string
 
 	switch reportType {
 	case "daily":
 		fmt.Println("Generating daily report...")
-		err = reportGenerator.GenerateDailyReport()
+		reportPath, err = reportGenerator.GenerateDailyReport(format)
 	case "weekly":
 		fmt.Println("Generating weekly report...")
-		err = reportGenerator.GenerateWeeklyReport()
+		reportPath, err = reportGenerator.GenerateWeeklyReport(format)
 	case "custom":
 		if len(args) < 2 {
 			fmt.Println("Missing date range. Use: fbads report custom <start_date> <end_date>")
@@ -898,27 +1452,23 @@ func generateReport(cfg *config.Config, reportType string, args []string) {
 			os.Exit(1)
 		}
 
-		startDate, err := time.Parse("2006-01-02", args[0])
-		if err != nil {
-			fmt.Printf("Invalid start date format: %v\n", err)
+		startDate, parseErr := time.Parse("2006-01-02", args[0])
+		if parseErr != nil {
+			fmt.Printf("Invalid start date format: %v\n", parseErr)
 			os.Exit(1)
 		}
 
-		endDate, err := time.Parse("2006-01-02", args[1])
-		if err != nil {
-			fmt.Printf("Invalid end date format: %v\n", err)
+		endDate, parseErr := time.Parse("2006-01-02", args[1])
+		if parseErr != nil {
+			fmt.Printf("Invalid end date format: %v\n", parseErr)
 			os.Exit(1)
 		}
 
 		fmt.Printf("Generating custom report for period: %s to %s\n", args[0], args[1])
-		err = reportGenerator.GenerateCustomReport(startDate, endDate)
-		if err != nil {
-			fmt.Printf("Invalid end date format: %v\n", err)
-			os.Exit(1)
-		}
+		reportPath, err = reportGenerator.GenerateCustomReport(startDate, endDate, format)
 	default:
 		fmt.Printf("Unknown report type: %s\n", reportType)
-		fmt.Println("Available report types: daily, weekly, custom")
+		fmt.Println("Available report types: daily, weekly, custom, creatives, explain-recommendations")
 		os.Exit(1)
 	}
 
@@ -927,18 +1477,64 @@ func generateReport(cfg *config.Config, reportType string, args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Printf("Report generated successfully in: %s\n", reportsDir)
+	fmt.Printf("Report generated successfully: %s\n", reportPath)
+	if mailer != nil {
+		fmt.Printf("Report mailed to %s\n", strings.Join(mailer.Recipients(), ", "))
+	}
+}
+
+// newReportGenerator creates a report generator writing to the reports directory under the
+// config dir. Reports are mailed when mailer is not nil.
+func newReportGenerator(cfg *config.Config, mailer *api.ReportMailer) *api.ReportGenerator {
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create metrics collector
+	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+
+	// Create audience analyzer
+	audienceAnalyzer := audience.NewAudienceAnalyzer(authClient, cfg.AccountID)
+
+	// Create performance analyzer
+	analyzer := api.NewPerformanceAnalyzer(metricsCollector, audienceAnalyzer)
+	analyzer.SetRecommendationThresholds(cfg.Recommendations)
+	analyzer.SetConversionValues(cfg.ConversionValues())
+
+	// Set default reports directory
+	reportsDir := filepath.Join(cfg.ConfigDir, "reports")
+
+	// Create report generator
+	reportGenerator := api.NewReportGenerator(analyzer, metricsCollector, reportsDir)
+	if mailer != nil {
+		reportGenerator.SetMailer(mailer)
+	}
+	return reportGenerator
+}
+
+// explainRecommendations prints the thresholds that trigger report recommendations
+func explainRecommendations(cfg *config.Config) {
+	fmt.Println("Active recommendation thresholds (set under \"recommendations\" in config.json):")
+	fmt.Println()
+	for _, rule := range api.DescribeRecommendationThresholds(cfg.Recommendations) {
+		fmt.Printf("  - %s\n", rule)
+	}
 }
 
 func optimizeCampaigns(cfg *config.Config) {
 	// Parse optimize subcommands
 	if len(os.Args) < 3 {
-		fmt.Println("Missing optimize subcommand. Available commands: validate, create, update")
+		fmt.Println("Missing optimize subcommand. Available commands: validate, validate-data, create, update, simulate, ads, recommend, apply-bids, prune")
 		fmt.Println("\nUsage: fbads optimize <subcommand> [options]")
 		fmt.Println("\nSubcommands:")
 		fmt.Println("  validate <yaml_file>     Validate a YAML campaign configuration file")
+		fmt.Println("  validate-data            Show which campaigns have enough data for optimization")
 		fmt.Println("  create <yaml_file>       Create test campaigns from a YAML configuration")
 		fmt.Println("  update <campaign_ids>    Update campaign CPM based on performance data")
+		fmt.Println("  simulate [--since 30d]   Replay stored statistics to see what the optimizer would have done")
+		fmt.Println("  ads --campaign <id>      Analyze ad rotation within ad sets (--apply pauses losing ads)")
+		fmt.Println("  recommend [--apply]      Recommend budget changes and pauses from recent performance")
+		fmt.Println("  apply-bids [--dry-run]   Adjust ad set CPM bids towards the optimal CPM")
+		fmt.Println("  prune [--rules <file>]   Pause campaigns that trigger deactivation rules (--dry-run to preview)")
 		os.Exit(1)
 	}
 
@@ -947,13 +1543,25 @@ func optimizeCampaigns(cfg *config.Config) {
 	switch subCmd {
 	case "validate":
 		validateYAMLConfig(cfg, os.Args[3:])
+	case "validate-data":
+		validateOptimizationData(cfg, os.Args[3:])
 	case "create":
 		createTestCampaigns(cfg, os.Args[3:])
 	case "update":
 		updateCampaignCPM(cfg, os.Args[3:])
+	case "simulate":
+		simulateOptimization(cfg, os.Args[3:])
+	case "ads":
+		optimizeAds(cfg, os.Args[3:])
+	case "recommend":
+		recommendCampaignActions(cfg, os.Args[3:])
+	case "apply-bids":
+		applyBids(cfg, os.Args[3:])
+	case "prune":
+		pruneCampaigns(cfg, os.Args[3:])
 	default:
 		fmt.Printf("Unknown optimize subcommand: %s\n", subCmd)
-		fmt.Println("Available subcommands: validate, create, update")
+		fmt.Println("Available subcommands: validate, validate-data, create, update, simulate, ads, recommend, apply-bids, prune")
 		os.Exit(1)
 	}
 }
@@ -1001,13 +1609,21 @@ func validateYAMLConfig(cfg *config.Config, args []string) {
 		(len(campaignCfg.TargetingOptions.Audiences) + len(campaignCfg.TargetingOptions.Placements))
 	fmt.Printf("Total possible test combinations: %d\n", totalCombinations)
 
-	// Calculate budget per campaign
-	budgetPerCampaign, err := budgetCalc.GetBudgetPerCampaign(totalCombinations)
+	start, end, err := campaignCfg.Campaign.Schedule.Window(time.Now())
 	if err != nil {
+		fmt.Printf("Error in campaign schedule: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Schedule: %s to %s\n", start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04"))
+
+	// Calculate budget per campaign, split by the weights of audiences and placements
+	generator := optimization.NewCampaignGenerator(campaignCfg, budgetCalc)
+	if err := generator.GenerateAllCombinations(); err != nil {
 		fmt.Printf("Error calculating budget per campaign: %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Printf("Budget per test campaign: $%.2f\n", budgetPerCampaign)
+	budgetPerCampaign, highestBudget := generator.BudgetRange()
+	printBudgetPerCampaign(budgetPerCampaign, highestBudget)
 
 	// Estimate impressions with automatic CPM (using max CPM for estimate)
 	impressions, err := budgetCalc.CalculateImpressions(budgetPerCampaign, budgetCalc.MaxCPM)
@@ -1023,46 +1639,45 @@ func validateYAMLConfig(cfg *config.Config, args []string) {
 	}
 }
 
-// createTestCampaigns creates test campaigns from a YAML configuration
-func createTestCampaigns(cfg *config.Config, args []string) {
-	if len(args) < 1 {
-		fmt.Println("Missing YAML file path. Use: fbads optimize create <yaml_file> [--template=campaign.json] [--limit=N] [--batch-size=N] [--dry-run]")
-		os.Exit(1)
+// printBudgetPerCampaign prints the budget of each test campaign, or the range of budgets
+// when audiences or placements have weights
+func printBudgetPerCampaign(lowest, highest float64) {
+	if lowest == highest {
+		fmt.Printf("Budget per test campaign: $%.2f\n", lowest)
+		return
 	}
+	fmt.Printf("Budget per test campaign: $%.2f to $%.2f, by weight\n", lowest, highest)
+}
 
-	yamlPath := args[0]
+// createTestCampaigns creates test campaigns from a YAML configuration
+func createTestCampaigns(cfg *config.Config, args []string) {
 	templatePath := ""
 	limit := 0
 	batchSize := 3
 	dryRun := false
+	jsonOutput := false
+	resume := false
+	useBatch := false
+	skipRemoteValidation := false
 	priority := "audience"
 
 	// Parse optional flags
-	for i := 1; i < len(args); i++ {
-		switch {
-		case strings.HasPrefix(args[i], "--template="):
-			templatePath = strings.TrimPrefix(args[i], "--template=")
-		case args[i] == "--template" && i+1 < len(args):
-			templatePath = args[i+1]
-			i++
-		case strings.HasPrefix(args[i], "--limit="):
-			fmt.Sscanf(strings.TrimPrefix(args[i], "--limit="), "%d", &limit)
-		case args[i] == "--limit" && i+1 < len(args):
-			fmt.Sscanf(args[i+1], "%d", &limit)
-			i++
-		case strings.HasPrefix(args[i], "--batch-size="):
-			fmt.Sscanf(strings.TrimPrefix(args[i], "--batch-size="), "%d", &batchSize)
-		case args[i] == "--batch-size" && i+1 < len(args):
-			fmt.Sscanf(args[i+1], "%d", &batchSize)
-			i++
-		case args[i] == "--dry-run" || args[i] == "-d":
-			dryRun = true
-		case strings.HasPrefix(args[i], "--priority="):
-			priority = strings.TrimPrefix(args[i], "--priority=")
-		case args[i] == "--priority" && i+1 < len(args):
-			priority = args[i+1]
-			i++
-		}
+	fs := newCommandFlags("optimize create <yaml_file> [options]")
+	fs.StringVar(&templatePath, "template", "", "Campaign JSON used as a template")
+	fs.IntVar(&limit, "limit", 0, "Maximum number of test campaigns to create")
+	fs.IntVar(&batchSize, "batch-size", batchSize, "Campaigns created per batch")
+	fs.BoolVar(&dryRun, "dry-run", false, "Preview the campaigns without creating them")
+	alias(fs, "d", "dry-run")
+	fs.BoolVar(&jsonOutput, "json", false, "With --dry-run, print every generated campaign configuration as JSON")
+	fs.StringVar(&priority, "priority", priority, "Combinations kept first when limited: audience or placement")
+	fs.BoolVar(&resume, "resume", false, "Create only the campaigns missing from an earlier run")
+	fs.BoolVar(&useBatch, "use-batch", false, "Create each batch of campaigns with Graph API batch requests")
+	fs.BoolVar(&skipRemoteValidation, "skip-remote-validation", false, "Don't check the targeted interests with Facebook")
+	yamlPath := parseCommandArgs(fs, args, 1, 1)[0]
+
+	if jsonOutput && !dryRun {
+		fmt.Println("--json is only supported with --dry-run")
+		os.Exit(1)
 	}
 
 	// Parse YAML configuration
@@ -1135,20 +1750,85 @@ func createTestCampaigns(cfg *config.Config, args []string) {
 	}
 	fmt.Printf("Batch size: %d, Total batches: %d\n", batchSize, totalBatches)
 
-	// Get budget per campaign
-	budgetPerCampaign, err := budgetCalc.GetBudgetPerCampaign(totalCombinations)
+	// Budgets are split by the weights of audiences and placements
+	printBudgetPerCampaign(generator.BudgetRange())
+
+	// Campaigns created by earlier runs are recorded so they are never created twice.
+	// Demo campaigns don't outlive the command, so they are not recorded.
+	statePath := optimization.CreationStatePath(cfg.ConfigDir, campaignCfg.Campaign.Name)
+	if demoMode {
+		statePath = ""
+	}
+	state, err := optimization.LoadCreationState(statePath, campaignCfg.Campaign.Name)
 	if err != nil {
-		fmt.Printf("Error calculating budget per campaign: %v\n", err)
+		fmt.Printf("Error loading creation state: %v\n", err)
+		os.Exit(1)
+	}
+	if len(state.Campaigns) > 0 {
+		if !resume && !dryRun {
+			fmt.Printf("\n%d campaigns of this configuration were already created (state: %s).\n", len(state.Campaigns), state.Path())
+			fmt.Println("Run again with --resume to create only the remaining ones.")
+			os.Exit(1)
+		}
+		if resume {
+			generator.Combinations = state.Remaining(generator.Combinations)
+			generator.ResetBatch()
+			totalCombinations = generator.TotalCombinations()
+			totalBatches = generator.TotalBatches()
+			fmt.Printf("Resuming: %d campaigns already created, %d remaining\n", len(state.Campaigns), totalCombinations)
+		}
+		if incomplete := state.Incomplete(); len(incomplete) > 0 {
+			fmt.Printf("%d campaigns were left incomplete by an earlier run and are not created again;\n", len(incomplete))
+			fmt.Println("finish them in Ads Manager or delete them:")
+			for _, campaign := range incomplete {
+				fmt.Printf("  - %s (%s)\n", campaign.Name, campaign.CampaignID)
+			}
+		}
+		if totalCombinations == 0 {
+			fmt.Println("\nEvery campaign of this configuration has already been created.")
+			return
+		}
+	}
+
+	// Targeting is checked per audience and placement first, so a mistake in the YAML is
+	// reported once instead of for every creative
+	if problems := generator.ValidateTargeting(); problems.Err() != nil {
+		fmt.Println()
+		printValidationProblems(problems)
+		os.Exit(1)
+	}
+	if !skipRemoteValidation {
+		checkInterests(cfg, generator.TargetingSpecs())
+	}
+
+	// Generated campaigns are validated like campaign files, before anything is created
+	invalid := 0
+	for _, combination := range generator.Combinations {
+		facebookCampaign := generator.ConvertToFacebookCampaign(combination)
+		if problems := checkCampaignConfig(facebookCampaign); problems.Err() != nil {
+			fmt.Printf("\nGenerated campaign %s:\n", combination.Name)
+			printValidationProblems(problems)
+			invalid++
+		}
+	}
+	if invalid > 0 {
+		fmt.Printf("\n%d of %d generated campaigns are invalid; fix the configuration or template\n", invalid, totalCombinations)
 		os.Exit(1)
 	}
-	fmt.Printf("Budget per test campaign: $%.2f\n", budgetPerCampaign)
 
 	// Create rate limiter for Facebook API calls
 	rateLimiter := optimization.NewRateLimiter()
 	rateLimiter.SetRequestInterval(500 * time.Millisecond) // Facebook's rate limit is relatively low
 
 	// Process all batches
-	if dryRun {
+	if dryRun && jsonOutput {
+		fmt.Println("\nDry run mode - generated campaign configurations:")
+		if err := writeGeneratedCampaigns(os.Stdout, generator); err != nil {
+			fmt.Printf("Error writing campaign configurations: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("\nNo campaigns were created (dry run mode)")
+	} else if dryRun {
 		fmt.Println("\nDry run mode - showing first batch without creating campaigns:")
 
 		// Just get the first batch for preview
@@ -1170,15 +1850,17 @@ func createTestCampaigns(cfg *config.Config, args []string) {
 		fmt.Println("\nNo campaigns were created (dry run mode)")
 	} else {
 		// Create auth client
-		authClient := auth.NewFacebookAuth(
-			cfg.AppID,
-			cfg.AppSecret,
-			cfg.AccessToken,
-			cfg.APIVersion,
-		)
+		authClient := newAuthClient(cfg)
 
 		// Create campaign creator
-		campaignCreator := internal_campaign.NewCampaignCreator(authClient, cfg.AccountID)
+		campaignCreator := newCampaignCreator(authClient, cfg)
+		batchCreator := internal_campaign.NewBatchCreator(campaignCreator)
+
+		// Every generated campaign has a single ad set
+		ensureAccountCapacity(api.NewClient(authClient, cfg.AccountID), cfg, api.ObjectCounts{
+			Campaigns: totalCombinations,
+			AdSets:    totalCombinations,
+		})
 
 		// Ask for confirmation before proceeding
 		fmt.Printf("\nThis will create %d test campaigns. Proceed? (y/n): ", totalCombinations)
@@ -1190,13 +1872,15 @@ func createTestCampaigns(cfg *config.Config, args []string) {
 		}
 
 		// Create a context with timeout for the entire operation
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		ctx, cancel := context.WithTimeout(cmdContext, 30*time.Minute)
 		defer cancel()
 
 		createdCount := 0
 		failedCount := 0
+		var failures []string
 
 		// Process all batches
+		throttled := false
 		for {
 			batch := generator.GetNextBatch()
 			if len(batch) == 0 {
@@ -1206,26 +1890,86 @@ func createTestCampaigns(cfg *config.Config, args []string) {
 			fmt.Printf("\nProcessing batch %d/%d (%d campaigns)...\n",
 				generator.CurrentBatch, totalBatches, len(batch))
 
+			// Convert to Facebook campaign configurations
+			facebookCampaigns := make([]*models.CampaignConfig, len(batch))
+			for i, combination := range batch {
+				facebookCampaigns[i] = generator.ConvertToFacebookCampaign(combination)
+			}
+
+			// With --use-batch the whole batch is created up front in as few calls as possible.
+			// Only an account throttle is returned to the rate limiter: retrying the batch for
+			// any other failure would create its successful campaigns twice.
+			var batchResults []internal_campaign.BatchResult
+			if useBatch {
+				err := rateLimiter.ExecuteForAccount(ctx, cfg.AccountID, func() error {
+					batchResults = batchCreator.CreateCampaignsContext(ctx, facebookCampaigns)
+					for _, result := range batchResults {
+						var throttle *auth.ThrottleError
+						if errors.As(result.Err, &throttle) && throttle.Scope == auth.ThrottleScopeAccount {
+							return result.Err
+						}
+					}
+					return nil
+				})
+				if batchResults == nil {
+					batchResults = make([]internal_campaign.BatchResult, len(batch))
+					for i := range batchResults {
+						batchResults[i].Err = err
+					}
+				}
+			}
+
 			for i, combination := range batch {
-				// Convert to Facebook campaign configuration
-				facebookCampaign := generator.ConvertToFacebookCampaign(combination)
+				facebookCampaign := facebookCampaigns[i]
 
 				fmt.Printf("[%d/%d] Creating campaign: %s... ",
 					createdCount+failedCount+1, totalCombinations, facebookCampaign.Name)
-				// Use i to avoid "not used" warning
-				_ = i
 
 				// Execute with rate limiting and retries
-				err := rateLimiter.Execute(ctx, func() error {
-					return campaignCreator.CreateFromConfig(facebookCampaign)
-				})
+				var campaignID string
+				var err error
+				if useBatch {
+					campaignID, err = batchResults[i].CampaignID, batchResults[i].Err
+				} else {
+					campaignID, err = createWithRetries(ctx, rateLimiter, cfg.AccountID, func() (string, error) {
+						return campaignCreator.CreateFromConfigWithIDContext(ctx, facebookCampaign)
+					})
+				}
+				if err != nil && campaignID != "" {
+					err = fmt.Errorf("%w (campaign %s was left incomplete)", err, campaignID)
+
+					// The campaign is recorded so --resume doesn't create the combination again
+					state.RecordIncomplete(combination, campaignID, facebookCampaign.Name, time.Now())
+					if err := state.Save(); err != nil {
+						fmt.Printf("Error saving creation state: %v\n", err)
+						fmt.Printf("Campaign %s is not recorded; delete it or it will be created again by --resume\n", campaignID)
+						os.Exit(1)
+					}
+				}
 
-				if err != nil {
+				var throttle *auth.ThrottleError
+				if errors.As(err, &throttle) && throttle.Scope == auth.ThrottleScopeAccount {
 					fmt.Printf("FAILED: %v\n", err)
 					failedCount++
+					failures = append(failures, fmt.Sprintf("%s: %v", facebookCampaign.Name, err))
+					throttled = true
+					if !useBatch {
+						break
+					}
+				} else if err != nil {
+					fmt.Printf("FAILED: %v\n", err)
+					failedCount++
+					failures = append(failures, fmt.Sprintf("%s: %v", facebookCampaign.Name, err))
 				} else {
 					fmt.Println("SUCCESS")
 					createdCount++
+
+					state.Record(combination, campaignID, facebookCampaign.Name, time.Now())
+					if err := state.Save(); err != nil {
+						fmt.Printf("Error saving creation state: %v\n", err)
+						fmt.Printf("Campaign %s is not recorded; delete it or it will be created again by --resume\n", campaignID)
+						os.Exit(1)
+					}
 				}
 
 				// Check if context was cancelled (timeout or user interrupt)
@@ -1237,6 +1981,12 @@ func createTestCampaigns(cfg *config.Config, args []string) {
 					// Continue with next campaign
 				}
 			}
+
+			// Results of a batch are all recorded before stopping, since the rest of it may exist
+			if throttled {
+				fmt.Println("\nStopping: the ad account is throttled. Re-run the command with --resume after the cool-down.")
+				break
+			}
 		}
 
 		// Print final summary
@@ -1245,38 +1995,84 @@ func createTestCampaigns(cfg *config.Config, args []string) {
 		fmt.Printf("  Failed: %d\n", failedCount)
 		fmt.Printf("  Total: %d\n", totalCombinations)
 
-		// For now, provide a placeholder message since we haven't fully implemented the API integration
-		if createdCount == 0 && failedCount == 0 {
-			fmt.Println("\nNote: Campaign creation functionality will be implemented in the next version.")
-			fmt.Println("This command currently simulates the creation process without making API calls.")
+		if len(failures) > 0 {
+			fmt.Println("\nFailed campaigns:")
+			for _, failure := range failures {
+				fmt.Printf("  - %s\n", failure)
+			}
+			if len(state.Incomplete()) > 0 {
+				fmt.Println("\nCampaigns left incomplete are recorded and not created again; finish or delete them.")
+			}
+			fmt.Println("\nRun again with --resume to create the remaining campaigns.")
+			os.Exit(1)
 		}
 	}
 }
 
-// updateCampaignCPM updates campaign CPM based on performance data
-func updateCampaignCPM(cfg *config.Config, args []string) {
-	if len(args) < 1 {
-		fmt.Println("Missing campaign IDs. Use: fbads optimize update <campaign_id1,campaign_id2,...> [--max-cpm=N]")
-		os.Exit(1)
+// createWithRetries creates a campaign through the rate limiter. Failures before the campaign
+// exists are retried; once create has returned a campaign ID, a retry would create a second
+// campaign, so the error is returned with the ID instead. Account throttles are still passed
+// to the rate limiter, which puts the account on cool-down without retrying.
+func createWithRetries(ctx context.Context, rateLimiter *optimization.RateLimiter, accountID string, create func() (string, error)) (string, error) {
+	var campaignID string
+	var createErr error
+	err := rateLimiter.ExecuteForAccount(ctx, accountID, func() error {
+		campaignID, createErr = create()
+		var throttle *auth.ThrottleError
+		if campaignID != "" && !(errors.As(createErr, &throttle) && throttle.Scope == auth.ThrottleScopeAccount) {
+			return nil
+		}
+		return createErr
+	})
+	if err == nil {
+		err = createErr
+	}
+	return campaignID, err
+}
+
+// writeGeneratedCampaigns writes the configuration of every remaining generated campaign as a
+// JSON array, in batch order
+func writeGeneratedCampaigns(w io.Writer, generator *optimization.CampaignGenerator) error {
+	campaigns := []*models.CampaignConfig{}
+	for batch := generator.GetNextBatch(); len(batch) > 0; batch = generator.GetNextBatch() {
+		for _, combination := range batch {
+			campaigns = append(campaigns, generator.ConvertToFacebookCampaign(combination))
+		}
 	}
 
-	campaignIDs := strings.Split(args[0], ",")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(campaigns)
+}
+
+// updateCampaignCPM updates campaign CPM based on performance data
+func updateCampaignCPM(cfg *config.Config, args []string) {
 	maxCPM := 15.0 // Default max CPM
 
 	// Parse optional flags
-	for i := 1; i < len(args); i++ {
-		switch {
-		case strings.HasPrefix(args[i], "--max-cpm="):
-			fmt.Sscanf(strings.TrimPrefix(args[i], "--max-cpm="), "%f", &maxCPM)
-		case args[i] == "--max-cpm" && i+1 < len(args):
-			fmt.Sscanf(args[i+1], "%f", &maxCPM)
-			i++
-		}
-	}
+	fs := newCommandFlags("optimize update <campaign_id1,campaign_id2,...> [options]")
+	fs.Float64Var(&maxCPM, "max-cpm", maxCPM, "Maximum CPM in dollars")
+	campaignIDs := strings.Split(parseCommandArgs(fs, args, 1, 1)[0], ",")
 
 	fmt.Printf("Processing CPM optimization for %d campaigns\n", len(campaignIDs))
 	fmt.Printf("Maximum CPM: $%.2f\n", maxCPM)
 
+	// Only campaigns with enough stored data are optimized
+	statsManager, clock := newOptimizationStatistics(cfg)
+	endDate := clock.Today()
+	startDate := endDate.AddDate(0, 0, -7)
+
+	validator := optimization.NewPerformanceValidator()
+	results := make(map[string]optimization.ValidationResult, len(campaignIDs))
+	for _, campaignID := range campaignIDs {
+		stats, err := statsManager.GetCampaignStatistics(campaignID, startDate, endDate)
+		if err != nil {
+			fmt.Printf("Error getting statistics for campaign %s: %v\n", campaignID, err)
+			os.Exit(1)
+		}
+		results[campaignID] = validator.ValidateCampaignData(campaignID, stats)
+	}
+
 	// This is placeholder code for the future implementation
 	// Will be implemented in the next version
 
@@ -1285,6 +2081,10 @@ func updateCampaignCPM(cfg *config.Config, args []string) {
 	// TODO: Implement CPM optimization logic with the API client
 
 	for _, campaignID := range campaignIDs {
+		if !results[campaignID].IsValid {
+			continue
+		}
+
 		fmt.Printf("Campaign %s: CPM optimization will be implemented in the next version\n", campaignID)
 
 		// In a real implementation, we would:
@@ -1292,23 +2092,66 @@ func updateCampaignCPM(cfg *config.Config, args []string) {
 		// 2. Calculate optimal CPM
 		// 3. Update the campaign's CPM if needed
 	}
+
+	renderSkippedSection(os.Stdout, results)
 }
 
-func configureApp(cfg *config.Config, configPath string) {
-	fmt.Println("Configuring application...")
+// configureApp prompts for the credentials of a profile and saves them. Without a
+// profile name the default profile is configured; pressing Enter keeps a stored value.
+func configureApp(profileName string, configPath string) {
+	// Edit the file as stored, without the credentials of the selected profile applied
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Printf("Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if profileName == "" {
+		profileName = cfg.DefaultProfile
+	}
+	if profileName == "" {
+		profileName = config.DefaultProfileName
+	}
+	if err := config.ValidateProfileName(profileName); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	profile, _ := cfg.GetProfile(profileName)
+
+	fmt.Printf("Configuring profile %s...\n", profileName)
 
 	// Simple configuration prompt (to be expanded)
 	fmt.Print("Enter Facebook App ID: ")
-	fmt.Scanln(&cfg.AppID)
+	fmt.Scanln(&profile.AppID)
 
 	fmt.Print("Enter Facebook App Secret: ")
-	fmt.Scanln(&cfg.AppSecret)
+	fmt.Scanln(&profile.AppSecret)
 
 	fmt.Print("Enter Facebook Access Token: ")
-	fmt.Scanln(&cfg.AccessToken)
+	fmt.Scanln(&profile.AccessToken)
 
 	fmt.Print("Enter Facebook Ad Account ID (without act_ prefix): ")
-	fmt.Scanln(&cfg.AccountID)
+	fmt.Scanln(&profile.AccountID)
+
+	// Short-lived tokens expire in about an hour, so swap them for a long-lived one
+	apiVersion := profile.APIVersion
+	if apiVersion == "" {
+		apiVersion = cfg.APIVersion
+	}
+	authClient := auth.NewFacebookAuth(profile.AppID, profile.AppSecret, profile.AccessToken, apiVersion)
+	profile.TokenExpiresAt = ""
+	if _, expires, err := authClient.ExchangeForLongLivedToken(); err != nil {
+		fmt.Printf("Warning: could not exchange the access token for a long-lived one: %v\n", err)
+		fmt.Println("The token is saved as entered and may expire within hours.")
+	} else {
+		profile.AccessToken = authClient.AccessToken
+		if !expires.IsZero() {
+			profile.TokenExpiresAt = expires.Format(time.RFC3339)
+			fmt.Printf("Exchanged the access token for a long-lived token, valid until %s\n", expires.Format("2006-01-02"))
+		} else {
+			fmt.Println("Exchanged the access token for a long-lived token")
+		}
+	}
+	cfg.SetProfile(profileName, profile)
 
 	// Save configuration
 	if err := cfg.SaveConfig(configPath); err != nil {
@@ -1319,20 +2162,85 @@ func configureApp(cfg *config.Config, configPath string) {
 	fmt.Println("Configuration saved successfully!")
 }
 
+// listProfiles prints the profiles stored in the profiles directory and the configuration file
+func listProfiles(configPath string) {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Printf("Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	names := cfg.ProfileNames()
+	if len(names) == 0 {
+		fmt.Println("No profiles configured. Run fbads config [--profile <name>] to add one.")
+		return
+	}
+
+	defaultProfile := cfg.DefaultProfile
+	if defaultProfile == "" {
+		defaultProfile = config.DefaultProfileName
+	}
+	nameWidth := len("Profile")
+	for _, name := range names {
+		nameWidth = max(nameWidth, len(name))
+	}
+
+	fmt.Printf("  %-*s | %s\n", nameWidth, "Profile", "Account ID")
+	fmt.Println(strings.Repeat("-", nameWidth+25))
+	for _, name := range names {
+		profile, _ := cfg.GetProfile(name)
+		marker := " "
+		if name == defaultProfile {
+			marker = "*"
+		}
+		fmt.Printf("%s %-*s | act_%s\n", marker, nameWidth, name, profile.AccountID)
+	}
+	fmt.Println("\n* default profile")
+}
+
+// dashboardShutdownTimeout is how long the dashboard waits for requests in progress when stopped
+const dashboardShutdownTimeout = 10 * time.Second
+
 func startDashboard(cfg *config.Config) {
-	// Parse optional port flag
+	// Parse optional port and refresh interval
 	port := 8080
-	if len(os.Args) >= 3 {
-		fmt.Sscanf(os.Args[2], "%d", &port)
+	refreshInterval := api.DefaultDashboardRefreshInterval
+	streamInterval := api.DefaultDashboardStreamInterval
+	bind := ""
+	dashboardAuth := api.DashboardAuth{
+		User:  os.Getenv("FBADS_DASHBOARD_USER"),
+		Pass:  os.Getenv("FBADS_DASHBOARD_PASSWORD"),
+		Token: os.Getenv("FBADS_DASHBOARD_TOKEN"),
+	}
+	fs := newCommandFlags("dashboard [port] [options]")
+	fs.DurationVar(&refreshInterval, "refresh", refreshInterval, "How often the dashboard data is refreshed, e.g. 15m or 1h")
+	fs.DurationVar(&streamInterval, "stream-interval", streamInterval, "How often open pages receive the dashboard data, e.g. 10s")
+	fs.StringVar(&bind, "bind", bind, "Address to listen on, e.g. 127.0.0.1 (default: every interface)")
+	fs.StringVar(&dashboardAuth.User, "user", dashboardAuth.User, "User for HTTP basic auth (default: $FBADS_DASHBOARD_USER)")
+	fs.StringVar(&dashboardAuth.Pass, "password", dashboardAuth.Pass, "Password for HTTP basic auth (default: $FBADS_DASHBOARD_PASSWORD)")
+	fs.StringVar(&dashboardAuth.Token, "token", dashboardAuth.Token, "Bearer token accepted instead of a password (default: $FBADS_DASHBOARD_TOKEN)")
+	positional := parseCommandArgs(fs, os.Args[2:], 0, 1)
+
+	if (dashboardAuth.User == "") != (dashboardAuth.Pass == "") {
+		fmt.Println("Error: basic auth needs both a user and a password")
+		os.Exit(1)
+	}
+
+	if refreshInterval <= 0 {
+		fmt.Printf("Invalid refresh interval: %s (use e.g. 15m or 1h)\n", refreshInterval)
+		os.Exit(1)
+	}
+	if len(positional) == 1 {
+		value, err := strconv.Atoi(positional[0])
+		if err != nil || value <= 0 || value > 65535 {
+			fmt.Printf("Invalid port: %s\n", positional[0])
+			os.Exit(1)
+		}
+		port = value
 	}
 
 	// Create auth client
-	authClient := auth.NewFacebookAuth(
-		cfg.AppID,
-		cfg.AppSecret,
-		cfg.AccessToken,
-		cfg.APIVersion,
-	)
+	authClient := newAuthClient(cfg)
 
 	// Create metrics collector
 	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
@@ -1342,6 +2250,8 @@ func startDashboard(cfg *config.Config) {
 
 	// Create performance analyzer
 	analyzer := api.NewPerformanceAnalyzer(metricsCollector, audienceAnalyzer)
+	analyzer.SetRecommendationThresholds(cfg.Recommendations)
+	analyzer.SetConversionValues(cfg.ConversionValues())
 
 	// Set dashboard directories
 	dashboardDir := filepath.Join(cfg.ConfigDir, "dashboard")
@@ -1350,6 +2260,15 @@ func startDashboard(cfg *config.Config) {
 
 	// Create dashboard
 	dashboard := api.NewDashboard(metricsCollector, analyzer, port, templateDir, dataDir)
+	dashboard.SetRefreshInterval(refreshInterval)
+	dashboard.SetStreamInterval(streamInterval)
+	dashboard.SetRefreshToken(os.Getenv("FBADS_API_TOKEN"))
+	dashboard.SetBindAddress(bind)
+	dashboard.SetStatisticsDir(cfg.StatisticsDir())
+	dashboard.SetAuth(dashboardAuth)
+	if !dashboardAuth.Enabled() {
+		fmt.Println("Warning: the dashboard is not protected; set --user and --password or --token, or --bind 127.0.0.1")
+	}
 
 	// Create dashboard files
 	if err := dashboard.CreateDashboardFiles(); err != nil {
@@ -1357,30 +2276,90 @@ func startDashboard(cfg *config.Config) {
 		os.Exit(1)
 	}
 
-	fmt.Printf("Starting dashboard on http://localhost:%d\n", port)
+	// Ctrl-C or SIGTERM lets the requests in progress finish before exiting
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-cmdContext.Done()
+		fmt.Println("\nStopping dashboard...")
+		ctx, cancel := context.WithTimeout(context.Background(), dashboardShutdownTimeout)
+		defer cancel()
+		if err := dashboard.Stop(ctx); err != nil {
+			fmt.Printf("Error stopping dashboard: %v\n", err)
+		}
+	}()
 
 	// Start dashboard
 	if err := dashboard.Start(); err != nil {
 		fmt.Printf("Error starting dashboard: %v\n", err)
 		os.Exit(1)
 	}
+	<-stopped
+	fmt.Println("Dashboard stopped.")
 }
 
 // exportCampaign exports a campaign by ID to a configuration file
-func exportCampaign(cfg *config.Config, campaignID string, args []string) {
-	// Determine output file name
-	outputFile := campaignID + ".json"
-	if len(args) > 0 {
-		outputFile = args[0]
+func exportCampaign(cfg *config.Config, args []string) {
+	showWarnings := false
+	profileName := ""
+	creativeLib := ""
+	all := false
+	dir := "."
+	concurrency := defaultExportConcurrency
+	formatName := ""
+	fs := newCommandFlags("export <campaign_id> [output_file] [options]\n       fbads export --all [--dir <dir>] [--concurrency <n>] [options]")
+	fs.BoolVar(&showWarnings, "show-warnings", false, "Print fields that could not be converted")
+	fs.StringVar(&profileName, "profile", "", "Export profile that selects the fields to keep")
+	fs.StringVar(&formatName, "format", "", "File format: json or yaml (default: by the output file extension, else json)")
+	fs.StringVar(&creativeLib, "creative-lib", "", "Move the creatives to a shared creative library file")
+	fs.BoolVar(&all, "all", false, "Export every campaign of the account, one file each")
+	fs.StringVar(&dir, "dir", dir, "Directory for the files of --all")
+	fs.IntVar(&concurrency, "concurrency", concurrency, "Campaigns fetched at once with --all")
+	positional := parseCommandFlags(fs, args)
+
+	profile, err := internal_campaign.ParseExportProfile(profileName)
+	if err != nil {
+		fmt.Printf("Invalid --profile value: %v\n", err)
+		os.Exit(1)
+	}
+	format, err := internal_campaign.ParseConfigFormat(formatName)
+	if err != nil {
+		fmt.Printf("Invalid --format value: %v\n", err)
+		os.Exit(1)
+	}
+
+	if all {
+		if len(positional) > 0 {
+			fmt.Println("Error: --all exports every campaign and takes no campaign ID")
+			fs.Usage()
+			os.Exit(1)
+		}
+		exportAll(cfg, dir, concurrency, profile, format, creativeLib)
+		return
+	}
+	if len(positional) == 0 {
+		fmt.Println("Missing arguments")
+		fs.Usage()
+		os.Exit(1)
+	}
+	if len(positional) > 2 {
+		fmt.Printf("Unexpected argument: %s\n", positional[2])
+		fs.Usage()
+		os.Exit(1)
+	}
+	campaignID := positional[0]
+
+	// Determine output file name; without --format a .yaml or .yml file is written as YAML
+	outputFile := campaignID + format.Extension()
+	if len(positional) == 2 {
+		outputFile = positional[1]
+		if formatName == "" {
+			format = internal_campaign.ConfigFormatFromPath(outputFile)
+		}
 	}
 
 	// Create auth client
-	authClient := auth.NewFacebookAuth(
-		cfg.AppID,
-		cfg.AppSecret,
-		cfg.AccessToken,
-		cfg.APIVersion,
-	)
+	authClient := newAuthClient(cfg)
 
 	// Create API client
 	client := api.NewClient(authClient, cfg.AccountID)
@@ -1394,11 +2373,18 @@ func exportCampaign(cfg *config.Config, campaignID string, args []string) {
 		os.Exit(1)
 	}
 
-	// Convert to a campaign configuration
-	config := convertToConfig(details)
+	// Unparseable timestamps are left out of the configuration
+	if showWarnings {
+		printParseWarnings(campaignID, details.Warnings)
+	} else if len(details.Warnings) > 0 {
+		fmt.Fprintf(os.Stderr, "Note: %d fields could not be parsed and were left out; use --show-warnings to list them\n", len(details.Warnings))
+	}
+
+	// Convert to a campaign configuration and reduce it to the profile
+	config, creatives := internal_campaign.ApplyExportProfile(internal_campaign.ConfigFromDetails(details), profile)
 
 	// Write to file
-	data, err := json.MarshalIndent(config, "", "  ")
+	data, err := internal_campaign.MarshalExportConfigFormat(config, profile, format)
 	if err != nil {
 		fmt.Printf("Error serializing configuration: %v\n", err)
 		os.Exit(1)
@@ -1409,44 +2395,60 @@ func exportCampaign(cfg *config.Config, campaignID string, args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Printf("Campaign exported successfully to: %s\n", outputFile)
+	fmt.Printf("Campaign exported successfully to: %s (profile: %s)\n", outputFile, profile)
+
+	// The structure profile leaves only creative references in the configuration
+	if profile == internal_campaign.ExportProfileStructure {
+		if creativeLib == "" {
+			fmt.Println("Note: creatives were replaced with creative_ref placeholders; use --creative-lib <file> to keep them")
+			return
+		}
+
+		if err := mergeCreativeLibrary(creativeLib, creatives); err != nil {
+			fmt.Printf("Error updating creative library: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Stored %d creatives in: %s\n", len(creatives), creativeLib)
+	}
+}
+
+// mergeCreativeLibrary adds creatives to the library file at path, creating it when missing
+func mergeCreativeLibrary(path string, creatives internal_campaign.CreativeLibrary) error {
+	library, err := internal_campaign.LoadCreativeLibrary(path)
+	if os.IsNotExist(err) {
+		library = make(internal_campaign.CreativeLibrary)
+	} else if err != nil {
+		return err
+	}
+
+	library.Merge(creatives)
+	return library.Save(path)
 }
 
 // exportCampaignYAML exports a campaign by ID to a YAML file for optimization
-func exportCampaignYAML(cfg *config.Config, campaignID string, args []string) {
+func exportCampaignYAML(cfg *config.Config, args []string) {
 	// Set up default export config
 	exporterConfig := optimization.DefaultExporterConfig()
 
+	// Parse arguments
+	fs := newCommandFlags("exportyaml <campaign_id> [output_file] [options]")
+	fs.Float64Var(&exporterConfig.TotalBudget, "budget", exporterConfig.TotalBudget, "Total budget in dollars")
+	fs.Float64Var(&exporterConfig.TestBudgetPercentage, "test-percent", exporterConfig.TestBudgetPercentage, "Share of the budget used for testing")
+	fs.Float64Var(&exporterConfig.MaxCPM, "max-cpm", exporterConfig.MaxCPM, "Maximum CPM in dollars")
+	positional := parseCommandArgs(fs, args, 1, 2)
+	campaignID := positional[0]
+
 	// Determine output file name
 	outputFile := campaignID + ".yaml"
-
-	// Parse arguments
-	for i := 0; i < len(args); i++ {
-		if args[i] == "--budget" && i+1 < len(args) {
-			fmt.Sscanf(args[i+1], "%f", &exporterConfig.TotalBudget)
-			i++
-		} else if args[i] == "--test-percent" && i+1 < len(args) {
-			fmt.Sscanf(args[i+1], "%f", &exporterConfig.TestBudgetPercentage)
-			i++
-		} else if args[i] == "--max-cpm" && i+1 < len(args) {
-			fmt.Sscanf(args[i+1], "%f", &exporterConfig.MaxCPM)
-			i++
-		} else if !strings.HasPrefix(args[i], "--") && i == 0 {
-			// First non-flag argument is the output file
-			outputFile = args[i]
-		}
+	if len(positional) == 2 {
+		outputFile = positional[1]
 	}
 
 	// Set output path
 	exporterConfig.OutputPath = outputFile
 
 	// Create auth client
-	authClient := auth.NewFacebookAuth(
-		cfg.AppID,
-		cfg.AppSecret,
-		cfg.AccessToken,
-		cfg.APIVersion,
-	)
+	authClient := newAuthClient(cfg)
 
 	// Create API client
 	client := api.NewClient(authClient, cfg.AccountID)
@@ -1480,31 +2482,13 @@ func exportCampaignYAML(cfg *config.Config, campaignID string, args []string) {
 func listPages(cfg *config.Config) {
 	// Parse flags
 	var format string
-
-	// Check for flags
-	args := os.Args[2:]
-	for i := 0; i < len(args); i++ {
-		switch args[i] {
-		case "--format", "-f":
-			if i+1 < len(args) {
-				format = args[i+1]
-				i++
-			}
-		}
-	}
-
-	// Set default format
-	if format == "" {
-		format = "table" // Default to table format
-	}
+	fs := newCommandFlags("pages [options]")
+	fs.StringVar(&format, "format", "table", "Output format: table, json or csv")
+	alias(fs, "f", "format")
+	parseCommandArgs(fs, os.Args[2:], 0, 0)
 
 	// Create auth client
-	authClient := auth.NewFacebookAuth(
-		cfg.AppID,
-		cfg.AppSecret,
-		cfg.AccessToken,
-		cfg.APIVersion,
-	)
+	authClient := newAuthClient(cfg)
 
 	// Create API client
 	client := api.NewClient(authClient, cfg.AccountID)
@@ -1609,161 +2593,69 @@ func displayPagesCSV(pages []models.Page) {
 	}
 }
 
-// convertToConfig converts campaign details to a configuration
-func convertToConfig(details *models.CampaignDetails) *models.CampaignConfig {
-	config := &models.CampaignConfig{
-		Name:                details.Name,
-		Status:              details.Status,
-		Objective:           details.ObjectiveType,
-		BuyingType:          details.BuyingType,
-		SpecialAdCategories: details.SpecialAdCategories,
-		BidStrategy:         details.BidStrategy,
-		DailyBudget:         details.DailyBudget,
-		LifetimeBudget:      details.LifetimeBudget,
-		AdSets:              []models.AdSetConfig{},
-		Ads:                 []models.AdConfig{},
-	}
-
-	// Add start/end times if available
-	if !details.StartTime.IsZero() {
-		config.StartTime = details.StartTime.Format(time.RFC3339)
-	}
-
-	if !details.StopTime.IsZero() {
-		config.EndTime = details.StopTime.Format(time.RFC3339)
-	}
-
-	// Process AdSets
-	for _, adset := range details.AdSets {
-		adsetConfig := models.AdSetConfig{
-			Name:             adset.Name,
-			Status:           adset.Status,
-			Targeting:        adset.Targeting,
-			OptimizationGoal: adset.OptimizationGoal,
-			BillingEvent:     adset.BillingEvent,
-			BidAmount:        adset.BidAmount,
-		}
-
-		// Add start/end times if available
-		if !adset.StartTime.IsZero() {
-			adsetConfig.StartTime = adset.StartTime.Format(time.RFC3339)
-		}
-
-		if !adset.EndTime.IsZero() {
-			adsetConfig.EndTime = adset.EndTime.Format(time.RFC3339)
-		}
-
-		config.AdSets = append(config.AdSets, adsetConfig)
-	}
-
-	// Process Ads
-	for _, ad := range details.Ads {
-		adConfig := models.AdConfig{
-			Name:   ad.Name,
-			Status: ad.Status,
-			Creative: models.CreativeConfig{
-				Name:         ad.Creative.Title, // Use name field for title value per API requirements
-				Body:         ad.Creative.Body,
-				ImageURL:     ad.Creative.ImageURL,
-				LinkURL:      ad.Creative.LinkURL,
-				CallToAction: ad.Creative.CallToActionType,
-				PageID:       ad.Creative.PageID,
-			},
-		}
-
-		config.Ads = append(config.Ads, adConfig)
-	}
-
-	return config
-}
-
 // updateCampaign handles updating an existing campaign
 func updateCampaign(cfg *config.Config) {
 	// Parse flags
 	var (
-		campaignID     string
-		status         string
-		name           string
-		dailyBudget    float64
-		lifetimeBudget float64
-		bidStrategy    string
-		jsonFile       string
+		campaignID   string
+		adSetID      string
+		jsonFile     string
+		switchBudget bool
 	)
 
-	// Skip the first two args (fbads update)
-	args := os.Args[2:]
+	// Field values from flags, applied in the order given; "none" clears a field
+	var fieldFlags []updateFlag
+	fs := newCommandFlags("update --id=CAMPAIGN_ID|--adset-id=ADSET_ID [options]")
+	fs.StringVar(&campaignID, "id", "", "Campaign ID to update")
+	fs.StringVar(&adSetID, "adset-id", "", "Ad set ID to update")
+	for _, f := range []struct{ name, field, usage string }{
+		{"status", "status", "New status (ACTIVE, PAUSED, ARCHIVED)"},
+		{"name", "name", "New campaign name"},
+		{"daily-budget", "daily_budget", "New daily budget (e.g., 50.00)"},
+		{"lifetime-budget", "lifetime_budget", "New lifetime budget (e.g., 1000.00)"},
+		{"bid-strategy", "bid_strategy", "New bid strategy (e.g., LOWEST_COST_WITHOUT_CAP)"},
+		{"end-time", "end_time", "New end date (YYYY-MM-DD), or none to remove it"},
+		{"spend-cap", "spend_cap", "New spend cap, or none to remove it"},
+		{"bid-cap", "bid_cap", "none removes the bid cap by switching to LOWEST_COST_WITHOUT_CAP"},
+		{"bid-amount", "bid_amount", "New bid amount (ad sets only, e.g., 4.50)"},
+	} {
+		field := f.field
+		fs.Func(f.name, f.usage, func(value string) error {
+			fieldFlags = append(fieldFlags, updateFlag{field, value})
+			return nil
+		})
+	}
+	fs.StringVar(&jsonFile, "file", "", "JSON file with update parameters (null clears a field)")
+	fs.BoolVar(&switchBudget, "switch-budget-type", false, "Allow switching between daily and lifetime budget")
+	parseCommandArgs(fs, os.Args[2:], 0, 0)
+
+	if campaignID != "" && adSetID != "" {
+		fmt.Println("Error: Use either --id or --adset-id, not both")
+		os.Exit(1)
+	}
 
-	// Handle flags
-	for i := 0; i < len(args); i++ {
-		switch {
-		case strings.HasPrefix(args[i], "--id="):
-			campaignID = strings.TrimPrefix(args[i], "--id=")
-		case args[i] == "--id" && i+1 < len(args):
-			campaignID = args[i+1]
-			i++
-		case strings.HasPrefix(args[i], "--status="):
-			status = strings.TrimPrefix(args[i], "--status=")
-		case args[i] == "--status" && i+1 < len(args):
-			status = args[i+1]
-			i++
-		case strings.HasPrefix(args[i], "--name="):
-			name = strings.TrimPrefix(args[i], "--name=")
-		case args[i] == "--name" && i+1 < len(args):
-			name = args[i+1]
-			i++
-		case strings.HasPrefix(args[i], "--daily-budget="):
-			fmt.Sscanf(strings.TrimPrefix(args[i], "--daily-budget="), "%f", &dailyBudget)
-		case args[i] == "--daily-budget" && i+1 < len(args):
-			fmt.Sscanf(args[i+1], "%f", &dailyBudget)
-			i++
-		case strings.HasPrefix(args[i], "--lifetime-budget="):
-			fmt.Sscanf(strings.TrimPrefix(args[i], "--lifetime-budget="), "%f", &lifetimeBudget)
-		case args[i] == "--lifetime-budget" && i+1 < len(args):
-			fmt.Sscanf(args[i+1], "%f", &lifetimeBudget)
-			i++
-		case strings.HasPrefix(args[i], "--bid-strategy="):
-			bidStrategy = strings.TrimPrefix(args[i], "--bid-strategy=")
-		case args[i] == "--bid-strategy" && i+1 < len(args):
-			bidStrategy = args[i+1]
-			i++
-		case strings.HasPrefix(args[i], "--file="):
-			jsonFile = strings.TrimPrefix(args[i], "--file=")
-		case args[i] == "--file" && i+1 < len(args):
-			jsonFile = args[i+1]
-			i++
-		}
+	// Ad sets have their own fields and checks
+	if adSetID != "" {
+		updateAdSet(cfg, adSetID, jsonFile, fieldFlags)
+		return
 	}
 
 	// Check if at least campaign ID is provided
 	if campaignID == "" {
-		fmt.Println("Error: Campaign ID is required")
-		fmt.Println("Usage: fbads update --id=CAMPAIGN_ID [options]")
-		fmt.Println("\nOptions:")
-		fmt.Println("  --id=ID                   Campaign ID to update (required)")
-		fmt.Println("  --status=STATUS           New status (ACTIVE, PAUSED, ARCHIVED)")
-		fmt.Println("  --name=NAME               New campaign name")
-		fmt.Println("  --daily-budget=BUDGET     New daily budget (e.g., 50.00)")
-		fmt.Println("  --lifetime-budget=BUDGET  New lifetime budget (e.g., 1000.00)")
-		fmt.Println("  --bid-strategy=STRATEGY   New bid strategy (e.g., LOWEST_COST_WITHOUT_CAP)")
-		fmt.Println("  --file=FILE               JSON file with update parameters")
+		fmt.Println("Error: Campaign ID or ad set ID is required")
+		fs.Usage()
 		os.Exit(1)
 	}
 
 	// Check if at least one update parameter is provided
-	if status == "" && name == "" && dailyBudget == 0 && lifetimeBudget == 0 &&
-		bidStrategy == "" && jsonFile == "" {
+	if len(fieldFlags) == 0 && jsonFile == "" {
 		fmt.Println("Error: At least one update parameter must be provided")
 		fmt.Println("Usage: fbads update --id=CAMPAIGN_ID [options]")
 		os.Exit(1)
 	}
 
 	// Create the Facebook auth object
-	authClient := auth.NewFacebookAuth(
-		cfg.AppID,
-		cfg.AppSecret,
-		cfg.AccessToken,
-		cfg.APIVersion,
-	)
+	authClient := newAuthClient(cfg)
 
 	// Create API client
 	client := api.NewClient(authClient, cfg.AccountID)
@@ -1788,42 +2680,28 @@ func updateCampaign(cfg *config.Config) {
 	}
 
 	// Add command-line parameters (these override file parameters)
-	if status != "" {
-		validStatuses := map[string]bool{"ACTIVE": true, "PAUSED": true, "ARCHIVED": true}
-		if !validStatuses[strings.ToUpper(status)] {
-			fmt.Printf("Invalid status: %s. Must be one of: ACTIVE, PAUSED, ARCHIVED\n", status)
+	for _, f := range fieldFlags {
+		if err := applyUpdateField(params, f.field, f.value); err != nil {
+			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
-		params.Set("status", strings.ToUpper(status))
-	}
-
-	if name != "" {
-		params.Set("name", name)
-	}
-
-	if dailyBudget > 0 {
-		// Convert to cents as required by the API
-		params.Set("daily_budget", fmt.Sprintf("%d", int(dailyBudget*100)))
-	}
-
-	if lifetimeBudget > 0 {
-		// Convert to cents as required by the API
-		params.Set("lifetime_budget", fmt.Sprintf("%d", int(lifetimeBudget*100)))
-	}
-
-	if bidStrategy != "" {
-		params.Set("bid_strategy", bidStrategy)
 	}
 
 	// Verify the campaign exists before updating
 	fmt.Printf("Verifying campaign %s exists...\n", campaignID)
-	_, verifyErr := client.GetCampaignDetails(campaignID)
+	details, verifyErr := client.GetCampaignDetails(campaignID)
 	if verifyErr != nil {
 		fmt.Printf("Error: Campaign not found or cannot be accessed: %v\n", verifyErr)
 		fmt.Println("Please check that the campaign ID is correct and you have permission to access it.")
 		os.Exit(1)
 	}
 
+	// The API rejects a daily budget on a lifetime budget campaign and vice versa
+	if err := checkBudgetCompatibility(details, params, switchBudget); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Make the API call to update the campaign
 	fmt.Printf("Updating campaign %s with parameters: %v\n", campaignID, params)
 	updateErr := client.UpdateCampaign(campaignID, params)
@@ -1835,62 +2713,60 @@ func updateCampaign(cfg *config.Config) {
 	fmt.Printf("Campaign %s updated successfully\n", campaignID)
 }
 
-// loadParamsFromFile loads campaign update parameters from a JSON file
-func loadParamsFromFile(filePath string) (url.Values, error) {
-	params := url.Values{}
-
-	// Read the file
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return params, fmt.Errorf("error reading file: %w", err)
+// campaignBudgetType returns "daily", "lifetime" or "" when the budget is set on the ad sets
+func campaignBudgetType(details *models.CampaignDetails) string {
+	switch {
+	case details.DailyBudget > 0:
+		return "daily"
+	case details.LifetimeBudget > 0:
+		return "lifetime"
 	}
+	return ""
+}
 
-	// Parse JSON
-	var updateConfig struct {
-		Status         string  `json:"status,omitempty"`
-		Name           string  `json:"name,omitempty"`
-		DailyBudget    float64 `json:"daily_budget,omitempty"`
-		LifetimeBudget float64 `json:"lifetime_budget,omitempty"`
-		BidStrategy    string  `json:"bid_strategy,omitempty"`
+// checkBudgetCompatibility verifies that a budget update matches the campaign's budget type.
+// With switchType the other budget is cleared so the campaign changes budget type.
+func checkBudgetCompatibility(details *models.CampaignDetails, params url.Values, switchType bool) error {
+	requested := ""
+	switch {
+	case params.Get("daily_budget") != "" && params.Get("lifetime_budget") != "":
+		return fmt.Errorf("cannot set both a daily and a lifetime budget")
+	case params.Get("daily_budget") != "":
+		requested = "daily"
+	case params.Get("lifetime_budget") != "":
+		requested = "lifetime"
+	default:
+		return nil // No budget change
 	}
 
-	if err := json.Unmarshal(data, &updateConfig); err != nil {
-		return params, fmt.Errorf("error parsing JSON: %w", err)
+	current := campaignBudgetType(details)
+	if current == "" || current == requested {
+		return nil
 	}
 
-	// Add parameters
-	if updateConfig.Status != "" {
-		validStatuses := map[string]bool{"ACTIVE": true, "PAUSED": true, "ARCHIVED": true}
-		status := strings.ToUpper(updateConfig.Status)
-		if !validStatuses[status] {
-			return params, fmt.Errorf("invalid status: %s. Must be one of: ACTIVE, PAUSED, ARCHIVED", status)
+	if !switchType {
+		currentAmount := details.DailyBudget
+		if current == "lifetime" {
+			currentAmount = details.LifetimeBudget
 		}
-		params.Set("status", status)
-	}
-
-	if updateConfig.Name != "" {
-		params.Set("name", updateConfig.Name)
-	}
-
-	if updateConfig.DailyBudget > 0 {
-		// Convert to cents as required by the API
-		params.Set("daily_budget", fmt.Sprintf("%d", int(updateConfig.DailyBudget*100)))
+		return fmt.Errorf("campaign %s uses a %s budget ($%.2f), so a %s budget cannot be set. "+
+			"Use --%s-budget instead, or pass --switch-budget-type to change the budget type",
+			details.ID, current, currentAmount/100, requested, current)
 	}
 
-	if updateConfig.LifetimeBudget > 0 {
-		// Convert to cents as required by the API
-		params.Set("lifetime_budget", fmt.Sprintf("%d", int(updateConfig.LifetimeBudget*100)))
+	// Lifetime budgets need an end date
+	if requested == "lifetime" && details.StopTime.IsZero() {
+		return fmt.Errorf("campaign %s has no end date, which a lifetime budget requires", details.ID)
 	}
 
-	if updateConfig.BidStrategy != "" {
-		params.Set("bid_strategy", updateConfig.BidStrategy)
-	}
+	fmt.Printf("Switching campaign %s from a %s to a %s budget\n", details.ID, current, requested)
+	params.Set(current+"_budget", "0")
 
-	return params, nil
+	return nil
 }
 
 // duplicateCampaign handles duplicating a campaign with all its internals
-func duplicateCampaign(cfg *config.Config, campaignID string, args []string) {
+func duplicateCampaign(cfg *config.Config, args []string) {
 	// Parse flags
 	var (
 		campaignName string
@@ -1899,48 +2775,27 @@ func duplicateCampaign(cfg *config.Config, campaignID string, args []string) {
 		endDateStr   string
 		budgetFactor float64 = 1.0 // Default to same budget
 		dryRun       bool
+		targetID     string
+		reuse        bool
 	)
 
 	// Handle flags
-	for i := 0; i < len(args); i++ {
-		switch {
-		case strings.HasPrefix(args[i], "--name="):
-			campaignName = strings.TrimPrefix(args[i], "--name=")
-		case args[i] == "--name" && i+1 < len(args):
-			campaignName = args[i+1]
-			i++
-		case strings.HasPrefix(args[i], "--status="):
-			status = strings.TrimPrefix(args[i], "--status=")
-		case args[i] == "--status" && i+1 < len(args):
-			status = args[i+1]
-			i++
-		case strings.HasPrefix(args[i], "--start="):
-			startDateStr = strings.TrimPrefix(args[i], "--start=")
-		case args[i] == "--start" && i+1 < len(args):
-			startDateStr = args[i+1]
-			i++
-		case strings.HasPrefix(args[i], "--end="):
-			endDateStr = strings.TrimPrefix(args[i], "--end=")
-		case args[i] == "--end" && i+1 < len(args):
-			endDateStr = args[i+1]
-			i++
-		case strings.HasPrefix(args[i], "--budget-factor="):
-			fmt.Sscanf(strings.TrimPrefix(args[i], "--budget-factor="), "%f", &budgetFactor)
-		case args[i] == "--budget-factor" && i+1 < len(args):
-			fmt.Sscanf(args[i+1], "%f", &budgetFactor)
-			i++
-		case args[i] == "--dry-run" || args[i] == "-d":
-			dryRun = true
-		}
-	}
+	fs := newCommandFlags("duplicate <campaign_id> [options]")
+	fs.StringVar(&campaignName, "name", "", "Name of the copy (default: Copy of <original name>)")
+	fs.StringVar(&status, "status", status, "Status of the copy")
+	fs.StringVar(&startDateStr, "start", "", "Start date of the copy (YYYY-MM-DD)")
+	fs.StringVar(&endDateStr, "end", "", "End date of the copy (YYYY-MM-DD)")
+	fs.Float64Var(&budgetFactor, "budget-factor", budgetFactor, "Multiplier applied to the budgets")
+	fs.BoolVar(&dryRun, "dry-run", false, "Preview the copy without creating it")
+	alias(fs, "d", "dry-run")
+	fs.StringVar(&targetID, "target-account", "", "Ad account to create the copy in (default: the source account)")
+	fs.BoolVar(&reuse, "reuse-creatives", false, "Show the original creatives in the copy instead of recreating them, keeping their likes and comments")
+	campaignID := parseCommandArgs(fs, args, 1, 1)[0]
+
+	status = mustParseSettableStatus(status)
 
 	// Create auth client
-	authClient := auth.NewFacebookAuth(
-		cfg.AppID,
-		cfg.AppSecret,
-		cfg.AccessToken,
-		cfg.APIVersion,
-	)
+	authClient := newAuthClient(cfg)
 
 	// Create API client
 	client := api.NewClient(authClient, cfg.AccountID)
@@ -1954,23 +2809,26 @@ func duplicateCampaign(cfg *config.Config, campaignID string, args []string) {
 		os.Exit(1)
 	}
 
-	// If no custom name provided, create a default name
-	if campaignName == "" {
-		campaignName = "Copy of " + details.Name
+	// The copy is created in the target account, which defaults to the source account
+	targetCfg := *cfg
+	if targetID = strings.TrimPrefix(targetID, "act_"); targetID != "" {
+		targetCfg.AccountID = targetID
+	}
+	crossAccount := targetCfg.AccountID != cfg.AccountID
+	targetClient := api.NewClient(authClient, targetCfg.AccountID)
+	if crossAccount && reuse {
+		fmt.Println("Error: --reuse-creatives can't be used with --target-account, creatives belong to the account they were created in")
+		os.Exit(1)
 	}
 
-	// Convert to a campaign configuration
-	campaignConfig := convertToConfig(details)
-
-	// For duplication, we need to ensure we're not carrying over any IDs
-	// The Create function will assign new IDs
-
-	// Remove any unsupported fields from creatives based on recent API changes
-	// The Facebook API error shows that image_url is no longer supported in link_data
-
-	// Update the campaign config with the new parameters
-	campaignConfig.Name = campaignName
-	campaignConfig.Status = status
+	// Build the configuration for the copy
+	campaignConfig := internal_campaign.DuplicateConfig(details, internal_campaign.DuplicateOptions{
+		Name:           campaignName,
+		Status:         status,
+		BudgetFactor:   budgetFactor,
+		CrossAccount:   crossAccount,
+		ReuseCreatives: reuse,
+	})
 
 	// Parse and update dates if provided
 	if startDateStr != "" {
@@ -1991,59 +2849,31 @@ func duplicateCampaign(cfg *config.Config, campaignID string, args []string) {
 		campaignConfig.EndTime = endDate.Format(time.RFC3339)
 	}
 
-	// Fix budget values: when retrieved from Facebook, budgets are in cents
-	// but the CampaignConfig expects dollars for display
-	if campaignConfig.DailyBudget > 0 {
-		// Convert from cents to dollars (e.g., 2000 cents -> $20.00)
-		campaignConfig.DailyBudget = campaignConfig.DailyBudget / 100
-	}
-
-	if campaignConfig.LifetimeBudget > 0 {
-		// Convert from cents to dollars (e.g., 2000 cents -> $20.00)
-		campaignConfig.LifetimeBudget = campaignConfig.LifetimeBudget / 100
-	}
-
-	// Apply budget factor after the conversion
-	if budgetFactor != 1.0 {
-		if campaignConfig.DailyBudget > 0 {
-			campaignConfig.DailyBudget = campaignConfig.DailyBudget * budgetFactor
-		}
-		if campaignConfig.LifetimeBudget > 0 {
-			campaignConfig.LifetimeBudget = campaignConfig.LifetimeBudget * budgetFactor
-		}
-	}
-
-	// Clear any ID fields from the AdSets and Ads to ensure new ones are created
-	for i := range campaignConfig.AdSets {
-		// Update ad set names to indicate they're copies
-		if !strings.HasPrefix(campaignConfig.AdSets[i].Name, "Copy of ") {
-			campaignConfig.AdSets[i].Name = "Copy of " + campaignConfig.AdSets[i].Name
+	// Validate the copy, reporting every problem at once
+	problems := checkCampaignConfig(campaignConfig)
+	if crossAccount {
+		// Pages, audiences and images must be usable from the target account, or the
+		// copy would fail after some of its objects were created
+		fmt.Printf("Checking references against ad account %s...\n", targetCfg.AccountID)
+		problems = append(problems, internal_campaign.CheckCrossAccountImages(campaignConfig)...)
+		references, err := targetClient.CheckAccountReferences(campaignConfig)
+		if err != nil {
+			fmt.Printf("Error checking ad account %s: %v\n", targetCfg.AccountID, err)
+			os.Exit(1)
 		}
-		// Set the status to match the campaign
-		campaignConfig.AdSets[i].Status = status
+		problems = append(problems, references...)
 	}
-
-	for i := range campaignConfig.Ads {
-		// Update ad names to indicate they're copies
-		if !strings.HasPrefix(campaignConfig.Ads[i].Name, "Copy of ") {
-			campaignConfig.Ads[i].Name = "Copy of " + campaignConfig.Ads[i].Name
-		}
-		// Set the status to match the campaign
-		campaignConfig.Ads[i].Status = status
-
-		// Remove ImageURL field which is no longer supported by the Facebook API
-		// This fixes the error "The field image_url is not supported in the field link_data of object_story_spec"
-		campaignConfig.Ads[i].Creative.ImageURL = ""
-
-		// Ensure the LinkURL is not empty
-		if campaignConfig.Ads[i].Creative.LinkURL == "" {
-			fmt.Println("Warning: Link URL is empty in ad creative. Setting a default link to prevent API error.")
-			campaignConfig.Ads[i].Creative.LinkURL = "https://corespirit.com/funnels/pract"
-		}
+	if problems.Err() != nil {
+		printValidationProblems(problems)
+		os.Exit(1)
 	}
+	printValidationWarnings(problems)
 
 	// Print configuration summary
 	fmt.Println("\nDuplicated Campaign Configuration Summary:")
+	if crossAccount {
+		fmt.Printf("Target ad account: %s\n", targetCfg.AccountID)
+	}
 	printCampaignConfigSummary(campaignConfig)
 
 	// If dry run, just print configuration summary and exit
@@ -2052,6 +2882,8 @@ func duplicateCampaign(cfg *config.Config, campaignID string, args []string) {
 		return
 	}
 
+	ensureAccountCapacity(targetClient, &targetCfg, plannedObjects([]*models.CampaignConfig{campaignConfig}))
+
 	// Ask for confirmation
 	fmt.Print("\nDo you want to create this duplicated campaign? (y/n): ")
 	var confirm string
@@ -2063,7 +2895,7 @@ func duplicateCampaign(cfg *config.Config, campaignID string, args []string) {
 	}
 
 	// Create campaign creator
-	creator := internal_campaign.NewCampaignCreator(authClient, cfg.AccountID)
+	creator := newCampaignCreator(authClient, &targetCfg)
 
 	fmt.Println("Creating duplicated campaign...")
 
@@ -2080,21 +2912,21 @@ func duplicateCampaign(cfg *config.Config, campaignID string, args []string) {
 // handleStatistics processes statistics subcommands
 func handleStatistics(cfg *config.Config, subCmd string, args []string) {
 	// Create auth client
-	authClient := auth.NewFacebookAuth(
-		cfg.AppID,
-		cfg.AppSecret,
-		cfg.AccessToken,
-		cfg.APIVersion,
-	)
+	authClient := newAuthClient(cfg)
 
 	// Create metrics collector
 	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
 
-	// Set default storage directory
-	statsDir := filepath.Join(cfg.ConfigDir, "stats")
+	// Storage type and directory come from the config
+	storageType, err := api.ParseStorageType(cfg.Statistics.Storage)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Create statistics manager
-	statsManager := api.NewStatisticsManager(metricsCollector, api.StorageTypeFile, statsDir)
+	statsManager := api.NewStatisticsManager(metricsCollector, storageType, cfg.StatisticsDir())
+	statsManager.SetConversionValues(cfg.ConversionValues())
 
 	// Parse common flags
 	var (
@@ -2104,51 +2936,36 @@ func handleStatistics(cfg *config.Config, subCmd string, args []string) {
 		outputFile   string
 		days         int    = 30     // Default to 30 days
 		format       string = "json" // Default format
+		overwrite    bool
+		full         bool
 	)
 
 	// Process flags
-	for i := 0; i < len(args); i++ {
-		switch args[i] {
-		case "--start", "-s":
-			if i+1 < len(args) {
-				startDateStr = args[i+1]
-				i++
-			}
-		case "--end", "-e":
-			if i+1 < len(args) {
-				endDateStr = args[i+1]
-				i++
-			}
-		case "--days", "-d":
-			if i+1 < len(args) {
-				fmt.Sscanf(args[i+1], "%d", &days)
-				i++
-			}
-		case "--campaign", "-c":
-			if i+1 < len(args) {
-				campaignID = args[i+1]
-				i++
-			}
-		case "--output", "-o":
-			if i+1 < len(args) {
-				outputFile = args[i+1]
-				i++
-			}
-		case "--format", "-f":
-			if i+1 < len(args) {
-				format = args[i+1]
-				i++
-			}
-		}
-	}
+	fs := newCommandFlags("stats " + subCmd + " [options]")
+	fs.StringVar(&startDateStr, "start", "", "Start date (YYYY-MM-DD)")
+	alias(fs, "s", "start")
+	alias(fs, "since", "start")
+	fs.StringVar(&endDateStr, "end", "", "End date (YYYY-MM-DD, default: yesterday)")
+	alias(fs, "e", "end")
+	alias(fs, "until", "end")
+	fs.IntVar(&days, "days", days, "Number of days before today when no start date is given")
+	alias(fs, "d", "days")
+	fs.StringVar(&campaignID, "campaign", "", "Campaign to analyze or validate")
+	alias(fs, "c", "campaign")
+	fs.StringVar(&outputFile, "output", "", "Output file")
+	alias(fs, "o", "output")
+	fs.StringVar(&format, "format", format, "Output format")
+	alias(fs, "f", "format")
+	fs.BoolVar(&overwrite, "overwrite", false, "Replace statistics that are already stored")
+	fs.BoolVar(&full, "full", false, "Collect every day instead of only the missing ones")
+	parseCommandArgs(fs, args, 0, 0)
 
 	// Set default date range if not specified
 	var startDate, endDate time.Time
-	var err error
 
 	if startDateStr == "" {
-		// Default start date (30 days ago or as specified by --days)
-		startDate = time.Now().AddDate(0, 0, -days)
+		// Default start date (30 days ago or as specified by --days), in the account timezone
+		startDate = metricsCollector.Clock().Today().AddDate(0, 0, -days)
 	} else {
 		startDate, err = time.Parse("2006-01-02", startDateStr)
 		if err != nil {
@@ -2159,8 +2976,8 @@ func handleStatistics(cfg *config.Config, subCmd string, args []string) {
 	}
 
 	if endDateStr == "" {
-		// Default end date (yesterday)
-		endDate = time.Now().AddDate(0, 0, -1)
+		// Default end date (yesterday in the account timezone)
+		endDate = metricsCollector.Clock().Yesterday()
 	} else {
 		endDate, err = time.Parse("2006-01-02", endDateStr)
 		if err != nil {
@@ -2173,7 +2990,7 @@ func handleStatistics(cfg *config.Config, subCmd string, args []string) {
 	// Process subcommand
 	switch subCmd {
 	case "collect":
-		collectStatistics(statsManager, startDate, endDate)
+		collectStatistics(statsManager, startDate, endDate, full)
 	case "analyze":
 		analyzeStatistics(statsManager, startDate, endDate, campaignID, format)
 	case "export":
@@ -2186,15 +3003,20 @@ func handleStatistics(cfg *config.Config, subCmd string, args []string) {
 		exportStatistics(statsManager, startDate, endDate, outputFile)
 	case "validate":
 		validateCampaignData(statsManager, startDate, endDate, campaignID, format)
+	case "insights":
+		exportInsights(metricsCollector, startDate, endDate, format, outputFile)
+	case "backfill":
+		backfillStatistics(statsManager, startDate, endDate, overwrite)
 	default:
 		fmt.Printf("Unknown stats subcommand: %s\n", subCmd)
-		fmt.Println("Available subcommands: collect, analyze, export, validate")
+		fmt.Println("Available subcommands: collect, analyze, export, insights, validate, backfill")
 		os.Exit(1)
 	}
 }
 
-// collectStatistics collects metrics for the given date range
-func collectStatistics(statsManager *api.StatisticsManager, startDate, endDate time.Time) {
+// collectStatistics collects metrics for the given date range.
+// Only campaigns with delivery are fetched unless full is set.
+func collectStatistics(statsManager *api.StatisticsManager, startDate, endDate time.Time, full bool) {
 	fmt.Printf("Collecting campaign statistics from %s to %s...\n",
 		startDate.Format("2006-01-02"),
 		endDate.Format("2006-01-02"))
@@ -2211,10 +3033,12 @@ func collectStatistics(statsManager *api.StatisticsManager, startDate, endDate t
 		}
 
 		fmt.Printf("Collecting data for %s...\n", current.Format("2006-01-02"))
-		err := statsManager.CollectAndStoreStatistics(timeRange)
+		result, err := statsManager.CollectAndStoreStatistics(timeRange, api.CollectOptions{Full: full})
 		if err != nil {
 			fmt.Printf("Error collecting data for %s: %v\n", current.Format("2006-01-02"), err)
 			collectErrors = append(collectErrors, fmt.Sprintf("%s: %v", current.Format("2006-01-02"), err))
+		} else if !full {
+			fmt.Printf("  %d campaigns with delivery, %d stopped since the previous day\n", result.ActiveCampaigns, result.ZeroRows)
 		}
 
 		// Move to next day
@@ -2232,6 +3056,79 @@ func collectStatistics(statsManager *api.StatisticsManager, startDate, endDate t
 	}
 }
 
+// exportInsights streams daily campaign insights from the API to a CSV or NDJSON file
+func exportInsights(metricsCollector *api.MetricsCollector, startDate, endDate time.Time, format, outputFile string) {
+	if endDate.Before(startDate) {
+		fmt.Println("End date must not be before start date")
+		os.Exit(1)
+	}
+
+	// Progress goes to stderr so stdout can be piped
+	out := io.Writer(os.Stdout)
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			fmt.Printf("Error creating output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	fmt.Fprintf(os.Stderr, "Exporting daily insights from %s to %s...\n",
+		startDate.Format("2006-01-02"),
+		endDate.Format("2006-01-02"))
+
+	rows, err := metricsCollector.ExportDailyInsightsContext(cmdContext, out, api.TimeRange{
+		Since: startDate.Format("2006-01-02"),
+		Until: endDate.Format("2006-01-02"),
+	}, format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting insights after %d rows: %v\n", rows, err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d rows\n", rows)
+}
+
+// backfillStatistics imports historical daily insights into the statistics store
+func backfillStatistics(statsManager *api.StatisticsManager, startDate, endDate time.Time, overwrite bool) {
+	if endDate.Before(startDate) {
+		fmt.Println("End date must not be before start date")
+		os.Exit(1)
+	}
+
+	totalDays := int(endDate.Sub(startDate).Hours()/24) + 1
+	fmt.Printf("Backfilling campaign statistics from %s to %s (%d days)...\n",
+		startDate.Format("2006-01-02"),
+		endDate.Format("2006-01-02"),
+		totalDays)
+
+	done := 0
+	result, err := statsManager.BackfillContext(cmdContext, startDate, endDate, api.BackfillOptions{
+		Overwrite: overwrite,
+		Progress: func(day time.Time, records int) {
+			done++
+			fmt.Printf("  [%d] %s: %d campaigns\n", done, day.Format("2006-01-02"), records)
+		},
+	})
+
+	if result != nil {
+		fmt.Printf("\nBackfill summary:\n")
+		fmt.Printf("  Days imported: %d\n", result.DaysImported)
+		fmt.Printf("  Days skipped (already stored): %d\n", result.DaysSkipped)
+		fmt.Printf("  Records stored: %d\n", result.Records)
+	}
+
+	if err != nil {
+		fmt.Printf("\nError during backfill: %v\n", err)
+		fmt.Println("Run the same command again to resume; completed days are skipped.")
+		os.Exit(1)
+	}
+
+	fmt.Println("\nStatistics backfill completed successfully!")
+}
+
 // analyzeStatistics analyzes campaign performance for the given date range
 func analyzeStatistics(statsManager *api.StatisticsManager, startDate, endDate time.Time, campaignID, format string) {
 	if campaignID != "" {
@@ -2257,10 +3154,10 @@ func analyzeStatistics(statsManager *api.StatisticsManager, startDate, endDate t
 		case "json":
 			displayStatisticsJSON(stats)
 		case "table":
-			displayCampaignStatisticsTable(stats)
+			displayCampaignStatisticsTable(stats, statsManager.ConversionValues())
 		default:
 			fmt.Printf("Unsupported format: %s. Using table format.\n", format)
-			displayCampaignStatisticsTable(stats)
+			displayCampaignStatisticsTable(stats, statsManager.ConversionValues())
 		}
 
 	} else {
@@ -2290,6 +3187,10 @@ func analyzeStatistics(statsManager *api.StatisticsManager, startDate, endDate t
 			fmt.Printf("Unsupported format: %s. Using table format.\n", format)
 			displayAnalysisTable(analysis)
 		}
+
+		if analysis.SkippedRecords > 0 {
+			fmt.Fprintf(os.Stderr, "Note: %d stored records without a timestamp were left out of the analysis\n", analysis.SkippedRecords)
+		}
 	}
 }
 
@@ -2304,8 +3205,9 @@ func displayStatisticsJSON(stats []utils.CampaignPerformance) {
 	fmt.Println(string(data))
 }
 
-// displayCampaignStatisticsTable displays campaign performance data in a table format
-func displayCampaignStatisticsTable(stats []utils.CampaignPerformance) {
+// displayCampaignStatisticsTable displays campaign performance data in a table format.
+// ROAS is based on the reported conversion values, or the conversions valued at values.
+func displayCampaignStatisticsTable(stats []utils.CampaignPerformance, values models.ConversionValues) {
 	if len(stats) == 0 {
 		fmt.Println("No statistics available.")
 		return
@@ -2332,10 +3234,15 @@ func displayCampaignStatisticsTable(stats []utils.CampaignPerformance) {
 	totalClicks := 0
 	totalSpend := 0.0
 	totalConversions := 0
+	totalValue := 0.0
 
 	// Sort by date
 	sortPerformancesByDate(stats)
 
+	for i := range stats {
+		values.Apply(&stats[i])
+	}
+
 	for _, stat := range stats {
 		fmt.Printf("%-10s | %-10d | %-10d | %-8.2f | %-6.2f | %-8.2f | %-8.2f | %-8d | %-8.2f\n",
 			stat.LastUpdated.Format("2006-01-02"),
@@ -2352,6 +3259,7 @@ func displayCampaignStatisticsTable(stats []utils.CampaignPerformance) {
 		totalClicks += stat.Clicks
 		totalSpend += stat.Spend
 		totalConversions += stat.Conversions
+		totalValue += stat.ConversionValue
 	}
 
 	// Print totals
@@ -2378,10 +3286,8 @@ func displayCampaignStatisticsTable(stats []utils.CampaignPerformance) {
 		avgCPC = totalSpend / float64(totalClicks)
 	}
 
-	if totalSpend > 0 && totalConversions > 0 {
-		// Simplified ROAS calculation
-		avgOrderValue := 50.0 // Example value, same as in the analyzer
-		avgROAS = float64(totalConversions) * avgOrderValue / totalSpend
+	if totalSpend > 0 {
+		avgROAS = totalValue / totalSpend
 	}
 
 	fmt.Printf("%-10s | %-10d | %-10d | %-8.2f | %-6.2f | %-8.2f | %-8.2f | %-8d | %-8.2f\n",
@@ -2394,6 +3300,10 @@ func displayCampaignStatisticsTable(stats []utils.CampaignPerformance) {
 		avgCPC,
 		totalConversions,
 		avgROAS)
+
+	if method := values.Method(stats); method != "" {
+		fmt.Printf("\nRevenue for ROAS %s\n", method)
+	}
 }
 
 // sortPerformancesByDate sorts campaign performances by date
@@ -2437,6 +3347,9 @@ func displayAnalysisTable(analysis *api.AggregateStatistics) {
 	if analysis.TotalConversions > 0 {
 		fmt.Printf("Average CPA: $%.2f\n", analysis.AvgCPA)
 	}
+	if analysis.ROIMethod != "" {
+		fmt.Printf("Revenue for ROI %s\n", analysis.ROIMethod)
+	}
 
 	// Print trend summary if available
 	if analysis.TrendImpressions != nil && len(analysis.TrendImpressions.Values) > 1 {
@@ -2737,50 +3650,15 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%dm", minutes)
 }
 
-// deleteCampaign deletes a campaign by ID
-func deleteCampaign(cfg *config.Config, campaignID string) {
-	// Create auth client
-	authClient := auth.NewFacebookAuth(
-		cfg.AppID,
-		cfg.AppSecret,
-		cfg.AccessToken,
-		cfg.APIVersion,
-	)
-
-	// Create API client
-	client := api.NewClient(authClient, cfg.AccountID)
-
-	// Verify the campaign exists before deleting
-	fmt.Printf("Verifying campaign %s exists...\n", campaignID)
-	campaign, verifyErr := client.GetCampaignDetails(campaignID)
-	if verifyErr != nil {
-		fmt.Printf("Error: Campaign not found or cannot be accessed: %v\n", verifyErr)
-		fmt.Println("Please check that the campaign ID is correct and you have permission to access it.")
-		os.Exit(1)
-	}
-
-	fmt.Printf("Found campaign: %s (Status: %s)\n", campaign.Name, campaign.Status)
-
-	// Ask for confirmation before proceeding
-	fmt.Printf("\nWARNING: This will permanently delete the campaign. This action cannot be undone.\n")
-	fmt.Print("Are you sure you want to delete this campaign? (y/n): ")
-	var confirm string
-	fmt.Scanln(&confirm)
-	
-	if confirm != "y" && confirm != "Y" && confirm != "yes" && confirm != "Yes" {
-		fmt.Println("Campaign deletion cancelled.")
-		return
-	}
-
-	// Delete the campaign
-	fmt.Printf("Deleting campaign %s...\n", campaignID)
-	err := client.DeleteCampaign(campaignID)
-	if err != nil {
-		fmt.Printf("Error deleting campaign: %v\n", err)
-		os.Exit(1)
+// ownedCampaigns returns the campaigns carrying the fbads ownership label
+func ownedCampaigns(campaigns []models.Campaign) []models.Campaign {
+	owned := make([]models.Campaign, 0)
+	for _, campaign := range campaigns {
+		if internal_campaign.IsOwned(campaign.Labels) {
+			owned = append(owned, campaign)
+		}
 	}
-
-	fmt.Printf("Campaign %s deleted successfully\n", campaignID)
+	return owned
 }
 
 func printUsage() {
@@ -2791,9 +3669,24 @@ func printUsage() {
 	fmt.Println("    --limit, -l <num>      Limit the number of results (default: 10)")
 	fmt.Println("    --status, -s <status>  Filter by status (ACTIVE, PAUSED, etc.)")
 	fmt.Println("    --format, -f <format>  Output format (table, json, csv)")
+	fmt.Println("    --show-warnings        List fields that could not be parsed")
+	fmt.Println("    --mine                 Only campaigns created by fbads (labeled fbads:v<version>)")
+	fmt.Println("    --with-insights        Add spend, impressions, clicks, CTR and conversions")
+	fmt.Println("    --since <30d|date>     Start of the insights period (default: 30d)")
+	fmt.Println("    --until <date>         End of the insights period (default: yesterday)")
+	fmt.Println("")
+	fmt.Println("  adsets <campaign_id>     List the ad sets of a campaign")
+	fmt.Println("    --status, -s <status>  Filter by status (ACTIVE, PAUSED, etc.)")
+	fmt.Println("    --format, -f <format>  Output format (table, json, csv)")
+	fmt.Println("    --with-metrics         Add spend, impressions and CPC")
+	fmt.Println("    --since <7d|date>      Start of the metrics period (default: 30d)")
 	fmt.Println("")
 	fmt.Println("  create <config_file>     Create a new campaign from configuration")
 	fmt.Println("    --dry-run, -d          Preview the campaign without creating it")
+	fmt.Println("    --verbose              With --dry-run, print every API request with its parameters")
+	fmt.Println("    --creative-lib <file>  Creative library for configurations with creative_ref placeholders")
+	fmt.Println("    --format <format>      json or yaml (default: by the file extension)")
+	fmt.Println("    --skip-remote-validation  Don't check the targeted interests with Facebook")
 	fmt.Println("")
 	fmt.Println("  update                   Update an existing campaign")
 	fmt.Println("    --id=ID                Campaign ID to update (required)")
@@ -2802,9 +3695,45 @@ func printUsage() {
 	fmt.Println("    --daily-budget=BUDGET  New daily budget (e.g., 50.00)")
 	fmt.Println("    --lifetime-budget=BUDGET  New lifetime budget (e.g., 1000.00)")
 	fmt.Println("    --bid-strategy=STRATEGY   New bid strategy (e.g., LOWEST_COST_WITHOUT_CAP)")
-	fmt.Println("    --file=FILE            JSON file with update parameters")
+	fmt.Println("    --end-time=DATE|none   New end date (YYYY-MM-DD), or none to remove it")
+	fmt.Println("    --spend-cap=AMT|none   New spend cap, or none to remove it")
+	fmt.Println("    --bid-cap=none         Remove the bid cap by switching to LOWEST_COST_WITHOUT_CAP")
+	fmt.Println("    --file=FILE            JSON file with update parameters (null clears a field)")
+	fmt.Println("    --switch-budget-type   Allow switching between daily and lifetime budget")
+	fmt.Println("")
+	fmt.Println("  delete <campaign_id>     Delete a campaign with its ad sets and ads")
+	fmt.Println("    --adset <id>           Delete an ad set with its ads instead")
+	fmt.Println("    --ad <id>              Delete a single ad instead")
+	fmt.Println("    --dry-run              List what would be deleted without deleting it")
+	fmt.Println("    --force                Delete without asking for confirmation")
+	fmt.Println("    --mine                 Refuse unless the object was created by fbads")
+	fmt.Println("")
+	fmt.Println("  status <campaign_id>     Show the campaign, ad sets and ads with their effective status")
+	fmt.Println("                           and why each will not deliver")
+	fmt.Println("")
+	fmt.Println("  activate <campaign_id>   Activate a paused campaign")
+	fmt.Println("    --cascade              Also activate its paused ad sets and ads")
+	fmt.Println("    --force                Activate without asking for confirmation")
+	fmt.Println("")
+	fmt.Println("  pause <campaign_id>...   Pause campaigns")
+	fmt.Println("    --label NAME           Also pause every campaign with this ad label")
+	fmt.Println("    --until DATE           Resume automatically at this date (account timezone)")
+	fmt.Println("")
+	fmt.Println("  resume <campaign_id>...  Resume campaigns and cancel their scheduled resumes")
+	fmt.Println("    --label NAME           Also resume every campaign with this ad label")
 	fmt.Println("")
-	fmt.Println("  delete <campaign_id>     Delete a campaign by ID")
+	fmt.Println("  bulk-status --status STATUS  Set the status of every campaign matching the filters")
+	fmt.Println("    --name-prefix PREFIX   Only campaigns whose name starts with PREFIX")
+	fmt.Println("    --ids ID,ID,...        Only these campaigns")
+	fmt.Println("    --objective OBJECTIVE  Only campaigns with this objective")
+	fmt.Println("    --dry-run, -d          Show the campaigns that would change")
+	fmt.Println("    --force                Change them without asking for confirmation")
+	fmt.Println("    --format json          Print the result summary as JSON")
+	fmt.Println("")
+	fmt.Println("  schedule <subcommand>    Scheduled resumes and jobs")
+	fmt.Println("    - list                 List scheduled resumes")
+	fmt.Println("    - run                  Resume the campaigns that are due (run from cron)")
+	fmt.Println("    - daemon               Run the reports, prunes and resumes of the scheduler config section")
 	fmt.Println("")
 	fmt.Println("  duplicate <campaign_id>  Duplicate an existing campaign with all its internals")
 	fmt.Println("    --name=NAME            Name for the duplicated campaign (defaults to 'Copy of [original]')")
@@ -2813,9 +3742,26 @@ func printUsage() {
 	fmt.Println("    --end=YYYY-MM-DD       New end date for the duplicated campaign")
 	fmt.Println("    --budget-factor=X      Multiply budget by factor X (e.g., 1.5)")
 	fmt.Println("    --dry-run, -d          Preview without creating the duplicate")
+	fmt.Println("    --target-account=ID    Create the copy in another ad account")
+	fmt.Println("    --reuse-creatives      Keep the original creatives instead of recreating them")
+	fmt.Println("")
+	fmt.Println("  split-geo <campaign_id>  Duplicate a campaign once per country with a share of the budget")
+	fmt.Println("    --countries <list>     Countries and weights, e.g. US:0.5,GB:0.3,DE:0.2")
+	fmt.Println("    --weights auto         Weight countries by estimated reach (countries without weights)")
+	fmt.Println("    --total-budget <amt>   Budget to split (default: the campaign's budget)")
+	fmt.Println("    --status <status>      Status of the new campaigns (default: PAUSED)")
+	fmt.Println("    --manifest <file>      Where to write the manifest of created campaigns")
+	fmt.Println("    --dry-run, -d          Preview without creating campaigns")
 	fmt.Println("")
-	fmt.Println("  export <campaign_id> [output_file]")
+	fmt.Println("  export <campaign_id> [output_file] [options]")
 	fmt.Println("                           Export campaign to JSON configuration file")
+	fmt.Println("    --show-warnings        List fields that could not be parsed")
+	fmt.Println("    --profile <profile>    full (default), slim (no timestamps or defaults) or structure (no creatives)")
+	fmt.Println("    --creative-lib <file>  With --profile structure, store the creatives in this library")
+	fmt.Println("    --format <format>      json or yaml (default: by the output file extension, else json)")
+	fmt.Println("    --all                  Export every campaign to <id>_<name>.json, without a campaign ID")
+	fmt.Println("    --dir <path>           Directory for the files of --all (default: current directory)")
+	fmt.Println("    --concurrency <n>      Campaigns fetched at once with --all (default: 4)")
 	fmt.Println("")
 	fmt.Println("  exportyaml <campaign_id> [output_file]")
 	fmt.Println("                           Export campaign to YAML for optimization testing")
@@ -2823,13 +3769,53 @@ func printUsage() {
 	fmt.Println("    --test-percent <pct>   Set the test budget percentage (default: 20)")
 	fmt.Println("    --max-cpm <amount>     Set the maximum CPM for bidding (default: 15.00)")
 	fmt.Println("")
+	fmt.Println("  backup [options]         Back up every campaign configuration to a directory")
+	fmt.Println("    --dir <path>           Backup directory (default: backups/<date>)")
+	fmt.Println("    --include-insights     Also store lifetime insights for each campaign")
+	fmt.Println("    --profile <profile>    full (default), slim or structure (creatives in creatives.json)")
+	fmt.Println("")
+	fmt.Println("  restore --dir <path> [options]")
+	fmt.Println("                           Recreate campaigns from a backup directory")
+	fmt.Println("    --status <status>      Status of restored campaigns (default: PAUSED)")
+	fmt.Println("    --prefix <text>        Prepend text to restored campaign names")
+	fmt.Println("    --suffix <text>        Append text to restored campaign names")
+	fmt.Println("    --rollback             Delete created campaigns if any restore fails")
+	fmt.Println("    --dry-run, -d          Validate and list campaigns without creating them")
+	fmt.Println("    --force, -f            Skip the confirmation prompt")
+	fmt.Println("")
 	fmt.Println("  pages                    List Facebook Pages available for the API token")
 	fmt.Println("")
+	fmt.Println("  creatives list [options] List the ad creatives of the account, to reuse by creative_id")
+	fmt.Println("    --limit, -l <n>        Number of creatives to list (default: 25)")
+	fmt.Println("    --after <cursor>       List the next page")
+	fmt.Println("    --format, -f <format>  Output format (table, json)")
+	fmt.Println("")
+	fmt.Println("  account <subcommand>     Ad account commands")
+	fmt.Println("    - status               Campaign and ad set counts against the account limits")
+	fmt.Println("")
+	fmt.Println("  activity [options]       Show who changed what in the account")
+	fmt.Println("    --since <7d|date>      Start of the period (default: 7d)")
+	fmt.Println("    --campaign <id>        Only changes to this campaign, its ad sets and ads")
+	fmt.Println("")
+	fmt.Println("  insights [options]       Print raw insights rows")
+	fmt.Println("    --level <level>        campaign, adset or ad (default: campaign)")
+	fmt.Println("    --since <30d|date>     Start of the period (default: 30d)")
+	fmt.Println("    --until <date>         End of the period (default: yesterday)")
+	fmt.Println("    --breakdown <list>     Breakdowns such as age,gender or country")
+	fmt.Println("    --fields <list>        Insights fields (default: the performance fields)")
+	fmt.Println("    --format, -f <format>  Output format (table, csv, json)")
+	fmt.Println("    --output, -o <file>    Write the rows to a file")
+	fmt.Println("")
+	fmt.Println("  collect [options]         Collect today's statistics on a schedule")
+	fmt.Println("    --interval <duration>  Time between collections (default: 6h)")
+	fmt.Println("    --once                 Collect once and exit")
+	fmt.Println("")
 	fmt.Println("  stats <subcommand> [args] Campaign statistics analysis")
 	fmt.Println("    - collect              Collect performance statistics")
 	fmt.Println("      --start, -s <date>    Start date (YYYY-MM-DD)")
 	fmt.Println("      --end, -e <date>      End date (YYYY-MM-DD)")
 	fmt.Println("      --days, -d <num>      Number of days back from today (default: 30)")
+	fmt.Println("      --full                Fetch every campaign, not only those with delivery")
 	fmt.Println("    - analyze              Analyze campaign statistics")
 	fmt.Println("      --start, -s <date>    Start date (YYYY-MM-DD)")
 	fmt.Println("      --end, -e <date>      End date (YYYY-MM-DD)")
@@ -2841,48 +3827,137 @@ func printUsage() {
 	fmt.Println("      --end, -e <date>      End date (YYYY-MM-DD)")
 	fmt.Println("      --days, -d <num>      Number of days back from today (default: 30)")
 	fmt.Println("      --output, -o <file>   Output file path (defaults to stats_export_<date>.csv)")
+	fmt.Println("    - insights             Stream daily insights from the API as CSV or NDJSON")
+	fmt.Println("      --since <date>        Start date (YYYY-MM-DD)")
+	fmt.Println("      --until <date>        End date (YYYY-MM-DD)")
+	fmt.Println("      --format, -f <fmt>    Output format: csv or ndjson (default: ndjson)")
+	fmt.Println("      --output, -o <file>   Output file path (defaults to stdout)")
 	fmt.Println("    - validate             Validate campaign data for optimization")
 	fmt.Println("      --start, -s <date>    Start date (YYYY-MM-DD)")
 	fmt.Println("      --end, -e <date>      End date (YYYY-MM-DD)")
 	fmt.Println("      --days, -d <num>      Number of days back from today (default: 30)")
 	fmt.Println("      --campaign, -c <id>   Specific campaign to validate (optional)")
 	fmt.Println("      --format, -f <fmt>    Output format: json or table (default: json)")
+	fmt.Println("    - backfill             Import historical daily insights")
+	fmt.Println("      --since <date>        Start date (YYYY-MM-DD)")
+	fmt.Println("      --until <date>        End date (YYYY-MM-DD)")
+	fmt.Println("      --overwrite           Re-import days that are already stored")
 	fmt.Println("")
 	fmt.Println("  audience <subcommand> [args]")
 	fmt.Println("                           Audience targeting and analysis commands")
 	fmt.Println("    - search <query>           Search for audience segments")
 	fmt.Println("      --type, -t <type>        Segment type (default: adinterest)")
 	fmt.Println("      --class, -c <class>      Category class when type is adTargetingCategory")
-	fmt.Println("      --output, -o <file>      Export results to file")
+	fmt.Println("      --output, -o <file>      Export results to file (.csv files as CSV, others as JSON)")
+	fmt.Println("      --format <fmt>           Export format: json or csv")
+	fmt.Println("      --merge                  Add the results to the file instead of replacing it")
+	fmt.Println("      --country <codes>        Estimate each result's audience in these countries, e.g. GB,DE")
+	fmt.Println("      --age <range>            Estimate each result's audience in an age range, e.g. 25-44")
+	fmt.Println("      --debug                  Print the raw API responses to stderr")
 	fmt.Println("    - filter                   Filter audience segments")
 	fmt.Println("      --query, -q <query>      Initial search query")
 	fmt.Println("      --min-size <size>        Minimum audience size")
 	fmt.Println("      --max-size <size>        Maximum audience size")
 	fmt.Println("      --types <types>          Comma-separated list of types")
 	fmt.Println("      --keywords, -k <kw>      Comma-separated list of keywords")
-	fmt.Println("      --output, -o <file>      Export results to file")
-	fmt.Println("    - stats                    Collect segment statistics")
+	fmt.Println("      --output, -o <file>      Export results to file (.csv files as CSV, others as JSON)")
+	fmt.Println("      --format <fmt>           Export format: json or csv")
+	fmt.Println("      --merge                  Add the results to the file instead of replacing it")
+	fmt.Println("    - stats                    Show campaign performance by age and gender")
 	fmt.Println("      --campaign, -c <id>      Campaign ID to analyze")
 	fmt.Println("      --days, -d <days>        Number of days to analyze (default: 30)")
+	fmt.Println("      --output, -o <file>      Also write the statistics to a JSON file")
+	fmt.Println("    - overlap                  Estimate how much the audiences of interests overlap")
+	fmt.Println("      --ids <ids>              Comma-separated interest IDs")
+	fmt.Println("      --segments <file>        Interests from a file written by search or filter --output")
+	fmt.Println("      --countries <codes>      Countries to estimate the audiences in (default: US)")
+	fmt.Println("      --threshold <ratio>      Highlight pairs overlapping at least this much (default: 0.5)")
+	fmt.Println("      --output, -o <file>      Export the overlap (.csv files as CSV, others as JSON)")
 	fmt.Println("")
 	fmt.Println("  report <type> [args]     Generate performance reports")
 	fmt.Println("    - daily                Daily report for yesterday")
 	fmt.Println("    - weekly               Weekly report for the last 7 days")
 	fmt.Println("    - custom <start> <end> Custom date range report (YYYY-MM-DD format)")
+	fmt.Println("      --format, -f <fmt>   Daily, weekly and custom report file: json, csv or html (default: json)")
+	fmt.Println("      --email              Mail the report to the recipients of the email config section")
+	fmt.Println("    - creatives            Performance of each distinct creative across campaigns")
+	fmt.Println("      --since <period>     Days back (e.g. 30d) or start date (default: 30d)")
+	fmt.Println("      --format, -f <fmt>   Output format: table or json (default: table)")
+	fmt.Println("      --output, -o <file>  Write the report to a file")
+	fmt.Println("    - explain-recommendations  Show the thresholds behind report recommendations")
+	fmt.Println("")
+	fmt.Println("  experiment <subcommand>  Compare experiment arms labeled exp:<name>:<arm>")
+	fmt.Println("    - report <name>        Spend, conversions and CPA per arm with a significance verdict")
+	fmt.Println("      --since <period>     Days back (e.g. 30d) or start date (default: 30d)")
+	fmt.Println("      --until <date>       End date (default: yesterday)")
+	fmt.Println("    - label <name> <arm> <campaign_id>...  Assign campaigns to an arm")
 	fmt.Println("")
 	fmt.Println("  optimize <subcommand>    Campaign optimization commands")
 	fmt.Println("    - validate <yaml_file>  Validate a YAML campaign configuration file")
+	fmt.Println("    - validate-data         Show which campaigns have enough stored data to optimize")
+	fmt.Println("      --since <7d|date>     Start of the evaluated period (default: 7d)")
 	fmt.Println("    - create <yaml_file>    Create test campaigns from a YAML configuration")
 	fmt.Println("      --limit <num>         Limit the number of test combinations to create")
 	fmt.Println("      --batch-size <num>    Number of campaigns to create in each batch (default: 3)")
 	fmt.Println("      --priority <type>     Priority for combinations: audience or placement (default: audience)")
 	fmt.Println("      --dry-run, -d         Preview campaigns without creating them")
+	fmt.Println("      --json                With --dry-run, print every generated campaign as JSON")
+	fmt.Println("      --resume              Create only the campaigns missing from an earlier run")
+	fmt.Println("      --use-batch           Create each batch with Graph API batch requests")
+	fmt.Println("      --skip-remote-validation  Don't check the targeted interests with Facebook")
 	fmt.Println("    - update <campaign_ids> Update campaign CPM based on performance data")
 	fmt.Println("      --max-cpm <value>     Maximum CPM price allowed (default: 15.0)")
+	fmt.Println("    - simulate              Replay stored statistics through the optimizer without changing anything")
+	fmt.Println("      --since <30d|date>    Start of the replayed period (default: 30d)")
+	fmt.Println("      --max-cpm <value>     Maximum CPM price allowed (default: 15.0)")
+	fmt.Println("    - ads --campaign <id>   Compare the delivery and performance of ads within each ad set")
+	fmt.Println("      --since <7d|date>     Start of the analyzed period (default: 7d)")
+	fmt.Println("      --apply               Pause ads that are significantly worse than their siblings")
+	fmt.Println("    - recommend             Recommend an action for each campaign from its recent performance")
+	fmt.Println("      --since <7d|date>     Start of the analyzed period (default: 7d)")
+	fmt.Println("      --min-impressions <n> Impressions needed before a campaign is judged (default: 1000)")
+	fmt.Println("      --reference-cpc <v>   Benchmark CPC in dollars (default: 1.0)")
+	fmt.Println("      --max-cpm <value>     Maximum CPM price allowed (default: 15.0)")
+	fmt.Println("      --apply               Change budgets and pause campaigns as recommended")
+	fmt.Println("    - apply-bids            Adjust ad set CPM bids towards the optimal CPM (48-hour cooldown)")
+	fmt.Println("      --since <7d|date>     Start of the period the CPM is measured over (default: 7d)")
+	fmt.Println("      --max-cpm <value>     Maximum CPM price allowed (default: 15.0)")
+	fmt.Println("      --min-cpm <value>     Minimum CPM price allowed (default: 1.0)")
+	fmt.Println("      --min-impressions <n> Impressions needed before a bid is adjusted (default: 1000)")
+	fmt.Println("      --dry-run, -d         Show the proposed bids without changing them")
+	fmt.Println("    - prune                 Pause active campaigns that trigger deactivation rules")
+	fmt.Println("      --rules <file>        JSON or YAML list of rules (default: built-in CPA, CTR and ROAS rules)")
+	fmt.Println("      --since <7d|date>     Start of the checked period (default: 7d)")
+	fmt.Println("      --cpc-factor <value>  Also pause campaigns with a CPC this many times the median, 0 to disable (default: 1.5)")
+	fmt.Println("      --min-impressions <n> Impressions needed before a CPC is compared (default: 1000)")
+	fmt.Println("      --dry-run, -d         Show what would be paused without pausing")
 	fmt.Println("")
 	fmt.Println("  dashboard [port]         Start web dashboard (default port: 8080)")
+	fmt.Println("    --refresh <duration>   Background refresh interval (default: 15m)")
+	fmt.Println("    --stream-interval <d>  How often open pages are updated (default: 30s)")
+	fmt.Println("    --bind <address>       Address to listen on, e.g. 127.0.0.1")
+	fmt.Println("    --user, --password     Require HTTP basic auth")
+	fmt.Println("    --token <token>        Accept a bearer token")
+	fmt.Println("")
+	fmt.Println("  serve [options]          Start the JSON REST API server")
+	fmt.Println("    --port <port>          Port to listen on (default: 9090)")
+	fmt.Println("    --token <token>        Bearer token required by clients (or FBADS_API_TOKEN)")
+	fmt.Println("    --read-only            Disable endpoints that change campaigns")
 	fmt.Println("")
-	fmt.Println("  config                   Configure the application")
+	fmt.Println("  config                   Configure the application, or the profile given with --profile")
+	fmt.Println("    list                   List the configured profiles (alias: list-profiles)")
+	fmt.Println("    test                   Check that the access token works and can read the ad account")
 	fmt.Println("")
 	fmt.Println("  help                     Show help information")
+	fmt.Println("")
+	fmt.Println("Global options:")
+	fmt.Println("  --demo                   Use a sample ad account instead of Facebook (or FBADS_DEMO=1);")
+	fmt.Println("                           --mock is accepted as its old name")
+	fmt.Println("  --account <id>           Ad account to use instead of the configured one")
+	fmt.Println("  --config <path>          Configuration file (default: ~/.fbads/config.json)")
+	fmt.Println("  --profile <name>         Configuration profile to use (or FBADS_PROFILE); give it before")
+	fmt.Println("                           export and backup, whose own --profile selects the fields")
+	fmt.Println("  --no-normalize           Send legacy objectives such as CONVERSIONS as configured")
+	fmt.Println("")
+	fmt.Println("Flags can be given as --flag value or --flag=value. Run fbads <command> --help for its options.")
 }
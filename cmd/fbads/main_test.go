@@ -0,0 +1,534 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	internal_campaign "github.com/user/fb-ads/internal/campaign"
+	"github.com/user/fb-ads/internal/demo"
+	"github.com/user/fb-ads/internal/optimization"
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestCheckBudgetCompatibility(t *testing.T) {
+	daily := &models.CampaignDetails{ID: "1", DailyBudget: 5000}
+	lifetime := &models.CampaignDetails{ID: "2", LifetimeBudget: 100000, StopTime: time.Now().AddDate(0, 1, 0)}
+	lifetimeNoEnd := &models.CampaignDetails{ID: "3", DailyBudget: 5000}
+	adSetBudget := &models.CampaignDetails{ID: "4"}
+
+	tests := []struct {
+		name          string
+		details       *models.CampaignDetails
+		params        url.Values
+		switchType    bool
+		expectError   string
+		expectCleared string
+	}{
+		{
+			name:    "no budget change",
+			details: daily,
+			params:  url.Values{"name": {"New name"}},
+		},
+		{
+			name:    "daily on daily campaign",
+			details: daily,
+			params:  url.Values{"daily_budget": {"6000"}},
+		},
+		{
+			name:    "lifetime on lifetime campaign",
+			details: lifetime,
+			params:  url.Values{"lifetime_budget": {"200000"}},
+		},
+		{
+			name:    "campaign without campaign budget",
+			details: adSetBudget,
+			params:  url.Values{"daily_budget": {"6000"}},
+		},
+		{
+			name:        "daily on lifetime campaign",
+			details:     lifetime,
+			params:      url.Values{"daily_budget": {"6000"}},
+			expectError: "uses a lifetime budget ($1000.00)",
+		},
+		{
+			name:        "lifetime on daily campaign",
+			details:     daily,
+			params:      url.Values{"lifetime_budget": {"200000"}},
+			expectError: "--switch-budget-type",
+		},
+		{
+			name:        "both budgets",
+			details:     daily,
+			params:      url.Values{"daily_budget": {"6000"}, "lifetime_budget": {"200000"}},
+			switchType:  true,
+			expectError: "both a daily and a lifetime budget",
+		},
+		{
+			name:          "switch lifetime to daily",
+			details:       lifetime,
+			params:        url.Values{"daily_budget": {"6000"}},
+			switchType:    true,
+			expectCleared: "lifetime_budget",
+		},
+		{
+			name:          "switch daily to lifetime",
+			details:       &models.CampaignDetails{ID: "5", DailyBudget: 5000, StopTime: time.Now().AddDate(0, 1, 0)},
+			params:        url.Values{"lifetime_budget": {"200000"}},
+			switchType:    true,
+			expectCleared: "daily_budget",
+		},
+		{
+			name:        "switch to lifetime without end date",
+			details:     lifetimeNoEnd,
+			params:      url.Values{"lifetime_budget": {"200000"}},
+			switchType:  true,
+			expectError: "no end date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkBudgetCompatibility(tt.details, tt.params, tt.switchType)
+
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if tt.expectCleared != "" && tt.params.Get(tt.expectCleared) != "0" {
+				t.Errorf("Expected %s to be cleared, got %q", tt.expectCleared, tt.params.Get(tt.expectCleared))
+			}
+		})
+	}
+}
+
+func TestValidateCampaignConfig_ReportsAllProblems(t *testing.T) {
+	config := &models.CampaignConfig{
+		Name:        "Spring",
+		Objective:   "OUTCOME_TRAFFIC",
+		DailyBudget: 50,
+		AdSets: []models.AdSetConfig{
+			{Name: "Broad", OptimizationGoal: "LINK_CLICKS", BillingEvent: "IMPRESSIONS", Targeting: map[string]interface{}{"age_min": 18, "geo_locations": map[string]interface{}{"countries": []string{"US"}}}},
+			{Name: "Narrow", OptimizationGoal: "LINK_CLICKS", Targeting: map[string]interface{}{"age_min": 30, "geo_locations": map[string]interface{}{"countries": []string{"US"}}}},
+			{Name: "Chat", OptimizationGoal: "CONVERSATIONS", BillingEvent: "IMPRESSIONS", Targeting: map[string]interface{}{"age_min": 18, "geo_locations": map[string]interface{}{"countries": []string{"US"}}}, DestinationType: "WHATSAPP"},
+		},
+		Ads: []models.AdConfig{
+			{Name: "Ad", Creative: models.CreativeConfig{Title: "Sale", LinkURL: "https://example.com", PageID: "123"}},
+			{Name: "Ad 2", Creative: models.CreativeConfig{Title: "Sale", LinkURL: "https://example.com"}},
+		},
+	}
+
+	err := validateCampaignConfig(config)
+
+	problems, ok := err.(models.ValidationErrors)
+	if !ok {
+		t.Fatalf("Expected models.ValidationErrors, got %T: %v", err, err)
+	}
+
+	expected := []string{
+		"buying_type",
+		"adsets[1].billing_event",
+		"adsets[2].destination_type",
+		"ads[1].creative.page_id",
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %d:\n%v", len(expected), len(problems), err)
+	}
+	for i, path := range expected {
+		if problems[i].Path != path {
+			t.Errorf("Problem %d: expected path %s, got %s", i+1, path, problems[i].Path)
+		}
+	}
+
+	// All problems are listed in one numbered message
+	message := err.Error()
+	if !strings.Contains(message, "4 problems found") || !strings.Contains(message, "4. ads[1].creative.page_id") {
+		t.Errorf("Expected a numbered list of all problems, got:\n%s", message)
+	}
+}
+
+func TestValidateCampaignConfig_FiveProblems(t *testing.T) {
+	config := &models.CampaignConfig{
+		Objective:  "OUTCOME_TRAFFIC",
+		BuyingType: "AUCTION",
+		AdSets: []models.AdSetConfig{
+			{Name: "Broad", BillingEvent: "IMPRESSIONS", Targeting: map[string]interface{}{"age_min": 18, "geo_locations": map[string]interface{}{"countries": []string{"US"}}}},
+		},
+		Ads: []models.AdConfig{
+			{Creative: models.CreativeConfig{Title: "Sale", LinkURL: "https://example.com", PageID: "123"}},
+			{Name: "Ad 2", Creative: models.CreativeConfig{LinkURL: "https://example.com", PageID: "123"}},
+		},
+	}
+
+	problems := checkCampaignConfig(config)
+
+	expected := []string{
+		"name",
+		"daily_budget",
+		"adsets[0].optimization_goal",
+		"ads[0].name",
+		"ads[1].creative.title",
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %d:\n%v", len(expected), len(problems), problems)
+	}
+	for i, path := range expected {
+		if problems[i].Path != path || problems[i].Warning {
+			t.Errorf("Problem %d: expected error at %s, got %+v", i+1, path, problems[i])
+		}
+	}
+}
+
+func TestValidateCampaignConfig_WarningsOnly(t *testing.T) {
+	config := &models.CampaignConfig{
+		Name:        "Spring",
+		Status:      "RUNNING",
+		Objective:   "OUTCOME_TRAFFIC",
+		BuyingType:  "AUCTION",
+		DailyBudget: 50,
+		AdSets: []models.AdSetConfig{
+			{Name: "Broad", OptimizationGoal: "LINK_CLICKS", BillingEvent: "IMPRESSIONS", Targeting: map[string]interface{}{"age_min": 18, "geo_locations": map[string]interface{}{"countries": []string{"US"}}}},
+		},
+		Ads: []models.AdConfig{
+			{Name: "Ad", Creative: models.CreativeConfig{Title: "Sale", LinkURL: "https://example.com", PageID: "123"}},
+		},
+	}
+
+	if err := validateCampaignConfig(config); err != nil {
+		t.Fatalf("Expected warnings not to fail validation, got %v", err)
+	}
+
+	warnings := checkCampaignConfig(config).Warnings()
+	if len(warnings) != 1 || warnings[0].Path != "status" {
+		t.Errorf("Expected a status warning, got %v", warnings)
+	}
+}
+
+func TestValidateCampaignConfig_UnknownEnumValues(t *testing.T) {
+	config := &models.CampaignConfig{
+		Name:        "Spring",
+		Objective:   "CONVERSIONS",
+		BuyingType:  "AUCTION",
+		BidStrategy: "LOWEST_COST_WITHOUT_CAPP",
+		DailyBudget: 50,
+		AdSets: []models.AdSetConfig{
+			{Name: "Broad", OptimizationGoal: "link_clicks", BillingEvent: "IMPRESION", Targeting: map[string]interface{}{"age_min": 18, "geo_locations": map[string]interface{}{"countries": []string{"US"}}}},
+		},
+		Ads: []models.AdConfig{
+			{Name: "Ad", Creative: models.CreativeConfig{Title: "Sale", LinkURL: "https://example.com", PageID: "123"}},
+		},
+	}
+
+	var paths []string
+	for _, problem := range checkCampaignConfig(config).Errors() {
+		paths = append(paths, problem.Path)
+	}
+
+	// Lower case values are accepted and typos are not
+	expected := []string{"bid_strategy", "adsets[0].billing_event"}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected problems at %v, got %v", expected, paths)
+	}
+
+	// Legacy objectives are translated when the campaign is created, so they only warn
+	warnings := checkCampaignConfig(config).Warnings()
+	if len(warnings) != 1 || warnings[0].Path != "objective" {
+		t.Errorf("Expected a warning about the legacy objective, got %v", warnings)
+	}
+}
+
+func TestValidateCampaignConfig_VideoCreatives(t *testing.T) {
+	config := &models.CampaignConfig{
+		Name:        "Spring",
+		Objective:   "OUTCOME_AWARENESS",
+		BuyingType:  "AUCTION",
+		DailyBudget: 50,
+		AdSets: []models.AdSetConfig{
+			{Name: "Broad", OptimizationGoal: "THRUPLAY", BillingEvent: "IMPRESSIONS", Targeting: map[string]interface{}{"age_min": 18, "geo_locations": map[string]interface{}{"countries": []string{"US"}}}},
+		},
+		Ads: []models.AdConfig{
+			{Name: "Video", Creative: models.CreativeConfig{Title: "Trailer", VideoID: "987", ImageHash: "abc", PageID: "123"}},
+			{Name: "No thumbnail", Creative: models.CreativeConfig{Title: "Trailer", VideoID: "987", LinkURL: "https://example.com", PageID: "123"}},
+			{Name: "Button without link", Creative: models.CreativeConfig{Title: "Trailer", VideoID: "987", ImageHash: "abc", CallToAction: "SHOP_NOW", PageID: "123"}},
+			{Name: "Neither", Creative: models.CreativeConfig{Title: "Sale", ImageHash: "abc", PageID: "123"}},
+		},
+	}
+
+	var paths []string
+	for _, problem := range checkCampaignConfig(config).Errors() {
+		paths = append(paths, problem.Path)
+	}
+
+	expected := []string{"ads[1].creative.image_url", "ads[2].creative.link_url", "ads[3].creative.link_url"}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected problems at %v, got %v", expected, paths)
+	}
+}
+
+func TestValidateCampaignConfig_CarouselCards(t *testing.T) {
+	card := models.CreativeCard{Title: "Tents", LinkURL: "https://example.com/tents", ImageHash: "abc"}
+	config := &models.CampaignConfig{
+		Name:        "Spring",
+		Objective:   "OUTCOME_TRAFFIC",
+		BuyingType:  "AUCTION",
+		DailyBudget: 50,
+		AdSets: []models.AdSetConfig{
+			{Name: "Broad", OptimizationGoal: "LINK_CLICKS", BillingEvent: "IMPRESSIONS", Targeting: map[string]interface{}{"age_min": 18, "geo_locations": map[string]interface{}{"countries": []string{"US"}}}},
+		},
+		Ads: []models.AdConfig{
+			{Name: "Carousel", Creative: models.CreativeConfig{Title: "Gear", PageID: "123", Cards: []models.CreativeCard{card, card}}},
+			{Name: "One card", Creative: models.CreativeConfig{Title: "Gear", PageID: "123", Cards: []models.CreativeCard{card}}},
+			{Name: "Missing link", Creative: models.CreativeConfig{Title: "Gear", PageID: "123", Cards: []models.CreativeCard{card, {Title: "Boots", ImageURL: "missing.jpg"}}}},
+		},
+	}
+
+	var paths []string
+	for _, problem := range checkCampaignConfig(config).Errors() {
+		paths = append(paths, problem.Path)
+	}
+
+	expected := []string{"ads[1].creative.cards", "ads[2].creative.cards[1].link_url", "ads[2].creative.cards[1].image_url"}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected problems at %v, got %v", expected, paths)
+	}
+}
+
+func TestOwnedCampaigns(t *testing.T) {
+	campaigns := []models.Campaign{
+		{ID: "1", Labels: []string{"fbads:v1"}},
+		{ID: "2"},
+		{ID: "3", Labels: []string{"brand", "fbads:v2"}},
+		{ID: "4", Labels: []string{"brand"}},
+	}
+
+	owned := ownedCampaigns(campaigns)
+	if len(owned) != 2 || owned[0].ID != "1" || owned[1].ID != "3" {
+		t.Errorf("Expected campaigns 1 and 3, got %+v", owned)
+	}
+}
+
+func TestDuplicateCampaign_DemoAccount(t *testing.T) {
+	authClient := auth.NewFacebookAuth("", "", "", "v22.0")
+	authClient.Transport = demo.NewProvider()
+	client := api.NewClient(authClient, demo.AccountID)
+
+	original, err := client.GetCampaignDetails("120200000000101")
+	if err != nil {
+		t.Fatalf("GetCampaignDetails failed: %v", err)
+	}
+
+	config := internal_campaign.DuplicateConfig(original, internal_campaign.DuplicateOptions{Status: "PAUSED", BudgetFactor: 2})
+	creator := internal_campaign.NewCampaignCreator(authClient, demo.AccountID)
+	copyID, err := creator.CreateFromConfigWithID(config)
+	if err != nil {
+		t.Fatalf("CreateFromConfigWithID failed: %v", err)
+	}
+
+	duplicate, err := client.GetCampaignDetails(copyID)
+	if err != nil {
+		t.Fatalf("GetCampaignDetails failed: %v", err)
+	}
+	if duplicate.Name != "Copy of "+original.Name || duplicate.Status != "PAUSED" || duplicate.DailyBudget != original.DailyBudget*2 {
+		t.Errorf("Expected a paused copy with twice the budget, got %s %s %.0f", duplicate.Name, duplicate.Status, duplicate.DailyBudget)
+	}
+
+	// Every ad lands in the copy of its ad set with the same image
+	layout := func(details *models.CampaignDetails, prefix string) string {
+		names := make(map[string]string)
+		for _, adSet := range details.AdSets {
+			names[adSet.ID] = strings.TrimPrefix(adSet.Name, prefix)
+		}
+		var ads []string
+		for _, ad := range details.Ads {
+			ads = append(ads, names[ad.AdSetID]+"/"+strings.TrimPrefix(ad.Name, prefix)+"/"+ad.Creative.ImageHash)
+		}
+		sort.Strings(ads)
+		return strings.Join(ads, ", ")
+	}
+	if got, want := layout(duplicate, "Copy of "), layout(original, ""); got != want {
+		t.Errorf("Duplicate ads = %s, want %s", got, want)
+	}
+}
+
+func TestBudgetUpdates(t *testing.T) {
+	campaignBudget := &models.CampaignDetails{ID: "1", Name: "CBO", DailyBudget: 5000}
+	updates := budgetUpdates(campaignBudget, 1.2)
+	if len(updates) != 1 || updates[0].adSetID != "" || updates[0].dailyBudget != 60 || updates[0].lifetimeBudget != 0 {
+		t.Errorf("Expected the campaign daily budget to become $60, got %+v", updates)
+	}
+
+	adSetBudgets := &models.CampaignDetails{ID: "2", Name: "ABO", AdSets: []models.AdSetDetails{
+		{ID: "21", Status: "ACTIVE", DailyBudget: 1000},
+		{ID: "22", Status: "PAUSED", DailyBudget: 1000},
+		{ID: "23", Status: "ACTIVE", LifetimeBudget: 20000},
+	}}
+	updates = budgetUpdates(adSetBudgets, 0.8)
+	if len(updates) != 2 {
+		t.Fatalf("Expected the two active ad sets to be updated, got %+v", updates)
+	}
+	if updates[0].adSetID != "21" || updates[0].dailyBudget != 8 {
+		t.Errorf("Expected ad set 21 to get $8 per day, got %+v", updates[0])
+	}
+	if updates[1].adSetID != "23" || updates[1].lifetimeBudget != 160 || updates[1].dailyBudget != 0 {
+		t.Errorf("Expected ad set 23 to get $160 lifetime, got %+v", updates[1])
+	}
+}
+
+func TestWriteGeneratedCampaigns(t *testing.T) {
+	yamlConfig := `
+campaign:
+  name: "Test"
+  total_budget: 1000
+  test_budget_percentage: 20
+  max_cpm: 10
+creatives:
+  - id: "c1"
+    title: "Creative 1"
+    description: "Description 1"
+    image_url: "https://example.com/1.jpg"
+    page_id: "123"
+targeting_options:
+  audiences:
+    - id: "a1"
+      name: "Audience 1"
+      parameters:
+        age_min: 18
+  placements:
+    - id: "p1"
+      name: "Feed"
+      position: "feed"
+`
+	campaignCfg, err := optimization.ParseYAMLReader(strings.NewReader(yamlConfig))
+	if err != nil {
+		t.Fatalf("Error parsing YAML: %v", err)
+	}
+	budgetCalc, err := optimization.NewBudgetCalculator(1000, 20, 10)
+	if err != nil {
+		t.Fatalf("Error creating budget calculator: %v", err)
+	}
+	generator := optimization.NewCampaignGenerator(campaignCfg, budgetCalc)
+	generator.SetMaxBatchSize(1)
+	if err := generator.GenerateAllCombinations(); err != nil {
+		t.Fatalf("Error generating combinations: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeGeneratedCampaigns(&buf, generator); err != nil {
+		t.Fatalf("writeGeneratedCampaigns failed: %v", err)
+	}
+
+	var campaigns []models.CampaignConfig
+	if err := json.Unmarshal(buf.Bytes(), &campaigns); err != nil {
+		t.Fatalf("Expected a JSON array, got %v:\n%s", err, buf.String())
+	}
+	if len(campaigns) != 2 {
+		t.Fatalf("Expected every batch to be written (2 campaigns), got %d", len(campaigns))
+	}
+	for _, campaign := range campaigns {
+		if campaign.Name == "" || len(campaign.AdSets) != 1 {
+			t.Errorf("Unexpected campaign configuration: %+v", campaign)
+		}
+	}
+}
+
+// countingTransport counts the insights requests answered by the demo account
+type countingTransport struct {
+	provider *demo.Provider
+	insights int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "/insights") {
+		c.insights++
+	}
+	return c.provider.RoundTrip(req)
+}
+
+func TestAddCampaignInsights_DemoAccount(t *testing.T) {
+	transport := &countingTransport{provider: demo.NewProvider()}
+	authClient := auth.NewFacebookAuth("", "", "", "v22.0")
+	authClient.Transport = transport
+
+	campaigns, err := api.NewClient(authClient, demo.AccountID).GetAllCampaigns()
+	if err != nil {
+		t.Fatalf("GetAllCampaigns failed: %v", err)
+	}
+	rows := make([]campaignRow, len(campaigns))
+	for i, campaign := range campaigns {
+		rows[i] = campaignRow{Campaign: campaign}
+	}
+
+	collector := api.NewMetricsCollector(authClient, demo.AccountID)
+	yesterday := collector.Clock().Yesterday()
+	timeRange := api.TimeRange{
+		Since: yesterday.AddDate(0, 0, -6).Format("2006-01-02"),
+		Until: yesterday.Format("2006-01-02"),
+	}
+	if err := addCampaignInsights(context.Background(), collector, rows, timeRange); err != nil {
+		t.Fatalf("addCampaignInsights failed: %v", err)
+	}
+
+	if transport.insights != 1 {
+		t.Errorf("Expected a single insights request, got %d", transport.insights)
+	}
+
+	delivered, idle := 0, 0
+	for _, row := range rows {
+		if row.Insights == nil || row.Insights.CampaignID != row.ID {
+			t.Fatalf("Expected insights for campaign %s, got %+v", row.ID, row.Insights)
+		}
+		if row.Insights.Spend > 0 {
+			delivered++
+		} else if row.Insights.Impressions == 0 && row.Insights.Clicks == 0 {
+			idle++
+		}
+	}
+	if delivered == 0 || idle == 0 {
+		t.Errorf("Expected both delivering and idle campaigns, got %d delivering and %d idle", delivered, idle)
+	}
+}
+
+func TestCreateWithRetries(t *testing.T) {
+	rateLimiter := optimization.NewRateLimiter()
+	rateLimiter.BaseDelay, rateLimiter.MaxDelay, rateLimiter.MinRequestInterval = time.Millisecond, time.Millisecond, 0
+
+	tests := []struct {
+		name          string
+		results       []error
+		campaignIDs   []string
+		expectedCalls int
+		expectedID    string
+		expectError   bool
+	}{
+		{"retried before the campaign exists", []error{errors.New("timeout"), nil}, []string{"", "111"}, 2, "111", false},
+		{"not retried once the campaign exists", []error{errors.New("ad set failed"), nil}, []string{"111", "222"}, 1, "111", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			campaignID, err := createWithRetries(context.Background(), rateLimiter, "act_1", func() (string, error) {
+				calls++
+				return tt.campaignIDs[calls-1], tt.results[calls-1]
+			})
+			if calls != tt.expectedCalls || campaignID != tt.expectedID || (err != nil) != tt.expectError {
+				t.Errorf("Got %d calls, campaign %q and error %v", calls, campaignID, err)
+			}
+		})
+	}
+}
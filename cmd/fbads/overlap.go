@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/user/fb-ads/internal/audience"
+)
+
+// audienceOverlap estimates how much the audiences of interests overlap, prints the
+// overlap matrix and optionally exports it
+func audienceOverlap(analyzer *audience.AudienceAnalyzer, args []string) {
+	var ids, segmentsFile, countries, outputFile, format string
+	threshold := audience.DefaultOverlapThreshold
+
+	// Parse flags
+	fs := newCommandFlags("audience overlap --ids ID,ID,... | --segments FILE [options]")
+	fs.StringVar(&ids, "ids", "", "Comma-separated interest IDs to compare")
+	fs.StringVar(&segmentsFile, "segments", "", "Compare the segments of a file written by search or filter --output")
+	fs.StringVar(&countries, "countries", strings.Join(audience.DefaultAudienceCountries, ","), "Comma-separated countries the audiences are estimated in")
+	fs.Float64Var(&threshold, "threshold", threshold, "Overlap ratio from which a pair is highlighted, between 0 and 1")
+	fs.StringVar(&outputFile, "output", "", "Export the overlap to a file (.csv files as CSV, others as JSON)")
+	alias(fs, "o", "output")
+	fs.StringVar(&format, "format", "", "Export format: json or csv (default: by the file extension)")
+	parseCommandArgs(fs, args, 0, 0)
+
+	var interests []audience.AudienceSegment
+	if segmentsFile != "" {
+		segments, err := audience.LoadSegments(segmentsFile)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		interests = append(interests, segments...)
+	}
+
+	// Interests given by ID are named after the segments researched earlier, if any
+	names := make(map[string]string)
+	for _, segment := range analyzer.Segments() {
+		names[segment.ID] = segment.Name
+	}
+	for _, id := range splitList(ids) {
+		interests = append(interests, audience.AudienceSegment{ID: id, Name: names[id]})
+	}
+
+	if len(interests) == 0 {
+		fmt.Println("Missing interests. Use: fbads audience overlap --ids ID,ID,... or --segments FILE")
+		os.Exit(1)
+	}
+	countryList := splitList(strings.ToUpper(countries))
+	if len(countryList) == 0 {
+		fmt.Println("Error: --countries needs at least one country")
+		os.Exit(1)
+	}
+	targeting := map[string]interface{}{
+		"geo_locations": map[string]interface{}{"countries": countryList},
+	}
+
+	fmt.Printf("Estimating audience overlap in %s...\n", strings.Join(countryList, ", "))
+	analysis, err := analyzer.AnalyzeOverlapContext(cmdContext, interests, targeting, threshold)
+	if err != nil {
+		fmt.Printf("Error analyzing audience overlap: %v\n", err)
+		os.Exit(1)
+	}
+
+	renderOverlap(os.Stdout, analysis)
+
+	if outputFile != "" {
+		if err := audience.ExportOverlap(outputFile, analysis, format); err != nil {
+			fmt.Printf("Error exporting to file: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\nExported the overlap of %d pairs to %s\n", len(analysis.Pairs), outputFile)
+	}
+}
+
+// renderOverlap writes the audience size of each interest, the matrix of overlap ratios
+// with pairs at or above the threshold marked, and a list of those pairs
+func renderOverlap(w io.Writer, analysis *audience.OverlapAnalysis) {
+	fmt.Fprintln(w, "\nInterests:")
+	for i, interest := range analysis.Interests {
+		fmt.Fprintf(w, "  %2d. %-30s %-15s %s people\n", i+1, truncateString(interest.Name, 30), interest.ID, audience.FormatNumberReadable(interest.Size))
+	}
+
+	fmt.Fprintf(w, "\nOverlap as a share of the smaller audience (* at or above %.0f%%):\n\n", analysis.Threshold*100)
+	fmt.Fprint(w, "     ")
+	for i := range analysis.Interests {
+		fmt.Fprintf(w, " %6d ", i+1)
+	}
+	fmt.Fprintln(w)
+	for i, row := range analysis.Interests {
+		fmt.Fprintf(w, "  %2d ", i+1)
+		for j, column := range analysis.Interests {
+			ratio, ok := analysis.Ratio(row.ID, column.ID)
+			switch {
+			case i == j || !ok:
+				fmt.Fprintf(w, " %6s ", "-")
+			case ratio >= analysis.Threshold:
+				fmt.Fprintf(w, " %5.0f%%*", ratio*100)
+			default:
+				fmt.Fprintf(w, " %5.0f%% ", ratio*100)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	above := analysis.AboveThreshold()
+	if len(above) == 0 {
+		fmt.Fprintln(w, "\nNo pair of interests reaches the threshold.")
+		return
+	}
+	fmt.Fprintf(w, "\n%d pairs target nearly the same people:\n", len(above))
+	for _, pair := range above {
+		fmt.Fprintf(w, "  %s and %s: %.0f%% (%s people in both)\n",
+			overlapName(pair.A), overlapName(pair.B), pair.Ratio*100, audience.FormatNumberReadable(pair.OverlapSize))
+	}
+}
+
+// overlapName returns the name of a compared interest, or its ID when it has no name
+func overlapName(interest audience.OverlapInterest) string {
+	if interest.Name == "" {
+		return interest.ID
+	}
+	return interest.Name
+}
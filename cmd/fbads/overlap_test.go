@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/internal/audience"
+)
+
+func TestRenderOverlap(t *testing.T) {
+	hiking := audience.OverlapInterest{ID: "1", Name: "Hiking", Size: 1000000}
+	trekking := audience.OverlapInterest{ID: "2", Name: "Trekking", Size: 400000}
+	knitting := audience.OverlapInterest{ID: "3", Size: 300000}
+	analysis := &audience.OverlapAnalysis{
+		Interests: []audience.OverlapInterest{hiking, trekking, knitting},
+		Pairs: []audience.InterestOverlap{
+			{A: hiking, B: trekking, OverlapSize: 300000, Ratio: 0.75},
+			{A: hiking, B: knitting, Ratio: 0},
+			{A: trekking, B: knitting, OverlapSize: 30000, Ratio: 0.1},
+		},
+		Threshold: 0.5,
+	}
+
+	var out strings.Builder
+	renderOverlap(&out, analysis)
+	output := out.String()
+
+	for _, expected := range []string{
+		"   1       -     75%*     0% ",
+		"   2     75%*      -     10% ",
+		"1 pairs target nearly the same people:",
+		"Hiking and Trekking: 75% (300k people in both)",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in the output:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "and 3:") {
+		t.Errorf("Expected pairs below the threshold to be left out of the list:\n%s", output)
+	}
+}
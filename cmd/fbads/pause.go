@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	internal_campaign "github.com/user/fb-ads/internal/campaign"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// schedulePath returns the location of the local schedule file
+func schedulePath(cfg *config.Config) string {
+	return filepath.Join(cfg.ConfigDir, "schedule.json")
+}
+
+// pauseResumeArgs holds the arguments shared by the pause and resume commands
+type pauseResumeArgs struct {
+	campaignIDs []string
+	label       string
+	until       string
+}
+
+// parsePauseResumeArgs parses the campaign IDs (space or comma separated), --label and
+// --until of the pause or resume command
+func parsePauseResumeArgs(command string, args []string) pauseResumeArgs {
+	var parsed pauseResumeArgs
+	fs := newCommandFlags(command + " <campaign_id> [<campaign_id>...] [options]")
+	fs.StringVar(&parsed.label, "label", "", "Also select the campaigns with this ad label")
+	fs.StringVar(&parsed.until, "until", "", "Resume the campaigns on this date (YYYY-MM-DD, pause only)")
+
+	for _, arg := range parseCommandFlags(fs, args) {
+		for _, id := range strings.Split(arg, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				parsed.campaignIDs = append(parsed.campaignIDs, id)
+			}
+		}
+	}
+	return parsed
+}
+
+// selectCampaignIDs returns the given campaign IDs plus those of campaigns carrying the label
+func selectCampaignIDs(client *api.Client, parsed pauseResumeArgs) ([]string, error) {
+	ids := append([]string{}, parsed.campaignIDs...)
+	if parsed.label == "" {
+		return ids, nil
+	}
+
+	campaigns, err := client.GetAllCampaignsContext(cmdContext)
+	if err != nil {
+		return nil, fmt.Errorf("error getting campaigns: %w", err)
+	}
+
+	return append(ids, campaignsWithLabel(campaigns, parsed.label)...), nil
+}
+
+// campaignsWithLabel returns the IDs of campaigns with an ad label of the given name (case-insensitive)
+func campaignsWithLabel(campaigns []models.Campaign, label string) []string {
+	var ids []string
+	for _, campaign := range campaigns {
+		for _, name := range campaign.Labels {
+			if strings.EqualFold(name, label) {
+				ids = append(ids, campaign.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// parseUntil parses a resume time: a date (YYYY-MM-DD) means midnight in the account timezone.
+// The time must be in the future.
+func parseUntil(value string, loc *time.Location, now time.Time) (time.Time, error) {
+	resumeAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		resumeAt, err = time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid --until %q: use YYYY-MM-DD or an RFC3339 timestamp", value)
+		}
+	}
+
+	if !resumeAt.After(now) {
+		return time.Time{}, fmt.Errorf("--until %s is not in the future", value)
+	}
+
+	return resumeAt.In(loc), nil
+}
+
+// pauseCampaigns pauses campaigns and optionally schedules them to be resumed
+func pauseCampaigns(cfg *config.Config, args []string) {
+	parsed := parsePauseResumeArgs("pause", args)
+	if len(parsed.campaignIDs) == 0 && parsed.label == "" {
+		fmt.Println("Missing campaign ID. Use: fbads pause <campaign_id> [<campaign_id>...] [--label NAME] [--until YYYY-MM-DD]")
+		os.Exit(1)
+	}
+
+	authClient := newAuthClient(cfg)
+	client := api.NewClient(authClient, cfg.AccountID)
+	clock := api.NewAccountClock(authClient, cfg.AccountID)
+
+	var resumeAt time.Time
+	if parsed.until != "" {
+		var err error
+		resumeAt, err = parseUntil(parsed.until, clock.Location(), clock.Now())
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	campaignIDs, err := selectCampaignIDs(client, parsed)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(campaignIDs) == 0 {
+		fmt.Printf("No campaigns have the label %q.\n", parsed.label)
+		return
+	}
+
+	schedule, err := internal_campaign.LoadSchedule(schedulePath(cfg))
+	if err != nil {
+		fmt.Printf("Error loading schedule: %v\n", err)
+		os.Exit(1)
+	}
+
+	failed := 0
+	for _, campaignID := range campaignIDs {
+		if err := setCampaignStatus(client, campaignID, models.CampaignStatusPaused); err != nil {
+			fmt.Printf("Error pausing campaign %s: %v\n", campaignID, err)
+			failed++
+			continue
+		}
+
+		// A pause without --until is indefinite, so it replaces any pending resume
+		if resumeAt.IsZero() {
+			schedule.Cancel(campaignID)
+			fmt.Printf("Campaign %s paused\n", campaignID)
+			continue
+		}
+
+		schedule.ScheduleResume(campaignID, resumeAt, clock.Now())
+		fmt.Printf("Campaign %s paused until %s\n", campaignID, resumeAt.Format("2006-01-02 15:04 MST"))
+	}
+
+	if err := schedule.Save(); err != nil {
+		fmt.Printf("Error saving schedule: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !resumeAt.IsZero() && failed < len(campaignIDs) {
+		fmt.Println("Run 'fbads schedule run' periodically (e.g. from cron) to carry out scheduled resumes.")
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// resumeCampaigns activates paused campaigns and cancels their scheduled resumes
+func resumeCampaigns(cfg *config.Config, args []string) {
+	parsed := parsePauseResumeArgs("resume", args)
+	if len(parsed.campaignIDs) == 0 && parsed.label == "" {
+		fmt.Println("Missing campaign ID. Use: fbads resume <campaign_id> [<campaign_id>...] [--label NAME]")
+		os.Exit(1)
+	}
+	if parsed.until != "" {
+		fmt.Println("Error: --until is only supported by pause")
+		os.Exit(1)
+	}
+
+	authClient := newAuthClient(cfg)
+	client := api.NewClient(authClient, cfg.AccountID)
+
+	campaignIDs, err := selectCampaignIDs(client, parsed)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(campaignIDs) == 0 {
+		fmt.Printf("No campaigns have the label %q.\n", parsed.label)
+		return
+	}
+
+	schedule, err := internal_campaign.LoadSchedule(schedulePath(cfg))
+	if err != nil {
+		fmt.Printf("Error loading schedule: %v\n", err)
+		os.Exit(1)
+	}
+
+	failed := 0
+	for _, campaignID := range campaignIDs {
+		if err := setCampaignStatus(client, campaignID, models.CampaignStatusActive); err != nil {
+			fmt.Printf("Error resuming campaign %s: %v\n", campaignID, err)
+			failed++
+			continue
+		}
+
+		if schedule.Cancel(campaignID) {
+			fmt.Printf("Campaign %s resumed (scheduled resume cancelled)\n", campaignID)
+		} else {
+			fmt.Printf("Campaign %s resumed\n", campaignID)
+		}
+	}
+
+	if err := schedule.Save(); err != nil {
+		fmt.Printf("Error saving schedule: %v\n", err)
+		os.Exit(1)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// setCampaignStatus changes the status of a campaign
+func setCampaignStatus(client *api.Client, campaignID string, status models.CampaignStatus) error {
+	params := url.Values{}
+	params.Set("status", string(status))
+	return client.UpdateCampaign(campaignID, params)
+}
+
+// handleSchedule lists or runs scheduled resumes, or runs the scheduled jobs of the config
+func handleSchedule(cfg *config.Config, subcommand string, args []string) {
+	switch subcommand {
+	case "list":
+		schedule, err := internal_campaign.LoadSchedule(schedulePath(cfg))
+		if err != nil {
+			fmt.Printf("Error loading schedule: %v\n", err)
+			os.Exit(1)
+		}
+		clock := api.NewAccountClock(newAuthClient(cfg), cfg.AccountID)
+		renderSchedule(os.Stdout, schedule, clock.Location())
+
+	case "run":
+		if err := runScheduledResumes(cfg); err != nil {
+			fmt.Printf("Error running scheduled resumes: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "daemon":
+		scheduleDaemon(cfg, args)
+
+	default:
+		fmt.Printf("Unknown schedule subcommand: %s\n", subcommand)
+		fmt.Println("Available subcommands: list, run, daemon")
+		os.Exit(1)
+	}
+}
+
+// runScheduledResumes resumes the campaigns whose scheduled resume is due and saves the
+// schedule. Failed resumes stay scheduled for the next run and are returned as an error.
+func runScheduledResumes(cfg *config.Config) error {
+	schedule, err := internal_campaign.LoadSchedule(schedulePath(cfg))
+	if err != nil {
+		return fmt.Errorf("error loading schedule: %w", err)
+	}
+
+	authClient := newAuthClient(cfg)
+	clock := api.NewAccountClock(authClient, cfg.AccountID)
+	client := api.NewClient(authClient, cfg.AccountID)
+	notifier := newScheduleNotifier(os.Stdout, filepath.Join(cfg.ConfigDir, "notifications.log"))
+
+	events := schedule.RunDue(clock.Now(), func(campaignID string) error {
+		return setCampaignStatus(client, campaignID, models.CampaignStatusActive)
+	}, notifier)
+
+	if err := schedule.Save(); err != nil {
+		return fmt.Errorf("error saving schedule: %w", err)
+	}
+
+	if len(events) == 0 {
+		fmt.Println("No scheduled resumes are due.")
+	}
+	failed := 0
+	for _, event := range events {
+		if event.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d due resumes failed", failed, len(events))
+	}
+	return nil
+}
+
+// newScheduleNotifier returns a notifier that prints each outcome and appends it to a log file
+func newScheduleNotifier(w io.Writer, logPath string) func(internal_campaign.ScheduleEvent) {
+	return func(event internal_campaign.ScheduleEvent) {
+		message := scheduleEventMessage(event)
+		fmt.Fprintln(w, message)
+
+		logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Fprintf(w, "Warning: could not write notification log: %v\n", err)
+			return
+		}
+		defer logFile.Close()
+		fmt.Fprintf(logFile, "%s %s\n", time.Now().Format(time.RFC3339), message)
+	}
+}
+
+// scheduleEventMessage describes the outcome of a scheduled resume
+func scheduleEventMessage(event internal_campaign.ScheduleEvent) string {
+	if event.Err != nil {
+		return fmt.Sprintf("[notify] Scheduled resume of campaign %s FAILED (due %s): %v; it will be retried on the next run",
+			event.CampaignID, event.ResumeAt.Format(time.RFC3339), event.Err)
+	}
+	return fmt.Sprintf("[notify] Campaign %s resumed as scheduled (due %s)", event.CampaignID, event.ResumeAt.Format(time.RFC3339))
+}
+
+// renderSchedule lists the scheduled resumes in the account timezone
+func renderSchedule(w io.Writer, schedule *internal_campaign.Schedule, loc *time.Location) {
+	if len(schedule.Resumes) == 0 {
+		fmt.Fprintln(w, "No scheduled resumes.")
+		return
+	}
+
+	fmt.Fprintf(w, "%-20s %-22s %s\n", "CAMPAIGN ID", "RESUME AT", "LAST ERROR")
+	fmt.Fprintln(w, strings.Repeat("-", 70))
+	for _, resume := range schedule.Resumes {
+		lastError := "-"
+		if resume.LastError != "" {
+			lastError = fmt.Sprintf("%s (%d attempts)", truncateString(resume.LastError, 40), resume.Attempts)
+		}
+		fmt.Fprintf(w, "%-20s %-22s %s\n", resume.CampaignID, resume.ResumeAt.In(loc).Format("2006-01-02 15:04 MST"), lastError)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	internal_campaign "github.com/user/fb-ads/internal/campaign"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestParsePauseResumeArgs(t *testing.T) {
+	parsed := parsePauseResumeArgs("pause", []string{"111", "222,333", "--until", "2024-07-01", "--label=sale"})
+
+	if !reflect.DeepEqual(parsed.campaignIDs, []string{"111", "222", "333"}) {
+		t.Errorf("Unexpected campaign IDs: %v", parsed.campaignIDs)
+	}
+	if parsed.until != "2024-07-01" || parsed.label != "sale" {
+		t.Errorf("Unexpected flags: %+v", parsed)
+	}
+}
+
+func TestParseUntil(t *testing.T) {
+	loc := time.FixedZone("PDT", -7*3600)
+	now := time.Date(2024, 6, 28, 12, 0, 0, 0, loc)
+
+	resumeAt, err := parseUntil("2024-07-01", loc, now)
+	if err != nil {
+		t.Fatalf("parseUntil failed: %v", err)
+	}
+	if want := time.Date(2024, 7, 1, 0, 0, 0, 0, loc); !resumeAt.Equal(want) {
+		t.Errorf("Expected midnight in the account timezone %s, got %s", want, resumeAt)
+	}
+
+	if _, err := parseUntil("2024-06-28", loc, now); err == nil {
+		t.Errorf("Expected an error for a time in the past")
+	}
+	if _, err := parseUntil("next week", loc, now); err == nil {
+		t.Errorf("Expected an error for an invalid date")
+	}
+}
+
+func TestCampaignsWithLabel(t *testing.T) {
+	campaigns := []models.Campaign{
+		{ID: "1", Labels: []string{"Summer-Sale", "brand"}},
+		{ID: "2", Labels: []string{"brand"}},
+		{ID: "3"},
+	}
+
+	if ids := campaignsWithLabel(campaigns, "summer-sale"); !reflect.DeepEqual(ids, []string{"1"}) {
+		t.Errorf("Expected campaign 1, got %v", ids)
+	}
+	if ids := campaignsWithLabel(campaigns, "brand"); !reflect.DeepEqual(ids, []string{"1", "2"}) {
+		t.Errorf("Expected campaigns 1 and 2, got %v", ids)
+	}
+}
+
+func TestScheduleEventMessage(t *testing.T) {
+	due := time.Date(2024, 7, 1, 7, 0, 0, 0, time.UTC)
+
+	fired := scheduleEventMessage(internal_campaign.ScheduleEvent{CampaignID: "111", ResumeAt: due})
+	if !strings.Contains(fired, "111 resumed as scheduled") {
+		t.Errorf("Unexpected message: %s", fired)
+	}
+
+	failed := scheduleEventMessage(internal_campaign.ScheduleEvent{CampaignID: "111", ResumeAt: due, Err: errors.New("token expired")})
+	if !strings.Contains(failed, "FAILED") || !strings.Contains(failed, "token expired") {
+		t.Errorf("Unexpected message: %s", failed)
+	}
+}
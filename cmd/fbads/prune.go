@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/internal/optimization"
+	"github.com/user/fb-ads/pkg/models"
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+// cpcOutlierRuleID identifies events raised by the terminator rather than a configured rule
+const cpcOutlierRuleID = "cpc_outlier"
+
+// pruneOptions are the settings of a prune run
+type pruneOptions struct {
+	rulesPath      string
+	since          string
+	cpcFactor      float64
+	minImpressions int
+	dryRun         bool
+}
+
+// defaultPruneOptions returns the prune settings used when none are given
+func defaultPruneOptions() pruneOptions {
+	return pruneOptions{
+		since:          "7d",
+		cpcFactor:      1.5,
+		minImpressions: 1000,
+	}
+}
+
+// pruneCampaigns checks the active campaigns against deactivation rules and the terminator's
+// CPC comparison, and pauses the campaigns that trigger one unless --dry-run is given.
+// Every pause is appended to the deactivation log.
+func pruneCampaigns(cfg *config.Config, args []string) {
+	options := defaultPruneOptions()
+
+	// Handle flags
+	fs := newCommandFlags("optimize prune [options]")
+	fs.StringVar(&options.rulesPath, "rules", "", "JSON or YAML file with deactivation rules (default: built-in rules)")
+	fs.StringVar(&options.since, "since", options.since, "Period to check: a date (YYYY-MM-DD) or days like 7d")
+	fs.Float64Var(&options.cpcFactor, "cpc-factor", options.cpcFactor, "Also pause campaigns whose CPC is this many times the median (0 to disable)")
+	fs.IntVar(&options.minImpressions, "min-impressions", options.minImpressions, "Impressions a campaign needs before its CPC is compared")
+	fs.BoolVar(&options.dryRun, "dry-run", false, "Show what would be paused without pausing")
+	alias(fs, "d", "dry-run")
+	parseCommandArgs(fs, args, 0, 0)
+
+	if err := runPrune(cmdContext, cfg, options); err != nil {
+		fmt.Printf("Prune failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runPrune checks the active campaigns and pauses those that trigger a rule, unless it is
+// a dry run. It returns an error when the check fails or a campaign couldn't be paused.
+func runPrune(ctx context.Context, cfg *config.Config, options pruneOptions) error {
+	// Create auth client
+	authClient := newAuthClient(cfg)
+	client := api.NewClient(authClient, cfg.AccountID)
+	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+	clock := metricsCollector.Clock()
+
+	deactivator := utils.NewDeactivator(authClient, cfg.AccountID)
+	deactivator.SetConversionValues(cfg.ConversionValues())
+	if options.rulesPath != "" {
+		if err := deactivator.LoadRules(options.rulesPath); err != nil {
+			return fmt.Errorf("error loading rules: %w", err)
+		}
+	}
+
+	// Today is incomplete, so the period ends yesterday
+	startDate, err := parseSinceFlag(options.since, clock.Today())
+	if err != nil {
+		return fmt.Errorf("invalid since value: %w", err)
+	}
+	timeRange := api.TimeRange{
+		Since: startDate.Format("2006-01-02"),
+		Until: clock.Yesterday().Format("2006-01-02"),
+	}
+
+	campaigns, err := client.GetAllCampaignsContext(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting campaigns: %w", err)
+	}
+	performances, err := metricsCollector.CollectCampaignMetricsContext(ctx, api.InsightsRequest{TimeRange: timeRange})
+	if err != nil {
+		return fmt.Errorf("error collecting campaign metrics: %w", err)
+	}
+
+	// Only active campaigns can be paused; runtime counts from their start
+	startTimes := make(map[string]time.Time)
+	for _, campaign := range campaigns {
+		if models.CampaignStatus(campaign.Status) != models.CampaignStatusActive {
+			continue
+		}
+		started := campaign.StartTime
+		if started.IsZero() {
+			started = campaign.Created
+		}
+		startTimes[campaign.ID] = started
+	}
+	var active []models.CampaignPerformance
+	for _, perf := range performances {
+		if _, ok := startTimes[perf.CampaignID]; ok {
+			active = append(active, perf)
+		}
+	}
+
+	events := deactivator.CheckPerformances(active, startTimes, time.Now())
+	if options.cpcFactor > 0 {
+		events = append(events, cpcOutlierEvents(active, events, options.minImpressions, options.cpcFactor, time.Now())...)
+	}
+
+	fmt.Printf("Checked %d active campaigns from %s to %s against %d rules\n\n",
+		len(active), timeRange.Since, timeRange.Until, len(deactivator.Rules()))
+	renderDeactivationEvents(os.Stdout, events)
+
+	if len(events) == 0 {
+		return nil
+	}
+	if options.dryRun {
+		fmt.Printf("\n%d campaigns would be paused (dry run, nothing was changed)\n", len(events))
+		return nil
+	}
+
+	fmt.Println()
+	var paused []utils.DeactivationEvent
+	failed := 0
+	for _, event := range events {
+		params := url.Values{"status": {string(models.CampaignStatusPaused)}}
+		if err := client.UpdateCampaignContext(ctx, event.CampaignID, params); err != nil {
+			fmt.Printf("Error pausing campaign %s: %v\n", event.CampaignID, err)
+			failed++
+			continue
+		}
+		fmt.Printf("Paused campaign %s (%s)\n", event.Name, event.CampaignID)
+		paused = append(paused, event)
+	}
+
+	logPath := filepath.Join(cfg.ConfigDir, "deactivations.log")
+	if err := utils.AppendDeactivationLog(logPath, paused); err != nil {
+		return fmt.Errorf("error writing deactivation log: %w", err)
+	}
+	fmt.Printf("\nLogged %d pauses to %s\n", len(paused), logPath)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d campaigns could not be paused", failed, len(events))
+	}
+	return nil
+}
+
+// cpcOutlierEvents returns an event for every campaign whose CPC the terminator finds far
+// above the median, skipping campaigns that already triggered a rule
+func cpcOutlierEvents(performances []models.CampaignPerformance, ruleEvents []utils.DeactivationEvent, minImpressions int, cpcFactor float64, now time.Time) []utils.DeactivationEvent {
+	triggered := make(map[string]bool, len(ruleEvents))
+	for _, event := range ruleEvents {
+		triggered[event.CampaignID] = true
+	}
+	byID := make(map[string]models.CampaignPerformance, len(performances))
+	for _, perf := range performances {
+		byID[perf.CampaignID] = perf
+	}
+
+	campaigns := optimization.FromModels(performances)
+	terminator := optimization.NewTerminator(minImpressions)
+	threshold := terminator.MedianCPC(campaigns) * cpcFactor
+
+	var events []utils.DeactivationEvent
+	for _, campaignID := range terminator.GetUnderperformingCampaigns(campaigns, cpcFactor) {
+		if triggered[campaignID] {
+			continue
+		}
+		perf := byID[campaignID]
+		events = append(events, utils.DeactivationEvent{
+			CampaignID:  campaignID,
+			Name:        perf.Name,
+			RuleID:      cpcOutlierRuleID,
+			RuleName:    fmt.Sprintf("CPC at least %.1fx the median", cpcFactor),
+			MetricType:  "CPC",
+			Operator:    ">=",
+			MetricValue: perf.CPC,
+			Threshold:   threshold,
+			Timestamp:   now,
+		})
+	}
+	return events
+}
+
+// renderDeactivationEvents writes each event with the metric value that triggered it
+func renderDeactivationEvents(w io.Writer, events []utils.DeactivationEvent) {
+	if len(events) == 0 {
+		fmt.Fprintln(w, "No campaign triggered a rule.")
+		return
+	}
+
+	fmt.Fprintf(w, "%-20s | %-30s | %-30s | %s\n", "ID", "NAME", "RULE", "TRIGGER")
+	fmt.Fprintln(w, strings.Repeat("-", 110))
+	for _, event := range events {
+		fmt.Fprintf(w, "%-20s | %-30s | %-30s | %s %.2f %s %.2f\n",
+			event.CampaignID,
+			truncateString(event.Name, 30),
+			truncateString(event.RuleName, 30),
+			event.MetricType,
+			event.MetricValue,
+			event.Operator,
+			event.Threshold)
+	}
+}
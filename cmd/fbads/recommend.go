@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/internal/optimization"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// budgetUpdate is a new budget, in dollars, for a campaign or one of its ad sets
+type budgetUpdate struct {
+	adSetID        string // Empty for the campaign budget
+	name           string
+	dailyBudget    float64
+	lifetimeBudget float64
+}
+
+// recommendCampaignActions compares the recent performance of the account's campaigns and
+// prints the action recommended for each. With --apply budgets are raised or lowered and
+// campaigns recommended for termination are paused.
+func recommendCampaignActions(cfg *config.Config, args []string) {
+	since := "7d"
+	minImpressions := 1000
+	referenceCPC := 1.0
+	maxCPM := 15.0
+	apply := false
+
+	// Handle flags
+	fs := newCommandFlags("optimize recommend [options]")
+	fs.StringVar(&since, "since", since, "Period to analyze: a date (YYYY-MM-DD) or days like 7d")
+	fs.IntVar(&minImpressions, "min-impressions", minImpressions, "Impressions a campaign needs before it is judged")
+	fs.Float64Var(&referenceCPC, "reference-cpc", referenceCPC, "Benchmark CPC in dollars")
+	fs.Float64Var(&maxCPM, "max-cpm", maxCPM, "Maximum CPM in dollars")
+	fs.BoolVar(&apply, "apply", false, "Change budgets and pause campaigns as recommended")
+	parseCommandArgs(fs, args, 0, 0)
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+	client := api.NewClient(authClient, cfg.AccountID)
+	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+	clock := metricsCollector.Clock()
+
+	// Today is incomplete, so the analysis ends yesterday
+	startDate, err := parseSinceFlag(since, clock.Today())
+	if err != nil {
+		fmt.Printf("Invalid --since value: %v\n", err)
+		os.Exit(1)
+	}
+	timeRange := api.TimeRange{
+		Since: startDate.Format("2006-01-02"),
+		Until: clock.Yesterday().Format("2006-01-02"),
+	}
+
+	performances, err := metricsCollector.CollectCampaignMetricsContext(cmdContext, api.InsightsRequest{TimeRange: timeRange})
+	if err != nil {
+		fmt.Printf("Error collecting campaign metrics: %v\n", err)
+		os.Exit(1)
+	}
+	if len(performances) == 0 {
+		fmt.Println("No campaign delivered in the specified date range.")
+		return
+	}
+
+	names := make(map[string]string, len(performances))
+	for _, perf := range performances {
+		names[perf.CampaignID] = perf.Name
+	}
+
+	analyzer := optimization.NewAnalyzer(minImpressions, referenceCPC)
+	adjuster := optimization.NewAdjuster(maxCPM, 1.0, 10, 10, 24)
+	recommendations := optimization.RecommendCampaignActions(optimization.FromModels(performances), analyzer, adjuster)
+
+	fmt.Printf("Campaign recommendations from %s to %s\n\n", timeRange.Since, timeRange.Until)
+	changes := renderRecommendations(os.Stdout, recommendations, names)
+
+	if changes == 0 {
+		return
+	}
+	if !apply {
+		fmt.Printf("\nRun again with --apply to change %d campaigns\n", changes)
+		return
+	}
+
+	fmt.Println()
+	failed := 0
+	for _, recommendation := range recommendations {
+		campaignID := recommendation.Analytics.CampaignID
+		action := recommendation.Analytics.RecommendedAction
+
+		if action == optimization.ActionTerminate {
+			params := url.Values{"status": {string(models.CampaignStatusPaused)}}
+			if err := client.UpdateCampaignContext(cmdContext, campaignID, params); err != nil {
+				fmt.Printf("Error pausing campaign %s: %v\n", campaignID, err)
+				failed++
+				continue
+			}
+			fmt.Printf("Paused campaign %s (%s)\n", names[campaignID], campaignID)
+			continue
+		}
+
+		factor, ok := optimization.BudgetFactor(action)
+		if !ok {
+			continue
+		}
+
+		details, err := client.GetCampaignDetailsContext(cmdContext, campaignID)
+		if err != nil {
+			fmt.Printf("Error getting campaign %s: %v\n", campaignID, err)
+			failed++
+			continue
+		}
+
+		updates := budgetUpdates(details, factor)
+		if len(updates) == 0 {
+			fmt.Printf("Campaign %s (%s) has no budget to change\n", names[campaignID], campaignID)
+			continue
+		}
+		for _, update := range updates {
+			if err := applyBudgetUpdate(client, campaignID, update); err != nil {
+				fmt.Printf("Error updating the budget of %s: %v\n", update.name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("Set the budget of %s to %s\n", update.name, formatBudget(update))
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// renderRecommendations writes one line per campaign and returns the number of campaigns
+// whose recommendation --apply acts on
+func renderRecommendations(w io.Writer, recommendations []optimization.CampaignRecommendation, names map[string]string) int {
+	fmt.Fprintf(w, "%-20s | %-30s | %-11s | %-7s | %-5s | %-17s | %s\n",
+		"ID", "NAME", "IMPRESSIONS", "CPC", "SCORE", "CPM", "ACTION")
+	fmt.Fprintln(w, strings.Repeat("-", 128))
+
+	changes := 0
+	for _, recommendation := range recommendations {
+		analytics := recommendation.Analytics
+		adjustment := recommendation.Adjustment
+
+		cpm := fmt.Sprintf("$%.2f", adjustment.CurrentCPM)
+		if math.Abs(adjustment.AdjustedCPM-adjustment.CurrentCPM) >= 0.01 {
+			cpm = fmt.Sprintf("$%.2f -> $%.2f", adjustment.CurrentCPM, adjustment.AdjustedCPM)
+		}
+
+		fmt.Fprintf(w, "%-20s | %-30s | %-11d | $%-6.2f | %-5.0f | %-17s | %s\n",
+			analytics.CampaignID,
+			truncateString(names[analytics.CampaignID], 30),
+			analytics.Impressions,
+			analytics.CPC,
+			analytics.PerformanceScore,
+			cpm,
+			analytics.RecommendedAction)
+
+		if _, ok := optimization.BudgetFactor(analytics.RecommendedAction); ok || analytics.RecommendedAction == optimization.ActionTerminate {
+			changes++
+		}
+	}
+	return changes
+}
+
+// budgetUpdates scales the budgets of a campaign by factor. A campaign budget is changed on the
+// campaign; otherwise the budgets of its active ad sets are.
+func budgetUpdates(details *models.CampaignDetails, factor float64) []budgetUpdate {
+	if details.DailyBudget > 0 || details.LifetimeBudget > 0 {
+		return []budgetUpdate{{
+			name:           fmt.Sprintf("campaign %s (%s)", details.Name, details.ID),
+			dailyBudget:    models.CentsToDollars(details.DailyBudget) * factor,
+			lifetimeBudget: models.CentsToDollars(details.LifetimeBudget) * factor,
+		}}
+	}
+
+	var updates []budgetUpdate
+	for _, adSet := range details.AdSets {
+		if models.CampaignStatus(adSet.Status) != models.CampaignStatusActive {
+			continue
+		}
+		if adSet.DailyBudget <= 0 && adSet.LifetimeBudget <= 0 {
+			continue
+		}
+		updates = append(updates, budgetUpdate{
+			adSetID:        adSet.ID,
+			name:           fmt.Sprintf("ad set %s (%s)", adSet.Name, adSet.ID),
+			dailyBudget:    models.CentsToDollars(adSet.DailyBudget) * factor,
+			lifetimeBudget: models.CentsToDollars(adSet.LifetimeBudget) * factor,
+		})
+	}
+	return updates
+}
+
+// applyBudgetUpdate sends a new budget of a campaign or one of its ad sets
+func applyBudgetUpdate(client *api.Client, campaignID string, update budgetUpdate) error {
+	if update.adSetID != "" {
+		return client.UpdateAdSetBudgetContext(cmdContext, update.adSetID, update.dailyBudget, update.lifetimeBudget)
+	}
+
+	params := url.Values{}
+	if update.dailyBudget > 0 {
+		params.Set("daily_budget", strconv.FormatInt(models.DollarsToCents(update.dailyBudget), 10))
+	} else {
+		params.Set("lifetime_budget", strconv.FormatInt(models.DollarsToCents(update.lifetimeBudget), 10))
+	}
+	return client.UpdateCampaignContext(cmdContext, campaignID, params)
+}
+
+// formatBudget describes the budget of an update
+func formatBudget(update budgetUpdate) string {
+	if update.dailyBudget > 0 {
+		return fmt.Sprintf("$%.2f per day", update.dailyBudget)
+	}
+	return fmt.Sprintf("$%.2f lifetime", update.lifetimeBudget)
+}
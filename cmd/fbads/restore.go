@@ -0,0 +1,320 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/user/fb-ads/internal/api"
+	internal_campaign "github.com/user/fb-ads/internal/campaign"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// restoreItem is one campaign configuration read from a backup directory
+type restoreItem struct {
+	file   string
+	config *models.CampaignConfig
+}
+
+// restoreAccount recreates campaigns from a backup directory written by the backup command
+func restoreAccount(cfg *config.Config, args []string) {
+	var (
+		dir      string
+		status   = "PAUSED" // Default to PAUSED for safety
+		prefix   string
+		suffix   string
+		rollback bool
+		dryRun   bool
+		force    bool
+	)
+
+	// Handle flags
+	fs := newCommandFlags("restore --dir <backup_dir> [options]")
+	fs.StringVar(&dir, "dir", "", "Backup directory to restore")
+	fs.StringVar(&status, "status", status, "Status of the restored campaigns")
+	fs.StringVar(&prefix, "prefix", "", "Prefix added to the restored campaign names")
+	fs.StringVar(&suffix, "suffix", "", "Suffix added to the restored campaign names")
+	fs.BoolVar(&rollback, "rollback", false, "Undo everything created so far when a campaign fails")
+	fs.BoolVar(&dryRun, "dry-run", false, "Show what would be restored")
+	alias(fs, "d", "dry-run")
+	fs.BoolVar(&force, "force", false, "Restore without asking for confirmation")
+	alias(fs, "f", "force")
+	parseCommandArgs(fs, args, 0, 0)
+
+	status = mustParseSettableStatus(status)
+
+	if dir == "" {
+		fmt.Println("Missing backup directory. Use: fbads restore --dir <backup_dir> [options]")
+		os.Exit(1)
+	}
+
+	files, err := restoreFiles(dir)
+	if err != nil {
+		fmt.Printf("Error reading backup directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(files) == 0 {
+		fmt.Printf("No campaign configurations found in %s\n", dir)
+		return
+	}
+
+	// Validate every file before creating anything
+	var items []restoreItem
+	invalid := 0
+	for _, file := range files {
+		campaignConfig, err := loadRestoreConfig(filepath.Join(dir, file))
+		if err == nil {
+			err = validateCampaignConfig(campaignConfig)
+		}
+		if err != nil {
+			fmt.Printf("  Invalid %s: %v\n", file, err)
+			invalid++
+			continue
+		}
+
+		// Rename and pause the restored copy
+		campaignConfig.Name = prefix + campaignConfig.Name + suffix
+		applyStatus(campaignConfig, strings.ToUpper(status))
+
+		items = append(items, restoreItem{file: file, config: campaignConfig})
+	}
+
+	if invalid > 0 {
+		fmt.Printf("\n%d of %d files failed validation. Nothing was restored.\n", invalid, len(files))
+		os.Exit(1)
+	}
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create API client
+	client := api.NewClient(authClient, cfg.AccountID)
+
+	// Skip campaigns that already exist with the restored name
+	existing, err := client.GetAllCampaignsContext(cmdContext)
+	if err != nil {
+		fmt.Printf("Error fetching existing campaigns: %v\n", err)
+		os.Exit(1)
+	}
+
+	existingNames := make(map[string]bool, len(existing))
+	for _, campaign := range existing {
+		existingNames[campaign.Name] = true
+	}
+
+	var pending []restoreItem
+	skipped := 0
+	for _, item := range items {
+		if existingNames[item.config.Name] {
+			fmt.Printf("  Skipping %s: campaign %q already exists\n", item.file, item.config.Name)
+			skipped++
+			continue
+		}
+		pending = append(pending, item)
+	}
+
+	fmt.Printf("\n%d campaigns to restore, %d already present (status: %s)\n", len(pending), skipped, strings.ToUpper(status))
+	for _, item := range pending {
+		fmt.Printf("  %s -> %s\n", item.file, item.config.Name)
+	}
+
+	if dryRun {
+		fmt.Println("\nDry run: No campaigns will be created.")
+		return
+	}
+
+	if len(pending) == 0 {
+		return
+	}
+
+	configs := make([]*models.CampaignConfig, 0, len(pending))
+	for _, item := range pending {
+		configs = append(configs, item.config)
+	}
+	ensureAccountCapacity(client, cfg, plannedObjects(configs))
+
+	// Ask for confirmation
+	if !force {
+		fmt.Print("\nDo you want to restore these campaigns? (y/n): ")
+		var confirm string
+		fmt.Scanln(&confirm)
+
+		if confirm != "y" && confirm != "Y" && confirm != "yes" && confirm != "Yes" {
+			fmt.Println("Restore cancelled.")
+			return
+		}
+	}
+
+	// Create campaign creator
+	creator := newCampaignCreator(authClient, cfg)
+
+	result := restoreCampaigns(os.Stdout, pending, rollback,
+		func(campaignConfig *models.CampaignConfig) (string, error) {
+			return creator.CreateFromConfigWithIDContext(cmdContext, campaignConfig)
+		},
+		func(campaignID string) error {
+			return client.DeleteCampaignContext(cmdContext, campaignID)
+		})
+
+	// Print final summary
+	fmt.Printf("\nRestore completed:\n")
+	fmt.Printf("  Restored: %d\n", result.Restored)
+	fmt.Printf("  Skipped: %d\n", skipped)
+	fmt.Printf("  Failed: %d\n", len(result.Failures))
+	for _, failure := range result.Failures {
+		fmt.Printf("    - %s\n", failure)
+	}
+	if rollback && len(result.Failures) > 0 {
+		fmt.Printf("  Rolled back: %d\n", result.RolledBack)
+		fmt.Printf("  Not attempted: %d\n", result.NotAttempted)
+	}
+	if len(result.Remaining) > 0 {
+		fmt.Printf("  Left after a failed rollback: %s\n", strings.Join(result.Remaining, ", "))
+	}
+
+	if len(result.Failures) > 0 {
+		os.Exit(1)
+	}
+}
+
+// restoreResult is the outcome of restoring the pending campaigns of a backup
+type restoreResult struct {
+	Restored     int      // Campaigns created and kept, including ones the rollback could not delete
+	RolledBack   int      // Campaigns created, then deleted by the rollback
+	NotAttempted int      // Campaigns left out after the failure that started the rollback
+	Failures     []string // Campaigns that failed, with their errors
+	Remaining    []string // IDs of campaigns the rollback could not delete
+}
+
+// restoreCampaigns creates the pending campaigns in order. With rollback the first failure
+// stops the restore and every campaign created so far, including one left incomplete by
+// the failure, is deleted again.
+func restoreCampaigns(w io.Writer, pending []restoreItem, rollback bool,
+	create func(*models.CampaignConfig) (string, error), remove func(campaignID string) error) restoreResult {
+	var result restoreResult
+	var createdIDs []string
+	incomplete := make(map[string]bool)
+
+	for i, item := range pending {
+		fmt.Fprintf(w, "\n[%d/%d] Restoring %s...\n", i+1, len(pending), item.config.Name)
+
+		campaignID, err := create(item.config)
+		if campaignID != "" {
+			createdIDs = append(createdIDs, campaignID)
+		}
+		if err == nil {
+			result.Restored++
+			continue
+		}
+
+		if campaignID != "" {
+			incomplete[campaignID] = true
+		}
+		fmt.Fprintf(w, "  FAILED: %v\n", err)
+		result.Failures = append(result.Failures, fmt.Sprintf("%s: %v", item.file, err))
+
+		// With rollback the first failure undoes everything created so far
+		if rollback {
+			result.NotAttempted = len(pending) - i - 1
+			break
+		}
+	}
+
+	if !rollback || len(result.Failures) == 0 {
+		return result
+	}
+
+	fmt.Fprintf(w, "\nRolling back %d created campaigns...\n", len(createdIDs))
+	for _, campaignID := range createdIDs {
+		if err := remove(campaignID); err != nil {
+			fmt.Fprintf(w, "  Error deleting %s: %v\n", campaignID, err)
+			result.Remaining = append(result.Remaining, campaignID)
+			continue
+		}
+		fmt.Fprintf(w, "  Deleted %s\n", campaignID)
+		result.RolledBack++
+		if !incomplete[campaignID] {
+			result.Restored--
+		}
+	}
+	return result
+}
+
+// restoreFiles lists the campaign configuration files of a backup directory,
+// preferring the order recorded in the manifest
+func restoreFiles(dir string) ([]string, error) {
+	var manifest BackupManifest
+	if data, err := os.ReadFile(filepath.Join(dir, backupManifestFile)); err == nil {
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", backupManifestFile, err)
+		}
+
+		files := make([]string, 0, len(manifest.Campaigns))
+		for _, entry := range manifest.Campaigns {
+			files = append(files, entry.File)
+		}
+		return files, nil
+	}
+
+	// Without a manifest every campaign file in the directory is restored
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" ||
+			name == backupManifestFile || name == backupCreativesFile || strings.HasSuffix(name, "_insights.json") {
+			continue
+		}
+		files = append(files, name)
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// loadRestoreConfig reads a campaign configuration file
+func loadRestoreConfig(path string) (*models.CampaignConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var campaignConfig models.CampaignConfig
+	if err := json.Unmarshal(data, &campaignConfig); err != nil {
+		return nil, fmt.Errorf("error parsing configuration: %w", err)
+	}
+
+	// Backups made with the structure profile keep their creatives in a shared library
+	if internal_campaign.HasCreativeRefs(&campaignConfig) {
+		library, err := internal_campaign.LoadCreativeLibrary(filepath.Join(filepath.Dir(path), backupCreativesFile))
+		if err != nil {
+			return nil, fmt.Errorf("error reading creative library: %w", err)
+		}
+		if err := library.Resolve(&campaignConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	return &campaignConfig, nil
+}
+
+// applyStatus sets the status of a campaign and all its ad sets and ads
+func applyStatus(campaignConfig *models.CampaignConfig, status string) {
+	campaignConfig.Status = status
+	for i := range campaignConfig.AdSets {
+		campaignConfig.AdSets[i].Status = status
+	}
+	for _, ad := range campaignConfig.AllAds() {
+		ad.Status = status
+	}
+}
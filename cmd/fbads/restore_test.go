@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestRestoreCampaigns(t *testing.T) {
+	pending := []restoreItem{
+		{file: "1.json", config: &models.CampaignConfig{Name: "Spring"}},
+		{file: "2.json", config: &models.CampaignConfig{Name: "Summer"}},
+		{file: "3.json", config: &models.CampaignConfig{Name: "Autumn"}},
+		{file: "4.json", config: &models.CampaignConfig{Name: "Winter"}},
+	}
+
+	// Summer fails after its campaign was created
+	create := func(campaignConfig *models.CampaignConfig) (string, error) {
+		switch campaignConfig.Name {
+		case "Spring":
+			return "101", nil
+		case "Summer":
+			return "102", errors.New("ad set rejected")
+		case "Autumn":
+			return "103", nil
+		default:
+			return "", errors.New("invalid creative")
+		}
+	}
+
+	tests := []struct {
+		name             string
+		rollback         bool
+		failDelete       string
+		expected         restoreResult
+		expectedDeleted  []string
+		expectedFailures int
+	}{
+		{
+			name:             "without rollback",
+			expected:         restoreResult{Restored: 2},
+			expectedFailures: 2,
+		},
+		{
+			name:             "with rollback",
+			rollback:         true,
+			expected:         restoreResult{RolledBack: 2, NotAttempted: 2},
+			expectedDeleted:  []string{"101", "102"},
+			expectedFailures: 1,
+		},
+		{
+			name:             "rollback failing to delete",
+			rollback:         true,
+			failDelete:       "101",
+			expected:         restoreResult{Restored: 1, RolledBack: 1, NotAttempted: 2, Remaining: []string{"101"}},
+			expectedDeleted:  []string{"102"},
+			expectedFailures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted []string
+			remove := func(campaignID string) error {
+				if campaignID == tt.failDelete {
+					return errors.New("permission denied")
+				}
+				deleted = append(deleted, campaignID)
+				return nil
+			}
+
+			result := restoreCampaigns(io.Discard, pending, tt.rollback, create, remove)
+			if len(result.Failures) != tt.expectedFailures {
+				t.Errorf("Expected %d failures, got %v", tt.expectedFailures, result.Failures)
+			}
+			result.Failures = nil
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("restoreCampaigns = %+v, want %+v", result, tt.expected)
+			}
+			if !reflect.DeepEqual(deleted, tt.expectedDeleted) {
+				t.Errorf("Expected %v to be deleted, got %v", tt.expectedDeleted, deleted)
+			}
+		})
+	}
+}
+
+func TestRestoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"2.json", "1.json", "1_insights.json", backupCreativesFile, "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without a manifest the campaign files of the directory are restored by name
+	files, err := restoreFiles(dir)
+	if err != nil {
+		t.Fatalf("restoreFiles failed: %v", err)
+	}
+	if !reflect.DeepEqual(files, []string{"1.json", "2.json"}) {
+		t.Errorf("Expected the campaign files, got %v", files)
+	}
+
+	// A manifest sets the files and their order
+	manifest := `{"campaigns":[{"id":"2","file":"2.json"},{"id":"1","file":"1.json"}]}`
+	if err := os.WriteFile(filepath.Join(dir, backupManifestFile), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files, err = restoreFiles(dir)
+	if err != nil {
+		t.Fatalf("restoreFiles failed: %v", err)
+	}
+	if !reflect.DeepEqual(files, []string{"2.json", "1.json"}) {
+		t.Errorf("Expected the manifest order, got %v", files)
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/internal/optimization"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// optimizeAds analyzes how delivery is shared between the ads of each ad set of a campaign
+// and, with --apply, pauses ads that are significantly worse than their siblings
+func optimizeAds(cfg *config.Config, args []string) {
+	campaignID := ""
+	since := "7d"
+	apply := false
+
+	// Handle flags
+	fs := newCommandFlags("optimize ads --campaign <id> [options]")
+	fs.StringVar(&campaignID, "campaign", "", "Campaign whose ads are compared")
+	fs.StringVar(&since, "since", since, "Period to analyze: a date (YYYY-MM-DD) or days like 7d")
+	fs.BoolVar(&apply, "apply", false, "Pause the ads that are significantly worse")
+	parseCommandArgs(fs, args, 0, 0)
+
+	if campaignID == "" {
+		fmt.Println("Missing campaign ID. Use: fbads optimize ads --campaign <id> [--since 7d] [--apply]")
+		os.Exit(1)
+	}
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+	client := api.NewClient(authClient, cfg.AccountID)
+	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+	clock := metricsCollector.Clock()
+
+	// Today is incomplete, so the analysis ends yesterday
+	startDate, err := parseSinceFlag(since, clock.Today())
+	if err != nil {
+		fmt.Printf("Invalid --since value: %v\n", err)
+		os.Exit(1)
+	}
+	timeRange := api.TimeRange{
+		Since: startDate.Format("2006-01-02"),
+		Until: clock.Today().AddDate(0, 0, -1).Format("2006-01-02"),
+	}
+
+	details, err := client.GetCampaignDetails(campaignID)
+	if err != nil {
+		fmt.Printf("Error getting campaign: %v\n", err)
+		os.Exit(1)
+	}
+
+	performances, err := metricsCollector.CollectCampaignAdMetrics(campaignID, timeRange)
+	if err != nil {
+		fmt.Printf("Error collecting ad metrics: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Ad rotation of %s (%s) from %s to %s\n\n", details.Name, campaignID, timeRange.Since, timeRange.Until)
+
+	plans := optimization.PlanAdRotation(rotationAds(details, performances), optimization.DefaultRotationConfig())
+	pauses := renderRotationPlan(os.Stdout, plans)
+
+	if pauses == 0 {
+		return
+	}
+	if !apply {
+		fmt.Printf("\nRun again with --apply to pause %d ads\n", pauses)
+		return
+	}
+
+	fmt.Println()
+	failed := 0
+	for _, plan := range plans {
+		for _, ad := range plan.Pauses() {
+			if err := client.SetAdStatus(ad.AdID, string(models.CampaignStatusPaused)); err != nil {
+				fmt.Printf("Error pausing ad %s: %v\n", ad.AdID, err)
+				failed++
+				continue
+			}
+			fmt.Printf("Paused ad %s (%s)\n", ad.AdName, ad.AdID)
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// rotationAds combines the ads of a campaign with their delivery. Ads without
+// delivery are included with zero impressions since starving them is the point.
+func rotationAds(details *models.CampaignDetails, performances []api.AdPerformance) []optimization.RotationAd {
+	adSetNames := make(map[string]string, len(details.AdSets))
+	for _, adSet := range details.AdSets {
+		adSetNames[adSet.ID] = adSet.Name
+	}
+
+	byAd := make(map[string]api.AdPerformance, len(performances))
+	for _, perf := range performances {
+		byAd[perf.AdID] = perf
+	}
+
+	var ads []optimization.RotationAd
+	for _, ad := range details.Ads {
+		status := models.CampaignStatus(ad.Status)
+		if status == models.CampaignStatusArchived || status == models.CampaignStatusDeleted {
+			continue
+		}
+
+		perf := byAd[ad.ID]
+		adSetID := ad.AdSetID
+		if adSetID == "" {
+			adSetID = perf.AdSetID
+		}
+
+		ads = append(ads, optimization.RotationAd{
+			AdID:        ad.ID,
+			AdName:      ad.Name,
+			AdSetID:     adSetID,
+			AdSetName:   adSetNames[adSetID],
+			Active:      status == models.CampaignStatusActive,
+			Impressions: perf.Impressions,
+			Clicks:      perf.Clicks,
+			Conversions: perf.Conversions,
+			Spend:       perf.Spend,
+		})
+	}
+	return ads
+}
+
+// renderRotationPlan writes the per-ad-set plan and returns the number of ads it pauses
+func renderRotationPlan(w io.Writer, plans []optimization.AdSetRotationPlan) int {
+	if len(plans) == 0 {
+		fmt.Fprintln(w, "The campaign has no ads to analyze.")
+		return 0
+	}
+
+	pauses := 0
+	for _, plan := range plans {
+		fmt.Fprintf(w, "Ad set %s (%s): %d impressions\n", plan.AdSetName, plan.AdSetID, plan.Impressions)
+		if !plan.Sufficient {
+			fmt.Fprintln(w, "  Not enough delivery yet to judge its ads")
+		}
+
+		fmt.Fprintf(w, "  %-28s | %-7s | %-6s | %-11s | %-6s | %-9s | %-9s | %s\n",
+			"AD", "STATUS", "SHARE", "IMPRESSIONS", "CTR", "REL. CTR", "CPA", "ACTION")
+		for _, ad := range plan.Ads {
+			status := "paused"
+			if ad.Active {
+				status = "active"
+			}
+
+			cpa := "-"
+			if ad.CPA > 0 {
+				cpa = fmt.Sprintf("$%.2f", ad.CPA)
+			}
+
+			action := "keep"
+			switch {
+			case ad.Pause:
+				action = "PAUSE: " + ad.Reason
+				pauses++
+			case ad.Starved:
+				action = "starved: less than 10% of the delivery"
+			case !ad.Active:
+				action = "-"
+			}
+
+			fmt.Fprintf(w, "  %-28s | %-7s | %5.1f%% | %-11d | %5.2f%% | %-9.2f | %-9s | %s\n",
+				truncateString(ad.AdName, 28),
+				status,
+				ad.Share*100,
+				ad.Impressions,
+				ad.CTR*100,
+				ad.RelativeCTR,
+				cpa,
+				action)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "%d ads to pause\n", pauses)
+	return pauses
+}
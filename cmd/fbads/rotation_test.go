@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/optimization"
+	"github.com/user/fb-ads/pkg/models"
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+func TestRotationAds(t *testing.T) {
+	details := &models.CampaignDetails{
+		AdSets: []models.AdSetDetails{{ID: "s1", Name: "Broad"}},
+		Ads: []models.AdDetails{
+			{ID: "a", Name: "Hero", Status: "ACTIVE", AdSetID: "s1"},
+			{ID: "b", Name: "Starved", Status: "ACTIVE", AdSetID: "s1"},
+			{ID: "c", Name: "Old", Status: "PAUSED", AdSetID: "s1"},
+			{ID: "d", Name: "Gone", Status: "ARCHIVED", AdSetID: "s1"},
+		},
+	}
+	performances := []api.AdPerformance{
+		{AdID: "a", AdSetID: "s1", CampaignPerformance: utils.CampaignPerformance{Impressions: 9000, Clicks: 90}},
+		{AdID: "c", AdSetID: "s1", CampaignPerformance: utils.CampaignPerformance{Impressions: 1000, Clicks: 5}},
+	}
+
+	ads := rotationAds(details, performances)
+	if len(ads) != 3 {
+		t.Fatalf("Expected archived ads to be left out, got %+v", ads)
+	}
+	if ads[0].Impressions != 9000 || ads[0].AdSetName != "Broad" || !ads[0].Active {
+		t.Errorf("Expected the delivery of ad a, got %+v", ads[0])
+	}
+	if ads[1].AdID != "b" || ads[1].Impressions != 0 || !ads[1].Active {
+		t.Errorf("Expected ad b without delivery to be included, got %+v", ads[1])
+	}
+	if ads[2].Active {
+		t.Errorf("Expected the paused ad to be inactive")
+	}
+
+	plans := optimization.PlanAdRotation(ads, optimization.DefaultRotationConfig())
+	var buf bytes.Buffer
+	if pauses := renderRotationPlan(&buf, plans); pauses != 0 {
+		t.Errorf("Expected no pauses, got %d", pauses)
+	}
+	if !strings.Contains(buf.String(), "starved") {
+		t.Errorf("Expected the starved ad to be flagged, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/audience"
+	internal_campaign "github.com/user/fb-ads/internal/campaign"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// serveCreateTimeout bounds the creation of a campaign, which runs on after its client disconnects
+const serveCreateTimeout = 10 * time.Minute
+
+// serveBackend is what the REST server needs from the Facebook API. Calls take the
+// request context so upstream work stops when the client disconnects, except for
+// campaign creation, see creationContext.
+type serveBackend interface {
+	GetAllCampaignsContext(ctx context.Context) ([]models.Campaign, error)
+	GetCampaignDetailsContext(ctx context.Context, campaignID string) (*models.CampaignDetails, error)
+	UpdateCampaignContext(ctx context.Context, campaignID string, params url.Values) error
+	CreateFromConfigWithIDContext(ctx context.Context, config *models.CampaignConfig) (string, error)
+	WeeklyReport(ctx context.Context) (*api.PerformanceAnalysis, error)
+}
+
+// liveBackend serves requests from the Facebook Marketing API
+type liveBackend struct {
+	*api.Client
+	creator  *internal_campaign.CampaignCreator
+	analyzer *api.PerformanceAnalyzer
+	clock    *api.AccountClock
+}
+
+// CreateFromConfigWithIDContext creates a campaign with the campaign creator and returns its ID
+func (b *liveBackend) CreateFromConfigWithIDContext(ctx context.Context, config *models.CampaignConfig) (string, error) {
+	return b.creator.CreateFromConfigWithIDContext(ctx, config)
+}
+
+// WeeklyReport analyzes campaign performance for the last 7 days in the account timezone
+func (b *liveBackend) WeeklyReport(ctx context.Context) (*api.PerformanceAnalysis, error) {
+	return b.analyzer.AnalyzeCampaignPerformanceContext(ctx, b.clock.LastDays(7))
+}
+
+// apiServer exposes campaign operations as a JSON REST API
+type apiServer struct {
+	backend  serveBackend
+	token    string
+	readOnly bool
+}
+
+// apiError is the JSON body returned for failed requests
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// duplicateRequest is the body of POST /campaigns/{id}/duplicate
+type duplicateRequest struct {
+	Name         string  `json:"name,omitempty"`
+	Status       string  `json:"status,omitempty"`
+	BudgetFactor float64 `json:"budget_factor,omitempty"`
+}
+
+// statusRequest is the body of POST /campaigns/{id}/status
+type statusRequest struct {
+	Status string `json:"status"`
+}
+
+// serveAPI starts the REST API server
+func serveAPI(cfg *config.Config, args []string) {
+	port := 9090
+	bind := "127.0.0.1"
+	token := os.Getenv("FBADS_API_TOKEN")
+	readOnly := false
+
+	// Handle flags
+	fs := newCommandFlags("serve [options]")
+	fs.IntVar(&port, "port", port, "Port to listen on")
+	fs.StringVar(&bind, "bind", bind, "Address to listen on; empty or 0.0.0.0 for every interface")
+	fs.StringVar(&token, "token", token, "Bearer token required by the API (default: $FBADS_API_TOKEN)")
+	fs.BoolVar(&readOnly, "read-only", false, "Reject requests that change campaigns")
+	parseCommandArgs(fs, args, 0, 0)
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create API clients
+	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+	audienceAnalyzer := audience.NewAudienceAnalyzer(authClient, cfg.AccountID)
+
+	analyzer := api.NewPerformanceAnalyzer(metricsCollector, audienceAnalyzer)
+	analyzer.SetRecommendationThresholds(cfg.Recommendations)
+	analyzer.SetConversionValues(cfg.ConversionValues())
+
+	backend := &liveBackend{
+		Client:   api.NewClient(authClient, cfg.AccountID),
+		creator:  newCampaignCreator(authClient, cfg),
+		analyzer: analyzer,
+		clock:    metricsCollector.Clock(),
+	}
+
+	// Without a token only local clients may change campaigns
+	if token == "" {
+		if isLoopbackAddress(bind) {
+			fmt.Println("Warning: no API token set (--token or FBADS_API_TOKEN), requests are not authenticated")
+		} else if !readOnly {
+			fmt.Println("Warning: no API token set (--token or FBADS_API_TOKEN) and the API is reachable from other machines")
+			readOnly = true
+		}
+	}
+	if readOnly {
+		fmt.Println("Read-only mode: write endpoints are disabled")
+	}
+
+	server := &apiServer{backend: backend, token: token, readOnly: readOnly}
+
+	address := net.JoinHostPort(bind, strconv.Itoa(port))
+	fmt.Printf("Starting REST API on http://%s\n", net.JoinHostPort(bindHost(bind), strconv.Itoa(port)))
+
+	if err := http.ListenAndServe(address, server.Handler()); err != nil {
+		fmt.Printf("Error starting API server: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// isLoopbackAddress reports whether a bind address only accepts connections from this machine
+func isLoopbackAddress(bind string) bool {
+	if bind == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(bind)
+	return ip != nil && ip.IsLoopback()
+}
+
+// bindHost returns the host to show for a bind address; every interface is shown as localhost
+func bindHost(bind string) string {
+	if bind == "" || bind == "0.0.0.0" || bind == "::" {
+		return "localhost"
+	}
+	return bind
+}
+
+// Handler returns the HTTP handler with all routes
+func (s *apiServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/campaigns", s.handleCampaigns)
+	mux.HandleFunc("/campaigns/", s.handleCampaign)
+	mux.HandleFunc("/reports/weekly", s.handleWeeklyReport)
+	return s.authenticate(mux)
+}
+
+// authenticate checks the bearer token and the read-only mode
+func (s *apiServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" && !api.SecureEqual(r.Header.Get("Authorization"), "Bearer "+s.token) {
+			writeAPIError(w, http.StatusUnauthorized, "unauthorized", "missing or invalid bearer token")
+			return
+		}
+
+		if s.readOnly && r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusForbidden, "read_only", "server is running in read-only mode")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleCampaigns serves GET /campaigns and POST /campaigns
+func (s *apiServer) handleCampaigns(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		campaigns, err := s.backend.GetAllCampaignsContext(r.Context())
+		if err != nil {
+			writeAPIError(w, http.StatusBadGateway, "api_error", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, campaigns)
+
+	case http.MethodPost:
+		var campaignConfig models.CampaignConfig
+		if err := json.NewDecoder(r.Body).Decode(&campaignConfig); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
+			return
+		}
+
+		// Same validation as the create command
+		if err := validateCampaignConfig(&campaignConfig); err != nil {
+			writeAPIError(w, http.StatusUnprocessableEntity, "validation_failed", err.Error())
+			return
+		}
+
+		s.create(w, r, &campaignConfig)
+
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use GET or POST")
+	}
+}
+
+// handleCampaign serves /campaigns/{id}, /campaigns/{id}/duplicate and /campaigns/{id}/status
+func (s *apiServer) handleCampaign(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/campaigns/"), "/"), "/")
+	campaignID := parts[0]
+	if campaignID == "" || len(parts) > 2 {
+		writeAPIError(w, http.StatusNotFound, "not_found", "unknown route")
+		return
+	}
+
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		details, err := s.backend.GetCampaignDetailsContext(r.Context(), campaignID)
+		if err != nil {
+			writeAPIError(w, http.StatusBadGateway, "api_error", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, details)
+
+	case action == "duplicate" && r.Method == http.MethodPost:
+		s.duplicate(w, r, campaignID)
+
+	case action == "status" && r.Method == http.MethodPost:
+		s.setStatus(w, r, campaignID)
+
+	case action == "" || action == "duplicate" || action == "status":
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed for this route")
+
+	default:
+		writeAPIError(w, http.StatusNotFound, "not_found", "unknown route")
+	}
+}
+
+// duplicate creates a copy of a campaign
+func (s *apiServer) duplicate(w http.ResponseWriter, r *http.Request, campaignID string) {
+	request := duplicateRequest{Status: "PAUSED", BudgetFactor: 1.0}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
+			return
+		}
+	}
+	if request.BudgetFactor <= 0 {
+		request.BudgetFactor = 1.0
+	}
+
+	if request.Status == "" {
+		request.Status = "PAUSED"
+	}
+	status, err := models.ParseSettableCampaignStatus(request.Status)
+	if err != nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, "validation_failed", err.Error())
+		return
+	}
+
+	details, err := s.backend.GetCampaignDetailsContext(r.Context(), campaignID)
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, "api_error", err.Error())
+		return
+	}
+
+	// Same path as the duplicate command
+	campaignConfig := internal_campaign.DuplicateConfig(details, internal_campaign.DuplicateOptions{
+		Name:         request.Name,
+		Status:       string(status),
+		BudgetFactor: request.BudgetFactor,
+	})
+
+	// Same validation as POST /campaigns
+	if err := validateCampaignConfig(campaignConfig); err != nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, "validation_failed", err.Error())
+		return
+	}
+
+	s.create(w, r, campaignConfig)
+}
+
+// create creates a campaign and answers with its ID
+func (s *apiServer) create(w http.ResponseWriter, r *http.Request, campaignConfig *models.CampaignConfig) {
+	ctx, cancel := creationContext(r)
+	defer cancel()
+
+	campaignID, err := s.backend.CreateFromConfigWithIDContext(ctx, campaignConfig)
+	if err != nil {
+		// A campaign created without all of its ad sets or ads is named so it can be cleaned up
+		if campaignID != "" {
+			err = fmt.Errorf("campaign %s was created but is incomplete: %w", campaignID, err)
+		}
+		writeAPIError(w, http.StatusBadGateway, "api_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"status": "created", "id": campaignID, "name": campaignConfig.Name})
+}
+
+// creationContext returns the context a campaign is created with. It is detached from
+// the request: a client that disconnects halfway must not leave a campaign without its
+// ad sets or ads.
+func creationContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(r.Context()), serveCreateTimeout)
+}
+
+// setStatus changes the status of a campaign
+func (s *apiServer) setStatus(w http.ResponseWriter, r *http.Request, campaignID string) {
+	var request statusRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
+		return
+	}
+
+	status, err := models.ParseSettableCampaignStatus(request.Status)
+	if err != nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, "validation_failed", err.Error())
+		return
+	}
+
+	params := url.Values{}
+	params.Set("status", string(status))
+	if err := s.backend.UpdateCampaignContext(r.Context(), campaignID, params); err != nil {
+		writeAPIError(w, http.StatusBadGateway, "api_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id": campaignID, "status": string(status)})
+}
+
+// handleWeeklyReport serves GET /reports/weekly
+func (s *apiServer) handleWeeklyReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use GET")
+		return
+	}
+
+	analysis, err := s.backend.WeeklyReport(r.Context())
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, "api_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, analysis)
+}
+
+// writeJSON writes a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeAPIError writes a structured JSON error
+func writeAPIError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]apiError{"error": {Code: code, Message: message}})
+}
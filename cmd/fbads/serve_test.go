@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// fakeBackend records calls made by the API server
+type fakeBackend struct {
+	created     []*models.CampaignConfig
+	createCtxes []context.Context
+	updates     map[string]url.Values
+}
+
+func (f *fakeBackend) GetAllCampaignsContext(ctx context.Context) ([]models.Campaign, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return []models.Campaign{{ID: "1", Name: "First"}, {ID: "2", Name: "Second"}}, nil
+}
+
+func (f *fakeBackend) GetCampaignDetailsContext(ctx context.Context, campaignID string) (*models.CampaignDetails, error) {
+	// Campaign 43 has no ad set, so a copy of it can't be created
+	if campaignID == "43" {
+		return &models.CampaignDetails{ID: campaignID, Name: "Empty", ObjectiveType: "OUTCOME_TRAFFIC", BuyingType: "AUCTION"}, nil
+	}
+	return &models.CampaignDetails{
+		ID:            campaignID,
+		Name:          "Original",
+		Status:        "ACTIVE",
+		ObjectiveType: "OUTCOME_TRAFFIC",
+		BuyingType:    "AUCTION",
+		DailyBudget:   2000, // cents
+		AdSets: []models.AdSetDetails{{
+			ID:               "111",
+			Name:             "Ad Set",
+			OptimizationGoal: "LINK_CLICKS",
+			BillingEvent:     "IMPRESSIONS",
+			Targeting:        map[string]interface{}{"age_min": 18, "geo_locations": map[string]interface{}{"countries": []interface{}{"US"}}},
+		}},
+		Ads: []models.AdDetails{{
+			Name:     "Ad",
+			AdSetID:  "111",
+			Creative: models.CreativeDetails{Title: "Title", LinkURL: "https://example.com", PageID: "1"},
+		}},
+	}, nil
+}
+
+func (f *fakeBackend) UpdateCampaignContext(ctx context.Context, campaignID string, params url.Values) error {
+	if f.updates == nil {
+		f.updates = make(map[string]url.Values)
+	}
+	f.updates[campaignID] = params
+	return nil
+}
+
+func (f *fakeBackend) CreateFromConfigWithIDContext(ctx context.Context, config *models.CampaignConfig) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	f.created = append(f.created, config)
+	f.createCtxes = append(f.createCtxes, ctx)
+	return fmt.Sprintf("9%02d", len(f.created)), nil
+}
+
+func (f *fakeBackend) WeeklyReport(ctx context.Context) (*api.PerformanceAnalysis, error) {
+	return &api.PerformanceAnalysis{TotalSpend: 123.45}, nil
+}
+
+func newTestServer(backend *fakeBackend, token string, readOnly bool) *httptest.Server {
+	server := &apiServer{backend: backend, token: token, readOnly: readOnly}
+	return httptest.NewServer(server.Handler())
+}
+
+func doRequest(t *testing.T, method, url, body, token string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Error creating request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Error sending request: %v", err)
+	}
+	return resp
+}
+
+func decodeError(t *testing.T, resp *http.Response) apiError {
+	t.Helper()
+
+	var body map[string]apiError
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding error body: %v", err)
+	}
+	return body["error"]
+}
+
+const validCampaignJSON = `{
+	"name": "API Campaign",
+	"objective": "OUTCOME_TRAFFIC",
+	"buying_type": "AUCTION",
+	"daily_budget": 10,
+	"adsets": [{"name": "Ad Set", "optimization_goal": "LINK_CLICKS", "billing_event": "IMPRESSIONS", "targeting": {"age_min": 18, "geo_locations": {"countries": ["US"]}}}],
+	"ads": [{"name": "Ad", "creative": {"title": "Title", "link_url": "https://example.com", "page_id": "1"}}]
+}`
+
+func TestServeListCampaigns(t *testing.T) {
+	ts := newTestServer(&fakeBackend{}, "", false)
+	defer ts.Close()
+
+	resp := doRequest(t, http.MethodGet, ts.URL+"/campaigns", "", "")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	var campaigns []models.Campaign
+	if err := json.NewDecoder(resp.Body).Decode(&campaigns); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if len(campaigns) != 2 {
+		t.Errorf("Expected 2 campaigns, got %d", len(campaigns))
+	}
+}
+
+func TestServeGetCampaign(t *testing.T) {
+	ts := newTestServer(&fakeBackend{}, "", false)
+	defer ts.Close()
+
+	resp := doRequest(t, http.MethodGet, ts.URL+"/campaigns/42", "", "")
+	defer resp.Body.Close()
+
+	var details models.CampaignDetails
+	if err := json.NewDecoder(resp.Body).Decode(&details); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if details.ID != "42" {
+		t.Errorf("Expected campaign 42, got %q", details.ID)
+	}
+}
+
+func TestServeCreateCampaign(t *testing.T) {
+	backend := &fakeBackend{}
+	ts := newTestServer(backend, "", false)
+	defer ts.Close()
+
+	resp := doRequest(t, http.MethodPost, ts.URL+"/campaigns", validCampaignJSON, "")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", resp.StatusCode)
+	}
+	if len(backend.created) != 1 || backend.created[0].Name != "API Campaign" {
+		t.Errorf("Expected campaign to be created, got %v", backend.created)
+	}
+
+	// The response carries the ID of the new campaign
+	var body map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if body["id"] != "901" || body["name"] != "API Campaign" {
+		t.Errorf("Expected the ID and name of the new campaign, got %v", body)
+	}
+}
+
+func TestServeCreateCampaignValidationFails(t *testing.T) {
+	backend := &fakeBackend{}
+	ts := newTestServer(backend, "", false)
+	defer ts.Close()
+
+	resp := doRequest(t, http.MethodPost, ts.URL+"/campaigns", `{"name": "Missing everything"}`, "")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected 422, got %d", resp.StatusCode)
+	}
+	if apiErr := decodeError(t, resp); apiErr.Code != "validation_failed" {
+		t.Errorf("Expected validation_failed, got %q", apiErr.Code)
+	}
+	if len(backend.created) != 0 {
+		t.Errorf("Expected nothing to be created")
+	}
+}
+
+func TestServeDuplicateCampaign(t *testing.T) {
+	backend := &fakeBackend{}
+	ts := newTestServer(backend, "", false)
+	defer ts.Close()
+
+	resp := doRequest(t, http.MethodPost, ts.URL+"/campaigns/42/duplicate", `{"budget_factor": 2}`, "")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", resp.StatusCode)
+	}
+	if len(backend.created) != 1 {
+		t.Fatalf("Expected 1 created campaign, got %d", len(backend.created))
+	}
+
+	var body map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if body["id"] != "901" {
+		t.Errorf("Expected the ID of the copy, got %v", body)
+	}
+
+	dup := backend.created[0]
+	if dup.Name != "Copy of Original" {
+		t.Errorf("Expected name 'Copy of Original', got %q", dup.Name)
+	}
+	if dup.Status != "PAUSED" || dup.AdSets[0].Ads[0].Status != "PAUSED" {
+		t.Errorf("Expected status PAUSED, got %q", dup.Status)
+	}
+	if dup.DailyBudget != 40 {
+		t.Errorf("Expected daily budget 40, got %.2f", dup.DailyBudget)
+	}
+}
+
+func TestServeDuplicateCampaignValidation(t *testing.T) {
+	backend := &fakeBackend{}
+	ts := newTestServer(backend, "", false)
+	defer ts.Close()
+
+	// The status of the copy is checked like POST /campaigns/{id}/status
+	resp := doRequest(t, http.MethodPost, ts.URL+"/campaigns/42/duplicate", `{"status": "DELETED"}`, "")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected 422 for an invalid status, got %d", resp.StatusCode)
+	}
+	if apiErr := decodeError(t, resp); apiErr.Code != "validation_failed" {
+		t.Errorf("Expected validation_failed, got %q", apiErr.Code)
+	}
+
+	// The copy goes through the same validation as a new campaign
+	resp = doRequest(t, http.MethodPost, ts.URL+"/campaigns/43/duplicate", "", "")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for an invalid copy, got %d", resp.StatusCode)
+	}
+
+	if len(backend.created) != 0 {
+		t.Errorf("Expected nothing to be created, got %d campaigns", len(backend.created))
+	}
+}
+
+func TestServeSetStatus(t *testing.T) {
+	backend := &fakeBackend{}
+	ts := newTestServer(backend, "", false)
+	defer ts.Close()
+
+	resp := doRequest(t, http.MethodPost, ts.URL+"/campaigns/42/status", `{"status": "paused"}`, "")
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	if backend.updates["42"].Get("status") != "PAUSED" {
+		t.Errorf("Expected status PAUSED to be sent, got %v", backend.updates["42"])
+	}
+
+	resp = doRequest(t, http.MethodPost, ts.URL+"/campaigns/42/status", `{"status": "DELETED"}`, "")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for invalid status, got %d", resp.StatusCode)
+	}
+}
+
+func TestServeWeeklyReport(t *testing.T) {
+	ts := newTestServer(&fakeBackend{}, "", false)
+	defer ts.Close()
+
+	resp := doRequest(t, http.MethodGet, ts.URL+"/reports/weekly", "", "")
+	defer resp.Body.Close()
+
+	var analysis api.PerformanceAnalysis
+	if err := json.NewDecoder(resp.Body).Decode(&analysis); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if analysis.TotalSpend != 123.45 {
+		t.Errorf("Expected total spend 123.45, got %.2f", analysis.TotalSpend)
+	}
+}
+
+func TestServeAuthAndReadOnly(t *testing.T) {
+	backend := &fakeBackend{}
+	ts := newTestServer(backend, "secret", true)
+	defer ts.Close()
+
+	resp := doRequest(t, http.MethodGet, ts.URL+"/campaigns", "", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", resp.StatusCode)
+	}
+
+	resp = doRequest(t, http.MethodGet, ts.URL+"/campaigns", "", "secret")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 with token, got %d", resp.StatusCode)
+	}
+
+	resp = doRequest(t, http.MethodPost, ts.URL+"/campaigns", validCampaignJSON, "secret")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 in read-only mode, got %d", resp.StatusCode)
+	}
+	if len(backend.created) != 0 {
+		t.Errorf("Expected nothing to be created in read-only mode")
+	}
+}
+
+func TestServePassesRequestContext(t *testing.T) {
+	server := &apiServer{backend: &fakeBackend{}}
+
+	// A client that went away cancels the request context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/campaigns", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadGateway || !strings.Contains(rec.Body.String(), "context canceled") {
+		t.Errorf("Expected the cancelled context to reach the backend, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestServeCreationOutlivesRequest(t *testing.T) {
+	backend := &fakeBackend{}
+	server := &apiServer{backend: backend}
+
+	// A client that disconnects must not stop the creation halfway
+	for _, target := range []string{"/campaigns", "/campaigns/42/duplicate"} {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(validCampaignJSON)).WithContext(ctx)
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Errorf("%s: expected 201 after the client went away, got %d: %s", target, rec.Code, rec.Body.String())
+		}
+	}
+
+	for i, ctx := range backend.createCtxes {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Errorf("Creation %d: expected a timeout", i)
+		}
+	}
+}
+
+func TestIsLoopbackAddress(t *testing.T) {
+	tests := []struct {
+		bind     string
+		expected bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"localhost", true},
+		{"", false},
+		{"0.0.0.0", false},
+		{"192.168.1.10", false},
+	}
+
+	for _, tt := range tests {
+		if got := isLoopbackAddress(tt.bind); got != tt.expected {
+			t.Errorf("isLoopbackAddress(%q) = %v, want %v", tt.bind, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/internal/optimization"
+)
+
+// simulateOptimization replays stored statistics through the optimizer and reports what it would have done.
+// It never changes any campaign.
+func simulateOptimization(cfg *config.Config, args []string) {
+	since := "30d"
+	simConfig := optimization.DefaultSimulationConfig()
+
+	// Handle flags
+	fs := newCommandFlags("optimize simulate [options]")
+	fs.StringVar(&since, "since", since, "Period to replay: a date (YYYY-MM-DD) or days like 30d")
+	fs.Float64Var(&simConfig.MaxCPM, "max-cpm", simConfig.MaxCPM, "Maximum CPM in dollars")
+	parseCommandArgs(fs, args, 0, 0)
+
+	statsManager, clock := newOptimizationStatistics(cfg)
+
+	// Today is incomplete, so the simulation ends yesterday
+	endDate := clock.Today().AddDate(0, 0, -1)
+	startDate, err := parseSinceFlag(since, clock.Today())
+	if err != nil {
+		fmt.Printf("Invalid --since value: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Load extra days so the first simulated day sees a full window
+	history, err := statsManager.GetAllCampaignStatistics(startDate.AddDate(0, 0, 1-simConfig.WindowDays), endDate)
+	if err != nil {
+		fmt.Printf("Error getting campaign statistics: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(history) == 0 {
+		fmt.Println("No statistics found for the specified date range.")
+		fmt.Println("Collect some first with: fbads stats collect (or fbads stats backfill)")
+		return
+	}
+
+	fmt.Printf("Simulating the optimizer from %s to %s (no changes are made)...\n\n",
+		startDate.Format("2006-01-02"),
+		endDate.Format("2006-01-02"))
+
+	result := optimization.SimulateOptimization(history, startDate, endDate, simConfig)
+	renderSimulation(os.Stdout, result, campaignNames(history))
+}
+
+// renderSimulation writes the day-by-day action log and the estimated impact
+func renderSimulation(w io.Writer, result *optimization.SimulationResult, names map[string]string) {
+	if len(result.Actions) == 0 {
+		fmt.Fprintln(w, "The optimizer would not have taken any action.")
+	}
+
+	lastDay := ""
+	for _, action := range result.Actions {
+		day := action.Date.Format("2006-01-02")
+		if day != lastDay {
+			fmt.Fprintf(w, "%s\n", day)
+			lastDay = day
+		}
+
+		label := action.CampaignID
+		if name := names[action.CampaignID]; name != "" {
+			label = name + " (" + action.CampaignID + ")"
+		}
+
+		switch action.Action {
+		case optimization.SimulatedActionPause:
+			fmt.Fprintf(w, "  pause       %s: %s\n", label, action.Reason)
+		case optimization.SimulatedActionAdjustCPM:
+			fmt.Fprintf(w, "  adjust CPM  %s: $%.2f -> $%.2f\n", label, action.OldCPM, action.NewCPM)
+		}
+	}
+
+	impact := result.Impact
+	fmt.Fprintln(w, "\nSummary:")
+	fmt.Fprintf(w, "  CPM adjustments: %d\n", impact.Adjustments)
+
+	if len(impact.PausedCampaigns) == 0 {
+		fmt.Fprintln(w, "  Would not have paused any campaign")
+		return
+	}
+
+	fmt.Fprintf(w, "  Would have paused %d campaigns, saving ~$%.2f", len(impact.PausedCampaigns), impact.SpendAfterPause)
+	if impact.ConversionsAfterPause > 0 {
+		fmt.Fprintf(w, " at their realized CPA of $%.2f (%d conversions)\n", impact.PausedCPA, impact.ConversionsAfterPause)
+	} else {
+		fmt.Fprintln(w, " that brought no conversions")
+	}
+
+	if impact.KeptConversions > 0 {
+		fmt.Fprintf(w, "  Campaigns kept running converted at $%.2f; the saved spend would have bought ~%.1f more conversions there\n",
+			impact.KeptCPA, impact.NetConversions)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/internal/optimization"
+)
+
+func TestRenderSimulation(t *testing.T) {
+	day := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	result := &optimization.SimulationResult{
+		Actions: []optimization.SimulatedAction{
+			{Date: day, CampaignID: "d", Action: optimization.SimulatedActionPause, Reason: "CPC $2.00 is 3.3x the median $0.60"},
+			{Date: day, CampaignID: "a", Action: optimization.SimulatedActionAdjustCPM, OldCPM: 10, NewCPM: 11},
+		},
+		Impact: optimization.SimulationImpact{
+			PausedCampaigns:       []string{"d"},
+			Adjustments:           1,
+			SpendAfterPause:       412,
+			ConversionsAfterPause: 10,
+			PausedCPA:             41.2,
+			KeptConversions:       100,
+			KeptCPA:               5.5,
+			NetConversions:        64.9,
+		},
+	}
+
+	var buf bytes.Buffer
+	renderSimulation(&buf, result, map[string]string{"a": "Spring"})
+	output := buf.String()
+
+	for _, s := range []string{
+		"2024-03-02",
+		"pause       d: CPC $2.00",
+		"adjust CPM  Spring (a): $10.00 -> $11.00",
+		"Would have paused 1 campaigns, saving ~$412.00 at their realized CPA of $41.20",
+	} {
+		if !strings.Contains(output, s) {
+			t.Errorf("Expected output to contain %q, got:\n%s", s, output)
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/audience"
+	internal_campaign "github.com/user/fb-ads/internal/campaign"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// weightEpsilon is how far explicit country weights may be from summing to 1
+const weightEpsilon = 0.01
+
+// countryWeight is the share of the total budget assigned to one country
+type countryWeight struct {
+	Country string  `json:"country"`
+	Weight  float64 `json:"weight"`
+}
+
+// reachEstimator estimates the reach of a targeting spec
+type reachEstimator interface {
+	EstimateReach(targetingSpec map[string]interface{}) (int64, error)
+}
+
+// GeoSplitManifest records the campaigns created by a geo split
+type GeoSplitManifest struct {
+	SourceCampaignID string          `json:"source_campaign_id"`
+	SourceName       string          `json:"source_name"`
+	TotalBudget      float64         `json:"total_budget"`
+	BudgetType       string          `json:"budget_type"`
+	CreatedAt        time.Time       `json:"created_at"`
+	Campaigns        []GeoSplitEntry `json:"campaigns"`
+}
+
+// GeoSplitEntry describes the campaign generated for one country
+type GeoSplitEntry struct {
+	Country    string  `json:"country"`
+	Weight     float64 `json:"weight"`
+	Budget     float64 `json:"budget"`
+	Name       string  `json:"name"`
+	CampaignID string  `json:"campaign_id,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// splitGeo duplicates a campaign once per country with a weighted share of the budget
+func splitGeo(cfg *config.Config, args []string) {
+	var (
+		countriesStr string
+		weightsMode  string
+		totalBudget  float64
+		status       = "PAUSED" // Default to PAUSED for safety
+		manifestPath string
+		dryRun       bool
+		force        bool
+	)
+
+	// Handle flags
+	fs := newCommandFlags("split-geo <campaign_id> --countries US:0.5,GB:0.5 [options]")
+	fs.StringVar(&countriesStr, "countries", "", "Countries with their budget shares, e.g. US:0.5,GB:0.5")
+	fs.StringVar(&weightsMode, "weights", "", "auto weighs the countries by their past results")
+	fs.Float64Var(&totalBudget, "total-budget", 0, "Total budget split between the countries (default: the campaign budget)")
+	fs.StringVar(&status, "status", status, "Status of the new campaigns")
+	fs.StringVar(&manifestPath, "manifest", "", "Where to write the manifest of the split")
+	fs.BoolVar(&dryRun, "dry-run", false, "Show the split without creating campaigns")
+	alias(fs, "d", "dry-run")
+	fs.BoolVar(&force, "force", false, "Split without asking for confirmation")
+	alias(fs, "f", "force")
+	campaignID := parseCommandArgs(fs, args, 1, 1)[0]
+
+	status = mustParseSettableStatus(status)
+
+	if countriesStr == "" {
+		fmt.Println("Missing countries. Use: fbads split-geo <campaign_id> --countries US:0.5,GB:0.3,DE:0.2 [options]")
+		os.Exit(1)
+	}
+
+	autoWeighted := weightsMode == "auto"
+	if weightsMode != "" && !autoWeighted {
+		fmt.Printf("Unknown weights mode %q. Use --weights auto or put weights in --countries\n", weightsMode)
+		os.Exit(1)
+	}
+
+	weights, err := parseCountryWeights(countriesStr, autoWeighted)
+	if err != nil {
+		fmt.Printf("Invalid countries: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create API client
+	client := api.NewClient(authClient, cfg.AccountID)
+
+	fmt.Printf("Fetching campaign details for ID: %s\n", campaignID)
+
+	details, err := client.GetCampaignDetails(campaignID)
+	if err != nil {
+		fmt.Printf("Error fetching campaign details: %v\n", err)
+		os.Exit(1)
+	}
+
+	base := internal_campaign.DuplicateConfig(details, internal_campaign.DuplicateOptions{Name: details.Name, Status: strings.ToUpper(status)})
+
+	// Keep the original ad set and ad names; the country suffix marks the copies
+	for i := range base.AdSets {
+		if i < len(details.AdSets) {
+			base.AdSets[i].Name = details.AdSets[i].Name
+		}
+	}
+	originalAds := internal_campaign.ConfigFromDetails(details).AllAds()
+	for i, ad := range base.AllAds() {
+		if i < len(originalAds) {
+			ad.Name = originalAds[i].Name
+		}
+	}
+
+	// Weight countries by their estimated reach
+	if autoWeighted {
+		if len(base.AdSets) == 0 {
+			fmt.Println("Cannot estimate reach: the campaign has no ad sets")
+			os.Exit(1)
+		}
+
+		fmt.Println("Estimating reach per country...")
+		analyzer := audience.NewAudienceAnalyzer(authClient, cfg.AccountID)
+		weights, err = autoCountryWeights(analyzer, base.AdSets[0].Targeting, weights)
+		if err != nil {
+			fmt.Printf("Error estimating reach: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Default to splitting the current budget
+	budgetType := "daily"
+	if base.DailyBudget == 0 && base.LifetimeBudget > 0 {
+		budgetType = "lifetime"
+	}
+	if totalBudget <= 0 {
+		totalBudget = base.DailyBudget
+		if budgetType == "lifetime" {
+			totalBudget = base.LifetimeBudget
+		}
+	}
+	if totalBudget <= 0 {
+		fmt.Println("Missing total budget. Use --total-budget <amount>")
+		os.Exit(1)
+	}
+
+	configs, err := buildGeoSplitConfigs(base, weights, totalBudget)
+	if err != nil {
+		fmt.Printf("Error generating campaign configurations: %v\n", err)
+		os.Exit(1)
+	}
+
+	manifest := GeoSplitManifest{
+		SourceCampaignID: campaignID,
+		SourceName:       details.Name,
+		TotalBudget:      totalBudget,
+		BudgetType:       budgetType,
+		CreatedAt:        time.Now(),
+	}
+
+	// Print the plan
+	fmt.Printf("\nSplitting '%s' into %d campaigns (total %s budget $%.2f):\n", details.Name, len(configs), budgetType, totalBudget)
+	for i, campaignConfig := range configs {
+		budget := campaignConfig.DailyBudget
+		if budgetType == "lifetime" {
+			budget = campaignConfig.LifetimeBudget
+		}
+
+		fmt.Printf("  %s  weight %.3f  budget $%.2f  %s\n", weights[i].Country, weights[i].Weight, budget, campaignConfig.Name)
+		manifest.Campaigns = append(manifest.Campaigns, GeoSplitEntry{
+			Country: weights[i].Country,
+			Weight:  weights[i].Weight,
+			Budget:  budget,
+			Name:    campaignConfig.Name,
+		})
+
+		if err := validateCampaignConfig(campaignConfig); err != nil {
+			fmt.Printf("Generated configuration for %s is invalid: %v\n", weights[i].Country, err)
+			os.Exit(1)
+		}
+	}
+
+	if dryRun {
+		fmt.Println("\nDry run: No campaigns will be created.")
+		return
+	}
+
+	ensureAccountCapacity(client, cfg, plannedObjects(configs))
+
+	// Ask for confirmation
+	if !force {
+		fmt.Print("\nDo you want to create these campaigns? (y/n): ")
+		var confirm string
+		fmt.Scanln(&confirm)
+
+		if confirm != "y" && confirm != "Y" && confirm != "yes" && confirm != "Yes" {
+			fmt.Println("Split cancelled.")
+			return
+		}
+	}
+
+	// Create campaign creator
+	creator := newCampaignCreator(authClient, cfg)
+
+	failed := 0
+	for i, campaignConfig := range configs {
+		fmt.Printf("\n[%d/%d] Creating %s...\n", i+1, len(configs), campaignConfig.Name)
+
+		createdID, err := creator.CreateFromConfigWithID(campaignConfig)
+		manifest.Campaigns[i].CampaignID = createdID
+		if err != nil {
+			fmt.Printf("  FAILED: %v\n", err)
+			manifest.Campaigns[i].Error = err.Error()
+			failed++
+		}
+	}
+
+	if manifestPath == "" {
+		manifestPath = fmt.Sprintf("split_geo_%s_%s.json", campaignID, time.Now().Format("20060102-150405"))
+	}
+	if err := writeJSONFile(manifestPath, manifest); err != nil {
+		fmt.Printf("Error writing manifest: %v\n", err)
+	} else {
+		fmt.Printf("\nManifest: %s\n", manifestPath)
+	}
+
+	fmt.Printf("Created %d of %d campaigns\n", len(configs)-failed, len(configs))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// parseCountryWeights parses "US:0.5,GB:0.3,DE:0.2". When weights are estimated
+// automatically the list may contain country codes only.
+func parseCountryWeights(value string, autoWeighted bool) ([]countryWeight, error) {
+	var weights []countryWeight
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		country, weightStr, hasWeight := strings.Cut(part, ":")
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if len(country) != 2 {
+			return nil, fmt.Errorf("invalid country code %q", country)
+		}
+		if seen[country] {
+			return nil, fmt.Errorf("country %s listed twice", country)
+		}
+		seen[country] = true
+
+		weight := 0.0
+		if hasWeight {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(weightStr), 64)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("invalid weight %q for %s", weightStr, country)
+			}
+			weight = parsed
+		} else if !autoWeighted {
+			return nil, fmt.Errorf("missing weight for %s (use US:0.5 or --weights auto)", country)
+		}
+
+		weights = append(weights, countryWeight{Country: country, Weight: weight})
+	}
+
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("no countries given")
+	}
+
+	if autoWeighted {
+		return weights, nil
+	}
+
+	return normalizeWeights(weights, weightEpsilon)
+}
+
+// normalizeWeights checks that weights sum to 1 within epsilon and scales them to sum exactly to 1.
+// A negative epsilon skips the check, which is used for weights derived from reach.
+func normalizeWeights(weights []countryWeight, epsilon float64) ([]countryWeight, error) {
+	sum := 0.0
+	for _, w := range weights {
+		sum += w.Weight
+	}
+
+	if sum <= 0 {
+		return nil, fmt.Errorf("weights must be positive")
+	}
+	if epsilon >= 0 && math.Abs(sum-1) > epsilon {
+		return nil, fmt.Errorf("weights sum to %.3f, expected 1 (±%.2f)", sum, epsilon)
+	}
+
+	normalized := make([]countryWeight, len(weights))
+	for i, w := range weights {
+		normalized[i] = countryWeight{Country: w.Country, Weight: w.Weight / sum}
+	}
+
+	return normalized, nil
+}
+
+// autoCountryWeights weights countries by the estimated reach of the targeting restricted to each country
+func autoCountryWeights(estimator reachEstimator, targeting map[string]interface{}, countries []countryWeight) ([]countryWeight, error) {
+	weights := make([]countryWeight, len(countries))
+
+	for i, c := range countries {
+		reach, err := estimator.EstimateReach(targetingForCountry(targeting, c.Country))
+		if err != nil {
+			return nil, fmt.Errorf("error estimating reach for %s: %w", c.Country, err)
+		}
+
+		fmt.Printf("  %s: %s people\n", c.Country, audience.FormatNumberReadable(reach))
+		weights[i] = countryWeight{Country: c.Country, Weight: float64(reach)}
+	}
+
+	return normalizeWeights(weights, -1)
+}
+
+// buildGeoSplitConfigs generates one campaign configuration per country with its share of the budget
+func buildGeoSplitConfigs(base *models.CampaignConfig, weights []countryWeight, totalBudget float64) ([]*models.CampaignConfig, error) {
+	useLifetime := base.DailyBudget == 0 && base.LifetimeBudget > 0
+
+	configs := make([]*models.CampaignConfig, 0, len(weights))
+	for _, w := range weights {
+		campaignConfig, err := copyCampaignConfig(base)
+		if err != nil {
+			return nil, err
+		}
+
+		// Budgets are rounded to cents
+		budget := math.Round(totalBudget*w.Weight*100) / 100
+		if useLifetime {
+			campaignConfig.LifetimeBudget = budget
+		} else {
+			campaignConfig.DailyBudget = budget
+			campaignConfig.LifetimeBudget = 0
+		}
+
+		suffix := " - " + w.Country
+		campaignConfig.Name += suffix
+
+		for i := range campaignConfig.AdSets {
+			campaignConfig.AdSets[i].Name += suffix
+			campaignConfig.AdSets[i].Targeting = targetingForCountry(campaignConfig.AdSets[i].Targeting, w.Country)
+		}
+		for _, ad := range campaignConfig.AllAds() {
+			ad.Name += suffix
+		}
+
+		configs = append(configs, campaignConfig)
+	}
+
+	return configs, nil
+}
+
+// targetingForCountry returns a copy of the targeting restricted to one country
+func targetingForCountry(targeting map[string]interface{}, country string) map[string]interface{} {
+	restricted := make(map[string]interface{}, len(targeting)+1)
+	for key, value := range targeting {
+		restricted[key] = value
+	}
+
+	// Replace all locations (regions, cities, zips) with the country
+	restricted["geo_locations"] = map[string]interface{}{
+		"countries": []string{country},
+	}
+
+	return restricted
+}
+
+// copyCampaignConfig returns a deep copy of a campaign configuration
+func copyCampaignConfig(campaignConfig *models.CampaignConfig) (*models.CampaignConfig, error) {
+	data, err := json.Marshal(campaignConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error copying configuration: %w", err)
+	}
+
+	var copied models.CampaignConfig
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("error copying configuration: %w", err)
+	}
+
+	return &copied, nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// fakeReachEstimator returns a fixed reach per country
+type fakeReachEstimator struct {
+	reach map[string]int64
+	specs []map[string]interface{}
+}
+
+func (f *fakeReachEstimator) EstimateReach(targetingSpec map[string]interface{}) (int64, error) {
+	f.specs = append(f.specs, targetingSpec)
+	country := targetingSpec["geo_locations"].(map[string]interface{})["countries"].([]string)[0]
+	return f.reach[country], nil
+}
+
+func TestParseCountryWeights(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		auto        bool
+		expectError string
+		expected    []countryWeight
+	}{
+		{
+			name:     "exact weights",
+			value:    "US:0.5,GB:0.3,DE:0.2",
+			expected: []countryWeight{{"US", 0.5}, {"GB", 0.3}, {"DE", 0.2}},
+		},
+		{
+			name:     "weights within epsilon are normalized",
+			value:    "us:0.335,gb:0.335,de:0.335",
+			expected: []countryWeight{{"US", 1.0 / 3}, {"GB", 1.0 / 3}, {"DE", 1.0 / 3}},
+		},
+		{
+			name:        "weights not summing to one",
+			value:       "US:0.5,GB:0.3",
+			expectError: "sum to 0.800",
+		},
+		{
+			name:        "missing weight",
+			value:       "US:0.5,GB",
+			expectError: "missing weight for GB",
+		},
+		{
+			name:        "invalid country",
+			value:       "USA:1",
+			expectError: "invalid country code",
+		},
+		{
+			name:        "duplicate country",
+			value:       "US:0.5,US:0.5",
+			expectError: "listed twice",
+		},
+		{
+			name:     "auto weights allow bare countries",
+			value:    "US,GB",
+			auto:     true,
+			expected: []countryWeight{{"US", 0}, {"GB", 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights, err := parseCountryWeights(tt.value, tt.auto)
+
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(weights) != len(tt.expected) {
+				t.Fatalf("Expected %d weights, got %d", len(tt.expected), len(weights))
+			}
+			for i := range weights {
+				if weights[i].Country != tt.expected[i].Country || math.Abs(weights[i].Weight-tt.expected[i].Weight) > 1e-9 {
+					t.Errorf("Weight %d: expected %+v, got %+v", i, tt.expected[i], weights[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAutoCountryWeights(t *testing.T) {
+	estimator := &fakeReachEstimator{reach: map[string]int64{"US": 6000000, "GB": 3000000, "DE": 1000000}}
+	targeting := map[string]interface{}{
+		"age_min":       25,
+		"geo_locations": map[string]interface{}{"countries": []string{"US", "GB", "DE"}},
+	}
+
+	weights, err := autoCountryWeights(estimator, targeting, []countryWeight{{Country: "US"}, {Country: "GB"}, {Country: "DE"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]float64{"US": 0.6, "GB": 0.3, "DE": 0.1}
+	for _, w := range weights {
+		if math.Abs(w.Weight-expected[w.Country]) > 1e-9 {
+			t.Errorf("Expected weight %.2f for %s, got %.4f", expected[w.Country], w.Country, w.Weight)
+		}
+	}
+
+	// Reach is estimated for the original targeting restricted to each country
+	if len(estimator.specs) != 3 || estimator.specs[0]["age_min"] != 25 {
+		t.Errorf("Expected the base targeting to be estimated per country, got %v", estimator.specs)
+	}
+}
+
+func TestBuildGeoSplitConfigs(t *testing.T) {
+	base := &models.CampaignConfig{
+		Name:        "Launch",
+		DailyBudget: 50,
+		AdSets: []models.AdSetConfig{{
+			Name: "Broad",
+			Targeting: map[string]interface{}{
+				"age_min":       18,
+				"geo_locations": map[string]interface{}{"countries": []string{"US"}, "cities": []string{"123"}},
+			},
+		}},
+		Ads: []models.AdConfig{{Name: "Ad"}},
+	}
+	weights := []countryWeight{{"US", 0.5}, {"GB", 0.3}, {"DE", 0.2}}
+
+	configs, err := buildGeoSplitConfigs(base, weights, 300)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(configs) != 3 {
+		t.Fatalf("Expected 3 configs, got %d", len(configs))
+	}
+
+	expectedBudgets := []float64{150, 90, 60}
+	for i, campaignConfig := range configs {
+		country := weights[i].Country
+
+		if campaignConfig.Name != "Launch - "+country {
+			t.Errorf("Expected name 'Launch - %s', got %q", country, campaignConfig.Name)
+		}
+		if campaignConfig.DailyBudget != expectedBudgets[i] {
+			t.Errorf("Expected budget %.2f for %s, got %.2f", expectedBudgets[i], country, campaignConfig.DailyBudget)
+		}
+		if campaignConfig.AdSets[0].Name != "Broad - "+country || campaignConfig.Ads[0].Name != "Ad - "+country {
+			t.Errorf("Expected ad set and ad names suffixed with %s", country)
+		}
+
+		targeting := campaignConfig.AdSets[0].Targeting
+		expectedGeo := map[string]interface{}{"countries": []string{country}}
+		if !reflect.DeepEqual(targeting["geo_locations"], expectedGeo) {
+			t.Errorf("Expected geo_locations %v, got %v", expectedGeo, targeting["geo_locations"])
+		}
+		if targeting["age_min"] != float64(18) {
+			t.Errorf("Expected other targeting to be kept, got %v", targeting)
+		}
+	}
+
+	// The source configuration is left untouched
+	if base.Name != "Launch" || base.AdSets[0].Name != "Broad" {
+		t.Errorf("Expected base config to be unchanged, got %q / %q", base.Name, base.AdSets[0].Name)
+	}
+}
+
+func TestBuildGeoSplitConfigs_LifetimeBudget(t *testing.T) {
+	base := &models.CampaignConfig{Name: "Promo", LifetimeBudget: 1000}
+
+	configs, err := buildGeoSplitConfigs(base, []countryWeight{{"FR", 2.0 / 3}, {"ES", 1.0 / 3}}, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if configs[0].LifetimeBudget != 666.67 || configs[1].LifetimeBudget != 333.33 {
+		t.Errorf("Expected lifetime budgets 666.67 and 333.33, got %.2f and %.2f", configs[0].LifetimeBudget, configs[1].LifetimeBudget)
+	}
+	if configs[0].DailyBudget != 0 {
+		t.Errorf("Expected no daily budget, got %.2f", configs[0].DailyBudget)
+	}
+}
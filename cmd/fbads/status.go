@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// campaignStatus prints the effective delivery tree of a campaign
+func campaignStatus(cfg *config.Config, args []string) {
+	fs := newCommandFlags("status <campaign_id>")
+	campaignID := parseCommandArgs(fs, args, 1, 1)[0]
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create API client
+	client := api.NewClient(authClient, cfg.AccountID)
+
+	tree, err := client.GetDeliveryTree(campaignID)
+	if err != nil {
+		fmt.Printf("Error getting campaign: %v\n", err)
+		os.Exit(1)
+	}
+	api.ExplainDelivery(tree, time.Now())
+
+	renderDeliveryTree(os.Stdout, tree)
+}
+
+// renderDeliveryTree writes each object of a delivery tree with its configured and effective
+// status and the reasons it will not deliver
+func renderDeliveryTree(w io.Writer, tree *api.DeliveryNode) {
+	var render func(node api.DeliveryNode, depth int)
+	render = func(node api.DeliveryNode, depth int) {
+		indent := strings.Repeat("  ", depth)
+
+		state := "delivering"
+		if !node.Delivering() {
+			state = "NOT DELIVERING"
+		}
+		fmt.Fprintf(w, "%s%s %s (%s): %s, effective %s -> %s\n",
+			indent, objectTypeName(node.ObjectType), node.Name, node.ID, node.Status, node.EffectiveStatus, state)
+		for _, reason := range node.Reasons {
+			fmt.Fprintf(w, "%s  - %s\n", indent, reason)
+		}
+		for _, note := range node.Notes {
+			fmt.Fprintf(w, "%s  * %s\n", indent, note)
+		}
+
+		for _, child := range node.Children {
+			render(child, depth+1)
+		}
+	}
+	render(*tree, 0)
+}
+
+// activateCampaign activates a campaign and, with --cascade, its paused ad sets and ads
+func activateCampaign(cfg *config.Config, args []string) {
+	cascade := false
+	force := false
+	fs := newCommandFlags("activate <campaign_id> [options]")
+	fs.BoolVar(&cascade, "cascade", false, "Also activate the paused ad sets and ads")
+	fs.BoolVar(&force, "force", false, "Activate without asking for confirmation")
+	alias(fs, "f", "force")
+	campaignID := parseCommandArgs(fs, args, 1, 1)[0]
+
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create API client
+	client := api.NewClient(authClient, cfg.AccountID)
+
+	tree, err := client.GetDeliveryTree(campaignID)
+	if err != nil {
+		fmt.Printf("Error getting campaign: %v\n", err)
+		os.Exit(1)
+	}
+
+	targets := activationTargets(tree, cascade)
+	if len(targets) == 0 {
+		fmt.Printf("Campaign %s and its ad sets and ads are already active\n", tree.Name)
+		return
+	}
+
+	fmt.Println("This will activate:")
+	for _, node := range targets {
+		fmt.Printf("  %s %s (%s), now %s\n", objectTypeName(node.ObjectType), node.Name, node.ID, node.Status)
+	}
+	if !cascade {
+		if paused := pausedChildren(tree); paused > 0 {
+			fmt.Printf("\nWarning: %d ad sets and ads stay paused and nothing under them will deliver. Use --cascade to activate them too.\n", paused)
+		}
+	}
+
+	if !force {
+		fmt.Print("\nProceed? (y/n): ")
+		var confirm string
+		fmt.Scanln(&confirm)
+
+		if confirm != "y" && confirm != "Y" && confirm != "yes" && confirm != "Yes" {
+			fmt.Println("Activation cancelled.")
+			return
+		}
+	}
+
+	// Targets are ordered parents first
+	failed := 0
+	for _, node := range targets {
+		// Ad sets and ads are updated through the same object endpoint as campaigns
+		if err := setCampaignStatus(client, node.ID, models.CampaignStatusActive); err != nil {
+			fmt.Printf("Error activating %s %s: %v\n", strings.ToLower(objectTypeName(node.ObjectType)), node.ID, err)
+			failed++
+			continue
+		}
+		fmt.Printf("Activated %s %s (%s)\n", strings.ToLower(objectTypeName(node.ObjectType)), node.Name, node.ID)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// activationTargets returns the paused objects to activate, parents first: the campaign and,
+// with cascade, its paused ad sets and ads. Archived and deleted objects are left alone.
+func activationTargets(tree *api.DeliveryNode, cascade bool) []api.DeliveryNode {
+	var targets []api.DeliveryNode
+	if models.CampaignStatus(tree.Status) == models.CampaignStatusPaused {
+		targets = append(targets, *tree)
+	}
+	if !cascade {
+		return targets
+	}
+
+	for _, adSet := range tree.Children {
+		if models.CampaignStatus(adSet.Status) == models.CampaignStatusPaused {
+			targets = append(targets, adSet)
+		}
+	}
+	for _, adSet := range tree.Children {
+		for _, ad := range adSet.Children {
+			if models.CampaignStatus(ad.Status) == models.CampaignStatusPaused {
+				targets = append(targets, ad)
+			}
+		}
+	}
+	return targets
+}
+
+// pausedChildren counts the paused ad sets and ads of a campaign
+func pausedChildren(tree *api.DeliveryNode) int {
+	paused := 0
+	for _, adSet := range tree.Children {
+		if models.CampaignStatus(adSet.Status) == models.CampaignStatusPaused {
+			paused++
+		}
+		for _, ad := range adSet.Children {
+			if models.CampaignStatus(ad.Status) == models.CampaignStatusPaused {
+				paused++
+			}
+		}
+	}
+	return paused
+}
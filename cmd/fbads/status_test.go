@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+)
+
+// pausedCopy is a duplicated campaign whose ad set and one of its ads are paused
+func pausedCopy() *api.DeliveryNode {
+	return &api.DeliveryNode{ObjectType: api.ObjectTypeCampaign, ID: "1", Name: "Copy", Status: "PAUSED", Children: []api.DeliveryNode{
+		{ObjectType: api.ObjectTypeAdSet, ID: "11", Status: "PAUSED", Children: []api.DeliveryNode{
+			{ObjectType: api.ObjectTypeAd, ID: "111", Status: "PAUSED"},
+			{ObjectType: api.ObjectTypeAd, ID: "112", Status: "ARCHIVED"},
+		}},
+		{ObjectType: api.ObjectTypeAdSet, ID: "12", Status: "ACTIVE"},
+	}}
+}
+
+func TestActivationTargets(t *testing.T) {
+	ids := func(nodes []api.DeliveryNode) string {
+		var ids []string
+		for _, node := range nodes {
+			ids = append(ids, node.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	if got := ids(activationTargets(pausedCopy(), false)); got != "1" {
+		t.Errorf("Expected only the campaign without --cascade, got %s", got)
+	}
+	if got := ids(activationTargets(pausedCopy(), true)); got != "1,11,111" {
+		t.Errorf("Expected the paused campaign, ad set and ad parents first, got %s", got)
+	}
+	if paused := pausedChildren(pausedCopy()); paused != 2 {
+		t.Errorf("Expected 2 paused children, got %d", paused)
+	}
+}
+
+func TestRenderDeliveryTree(t *testing.T) {
+	tree := pausedCopy()
+	api.ExplainDelivery(tree, time.Now())
+
+	var buf bytes.Buffer
+	renderDeliveryTree(&buf, tree)
+	output := buf.String()
+
+	for _, want := range []string{
+		"Campaign Copy (1): PAUSED",
+		"  Ad set  (11): PAUSED",
+		"    - paused parent: ad set is PAUSED",
+		"NOT DELIVERING",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+}
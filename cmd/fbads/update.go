@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// clearValue is the flag value that removes a field instead of setting it
+const clearValue = "none"
+
+// spendCapRemovalValue is the spend_cap value the Marketing API documents for removing a spend cap
+const spendCapRemovalValue = "922337203685478"
+
+// clearableFields maps each removable update field to the parameter and value that remove it
+var clearableFields = map[string]struct{ param, value string }{
+	"end_time":  {"stop_time", ""},
+	"spend_cap": {"spend_cap", spendCapRemovalValue},
+	"bid_cap":   {"bid_strategy", string(models.BidStrategyLowestCostWithoutCap)}, // Caps are removed by switching to an uncapped strategy
+}
+
+// updateFields lists the fields accepted by the update command and its JSON file
+var updateFields = []string{"status", "name", "daily_budget", "lifetime_budget", "bid_strategy", "end_time", "spend_cap", "bid_cap"}
+
+// adSetUpdateFields lists the fields accepted when the update command targets an ad set
+var adSetUpdateFields = []string{"status", "name", "bid_amount", "daily_budget", "lifetime_budget", "end_time"}
+
+// updateFlag is a field value given on the command line
+type updateFlag struct{ field, value string }
+
+// updateAdSet updates an ad set from a JSON file and flags; flags override the file
+func updateAdSet(cfg *config.Config, adSetID, jsonFile string, flags []updateFlag) {
+	if len(flags) == 0 && jsonFile == "" {
+		fmt.Println("Error: At least one update parameter must be provided")
+		fmt.Println("Usage: fbads update --adset-id=ADSET_ID [--status=STATUS] [--name=NAME] [--bid-amount=AMOUNT] [--file=FILE]")
+		os.Exit(1)
+	}
+
+	params, err := adSetUpdateParams(jsonFile, flags)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create the Facebook auth object
+	authClient := newAuthClient(cfg)
+
+	// Create API client
+	client := api.NewClient(authClient, cfg.AccountID)
+
+	// Verify the ad set exists before updating
+	fmt.Printf("Verifying ad set %s exists...\n", adSetID)
+	if _, err := client.GetAdSetDetails(adSetID); err != nil {
+		fmt.Printf("Error: Ad set not found or cannot be accessed: %v\n", err)
+		fmt.Println("Please check that the ad set ID is correct and you have permission to access it.")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Updating ad set %s with parameters: %v\n", adSetID, params)
+	if err := client.UpdateAdSet(adSetID, params); err != nil {
+		fmt.Printf("Error updating ad set: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Ad set %s updated successfully\n", adSetID)
+}
+
+// adSetUpdateParams builds the ad set update parameters from a JSON file and flags
+func adSetUpdateParams(jsonFile string, flags []updateFlag) (url.Values, error) {
+	params := url.Values{}
+	if jsonFile != "" {
+		fileParams, err := loadAdSetParamsFromFile(jsonFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading parameters from file: %w", err)
+		}
+		params = fileParams
+	}
+
+	for _, f := range flags {
+		if err := applyAdSetUpdateField(params, f.field, f.value); err != nil {
+			return nil, err
+		}
+	}
+
+	if params.Get("daily_budget") != "" && params.Get("lifetime_budget") != "" {
+		return nil, fmt.Errorf("cannot set both a daily and a lifetime budget")
+	}
+	return params, nil
+}
+
+// applyUpdateField validates a field value and sets the matching API parameters.
+// The value "none" clears the field when it is clearable; other fields, such as the
+// name, take it as their value.
+func applyUpdateField(params url.Values, field, value string) error {
+	if _, ok := clearableFields[field]; ok && strings.EqualFold(value, clearValue) {
+		return clearUpdateField(params, field)
+	}
+
+	err := setUpdateField(params, field, value)
+	if err != nil && strings.EqualFold(value, clearValue) {
+		// "none" that isn't a valid value was meant to clear the field, explain why it can't be
+		return clearUpdateField(params, field)
+	}
+	return err
+}
+
+// setUpdateField validates a field value and sets the matching API parameters
+func setUpdateField(params url.Values, field, value string) error {
+	switch field {
+	case "status":
+		status, err := models.ParseSettableCampaignStatus(value)
+		if err != nil {
+			return err
+		}
+		params.Set("status", string(status))
+
+	case "name":
+		if value == "" {
+			return fmt.Errorf("%s cannot be empty", field)
+		}
+		params.Set(field, value)
+
+	case "bid_strategy":
+		strategy, err := models.ParseBidStrategy(value)
+		if err != nil {
+			return err
+		}
+		params.Set(field, string(strategy))
+
+	case "daily_budget", "lifetime_budget", "spend_cap":
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil || amount <= 0 {
+			return fmt.Errorf("invalid %s %q: must be a positive amount", field, value)
+		}
+		// Convert to cents as required by the API
+		params.Set(field, strconv.FormatInt(models.DollarsToCents(amount), 10))
+
+	case "end_time":
+		endTime, err := parseEndTime(value)
+		if err != nil {
+			return err
+		}
+		params.Set("stop_time", endTime)
+
+	case "bid_amount":
+		return fmt.Errorf("bid amounts are set on ad sets; use --adset-id to update an ad set")
+
+	case "bid_cap":
+		return fmt.Errorf("bid caps are set on ad sets (use --adset-id with --bid-amount); only --bid-cap=none (switch to LOWEST_COST_WITHOUT_CAP) is supported for campaigns")
+
+	default:
+		return fmt.Errorf("unknown update field %q", field)
+	}
+
+	return nil
+}
+
+// applyAdSetUpdateField validates an ad set field value and sets the matching API parameters.
+// Only the end time can be cleared with "none".
+func applyAdSetUpdateField(params url.Values, field, value string) error {
+	valid := false
+	for _, allowed := range adSetUpdateFields {
+		valid = valid || field == allowed
+	}
+	if !valid {
+		return fmt.Errorf("%s cannot be updated on an ad set (valid fields: %s)", field, strings.Join(adSetUpdateFields, ", "))
+	}
+
+	if field == "end_time" && strings.EqualFold(value, clearValue) {
+		return clearAdSetUpdateField(params, field)
+	}
+
+	err := setAdSetUpdateField(params, field, value)
+	if err != nil && strings.EqualFold(value, clearValue) {
+		return clearAdSetUpdateField(params, field)
+	}
+	return err
+}
+
+// clearAdSetUpdateField removes the end time of an ad set, the only field that can be cleared
+func clearAdSetUpdateField(params url.Values, field string) error {
+	if field != "end_time" {
+		return fmt.Errorf("%s cannot be cleared on an ad set (clearable fields: end_time)", field)
+	}
+	params.Set("end_time", "")
+	return nil
+}
+
+// setAdSetUpdateField validates an ad set field value and sets the matching API parameters
+func setAdSetUpdateField(params url.Values, field, value string) error {
+	switch field {
+	case "status", "name", "daily_budget", "lifetime_budget":
+		// Validated the same way as for campaigns
+		return setUpdateField(params, field, value)
+
+	case "bid_amount":
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil || amount <= 0 {
+			return fmt.Errorf("invalid %s %q: must be a positive amount", field, value)
+		}
+		// Convert to cents as required by the API
+		params.Set(field, strconv.FormatInt(models.DollarsToCents(amount), 10))
+
+	case "end_time":
+		endTime, err := parseEndTime(value)
+		if err != nil {
+			return err
+		}
+		// Ad sets end at end_time, campaigns at stop_time
+		params.Set("end_time", endTime)
+	}
+
+	return nil
+}
+
+// clearUpdateField sets the parameters that remove a field, or explains why it cannot be removed
+func clearUpdateField(params url.Values, field string) error {
+	clear, ok := clearableFields[field]
+	if !ok {
+		clearable := make([]string, 0, len(clearableFields))
+		for name := range clearableFields {
+			clearable = append(clearable, name)
+		}
+		sort.Strings(clearable)
+
+		msg := fmt.Sprintf("%s cannot be cleared (clearable fields: %s)", field, strings.Join(clearable, ", "))
+		if field == "daily_budget" || field == "lifetime_budget" {
+			msg += "; a campaign with a campaign budget always needs one, use --switch-budget-type with the other budget to change its type"
+		}
+		return fmt.Errorf("%s", msg)
+	}
+
+	params.Set(clear.param, clear.value)
+	return nil
+}
+
+// parseEndTime parses an end date (YYYY-MM-DD) or timestamp (RFC3339) into the API format
+func parseEndTime(value string) (string, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Format(time.RFC3339), nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return "", fmt.Errorf("invalid end_time %q: use YYYY-MM-DD, an RFC3339 timestamp, or none", value)
+	}
+	return t.Format(time.RFC3339), nil
+}
+
+// loadParamsFromFile loads campaign update parameters from a JSON file.
+// A field set to null is cleared; a missing field is left unchanged.
+func loadParamsFromFile(filePath string) (url.Values, error) {
+	return loadFieldsFromFile(filePath, updateFields, applyUpdateField, clearUpdateField)
+}
+
+// loadAdSetParamsFromFile loads ad set update parameters from a JSON file, like loadParamsFromFile
+func loadAdSetParamsFromFile(filePath string) (url.Values, error) {
+	return loadFieldsFromFile(filePath, adSetUpdateFields, applyAdSetUpdateField, clearAdSetUpdateField)
+}
+
+// loadFieldsFromFile reads the given fields from a JSON file and applies each value.
+// null clears the field, while a string such as "none" is applied as it is.
+func loadFieldsFromFile(filePath string, fields []string, apply func(params url.Values, field, value string) error,
+	clear func(params url.Values, field string) error) (url.Values, error) {
+	params := url.Values{}
+
+	// Read the file
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return params, fmt.Errorf("error reading file: %w", err)
+	}
+
+	// Keep the raw values so null can be told apart from an absent field
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return params, fmt.Errorf("error parsing JSON: %w", err)
+	}
+
+	for _, field := range fields {
+		rawValue, ok := raw[field]
+		if !ok {
+			continue
+		}
+
+		if string(rawValue) == "null" {
+			if err := clear(params, field); err != nil {
+				return params, err
+			}
+			continue
+		}
+
+		// Values may be JSON strings or numbers
+		var value string
+		if err := json.Unmarshal(rawValue, &value); err != nil {
+			var number json.Number
+			if err := json.Unmarshal(rawValue, &number); err != nil {
+				return params, fmt.Errorf("invalid value for %s: %s", field, string(rawValue))
+			}
+			value = number.String()
+		}
+
+		if err := apply(params, field, value); err != nil {
+			return params, err
+		}
+	}
+
+	return params, nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyUpdateField_Clear(t *testing.T) {
+	tests := []struct {
+		field         string
+		expectedParam string
+		expectedValue string
+	}{
+		{"end_time", "stop_time", ""},
+		{"spend_cap", "spend_cap", spendCapRemovalValue},
+		{"bid_cap", "bid_strategy", "LOWEST_COST_WITHOUT_CAP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			params := url.Values{}
+			if err := applyUpdateField(params, tt.field, "none"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			values, ok := params[tt.expectedParam]
+			if !ok || len(values) != 1 || values[0] != tt.expectedValue {
+				t.Errorf("Expected %s=%q, got %v", tt.expectedParam, tt.expectedValue, params)
+			}
+		})
+	}
+}
+
+func TestApplyUpdateField_NotClearable(t *testing.T) {
+	tests := []struct {
+		field       string
+		expectError string
+	}{
+		{"daily_budget", "--switch-budget-type"},
+		{"bid_amount", "clearable fields: bid_cap, end_time, spend_cap"},
+		{"status", "status cannot be cleared"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			params := url.Values{}
+			err := applyUpdateField(params, tt.field, "none")
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+			}
+			if len(params) != 0 {
+				t.Errorf("Expected no parameters, got %v", params)
+			}
+		})
+	}
+}
+
+func TestApplyUpdateField_NoneAsValue(t *testing.T) {
+	// "none" only clears clearable fields; a campaign can be named None
+	params := url.Values{}
+	if err := applyUpdateField(params, "name", "None"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if params.Get("name") != "None" {
+		t.Errorf("Expected the name None, got %v", params)
+	}
+
+	params = url.Values{}
+	if err := applyAdSetUpdateField(params, "name", "none"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if params.Get("name") != "none" {
+		t.Errorf("Expected the ad set name none, got %v", params)
+	}
+
+	// In a file the string is a value, while null asks to clear the field
+	path := filepath.Join(t.TempDir(), "update.json")
+	if err := os.WriteFile(path, []byte(`{"name": "none"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	params, err := loadParamsFromFile(path)
+	if err != nil || params.Get("name") != "none" {
+		t.Errorf("Expected the name none from the file, got %v, %v", params, err)
+	}
+	if err := os.WriteFile(path, []byte(`{"name": null}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadParamsFromFile(path); err == nil || !strings.Contains(err.Error(), "name cannot be cleared") {
+		t.Errorf("Expected a not clearable error for a null name, got %v", err)
+	}
+	if _, err := loadAdSetParamsFromFile(path); err == nil || !strings.Contains(err.Error(), "name cannot be cleared on an ad set") {
+		t.Errorf("Expected a not clearable error for a null ad set name, got %v", err)
+	}
+}
+
+func TestApplyUpdateField_Set(t *testing.T) {
+	params := url.Values{}
+	for field, value := range map[string]string{
+		"end_time":     "2024-05-01",
+		"spend_cap":    "500",
+		"daily_budget": "75.5",
+		"status":       "paused",
+	} {
+		if err := applyUpdateField(params, field, value); err != nil {
+			t.Fatalf("Unexpected error for %s: %v", field, err)
+		}
+	}
+
+	expected := url.Values{
+		"stop_time":    {"2024-05-01T00:00:00Z"},
+		"spend_cap":    {"50000"},
+		"daily_budget": {"7550"},
+		"status":       {"PAUSED"},
+	}
+	if params.Encode() != expected.Encode() {
+		t.Errorf("Expected %v, got %v", expected, params)
+	}
+
+	if err := applyUpdateField(url.Values{}, "bid_cap", "2.50"); err == nil {
+		t.Errorf("Expected setting a campaign bid cap to be rejected")
+	}
+}
+
+func TestApplyUpdateField_InvalidValuesListChoices(t *testing.T) {
+	tests := []struct {
+		field, value, wantInMsg string
+	}{
+		{"status", "DELETED", "ACTIVE, PAUSED, ARCHIVED"},
+		{"status", "running", "ACTIVE, PAUSED, ARCHIVED"},
+		{"bid_strategy", "LOWEST_COST", "LOWEST_COST_WITHOUT_CAP"},
+	}
+
+	for _, tt := range tests {
+		err := applyUpdateField(url.Values{}, tt.field, tt.value)
+		if err == nil || !strings.Contains(err.Error(), tt.wantInMsg) {
+			t.Errorf("Expected %s=%s to be rejected listing %s, got %v", tt.field, tt.value, tt.wantInMsg, err)
+		}
+	}
+
+	params := url.Values{}
+	if err := applyUpdateField(params, "bid_strategy", "cost_cap"); err != nil || params.Get("bid_strategy") != "COST_CAP" {
+		t.Errorf("Expected the bid strategy to be normalized, got %v (%v)", params, err)
+	}
+}
+
+func TestLoadParamsFromFile_NullClears(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "update.json")
+	content := `{"name": "Renamed", "end_time": null, "spend_cap": null, "lifetime_budget": 1000}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	params, err := loadParamsFromFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := url.Values{
+		"name":            {"Renamed"},
+		"stop_time":       {""},
+		"spend_cap":       {spendCapRemovalValue},
+		"lifetime_budget": {"100000"},
+	}
+	if params.Encode() != expected.Encode() {
+		t.Errorf("Expected %v, got %v", expected, params)
+	}
+
+	// Absent fields are left alone
+	if _, ok := params["bid_strategy"]; ok {
+		t.Errorf("Expected absent bid_strategy to be left unchanged")
+	}
+
+	// null on a field that cannot be cleared is an error
+	if err := os.WriteFile(path, []byte(`{"daily_budget": null}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadParamsFromFile(path); err == nil || !strings.Contains(err.Error(), "daily_budget cannot be cleared") {
+		t.Errorf("Expected a not clearable error, got %v", err)
+	}
+}
+
+func TestApplyAdSetUpdateField(t *testing.T) {
+	tests := []struct {
+		field         string
+		value         string
+		expectedParam string
+		expectedValue string
+		expectError   string
+	}{
+		{field: "bid_amount", value: "4.50", expectedParam: "bid_amount", expectedValue: "450"},
+		{field: "status", value: "paused", expectedParam: "status", expectedValue: "PAUSED"},
+		{field: "name", value: "Broad", expectedParam: "name", expectedValue: "Broad"},
+		{field: "end_time", value: "2024-12-31", expectedParam: "end_time", expectedValue: "2024-12-31T00:00:00Z"},
+		{field: "end_time", value: "none", expectedParam: "end_time", expectedValue: ""},
+		{field: "status", value: "DELETED", expectError: "invalid status"},
+		{field: "bid_amount", value: "-1", expectError: "must be a positive amount"},
+		{field: "bid_amount", value: "none", expectError: "bid_amount cannot be cleared on an ad set"},
+		{field: "spend_cap", value: "100", expectError: "spend_cap cannot be updated on an ad set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field+"="+tt.value, func(t *testing.T) {
+			params := url.Values{}
+			err := applyAdSetUpdateField(params, tt.field, tt.value)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			values, ok := params[tt.expectedParam]
+			if !ok || len(values) != 1 || values[0] != tt.expectedValue {
+				t.Errorf("Expected %s=%q, got %v", tt.expectedParam, tt.expectedValue, params)
+			}
+		})
+	}
+}
+
+func TestAdSetUpdateParams_FlagsOverrideFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "adset.json")
+	content := `{"bid_amount": 3, "status": "ACTIVE", "end_time": null, "spend_cap": 100}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	params, err := adSetUpdateParams(path, []updateFlag{{"bid_amount", "4.5"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Campaign-only fields in the file are ignored like any other unknown field
+	expected := url.Values{
+		"bid_amount": {"450"},
+		"status":     {"ACTIVE"},
+		"end_time":   {""},
+	}
+	if params.Encode() != expected.Encode() {
+		t.Errorf("Expected %v, got %v", expected, params)
+	}
+}
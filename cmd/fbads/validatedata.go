@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/internal/optimization"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// validateOptimizationData reports which campaigns have enough stored statistics for optimization
+func validateOptimizationData(cfg *config.Config, args []string) {
+	since := "7d"
+
+	// Handle flags
+	fs := newCommandFlags("optimize validate-data [options]")
+	fs.StringVar(&since, "since", since, "Period to check: a date (YYYY-MM-DD) or days like 7d")
+	parseCommandArgs(fs, args, 0, 0)
+
+	statsManager, clock := newOptimizationStatistics(cfg)
+
+	endDate := clock.Today()
+	startDate, err := parseSinceFlag(since, endDate)
+	if err != nil {
+		fmt.Printf("Invalid --since value: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Validating stored statistics from %s to %s...\n\n",
+		startDate.Format("2006-01-02"),
+		endDate.Format("2006-01-02"))
+
+	allStats, err := statsManager.GetAllCampaignStatistics(startDate, endDate)
+	if err != nil {
+		fmt.Printf("Error getting campaign statistics: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(allStats) == 0 {
+		fmt.Println("No statistics found for the specified date range.")
+		fmt.Println("Collect some first with: fbads stats collect (or fbads stats backfill)")
+		return
+	}
+
+	results := optimization.NewPerformanceValidator().ValidateCampaignsData(allStats)
+	renderValidationReport(os.Stdout, results, campaignNames(allStats))
+}
+
+// newOptimizationStatistics opens the statistics store used by the optimize commands
+func newOptimizationStatistics(cfg *config.Config) (*api.StatisticsManager, *api.AccountClock) {
+	// Create auth client
+	authClient := newAuthClient(cfg)
+
+	// Create metrics collector
+	metricsCollector := api.NewMetricsCollector(authClient, cfg.AccountID)
+
+	return api.NewStatisticsManager(metricsCollector, api.StorageTypeFile, cfg.StatisticsDir()), metricsCollector.Clock()
+}
+
+// parseSinceFlag parses a relative ("7d") or absolute ("2024-01-01") start date
+func parseSinceFlag(value string, today time.Time) (time.Time, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil || days <= 0 {
+			return time.Time{}, fmt.Errorf("%q is not a number of days like 7d", value)
+		}
+		return today.AddDate(0, 0, -days), nil
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", value, today.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a number of days like 7d nor a date like 2024-01-01", value)
+	}
+	return date, nil
+}
+
+// campaignNames returns the latest known name of each campaign in stored statistics
+func campaignNames(allStats map[string][]models.CampaignPerformance) map[string]string {
+	names := make(map[string]string, len(allStats))
+	for campaignID, performances := range allStats {
+		for _, perf := range performances {
+			if perf.Name != "" {
+				names[campaignID] = perf.Name
+			}
+		}
+	}
+	return names
+}
+
+// sortedValidationResults returns validation results ordered by campaign ID
+func sortedValidationResults(results map[string]optimization.ValidationResult) []optimization.ValidationResult {
+	sorted := make([]optimization.ValidationResult, 0, len(results))
+	for _, result := range results {
+		sorted = append(sorted, result)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].CampaignID < sorted[j].CampaignID
+	})
+	return sorted
+}
+
+// renderValidationReport writes a per-campaign table of validation results with totals
+func renderValidationReport(w io.Writer, results map[string]optimization.ValidationResult, names map[string]string) {
+	fmt.Fprintf(w, "%-24s | %-6s | %-11s | %-8s | %-10s | %-8s | %-6s | %-10s | %s\n",
+		"CAMPAIGN", "POINTS", "IMPRESSIONS", "CLICKS", "SPEND", "RUNTIME", "VALID", "WAIT", "REASONS")
+	fmt.Fprintf(w, "%s-+-%s-+-%s-+-%s-+-%s-+-%s-+-%s-+-%s-+-%s\n",
+		strings.Repeat("-", 24),
+		strings.Repeat("-", 6),
+		strings.Repeat("-", 11),
+		strings.Repeat("-", 8),
+		strings.Repeat("-", 10),
+		strings.Repeat("-", 8),
+		strings.Repeat("-", 6),
+		strings.Repeat("-", 10),
+		strings.Repeat("-", 30))
+
+	validCount := 0
+	for _, result := range sortedValidationResults(results) {
+		label := result.CampaignID
+		if name := names[result.CampaignID]; name != "" {
+			label = name + " (" + result.CampaignID + ")"
+		}
+
+		valid := "no"
+		if result.IsValid {
+			valid = "yes"
+			validCount++
+		}
+
+		wait := "-"
+		if result.RecommendWait {
+			wait = formatDuration(result.WaitTimeNeeded)
+		}
+
+		fmt.Fprintf(w, "%-24s | %-6d | %-11d | %-8d | $%-9.2f | %-8s | %-6s | %-10s | %s\n",
+			truncateString(label, 24),
+			result.DataPoints,
+			result.Metrics.TotalImpressions,
+			result.Metrics.TotalClicks,
+			result.Metrics.TotalSpend,
+			formatDuration(result.RunningTime),
+			valid,
+			wait,
+			strings.Join(result.Reasons, "; "))
+	}
+
+	fmt.Fprintf(w, "\n%d of %d campaigns have enough data\n", validCount, len(results))
+}
+
+// renderSkippedSection lists the campaigns an optimization run skips for insufficient data
+func renderSkippedSection(w io.Writer, results map[string]optimization.ValidationResult) {
+	var skipped []optimization.ValidationResult
+	for _, result := range sortedValidationResults(results) {
+		if !result.IsValid {
+			skipped = append(skipped, result)
+		}
+	}
+
+	if len(skipped) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\nSkipped: insufficient data (%d campaigns)\n", len(skipped))
+	for _, result := range skipped {
+		line := fmt.Sprintf("  - %s: %s", result.CampaignID, strings.Join(result.Reasons, "; "))
+		if result.RecommendWait {
+			line += fmt.Sprintf(" (wait ~%s)", formatDuration(result.WaitTimeNeeded))
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/internal/optimization"
+)
+
+func mixedValidationResults() map[string]optimization.ValidationResult {
+	return map[string]optimization.ValidationResult{
+		"100": {
+			IsValid:     true,
+			CampaignID:  "100",
+			DataPoints:  7,
+			RunningTime: 6 * 24 * time.Hour,
+			Metrics:     optimization.ValidationMetrics{TotalImpressions: 52000, TotalClicks: 610, TotalSpend: 240.5},
+		},
+		"200": {
+			IsValid:        false,
+			CampaignID:     "200",
+			DataPoints:     1,
+			Reasons:        []string{"Insufficient impressions: 300 (minimum required: 1000)", "Insufficient data points: 1 (minimum required: 2)"},
+			Metrics:        optimization.ValidationMetrics{TotalImpressions: 300, TotalClicks: 4, TotalSpend: 2},
+			RecommendWait:  true,
+			WaitTimeNeeded: 26*time.Hour + 30*time.Minute,
+		},
+		"300": {
+			IsValid:        false,
+			CampaignID:     "300",
+			DataPoints:     3,
+			RunningTime:    46 * time.Hour,
+			Reasons:        []string{"Insufficient clicks: 8 (minimum required: 10)"},
+			Metrics:        optimization.ValidationMetrics{TotalImpressions: 4000, TotalClicks: 8, TotalSpend: 12},
+			RecommendWait:  true,
+			WaitTimeNeeded: 2*time.Hour + 15*time.Minute,
+		},
+	}
+}
+
+func TestRenderValidationReport(t *testing.T) {
+	var buf bytes.Buffer
+	renderValidationReport(&buf, mixedValidationResults(), map[string]string{"100": "Spring Sale"})
+	output := buf.String()
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("Expected header, separator, 3 rows, blank and totals; got %d lines:\n%s", len(lines), output)
+	}
+
+	// Rows are ordered by campaign ID
+	if !strings.HasPrefix(lines[2], "Spring Sale (100)") ||
+		!strings.HasPrefix(lines[3], "200") || !strings.HasPrefix(lines[4], "300") {
+		t.Errorf("Unexpected row order:\n%s", output)
+	}
+
+	expected := []string{
+		"| 7      | 52000       | 610      | $240.50    | 6d 0h    | yes    | -",
+		"| no     | 1d 2h",
+		"Insufficient impressions: 300 (minimum required: 1000); Insufficient data points: 1",
+		"| 1d 22h   | no     | 2h 15m",
+		"1 of 3 campaigns have enough data",
+	}
+	for _, text := range expected {
+		if !strings.Contains(output, text) {
+			t.Errorf("Expected output to contain %q, got:\n%s", text, output)
+		}
+	}
+}
+
+func TestRenderSkippedSection(t *testing.T) {
+	var buf bytes.Buffer
+	renderSkippedSection(&buf, mixedValidationResults())
+	output := buf.String()
+
+	if !strings.Contains(output, "Skipped: insufficient data (2 campaigns)") {
+		t.Errorf("Expected skipped header, got:\n%s", output)
+	}
+	if !strings.Contains(output, "  - 300: Insufficient clicks: 8 (minimum required: 10) (wait ~2h 15m)") {
+		t.Errorf("Expected campaign 300 with wait time, got:\n%s", output)
+	}
+	if strings.Contains(output, "- 100:") {
+		t.Errorf("Valid campaign should not be listed as skipped:\n%s", output)
+	}
+
+	// Nothing is printed when every campaign has enough data
+	buf.Reset()
+	renderSkippedSection(&buf, map[string]optimization.ValidationResult{"1": {IsValid: true, CampaignID: "1"}})
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output, got %q", buf.String())
+	}
+}
+
+func TestParseSinceFlag(t *testing.T) {
+	today := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]string{
+		"7d":         "2024-05-13",
+		"30d":        "2024-04-20",
+		"2024-01-01": "2024-01-01",
+	}
+	for value, expected := range tests {
+		got, err := parseSinceFlag(value, today)
+		if err != nil {
+			t.Errorf("parseSinceFlag(%q) returned error: %v", value, err)
+			continue
+		}
+		if got.Format("2006-01-02") != expected {
+			t.Errorf("parseSinceFlag(%q) = %s, want %s", value, got.Format("2006-01-02"), expected)
+		}
+	}
+
+	for _, value := range []string{"d", "-3d", "last week", "2024-13-01"} {
+		if _, err := parseSinceFlag(value, today); err == nil {
+			t.Errorf("Expected error for %q", value)
+		}
+	}
+}
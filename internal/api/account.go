@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// accountFields are the ad account fields read by GetAccountInfo
+const accountFields = "id,account_id,name,account_status,currency,timezone_name"
+
+// accountStatuses names the values of an ad account's account_status
+var accountStatuses = map[int]string{
+	1:   "ACTIVE",
+	2:   "DISABLED",
+	3:   "UNSETTLED",
+	7:   "PENDING_RISK_REVIEW",
+	8:   "PENDING_SETTLEMENT",
+	9:   "IN_GRACE_PERIOD",
+	100: "PENDING_CLOSURE",
+	101: "CLOSED",
+	201: "ANY_ACTIVE",
+	202: "ANY_CLOSED",
+}
+
+// AccountInfo describes an ad account
+type AccountInfo struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Status   int    `json:"account_status"`
+	Currency string `json:"currency"`
+	Timezone string `json:"timezone_name"`
+}
+
+// StatusName returns the name of the account status, such as ACTIVE or DISABLED
+func (a AccountInfo) StatusName() string {
+	if name, ok := accountStatuses[a.Status]; ok {
+		return name
+	}
+	return "STATUS_" + strconv.Itoa(a.Status)
+}
+
+// IsActive reports whether ads of the account can run
+func (a AccountInfo) IsActive() bool {
+	return a.Status == 1
+}
+
+// GetAccountInfo retrieves the name, status, currency and timezone of the ad account
+func (c *Client) GetAccountInfo() (*AccountInfo, error) {
+	return c.GetAccountInfoContext(context.Background())
+}
+
+// GetAccountInfoContext retrieves the ad account like GetAccountInfo
+func (c *Client) GetAccountInfoContext(ctx context.Context) (*AccountInfo, error) {
+	object, err := c.getObjectContext(ctx, fmt.Sprintf("act_%s", c.accountID), accountFields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountInfo{
+		ID:       firstNonEmpty(getString(object, "account_id"), c.accountID),
+		Name:     getString(object, "name"),
+		Status:   int(getFloat(object, "account_status")),
+		Currency: getString(object, "currency"),
+		Timezone: getString(object, "timezone_name"),
+	}, nil
+}
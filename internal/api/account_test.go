@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestGetAccountInfo(t *testing.T) {
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			if !strings.HasSuffix(req.URL.Path, "/act_123") || req.URL.Query().Get("fields") != accountFields {
+				t.Errorf("Unexpected request: %s", req.URL)
+			}
+			return jsonResponse(`{"id":"act_123","account_id":"123","name":"Shop","account_status":3,"currency":"EUR","timezone_name":"Europe/Berlin"}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	account, err := client.GetAccountInfo()
+	if err != nil {
+		t.Fatalf("GetAccountInfo failed: %v", err)
+	}
+	expected := AccountInfo{ID: "123", Name: "Shop", Status: 3, Currency: "EUR", Timezone: "Europe/Berlin"}
+	if *account != expected {
+		t.Errorf("GetAccountInfo() = %+v, want %+v", *account, expected)
+	}
+	if account.StatusName() != "UNSETTLED" || account.IsActive() {
+		t.Errorf("Expected an unsettled, inactive account, got %s", account.StatusName())
+	}
+	if name := (AccountInfo{Status: 42}).StatusName(); name != "STATUS_42" {
+		t.Errorf("Expected an unknown status to be named by its value, got %s", name)
+	}
+}
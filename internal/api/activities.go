@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Activity is one change recorded in the ad account activity log
+type Activity struct {
+	Time       time.Time `json:"time"`
+	EventType  string    `json:"event_type"`
+	ActorName  string    `json:"actor_name"`
+	ObjectID   string    `json:"object_id"`
+	ObjectName string    `json:"object_name"`
+	ObjectType string    `json:"object_type,omitempty"`
+	OldValue   string    `json:"old_value,omitempty"`
+	NewValue   string    `json:"new_value,omitempty"`
+	Source     string    `json:"source"`
+}
+
+// ActivitySourceAccount tags activities read from the ad account activity log
+const ActivitySourceAccount = "account"
+
+// GetActivities returns the changes made in the account between since and until, oldest first
+func (c *Client) GetActivities(since, until time.Time) ([]Activity, error) {
+	return c.GetActivitiesContext(context.Background(), since, until)
+}
+
+// GetActivitiesContext returns the changes made in the account between since and until,
+// oldest first. Cancelling the context stops the pagination.
+func (c *Client) GetActivitiesContext(ctx context.Context, since, until time.Time) ([]Activity, error) {
+	params := url.Values{}
+	params.Set("fields", "event_type,event_time,actor_name,object_id,object_name,object_type,extra_data")
+	params.Set("since", strconv.FormatInt(since.Unix(), 10))
+	params.Set("until", strconv.FormatInt(until.Unix(), 10))
+	params.Set("limit", "100")
+
+	endpoint := fmt.Sprintf("act_%s/activities", c.accountID)
+
+	req, err := c.auth.GetAuthenticatedRequestContext(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	var activities []Activity
+	for req != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Data   []map[string]interface{} `json:"data"`
+			Paging struct {
+				Next string `json:"next"`
+			} `json:"paging"`
+		}
+		if err := doJSON(c.doWithRetry, req, &page); err != nil {
+			return nil, fmt.Errorf("error fetching activities: %w", err)
+		}
+
+		for _, row := range page.Data {
+			activities = append(activities, parseActivity(row))
+		}
+
+		req = nil
+		if page.Paging.Next != "" {
+			if req, err = http.NewRequestWithContext(ctx, "GET", page.Paging.Next, nil); err != nil {
+				return nil, fmt.Errorf("error creating request: %w", err)
+			}
+		}
+	}
+
+	sortActivities(activities)
+	return activities, nil
+}
+
+// parseActivity converts one row of the activities edge.
+// extra_data is a JSON string; changes carry their old and new values in it.
+func parseActivity(row map[string]interface{}) Activity {
+	activity := Activity{
+		EventType:  getString(row, "event_type"),
+		ActorName:  getString(row, "actor_name"),
+		ObjectID:   getString(row, "object_id"),
+		ObjectName: getString(row, "object_name"),
+		ObjectType: getString(row, "object_type"),
+		Source:     ActivitySourceAccount,
+	}
+
+	if t, err := parseTime(getString(row, "event_time")); err == nil {
+		activity.Time = t
+	}
+
+	var extra map[string]interface{}
+	if err := json.Unmarshal([]byte(getString(row, "extra_data")), &extra); err == nil {
+		activity.OldValue = activityValue(extra["old_value"], "old_value")
+		activity.NewValue = activityValue(extra["new_value"], "new_value")
+	}
+
+	return activity
+}
+
+// activityValue formats an old or new value from extra_data.
+// Budgets are nested objects like {"old_value": 1000, "currency": "USD"}.
+func activityValue(value interface{}, key string) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}:
+		inner := activityValue(v[key], key)
+		if currency, ok := v["currency"].(string); ok && inner != "" {
+			return inner + " " + currency
+		}
+		return inner
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// sortActivities orders activities chronologically
+func sortActivities(activities []Activity) {
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].Time.Before(activities[j].Time)
+	})
+}
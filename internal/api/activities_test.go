@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+// activitiesFixture is two pages of the activities edge, newest first as the API returns them
+var activitiesFixture = []string{
+	`{"data":[
+		{"event_type":"update_campaign_run_status","event_time":"2024-03-05T09:30:00+0000","actor_name":"Jane Smith",
+		 "object_id":"111","object_name":"Spring Sale","object_type":"CAMPAIGN",
+		 "extra_data":"{\"old_value\":\"Active\",\"new_value\":\"Paused\"}"},
+		{"event_type":"update_campaign_budget","event_time":"2024-03-04T16:00:00+0000","actor_name":"John Doe",
+		 "object_id":"111","object_name":"Spring Sale","object_type":"CAMPAIGN",
+		 "extra_data":"{\"old_value\":{\"old_value\":5000,\"currency\":\"USD\"},\"new_value\":{\"new_value\":7500,\"currency\":\"USD\"},\"type\":\"payment_amount\"}"}
+	],"paging":{"next":"https://graph.facebook.com/v22.0/act_feed/activities?after=page2"}}`,
+	`{"data":[
+		{"event_type":"create_ad","event_time":"2024-03-02T08:15:00+0000","actor_name":"Jane Smith",
+		 "object_id":"333","object_name":"Spring Sale - Ad","object_type":"AD","extra_data":""},
+		{"event_type":"update_ad_set_bid_amount","event_time":"2024-03-03T11:45:00+0000","actor_name":"John Doe",
+		 "object_id":"222","object_name":"Spring Sale - Ad Set","object_type":"AD_SET",
+		 "extra_data":"{\"old_value\":150,\"new_value\":200}"}
+	],"paging":{}}`,
+}
+
+func TestGetActivities(t *testing.T) {
+	requests := 0
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			requests++
+			if requests == 1 {
+				if !strings.HasSuffix(req.URL.Path, "/act_feed/activities") {
+					t.Errorf("Unexpected request path: %s", req.URL.Path)
+				}
+				if req.URL.Query().Get("since") != "1709251200" {
+					t.Errorf("Expected since as a unix timestamp, got %q", req.URL.Query().Get("since"))
+				}
+				return jsonResponse(activitiesFixture[0])
+			}
+			if req.URL.Query().Get("after") != "page2" {
+				t.Errorf("Expected the next page to be requested, got %s", req.URL)
+			}
+			return jsonResponse(activitiesFixture[1])
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "feed",
+	}
+
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	activities, err := client.GetActivities(since, since.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("GetActivities failed: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 page requests, got %d", requests)
+	}
+
+	expected := []struct {
+		eventType string
+		objectID  string
+		oldValue  string
+		newValue  string
+	}{
+		{"create_ad", "333", "", ""},
+		{"update_ad_set_bid_amount", "222", "150", "200"},
+		{"update_campaign_budget", "111", "5000 USD", "7500 USD"},
+		{"update_campaign_run_status", "111", "Active", "Paused"},
+	}
+
+	if len(activities) != len(expected) {
+		t.Fatalf("Expected %d activities, got %d", len(expected), len(activities))
+	}
+
+	for i, e := range expected {
+		a := activities[i]
+		if a.EventType != e.eventType || a.ObjectID != e.objectID || a.OldValue != e.oldValue || a.NewValue != e.newValue {
+			t.Errorf("Activity %d: expected %+v, got %+v", i, e, a)
+		}
+		if a.Source != ActivitySourceAccount {
+			t.Errorf("Activity %d: expected source %q, got %q", i, ActivitySourceAccount, a.Source)
+		}
+		if i > 0 && a.Time.Before(activities[i-1].Time) {
+			t.Errorf("Expected activities in chronological order, got %s before %s", activities[i-1].Time, a.Time)
+		}
+	}
+
+	if activities[2].ActorName != "John Doe" || activities[2].ObjectName != "Spring Sale" {
+		t.Errorf("Expected actor and object names, got %+v", activities[2])
+	}
+}
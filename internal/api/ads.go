@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// adFields are the ad fields read into models.AdDetails, with the creative fields that
+// export and duplicate need
+const adFields = "id,name,status,adset_id,campaign_id,creative{id,name,title,body,image_url,image_hash,link_url,call_to_action_type," +
+	objectStorySpecField + "}"
+
+// creativeFields are the creative fields listed by GetAdCreatives
+const creativeFields = "id,name,title,body," + objectStorySpecField
+
+// objectStorySpecField reads the page, link, carousel and video settings of a creative
+const objectStorySpecField = "object_story_spec{page_id,link_data{link,call_to_action,page_welcome_message,image_hash," +
+	"child_attachments{link,name,description,image_hash,call_to_action}},video_data{video_id,image_hash,image_url,call_to_action}}"
+
+// GetAccountAds retrieves a page of the ads of the whole account
+func (c *Client) GetAccountAds(limit int, after string) (*models.AdResponse, error) {
+	return c.GetAccountAdsContext(context.Background(), limit, after)
+}
+
+// GetAccountAdsContext retrieves a page of the ads of the whole account. Pass the after
+// cursor of a page to get the next one; a page without a next link is the last.
+func (c *Client) GetAccountAdsContext(ctx context.Context, limit int, after string) (*models.AdResponse, error) {
+	page, err := c.getPage(ctx, "act_"+c.accountID+"/ads", adFields, limit, after)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching ads: %w", err)
+	}
+
+	response := &models.AdResponse{Paging: page.Paging}
+	for _, row := range page.Data {
+		response.Data = append(response.Data, parseAd(row))
+	}
+	return response, nil
+}
+
+// GetAdCreatives retrieves a page of the ad creatives of the account
+func (c *Client) GetAdCreatives(limit int, after string) (*models.CreativeResponse, error) {
+	return c.GetAdCreativesContext(context.Background(), limit, after)
+}
+
+// GetAdCreativesContext retrieves a page of the ad creatives of the account, which ads can
+// reuse by ID. Pass the after cursor of a page to get the next one.
+func (c *Client) GetAdCreativesContext(ctx context.Context, limit int, after string) (*models.CreativeResponse, error) {
+	page, err := c.getPage(ctx, "act_"+c.accountID+"/adcreatives", creativeFields, limit, after)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching ad creatives: %w", err)
+	}
+
+	response := &models.CreativeResponse{Paging: page.Paging}
+	for _, row := range page.Data {
+		response.Data = append(response.Data, parseCreative(row))
+	}
+	return response, nil
+}
+
+// parseAd converts an ad object with the adFields
+func parseAd(adMap map[string]interface{}) models.AdDetails {
+	ad := models.AdDetails{
+		ID:         getString(adMap, "id"),
+		Name:       getString(adMap, "name"),
+		Status:     getString(adMap, "status"),
+		AdSetID:    getString(adMap, "adset_id"),
+		CampaignID: getString(adMap, "campaign_id"),
+	}
+
+	// Extract creative if available
+	if creative, ok := adMap["creative"].(map[string]interface{}); ok {
+		ad.Creative = parseCreative(creative)
+	}
+
+	return ad
+}
+
+// parseCreative converts a creative object with the fields of the object story spec
+func parseCreative(creative map[string]interface{}) models.CreativeDetails {
+	creativeDetails := models.CreativeDetails{
+		ID:               getString(creative, "id"),
+		Name:             getString(creative, "name"),
+		Title:            getString(creative, "title"),
+		Body:             getString(creative, "body"),
+		ImageURL:         getString(creative, "image_url"),
+		ImageHash:        getString(creative, "image_hash"),
+		LinkURL:          getString(creative, "link_url"),
+		CallToActionType: getString(creative, "call_to_action_type"),
+	}
+
+	// Extract page_id from object_story_spec if available
+	if objectStorySpec, ok := creative["object_story_spec"].(map[string]interface{}); ok {
+		creativeDetails.PageID = getString(objectStorySpec, "page_id")
+
+		// Keep click-to-message settings so export/duplicate preserve them
+		if linkData, ok := objectStorySpec["link_data"].(map[string]interface{}); ok {
+			creativeDetails.PageWelcomeMessage = getString(linkData, "page_welcome_message")
+			if creativeDetails.ImageHash == "" {
+				creativeDetails.ImageHash = getString(linkData, "image_hash")
+			}
+			creativeDetails.LinkURL = firstNonEmpty(creativeDetails.LinkURL, getString(linkData, "link"))
+			creativeDetails.Cards = parseCarouselCards(linkData)
+			if cta, ok := linkData["call_to_action"].(map[string]interface{}); ok {
+				if value, ok := cta["value"].(map[string]interface{}); ok {
+					creativeDetails.WhatsAppNumber = getString(value, "whatsapp_number")
+				}
+			}
+		}
+
+		// Video ads keep the thumbnail, and the link in their call to action
+		if videoData, ok := objectStorySpec["video_data"].(map[string]interface{}); ok {
+			creativeDetails.VideoID = getString(videoData, "video_id")
+			creativeDetails.ImageHash = firstNonEmpty(creativeDetails.ImageHash, getString(videoData, "image_hash"))
+			creativeDetails.ImageURL = firstNonEmpty(creativeDetails.ImageURL, getString(videoData, "image_url"))
+			if cta, ok := videoData["call_to_action"].(map[string]interface{}); ok {
+				creativeDetails.CallToActionType = firstNonEmpty(creativeDetails.CallToActionType, getString(cta, "type"))
+				if value, ok := cta["value"].(map[string]interface{}); ok {
+					creativeDetails.LinkURL = firstNonEmpty(creativeDetails.LinkURL, getString(value, "link"))
+				}
+			}
+		}
+	}
+
+	return creativeDetails
+}
+
+// parseCarouselCards returns the cards of a carousel ad's link_data, nil for other ads
+func parseCarouselCards(linkData map[string]interface{}) []models.CreativeCard {
+	attachments, ok := linkData["child_attachments"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var cards []models.CreativeCard
+	for _, item := range attachments {
+		attachment, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		card := models.CreativeCard{
+			Title:     getString(attachment, "name"),
+			Body:      getString(attachment, "description"),
+			LinkURL:   getString(attachment, "link"),
+			ImageHash: getString(attachment, "image_hash"),
+		}
+		if cta, ok := attachment["call_to_action"].(map[string]interface{}); ok {
+			card.CallToAction = getString(cta, "type")
+		}
+		cards = append(cards, card)
+	}
+	return cards
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestGetAccountAds(t *testing.T) {
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			if req.URL.Path != "/v22.0/act_123/ads" {
+				t.Errorf("Expected the account's ads, got %s", req.URL.Path)
+			}
+			if req.URL.Query().Get("after") == "" {
+				return jsonResponse(`{"data":[{"id":"201","name":"Hero","status":"ACTIVE","adset_id":"111","campaign_id":"100",` +
+					`"creative":{"id":"301","object_story_spec":{"page_id":"555","link_data":{"link":"https://example.com","image_hash":"abc"}}}}],` +
+					`"paging":{"cursors":{"after":"b"},"next":"https://graph.facebook.com/v22.0/act_123/ads?after=b"}}`)
+			}
+			return jsonResponse(`{"data":[{"id":"202","name":"Video","status":"PAUSED","adset_id":"112","campaign_id":"100"}],"paging":{"cursors":{"before":"b"}}}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	first, err := client.GetAccountAds(25, "")
+	if err != nil {
+		t.Fatalf("GetAccountAds failed: %v", err)
+	}
+	if len(first.Data) != 1 || first.Paging.Next == "" {
+		t.Fatalf("Expected one ad and a next page, got %+v", first)
+	}
+	ad := first.Data[0]
+	if ad.CampaignID != "100" || ad.AdSetID != "111" || ad.Creative.PageID != "555" || ad.Creative.LinkURL != "https://example.com" {
+		t.Errorf("Unexpected ad: %+v", ad)
+	}
+
+	second, err := client.GetAccountAds(25, first.Paging.Cursors.After)
+	if err != nil {
+		t.Fatalf("GetAccountAds failed: %v", err)
+	}
+	if len(second.Data) != 1 || second.Data[0].ID != "202" || second.Paging.Next != "" {
+		t.Errorf("Expected the last page with the second ad, got %+v", second)
+	}
+}
+
+func TestGetAdCreatives(t *testing.T) {
+	var query url.Values
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			if req.URL.Path != "/v22.0/act_123/adcreatives" {
+				t.Errorf("Expected the account's creatives, got %s", req.URL.Path)
+			}
+			query = req.URL.Query()
+			return jsonResponse(`{"data":[{"id":"301","name":"Spring hero","title":"Spring Sale","body":"Gear for every trail",` +
+				`"object_story_spec":{"page_id":"555","link_data":{"link":"https://example.com","image_hash":"abc"}}}],` +
+				`"paging":{"cursors":{"after":"c"},"next":"https://graph.facebook.com/v22.0/act_123/adcreatives?after=c"}}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	response, err := client.GetAdCreatives(10, "b")
+	if err != nil {
+		t.Fatalf("GetAdCreatives failed: %v", err)
+	}
+	if query.Get("limit") != "10" || query.Get("after") != "b" || !strings.HasPrefix(query.Get("fields"), "id,name,title,body,object_story_spec{") {
+		t.Errorf("Unexpected query: %v", query)
+	}
+	if len(response.Data) != 1 || response.Paging.Cursors.After != "c" {
+		t.Fatalf("Expected one creative and a next page, got %+v", response)
+	}
+	creative := response.Data[0]
+	if creative.ID != "301" || creative.Title != "Spring Sale" || creative.PageID != "555" || creative.ImageHash != "abc" || creative.LinkURL != "https://example.com" {
+		t.Errorf("Unexpected creative: %+v", creative)
+	}
+}
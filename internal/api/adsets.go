@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/user/fb-ads/pkg/models"
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+// adSetFields are the ad set fields read into models.AdSetDetails
+const adSetFields = "id,name,status,campaign_id,targeting,optimization_goal,billing_event,bid_amount,bid_strategy," +
+	"daily_budget,lifetime_budget,start_time,end_time,destination_type"
+
+// GetAdSets returns every ad set of a campaign
+func (c *Client) GetAdSets(campaignID string) ([]models.AdSetDetails, error) {
+	return c.GetAdSetsContext(context.Background(), campaignID)
+}
+
+// GetAdSetsContext returns every ad set of a campaign, following the paging links.
+// Cancelling the context stops the pagination.
+func (c *Client) GetAdSetsContext(ctx context.Context, campaignID string) ([]models.AdSetDetails, error) {
+	params := url.Values{}
+	params.Set("fields", adSetFields)
+	params.Set("limit", "100")
+
+	req, err := c.auth.GetAuthenticatedRequestContext(ctx, campaignID+"/adsets", params)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	var adSets []models.AdSetDetails
+	for req != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Data   []map[string]interface{} `json:"data"`
+			Paging struct {
+				Next string `json:"next"`
+			} `json:"paging"`
+		}
+		if err := doJSON(c.doWithRetry, req, &page); err != nil {
+			return nil, fmt.Errorf("error fetching ad sets: %w", err)
+		}
+
+		for _, row := range page.Data {
+			adSets = append(adSets, parseAdSet(row))
+		}
+
+		req = nil
+		if page.Paging.Next != "" {
+			if req, err = http.NewRequestWithContext(ctx, "GET", page.Paging.Next, nil); err != nil {
+				return nil, fmt.Errorf("error creating request: %w", err)
+			}
+		}
+	}
+
+	return adSets, nil
+}
+
+// GetAccountAdSets retrieves a page of the ad sets of the whole account
+func (c *Client) GetAccountAdSets(limit int, after string) (*models.AdSetResponse, error) {
+	return c.GetAccountAdSetsContext(context.Background(), limit, after)
+}
+
+// GetAccountAdSetsContext retrieves a page of the ad sets of the whole account. Pass the
+// after cursor of a page to get the next one; a page without a next link is the last.
+func (c *Client) GetAccountAdSetsContext(ctx context.Context, limit int, after string) (*models.AdSetResponse, error) {
+	page, err := c.getPage(ctx, "act_"+c.accountID+"/adsets", adSetFields, limit, after)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching ad sets: %w", err)
+	}
+
+	response := &models.AdSetResponse{Paging: page.Paging}
+	for _, row := range page.Data {
+		response.Data = append(response.Data, parseAdSet(row))
+	}
+	return response, nil
+}
+
+// parseAdSet converts an ad set object with the adSetFields. Unparseable dates are
+// left zero; ad set details carry no warnings.
+func parseAdSet(object map[string]interface{}) models.AdSetDetails {
+	adSet := models.AdSetDetails{
+		ID:               getString(object, "id"),
+		CampaignID:       getString(object, "campaign_id"),
+		Name:             getString(object, "name"),
+		Status:           getString(object, "status"),
+		OptimizationGoal: getString(object, "optimization_goal"),
+		BillingEvent:     getString(object, "billing_event"),
+		BidAmount:        getFloat(object, "bid_amount"),
+		BidStrategy:      getString(object, "bid_strategy"),
+		DailyBudget:      getFloat(object, "daily_budget"),
+		LifetimeBudget:   getFloat(object, "lifetime_budget"),
+		DestinationType:  getString(object, "destination_type"),
+	}
+
+	var warnings []string
+	adSet.StartTime = parseTimeField("start_time", getString(object, "start_time"), &warnings)
+	adSet.EndTime = parseTimeField("end_time", getString(object, "end_time"), &warnings)
+
+	if targeting, ok := object["targeting"].(map[string]interface{}); ok {
+		adSet.Targeting = targeting
+	}
+
+	return adSet
+}
+
+// CollectAdSetMetrics collects the performance of the ad sets of a campaign, by ad set ID
+func (m *MetricsCollector) CollectAdSetMetrics(campaignID string, timeRange TimeRange) (map[string]utils.CampaignPerformance, error) {
+	return m.CollectAdSetMetricsContext(context.Background(), campaignID, timeRange)
+}
+
+// CollectAdSetMetricsContext collects the performance of the ad sets of a campaign, by ad
+// set ID. Ad sets without delivery in the time range are left out.
+func (m *MetricsCollector) CollectAdSetMetricsContext(ctx context.Context, campaignID string, timeRange TimeRange) (map[string]utils.CampaignPerformance, error) {
+	params := url.Values{}
+	params.Set("level", "adset")
+	params.Set("fields", "campaign_id,adset_id,adset_name,spend,impressions,clicks,actions,action_values")
+	params.Set("limit", "500")
+
+	timeRangeJSON, _ := json.Marshal(timeRange)
+	params.Set("time_range", string(timeRangeJSON))
+
+	filteringJSON, _ := json.Marshal([]Filter{{Field: "campaign.id", Operator: "EQUAL", Value: campaignID}})
+	params.Set("filtering", string(filteringJSON))
+
+	req, err := m.auth.GetAuthenticatedRequestContext(ctx, fmt.Sprintf("act_%s/insights", m.accountID), params)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	performances := make(map[string]utils.CampaignPerformance)
+	err = m.fetchInsightsPages(req, func(rows []map[string]interface{}) error {
+		for _, row := range rows {
+			perf := parsePerformance(row)
+			perf.Name = getString(row, "adset_name")
+			performances[getString(row, "adset_id")] = perf
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return performances, nil
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestGetAdSets_FollowsPaging(t *testing.T) {
+	var paths []string
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			paths = append(paths, req.URL.Path)
+			if req.URL.Query().Get("after") == "" {
+				return jsonResponse(`{"data":[{"id":"111","campaign_id":"100","name":"Broad","status":"ACTIVE","daily_budget":"2500","bid_strategy":"COST_CAP","bid_amount":"450"}],` +
+					`"paging":{"next":"https://graph.facebook.com/v22.0/100/adsets?after=abc"}}`)
+			}
+			return jsonResponse(`{"data":[{"id":"112","campaign_id":"100","name":"Lookalike","status":"PAUSED","end_time":"never"}]}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	adSets, err := client.GetAdSets("100")
+	if err != nil {
+		t.Fatalf("GetAdSets failed: %v", err)
+	}
+
+	if len(adSets) != 2 || adSets[1].ID != "112" || !strings.HasSuffix(paths[0], "/100/adsets") {
+		t.Fatalf("Expected both pages of the campaign's ad sets, got %+v from %v", adSets, paths)
+	}
+	first := adSets[0]
+	if first.DailyBudget != 2500 || first.BidStrategy != "COST_CAP" || first.BidAmount != 450 {
+		t.Errorf("Expected budget and bid fields, got %+v", first)
+	}
+	if !adSets[1].EndTime.IsZero() {
+		t.Errorf("Expected an unparseable end time to be left zero, got %s", adSets[1].EndTime)
+	}
+}
+
+func TestCollectAdSetMetrics(t *testing.T) {
+	collector := newFixtureCollector(t, "adsetmetrics", func(req *http.Request) *http.Response {
+		q := req.URL.Query()
+		if q.Get("level") != "adset" || q.Get("filtering") != `[{"field":"campaign.id","operator":"EQUAL","value":"100"}]` {
+			t.Errorf("Expected ad set insights of the campaign, got %s", req.URL.RawQuery)
+		}
+		return jsonResponse(`{"data":[{"campaign_id":"100","adset_id":"111","adset_name":"Broad","spend":"30","impressions":"3000","clicks":"60"}]}`)
+	})
+
+	metrics, err := collector.CollectAdSetMetrics("100", TimeRange{Since: "2024-01-01", Until: "2024-01-31"})
+	if err != nil {
+		t.Fatalf("CollectAdSetMetrics failed: %v", err)
+	}
+
+	broad, ok := metrics["111"]
+	if !ok || len(metrics) != 1 {
+		t.Fatalf("Expected metrics keyed by ad set ID, got %+v", metrics)
+	}
+	if broad.Name != "Broad" || broad.Spend != 30 || broad.CPC != 0.5 {
+		t.Errorf("Unexpected ad set metrics: %+v", broad)
+	}
+}
+
+func TestGetAccountAdSets(t *testing.T) {
+	var queries []string
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			queries = append(queries, req.URL.Path+" limit="+req.URL.Query().Get("limit")+" after="+req.URL.Query().Get("after"))
+			return jsonResponse(`{"data":[{"id":"111","campaign_id":"100","name":"Broad","status":"ACTIVE","daily_budget":"2500"}],` +
+				`"paging":{"cursors":{"before":"a","after":"b"},"next":"https://graph.facebook.com/v22.0/act_123/adsets?after=b"}}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	page, err := client.GetAccountAdSets(50, "a")
+	if err != nil {
+		t.Fatalf("GetAccountAdSets failed: %v", err)
+	}
+
+	if len(queries) != 1 || queries[0] != "/v22.0/act_123/adsets limit=50 after=a" {
+		t.Errorf("Expected one request for the account's ad sets, got %v", queries)
+	}
+	if len(page.Data) != 1 || page.Data[0].CampaignID != "100" || page.Data[0].DailyBudget != 2500 {
+		t.Errorf("Unexpected ad sets: %+v", page.Data)
+	}
+	if page.Paging.Cursors.After != "b" || page.Paging.Next == "" {
+		t.Errorf("Expected the cursor of the next page, got %+v", page.Paging)
+	}
+}
@@ -1,14 +1,19 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
 	"os"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/user/fb-ads/internal/audience"
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/models"
 	"github.com/user/fb-ads/pkg/utils"
 )
 
@@ -26,6 +31,10 @@ type PerformanceAnalysis struct {
 	AnalysisDate     time.Time                   `json:"analysis_date"`
 	Recommendations  []string                    `json:"recommendations"`
 	TopAudiences     []AudiencePerformance       `json:"top_audiences,omitempty"`
+	Period           TimeRange                   `json:"period"`
+	ROASMethod       string                      `json:"roas_method,omitempty"` // How the conversion values behind ROAS were obtained
+
+	campaigns []utils.CampaignPerformance // Every analyzed campaign, for the CSV report
 }
 
 // AudiencePerformance represents performance metrics for a specific audience segment
@@ -40,6 +49,8 @@ type AudiencePerformance struct {
 type PerformanceAnalyzer struct {
 	metricsCollector *MetricsCollector
 	audienceAnalyzer *audience.AudienceAnalyzer
+	thresholds       config.RecommendationThresholds
+	conversionValues models.ConversionValues
 }
 
 // NewPerformanceAnalyzer creates a new performance analyzer
@@ -47,31 +58,38 @@ func NewPerformanceAnalyzer(metricsCollector *MetricsCollector, audienceAnalyzer
 	return &PerformanceAnalyzer{
 		metricsCollector: metricsCollector,
 		audienceAnalyzer: audienceAnalyzer,
+		thresholds:       config.DefaultRecommendationThresholds(),
+		conversionValues: models.ConversionValues{Default: models.DefaultConversionValue},
 	}
 }
 
+// SetConversionValues sets the values of a conversion used for ROAS when Facebook doesn't
+// report conversion values
+func (p *PerformanceAnalyzer) SetConversionValues(values models.ConversionValues) {
+	p.conversionValues = values
+}
+
+// SetRecommendationThresholds sets the thresholds used to generate recommendations
+func (p *PerformanceAnalyzer) SetRecommendationThresholds(thresholds config.RecommendationThresholds) {
+	p.thresholds = thresholds
+}
+
 // AnalyzeCampaignPerformance analyzes campaign performance
 func (p *PerformanceAnalyzer) AnalyzeCampaignPerformance(timeRange TimeRange) (*PerformanceAnalysis, error) {
+	return p.AnalyzeCampaignPerformanceContext(context.Background(), timeRange)
+}
+
+// AnalyzeCampaignPerformanceContext analyzes campaign performance, stopping when the context is cancelled
+func (p *PerformanceAnalyzer) AnalyzeCampaignPerformanceContext(ctx context.Context, timeRange TimeRange) (*PerformanceAnalysis, error) {
 	// Create insights request
 	request := InsightsRequest{
 		Level:     "campaign",
 		TimeRange: timeRange,
-		Fields: []string{
-			"campaign_id",
-			"campaign_name",
-			"spend",
-			"impressions",
-			"clicks",
-			"actions",
-			"cpm",
-			"cpc",
-			"ctr",
-			"cost_per_action_type",
-		},
+		Fields:    PerformanceInsightsFields,
 	}
 
 	// Collect metrics
-	performances, err := p.metricsCollector.CollectCampaignMetrics(request)
+	performances, err := p.metricsCollector.CollectCampaignMetricsContext(ctx, request)
 	if err != nil {
 		return nil, fmt.Errorf("error collecting metrics: %w", err)
 	}
@@ -80,9 +98,17 @@ func (p *PerformanceAnalyzer) AnalyzeCampaignPerformance(timeRange TimeRange) (*
 		return nil, fmt.Errorf("no campaign data found for the specified time range")
 	}
 
+	// Reported conversion values are preferred, conversions are valued otherwise
+	for i := range performances {
+		p.conversionValues.Apply(&performances[i])
+	}
+
 	// Calculate summary statistics
 	analysis := &PerformanceAnalysis{
 		AnalysisDate: time.Now(),
+		Period:       timeRange,
+		ROASMethod:   p.conversionValues.Method(performances),
+		campaigns:    append([]utils.CampaignPerformance(nil), performances...),
 	}
 
 	var totalCPA float64
@@ -124,7 +150,8 @@ func (p *PerformanceAnalyzer) AnalyzeCampaignPerformance(timeRange TimeRange) (*
 	// Get top 5 campaigns by ROAS
 	if len(performances) > 0 {
 		numTop := int(math.Min(5, float64(len(performances))))
-		analysis.TopCampaigns = performances[:numTop]
+		// Copied, the worst campaigns are sorted in place below
+		analysis.TopCampaigns = append([]utils.CampaignPerformance(nil), performances[:numTop]...)
 	}
 
 	// Sort campaigns by CPA (descending) for worst campaigns
@@ -195,6 +222,14 @@ func (p *PerformanceAnalyzer) GenerateReport(analysis *PerformanceAnalysis, file
 	return nil
 }
 
+// GenerateReportHTML generates a standalone HTML report: the summary metrics, the top
+// and worst campaigns, the daily trend of the period and the recommendations, styled
+// like the dashboard. The page needs no other files, so it can be opened in a browser
+// or attached to an email.
+func (p *PerformanceAnalyzer) GenerateReportHTML(analysis *PerformanceAnalysis, filePath string) error {
+	return writeReportHTML(analysis, p.metricsCollector, filePath)
+}
+
 // sanitizeAnalysis replaces any NaN or Inf values with 0 to prevent JSON marshaling errors
 func sanitizeAnalysis(analysis *PerformanceAnalysis) {
 	// Replace NaN or Inf in main metrics
@@ -360,42 +395,35 @@ func (p *PerformanceAnalyzer) generateRecommendations(performances []utils.Campa
 		recommendations = append(recommendations, "No conversions recorded. Consider revising your campaign targeting or creative elements.")
 	}
 
+	thresholds := p.thresholds
+
 	// Check for campaigns with high spend but no conversions
-	var highSpendNoConv []string
 	for _, perf := range performances {
-		if perf.Conversions == 0 && perf.Spend > 100 {
-			highSpendNoConv = append(highSpendNoConv, perf.Name)
+		if perf.Conversions == 0 && perf.Spend > thresholds.HighSpendNoConversions {
+			recommendations = append(recommendations, fmt.Sprintf(
+				"Consider pausing '%s': spend $%.2f > threshold $%.2f with no conversions",
+				perf.Name, perf.Spend, thresholds.HighSpendNoConversions))
 		}
 	}
 
-	if len(highSpendNoConv) > 0 {
-		recommendations = append(recommendations, fmt.Sprintf("Consider pausing these campaigns with high spend but no conversions: %v", highSpendNoConv))
-	}
-
 	// Check for campaigns with very low CTR
-	var lowCTRCampaigns []string
 	for _, perf := range performances {
-		if perf.CTR < 0.5 && perf.Impressions > 1000 {
-			lowCTRCampaigns = append(lowCTRCampaigns, perf.Name)
+		if perf.CTR < thresholds.LowCTRPercent && perf.Impressions > thresholds.LowCTRMinImpressions {
+			recommendations = append(recommendations, fmt.Sprintf(
+				"Improve ad creatives for '%s': CTR %.2f%% < threshold %.2f%% over %s impressions",
+				perf.Name, perf.CTR, thresholds.LowCTRPercent, formatThousands(perf.Impressions)))
 		}
 	}
 
-	if len(lowCTRCampaigns) > 0 {
-		recommendations = append(recommendations, fmt.Sprintf("Improve ad creatives for these campaigns with low CTR: %v", lowCTRCampaigns))
-	}
-
 	// Check for high-performing campaigns that could benefit from more budget
-	var highROASCampaigns []string
 	for _, perf := range performances {
-		if perf.ROAS > 3.0 && perf.Conversions > 5 {
-			highROASCampaigns = append(highROASCampaigns, perf.Name)
+		if perf.ROAS > thresholds.HighROAS && perf.Conversions > thresholds.HighROASMinConversions {
+			recommendations = append(recommendations, fmt.Sprintf(
+				"Consider increasing budget for '%s': ROAS %.2f > threshold %.2f with %d conversions (minimum %d)",
+				perf.Name, perf.ROAS, thresholds.HighROAS, perf.Conversions, thresholds.HighROASMinConversions))
 		}
 	}
 
-	if len(highROASCampaigns) > 0 {
-		recommendations = append(recommendations, fmt.Sprintf("Consider increasing budget for these high ROAS campaigns: %v", highROASCampaigns))
-	}
-
 	// Add audience-specific recommendations if available
 	if len(analysis.TopAudiences) > 0 {
 		topAudience := analysis.TopAudiences[0]
@@ -410,3 +438,31 @@ func (p *PerformanceAnalyzer) generateRecommendations(performances []utils.Campa
 
 	return recommendations
 }
+
+// formatThousands formats an integer with thousands separators (4200 -> "4,200")
+func formatThousands(n int) string {
+	str := strconv.Itoa(n)
+	if n < 0 {
+		return "-" + formatThousands(-n)
+	}
+
+	var result strings.Builder
+	for i, digit := range str {
+		if i > 0 && (len(str)-i)%3 == 0 {
+			result.WriteByte(',')
+		}
+		result.WriteRune(digit)
+	}
+	return result.String()
+}
+
+// DescribeRecommendationThresholds explains the active recommendation rules
+func DescribeRecommendationThresholds(thresholds config.RecommendationThresholds) []string {
+	return []string{
+		fmt.Sprintf("Pause: spend > $%.2f with no conversions", thresholds.HighSpendNoConversions),
+		fmt.Sprintf("Improve creatives: CTR < %.2f%% over more than %s impressions",
+			thresholds.LowCTRPercent, formatThousands(thresholds.LowCTRMinImpressions)),
+		fmt.Sprintf("Increase budget: ROAS > %.2f with more than %d conversions",
+			thresholds.HighROAS, thresholds.HighROASMinConversions),
+	}
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+// findRecommendation returns the first recommendation containing the given text
+func findRecommendation(recommendations []string, text string) string {
+	for _, recommendation := range recommendations {
+		if strings.Contains(recommendation, text) {
+			return recommendation
+		}
+	}
+	return ""
+}
+
+func TestGenerateRecommendations_Boundaries(t *testing.T) {
+	thresholds := config.RecommendationThresholds{
+		HighSpendNoConversions: 200,
+		LowCTRPercent:          1.0,
+		LowCTRMinImpressions:   5000,
+		HighROAS:               2.5,
+		HighROASMinConversions: 10,
+	}
+
+	tests := []struct {
+		name        string
+		performance utils.CampaignPerformance
+		rule        string
+		expectFire  bool
+	}{
+		{
+			name:        "spend at threshold",
+			performance: utils.CampaignPerformance{Name: "A", Spend: 200, CTR: 2, Impressions: 100},
+			rule:        "Consider pausing",
+			expectFire:  false,
+		},
+		{
+			name:        "spend above threshold",
+			performance: utils.CampaignPerformance{Name: "A", Spend: 200.01, CTR: 2, Impressions: 100},
+			rule:        "Consider pausing",
+			expectFire:  true,
+		},
+		{
+			name:        "ctr at threshold",
+			performance: utils.CampaignPerformance{Name: "B", CTR: 1.0, Impressions: 6000, Conversions: 1},
+			rule:        "Improve ad creatives",
+			expectFire:  false,
+		},
+		{
+			name:        "ctr below threshold",
+			performance: utils.CampaignPerformance{Name: "B", CTR: 0.99, Impressions: 6000, Conversions: 1},
+			rule:        "Improve ad creatives",
+			expectFire:  true,
+		},
+		{
+			name:        "impressions at minimum",
+			performance: utils.CampaignPerformance{Name: "B", CTR: 0.5, Impressions: 5000, Conversions: 1},
+			rule:        "Improve ad creatives",
+			expectFire:  false,
+		},
+		{
+			name:        "roas at threshold",
+			performance: utils.CampaignPerformance{Name: "C", ROAS: 2.5, Conversions: 11, CTR: 2},
+			rule:        "Consider increasing budget",
+			expectFire:  false,
+		},
+		{
+			name:        "roas above threshold",
+			performance: utils.CampaignPerformance{Name: "C", ROAS: 2.51, Conversions: 11, CTR: 2},
+			rule:        "Consider increasing budget",
+			expectFire:  true,
+		},
+		{
+			name:        "conversions at minimum",
+			performance: utils.CampaignPerformance{Name: "C", ROAS: 5, Conversions: 10, CTR: 2},
+			rule:        "Consider increasing budget",
+			expectFire:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := &PerformanceAnalyzer{}
+			analyzer.SetRecommendationThresholds(thresholds)
+
+			performances := []utils.CampaignPerformance{tt.performance}
+			analysis := &PerformanceAnalysis{TotalConversions: tt.performance.Conversions}
+
+			recommendations := analyzer.generateRecommendations(performances, analysis)
+			fired := findRecommendation(recommendations, tt.rule) != ""
+			if fired != tt.expectFire {
+				t.Errorf("Rule %q fired = %v, want %v (recommendations: %v)", tt.rule, fired, tt.expectFire, recommendations)
+			}
+		})
+	}
+}
+
+func TestGenerateRecommendations_Explanations(t *testing.T) {
+	analyzer := &PerformanceAnalyzer{}
+	analyzer.SetRecommendationThresholds(config.DefaultRecommendationThresholds())
+
+	performances := []utils.CampaignPerformance{
+		{Name: "Low CTR", CTR: 0.31, Impressions: 4200, Conversions: 1},
+		{Name: "Big Spender", Spend: 150, CTR: 2},
+		{Name: "Winner", ROAS: 4.2, Conversions: 12, CTR: 2},
+	}
+	analysis := &PerformanceAnalysis{TotalConversions: 13}
+
+	recommendations := analyzer.generateRecommendations(performances, analysis)
+
+	expected := []string{
+		"CTR 0.31% < threshold 0.50% over 4,200 impressions",
+		"spend $150.00 > threshold $100.00 with no conversions",
+		"ROAS 4.20 > threshold 3.00 with 12 conversions (minimum 5)",
+	}
+
+	for _, text := range expected {
+		if findRecommendation(recommendations, text) == "" {
+			t.Errorf("Expected a recommendation containing %q, got %v", text, recommendations)
+		}
+	}
+}
+
+func TestFormatThousands(t *testing.T) {
+	tests := map[int]string{
+		0:       "0",
+		999:     "999",
+		1000:    "1,000",
+		4200:    "4,200",
+		1234567: "1,234,567",
+		-5000:   "-5,000",
+	}
+
+	for input, expected := range tests {
+		if result := formatThousands(input); result != expected {
+			t.Errorf("formatThousands(%d) = %q, want %q", input, result, expected)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+const (
+	// DefaultBackfillChunkDays is the number of days requested from the API at once
+	DefaultBackfillChunkDays = 30
+	// asyncInsightsMinDays is the range length from which insights are requested as an async job
+	asyncInsightsMinDays = 14
+)
+
+// asyncPollInterval is the delay between status checks of an async insights job
+var asyncPollInterval = 5 * time.Second
+
+// asyncMaxWait is how long an async insights job may run before it is given up
+var asyncMaxWait = 30 * time.Minute
+
+// BackfillOptions controls how historical statistics are imported
+type BackfillOptions struct {
+	Overwrite bool                             // Re-import days that are already stored
+	ChunkDays int                              // Days per API request, defaults to DefaultBackfillChunkDays
+	Progress  func(day time.Time, records int) // Called after each stored day
+}
+
+// BackfillResult summarizes a backfill run
+type BackfillResult struct {
+	DaysImported int `json:"days_imported"`
+	DaysSkipped  int `json:"days_skipped"`
+	Records      int `json:"records"`
+}
+
+// Backfill imports daily campaign insights between since and until (inclusive).
+// Every day is stored separately, so an interrupted run can be resumed:
+// days that are already present are skipped unless Overwrite is set.
+func (s *StatisticsManager) Backfill(since, until time.Time, options BackfillOptions) (*BackfillResult, error) {
+	return s.BackfillContext(context.Background(), since, until, options)
+}
+
+// BackfillContext imports daily campaign insights like Backfill. Cancelling ctx stops
+// the import; days stored before that are kept.
+func (s *StatisticsManager) BackfillContext(ctx context.Context, since, until time.Time, options BackfillOptions) (*BackfillResult, error) {
+	if options.ChunkDays <= 0 {
+		options.ChunkDays = DefaultBackfillChunkDays
+	}
+
+	result := &BackfillResult{}
+
+	// Find the days that still need to be imported
+	var missing []time.Time
+	for day := since; !day.After(until); day = day.AddDate(0, 0, 1) {
+		if !options.Overwrite && s.HasDailyStatistics(day) {
+			result.DaysSkipped++
+			continue
+		}
+		missing = append(missing, day)
+	}
+
+	// Request contiguous runs of missing days in chunks
+	for len(missing) > 0 {
+		chunk := 1
+		for chunk < len(missing) && chunk < options.ChunkDays &&
+			missing[chunk].Equal(missing[chunk-1].AddDate(0, 0, 1)) {
+			chunk++
+		}
+		days := missing[:chunk]
+		missing = missing[chunk:]
+
+		performances, err := s.metricsCollector.CollectDailyCampaignMetricsContext(ctx, TimeRange{
+			Since: days[0].Format("2006-01-02"),
+			Until: days[len(days)-1].Format("2006-01-02"),
+		})
+		if err != nil {
+			return result, fmt.Errorf("error collecting metrics for %s to %s: %w",
+				days[0].Format("2006-01-02"), days[len(days)-1].Format("2006-01-02"), err)
+		}
+
+		// Group the rows by their data date
+		byDay := make(map[string][]utils.CampaignPerformance)
+		for _, perf := range performances {
+			key := perf.LastUpdated.Format("2006-01-02")
+			byDay[key] = append(byDay[key], perf)
+		}
+
+		// Store every day, including days without delivery, so they are skipped next time
+		for _, day := range days {
+			dayPerformances := byDay[day.Format("2006-01-02")]
+			if err := s.StoreDailyStatistics(day, dayPerformances); err != nil {
+				return result, err
+			}
+
+			result.DaysImported++
+			result.Records += len(dayPerformances)
+
+			if options.Progress != nil {
+				options.Progress(day, len(dayPerformances))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// CollectDailyCampaignMetrics collects campaign metrics broken down by day.
+// LastUpdated of every returned record is the day the data belongs to, in the account timezone.
+func (m *MetricsCollector) CollectDailyCampaignMetrics(timeRange TimeRange) ([]utils.CampaignPerformance, error) {
+	return m.CollectDailyCampaignMetricsContext(context.Background(), timeRange)
+}
+
+// CollectDailyCampaignMetricsContext collects campaign metrics broken down by day like
+// CollectDailyCampaignMetrics, stopping when ctx is cancelled
+func (m *MetricsCollector) CollectDailyCampaignMetricsContext(ctx context.Context, timeRange TimeRange) ([]utils.CampaignPerformance, error) {
+	var performances []utils.CampaignPerformance
+	err := m.StreamDailyCampaignMetricsContext(ctx, timeRange, func(page []utils.CampaignPerformance) error {
+		performances = append(performances, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return performances, nil
+}
+
+// StreamDailyCampaignMetrics collects campaign metrics broken down by day and hands
+// them to handle one API page at a time, so callers never hold the whole result.
+// The page slice is only valid until handle returns.
+func (m *MetricsCollector) StreamDailyCampaignMetrics(timeRange TimeRange, handle func(page []utils.CampaignPerformance) error) error {
+	return m.StreamDailyCampaignMetricsContext(context.Background(), timeRange, handle)
+}
+
+// StreamDailyCampaignMetricsContext streams daily campaign metrics like
+// StreamDailyCampaignMetrics, stopping when ctx is cancelled
+func (m *MetricsCollector) StreamDailyCampaignMetricsContext(ctx context.Context, timeRange TimeRange, handle func(page []utils.CampaignPerformance) error) error {
+	loc := m.clock.LocationContext(ctx)
+
+	since, err := time.ParseInLocation("2006-01-02", timeRange.Since, loc)
+	if err != nil {
+		return fmt.Errorf("invalid start date: %w", err)
+	}
+	until, err := time.ParseInLocation("2006-01-02", timeRange.Until, loc)
+	if err != nil {
+		return fmt.Errorf("invalid end date: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("level", "campaign")
+	params.Set("fields", "campaign_id,campaign_name,spend,impressions,clicks,actions,cpm,ctr")
+	params.Set("time_increment", "1")
+	params.Set("limit", "500")
+
+	timeRangeJSON, _ := json.Marshal(timeRange)
+	params.Set("time_range", string(timeRangeJSON))
+
+	// Convert every page of raw rows before reading the next one
+	var page []utils.CampaignPerformance
+	handleRows := func(rows []map[string]interface{}) error {
+		page = page[:0]
+		for _, row := range rows {
+			perf := parsePerformance(row)
+
+			// Date the record by the day it describes, not by the import time
+			day, err := time.ParseInLocation("2006-01-02", getString(row, "date_start"), loc)
+			if err != nil {
+				return fmt.Errorf("invalid date_start in insights row: %w", err)
+			}
+			perf.LastUpdated = day
+
+			page = append(page, perf)
+		}
+		return handle(page)
+	}
+
+	// Large ranges are requested as an async report run to avoid timeouts
+	if int(until.Sub(since).Hours()/24)+1 >= asyncInsightsMinDays {
+		return m.runAsyncInsights(ctx, params, handleRows)
+	}
+
+	endpoint := fmt.Sprintf("act_%s/insights", m.accountID)
+	req, err := m.auth.GetAuthenticatedRequestContext(ctx, endpoint, params)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	return m.fetchInsightsPages(req, handleRows)
+}
+
+// runAsyncInsights starts an async insights job, waits for it and passes its result pages to handle.
+// The wait ends with an error when ctx is cancelled or the job runs longer than asyncMaxWait.
+func (m *MetricsCollector) runAsyncInsights(ctx context.Context, params url.Values, handle func(rows []map[string]interface{}) error) error {
+	// Start the report run
+	endpoint := fmt.Sprintf("%s/act_%s/insights", m.auth.GetAPIBaseURL(), m.accountID)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	m.auth.AuthenticateRequest(req)
+
+	var run struct {
+		ReportRunID string `json:"report_run_id"`
+	}
+	if err := m.doJSON(req, &run); err != nil {
+		return fmt.Errorf("error starting async insights job: %w", err)
+	}
+	if run.ReportRunID == "" {
+		return fmt.Errorf("API did not return a report run ID")
+	}
+
+	// Poll until the job finishes
+	deadline := time.NewTimer(asyncMaxWait)
+	defer deadline.Stop()
+	for {
+		statusParams := url.Values{}
+		statusParams.Set("fields", "async_status,async_percent_completion")
+
+		req, err := m.auth.GetAuthenticatedRequestContext(ctx, run.ReportRunID, statusParams)
+		if err != nil {
+			return fmt.Errorf("error creating request: %w", err)
+		}
+
+		var status struct {
+			AsyncStatus            string  `json:"async_status"`
+			AsyncPercentCompletion float64 `json:"async_percent_completion"`
+		}
+		if err := m.doJSON(req, &status); err != nil {
+			return fmt.Errorf("error checking async insights job: %w", err)
+		}
+
+		if status.AsyncStatus == "Job Completed" {
+			break
+		}
+		if status.AsyncStatus == "Job Failed" || status.AsyncStatus == "Job Skipped" {
+			return fmt.Errorf("async insights job %s: %s", run.ReportRunID, status.AsyncStatus)
+		}
+
+		select {
+		case <-time.After(asyncPollInterval):
+		case <-deadline.C:
+			return fmt.Errorf("async insights job %s did not finish within %s (%s, %.0f%% complete)",
+				run.ReportRunID, asyncMaxWait, status.AsyncStatus, status.AsyncPercentCompletion)
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for async insights job %s: %w", run.ReportRunID, ctx.Err())
+		}
+	}
+
+	// Read the results
+	resultParams := url.Values{}
+	resultParams.Set("limit", "500")
+
+	req, err = m.auth.GetAuthenticatedRequestContext(ctx, run.ReportRunID+"/insights", resultParams)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	return m.fetchInsightsPages(req, handle)
+}
+
+// fetchInsightsPages passes every page of an insights request to handle, following paging links.
+// The next pages are requested with the context of req, so cancelling it stops the pagination.
+func (m *MetricsCollector) fetchInsightsPages(req *http.Request, handle func(rows []map[string]interface{}) error) error {
+	ctx := req.Context()
+	for req != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var page struct {
+			Data   []map[string]interface{} `json:"data"`
+			Paging struct {
+				Next string `json:"next"`
+			} `json:"paging"`
+		}
+		if err := m.doJSON(req, &page); err != nil {
+			return err
+		}
+		if err := handle(page.Data); err != nil {
+			return err
+		}
+
+		req = nil
+		if page.Paging.Next != "" {
+			next, err := http.NewRequestWithContext(ctx, "GET", page.Paging.Next, nil)
+			if err != nil {
+				return fmt.Errorf("error creating request: %w", err)
+			}
+			req = next
+		}
+	}
+
+	return nil
+}
+
+// doJSON executes a request and decodes the JSON response into v
+func (m *MetricsCollector) doJSON(req *http.Request, v interface{}) error {
+	return doJSON(m.httpClient.Do, req, v)
+}
+
+// doJSON executes a request with do and decodes the JSON response into v
+func doJSON(do func(*http.Request) (*http.Response, error), req *http.Request, v interface{}) error {
+	resp, err := do(req)
+	if err != nil {
+		return fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return nil
+}
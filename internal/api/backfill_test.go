@@ -0,0 +1,250 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+// insightsFixture serves daily insights for two campaigns and counts the requests
+type insightsFixture struct {
+	t        *testing.T
+	requests int
+}
+
+func (f *insightsFixture) roundTrip(req *http.Request) *http.Response {
+	f.requests++
+
+	if req.URL.Query().Get("time_increment") != "1" {
+		f.t.Errorf("Expected time_increment=1, got %q", req.URL.Query().Get("time_increment"))
+	}
+
+	var timeRange TimeRange
+	json.Unmarshal([]byte(req.URL.Query().Get("time_range")), &timeRange)
+
+	since, _ := time.Parse("2006-01-02", timeRange.Since)
+	until, _ := time.Parse("2006-01-02", timeRange.Until)
+
+	var rows []string
+	for day := since; !day.After(until); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		rows = append(rows,
+			fmt.Sprintf(`{"campaign_id":"111","campaign_name":"Alpha","spend":"10.50","impressions":"1000","clicks":"20","date_start":"%s","date_stop":"%s"}`, date, date),
+			fmt.Sprintf(`{"campaign_id":"222","campaign_name":"Beta","spend":"5","impressions":"400","clicks":"4","date_start":"%s","date_stop":"%s"}`, date, date))
+	}
+
+	return jsonResponse(`{"data":[` + strings.Join(rows, ",") + `]}`)
+}
+
+func jsonResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+	}
+}
+
+// newFixtureCollector returns a metrics collector answering from a fixture
+func newFixtureCollector(t *testing.T, accountID string, transport roundTripFunc) *MetricsCollector {
+	t.Helper()
+
+	clock, _ := newTestClock(t, accountID, "UTC", time.Now())
+	clock.Location()
+
+	return &MetricsCollector{
+		httpClient: &http.Client{Transport: transport},
+		auth:       auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID:  accountID,
+		clock:      clock,
+	}
+}
+
+func TestBackfill_ThreeDaysIdempotent(t *testing.T) {
+	fixture := &insightsFixture{t: t}
+	collector := newFixtureCollector(t, "backfill-3d", fixture.roundTrip)
+
+	dir := t.TempDir()
+	stats := NewStatisticsManager(collector, StorageTypeFile, dir)
+
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	result, err := stats.Backfill(since, until, BackfillOptions{})
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+	if result.DaysImported != 3 || result.DaysSkipped != 0 || result.Records != 6 {
+		t.Errorf("Unexpected first run result: %+v", result)
+	}
+
+	// One record per campaign per day, dated by the data day
+	all, err := stats.GetAllCampaignStatistics(since, until)
+	if err != nil {
+		t.Fatalf("Error reading statistics: %v", err)
+	}
+	if len(all["111"]) != 3 || len(all["222"]) != 3 {
+		t.Fatalf("Expected 3 records per campaign, got %d and %d", len(all["111"]), len(all["222"]))
+	}
+
+	perfs, err := stats.GetCampaignStatistics("111", since, until)
+	if err != nil {
+		t.Fatalf("Error reading campaign statistics: %v", err)
+	}
+	for i, perf := range perfs {
+		expected := since.AddDate(0, 0, i)
+		if !perf.LastUpdated.Equal(expected) {
+			t.Errorf("Expected LastUpdated %s, got %s", expected.Format("2006-01-02"), perf.LastUpdated)
+		}
+		if perf.Spend != 10.50 || perf.Impressions != 1000 {
+			t.Errorf("Unexpected metrics: %+v", perf)
+		}
+	}
+
+	// A second run finds every day stored and makes no requests
+	requestsBefore := fixture.requests
+	before, _ := os.ReadFile(filepath.Join(dir, "daily", "111_2024-01-02.json"))
+
+	result, err = stats.Backfill(since, until, BackfillOptions{})
+	if err != nil {
+		t.Fatalf("Second backfill failed: %v", err)
+	}
+	if result.DaysImported != 0 || result.DaysSkipped != 3 {
+		t.Errorf("Unexpected second run result: %+v", result)
+	}
+	if fixture.requests != requestsBefore {
+		t.Errorf("Expected no API requests on re-run, got %d", fixture.requests-requestsBefore)
+	}
+
+	after, _ := os.ReadFile(filepath.Join(dir, "daily", "111_2024-01-02.json"))
+	if string(before) != string(after) {
+		t.Errorf("Expected stored data to be unchanged by re-run")
+	}
+
+	// Overwrite imports everything again without duplicating records
+	result, err = stats.Backfill(since, until, BackfillOptions{Overwrite: true})
+	if err != nil {
+		t.Fatalf("Overwrite backfill failed: %v", err)
+	}
+	if result.DaysImported != 3 {
+		t.Errorf("Expected 3 days re-imported, got %+v", result)
+	}
+
+	all, _ = stats.GetAllCampaignStatistics(since, until)
+	if len(all["111"]) != 3 {
+		t.Errorf("Expected 3 records after overwrite, got %d", len(all["111"]))
+	}
+}
+
+func TestBackfill_ResumesMissingDays(t *testing.T) {
+	fixture := &insightsFixture{t: t}
+	collector := newFixtureCollector(t, "backfill-resume", fixture.roundTrip)
+	stats := NewStatisticsManager(collector, StorageTypeMemory, "")
+
+	// Day two was imported by an earlier, interrupted run
+	day2 := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	if err := stats.StoreDailyStatistics(day2, nil); err != nil {
+		t.Fatalf("Error storing day: %v", err)
+	}
+
+	var progress []string
+	result, err := stats.Backfill(day2.AddDate(0, 0, -1), day2.AddDate(0, 0, 1), BackfillOptions{
+		Progress: func(day time.Time, records int) {
+			progress = append(progress, day.Format("2006-01-02"))
+		},
+	})
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+
+	if result.DaysImported != 2 || result.DaysSkipped != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if strings.Join(progress, ",") != "2024-01-01,2024-01-03" {
+		t.Errorf("Unexpected progress: %v", progress)
+	}
+	// The gap splits the range into two requests
+	if fixture.requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", fixture.requests)
+	}
+}
+
+func TestCollectDailyCampaignMetrics_AsyncJob(t *testing.T) {
+	oldInterval := asyncPollInterval
+	asyncPollInterval = 0
+	defer func() { asyncPollInterval = oldInterval }()
+
+	statusChecks := 0
+	collector := newFixtureCollector(t, "backfill-async", func(req *http.Request) *http.Response {
+		switch {
+		case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/act_backfill-async/insights"):
+			return jsonResponse(`{"report_run_id":"987"}`)
+		case strings.HasSuffix(req.URL.Path, "/987"):
+			statusChecks++
+			if statusChecks == 1 {
+				return jsonResponse(`{"async_status":"Job Running","async_percent_completion":50}`)
+			}
+			return jsonResponse(`{"async_status":"Job Completed","async_percent_completion":100}`)
+		case strings.HasSuffix(req.URL.Path, "/987/insights") && req.URL.Query().Get("after") == "":
+			return jsonResponse(`{"data":[{"campaign_id":"111","spend":"1","date_start":"2024-01-01"}],"paging":{"next":"https://graph.facebook.com/v22.0/987/insights?after=abc"}}`)
+		case strings.HasSuffix(req.URL.Path, "/987/insights"):
+			return jsonResponse(`{"data":[{"campaign_id":"111","spend":"2","date_start":"2024-01-20"}]}`)
+		}
+		t.Errorf("Unexpected request: %s %s", req.Method, req.URL)
+		return jsonResponse(`{}`)
+	})
+
+	performances, err := collector.CollectDailyCampaignMetrics(TimeRange{Since: "2024-01-01", Until: "2024-01-31"})
+	if err != nil {
+		t.Fatalf("CollectDailyCampaignMetrics failed: %v", err)
+	}
+
+	if statusChecks != 2 {
+		t.Errorf("Expected 2 status checks, got %d", statusChecks)
+	}
+	if len(performances) != 2 {
+		t.Fatalf("Expected 2 rows across pages, got %d", len(performances))
+	}
+	if got := performances[1].LastUpdated.Format("2006-01-02"); got != "2024-01-20" {
+		t.Errorf("Expected second row dated 2024-01-20, got %s", got)
+	}
+}
+
+func TestCollectDailyCampaignMetrics_AsyncJobStuck(t *testing.T) {
+	oldInterval, oldMaxWait := asyncPollInterval, asyncMaxWait
+	defer func() { asyncPollInterval, asyncMaxWait = oldInterval, oldMaxWait }()
+	asyncPollInterval = time.Millisecond
+
+	collector := newFixtureCollector(t, "backfill-stuck", func(req *http.Request) *http.Response {
+		if req.Method == http.MethodPost {
+			return jsonResponse(`{"report_run_id":"988"}`)
+		}
+		return jsonResponse(`{"async_status":"Job Running","async_percent_completion":10}`)
+	})
+	timeRange := TimeRange{Since: "2024-01-01", Until: "2024-01-31"}
+
+	// A job that never finishes is given up after the maximum wait
+	asyncMaxWait = 20 * time.Millisecond
+	_, err := collector.CollectDailyCampaignMetrics(timeRange)
+	if err == nil || !strings.Contains(err.Error(), "did not finish within") {
+		t.Errorf("Expected the job to time out, got %v", err)
+	}
+
+	// Cancelling the context stops the wait
+	asyncMaxWait = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = collector.CollectDailyCampaignMetricsContext(ctx, timeRange)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to stop with the context, got %v", err)
+	}
+}
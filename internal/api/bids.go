@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+
+	"github.com/user/fb-ads/internal/optimization"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// AppliedAdjustment is a CPM adjustment sent to the ad sets of a campaign
+type AppliedAdjustment struct {
+	Adjustment optimization.CampaignAdjustment
+	AdSetIDs   []string // Ad sets whose bid_amount was updated
+}
+
+// ApplyAdjustments sets the bid of the active ad sets of every adjusted campaign to its
+// adjusted CPM
+func (c *Client) ApplyAdjustments(adjustments []optimization.CampaignAdjustment) ([]AppliedAdjustment, error) {
+	return c.ApplyAdjustmentsContext(context.Background(), adjustments)
+}
+
+// ApplyAdjustmentsContext sets bids like ApplyAdjustments. Adjustments that leave the CPM
+// unchanged are skipped, as are ad sets bidding without a cap, which take no bid_amount.
+// On error the adjustments applied so far are returned with it.
+func (c *Client) ApplyAdjustmentsContext(ctx context.Context, adjustments []optimization.CampaignAdjustment) ([]AppliedAdjustment, error) {
+	var applied []AppliedAdjustment
+	for _, adjustment := range adjustments {
+		if math.Abs(adjustment.AdjustedCPM-adjustment.CurrentCPM) < 0.01 {
+			continue
+		}
+
+		adSets, err := c.GetAdSetsContext(ctx, adjustment.CampaignID)
+		if err != nil {
+			return applied, fmt.Errorf("error getting ad sets of campaign %s: %w", adjustment.CampaignID, err)
+		}
+
+		// The API expects bids in cents
+		params := url.Values{}
+		params.Set("bid_amount", strconv.FormatInt(models.DollarsToCents(adjustment.AdjustedCPM), 10))
+
+		result := AppliedAdjustment{Adjustment: adjustment}
+		for _, adSet := range adSets {
+			if models.CampaignStatus(adSet.Status) != models.CampaignStatusActive {
+				continue
+			}
+			if models.BidStrategy(adSet.BidStrategy) == models.BidStrategyLowestCostWithoutCap {
+				continue
+			}
+
+			if err := c.UpdateAdSetContext(ctx, adSet.ID, params); err != nil {
+				if len(result.AdSetIDs) > 0 {
+					applied = append(applied, result)
+				}
+				return applied, fmt.Errorf("error updating bid of ad set %s: %w", adSet.ID, err)
+			}
+			result.AdSetIDs = append(result.AdSetIDs, adSet.ID)
+		}
+
+		if len(result.AdSetIDs) > 0 {
+			applied = append(applied, result)
+		}
+	}
+
+	return applied, nil
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/internal/optimization"
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestApplyAdjustments(t *testing.T) {
+	bids := make(map[string]string)
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			if req.Method == "POST" {
+				if err := req.ParseForm(); err != nil {
+					t.Fatalf("Error parsing update: %v", err)
+				}
+				id := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+				bids[id] = req.PostForm.Get("bid_amount")
+				return jsonResponse(`{"success":true}`)
+			}
+			return jsonResponse(`{"data":[` +
+				`{"id":"111","status":"ACTIVE","bid_strategy":"COST_CAP"},` +
+				`{"id":"112","status":"PAUSED","bid_strategy":"COST_CAP"},` +
+				`{"id":"113","status":"ACTIVE","bid_strategy":"LOWEST_COST_WITHOUT_CAP"}]}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	applied, err := client.ApplyAdjustments([]optimization.CampaignAdjustment{
+		{CampaignID: "100", CurrentCPM: 10, AdjustedCPM: 11.555},
+		{CampaignID: "200", CurrentCPM: 8, AdjustedCPM: 8},
+	})
+	if err != nil {
+		t.Fatalf("ApplyAdjustments failed: %v", err)
+	}
+
+	if len(applied) != 1 || applied[0].Adjustment.CampaignID != "100" || len(applied[0].AdSetIDs) != 1 {
+		t.Fatalf("Expected only campaign 100 to be applied to one ad set, got %+v", applied)
+	}
+	if len(bids) != 1 || bids["111"] != "1156" {
+		t.Errorf("Expected only the active capped ad set to get a bid of 1156 cents, got %v", bids)
+	}
+}
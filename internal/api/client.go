@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -24,16 +25,26 @@ type Client struct {
 // NewClient creates a new Facebook Marketing API client
 func NewClient(auth *auth.FacebookAuth, accountID string) *Client {
 	return &Client{
-		httpClient: &http.Client{},
+		httpClient: auth.HTTPClient(),
 		auth:       auth,
 		accountID:  accountID,
 	}
 }
 
+// doWithRetry sends a request, retrying it while Facebook rejects it with a rate limit
+func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
+	return auth.DoWithRetry(c.httpClient, req, c.auth.Retry, c.accountID)
+}
+
 // GetCampaigns retrieves all campaigns for the account
 func (c *Client) GetCampaigns(limit int, after string) (*models.CampaignResponse, error) {
+	return c.GetCampaignsContext(context.Background(), limit, after)
+}
+
+// GetCampaignsContext retrieves a page of campaigns for the account
+func (c *Client) GetCampaignsContext(ctx context.Context, limit int, after string) (*models.CampaignResponse, error) {
 	params := url.Values{}
-	params.Set("fields", "id,name,status,objective,spend_cap,daily_budget,lifetime_budget,bid_strategy,buying_type,created_time,updated_time,start_time,stop_time,special_ad_categories")
+	params.Set("fields", campaignFields)
 
 	if limit > 0 {
 		params.Set("limit", fmt.Sprintf("%d", limit))
@@ -45,12 +56,12 @@ func (c *Client) GetCampaigns(limit int, after string) (*models.CampaignResponse
 
 	endpoint := fmt.Sprintf("act_%s/campaigns", c.accountID)
 
-	req, err := c.auth.GetAuthenticatedRequest(endpoint, params)
+	req, err := c.auth.GetAuthenticatedRequestContext(ctx, endpoint, params)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("error executing request: %w", err)
 	}
@@ -58,7 +69,7 @@ func (c *Client) GetCampaigns(limit int, after string) (*models.CampaignResponse
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+		return nil, fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
 	}
 
 	// First, decode raw response to handle date parsing issues
@@ -78,59 +89,8 @@ func (c *Client) GetCampaigns(limit int, after string) (*models.CampaignResponse
 
 	// Create the campaign response
 	campaignResp := models.CampaignResponse{}
-
-	// Process the data array if it exists
 	if rawData, ok := rawResponse["data"].([]interface{}); ok {
-		for _, rawCampaign := range rawData {
-			campaignMap, ok := rawCampaign.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			campaign := models.Campaign{
-				ID:             getString(campaignMap, "id"),
-				Name:           getString(campaignMap, "name"),
-				Status:         getString(campaignMap, "status"),
-				ObjectiveType:  getString(campaignMap, "objective"),
-				SpendCap:       getFloat(campaignMap, "spend_cap"),
-				DailyBudget:    getFloat(campaignMap, "daily_budget"),
-				LifetimeBudget: getFloat(campaignMap, "lifetime_budget"),
-				BidStrategy:    getString(campaignMap, "bid_strategy"),
-				BuyingType:     getString(campaignMap, "buying_type"),
-			}
-
-			// Handle date fields with flexible parsing
-			createdStr := getString(campaignMap, "created_time")
-			if createdStr != "" {
-				campaign.Created = parseTime(createdStr)
-			}
-
-			updatedStr := getString(campaignMap, "updated_time")
-			if updatedStr != "" {
-				campaign.Updated = parseTime(updatedStr)
-			}
-
-			startStr := getString(campaignMap, "start_time")
-			if startStr != "" {
-				campaign.StartTime = parseTime(startStr)
-			}
-
-			stopStr := getString(campaignMap, "stop_time")
-			if stopStr != "" {
-				campaign.StopTime = parseTime(stopStr)
-			}
-
-			// Parse special_ad_categories if it exists
-			if rawCategories, ok := campaignMap["special_ad_categories"].([]interface{}); ok {
-				for _, cat := range rawCategories {
-					if catStr, ok := cat.(string); ok {
-						campaign.SpecialAdCategories = append(campaign.SpecialAdCategories, catStr)
-					}
-				}
-			}
-
-			campaignResp.Data = append(campaignResp.Data, campaign)
-		}
+		campaignResp.Data = parseCampaigns(rawData)
 	}
 
 	// Process paging info if it exists
@@ -146,6 +106,53 @@ func (c *Client) GetCampaigns(limit int, after string) (*models.CampaignResponse
 	return &campaignResp, nil
 }
 
+// campaignFields are the campaign fields read into models.Campaign
+const campaignFields = "id,name,status,objective,spend_cap,daily_budget,lifetime_budget,bid_strategy,buying_type," +
+	"created_time,updated_time,start_time,stop_time,special_ad_categories,adlabels{name}"
+
+// parseCampaigns converts the campaign objects of a data array, skipping anything else
+func parseCampaigns(rawData []interface{}) []models.Campaign {
+	var campaigns []models.Campaign
+	for _, rawCampaign := range rawData {
+		campaignMap, ok := rawCampaign.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		campaign := models.Campaign{
+			ID:             getString(campaignMap, "id"),
+			Name:           getString(campaignMap, "name"),
+			Status:         getString(campaignMap, "status"),
+			ObjectiveType:  getString(campaignMap, "objective"),
+			SpendCap:       getFloat(campaignMap, "spend_cap"),
+			DailyBudget:    getFloat(campaignMap, "daily_budget"),
+			LifetimeBudget: getFloat(campaignMap, "lifetime_budget"),
+			BidStrategy:    getString(campaignMap, "bid_strategy"),
+			BuyingType:     getString(campaignMap, "buying_type"),
+		}
+
+		// Handle date fields with flexible parsing
+		campaign.Created = parseTimeField("created_time", getString(campaignMap, "created_time"), &campaign.Warnings)
+		campaign.Updated = parseTimeField("updated_time", getString(campaignMap, "updated_time"), &campaign.Warnings)
+		campaign.StartTime = parseTimeField("start_time", getString(campaignMap, "start_time"), &campaign.Warnings)
+		campaign.StopTime = parseTimeField("stop_time", getString(campaignMap, "stop_time"), &campaign.Warnings)
+
+		// Parse special_ad_categories if it exists
+		if rawCategories, ok := campaignMap["special_ad_categories"].([]interface{}); ok {
+			for _, cat := range rawCategories {
+				if catStr, ok := cat.(string); ok {
+					campaign.SpecialAdCategories = append(campaign.SpecialAdCategories, catStr)
+				}
+			}
+		}
+
+		campaign.Labels = getLabelNames(campaignMap)
+
+		campaigns = append(campaigns, campaign)
+	}
+	return campaigns
+}
+
 // Helper functions for parsing the JSON response
 func getString(m map[string]interface{}, key string) string {
 	if val, ok := m[key].(string); ok {
@@ -154,6 +161,25 @@ func getString(m map[string]interface{}, key string) string {
 	return ""
 }
 
+// getLabelNames returns the names of an object's ad labels.
+// The adlabels field is a plain list, but is also accepted wrapped in a data edge.
+func getLabelNames(m map[string]interface{}) []string {
+	rawData, ok := m["adlabels"].([]interface{})
+	if !ok {
+		if edge, isEdge := m["adlabels"].(map[string]interface{}); isEdge {
+			rawData, _ = edge["data"].([]interface{})
+		}
+	}
+
+	var names []string
+	for _, rawLabel := range rawData {
+		if label, ok := rawLabel.(map[string]interface{}); ok && getString(label, "name") != "" {
+			names = append(names, getString(label, "name"))
+		}
+	}
+	return names
+}
+
 func getFloat(m map[string]interface{}, key string) float64 {
 	switch v := m[key].(type) {
 	case float64:
@@ -167,27 +193,27 @@ func getFloat(m map[string]interface{}, key string) float64 {
 	return 0
 }
 
-func parseTime(timeStr string) time.Time {
-	// Try multiple date formats
-	formats := []string{
-		time.RFC3339,                // 2006-01-02T15:04:05Z07:00
-		"2006-01-02T15:04:05-0700",  // Another common format
-		"2006-01-02T15:04:05",       // Without timezone
-		"2006-01-02T15:04:05-07:00", // With timezone
-		"2006-01-02T15:04:05+0000",  // Yet another format
-		"2006-01-02",                // Just date
-		time.RFC1123,                // Mon, 02 Jan 2006 15:04:05 MST
-		time.RFC1123Z,               // Mon, 02 Jan 2006 15:04:05 -0700
-	}
+// timestampFormats are the layouts Facebook uses for timestamps, tried in order
+var timestampFormats = []string{
+	"2006-01-02T15:04:05-0700",           // Graph API default, e.g. 2024-03-01T10:00:00+0000
+	time.RFC3339,                         // 2006-01-02T15:04:05Z07:00
+	"2006-01-02T15:04:05.999999999-0700", // With fractional seconds
+	"2006-01-02T15:04:05",                // Without timezone
+	"2006-01-02",                         // Just date
+	time.RFC1123,                         // Mon, 02 Jan 2006 15:04:05 MST
+	time.RFC1123Z,                        // Mon, 02 Jan 2006 15:04:05 -0700
+}
 
-	for _, format := range formats {
+// parseTime parses a timestamp in any of the formats returned by the API
+func parseTime(timeStr string) (time.Time, error) {
+	for _, format := range timestampFormats {
 		if t, err := time.Parse(format, timeStr); err == nil {
-			return t
+			return t, nil
 		}
 	}
 
 	// If all parse attempts fail, try a custom approach
-	// Handle the format like "2025-04-08T12:02:56+0100"
+	// Handle offsets like "+0100" after unusual date parts
 	if len(timeStr) > 20 {
 		// Extract the timezone portion
 		tzOffset := timeStr[len(timeStr)-5:]
@@ -196,17 +222,35 @@ func parseTime(timeStr string) time.Time {
 			tzFormatted := tzOffset[:3] + ":" + tzOffset[3:]
 			reformatted := timeStr[:len(timeStr)-5] + tzFormatted
 			if t, err := time.Parse(time.RFC3339, reformatted); err == nil {
-				return t
+				return t, nil
 			}
 		}
 	}
 
-	fmt.Printf("Warning: could not parse time string: %s\n", timeStr)
-	return time.Time{} // Return zero time if parsing fails
+	return time.Time{}, fmt.Errorf("could not parse time string %q", timeStr)
+}
+
+// parseTimeField parses an optional timestamp field.
+// An unparseable value leaves the zero time and adds a warning naming the field.
+func parseTimeField(field, value string, warnings *[]string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+
+	t, err := parseTime(value)
+	if err != nil {
+		*warnings = append(*warnings, fmt.Sprintf("%s: %v", field, err))
+	}
+	return t
 }
 
 // GetCampaignDetails retrieves detailed information about a specific campaign
 func (c *Client) GetCampaignDetails(campaignID string) (*models.CampaignDetails, error) {
+	return c.GetCampaignDetailsContext(context.Background(), campaignID)
+}
+
+// GetCampaignDetailsContext retrieves detailed information about a specific campaign
+func (c *Client) GetCampaignDetailsContext(ctx context.Context, campaignID string) (*models.CampaignDetails, error) {
 	// Create the fields list for all the information we need
 	fields := []string{
 		"id",
@@ -224,11 +268,11 @@ func (c *Client) GetCampaignDetails(campaignID string) (*models.CampaignDetails,
 		"stop_time",
 		"special_ad_categories",
 		// "targeting",  // Targeting is at the adset level, not campaign level
-		"adlabels",
+		"adlabels{name}",
 		"promoted_object",
 		"source_campaign_id",
-		"adsets{id,name,status,targeting,optimization_goal,billing_event,bid_amount,start_time,end_time}",
-		"ads{id,name,status,creative{id,name,title,body,image_url,link_url,call_to_action_type,object_story_spec{page_id}}}",
+		"adsets{id,name,status,targeting,optimization_goal,billing_event,bid_amount,start_time,end_time,destination_type}",
+		"ads{" + adFields + "}",
 	}
 
 	// Create the parameters
@@ -239,13 +283,13 @@ func (c *Client) GetCampaignDetails(campaignID string) (*models.CampaignDetails,
 	endpoint := campaignID
 
 	// Create the request
-	req, err := c.auth.GetAuthenticatedRequest(endpoint, params)
+	req, err := c.auth.GetAuthenticatedRequestContext(ctx, endpoint, params)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	// Send the request
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("error executing request: %w", err)
 	}
@@ -254,7 +298,7 @@ func (c *Client) GetCampaignDetails(campaignID string) (*models.CampaignDetails,
 	// Check for errors
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+		return nil, fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
 	}
 
 	// Read the response body
@@ -284,28 +328,14 @@ func (c *Client) GetCampaignDetails(campaignID string) (*models.CampaignDetails,
 		BidStrategy:         getString(rawData, "bid_strategy"),
 		BuyingType:          getString(rawData, "buying_type"),
 		SpecialAdCategories: []string{},
+		Labels:              getLabelNames(rawData),
 	}
 
 	// Handle date fields
-	createdStr := getString(rawData, "created_time")
-	if createdStr != "" {
-		details.Created = parseTime(createdStr)
-	}
-
-	updatedStr := getString(rawData, "updated_time")
-	if updatedStr != "" {
-		details.Updated = parseTime(updatedStr)
-	}
-
-	startStr := getString(rawData, "start_time")
-	if startStr != "" {
-		details.StartTime = parseTime(startStr)
-	}
-
-	stopStr := getString(rawData, "stop_time")
-	if stopStr != "" {
-		details.StopTime = parseTime(stopStr)
-	}
+	details.Created = parseTimeField("created_time", getString(rawData, "created_time"), &details.Warnings)
+	details.Updated = parseTimeField("updated_time", getString(rawData, "updated_time"), &details.Warnings)
+	details.StartTime = parseTimeField("start_time", getString(rawData, "start_time"), &details.Warnings)
+	details.StopTime = parseTimeField("stop_time", getString(rawData, "stop_time"), &details.Warnings)
 
 	// Handle special ad categories
 	if categories, ok := rawData["special_ad_categories"].([]interface{}); ok {
@@ -321,77 +351,41 @@ func (c *Client) GetCampaignDetails(campaignID string) (*models.CampaignDetails,
 		details.Targeting = targeting
 	}
 
-	// Extract adsets if available
-	if adsets, ok := rawData["adsets"].(map[string]interface{}); ok {
-		if data, ok := adsets["data"].([]interface{}); ok {
-			for _, rawAdset := range data {
-				if adsetMap, ok := rawAdset.(map[string]interface{}); ok {
-					adset := models.AdSetDetails{
-						ID:               getString(adsetMap, "id"),
-						Name:             getString(adsetMap, "name"),
-						Status:           getString(adsetMap, "status"),
-						OptimizationGoal: getString(adsetMap, "optimization_goal"),
-						BillingEvent:     getString(adsetMap, "billing_event"),
-						BidAmount:        getFloat(adsetMap, "bid_amount"),
-					}
-
-					// Parse dates
-					startStr := getString(adsetMap, "start_time")
-					if startStr != "" {
-						adset.StartTime = parseTime(startStr)
-					}
-
-					endStr := getString(adsetMap, "end_time")
-					if endStr != "" {
-						adset.EndTime = parseTime(endStr)
-					}
-
-					// Extract targeting if available
-					if targeting, ok := adsetMap["targeting"].(map[string]interface{}); ok {
-						adset.Targeting = targeting
-					}
-
-					details.AdSets = append(details.AdSets, adset)
-				}
-			}
+	// Extract adsets, following the edge's paging links past the first page
+	adSets, err := c.allEdgeData(ctx, rawData, "adsets")
+	if err != nil {
+		return nil, err
+	}
+	for _, adsetMap := range adSets {
+		adset := models.AdSetDetails{
+			ID:               getString(adsetMap, "id"),
+			Name:             getString(adsetMap, "name"),
+			Status:           getString(adsetMap, "status"),
+			OptimizationGoal: getString(adsetMap, "optimization_goal"),
+			BillingEvent:     getString(adsetMap, "billing_event"),
+			BidAmount:        getFloat(adsetMap, "bid_amount"),
+			DestinationType:  getString(adsetMap, "destination_type"),
 		}
-	}
 
-	// Extract ads if available
-	if ads, ok := rawData["ads"].(map[string]interface{}); ok {
-		if data, ok := ads["data"].([]interface{}); ok {
-			for _, rawAd := range data {
-				if adMap, ok := rawAd.(map[string]interface{}); ok {
-					ad := models.AdDetails{
-						ID:     getString(adMap, "id"),
-						Name:   getString(adMap, "name"),
-						Status: getString(adMap, "status"),
-					}
-
-					// Extract creative if available
-					if creative, ok := adMap["creative"].(map[string]interface{}); ok {
-						creativeDetails := models.CreativeDetails{
-							ID:               getString(creative, "id"),
-							Name:             getString(creative, "name"),
-							Title:            getString(creative, "title"),
-							Body:             getString(creative, "body"),
-							ImageURL:         getString(creative, "image_url"),
-							LinkURL:          getString(creative, "link_url"),
-							CallToActionType: getString(creative, "call_to_action_type"),
-						}
-
-						// Extract page_id from object_story_spec if available
-						if objectStorySpec, ok := creative["object_story_spec"].(map[string]interface{}); ok {
-							creativeDetails.PageID = getString(objectStorySpec, "page_id")
-						}
-
-						ad.Creative = creativeDetails
-					}
-
-					details.Ads = append(details.Ads, ad)
-				}
-			}
+		// Parse dates
+		adset.StartTime = parseTimeField("adsets["+adset.ID+"].start_time", getString(adsetMap, "start_time"), &details.Warnings)
+		adset.EndTime = parseTimeField("adsets["+adset.ID+"].end_time", getString(adsetMap, "end_time"), &details.Warnings)
+
+		// Extract targeting if available
+		if targeting, ok := adsetMap["targeting"].(map[string]interface{}); ok {
+			adset.Targeting = targeting
 		}
+
+		details.AdSets = append(details.AdSets, adset)
+	}
+
+	// Extract ads, following the edge's paging links past the first page
+	ads, err := c.allEdgeData(ctx, rawData, "ads")
+	if err != nil {
+		return nil, err
+	}
+	for _, adMap := range ads {
+		details.Ads = append(details.Ads, parseAd(adMap))
 	}
 
 	return details, nil
@@ -399,37 +393,32 @@ func (c *Client) GetCampaignDetails(campaignID string) (*models.CampaignDetails,
 
 // GetAllCampaigns retrieves all campaigns by handling pagination
 func (c *Client) GetAllCampaigns() ([]models.Campaign, error) {
-	// Check if we're in mock mode (no API credentials)
-	// This is helpful for testing without real Facebook credentials
-	if c.auth.AccessToken == "YOUR_FACEBOOK_ACCESS_TOKEN" || c.auth.AccessToken == "" {
-		fmt.Println("[Using mock data] Configure real Facebook credentials with 'fbads config'")
-		return getMockCampaigns(), nil
-	}
-
-	fmt.Println("[Using Facebook API] Fetching campaigns from account ID:", c.accountID)
-
-	var allCampaigns []models.Campaign
-	var nextCursor string
+	return c.GetAllCampaignsContext(context.Background())
+}
 
-	for {
-		resp, err := c.GetCampaigns(100, nextCursor)
-		if err != nil {
-			return nil, err
-		}
+// GetAllCampaignsContext retrieves all campaigns by handling pagination. Cancelling the
+// context stops the pagination and aborts the request in flight.
+func (c *Client) GetAllCampaignsContext(ctx context.Context) ([]models.Campaign, error) {
+	fmt.Println("Fetching campaigns from account ID:", c.accountID)
 
-		allCampaigns = append(allCampaigns, resp.Data...)
-		fmt.Printf("[Using Facebook API] Retrieved %d campaigns\n", len(resp.Data))
+	params := url.Values{}
+	params.Set("fields", campaignFields)
+	params.Set("limit", "100")
 
-		// Check if there are more pages
-		if resp.Paging.Next == "" {
-			break
+	var allCampaigns []models.Campaign
+	err := c.getPaged(ctx, fmt.Sprintf("act_%s/campaigns", c.accountID), params, func(data []byte) error {
+		var rawData []interface{}
+		if err := json.Unmarshal(data, &rawData); err != nil {
+			return fmt.Errorf("error decoding campaigns: %w", err)
 		}
 
-		// Extract the next cursor
-		nextCursor = resp.Paging.Cursors.After
-		if nextCursor == "" {
-			break
-		}
+		campaigns := parseCampaigns(rawData)
+		allCampaigns = append(allCampaigns, campaigns...)
+		fmt.Printf("Retrieved %d campaigns\n", len(campaigns))
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return allCampaigns, nil
@@ -437,233 +426,49 @@ func (c *Client) GetAllCampaigns() ([]models.Campaign, error) {
 
 // GetPages retrieves Facebook Pages available for the current access token
 func (c *Client) GetPages() ([]models.Page, error) {
+	return c.GetPagesContext(context.Background())
+}
+
+// pagesPerRequest is the number of Facebook Pages requested per page of me/accounts
+const pagesPerRequest = 100
+
+// GetPagesContext retrieves Facebook Pages available for the current access token,
+// following the paging cursors. Cancelling the context stops the pagination.
+func (c *Client) GetPagesContext(ctx context.Context) ([]models.Page, error) {
 	// Create the parameters
 	params := url.Values{}
 	params.Set("fields", "id,name,category,picture")
-
-	// Create the endpoint (no account ID needed as we're getting pages for the user token)
-	endpoint := "me/accounts"
-
-	// Create the request
-	req, err := c.auth.GetAuthenticatedRequest(endpoint, params)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	// Send the request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error executing request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check for errors
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
-	}
-
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
+	params.Set("limit", strconv.Itoa(pagesPerRequest))
+
+	// No account ID needed as we're getting pages for the user token
+	var pages []models.Page
+	err := c.getPaged(ctx, "me/accounts", params, func(data []byte) error {
+		var page []models.Page
+		if err := json.Unmarshal(data, &page); err != nil {
+			return fmt.Errorf("error decoding pages: %w", err)
+		}
+		pages = append(pages, page...)
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return nil, fmt.Errorf("error fetching pages: %w", err)
 	}
 
-	// Parse the response
-	var result struct {
-		Data   []models.Page `json:"data"`
-		Paging struct {
-			Cursors struct {
-				Before string `json:"before"`
-				After  string `json:"after"`
-			} `json:"cursors"`
-			Next string `json:"next"`
-		} `json:"paging"`
-	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("error parsing response: %w", err)
-	}
-
-	return result.Data, nil
-}
-
-// getMockCampaigns returns mock campaign data for testing
-func getMockCampaigns() []models.Campaign {
-	now := time.Now()
-	yesterday := now.AddDate(0, 0, -1)
-
-	return []models.Campaign{
-		{
-			ID:             "23847239847",
-			Name:           "Summer Sale 2023",
-			Status:         "ACTIVE",
-			ObjectiveType:  "CONVERSIONS",
-			SpendCap:       0,
-			DailyBudget:    50.00,
-			LifetimeBudget: 0,
-			BidStrategy:    "LOWEST_COST_WITHOUT_CAP",
-			BuyingType:     "AUCTION",
-			Created:        yesterday.AddDate(0, 0, -5),
-			Updated:        yesterday,
-		},
-		{
-			ID:             "23847239848",
-			Name:           "New Product Launch - Premium Widgets",
-			Status:         "ACTIVE",
-			ObjectiveType:  "CONVERSIONS",
-			SpendCap:       1000.00,
-			DailyBudget:    100.00,
-			LifetimeBudget: 0,
-			BidStrategy:    "LOWEST_COST_WITHOUT_CAP",
-			BuyingType:     "AUCTION",
-			Created:        yesterday.AddDate(0, 0, -10),
-			Updated:        yesterday,
-		},
-		{
-			ID:             "23847239849",
-			Name:           "Brand Awareness Campaign",
-			Status:         "PAUSED",
-			ObjectiveType:  "BRAND_AWARENESS",
-			SpendCap:       0,
-			DailyBudget:    0,
-			LifetimeBudget: 5000.00,
-			BidStrategy:    "LOWEST_COST_WITH_BID_CAP",
-			BuyingType:     "AUCTION",
-			Created:        yesterday.AddDate(0, -1, 0),
-			Updated:        yesterday.AddDate(0, 0, -5),
-		},
-		{
-			ID:             "23847239850",
-			Name:           "Retargeting Campaign - Cart Abandoners",
-			Status:         "ACTIVE",
-			ObjectiveType:  "CONVERSIONS",
-			SpendCap:       0,
-			DailyBudget:    75.00,
-			LifetimeBudget: 0,
-			BidStrategy:    "LOWEST_COST_WITH_BID_CAP",
-			BuyingType:     "AUCTION",
-			Created:        yesterday.AddDate(0, -2, 0),
-			Updated:        yesterday,
-		},
-		{
-			ID:             "23847239851",
-			Name:           "Lead Generation - Newsletter Signup",
-			Status:         "ACTIVE",
-			ObjectiveType:  "LEAD_GENERATION",
-			SpendCap:       500.00,
-			DailyBudget:    25.00,
-			LifetimeBudget: 0,
-			BidStrategy:    "LOWEST_COST_WITHOUT_CAP",
-			BuyingType:     "AUCTION",
-			Created:        yesterday.AddDate(0, -1, -15),
-			Updated:        yesterday.AddDate(0, 0, -3),
-		},
-		{
-			ID:             "23847239852",
-			Name:           "Holiday Special Promotion",
-			Status:         "SCHEDULED",
-			ObjectiveType:  "CONVERSIONS",
-			SpendCap:       0,
-			DailyBudget:    150.00,
-			LifetimeBudget: 0,
-			BidStrategy:    "LOWEST_COST_WITHOUT_CAP",
-			BuyingType:     "AUCTION",
-			Created:        yesterday.AddDate(0, 0, -2),
-			Updated:        yesterday,
-			StartTime:      now.AddDate(0, 0, 30), // 30 days in the future
-			StopTime:       now.AddDate(0, 0, 45), // 45 days in the future
-		},
-		{
-			ID:             "23847239853",
-			Name:           "Winter Collection 2023",
-			Status:         "SCHEDULED",
-			ObjectiveType:  "CATALOG_SALES",
-			SpendCap:       0,
-			DailyBudget:    0,
-			LifetimeBudget: 2000.00,
-			BidStrategy:    "LOWEST_COST_WITHOUT_CAP",
-			BuyingType:     "AUCTION",
-			Created:        yesterday.AddDate(0, 0, -1),
-			Updated:        yesterday,
-			StartTime:      now.AddDate(0, 1, 0), // 1 month in the future
-			StopTime:       now.AddDate(0, 2, 0), // 2 months in the future
-		},
-		{
-			ID:             "23847239854",
-			Name:           "App Install Campaign",
-			Status:         "ACTIVE",
-			ObjectiveType:  "APP_INSTALLS",
-			SpendCap:       1500.00,
-			DailyBudget:    50.00,
-			LifetimeBudget: 0,
-			BidStrategy:    "LOWEST_COST_WITH_BID_CAP",
-			BuyingType:     "AUCTION",
-			Created:        yesterday.AddDate(0, -3, 0),
-			Updated:        yesterday.AddDate(0, 0, -1),
-		},
-		{
-			ID:             "23847239855",
-			Name:           "Video Views - Product Demo",
-			Status:         "ACTIVE",
-			ObjectiveType:  "VIDEO_VIEWS",
-			SpendCap:       0,
-			DailyBudget:    30.00,
-			LifetimeBudget: 0,
-			BidStrategy:    "LOWEST_COST_WITHOUT_CAP",
-			BuyingType:     "AUCTION",
-			Created:        yesterday.AddDate(0, -1, -10),
-			Updated:        yesterday,
-		},
-		{
-			ID:             "23847239856",
-			Name:           "Store Traffic Campaign - New York",
-			Status:         "PAUSED",
-			ObjectiveType:  "STORE_TRAFFIC",
-			SpendCap:       0,
-			DailyBudget:    45.00,
-			LifetimeBudget: 0,
-			BidStrategy:    "LOWEST_COST_WITHOUT_CAP",
-			BuyingType:     "AUCTION",
-			Created:        yesterday.AddDate(0, -2, -15),
-			Updated:        yesterday.AddDate(0, 0, -10),
-		},
-		{
-			ID:             "23847239857",
-			Name:           "Page Likes Campaign",
-			Status:         "ARCHIVED",
-			ObjectiveType:  "PAGE_LIKES",
-			SpendCap:       0,
-			DailyBudget:    0,
-			LifetimeBudget: 300.00,
-			BidStrategy:    "LOWEST_COST_WITHOUT_CAP",
-			BuyingType:     "AUCTION",
-			Created:        yesterday.AddDate(0, -6, 0),
-			Updated:        yesterday.AddDate(0, -1, 0),
-		},
-		{
-			ID:             "23847239858",
-			Name:           "Messages Campaign - Customer Support",
-			Status:         "ACTIVE",
-			ObjectiveType:  "MESSAGES",
-			SpendCap:       0,
-			DailyBudget:    20.00,
-			LifetimeBudget: 0,
-			BidStrategy:    "LOWEST_COST_WITHOUT_CAP",
-			BuyingType:     "AUCTION",
-			Created:        yesterday.AddDate(0, -1, -5),
-			Updated:        yesterday,
-		},
-	}
+	return pages, nil
 }
 
 // UpdateCampaign updates an existing campaign with the provided parameters
 func (c *Client) UpdateCampaign(campaignID string, params url.Values) error {
+	return c.UpdateCampaignContext(context.Background(), campaignID, params)
+}
+
+// UpdateCampaignContext updates an existing campaign with the provided parameters
+func (c *Client) UpdateCampaignContext(ctx context.Context, campaignID string, params url.Values) error {
 	// Create the endpoint URL with the campaign ID
 	endpoint := fmt.Sprintf("%s/%s", c.auth.GetAPIBaseURL(), campaignID)
 
 	// Create the request
-	req, err := http.NewRequest("POST", endpoint, strings.NewReader(params.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(params.Encode()))
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
@@ -675,7 +480,7 @@ func (c *Client) UpdateCampaign(campaignID string, params url.Values) error {
 	c.auth.AuthenticateRequest(req)
 
 	// Send the request
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doWithRetry(req)
 	if err != nil {
 		return fmt.Errorf("error executing request: %w", err)
 	}
@@ -687,9 +492,9 @@ func (c *Client) UpdateCampaign(campaignID string, params url.Values) error {
 		return fmt.Errorf("error reading response: %w", err)
 	}
 
-	// Check for errors
+	// Check for errors, with Facebook's explanation when it gives one
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+		return fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
 	}
 
 	// Parse the response
@@ -708,30 +513,163 @@ func (c *Client) UpdateCampaign(campaignID string, params url.Values) error {
 	return nil
 }
 
-// DeleteCampaign deletes a campaign by ID
-// This sets the campaign status to DELETED in the Facebook Ads API
-func (c *Client) DeleteCampaign(campaignID string) error {
-	// Create the parameters with DELETED status
+// UpdateAdSet updates an existing ad set with the provided parameters
+func (c *Client) UpdateAdSet(adSetID string, params url.Values) error {
+	return c.UpdateAdSetContext(context.Background(), adSetID, params)
+}
+
+// UpdateAdSetContext updates an existing ad set with the provided parameters
+func (c *Client) UpdateAdSetContext(ctx context.Context, adSetID string, params url.Values) error {
+	if err := normalizeStatusParam(params); err != nil {
+		return err
+	}
+
+	// Ad sets are updated through the same object endpoint as campaigns
+	return c.UpdateCampaignContext(ctx, adSetID, params)
+}
+
+// normalizeStatusParam checks that a status being set is ACTIVE, PAUSED or ARCHIVED and
+// upper-cases it for the API
+func normalizeStatusParam(params url.Values) error {
+	status := params.Get("status")
+	if status == "" {
+		return nil
+	}
+
+	parsed, err := models.ParseSettableCampaignStatus(status)
+	if err != nil {
+		return err
+	}
+	params.Set("status", string(parsed))
+	return nil
+}
+
+// UpdateAd updates an existing ad with the provided parameters
+func (c *Client) UpdateAd(adID string, params url.Values) error {
+	return c.UpdateAdContext(context.Background(), adID, params)
+}
+
+// UpdateAdContext updates an existing ad with the provided parameters
+func (c *Client) UpdateAdContext(ctx context.Context, adID string, params url.Values) error {
+	if err := normalizeStatusParam(params); err != nil {
+		return err
+	}
+
+	// Ads are updated through the same object endpoint as campaigns
+	return c.UpdateCampaignContext(ctx, adID, params)
+}
+
+// PauseAd pauses a single ad; its ad set keeps delivering its other ads
+func (c *Client) PauseAd(adID string) error {
+	return c.UpdateAd(adID, url.Values{"status": {string(models.CampaignStatusPaused)}})
+}
+
+// ActivateAd activates a single ad
+func (c *Client) ActivateAd(adID string) error {
+	return c.UpdateAd(adID, url.Values{"status": {string(models.CampaignStatusActive)}})
+}
+
+// UpdateAdSetBudget sets the daily or lifetime budget of an ad set, given in dollars
+func (c *Client) UpdateAdSetBudget(adSetID string, dailyBudget, lifetimeBudget float64) error {
+	return c.UpdateAdSetBudgetContext(context.Background(), adSetID, dailyBudget, lifetimeBudget)
+}
+
+// UpdateAdSetBudgetContext sets the daily or lifetime budget of an ad set, given in dollars.
+// Exactly one of the budgets must be positive; the API expects them in cents.
+func (c *Client) UpdateAdSetBudgetContext(ctx context.Context, adSetID string, dailyBudget, lifetimeBudget float64) error {
+	if dailyBudget < 0 || lifetimeBudget < 0 {
+		return fmt.Errorf("budgets cannot be negative")
+	}
+	if (dailyBudget > 0) == (lifetimeBudget > 0) {
+		return fmt.Errorf("set either a daily or a lifetime budget")
+	}
+
 	params := url.Values{}
-	params.Set("status", "DELETED")
+	if dailyBudget > 0 {
+		params.Set("daily_budget", strconv.FormatInt(models.DollarsToCents(dailyBudget), 10))
+	} else {
+		params.Set("lifetime_budget", strconv.FormatInt(models.DollarsToCents(lifetimeBudget), 10))
+	}
 
-	// Create the endpoint URL with the campaign ID
-	endpoint := fmt.Sprintf("%s/%s", c.auth.GetAPIBaseURL(), campaignID)
+	return c.UpdateAdSetContext(ctx, adSetID, params)
+}
+
+// GetAdSetDetails retrieves detailed information about a specific ad set
+func (c *Client) GetAdSetDetails(adSetID string) (*models.AdSetDetails, error) {
+	return c.GetAdSetDetailsContext(context.Background(), adSetID)
+}
+
+// GetAdSetDetailsContext retrieves detailed information about a specific ad set
+func (c *Client) GetAdSetDetailsContext(ctx context.Context, adSetID string) (*models.AdSetDetails, error) {
+	object, err := c.getObjectContext(ctx, adSetID, adSetFields)
+	if err != nil {
+		return nil, err
+	}
+
+	adSet := parseAdSet(object)
+	// Every ad set belongs to a campaign, so an object without one is something else
+	if adSet.ID == "" || adSet.CampaignID == "" {
+		return nil, fmt.Errorf("%s is not an ad set", adSetID)
+	}
+
+	return &adSet, nil
+}
+
+// SetAdStatus changes the status of an ad
+func (c *Client) SetAdStatus(adID, status string) error {
+	params := url.Values{}
+	params.Set("status", status)
+
+	// Ads are updated through the same object endpoint as campaigns
+	return c.UpdateCampaign(adID, params)
+}
+
+// DeleteCampaign deletes a campaign by ID, together with its ad sets and ads
+func (c *Client) DeleteCampaign(campaignID string) error {
+	return c.DeleteCampaignContext(context.Background(), campaignID)
+}
+
+// DeleteCampaignContext deletes a campaign by ID, together with its ad sets and ads
+func (c *Client) DeleteCampaignContext(ctx context.Context, campaignID string) error {
+	return c.deleteObject(ctx, campaignID)
+}
+
+// DeleteAdSet deletes an ad set by ID, together with its ads
+func (c *Client) DeleteAdSet(adSetID string) error {
+	return c.DeleteAdSetContext(context.Background(), adSetID)
+}
+
+// DeleteAdSetContext deletes an ad set by ID, together with its ads
+func (c *Client) DeleteAdSetContext(ctx context.Context, adSetID string) error {
+	return c.deleteObject(ctx, adSetID)
+}
+
+// DeleteAd deletes an ad by ID
+func (c *Client) DeleteAd(adID string) error {
+	return c.DeleteAdContext(context.Background(), adID)
+}
+
+// DeleteAdContext deletes an ad by ID
+func (c *Client) DeleteAdContext(ctx context.Context, adID string) error {
+	return c.deleteObject(ctx, adID)
+}
+
+// deleteObject issues a DELETE request for a campaign, ad set or ad
+func (c *Client) deleteObject(ctx context.Context, objectID string) error {
+	// Create the endpoint URL with the object ID
+	endpoint := fmt.Sprintf("%s/%s", c.auth.GetAPIBaseURL(), objectID)
 
 	// Create the request
-	req, err := http.NewRequest("POST", endpoint, strings.NewReader(params.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint, nil)
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
 
-	// Set the content type header
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
 	// Add authentication
 	c.auth.AuthenticateRequest(req)
 
 	// Send the request
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doWithRetry(req)
 	if err != nil {
 		return fmt.Errorf("error executing request: %w", err)
 	}
@@ -745,7 +683,7 @@ func (c *Client) DeleteCampaign(campaignID string) error {
 
 	// Check for errors
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+		return fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
 	}
 
 	// Parse the response
@@ -0,0 +1,404 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Time
+	}{
+		{"graph api offset", "2024-03-01T10:00:00+0000", time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{"negative offset", "2024-03-01T10:00:00-0700", time.Date(2024, 3, 1, 17, 0, 0, 0, time.UTC)},
+		{"positive offset", "2025-04-08T12:02:56+0100", time.Date(2025, 4, 8, 11, 2, 56, 0, time.UTC)},
+		{"rfc3339 utc", "2024-03-01T10:00:00Z", time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{"rfc3339 offset", "2024-03-01T10:00:00+02:00", time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)},
+		{"fractional seconds", "2024-03-01T10:00:00.123+0000", time.Date(2024, 3, 1, 10, 0, 0, 123000000, time.UTC)},
+		{"without timezone", "2024-03-01T10:00:00", time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{"date only", "2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"rfc1123z", "Fri, 01 Mar 2024 10:00:00 +0000", time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseTime(tt.value)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !parsed.Equal(tt.expected) {
+				t.Errorf("Expected %s, got %s", tt.expected, parsed)
+			}
+		})
+	}
+
+	if parsed, err := parseTime("next tuesday"); err == nil || !parsed.IsZero() {
+		t.Errorf("Expected an error and zero time for garbage, got %s, %v", parsed, err)
+	}
+}
+
+func TestParseTimeField(t *testing.T) {
+	var warnings []string
+
+	// Missing fields are not a problem
+	if parsed := parseTimeField("stop_time", "", &warnings); !parsed.IsZero() || len(warnings) != 0 {
+		t.Errorf("Expected zero time without warnings for an empty value, got %s, %v", parsed, warnings)
+	}
+
+	parseTimeField("start_time", "2024-03-01T10:00:00+0000", &warnings)
+	parseTimeField("stop_time", "garbage", &warnings)
+
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "stop_time: ") || !strings.Contains(warnings[0], `"garbage"`) {
+		t.Errorf("Expected one warning for stop_time, got %v", warnings)
+	}
+}
+
+func TestGetCampaignDetails_TimestampWarnings(t *testing.T) {
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			return jsonResponse(`{"id":"123","name":"Launch","status":"PAUSED",` +
+				`"created_time":"2024-03-01T10:00:00+0000","start_time":"soon",` +
+				`"adsets":{"data":[{"id":"456","name":"Broad","end_time":"31/12/2024"}]}}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "details",
+	}
+
+	details, err := client.GetCampaignDetails("123")
+	if err != nil {
+		t.Fatalf("GetCampaignDetails failed: %v", err)
+	}
+
+	if details.Created.IsZero() || !details.StartTime.IsZero() {
+		t.Errorf("Expected created_time parsed and start_time left zero, got %s and %s", details.Created, details.StartTime)
+	}
+	if len(details.Warnings) != 2 ||
+		!strings.HasPrefix(details.Warnings[0], "start_time: ") ||
+		!strings.HasPrefix(details.Warnings[1], "adsets[456].end_time: ") {
+		t.Errorf("Expected warnings for start_time and the ad set end_time, got %v", details.Warnings)
+	}
+}
+
+func TestGetAllCampaignsContext_StopsPaginationWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	requests := 0
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			requests++
+			if req.Context() != ctx {
+				t.Errorf("Expected the request to carry the caller's context")
+			}
+			// The caller gives up after the first page
+			cancel()
+			return jsonResponse(`{"data":[{"id":"1","name":"First"}],"paging":{"cursors":{"after":"abc"},"next":"https://graph.facebook.com/next"}}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	_, err := client.GetAllCampaignsContext(ctx)
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected pagination to stop after the first page, got %d requests", requests)
+	}
+}
+
+func TestGetPages_FollowsPaging(t *testing.T) {
+	tests := []struct {
+		name     string
+		endless  bool
+		requests int
+		pages    int
+	}{
+		{name: "last page ends the listing", requests: 2, pages: 3},
+		{name: "endless paging is capped", endless: true, requests: maxPageRequests, pages: 2 * maxPageRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			client := &Client{
+				httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+					requests++
+					if requests == 1 && req.URL.Query().Get("limit") != "100" {
+						t.Errorf("Expected the first request to ask for 100 pages, got %s", req.URL.RawQuery)
+					}
+					if requests == 1 || tt.endless {
+						return jsonResponse(`{"data":[{"id":"1","name":"Shop"},{"id":"2","name":"Blog"}],` +
+							`"paging":{"cursors":{"after":"abc"},"next":"https://graph.facebook.com/v22.0/me/accounts?after=abc"}}`)
+					}
+					return jsonResponse(`{"data":[{"id":"3","name":"Events"}],"paging":{"cursors":{"before":"abc"}}}`)
+				})},
+				auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+				accountID: "123",
+			}
+
+			pages, err := client.GetPages()
+			if err != nil {
+				t.Fatalf("GetPages failed: %v", err)
+			}
+			if requests != tt.requests || len(pages) != tt.pages {
+				t.Errorf("Expected %d pages from %d requests, got %d from %d", tt.pages, tt.requests, len(pages), requests)
+			}
+		})
+	}
+}
+
+func TestUpdateAdSet_PostsToAdSet(t *testing.T) {
+	var method, path, body string
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			method, path = req.Method, req.URL.Path
+			raw, _ := io.ReadAll(req.Body)
+			body = string(raw)
+			return jsonResponse(`{"success":true}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	params := url.Values{}
+	params.Set("bid_amount", "450")
+	if err := client.UpdateAdSet("456", params); err != nil {
+		t.Fatalf("UpdateAdSet failed: %v", err)
+	}
+
+	if method != http.MethodPost || !strings.HasSuffix(path, "/456") || !strings.Contains(body, "bid_amount=450") {
+		t.Errorf("Expected a POST of bid_amount to the ad set, got %s %s %q", method, path, body)
+	}
+}
+
+func TestUpdateAdSetBudget(t *testing.T) {
+	var bodies []string
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			raw, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(raw))
+			return jsonResponse(`{"success":true}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	if err := client.UpdateAdSetBudget("456", 25.99, 0); err != nil {
+		t.Fatalf("UpdateAdSetBudget failed: %v", err)
+	}
+	if err := client.UpdateAdSetBudget("456", 0, 1000); err != nil {
+		t.Fatalf("UpdateAdSetBudget failed: %v", err)
+	}
+	if len(bodies) != 2 || !strings.Contains(bodies[0], "daily_budget=2599") || !strings.Contains(bodies[1], "lifetime_budget=100000") {
+		t.Errorf("Expected the budgets in cents, got %q", bodies)
+	}
+
+	// Invalid budgets never reach the API
+	for _, budgets := range [][2]float64{{0, 0}, {10, 20}, {-5, 0}} {
+		if err := client.UpdateAdSetBudget("456", budgets[0], budgets[1]); err == nil {
+			t.Errorf("Expected budgets %v to be rejected", budgets)
+		}
+	}
+	if len(bodies) != 2 {
+		t.Errorf("Expected no request for invalid budgets, got %d requests", len(bodies))
+	}
+}
+
+func TestUpdateAdSet_Errors(t *testing.T) {
+	requests := 0
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			requests++
+			resp := jsonResponse(`{"error":{"message":"Invalid parameter","type":"OAuthException","code":100,"error_subcode":1885621}}`)
+			resp.StatusCode = http.StatusBadRequest
+			resp.Status = "400 Bad Request"
+			return resp
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	// Statuses are checked before the request
+	err := client.UpdateAdSet("456", url.Values{"status": {"DELETED"}})
+	if err == nil || !strings.Contains(err.Error(), "invalid status") || requests != 0 {
+		t.Errorf("Expected DELETED to be rejected locally, got %v after %d requests", err, requests)
+	}
+
+	// Facebook's explanation is surfaced
+	err = client.UpdateAdSet("456", url.Values{"status": {"paused"}})
+	var apiErr *auth.FacebookAPIError
+	if !errors.As(err, &apiErr) || !apiErr.IsInvalidParameter() || apiErr.Subcode != 1885621 || !strings.Contains(err.Error(), "Invalid parameter") {
+		t.Errorf("Expected the Graph API error, got %v", err)
+	}
+}
+
+// serverTransport sends requests for the Graph API to a test server instead
+type serverTransport struct {
+	server *httptest.Server
+}
+
+// RoundTrip implements http.RoundTripper
+func (s serverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, _ := url.Parse(s.server.URL)
+	req.URL.Scheme, req.URL.Host = target.Scheme, target.Host
+	return s.server.Client().Transport.RoundTrip(req)
+}
+
+func TestPauseAndActivateAd(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Error parsing form: %v", err)
+		}
+		requests = append(requests, r.Method+" "+r.URL.Path+" status="+r.PostForm.Get("status"))
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		httpClient: &http.Client{Transport: serverTransport{server}},
+		auth:       auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID:  "123",
+	}
+
+	if err := client.PauseAd("789"); err != nil {
+		t.Fatalf("PauseAd failed: %v", err)
+	}
+	if err := client.ActivateAd("789"); err != nil {
+		t.Fatalf("ActivateAd failed: %v", err)
+	}
+
+	expected := []string{"POST /v22.0/789 status=PAUSED", "POST /v22.0/789 status=ACTIVE"}
+	if strings.Join(requests, "; ") != strings.Join(expected, "; ") {
+		t.Errorf("Expected %q, got %q", expected, requests)
+	}
+}
+
+func TestGetAdSetDetails(t *testing.T) {
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			if !strings.HasSuffix(req.URL.Path, "/456") {
+				return jsonResponse(`{}`)
+			}
+			return jsonResponse(`{"id":"456","campaign_id":"123","name":"Broad","status":"PAUSED",` +
+				`"bid_amount":"400","end_time":"2024-12-31T23:59:00+0000","targeting":{"age_min":18}}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	adSet, err := client.GetAdSetDetails("456")
+	if err != nil {
+		t.Fatalf("GetAdSetDetails failed: %v", err)
+	}
+	if adSet.CampaignID != "123" || adSet.Status != "PAUSED" || adSet.BidAmount != 400 || adSet.EndTime.IsZero() || adSet.Targeting == nil {
+		t.Errorf("Unexpected ad set details: %+v", adSet)
+	}
+
+	// An empty object means the ID does not point to an ad set
+	if _, err := client.GetAdSetDetails("789"); err == nil {
+		t.Errorf("Expected an error for a missing ad set")
+	}
+}
+
+func TestGetCampaignDetails_FollowsNestedEdgePaging(t *testing.T) {
+	var requested []string
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			requested = append(requested, req.URL.Path+"?"+req.URL.Query().Get("after"))
+			switch {
+			case req.URL.Path == "/v22.0/123/adsets":
+				return jsonResponse(`{"data":[{"id":"s2","name":"Second"}]}`)
+			case req.URL.Path == "/v22.0/123/ads" && req.URL.Query().Get("after") == "p2":
+				return jsonResponse(`{"data":[{"id":"a2","adset_id":"s1"}],` +
+					`"paging":{"next":"https://graph.facebook.com/v22.0/123/ads?after=p3"}}`)
+			case req.URL.Path == "/v22.0/123/ads" && req.URL.Query().Get("after") == "p3":
+				return jsonResponse(`{"data":[{"id":"a3","adset_id":"s2"}],"paging":{}}`)
+			}
+			return jsonResponse(`{"id":"123","name":"Launch","status":"ACTIVE",` +
+				`"adsets":{"data":[{"id":"s1","name":"First"}],"paging":{"next":"https://graph.facebook.com/v22.0/123/adsets?after=s"}},` +
+				`"ads":{"data":[{"id":"a1","adset_id":"s1"}],"paging":{"next":"https://graph.facebook.com/v22.0/123/ads?after=p2"}}}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	details, err := client.GetCampaignDetails("123")
+	if err != nil {
+		t.Fatalf("GetCampaignDetails failed: %v", err)
+	}
+
+	if len(details.AdSets) != 2 || details.AdSets[1].ID != "s2" {
+		t.Errorf("Expected both pages of ad sets, got %+v", details.AdSets)
+	}
+	if len(details.Ads) != 3 || details.Ads[0].ID != "a1" || details.Ads[2].ID != "a3" || details.Ads[2].AdSetID != "s2" {
+		t.Errorf("Expected all three pages of ads in order, got %+v", details.Ads)
+	}
+	if len(requested) != 4 {
+		t.Errorf("Expected the campaign plus three edge pages, got %v", requested)
+	}
+}
+
+func TestGetCampaignDetails_EdgePageError(t *testing.T) {
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			if req.URL.Path == "/v22.0/123/ads" {
+				return &http.Response{
+					StatusCode: http.StatusBadRequest,
+					Status:     "400 Bad Request",
+					Body:       io.NopCloser(strings.NewReader(`{"error":{"message":"Invalid cursor"}}`)),
+					Header:     make(http.Header),
+				}
+			}
+			return jsonResponse(`{"id":"123","ads":{"data":[{"id":"a1"}],"paging":{"next":"https://graph.facebook.com/v22.0/123/ads?after=x"}}}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	// A partial list would silently drop ads from exports and duplicates
+	if _, err := client.GetCampaignDetails("123"); err == nil || !strings.Contains(err.Error(), "error fetching ads") {
+		t.Errorf("Expected an error for the failed page, got %v", err)
+	}
+}
+
+func TestClient_RetriesRateLimitedRequests(t *testing.T) {
+	attempts := 0
+	fbAuth := auth.NewFacebookAuth("app", "secret", "token", "v22.0")
+	fbAuth.Retry = auth.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			attempts++
+			if attempts == 1 {
+				return &http.Response{
+					StatusCode: http.StatusBadRequest,
+					Status:     "400 Bad Request",
+					Body:       io.NopCloser(strings.NewReader(`{"error":{"message":"User request limit reached","code":17}}`)),
+					Header:     make(http.Header),
+				}
+			}
+			return jsonResponse(`{"success":true}`)
+		})},
+		auth:      fbAuth,
+		accountID: "123",
+	}
+
+	params := url.Values{}
+	params.Set("status", "PAUSED")
+	if err := client.UpdateCampaign("456", params); err != nil {
+		t.Fatalf("Expected the update to succeed after a retry, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+// detailCampaignChunk is the number of campaign IDs filtered on in one detailed insights request
+const detailCampaignChunk = 100
+
+// CollectOptions controls how statistics are collected
+type CollectOptions struct {
+	Full bool // Fetch insights of every campaign instead of only those with delivery
+}
+
+// CollectResult summarizes a collection run
+type CollectResult struct {
+	ActiveCampaigns int `json:"active_campaigns"` // Campaigns with delivery, fetched in detail
+	ZeroRows        int `json:"zero_rows"`        // Campaigns active the day before, stored with no delivery
+	Records         int `json:"records"`
+}
+
+// CollectAndStoreStatistics collects statistics for the given time range and stores them.
+// By default every day of the range is collected in two phases: a cheap query finds the
+// campaigns with delivery on that day, then detailed insights are fetched only for those.
+// Campaigns that delivered the day before but not on the day get a zero row so their series
+// have no gaps. Days are stored under their own date. Full fetches every campaign in one
+// request and stores the result under today's date.
+func (s *StatisticsManager) CollectAndStoreStatistics(timeRange TimeRange, options CollectOptions) (*CollectResult, error) {
+	if options.Full {
+		// Collect metrics
+		performances, err := s.metricsCollector.CollectCampaignMetrics(InsightsRequest{
+			Level:     "campaign",
+			TimeRange: timeRange,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error collecting metrics: %w", err)
+		}
+
+		// Store metrics
+		return &CollectResult{ActiveCampaigns: len(performances), Records: len(performances)}, s.StoreStatistics(performances)
+	}
+
+	loc := s.metricsCollector.clock.Location()
+	since, err := time.ParseInLocation("2006-01-02", timeRange.Since, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date: %w", err)
+	}
+	until, err := time.ParseInLocation("2006-01-02", timeRange.Until, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date: %w", err)
+	}
+
+	result := &CollectResult{}
+	for day := since; !day.After(until); day = day.AddDate(0, 0, 1) {
+		if err := s.collectActiveDay(day, result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// collectActiveDay collects and stores one day of statistics for the campaigns with delivery
+func (s *StatisticsManager) collectActiveDay(day time.Time, result *CollectResult) error {
+	date := day.Format("2006-01-02")
+	dayRange := TimeRange{Since: date, Until: date}
+
+	active, err := s.metricsCollector.ActiveCampaignIDs(dayRange)
+	if err != nil {
+		return fmt.Errorf("error finding active campaigns for %s: %w", date, err)
+	}
+
+	performances, err := s.metricsCollector.CollectCampaignDay(day, active)
+	if err != nil {
+		return fmt.Errorf("error collecting metrics for %s: %w", date, err)
+	}
+
+	// Campaigns that stopped delivering get a zero row for the day
+	previous, err := s.GetAllCampaignStatistics(day.AddDate(0, 0, -1), day.AddDate(0, 0, -1))
+	if err != nil {
+		return fmt.Errorf("error reading statistics of the previous day: %w", err)
+	}
+	zeroRows := zeroRowsForInactive(previous, performances, day)
+
+	result.ActiveCampaigns += len(active)
+	result.ZeroRows += len(zeroRows)
+	result.Records += len(performances) + len(zeroRows)
+
+	return s.StoreDailyStatistics(day, append(performances, zeroRows...))
+}
+
+// zeroRowsForInactive returns a zero-delivery record dated day for every campaign that
+// delivered in previous but has no record in collected
+func zeroRowsForInactive(previous map[string][]utils.CampaignPerformance, collected []utils.CampaignPerformance, day time.Time) []utils.CampaignPerformance {
+	seen := make(map[string]bool, len(collected))
+	for _, perf := range collected {
+		seen[perf.CampaignID] = true
+	}
+
+	var zeroRows []utils.CampaignPerformance
+	for campaignID, perfs := range previous {
+		if seen[campaignID] {
+			continue
+		}
+
+		for _, perf := range perfs {
+			if perf.Impressions > 0 || perf.Spend > 0 {
+				zeroRows = append(zeroRows, utils.CampaignPerformance{
+					CampaignID:  campaignID,
+					Name:        perf.Name,
+					LastUpdated: day,
+				})
+				break
+			}
+		}
+	}
+
+	sort.Slice(zeroRows, func(i, j int) bool {
+		return zeroRows[i].CampaignID < zeroRows[j].CampaignID
+	})
+	return zeroRows
+}
+
+// ActiveCampaignIDs returns the IDs of campaigns with impressions in the time range.
+// Only the campaign ID is requested, so the query is cheap even for large accounts.
+func (m *MetricsCollector) ActiveCampaignIDs(timeRange TimeRange) ([]string, error) {
+	params := url.Values{}
+	params.Set("level", "campaign")
+	params.Set("fields", "campaign_id")
+	params.Set("limit", "500")
+
+	timeRangeJSON, _ := json.Marshal(timeRange)
+	params.Set("time_range", string(timeRangeJSON))
+
+	filteringJSON, _ := json.Marshal([]Filter{{Field: "impressions", Operator: "GREATER_THAN", Value: 0}})
+	params.Set("filtering", string(filteringJSON))
+
+	req, err := m.auth.GetAuthenticatedRequest(fmt.Sprintf("act_%s/insights", m.accountID), params)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	var ids []string
+	err = m.fetchInsightsPages(req, func(rows []map[string]interface{}) error {
+		for _, row := range rows {
+			if id := getString(row, "campaign_id"); id != "" {
+				ids = append(ids, id)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// CollectCampaignDay collects the detailed metrics of the given campaigns for one day.
+// LastUpdated of every returned record is the day.
+func (m *MetricsCollector) CollectCampaignDay(day time.Time, campaignIDs []string) ([]utils.CampaignPerformance, error) {
+	if len(campaignIDs) == 0 {
+		return nil, nil
+	}
+
+	date := day.Format("2006-01-02")
+	timeRangeJSON, _ := json.Marshal(TimeRange{Since: date, Until: date})
+
+	var performances []utils.CampaignPerformance
+	for start := 0; start < len(campaignIDs); start += detailCampaignChunk {
+		end := start + detailCampaignChunk
+		if end > len(campaignIDs) {
+			end = len(campaignIDs)
+		}
+
+		params := url.Values{}
+		params.Set("level", "campaign")
+		params.Set("fields", "campaign_id,campaign_name,spend,impressions,clicks,actions,cpm,ctr")
+		params.Set("limit", "500")
+		params.Set("time_range", string(timeRangeJSON))
+
+		filteringJSON, _ := json.Marshal([]Filter{{Field: "campaign.id", Operator: "IN", Value: campaignIDs[start:end]}})
+		params.Set("filtering", string(filteringJSON))
+
+		req, err := m.auth.GetAuthenticatedRequest(fmt.Sprintf("act_%s/insights", m.accountID), params)
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+
+		err = m.fetchInsightsPages(req, func(rows []map[string]interface{}) error {
+			for _, row := range rows {
+				perf := parsePerformance(row)
+				perf.LastUpdated = day
+				performances = append(performances, perf)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return performances, nil
+}
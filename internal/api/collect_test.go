@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+func TestCollectAndStoreStatistics_ActiveCampaignsOnly(t *testing.T) {
+	var detailFilters []string
+	collector := newFixtureCollector(t, "collect-active", func(req *http.Request) *http.Response {
+		filtering := req.URL.Query().Get("filtering")
+		if req.URL.Query().Get("time_range") != `{"since":"2024-01-02","until":"2024-01-02"}` {
+			t.Errorf("Unexpected time range: %s", req.URL.Query().Get("time_range"))
+		}
+
+		// The cheap query only asks for the IDs of campaigns with delivery
+		if strings.Contains(filtering, `"impressions"`) {
+			if req.URL.Query().Get("fields") != "campaign_id" {
+				t.Errorf("Expected the active-set query to request only campaign_id, got %s", req.URL.Query().Get("fields"))
+			}
+			return jsonResponse(`{"data":[{"campaign_id":"111"},{"campaign_id":"444"}]}`)
+		}
+
+		detailFilters = append(detailFilters, filtering)
+		return jsonResponse(`{"data":[` +
+			`{"campaign_id":"111","campaign_name":"Alpha","spend":"12","impressions":"900","clicks":"9"},` +
+			`{"campaign_id":"444","campaign_name":"Delta","spend":"3","impressions":"100","clicks":"1"}]}`)
+	})
+
+	stats := NewStatisticsManager(collector, StorageTypeFile, t.TempDir())
+
+	// 111 and 222 delivered the day before, 333 already had a zero row
+	previousDay := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := stats.StoreDailyStatistics(previousDay, []utils.CampaignPerformance{
+		{CampaignID: "111", Name: "Alpha", Impressions: 1000, Spend: 10, LastUpdated: previousDay},
+		{CampaignID: "222", Name: "Beta", Impressions: 500, Spend: 5, LastUpdated: previousDay},
+		{CampaignID: "333", Name: "Gamma", LastUpdated: previousDay},
+	})
+	if err != nil {
+		t.Fatalf("Error storing statistics: %v", err)
+	}
+
+	result, err := stats.CollectAndStoreStatistics(TimeRange{Since: "2024-01-02", Until: "2024-01-02"}, CollectOptions{})
+	if err != nil {
+		t.Fatalf("CollectAndStoreStatistics failed: %v", err)
+	}
+	if result.ActiveCampaigns != 2 || result.ZeroRows != 1 || result.Records != 3 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// Detailed insights are requested only for the active set
+	if len(detailFilters) != 1 || !strings.Contains(detailFilters[0], `"campaign.id","operator":"IN","value":["111","444"]`) {
+		t.Errorf("Expected one detailed request filtered on the active campaigns, got %v", detailFilters)
+	}
+
+	day := previousDay.AddDate(0, 0, 1)
+	all, err := stats.GetAllCampaignStatistics(day, day)
+	if err != nil {
+		t.Fatalf("Error reading statistics: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("Expected records for 111, 222 and 444, got %v", all)
+	}
+	if perf := all["111"][0]; perf.Spend != 12 || !perf.LastUpdated.Equal(day) {
+		t.Errorf("Expected the detailed record dated by its day, got %+v", perf)
+	}
+
+	// The campaign that stopped delivering gets a zero row instead of a gap
+	zero := all["222"]
+	if len(zero) != 1 || zero[0].Impressions != 0 || zero[0].Spend != 0 || zero[0].Name != "Beta" || !zero[0].LastUpdated.Equal(day) {
+		t.Errorf("Expected a zero row for campaign 222, got %+v", zero)
+	}
+	if _, ok := all["333"]; ok {
+		t.Errorf("Expected no row for a campaign that was inactive the day before")
+	}
+}
+
+func TestCollectAndStoreStatistics_NoDelivery(t *testing.T) {
+	requests := 0
+	collector := newFixtureCollector(t, "collect-idle", func(req *http.Request) *http.Response {
+		requests++
+		return jsonResponse(`{"data":[]}`)
+	})
+
+	stats := NewStatisticsManager(collector, StorageTypeMemory, "")
+	result, err := stats.CollectAndStoreStatistics(TimeRange{Since: "2024-01-02", Until: "2024-01-03"}, CollectOptions{})
+	if err != nil {
+		t.Fatalf("CollectAndStoreStatistics failed: %v", err)
+	}
+
+	// Only the cheap query runs, once per day, and the days are still marked as collected
+	if requests != 2 || result.Records != 0 {
+		t.Errorf("Expected 2 requests and no records, got %d and %+v", requests, result)
+	}
+	if !stats.HasDailyStatistics(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the day to be stored")
+	}
+}
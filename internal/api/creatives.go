@@ -0,0 +1,334 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+// CreativeContent is the content of an ad creative that identifies it across campaigns
+type CreativeContent struct {
+	ID        string `json:"id"`
+	Title     string `json:"title,omitempty"`
+	Body      string `json:"body,omitempty"`
+	LinkURL   string `json:"link_url,omitempty"`
+	ImageHash string `json:"image_hash,omitempty"`
+}
+
+// Hash returns a stable content hash of the creative.
+// Creatives with the same title, body, link and image share a hash even when
+// they were created separately in different campaigns. A creative without any
+// content is identified by its ID, so ads reusing the same creative still match.
+func (c CreativeContent) Hash() string {
+	parts := []string{
+		strings.TrimSpace(c.Title),
+		strings.TrimSpace(c.Body),
+		strings.TrimSpace(c.LinkURL),
+		strings.TrimSpace(c.ImageHash),
+	}
+
+	key := "content:" + strings.Join(parts, "\x00")
+	if strings.Join(parts, "") == "" {
+		key = "id:" + c.ID
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// AdPerformance contains ad-level performance metrics
+type AdPerformance struct {
+	utils.CampaignPerformance
+	AdID       string `json:"ad_id"`
+	AdName     string `json:"ad_name"`
+	AdSetID    string `json:"adset_id,omitempty"`
+	AdSetName  string `json:"adset_name,omitempty"`
+	CreativeID string `json:"creative_id"`
+}
+
+// CreativeRollup aggregates the performance of one distinct creative across campaigns
+type CreativeRollup struct {
+	Hash        string          `json:"hash"`
+	Content     CreativeContent `json:"content"`
+	CreativeIDs []string        `json:"creative_ids"`
+	CampaignIDs []string        `json:"campaign_ids"`
+	Campaigns   []string        `json:"campaigns"`
+	Ads         int             `json:"ads"`
+	Spend       float64         `json:"spend"`
+	Impressions int             `json:"impressions"`
+	Clicks      int             `json:"clicks"`
+	Conversions int             `json:"conversions"`
+	CTR         float64         `json:"ctr"`
+	CPA         float64         `json:"cpa"`
+	Rank        int             `json:"rank"`
+}
+
+// CreativeRollupReport lists distinct creatives ranked from best to worst
+type CreativeRollupReport struct {
+	TimeRange TimeRange        `json:"time_range"`
+	Creatives []CreativeRollup `json:"creatives"`
+}
+
+// Best returns up to n of the best performing creatives
+func (r *CreativeRollupReport) Best(n int) []CreativeRollup {
+	if n > len(r.Creatives) {
+		n = len(r.Creatives)
+	}
+	return r.Creatives[:n]
+}
+
+// Worst returns up to n of the worst performing creatives, worst first
+func (r *CreativeRollupReport) Worst(n int) []CreativeRollup {
+	if n > len(r.Creatives) {
+		n = len(r.Creatives)
+	}
+
+	worst := make([]CreativeRollup, 0, n)
+	for i := len(r.Creatives) - 1; i >= len(r.Creatives)-n; i-- {
+		worst = append(worst, r.Creatives[i])
+	}
+	return worst
+}
+
+// RollupCreatives aggregates ad-level performance per creative content hash.
+// creatives maps creative IDs to their content; ads whose creative is unknown
+// are grouped by creative ID.
+func RollupCreatives(ads []AdPerformance, creatives map[string]CreativeContent) []CreativeRollup {
+	byHash := make(map[string]*CreativeRollup)
+	campaignSeen := make(map[string]map[string]bool)
+	creativeSeen := make(map[string]map[string]bool)
+
+	for _, ad := range ads {
+		content, ok := creatives[ad.CreativeID]
+		if !ok {
+			content = CreativeContent{ID: ad.CreativeID}
+		}
+		hash := content.Hash()
+
+		rollup, ok := byHash[hash]
+		if !ok {
+			rollup = &CreativeRollup{Hash: hash, Content: content}
+			byHash[hash] = rollup
+			campaignSeen[hash] = make(map[string]bool)
+			creativeSeen[hash] = make(map[string]bool)
+		}
+
+		rollup.Ads++
+		rollup.Spend += ad.Spend
+		rollup.Impressions += ad.Impressions
+		rollup.Clicks += ad.Clicks
+		rollup.Conversions += ad.Conversions
+
+		if !campaignSeen[hash][ad.CampaignID] {
+			campaignSeen[hash][ad.CampaignID] = true
+			rollup.CampaignIDs = append(rollup.CampaignIDs, ad.CampaignID)
+			rollup.Campaigns = append(rollup.Campaigns, ad.Name)
+		}
+		if ad.CreativeID != "" && !creativeSeen[hash][ad.CreativeID] {
+			creativeSeen[hash][ad.CreativeID] = true
+			rollup.CreativeIDs = append(rollup.CreativeIDs, ad.CreativeID)
+		}
+	}
+
+	rollups := make([]CreativeRollup, 0, len(byHash))
+	for _, rollup := range byHash {
+		if rollup.Impressions > 0 {
+			rollup.CTR = float64(rollup.Clicks) / float64(rollup.Impressions) * 100
+		}
+		if rollup.Conversions > 0 {
+			rollup.CPA = rollup.Spend / float64(rollup.Conversions)
+		}
+		rollups = append(rollups, *rollup)
+	}
+
+	rankCreatives(rollups)
+	return rollups
+}
+
+// rankCreatives sorts creatives from best to worst and numbers them.
+// Converting creatives rank first by lowest CPA, the rest by highest CTR.
+func rankCreatives(rollups []CreativeRollup) {
+	sort.Slice(rollups, func(i, j int) bool {
+		a, b := rollups[i], rollups[j]
+
+		if (a.Conversions > 0) != (b.Conversions > 0) {
+			return a.Conversions > 0
+		}
+		if a.Conversions > 0 && a.CPA != b.CPA {
+			return a.CPA < b.CPA
+		}
+		if a.CTR != b.CTR {
+			return a.CTR > b.CTR
+		}
+		return a.Hash < b.Hash
+	})
+
+	for i := range rollups {
+		rollups[i].Rank = i + 1
+	}
+}
+
+// GenerateCreativeRollup collects ad-level insights and creative content for
+// timeRange and rolls them up per distinct creative
+func (m *MetricsCollector) GenerateCreativeRollup(timeRange TimeRange) (*CreativeRollupReport, error) {
+	ads, err := m.CollectAdMetrics(timeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	creatives, err := m.CollectCreativeContent()
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreativeRollupReport{
+		TimeRange: timeRange,
+		Creatives: RollupCreatives(ads, creatives),
+	}, nil
+}
+
+// CollectAdMetrics collects ad-level metrics for the account
+func (m *MetricsCollector) CollectAdMetrics(timeRange TimeRange) ([]AdPerformance, error) {
+	ads, err := m.collectAdInsights(fmt.Sprintf("act_%s/insights", m.accountID), timeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	// Insights rows don't carry the creative, so look it up per ad
+	creativeIDs, err := m.collectAdCreativeIDs()
+	if err != nil {
+		return nil, err
+	}
+	for i := range ads {
+		ads[i].CreativeID = creativeIDs[ads[i].AdID]
+	}
+
+	return ads, nil
+}
+
+// CollectCampaignAdMetrics collects ad-level metrics for the ads of one campaign.
+// Ads without delivery in the time range have no row.
+func (m *MetricsCollector) CollectCampaignAdMetrics(campaignID string, timeRange TimeRange) ([]AdPerformance, error) {
+	return m.collectAdInsights(fmt.Sprintf("%s/insights", campaignID), timeRange)
+}
+
+// collectAdInsights requests ad-level insights from an insights endpoint
+func (m *MetricsCollector) collectAdInsights(endpoint string, timeRange TimeRange) ([]AdPerformance, error) {
+	params := url.Values{}
+	params.Set("level", "ad")
+	params.Set("fields", "ad_id,ad_name,adset_id,adset_name,campaign_id,campaign_name,spend,impressions,clicks,actions")
+	params.Set("limit", "500")
+
+	timeRangeJSON, _ := json.Marshal(timeRange)
+	params.Set("time_range", string(timeRangeJSON))
+
+	req, err := m.auth.GetAuthenticatedRequest(endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	var ads []AdPerformance
+	err = m.fetchInsightsPages(req, func(rows []map[string]interface{}) error {
+		for _, row := range rows {
+			ads = append(ads, AdPerformance{
+				CampaignPerformance: parsePerformance(row),
+				AdID:                getString(row, "ad_id"),
+				AdName:              getString(row, "ad_name"),
+				AdSetID:             getString(row, "adset_id"),
+				AdSetName:           getString(row, "adset_name"),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error collecting ad metrics: %w", err)
+	}
+
+	return ads, nil
+}
+
+// collectAdCreativeIDs returns the creative ID of every ad in the account
+func (m *MetricsCollector) collectAdCreativeIDs() (map[string]string, error) {
+	params := url.Values{}
+	params.Set("fields", "id,creative{id}")
+	params.Set("limit", "500")
+
+	req, err := m.auth.GetAuthenticatedRequest(fmt.Sprintf("act_%s/ads", m.accountID), params)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	creativeIDs := make(map[string]string)
+	err = m.fetchInsightsPages(req, func(rows []map[string]interface{}) error {
+		for _, row := range rows {
+			if creative, ok := row["creative"].(map[string]interface{}); ok {
+				creativeIDs[getString(row, "id")] = getString(creative, "id")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error collecting ad creatives: %w", err)
+	}
+
+	return creativeIDs, nil
+}
+
+// CollectCreativeContent returns the content of every creative in the account keyed by creative ID
+func (m *MetricsCollector) CollectCreativeContent() (map[string]CreativeContent, error) {
+	params := url.Values{}
+	params.Set("fields", "id,title,body,link_url,image_hash,object_story_spec{link_data{name,message,link,image_hash}}")
+	params.Set("limit", "500")
+
+	req, err := m.auth.GetAuthenticatedRequest(fmt.Sprintf("act_%s/adcreatives", m.accountID), params)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	creatives := make(map[string]CreativeContent)
+	err = m.fetchInsightsPages(req, func(rows []map[string]interface{}) error {
+		for _, row := range rows {
+			content := CreativeContent{
+				ID:        getString(row, "id"),
+				Title:     getString(row, "title"),
+				Body:      getString(row, "body"),
+				LinkURL:   getString(row, "link_url"),
+				ImageHash: getString(row, "image_hash"),
+			}
+
+			// Link ads keep their content in the object story spec
+			if spec, ok := row["object_story_spec"].(map[string]interface{}); ok {
+				if linkData, ok := spec["link_data"].(map[string]interface{}); ok {
+					content.Title = firstNonEmpty(content.Title, getString(linkData, "name"))
+					content.Body = firstNonEmpty(content.Body, getString(linkData, "message"))
+					content.LinkURL = firstNonEmpty(content.LinkURL, getString(linkData, "link"))
+					content.ImageHash = firstNonEmpty(content.ImageHash, getString(linkData, "image_hash"))
+				}
+			}
+
+			creatives[content.ID] = content
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error collecting creative content: %w", err)
+	}
+
+	return creatives, nil
+}
+
+// firstNonEmpty returns the first of the values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
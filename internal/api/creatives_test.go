@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+func TestCreativeContentHash(t *testing.T) {
+	base := CreativeContent{ID: "1", Title: "Spring sale", Body: "Save 20%", LinkURL: "https://example.com", ImageHash: "abc123"}
+
+	tests := []struct {
+		name  string
+		other CreativeContent
+		same  bool
+	}{
+		{
+			name:  "same content in another creative",
+			other: CreativeContent{ID: "2", Title: "Spring sale", Body: "Save 20%", LinkURL: "https://example.com", ImageHash: "abc123"},
+			same:  true,
+		},
+		{
+			name:  "surrounding whitespace is ignored",
+			other: CreativeContent{ID: "3", Title: " Spring sale", Body: "Save 20%\n", LinkURL: "https://example.com", ImageHash: "abc123"},
+			same:  true,
+		},
+		{
+			name:  "different image",
+			other: CreativeContent{ID: "1", Title: "Spring sale", Body: "Save 20%", LinkURL: "https://example.com", ImageHash: "def456"},
+			same:  false,
+		},
+		{
+			name:  "text moved between fields",
+			other: CreativeContent{ID: "1", Title: "Spring sale Save 20%", LinkURL: "https://example.com", ImageHash: "abc123"},
+			same:  false,
+		},
+		{
+			name:  "creative without content",
+			other: CreativeContent{ID: "1"},
+			same:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := base.Hash() == tt.other.Hash(); same != tt.same {
+				t.Errorf("Expected same hash %v, got %s vs %s", tt.same, base.Hash(), tt.other.Hash())
+			}
+		})
+	}
+
+	// Hashes are stable across runs and releases, so stored reports stay comparable
+	if hash := base.Hash(); hash != "05823df6c321f9d1" {
+		t.Errorf("Expected hash 05823df6c321f9d1, got %q", hash)
+	}
+
+	// Creatives without content are identified by their ID
+	if (CreativeContent{ID: "7"}).Hash() == (CreativeContent{ID: "8"}).Hash() {
+		t.Errorf("Expected creatives without content to hash by ID")
+	}
+}
+
+func TestRollupCreatives_AcrossCampaigns(t *testing.T) {
+	shared := CreativeContent{Title: "Spring sale", Body: "Save 20%", LinkURL: "https://example.com"}
+	creatives := map[string]CreativeContent{
+		"c1": {ID: "c1", Title: shared.Title, Body: shared.Body, LinkURL: shared.LinkURL},
+		"c2": {ID: "c2", Title: shared.Title, Body: shared.Body, LinkURL: shared.LinkURL},
+		"c3": {ID: "c3", Title: "Winter sale", Body: "Save 10%", LinkURL: "https://example.com"},
+	}
+
+	ad := func(campaignID, campaignName, creativeID string, spend float64, impressions, clicks, conversions int) AdPerformance {
+		return AdPerformance{
+			CampaignPerformance: utils.CampaignPerformance{
+				CampaignID: campaignID, Name: campaignName,
+				Spend: spend, Impressions: impressions, Clicks: clicks, Conversions: conversions,
+			},
+			CreativeID: creativeID,
+		}
+	}
+
+	ads := []AdPerformance{
+		ad("111", "Alpha", "c1", 40, 2000, 40, 4),
+		ad("111", "Alpha", "c1", 10, 500, 10, 1),
+		ad("222", "Beta", "c2", 50, 2500, 50, 0),
+		ad("222", "Beta", "c3", 30, 1000, 10, 1),
+		ad("333", "Gamma", "c9", 5, 1000, 30, 0),
+	}
+
+	rollups := RollupCreatives(ads, creatives)
+	if len(rollups) != 3 {
+		t.Fatalf("Expected 3 distinct creatives, got %d", len(rollups))
+	}
+
+	// The shared content converts cheapest and ranks first
+	best := rollups[0]
+	if best.Hash != creatives["c1"].Hash() || best.Rank != 1 {
+		t.Fatalf("Expected the shared creative to rank first, got %+v", best)
+	}
+	if best.Spend != 100 || best.Impressions != 5000 || best.Clicks != 100 || best.Conversions != 5 || best.Ads != 3 {
+		t.Errorf("Unexpected totals for the shared creative: %+v", best)
+	}
+	if best.CTR != 2 || best.CPA != 20 {
+		t.Errorf("Expected CTR 2%% and CPA $20, got %.2f and %.2f", best.CTR, best.CPA)
+	}
+	if !reflect.DeepEqual(best.CampaignIDs, []string{"111", "222"}) || !reflect.DeepEqual(best.Campaigns, []string{"Alpha", "Beta"}) {
+		t.Errorf("Expected the shared creative in Alpha and Beta, got %v %v", best.CampaignIDs, best.Campaigns)
+	}
+	if !reflect.DeepEqual(best.CreativeIDs, []string{"c1", "c2"}) {
+		t.Errorf("Expected creative IDs c1 and c2, got %v", best.CreativeIDs)
+	}
+
+	// Converting creatives rank ahead of non-converting ones
+	if rollups[1].Content.ID != "c3" || rollups[1].CPA != 30 {
+		t.Errorf("Expected the winter creative second, got %+v", rollups[1])
+	}
+
+	// Ads with an unknown creative are grouped by creative ID
+	if rollups[2].Hash != (CreativeContent{ID: "c9"}).Hash() || rollups[2].Rank != 3 {
+		t.Errorf("Expected the unknown creative last, got %+v", rollups[2])
+	}
+
+	report := &CreativeRollupReport{Creatives: rollups}
+	if worst := report.Worst(1); len(worst) != 1 || worst[0].Rank != 3 {
+		t.Errorf("Expected Worst(1) to return rank 3, got %+v", worst)
+	}
+	if len(report.Best(10)) != 3 || len(report.Worst(10)) != 3 {
+		t.Errorf("Expected Best and Worst to be capped at the number of creatives")
+	}
+}
+
+func TestCollectCreativeContent_LinkData(t *testing.T) {
+	collector := newFixtureCollector(t, "creatives", func(req *http.Request) *http.Response {
+		return jsonResponse(`{"data":[` +
+			`{"id":"c1","title":"Classic","body":"Body","link_url":"https://example.com","image_hash":"h1"},` +
+			`{"id":"c2","object_story_spec":{"link_data":{"name":"Classic","message":"Body","link":"https://example.com","image_hash":"h1"}}}]}`)
+	})
+
+	creatives, err := collector.CollectCreativeContent()
+	if err != nil {
+		t.Fatalf("CollectCreativeContent failed: %v", err)
+	}
+
+	// Both creative styles resolve to the same content
+	if creatives["c1"].Hash() != creatives["c2"].Hash() {
+		t.Errorf("Expected link data content to match the classic fields, got %+v and %+v", creatives["c1"], creatives["c2"])
+	}
+}
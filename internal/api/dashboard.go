@@ -1,18 +1,30 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/user/fb-ads/pkg/utils"
 )
 
+const (
+	// maxDashboardDays is the longest range the performance chart can be requested for
+	maxDashboardDays = 365
+	// dailyPerformanceCacheTTL is how long fetched daily performance is served without asking the API again
+	dailyPerformanceCacheTTL = time.Hour
+)
+
 // DashboardData represents the data model for the dashboard
 type DashboardData struct {
 	Title             string                       `json:"title"`
@@ -22,6 +34,8 @@ type DashboardData struct {
 	WorstCampaigns    []utils.CampaignPerformance  `json:"worst_campaigns"`
 	PerformanceByDay  []DailyPerformance           `json:"performance_by_day"`
 	Recommendations   []string                     `json:"recommendations"`
+	Stale             bool                         `json:"stale,omitempty"`   // The last refresh failed, the data is older
+	Warning           string                       `json:"warning,omitempty"` // Why the data is stale
 }
 
 // DashboardSummary contains summary metrics for the dashboard
@@ -36,6 +50,7 @@ type DashboardSummary struct {
 	AverageCPM       float64 `json:"average_cpm"`
 	AverageCPA       float64 `json:"average_cpa"`
 	AverageROAS      float64 `json:"average_roas"`
+	ROASMethod       string  `json:"roas_method,omitempty"`
 }
 
 // DailyPerformance represents performance data for a single day
@@ -57,42 +72,129 @@ type Dashboard struct {
 	metricsCollector *MetricsCollector
 	analyzer         *PerformanceAnalyzer
 	port             int
+	bindAddress      string
 	templateDir      string
 	dataDir          string
+	statsDir         string
+	auth             DashboardAuth
+	serverMu         sync.Mutex
+	server           *http.Server  // Set while Start serves
+	streamsDone      chan struct{} // Closed when the server shuts down, ends the event streams
+	stopped          chan struct{} // Closed when Stop finished shutting the server down
+	streamInterval   time.Duration
+
+	// Warm cache served to all API requests, see dashboard_refresh.go
+	refreshInterval time.Duration
+	refreshToken    string
+	compute         func() (*dashboardSnapshot, error)
+	cacheMu         sync.RWMutex
+	snapshot        *dashboardSnapshot
+	lastRefreshErr  error
+	lastRefreshAt   time.Time
+	refreshing      chan struct{} // Closed when the refresh in progress finishes
 }
 
 // NewDashboard creates a new dashboard
 func NewDashboard(metricsCollector *MetricsCollector, analyzer *PerformanceAnalyzer, port int, templateDir, dataDir string) *Dashboard {
-	return &Dashboard{
+	d := &Dashboard{
 		metricsCollector: metricsCollector,
 		analyzer:         analyzer,
 		port:             port,
 		templateDir:      templateDir,
 		dataDir:          dataDir,
+		refreshInterval:  DefaultDashboardRefreshInterval,
+		streamInterval:   DefaultDashboardStreamInterval,
 	}
+	d.compute = d.buildSnapshot
+	return d
 }
 
-// Start starts the dashboard web server
+// Start starts the dashboard web server and blocks until it fails or Stop is called.
+// After Stop it returns nil once the shutdown completed, so the requests in progress
+// have finished when it returns.
 func (d *Dashboard) Start() error {
 	// Create the data directory if it doesn't exist
 	if err := os.MkdirAll(d.dataDir, 0755); err != nil {
 		return fmt.Errorf("error creating data directory: %w", err)
 	}
 
-	// Set up HTTP routes
-	http.HandleFunc("/", d.handleHome)
-	http.HandleFunc("/api/dashboard", d.handleDashboardData)
-	http.HandleFunc("/api/campaigns", d.handleCampaigns)
-	http.HandleFunc("/api/performance", d.handlePerformance)
-	http.HandleFunc("/api/reports", d.handleReports)
+	listener, err := net.Listen("tcp", net.JoinHostPort(d.bindAddress, strconv.Itoa(d.port)))
+	if err != nil {
+		return fmt.Errorf("error listening: %w", err)
+	}
+
+	// Each start gets its own server, so a stopped dashboard can be started again
+	server := &http.Server{Addr: listener.Addr().String(), Handler: d.Handler()}
+	d.serverMu.Lock()
+	d.server = server
+	d.streamsDone = make(chan struct{})
+	streamsDone := d.streamsDone
+	d.stopped = make(chan struct{})
+	stopped := d.stopped
+	d.serverMu.Unlock()
 
-	// Serve static files
-	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir(filepath.Join(d.templateDir, "static")))))
+	// Shutdown waits for open requests, and event streams only end when told to
+	server.RegisterOnShutdown(func() { close(streamsDone) })
+
+	// Compute the data once, then keep it warm in the background
+	stopRefresher := d.StartRefresher()
+	defer stopRefresher()
 
 	// Start the server
-	addr := fmt.Sprintf(":%d", d.port)
-	fmt.Printf("Dashboard starting on http://localhost%s\n", addr)
-	return http.ListenAndServe(addr, nil)
+	host := d.bindAddress
+	if host == "" {
+		host = "localhost"
+	}
+	_, port, _ := net.SplitHostPort(server.Addr)
+	fmt.Printf("Dashboard starting on http://%s\n", net.JoinHostPort(host, port))
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	// Serve returns as soon as the shutdown starts; wait for it to drain the requests
+	<-stopped
+	return nil
+}
+
+// Stop shuts the dashboard server down gracefully: it stops accepting connections and
+// waits for the requests in progress until ctx is done. Stopping a dashboard that isn't
+// running does nothing.
+func (d *Dashboard) Stop(ctx context.Context) error {
+	d.serverMu.Lock()
+	server := d.server
+	stopped := d.stopped
+	d.server = nil
+	d.serverMu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	defer close(stopped)
+	return server.Shutdown(ctx)
+}
+
+// SetBindAddress sets the interface the dashboard listens on, e.g. 127.0.0.1 to only
+// accept local connections. Empty listens on every interface.
+func (d *Dashboard) SetBindAddress(address string) {
+	d.bindAddress = address
+}
+
+// Handler returns the HTTP handler with all routes, behind the authentication set with SetAuth
+func (d *Dashboard) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", d.handleHome)
+	mux.HandleFunc("/api/dashboard", d.handleDashboardData)
+	mux.HandleFunc("/api/campaigns", d.handleCampaigns)
+	mux.HandleFunc("/api/performance", d.handlePerformance)
+	mux.HandleFunc("/api/trends", d.handleTrends)
+	mux.HandleFunc("/api/reports", d.handleReports)
+	mux.HandleFunc("/api/stream", d.handleStream)
+
+	// Serve static files
+	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir(filepath.Join(d.templateDir, "static")))))
+
+	return d.requireAuth(mux)
 }
 
 // handleHome handles the dashboard home page
@@ -116,15 +218,18 @@ func (d *Dashboard) handleHome(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleDashboardData handles API requests for dashboard data
+// handleDashboardData serves the cached dashboard data
 func (d *Dashboard) handleDashboardData(w http.ResponseWriter, r *http.Request) {
-	// Get the dashboard data
-	data, err := d.generateDashboardData()
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Error generating dashboard data: %v", err), http.StatusInternalServerError)
+	snapshot, warning, ok := d.serveSnapshot(w, r)
+	if !ok {
 		return
 	}
 
+	// Copy so the warning doesn't leak into the shared snapshot
+	data := *snapshot.data
+	data.Stale = warning != ""
+	data.Warning = warning
+
 	// Set the content type
 	w.Header().Set("Content-Type", "application/json")
 
@@ -135,29 +240,25 @@ func (d *Dashboard) handleDashboardData(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// handleCampaigns handles API requests for campaign data
+// handleCampaigns serves the cached campaign analysis of the last 30 days
 func (d *Dashboard) handleCampaigns(w http.ResponseWriter, r *http.Request) {
-	// Create time range for the last 30 days
-	endDate := time.Now()
-	startDate := endDate.AddDate(0, 0, -30)
-
-	timeRange := TimeRange{
-		Since: startDate.Format("2006-01-02"),
-		Until: endDate.Format("2006-01-02"),
-	}
-
-	// Generate an analysis to get campaign data
-	analysis, err := d.analyzer.AnalyzeCampaignPerformance(timeRange)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Error analyzing performance: %v", err), http.StatusInternalServerError)
+	snapshot, warning, ok := d.serveSnapshot(w, r)
+	if !ok {
 		return
 	}
 
+	response := struct {
+		*PerformanceAnalysis
+		GeneratedAt time.Time `json:"generated_at"`
+		Stale       bool      `json:"stale,omitempty"`
+		Warning     string    `json:"warning,omitempty"`
+	}{snapshot.analysis, snapshot.data.GeneratedAt, warning != "", warning}
+
 	// Set the content type
 	w.Header().Set("Content-Type", "application/json")
 
 	// Encode the data as JSON
-	if err := json.NewEncoder(w).Encode(analysis); err != nil {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		http.Error(w, fmt.Sprintf("Error encoding JSON: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -167,12 +268,17 @@ func (d *Dashboard) handleCampaigns(w http.ResponseWriter, r *http.Request) {
 func (d *Dashboard) handlePerformance(w http.ResponseWriter, r *http.Request) {
 	// Parse the query parameters
 	days := 30
-	if r.URL.Query().Get("days") != "" {
-		fmt.Sscanf(r.URL.Query().Get("days"), "%d", &days)
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxDashboardDays {
+			http.Error(w, fmt.Sprintf("days must be a number between 1 and %d", maxDashboardDays), http.StatusBadRequest)
+			return
+		}
+		days = parsed
 	}
 
-	// Get the performance data
-	data, err := d.generateDailyPerformanceData(days)
+	// Get the performance data; the upstream request is cancelled with the browser request
+	data, err := d.generateDailyPerformanceData(r.Context(), days)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error generating performance data: %v", err), http.StatusInternalServerError)
 		return
@@ -188,6 +294,59 @@ func (d *Dashboard) handlePerformance(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// SetStatisticsDir sets the directory of the statistics stored by fbads collect, which
+// /api/trends analyzes
+func (d *Dashboard) SetStatisticsDir(dir string) {
+	d.statsDir = dir
+}
+
+// dashboardTrends is the response of /api/trends: the statistics analysis, and whether
+// any statistics were stored for the window
+type dashboardTrends struct {
+	*AggregateStatistics
+	NoData bool   `json:"no_data,omitempty"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// handleTrends handles API requests for the trends of the stored statistics over the last days
+func (d *Dashboard) handleTrends(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxDashboardDays {
+			http.Error(w, fmt.Sprintf("days must be a number between 1 and %d", maxDashboardDays), http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	endDate := d.metricsCollector.Clock().Today()
+	startDate := endDate.AddDate(0, 0, -(days - 1))
+
+	// Only the stored files are read, no request is sent to Facebook
+	statsManager := NewStatisticsManager(d.metricsCollector, StorageTypeFile, d.statsDir)
+	if d.analyzer != nil {
+		statsManager.SetConversionValues(d.analyzer.conversionValues)
+	}
+	stats, err := statsManager.AnalyzeStatistics(startDate, endDate)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error analyzing statistics: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	trends := dashboardTrends{AggregateStatistics: stats}
+	if stats.TrendSpend == nil {
+		trends.NoData = true
+		trends.Hint = "No statistics are stored for this period yet. Run fbads collect first."
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(trends); err != nil {
+		http.Error(w, fmt.Sprintf("Error encoding JSON: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
+
 // handleReports handles API requests for report data
 func (d *Dashboard) handleReports(w http.ResponseWriter, r *http.Request) {
 	// Get report name from query parameter
@@ -229,10 +388,10 @@ func (d *Dashboard) handleReports(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
-// generateDashboardData generates data for the dashboard
-func (d *Dashboard) generateDashboardData() (*DashboardData, error) {
-	// Create time range for the last 30 days
-	endDate := time.Now()
+// generateDashboardData analyzes the last 30 days and builds the dashboard data
+func (d *Dashboard) generateDashboardData() (*DashboardData, *PerformanceAnalysis, error) {
+	// Create time range for the last 30 days in the account timezone
+	endDate := d.metricsCollector.Clock().Today()
 	startDate := endDate.AddDate(0, 0, -30)
 
 	timeRange := TimeRange{
@@ -243,13 +402,19 @@ func (d *Dashboard) generateDashboardData() (*DashboardData, error) {
 	// Generate an analysis
 	analysis, err := d.analyzer.AnalyzeCampaignPerformance(timeRange)
 	if err != nil {
-		return nil, fmt.Errorf("error analyzing performance: %w", err)
+		return nil, nil, fmt.Errorf("error analyzing performance: %w", err)
 	}
 
 	// Get daily performance data
-	dailyPerformance, err := d.generateDailyPerformanceData(30)
+	dailyPerformance, err := d.generateDailyPerformanceData(context.Background(), 30)
 	if err != nil {
-		return nil, fmt.Errorf("error generating daily performance data: %w", err)
+		return nil, nil, fmt.Errorf("error generating daily performance data: %w", err)
+	}
+
+	// Count the campaigns of the whole account, not only the ranked ones
+	totalCampaigns, activeCampaigns, err := d.countCampaigns(context.Background())
+	if err != nil {
+		return nil, nil, fmt.Errorf("error counting campaigns: %w", err)
 	}
 
 	// Create the dashboard data
@@ -264,81 +429,85 @@ func (d *Dashboard) generateDashboardData() (*DashboardData, error) {
 
 	// Calculate summary metrics
 	dashboardData.Summary = DashboardSummary{
-		TotalCampaigns:   len(analysis.TopCampaigns) + len(analysis.WorstCampaigns),
-		ActiveCampaigns:  0, // To be calculated
+		TotalCampaigns:   totalCampaigns,
+		ActiveCampaigns:  activeCampaigns,
 		TotalSpend:       analysis.TotalSpend,
 		TotalImpressions: analysis.TotalImpressions,
 		TotalClicks:      analysis.TotalClicks,
 		TotalConversions: analysis.TotalConversions,
 		AverageCTR:       analysis.AverageCTR,
-		AverageCPM:       0, // To be calculated
+		AverageCPM:       averageCPM(analysis.TotalSpend, analysis.TotalImpressions),
 		AverageCPA:       analysis.AverageCPA,
 		AverageROAS:      analysis.AverageROAS,
+		ROASMethod:       analysis.ROASMethod,
 	}
 
 	// Save the dashboard data to a file
 	dataFile := filepath.Join(d.dataDir, "dashboard_data.json")
 	data, err := json.MarshalIndent(dashboardData, "", "  ")
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling dashboard data: %w", err)
+		return nil, nil, fmt.Errorf("error marshaling dashboard data: %w", err)
 	}
 
 	if err := os.WriteFile(dataFile, data, 0644); err != nil {
-		return nil, fmt.Errorf("error writing dashboard data: %w", err)
+		return nil, nil, fmt.Errorf("error writing dashboard data: %w", err)
 	}
 
-	return dashboardData, nil
+	return dashboardData, analysis, nil
 }
 
-// generateDailyPerformanceData generates daily performance data for the specified number of days
-func (d *Dashboard) generateDailyPerformanceData(days int) ([]DailyPerformance, error) {
-	// In a real implementation, this would query the Facebook API for daily performance data
-	// For now, we'll generate some sample data
-	var result []DailyPerformance
+// countCampaigns returns the number of campaigns in the account and how many of them are active
+func (d *Dashboard) countCampaigns(ctx context.Context) (total, active int, err error) {
+	m := d.metricsCollector
+	client := &Client{httpClient: m.httpClient, auth: m.auth, accountID: m.accountID}
+	campaigns, err := client.GetAllCampaignsContext(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, campaign := range campaigns {
+		if campaign.Status == "ACTIVE" {
+			active++
+		}
+	}
+	return len(campaigns), active, nil
+}
 
-	endDate := time.Now()
-	startDate := endDate.AddDate(0, 0, -days)
+// averageCPM returns the cost per thousand impressions, or 0 without impressions
+func averageCPM(spend float64, impressions int) float64 {
+	if impressions == 0 {
+		return 0
+	}
+	return spend / float64(impressions) * 1000
+}
+
+// generateDailyPerformanceData returns the account totals of the last days, ending today.
+// The result is cached per number of days for dailyPerformanceCacheTTL. An older cache
+// is only served when the API fails.
+func (d *Dashboard) generateDailyPerformanceData(ctx context.Context, days int) ([]DailyPerformance, error) {
+	endDate := d.metricsCollector.Clock().Today()
+	startDate := endDate.AddDate(0, 0, -(days - 1))
 
-	// Check if we have cached data
 	cacheFile := filepath.Join(d.dataDir, fmt.Sprintf("daily_performance_%d.json", days))
-	if data, err := os.ReadFile(cacheFile); err == nil {
-		// Parse the cached data
-		if err := json.Unmarshal(data, &result); err == nil {
-			return result, nil
+	if info, err := os.Stat(cacheFile); err == nil && time.Since(info.ModTime()) < dailyPerformanceCacheTTL {
+		var cached []DailyPerformance
+		if data, err := os.ReadFile(cacheFile); err == nil && json.Unmarshal(data, &cached) == nil {
+			return cached, nil
 		}
 	}
 
-	// Generate sample data
-	date := startDate
-	for date.Before(endDate) || date.Equal(endDate) {
-		// Generate random but somewhat realistic metrics
-		// In a real implementation, this would come from the Facebook API
-		spend := 50.0 + float64(date.Day()%10)*10.0
-		impressions := 5000 + date.Day()*200
-		clicks := 100 + date.Day()*5
-		conversions := 2 + date.Day()%5
-		ctr := float64(clicks) / float64(impressions) * 100
-		cpc := spend / float64(clicks)
-		cpm := spend / float64(impressions) * 1000
-		cpa := spend / float64(conversions)
-		roas := float64(conversions) * 50.0 / spend
-
-		// Create the daily performance
-		performance := DailyPerformance{
-			Date:         date.Format("2006-01-02"),
-			Spend:        spend,
-			Impressions:  impressions,
-			Clicks:       clicks,
-			Conversions:  conversions,
-			CTR:          ctr,
-			CPC:          cpc,
-			CPM:          cpm,
-			CPA:          cpa,
-			ROAS:         roas,
+	result, err := d.metricsCollector.CollectDailyMetricsContext(ctx, TimeRange{
+		Since: startDate.Format("2006-01-02"),
+		Until: endDate.Format("2006-01-02"),
+	})
+	if err != nil {
+		// Fall back to the last data fetched for the same number of days
+		var cached []DailyPerformance
+		if data, readErr := os.ReadFile(cacheFile); readErr == nil && json.Unmarshal(data, &cached) == nil {
+			fmt.Printf("Warning: error collecting daily metrics, serving cached data: %v\n", err)
+			return cached, nil
 		}
-
-		result = append(result, performance)
-		date = date.AddDate(0, 0, 1)
+		return nil, fmt.Errorf("error collecting daily metrics: %w", err)
 	}
 
 	// Cache the data
@@ -436,6 +605,7 @@ func (d *Dashboard) CreateDashboardFiles() error {
                 <div class="summary-card">
                     <h3>ROAS</h3>
                     <p id="average-roas">0.0x</p>
+                    <small id="roas-method"></small>
                 </div>
                 <div class="summary-card">
                     <h3>Active Campaigns</h3>
@@ -451,6 +621,14 @@ func (d *Dashboard) CreateDashboardFiles() error {
             </div>
         </section>
         
+        <section class="chart-section">
+            <h2>Collected Statistics</h2>
+            <p id="trends-hint" class="hint" hidden></p>
+            <div class="chart-container">
+                <canvas id="trends-chart"></canvas>
+            </div>
+        </section>
+        
         <div class="dashboard-grid">
             <section class="top-campaigns-section">
                 <h2>Top Performing Campaigns</h2>
@@ -581,6 +759,11 @@ section h2 {
     width: 100%;
 }
 
+.hint {
+    color: #65676b;
+    margin-bottom: 10px;
+}
+
 /* Tables */
 table {
     width: 100%;
@@ -689,6 +872,20 @@ async function fetchPerformanceData(days = 30) {
     }
 }
 
+// Fetch the trends of the collected statistics
+async function fetchTrends(days = 30) {
+    try {
+        const response = await fetch('/api/trends?days=' + days);
+        if (!response.ok) {
+            throw new Error('Failed to fetch trends');
+        }
+        return await response.json();
+    } catch (error) {
+        console.error('Error fetching trends:', error);
+        return null;
+    }
+}
+
 // Format currency
 function formatCurrency(value) {
     return '$' + parseFloat(value).toFixed(2);
@@ -713,9 +910,14 @@ function updateSummary(data) {
     document.getElementById('average-ctr').textContent = formatPercentage(data.summary.average_ctr);
     document.getElementById('average-cpa').textContent = formatCurrency(data.summary.average_cpa);
     document.getElementById('average-roas').textContent = parseFloat(data.summary.average_roas).toFixed(1) + 'x';
+    document.getElementById('roas-method').textContent = data.summary.roas_method ? 'Revenue ' + data.summary.roas_method : '';
     document.getElementById('active-campaigns').textContent = data.summary.active_campaigns;
     
-    document.getElementById('last-updated').textContent = new Date(data.generated_at).toLocaleString();
+    let updated = new Date(data.generated_at).toLocaleString();
+    if (data.stale) {
+        updated += ' (stale: ' + data.warning + ')';
+    }
+    document.getElementById('last-updated').textContent = updated;
 }
 
 // Update top campaigns table
@@ -751,16 +953,22 @@ function updateRecommendations(recommendations) {
     });
 }
 
+// The performance chart, replaced when new data arrives
+let performanceChart = null;
+
 // Create performance chart
 function createPerformanceChart(data) {
     const ctx = document.getElementById('performance-chart').getContext('2d');
+    if (performanceChart) {
+        performanceChart.destroy();
+    }
     
     const dates = data.map(item => item.date);
     const spend = data.map(item => item.spend);
     const conversions = data.map(item => item.conversions);
     const cpa = data.map(item => item.cpa);
     
-    new Chart(ctx, {
+    performanceChart = new Chart(ctx, {
         type: 'line',
         data: {
             labels: dates,
@@ -839,6 +1047,93 @@ function createPerformanceChart(data) {
     });
 }
 
+// Create the chart of the collected statistics: daily spend, CTR and CPM
+function createTrendsChart(trends) {
+    const hint = document.getElementById('trends-hint');
+    if (trends.no_data) {
+        hint.textContent = trends.hint;
+        hint.hidden = false;
+        return;
+    }
+    hint.textContent = 'Spend ' + formatChange(trends.trend_spend.change) +
+        ', CTR ' + formatChange(trends.trend_ctr.change) +
+        ', CPM ' + formatChange(trends.trend_cpm.change) + ' over the period';
+    hint.hidden = false;
+
+    const ctx = document.getElementById('trends-chart').getContext('2d');
+    const dates = trends.trend_spend.timestamps.map(t => t.substring(0, 10));
+
+    new Chart(ctx, {
+        type: 'line',
+        data: {
+            labels: dates,
+            datasets: [
+                {
+                    label: 'Spend',
+                    data: trends.trend_spend.values,
+                    borderColor: '#1877f2',
+                    backgroundColor: 'rgba(24, 119, 242, 0.1)',
+                    yAxisID: 'y',
+                    fill: true
+                },
+                {
+                    label: 'CTR (%)',
+                    data: trends.trend_ctr.values,
+                    borderColor: '#42b72a',
+                    yAxisID: 'y1',
+                    fill: false
+                },
+                {
+                    label: 'CPM',
+                    data: trends.trend_cpm.values,
+                    borderColor: '#f7b928',
+                    yAxisID: 'y2',
+                    fill: false
+                }
+            ]
+        },
+        options: {
+            responsive: true,
+            maintainAspectRatio: false,
+            scales: {
+                y: {
+                    position: 'left',
+                    title: {
+                        display: true,
+                        text: 'Spend ($)'
+                    }
+                },
+                y1: {
+                    position: 'right',
+                    title: {
+                        display: true,
+                        text: 'CTR (%)'
+                    },
+                    grid: {
+                        drawOnChartArea: false
+                    }
+                },
+                y2: {
+                    position: 'right',
+                    title: {
+                        display: true,
+                        text: 'CPM ($)'
+                    },
+                    grid: {
+                        drawOnChartArea: false
+                    }
+                }
+            }
+        }
+    });
+}
+
+// Format a percentage change with its sign
+function formatChange(value) {
+    const sign = value > 0 ? '+' : '';
+    return sign + parseFloat(value).toFixed(1) + '%';
+}
+
 // Fetch available reports
 async function fetchReports() {
     try {
@@ -956,6 +1251,31 @@ async function initDashboard() {
     if (performanceData.length > 0) {
         createPerformanceChart(performanceData);
     }
+    
+    const trends = await fetchTrends();
+    if (trends) {
+        createTrendsChart(trends);
+    }
+    
+    subscribeToUpdates();
+}
+
+// Re-render the summary and the chart with the data pushed by the server.
+// EventSource reconnects by itself when the connection drops.
+function subscribeToUpdates() {
+    const source = new EventSource('/api/stream');
+    source.onmessage = event => {
+        const data = JSON.parse(event.data);
+        updateSummary(data);
+        updateTopCampaigns(data.top_campaigns);
+        updateRecommendations(data.recommendations);
+        if (data.performance_by_day && data.performance_by_day.length > 0) {
+            createPerformanceChart(data.performance_by_day);
+        }
+    };
+    source.onerror = () => {
+        console.error('Dashboard update stream interrupted, reconnecting');
+    };
 }
 
 // Initialize when the DOM is loaded
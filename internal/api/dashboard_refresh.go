@@ -0,0 +1,157 @@
+package api
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// DefaultDashboardRefreshInterval is how often the dashboard data is recomputed in the background
+const DefaultDashboardRefreshInterval = 15 * time.Minute
+
+// dashboardSnapshot is the last successfully computed dashboard data
+type dashboardSnapshot struct {
+	data     *DashboardData
+	analysis *PerformanceAnalysis
+}
+
+// SetRefreshInterval sets how often the dashboard data is recomputed
+func (d *Dashboard) SetRefreshInterval(interval time.Duration) {
+	if interval > 0 {
+		d.refreshInterval = interval
+	}
+}
+
+// SetRefreshToken sets the bearer token required to force a refresh with ?refresh=1.
+// Without a token forced refreshes are refused.
+func (d *Dashboard) SetRefreshToken(token string) {
+	d.refreshToken = token
+}
+
+// buildSnapshot analyzes the account once and builds the data served by all endpoints
+func (d *Dashboard) buildSnapshot() (*dashboardSnapshot, error) {
+	data, analysis, err := d.generateDashboardData()
+	if err != nil {
+		return nil, err
+	}
+	return &dashboardSnapshot{data: data, analysis: analysis}, nil
+}
+
+// Refresh recomputes the dashboard data. Concurrent calls share the refresh in progress.
+// On failure the previous snapshot is kept and served as stale.
+func (d *Dashboard) Refresh() error {
+	d.cacheMu.Lock()
+	if done := d.refreshing; done != nil {
+		// Wait for the refresh in progress instead of starting another one
+		d.cacheMu.Unlock()
+		<-done
+
+		d.cacheMu.RLock()
+		defer d.cacheMu.RUnlock()
+		return d.lastRefreshErr
+	}
+	done := make(chan struct{})
+	d.refreshing = done
+	d.cacheMu.Unlock()
+
+	// Compute without holding the lock so requests keep getting the cached data
+	snapshot, err := d.compute()
+
+	d.cacheMu.Lock()
+	defer d.cacheMu.Unlock()
+	d.lastRefreshAt = time.Now()
+	d.lastRefreshErr = err
+	if err == nil {
+		d.snapshot = snapshot
+	}
+	d.refreshing = nil
+	close(done)
+
+	return err
+}
+
+// StartRefresher computes the dashboard data once, then refreshes it in the background
+// every refresh interval plus up to 10% jitter. The returned function stops the refresher.
+func (d *Dashboard) StartRefresher() (stop func()) {
+	if err := d.Refresh(); err != nil {
+		fmt.Printf("Warning: error computing dashboard data: %v\n", err)
+	}
+
+	quit := make(chan struct{})
+	go func() {
+		for {
+			timer := time.NewTimer(d.nextRefreshDelay())
+			select {
+			case <-quit:
+				timer.Stop()
+				return
+			case <-timer.C:
+				if err := d.Refresh(); err != nil {
+					fmt.Printf("Warning: error refreshing dashboard data: %v\n", err)
+				}
+			}
+		}
+	}()
+
+	return func() { close(quit) }
+}
+
+// nextRefreshDelay returns the refresh interval with random jitter, so several
+// dashboards started together don't hit the API at the same moment
+func (d *Dashboard) nextRefreshDelay() time.Duration {
+	jitter := int64(d.refreshInterval / 10)
+	if jitter <= 0 {
+		return d.refreshInterval
+	}
+	return d.refreshInterval + time.Duration(rand.Int63n(jitter))
+}
+
+// serveSnapshot returns the cached snapshot for a request and a warning when the last
+// refresh failed. ?refresh=1 forces a refresh first and requires the bearer token.
+// When no data can be served the error is written and ok is false. A client that
+// disconnects during a forced refresh stops waiting for it; the refresh itself completes
+// for the other requests sharing it.
+func (d *Dashboard) serveSnapshot(w http.ResponseWriter, r *http.Request) (snapshot *dashboardSnapshot, warning string, ok bool) {
+	if r.URL.Query().Get("refresh") == "1" {
+		if d.refreshToken == "" || !SecureEqual(r.Header.Get("Authorization"), "Bearer "+d.refreshToken) {
+			http.Error(w, "Forcing a refresh requires a valid bearer token", http.StatusUnauthorized)
+			return nil, "", false
+		}
+
+		// A failed refresh is reported as a warning on the cached data below
+		refreshed := make(chan struct{})
+		go func() {
+			d.Refresh()
+			close(refreshed)
+		}()
+		select {
+		case <-refreshed:
+		case <-r.Context().Done():
+			return nil, "", false
+		}
+	}
+
+	d.cacheMu.RLock()
+	defer d.cacheMu.RUnlock()
+
+	if d.snapshot == nil {
+		if d.lastRefreshErr != nil {
+			http.Error(w, fmt.Sprintf("Dashboard data is not available: %v", d.lastRefreshErr), http.StatusServiceUnavailable)
+		} else {
+			http.Error(w, "Dashboard data is not ready yet", http.StatusServiceUnavailable)
+		}
+		return nil, "", false
+	}
+
+	return d.snapshot, d.staleWarning(), true
+}
+
+// staleWarning describes the last refresh when it failed and the cached data is older.
+// The caller holds cacheMu.
+func (d *Dashboard) staleWarning() string {
+	if d.lastRefreshErr == nil {
+		return ""
+	}
+	return fmt.Sprintf("refresh at %s failed: %v", d.lastRefreshAt.Format(time.RFC3339), d.lastRefreshErr)
+}
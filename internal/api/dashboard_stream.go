@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultDashboardStreamInterval is how often /api/stream pushes the dashboard data
+const DefaultDashboardStreamInterval = 30 * time.Second
+
+// SetStreamInterval sets how often /api/stream pushes the dashboard data to subscribers.
+// The data itself changes with each refresh, see SetRefreshInterval.
+func (d *Dashboard) SetStreamInterval(interval time.Duration) {
+	if interval > 0 {
+		d.streamInterval = interval
+	}
+}
+
+// handleStream pushes the cached dashboard data as Server-Sent Events: once right away,
+// then every stream interval until the client disconnects or the dashboard stops.
+// Before the first refresh completes only a comment is sent, which keeps the connection open.
+func (d *Dashboard) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	d.serverMu.Lock()
+	stopped := d.streamsDone
+	d.serverMu.Unlock()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ticker := time.NewTicker(d.streamInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.writeStreamEvent(w); err != nil {
+			// The client is gone
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-stopped:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeStreamEvent writes the current dashboard data as one event
+func (d *Dashboard) writeStreamEvent(w http.ResponseWriter) error {
+	data, ok := d.currentData()
+	if !ok {
+		_, err := fmt.Fprint(w, ": dashboard data is not ready yet\n\n")
+		return err
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error encoding dashboard data: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", payload)
+	return err
+}
+
+// currentData returns a copy of the cached dashboard data, marked stale when the last
+// refresh failed. ok is false while there is no data yet.
+func (d *Dashboard) currentData() (data DashboardData, ok bool) {
+	d.cacheMu.RLock()
+	defer d.cacheMu.RUnlock()
+
+	if d.snapshot == nil {
+		return DashboardData{}, false
+	}
+
+	data = *d.snapshot.data
+	data.Warning = d.staleWarning()
+	data.Stale = data.Warning != ""
+	return data, true
+}
@@ -0,0 +1,655 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+// newTestDashboard returns a dashboard whose data comes from compute
+func newTestDashboard(compute func() (*dashboardSnapshot, error)) *Dashboard {
+	return &Dashboard{
+		refreshInterval: DefaultDashboardRefreshInterval,
+		streamInterval:  DefaultDashboardStreamInterval,
+		compute:         compute,
+	}
+}
+
+// testSnapshot returns a snapshot generated at the given time
+func testSnapshot(generatedAt time.Time, spend float64) *dashboardSnapshot {
+	return &dashboardSnapshot{
+		data:     &DashboardData{Title: "Test", GeneratedAt: generatedAt, Summary: DashboardSummary{TotalSpend: spend}},
+		analysis: &PerformanceAnalysis{TotalSpend: spend},
+	}
+}
+
+// getDashboardData requests /api/dashboard and decodes the response
+func getDashboardData(t *testing.T, d *Dashboard, target string, header http.Header) (int, DashboardData) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	d.handleDashboardData(rec, req)
+
+	var data DashboardData
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+			t.Fatalf("Error decoding response: %v", err)
+		}
+	}
+	return rec.Code, data
+}
+
+func TestDashboardServesCacheDuringSlowRefresh(t *testing.T) {
+	first := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var calls int32
+
+	d := newTestDashboard(func() (*dashboardSnapshot, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return testSnapshot(first, 10), nil
+		}
+		close(started)
+		<-release
+		return testSnapshot(first.Add(15*time.Minute), 20), nil
+	})
+
+	if err := d.Refresh(); err != nil {
+		t.Fatalf("Initial refresh failed: %v", err)
+	}
+
+	// Start a slow refresh and keep it blocked
+	done := make(chan error)
+	go func() { done <- d.Refresh() }()
+	<-started
+
+	// Handlers answer from the cache without waiting for the refresh
+	served := make(chan *httptest.ResponseRecorder)
+	go func() {
+		rec := httptest.NewRecorder()
+		d.handleDashboardData(rec, httptest.NewRequest(http.MethodGet, "/api/dashboard", nil))
+		served <- rec
+	}()
+	select {
+	case rec := <-served:
+		var data DashboardData
+		if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+			t.Fatalf("Error decoding response: %v", err)
+		}
+		if !data.GeneratedAt.Equal(first) || data.Summary.TotalSpend != 10 || data.Stale {
+			t.Errorf("Expected the cached data, got %+v", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handler blocked on the refresh in progress")
+	}
+
+	rec := httptest.NewRecorder()
+	d.handleCampaigns(rec, httptest.NewRequest(http.MethodGet, "/api/campaigns", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"total_spend":10`) || !strings.Contains(rec.Body.String(), `"generated_at"`) {
+		t.Errorf("Expected the cached analysis, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	_, data := getDashboardData(t, d, "/api/dashboard", nil)
+	if data.Summary.TotalSpend != 20 {
+		t.Errorf("Expected the refreshed data, got %+v", data)
+	}
+}
+
+func TestDashboardKeepsLastGoodDataOnFailure(t *testing.T) {
+	generatedAt := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	fail := false
+	d := newTestDashboard(func() (*dashboardSnapshot, error) {
+		if fail {
+			return nil, errors.New("API error: rate limited")
+		}
+		return testSnapshot(generatedAt, 10), nil
+	})
+
+	// Nothing to serve before the first successful refresh
+	if code, _ := getDashboardData(t, d, "/api/dashboard", nil); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before the first refresh, got %d", code)
+	}
+
+	d.Refresh()
+	fail = true
+	if err := d.Refresh(); err == nil {
+		t.Fatal("Expected the refresh to fail")
+	}
+
+	code, data := getDashboardData(t, d, "/api/dashboard", nil)
+	if code != http.StatusOK || !data.GeneratedAt.Equal(generatedAt) {
+		t.Fatalf("Expected the last good data, got %d %+v", code, data)
+	}
+	if !data.Stale || !strings.Contains(data.Warning, "rate limited") {
+		t.Errorf("Expected a staleness warning, got stale=%v warning=%q", data.Stale, data.Warning)
+	}
+
+	// The warning is per response, the cached data stays untouched
+	if d.snapshot.data.Warning != "" {
+		t.Errorf("Expected the snapshot to stay unmodified")
+	}
+}
+
+func TestDashboardForcedRefreshRequiresToken(t *testing.T) {
+	var calls int32
+	d := newTestDashboard(func() (*dashboardSnapshot, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return testSnapshot(time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC), float64(n)), nil
+	})
+	d.Refresh()
+
+	// Refused without a configured token
+	if code, _ := getDashboardData(t, d, "/api/dashboard?refresh=1", nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a configured token, got %d", code)
+	}
+
+	d.SetRefreshToken("secret")
+	header := http.Header{"Authorization": {"Bearer wrong"}}
+	if code, _ := getDashboardData(t, d, "/api/dashboard?refresh=1", header); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with a wrong token, got %d", code)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Expected no refresh for unauthorized requests, got %d computations", n)
+	}
+
+	header = http.Header{"Authorization": {"Bearer secret"}}
+	code, data := getDashboardData(t, d, "/api/dashboard?refresh=1", header)
+	if code != http.StatusOK || data.Summary.TotalSpend != 2 {
+		t.Errorf("Expected freshly computed data, got %d %+v", code, data)
+	}
+}
+
+func TestDashboardRefreshDelayJitter(t *testing.T) {
+	d := newTestDashboard(nil)
+	d.SetRefreshInterval(10 * time.Minute)
+
+	for i := 0; i < 100; i++ {
+		if delay := d.nextRefreshDelay(); delay < 10*time.Minute || delay >= 11*time.Minute {
+			t.Fatalf("Expected a delay within 10%% above the interval, got %s", delay)
+		}
+	}
+}
+
+func TestDashboardPerformance_FallsBackToCacheOnFailure(t *testing.T) {
+	failing := false
+	requests := 0
+	var collector *MetricsCollector
+	collector = newFixtureCollector(t, "dashdaily", func(req *http.Request) *http.Response {
+		requests++
+		if failing {
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Status:     "500 Internal Server Error",
+				Body:       io.NopCloser(strings.NewReader(`{"error":{"message":"Service temporarily unavailable","code":2}}`)),
+				Header:     make(http.Header),
+			}
+		}
+		today := collector.Clock().Today().Format("2006-01-02")
+		return jsonResponse(`{"data":[{"date_start":"` + today + `","spend":"12","impressions":"100","clicks":"4"}]}`)
+	})
+	d := &Dashboard{metricsCollector: collector, dataDir: t.TempDir()}
+
+	getPerformance := func(target string) (int, []DailyPerformance) {
+		rec := httptest.NewRecorder()
+		d.handlePerformance(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+		var days []DailyPerformance
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &days); err != nil {
+				t.Fatalf("Error decoding response: %v", err)
+			}
+		}
+		return rec.Code, days
+	}
+
+	code, days := getPerformance("/api/performance?days=3")
+	if code != http.StatusOK || len(days) != 3 || days[2].Spend != 12 || days[0].Spend != 0 {
+		t.Fatalf("Expected three days ending today, got %d %+v", code, days)
+	}
+
+	// Within the cache TTL the data is served without asking the API again
+	if code, _ := getPerformance("/api/performance?days=3"); code != http.StatusOK || requests != 1 {
+		t.Errorf("Expected the fresh cache to be served, got %d after %d requests", code, requests)
+	}
+
+	// Once the cache has expired the API is asked again; when it fails the old data is served
+	cacheFile := filepath.Join(d.dataDir, "daily_performance_3.json")
+	expired := time.Now().Add(-2 * dailyPerformanceCacheTTL)
+	if err := os.Chtimes(cacheFile, expired, expired); err != nil {
+		t.Fatal(err)
+	}
+	failing = true
+	code, cached := getPerformance("/api/performance?days=3")
+	if code != http.StatusOK || len(cached) != 3 || cached[2].Spend != 12 || requests != 2 {
+		t.Errorf("Expected the expired cache after a failed request, got %d %+v", code, cached)
+	}
+
+	// Without a cache for the range the error is reported
+	if code, _ := getPerformance("/api/performance?days=7"); code != http.StatusInternalServerError {
+		t.Errorf("Expected an error without cached data, got %d", code)
+	}
+
+	for _, days := range []string{"0", "abc", "1000"} {
+		if code, _ := getPerformance("/api/performance?days=" + days); code != http.StatusBadRequest {
+			t.Errorf("Expected days=%s to be rejected, got %d", days, code)
+		}
+	}
+}
+
+func TestAverageCPM(t *testing.T) {
+	tests := []struct {
+		name        string
+		spend       float64
+		impressions int
+		want        float64
+	}{
+		{"spend over impressions", 25, 10000, 2.5},
+		{"fractional", 1, 3000, 1.0 / 3},
+		{"no impressions", 40, 0, 0},
+		{"nothing spent", 0, 500, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := averageCPM(tt.spend, tt.impressions); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Expected a CPM of %f, got %f", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDashboardCountCampaigns(t *testing.T) {
+	collector := newFixtureCollector(t, "dashcount", func(req *http.Request) *http.Response {
+		if req.URL.Path != "/v22.0/act_dashcount/campaigns" {
+			t.Errorf("Expected the account's campaigns, got %s", req.URL.Path)
+		}
+		return jsonResponse(`{"data":[{"id":"1","status":"ACTIVE"},{"id":"2","status":"PAUSED"},{"id":"3","status":"ACTIVE"}]}`)
+	})
+	d := &Dashboard{metricsCollector: collector}
+
+	total, active, err := d.countCampaigns(context.Background())
+	if err != nil {
+		t.Fatalf("countCampaigns failed: %v", err)
+	}
+	if total != 3 || active != 2 {
+		t.Errorf("Expected 3 campaigns with 2 active, got %d with %d active", total, active)
+	}
+}
+
+func TestDashboardAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		auth       DashboardAuth
+		setup      func(req *http.Request)
+		wantStatus int
+		challenges int
+	}{
+		{"no auth configured", DashboardAuth{}, func(req *http.Request) {}, http.StatusOK, 0},
+		{"missing credentials", DashboardAuth{User: "admin", Pass: "secret", Token: "tok"}, func(req *http.Request) {}, http.StatusUnauthorized, 2},
+		{"basic auth", DashboardAuth{User: "admin", Pass: "secret"}, func(req *http.Request) { req.SetBasicAuth("admin", "secret") }, http.StatusOK, 0},
+		{"wrong password", DashboardAuth{User: "admin", Pass: "secret"}, func(req *http.Request) { req.SetBasicAuth("admin", "nope") }, http.StatusUnauthorized, 1},
+		{"bearer token", DashboardAuth{Token: "tok"}, func(req *http.Request) { req.Header.Set("Authorization", "Bearer tok") }, http.StatusOK, 0},
+		{"wrong token", DashboardAuth{Token: "tok"}, func(req *http.Request) { req.Header.Set("Authorization", "Bearer other") }, http.StatusUnauthorized, 1},
+		{"refresh token", DashboardAuth{User: "admin", Pass: "secret"}, func(req *http.Request) { req.Header.Set("Authorization", "Bearer refresh") }, http.StatusOK, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDashboard(func() (*dashboardSnapshot, error) {
+				return testSnapshot(time.Now(), 10), nil
+			})
+			d.SetRefreshToken("refresh")
+			d.SetAuth(tt.auth)
+			if err := d.Refresh(); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, target := range []string{"/api/dashboard", "/static/app.js"} {
+				req := httptest.NewRequest(http.MethodGet, target, nil)
+				tt.setup(req)
+				rec := httptest.NewRecorder()
+				d.Handler().ServeHTTP(rec, req)
+
+				// Static files don't exist in the test, so only the auth status matters there
+				if target == "/api/dashboard" && rec.Code != tt.wantStatus {
+					t.Errorf("Expected %d for %s, got %d", tt.wantStatus, target, rec.Code)
+				}
+				if tt.wantStatus == http.StatusUnauthorized && rec.Code != http.StatusUnauthorized {
+					t.Errorf("Expected %s to require authentication, got %d", target, rec.Code)
+				}
+				if got := len(rec.Header().Values("WWW-Authenticate")); got != tt.challenges {
+					t.Errorf("Expected %d challenges for %s, got %d", tt.challenges, target, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDashboardTrends(t *testing.T) {
+	collector := newFixtureCollector(t, "dashtrends", func(req *http.Request) *http.Response {
+		t.Errorf("Expected trends to come from stored statistics, got a request for %s", req.URL.Path)
+		return jsonResponse(`{"data":[]}`)
+	})
+	d := &Dashboard{metricsCollector: collector}
+	d.SetStatisticsDir(t.TempDir())
+
+	getTrends := func(target string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		d.handleTrends(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+		var trends map[string]interface{}
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &trends); err != nil {
+				t.Fatalf("Error decoding response: %v", err)
+			}
+		}
+		return rec.Code, trends
+	}
+
+	// Before anything is collected the payload is valid but flagged
+	code, trends := getTrends("/api/trends?days=7")
+	if code != http.StatusOK || trends["no_data"] != true || trends["hint"] == "" || trends["total_spend"] != 0.0 {
+		t.Fatalf("Expected an empty payload flagged as having no data, got %d %v", code, trends)
+	}
+
+	if code, _ := getTrends("/api/trends?days=0"); code != http.StatusBadRequest {
+		t.Errorf("Expected days=0 to be rejected, got %d", code)
+	}
+
+	stats := NewStatisticsManager(collector, StorageTypeFile, d.statsDir)
+	today := collector.Clock().Today()
+	for i, spend := range []float64{10, 20} {
+		day := today.AddDate(0, 0, i-2)
+		err := stats.StoreDailyStatistics(day, []utils.CampaignPerformance{
+			{CampaignID: "111", Name: "Alpha", Impressions: 1000, Clicks: 10 * (i + 1), Spend: spend, LastUpdated: day},
+		})
+		if err != nil {
+			t.Fatalf("Error storing statistics: %v", err)
+		}
+	}
+
+	code, trends = getTrends("/api/trends?days=7")
+	if code != http.StatusOK || trends["no_data"] != nil || trends["total_spend"] != 30.0 {
+		t.Fatalf("Expected the stored statistics, got %d %v", code, trends)
+	}
+	spendTrend, _ := trends["trend_spend"].(map[string]interface{})
+	if values, _ := spendTrend["values"].([]interface{}); len(values) != 2 || spendTrend["change"] != 100.0 {
+		t.Errorf("Expected two days of spend doubling, got %v", spendTrend)
+	}
+}
+
+func TestDashboardStartStop(t *testing.T) {
+	d := newTestDashboard(func() (*dashboardSnapshot, error) {
+		return testSnapshot(time.Now(), 10), nil
+	})
+	d.dataDir = t.TempDir()
+	d.SetBindAddress("127.0.0.1")
+
+	// Starting again after a stop must not collide with the routes of the first run
+	for run := 1; run <= 2; run++ {
+		started := make(chan error, 1)
+		go func() { started <- d.Start() }()
+
+		var addr string
+		for deadline := time.Now().Add(5 * time.Second); addr == "" && time.Now().Before(deadline); {
+			d.serverMu.Lock()
+			if d.server != nil {
+				addr = d.server.Addr
+			}
+			d.serverMu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+		}
+		if addr == "" {
+			t.Fatalf("Run %d: the dashboard did not start", run)
+		}
+
+		resp, err := http.Get("http://" + addr + "/api/dashboard")
+		if err != nil {
+			t.Fatalf("Run %d: %v", run, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Run %d: expected 200, got %d", run, resp.StatusCode)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := d.Stop(ctx); err != nil {
+			t.Errorf("Run %d: Stop failed: %v", run, err)
+		}
+		cancel()
+		if err := <-started; err != nil {
+			t.Errorf("Run %d: expected Start to return nil after Stop, got %v", run, err)
+		}
+	}
+
+	// Stopping a stopped dashboard does nothing
+	if err := d.Stop(context.Background()); err != nil {
+		t.Errorf("Expected no error stopping a stopped dashboard, got %v", err)
+	}
+}
+
+// readStreamData returns the data of the next event of a dashboard stream, skipping comments
+func readStreamData(t *testing.T, reader *bufio.Reader) DashboardData {
+	t.Helper()
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Error reading the stream: %v", err)
+		}
+		payload, ok := strings.CutPrefix(strings.TrimSpace(line), "data: ")
+		if !ok {
+			continue
+		}
+		var data DashboardData
+		if err := json.Unmarshal([]byte(payload), &data); err != nil {
+			t.Fatalf("Error decoding event %q: %v", payload, err)
+		}
+		return data
+	}
+}
+
+func TestDashboardStream(t *testing.T) {
+	var spend atomic.Int64
+	spend.Store(10)
+	d := newTestDashboard(func() (*dashboardSnapshot, error) {
+		return testSnapshot(time.Now(), float64(spend.Load())), nil
+	})
+	d.SetStreamInterval(20 * time.Millisecond)
+	if err := d.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	handlerDone := make(chan struct{})
+	handler := d.Handler()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+		if r.URL.Path == "/api/stream" {
+			close(handlerDone)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/stream", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Expected Content-Type text/event-stream, got %q", got)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	if data := readStreamData(t, reader); data.Summary.TotalSpend != 10 {
+		t.Errorf("Expected the first event to carry spend 10, got %v", data.Summary.TotalSpend)
+	}
+
+	// The next events carry the refreshed data
+	spend.Store(20)
+	if err := d.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for readStreamData(t, reader).Summary.TotalSpend != 20 {
+		if time.Now().After(deadline) {
+			t.Fatal("The stream never sent the refreshed data")
+		}
+	}
+
+	// The handler returns when the client disconnects
+	cancel()
+	select {
+	case <-handlerDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The stream handler kept running after the client disconnected")
+	}
+}
+
+func TestDashboardStream_NotReady(t *testing.T) {
+	d := newTestDashboard(nil)
+
+	// Without a snapshot the event is a comment, which EventSource ignores
+	rec := httptest.NewRecorder()
+	d.writeStreamEvent(rec)
+	if got := rec.Body.String(); !strings.HasPrefix(got, ":") {
+		t.Errorf("Expected a comment before the data is ready, got %q", got)
+	}
+}
+
+func TestDashboardStopEndsStreams(t *testing.T) {
+	d := newTestDashboard(func() (*dashboardSnapshot, error) {
+		return testSnapshot(time.Now(), 10), nil
+	})
+	d.dataDir = t.TempDir()
+	d.SetBindAddress("127.0.0.1")
+
+	started := make(chan error, 1)
+	go func() { started <- d.Start() }()
+
+	var addr string
+	for deadline := time.Now().Add(5 * time.Second); addr == "" && time.Now().Before(deadline); {
+		d.serverMu.Lock()
+		if d.server != nil {
+			addr = d.server.Addr
+		}
+		d.serverMu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if addr == "" {
+		t.Fatal("The dashboard did not start")
+	}
+
+	resp, err := http.Get("http://" + addr + "/api/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	readStreamData(t, bufio.NewReader(resp.Body))
+
+	// An open stream must not hold the shutdown until its timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Stop(ctx); err != nil {
+		t.Errorf("Stop failed with an open stream: %v", err)
+	}
+	if err := <-started; err != nil {
+		t.Errorf("Expected Start to return nil after Stop, got %v", err)
+	}
+}
+
+func TestDashboardStopWaitsForRequests(t *testing.T) {
+	release := make(chan struct{})
+	refreshing := make(chan struct{})
+	var calls int32
+	d := newTestDashboard(func() (*dashboardSnapshot, error) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			close(refreshing)
+			<-release
+		}
+		return testSnapshot(time.Now(), 10), nil
+	})
+	d.dataDir = t.TempDir()
+	d.SetBindAddress("127.0.0.1")
+	d.SetRefreshToken("secret")
+
+	started := make(chan error, 1)
+	go func() { started <- d.Start() }()
+
+	var addr string
+	for deadline := time.Now().Add(5 * time.Second); addr == "" && time.Now().Before(deadline); {
+		d.serverMu.Lock()
+		if d.server != nil {
+			addr = d.server.Addr
+		}
+		d.serverMu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if addr == "" {
+		t.Fatal("The dashboard did not start")
+	}
+
+	// A forced refresh keeps the request in progress until released
+	responses := make(chan int, 1)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/api/dashboard?refresh=1", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			responses <- 0
+			return
+		}
+		resp.Body.Close()
+		responses <- resp.StatusCode
+	}()
+	<-refreshing
+
+	stopped := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		stopped <- d.Stop(ctx)
+	}()
+
+	// Start must not return while the request is still being served
+	select {
+	case err := <-started:
+		t.Fatalf("Start returned before the request completed: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	if status := <-responses; status != http.StatusOK {
+		t.Errorf("Expected the request in progress to complete with 200, got %d", status)
+	}
+	if err := <-stopped; err != nil {
+		t.Errorf("Stop failed: %v", err)
+	}
+	if err := <-started; err != nil {
+		t.Errorf("Expected Start to return nil after Stop, got %v", err)
+	}
+}
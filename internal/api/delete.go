@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// Object types that can be deleted
+const (
+	ObjectTypeCampaign = "campaign"
+	ObjectTypeAdSet    = "adset"
+	ObjectTypeAd       = "ad"
+)
+
+// DeletionPlan describes an object and the children that are deleted with it
+type DeletionPlan struct {
+	ObjectType string                `json:"object_type"`
+	ID         string                `json:"id"`
+	Name       string                `json:"name"`
+	Status     string                `json:"status"`
+	Labels     []string              `json:"labels,omitempty"`
+	AdSets     []models.AdSetDetails `json:"adsets,omitempty"`
+	Ads        []models.AdDetails    `json:"ads,omitempty"`
+}
+
+// PlanDeletion fetches an object and the children deleting it would remove
+func (c *Client) PlanDeletion(objectType, objectID string) (*DeletionPlan, error) {
+	plan := &DeletionPlan{ObjectType: objectType, ID: objectID}
+
+	switch objectType {
+	case ObjectTypeCampaign:
+		details, err := c.GetCampaignDetails(objectID)
+		if err != nil {
+			return nil, err
+		}
+		plan.Name = details.Name
+		plan.Status = details.Status
+		plan.Labels = details.Labels
+		plan.AdSets = details.AdSets
+		plan.Ads = details.Ads
+
+	case ObjectTypeAdSet, ObjectTypeAd:
+		fields := "id,name,status,adlabels{name}"
+		if objectType == ObjectTypeAdSet {
+			fields += ",ads{id,name,status}"
+		}
+
+		object, err := c.getObject(objectID, fields)
+		if err != nil {
+			return nil, err
+		}
+		plan.Name = getString(object, "name")
+		plan.Status = getString(object, "status")
+		plan.Labels = getLabelNames(object)
+
+		// The plan lists every ad that goes with the ad set, not just the first page
+		ads, err := c.allEdgeData(context.Background(), object, "ads")
+		if err != nil {
+			return nil, err
+		}
+		for _, ad := range ads {
+			plan.Ads = append(plan.Ads, models.AdDetails{
+				ID:      getString(ad, "id"),
+				Name:    getString(ad, "name"),
+				Status:  getString(ad, "status"),
+				AdSetID: objectID,
+			})
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown object type %q", objectType)
+	}
+
+	return plan, nil
+}
+
+// Delete deletes a campaign, ad set or ad
+func (c *Client) Delete(objectType, objectID string) error {
+	switch objectType {
+	case ObjectTypeCampaign:
+		return c.DeleteCampaign(objectID)
+	case ObjectTypeAdSet:
+		return c.DeleteAdSet(objectID)
+	case ObjectTypeAd:
+		return c.DeleteAd(objectID)
+	}
+	return fmt.Errorf("unknown object type %q", objectType)
+}
+
+// getObject fetches the given fields of an object by ID
+func (c *Client) getObject(objectID, fields string) (map[string]interface{}, error) {
+	return c.getObjectContext(context.Background(), objectID, fields)
+}
+
+// getObjectContext fetches the given fields of an object by ID
+func (c *Client) getObjectContext(ctx context.Context, objectID, fields string) (map[string]interface{}, error) {
+	params := url.Values{}
+	params.Set("fields", fields)
+
+	req, err := c.auth.GetAuthenticatedRequestContext(ctx, objectID, params)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
+	}
+
+	var object map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&object); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return object, nil
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestPlanDeletion_AdSet(t *testing.T) {
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			if req.Method != http.MethodGet || !strings.HasSuffix(req.URL.Path, "/42") {
+				t.Errorf("Unexpected request: %s %s", req.Method, req.URL)
+			}
+			if fields := req.URL.Query().Get("fields"); !strings.Contains(fields, "ads{") {
+				t.Errorf("Expected the ads of the ad set to be requested, got fields %q", fields)
+			}
+			return jsonResponse(`{"id":"42","name":"Broad","status":"ACTIVE",
+				"adlabels":{"data":[{"id":"7","name":"fbads:v1"}]},
+				"ads":{"data":[{"id":"421","name":"Hero","status":"ACTIVE"},{"id":"422","name":"Reminder","status":"PAUSED"}]}}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	plan, err := client.PlanDeletion(ObjectTypeAdSet, "42")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if plan.Name != "Broad" || plan.Status != "ACTIVE" {
+		t.Errorf("Expected ad set Broad (ACTIVE), got %+v", plan)
+	}
+	if len(plan.Labels) != 1 || plan.Labels[0] != "fbads:v1" {
+		t.Errorf("Expected the fbads:v1 label, got %v", plan.Labels)
+	}
+	if len(plan.Ads) != 2 || plan.Ads[1].ID != "422" || plan.Ads[1].AdSetID != "42" {
+		t.Errorf("Expected both ads of the ad set, got %+v", plan.Ads)
+	}
+}
+
+func TestDelete_UsesDeleteMethod(t *testing.T) {
+	var requests []string
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			requests = append(requests, req.Method+" "+strings.TrimPrefix(req.URL.Path, "/v22.0/"))
+			if req.URL.Query().Get("access_token") != "token" {
+				t.Errorf("Expected an authenticated request, got %s", req.URL)
+			}
+			return jsonResponse(`{"success":true}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	for _, objectType := range []string{ObjectTypeCampaign, ObjectTypeAdSet, ObjectTypeAd} {
+		if err := client.Delete(objectType, "42"); err != nil {
+			t.Errorf("Unexpected error deleting %s: %v", objectType, err)
+		}
+	}
+	if len(requests) != 3 || requests[0] != "DELETE 42" || requests[2] != "DELETE 42" {
+		t.Errorf("Expected three DELETE requests, got %v", requests)
+	}
+
+	if err := client.Delete("creative", "42"); err == nil {
+		t.Errorf("Expected an error for an unknown object type")
+	}
+}
@@ -0,0 +1,281 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// DeliveryNode is a campaign, ad set or ad with what keeps it from delivering
+type DeliveryNode struct {
+	ObjectType      string         `json:"object_type"`
+	ID              string         `json:"id"`
+	Name            string         `json:"name"`
+	Status          string         `json:"status"`           // Configured status
+	EffectiveStatus string         `json:"effective_status"` // Status after the parents and review are taken into account
+	DailyBudget     float64        `json:"daily_budget,omitempty"`
+	LifetimeBudget  float64        `json:"lifetime_budget,omitempty"`
+	EndTime         time.Time      `json:"end_time,omitempty"`
+	LearningStage   string         `json:"learning_stage,omitempty"`  // Ad sets: LEARNING, SUCCESS or FAIL
+	ReviewFeedback  []string       `json:"review_feedback,omitempty"` // Ads: why review rejected the ad
+	Reasons         []string       `json:"reasons,omitempty"`         // Why the object will not deliver
+	Notes           []string       `json:"notes,omitempty"`           // Conditions that limit but do not stop delivery
+	Children        []DeliveryNode `json:"children,omitempty"`
+}
+
+// Delivering reports whether nothing keeps the object from delivering
+func (n DeliveryNode) Delivering() bool {
+	return len(n.Reasons) == 0
+}
+
+// GetDeliveryTree fetches a campaign with its ad sets and ads and the state that decides their delivery
+func (c *Client) GetDeliveryTree(campaignID string) (*DeliveryNode, error) {
+	fields := strings.Join([]string{
+		"id,name,status,effective_status,daily_budget,lifetime_budget,stop_time",
+		"adsets{id,name,status,effective_status,daily_budget,lifetime_budget,end_time,learning_stage_info}",
+		"ads{id,name,status,effective_status,adset_id,ad_review_feedback}",
+	}, ",")
+
+	object, err := c.getObject(campaignID, fields)
+	if err != nil {
+		return nil, err
+	}
+	adSets, err := c.allEdgeData(context.Background(), object, "adsets")
+	if err != nil {
+		return nil, err
+	}
+	ads, err := c.allEdgeData(context.Background(), object, "ads")
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	tree := &DeliveryNode{
+		ObjectType:      ObjectTypeCampaign,
+		ID:              getString(object, "id"),
+		Name:            getString(object, "name"),
+		Status:          getString(object, "status"),
+		EffectiveStatus: getString(object, "effective_status"),
+		DailyBudget:     getFloat(object, "daily_budget"),
+		LifetimeBudget:  getFloat(object, "lifetime_budget"),
+		EndTime:         parseTimeField("stop_time", getString(object, "stop_time"), &warnings),
+	}
+
+	for _, adSet := range adSets {
+		node := DeliveryNode{
+			ObjectType:      ObjectTypeAdSet,
+			ID:              getString(adSet, "id"),
+			Name:            getString(adSet, "name"),
+			Status:          getString(adSet, "status"),
+			EffectiveStatus: getString(adSet, "effective_status"),
+			DailyBudget:     getFloat(adSet, "daily_budget"),
+			LifetimeBudget:  getFloat(adSet, "lifetime_budget"),
+		}
+		node.EndTime = parseTimeField("adsets["+node.ID+"].end_time", getString(adSet, "end_time"), &warnings)
+		if learning, ok := adSet["learning_stage_info"].(map[string]interface{}); ok {
+			node.LearningStage = getString(learning, "status")
+		}
+		tree.Children = append(tree.Children, node)
+	}
+
+	for _, ad := range ads {
+		node := DeliveryNode{
+			ObjectType:      ObjectTypeAd,
+			ID:              getString(ad, "id"),
+			Name:            getString(ad, "name"),
+			Status:          getString(ad, "status"),
+			EffectiveStatus: getString(ad, "effective_status"),
+		}
+		// Review feedback is keyed by placement, e.g. {"global": {"Circumventing systems": "..."}}
+		if feedback, ok := ad["ad_review_feedback"].(map[string]interface{}); ok {
+			for _, rawReasons := range feedback {
+				if reasons, ok := rawReasons.(map[string]interface{}); ok {
+					for reason := range reasons {
+						node.ReviewFeedback = append(node.ReviewFeedback, reason)
+					}
+				}
+			}
+			sort.Strings(node.ReviewFeedback)
+		}
+
+		adSetID := getString(ad, "adset_id")
+		for i := range tree.Children {
+			if tree.Children[i].ID == adSetID {
+				tree.Children[i].Children = append(tree.Children[i].Children, node)
+				break
+			}
+		}
+	}
+
+	return tree, nil
+}
+
+// edgeData returns the objects of an edge expanded in a field list, like adsets{...}
+func edgeData(object map[string]interface{}, edge string) []map[string]interface{} {
+	var objects []map[string]interface{}
+	if container, ok := object[edge].(map[string]interface{}); ok {
+		data, _ := container["data"].([]interface{})
+		for _, raw := range data {
+			if item, ok := raw.(map[string]interface{}); ok {
+				objects = append(objects, item)
+			}
+		}
+	}
+	return objects
+}
+
+// allEdgeData returns every object of an edge expanded in a field list. The API returns only the
+// first page of a nested edge, so the remaining pages are fetched through its paging links.
+func (c *Client) allEdgeData(ctx context.Context, object map[string]interface{}, edge string) ([]map[string]interface{}, error) {
+	objects := edgeData(object, edge)
+
+	next := ""
+	if container, ok := object[edge].(map[string]interface{}); ok {
+		if paging, ok := container["paging"].(map[string]interface{}); ok {
+			next = getString(paging, "next")
+		}
+	}
+
+	for next != "" {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", next, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+
+		var page struct {
+			Data   []map[string]interface{} `json:"data"`
+			Paging struct {
+				Next string `json:"next"`
+			} `json:"paging"`
+		}
+		if err := doJSON(c.doWithRetry, req, &page); err != nil {
+			return nil, fmt.Errorf("error fetching %s: %w", edge, err)
+		}
+
+		objects = append(objects, page.Data...)
+		next = page.Paging.Next
+	}
+
+	return objects, nil
+}
+
+// ExplainDelivery fills in the reasons each object of a delivery tree will not deliver at the given time:
+// its own or a parent's status, a past end date, a missing budget, review, or no active children.
+// Ad sets in the learning phase get a note since they still deliver.
+func ExplainDelivery(tree *DeliveryNode, now time.Time) {
+	campaignBudget := tree.DailyBudget > 0 || tree.LifetimeBudget > 0
+	campaignReasons := ownDeliveryReasons(tree, now)
+
+	activeAdSets := 0
+	for i := range tree.Children {
+		adSet := &tree.Children[i]
+		adSetReasons := ownDeliveryReasons(adSet, now)
+		if !campaignBudget && adSet.DailyBudget == 0 && adSet.LifetimeBudget == 0 {
+			adSetReasons = append(adSetReasons, "ad set has zero budget and its campaign has none either")
+		}
+		switch adSet.LearningStage {
+		case "LEARNING":
+			adSet.Notes = append(adSet.Notes, "in the learning phase, delivery and costs are not stable yet")
+		case "FAIL":
+			adSet.Notes = append(adSet.Notes, "learning limited: not enough conversions to exit the learning phase")
+		}
+
+		activeAds := 0
+		for j := range adSet.Children {
+			ad := &adSet.Children[j]
+			ad.Reasons = append(ad.Reasons, inheritedReasons(campaignReasons, "campaign")...)
+			ad.Reasons = append(ad.Reasons, inheritedReasons(adSetReasons, "ad set")...)
+			adReasons := ownDeliveryReasons(ad, now)
+			ad.Reasons = append(ad.Reasons, adReasons...)
+			if len(adReasons) == 0 {
+				activeAds++
+			}
+		}
+
+		adSet.Reasons = append(adSet.Reasons, inheritedReasons(campaignReasons, "campaign")...)
+		adSet.Reasons = append(adSet.Reasons, adSetReasons...)
+		switch {
+		case len(adSet.Children) == 0:
+			adSet.Reasons = append(adSet.Reasons, "ad set has no ads")
+		case activeAds == 0:
+			adSet.Reasons = append(adSet.Reasons, "paused children: none of its ads can deliver")
+		}
+
+		if len(adSetReasons) == 0 && activeAds > 0 {
+			activeAdSets++
+		}
+	}
+
+	tree.Reasons = append(tree.Reasons, campaignReasons...)
+	switch {
+	case len(tree.Children) == 0:
+		tree.Reasons = append(tree.Reasons, "campaign has no ad sets")
+	case activeAdSets == 0:
+		tree.Reasons = append(tree.Reasons, "paused children: none of its ad sets can deliver")
+	}
+}
+
+// ownDeliveryReasons returns why an object will not deliver regardless of its parents and children
+func ownDeliveryReasons(node *DeliveryNode, now time.Time) []string {
+	label := objectLabel(node.ObjectType)
+
+	var reasons []string
+	if !statusActive(node.Status) {
+		reasons = append(reasons, fmt.Sprintf("%s is %s", label, node.Status))
+	}
+	if !node.EndTime.IsZero() && node.EndTime.Before(now) {
+		reasons = append(reasons, fmt.Sprintf("%s ended on %s", label, node.EndTime.Format("2006-01-02")))
+	}
+
+	switch node.EffectiveStatus {
+	case "DISAPPROVED":
+		reason := label + " was disapproved in review"
+		if len(node.ReviewFeedback) > 0 {
+			reason += ": " + strings.Join(node.ReviewFeedback, "; ")
+		}
+		reasons = append(reasons, reason)
+	case "PENDING_REVIEW":
+		reasons = append(reasons, label+" is pending review")
+	case "WITH_ISSUES":
+		reasons = append(reasons, label+" has issues that stop delivery")
+	}
+	return reasons
+}
+
+// inheritedReasons rewords a parent's own reasons for its children
+func inheritedReasons(reasons []string, parent string) []string {
+	inherited := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		if strings.HasPrefix(reason, parent+" is ") {
+			inherited = append(inherited, "paused parent: "+reason)
+		} else {
+			inherited = append(inherited, "parent "+reason)
+		}
+	}
+	return inherited
+}
+
+// statusActive reports whether a configured status lets an object deliver
+func statusActive(status string) bool {
+	return models.CampaignStatus(status) == models.CampaignStatusActive
+}
+
+// objectLabel returns how an object type is named in delivery reasons
+func objectLabel(objectType string) string {
+	switch objectType {
+	case ObjectTypeAdSet:
+		return "ad set"
+	case ObjectTypeAd:
+		return "ad"
+	}
+	return "campaign"
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+// deliveryFixture is a duplicated campaign that was activated while its children stayed paused,
+// plus an ad set that ended, one without a budget and a disapproved ad
+const deliveryFixture = `{
+	"id": "1", "name": "Spring copy", "status": "ACTIVE", "effective_status": "ACTIVE",
+	"adsets": {"data": [
+		{"id": "11", "name": "Paused", "status": "PAUSED", "effective_status": "PAUSED", "daily_budget": "1000"},
+		{"id": "12", "name": "Running", "status": "ACTIVE", "effective_status": "ACTIVE", "daily_budget": "1000",
+			"learning_stage_info": {"status": "LEARNING"}},
+		{"id": "13", "name": "Ended", "status": "ACTIVE", "effective_status": "ACTIVE", "daily_budget": "1000",
+			"end_time": "2024-05-31T23:59:59+0000"},
+		{"id": "14", "name": "No budget", "status": "ACTIVE", "effective_status": "ACTIVE"}
+	]},
+	"ads": {"data": [
+		{"id": "111", "name": "Copied ad", "status": "ACTIVE", "effective_status": "ADSET_PAUSED", "adset_id": "11"},
+		{"id": "121", "name": "Good ad", "status": "ACTIVE", "effective_status": "ACTIVE", "adset_id": "12"},
+		{"id": "122", "name": "Rejected ad", "status": "ACTIVE", "effective_status": "DISAPPROVED", "adset_id": "12",
+			"ad_review_feedback": {"global": {"Misleading claims": "..."}}},
+		{"id": "131", "name": "Late ad", "status": "ACTIVE", "effective_status": "ACTIVE", "adset_id": "13"},
+		{"id": "141", "name": "Paused ad", "status": "PAUSED", "effective_status": "PAUSED", "adset_id": "14"}
+	]}
+}`
+
+func TestExplainDelivery(t *testing.T) {
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			return jsonResponse(deliveryFixture)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "123",
+	}
+
+	tree, err := client.GetDeliveryTree("1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ExplainDelivery(tree, time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC))
+
+	if len(tree.Children) != 4 || len(tree.Children[1].Children) != 2 {
+		t.Fatalf("Expected 4 ad sets with the ads under their ad set, got %+v", tree)
+	}
+
+	tests := []struct {
+		name    string
+		node    DeliveryNode
+		reasons []string
+	}{
+		{name: "campaign", node: *tree, reasons: nil},
+		{name: "paused ad set", node: tree.Children[0], reasons: []string{"ad set is PAUSED"}},
+		{name: "ad under paused ad set", node: tree.Children[0].Children[0], reasons: []string{"paused parent: ad set is PAUSED"}},
+		{name: "running ad set", node: tree.Children[1], reasons: nil},
+		{name: "running ad", node: tree.Children[1].Children[0], reasons: nil},
+		{name: "disapproved ad", node: tree.Children[1].Children[1], reasons: []string{"ad was disapproved in review: Misleading claims"}},
+		{name: "ended ad set", node: tree.Children[2], reasons: []string{"ad set ended on 2024-05-31"}},
+		{name: "ad under ended ad set", node: tree.Children[2].Children[0], reasons: []string{"parent ad set ended on 2024-05-31"}},
+		{name: "ad set without budget", node: tree.Children[3], reasons: []string{
+			"ad set has zero budget and its campaign has none either",
+			"paused children: none of its ads can deliver",
+		}},
+		{name: "paused ad", node: tree.Children[3].Children[0], reasons: []string{
+			"parent ad set has zero budget and its campaign has none either",
+			"ad is PAUSED",
+		}},
+	}
+
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.node.Reasons, tt.reasons) {
+			t.Errorf("%s: expected reasons %q, got %q", tt.name, tt.reasons, tt.node.Reasons)
+		}
+	}
+
+	if notes := tree.Children[1].Notes; len(notes) != 1 {
+		t.Errorf("Expected a learning phase note for the running ad set, got %q", notes)
+	}
+}
+
+func TestExplainDelivery_PausedChildren(t *testing.T) {
+	// A duplicate activated in Ads Manager: the campaign is active but everything under it is paused
+	tree := &DeliveryNode{ObjectType: ObjectTypeCampaign, Status: "ACTIVE", DailyBudget: 5000, Children: []DeliveryNode{
+		{ObjectType: ObjectTypeAdSet, Status: "PAUSED", Children: []DeliveryNode{{ObjectType: ObjectTypeAd, Status: "PAUSED"}}},
+	}}
+	ExplainDelivery(tree, time.Now())
+
+	if tree.Delivering() || tree.Reasons[0] != "paused children: none of its ad sets can deliver" {
+		t.Errorf("Expected the campaign to be blocked by its paused children, got %q", tree.Reasons)
+	}
+	// The campaign budget covers the ad set
+	if reasons := tree.Children[0].Reasons; len(reasons) != 2 || reasons[0] != "ad set is PAUSED" {
+		t.Errorf("Expected the ad set to be paused with paused children, got %q", reasons)
+	}
+}
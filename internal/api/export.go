@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+// InsightsWriter writes daily insights rows in an export format as they arrive
+type InsightsWriter interface {
+	// WriteRows writes one page of rows
+	WriteRows(rows []utils.CampaignPerformance) error
+	// Flush writes any buffered data to the underlying writer
+	Flush() error
+}
+
+// NewInsightsWriter returns an InsightsWriter for the given format ("csv" or "ndjson")
+func NewInsightsWriter(w io.Writer, format string) (InsightsWriter, error) {
+	switch format {
+	case "csv":
+		return newCSVInsightsWriter(w), nil
+	case "ndjson", "json":
+		return newNDJSONInsightsWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unsupported insights format: %s (use csv or ndjson)", format)
+	}
+}
+
+// insightsCSVHeader lists the columns of a CSV insights export
+var insightsCSVHeader = []string{"date", "campaign_id", "campaign_name", "impressions", "clicks", "spend", "conversions", "ctr", "cpm", "cpc"}
+
+// csvInsightsWriter writes insights rows as CSV
+type csvInsightsWriter struct {
+	writer        *csv.Writer
+	record        []string
+	headerWritten bool
+}
+
+func newCSVInsightsWriter(w io.Writer) *csvInsightsWriter {
+	return &csvInsightsWriter{
+		writer: csv.NewWriter(w),
+		record: make([]string, len(insightsCSVHeader)),
+	}
+}
+
+// WriteRows writes one page of rows, preceded by the header on the first call
+func (c *csvInsightsWriter) WriteRows(rows []utils.CampaignPerformance) error {
+	if !c.headerWritten {
+		if err := c.writer.Write(insightsCSVHeader); err != nil {
+			return fmt.Errorf("error writing CSV header: %w", err)
+		}
+		c.headerWritten = true
+	}
+
+	for _, row := range rows {
+		c.record[0] = row.LastUpdated.Format("2006-01-02")
+		c.record[1] = row.CampaignID
+		c.record[2] = row.Name
+		c.record[3] = strconv.Itoa(row.Impressions)
+		c.record[4] = strconv.Itoa(row.Clicks)
+		c.record[5] = strconv.FormatFloat(row.Spend, 'f', 2, 64)
+		c.record[6] = strconv.Itoa(row.Conversions)
+		c.record[7] = strconv.FormatFloat(row.CTR, 'f', 2, 64)
+		c.record[8] = strconv.FormatFloat(row.CPM, 'f', 2, 64)
+		c.record[9] = strconv.FormatFloat(row.CPC, 'f', 2, 64)
+
+		if err := c.writer.Write(c.record); err != nil {
+			return fmt.Errorf("error writing CSV row: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Flush writes buffered rows, including the header if no rows were written
+func (c *csvInsightsWriter) Flush() error {
+	if !c.headerWritten {
+		if err := c.WriteRows(nil); err != nil {
+			return err
+		}
+	}
+
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return fmt.Errorf("error writing CSV: %w", err)
+	}
+	return nil
+}
+
+// ndjsonInsightsWriter writes insights rows as newline-delimited JSON
+type ndjsonInsightsWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newNDJSONInsightsWriter(w io.Writer) *ndjsonInsightsWriter {
+	buffer := bufio.NewWriter(w)
+	return &ndjsonInsightsWriter{
+		buffer:  buffer,
+		encoder: json.NewEncoder(buffer),
+	}
+}
+
+// WriteRows writes one JSON object per row
+func (n *ndjsonInsightsWriter) WriteRows(rows []utils.CampaignPerformance) error {
+	for i := range rows {
+		if err := n.encoder.Encode(&rows[i]); err != nil {
+			return fmt.Errorf("error writing JSON row: %w", err)
+		}
+	}
+	return nil
+}
+
+// Flush writes buffered rows
+func (n *ndjsonInsightsWriter) Flush() error {
+	if err := n.buffer.Flush(); err != nil {
+		return fmt.Errorf("error writing JSON: %w", err)
+	}
+	return nil
+}
+
+// ExportDailyInsights streams daily campaign insights for timeRange to w.
+// Every API page is written as soon as it arrives, so memory use is bounded
+// by the page size rather than by the size of the result. It returns the
+// number of rows written.
+func (m *MetricsCollector) ExportDailyInsights(w io.Writer, timeRange TimeRange, format string) (int, error) {
+	return m.ExportDailyInsightsContext(context.Background(), w, timeRange, format)
+}
+
+// ExportDailyInsightsContext streams daily campaign insights like ExportDailyInsights,
+// stopping when ctx is cancelled
+func (m *MetricsCollector) ExportDailyInsightsContext(ctx context.Context, w io.Writer, timeRange TimeRange, format string) (int, error) {
+	writer, err := NewInsightsWriter(w, format)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	err = m.StreamDailyCampaignMetricsContext(ctx, timeRange, func(page []utils.CampaignPerformance) error {
+		rows += len(page)
+		return writer.WriteRows(page)
+	})
+	if err != nil {
+		return rows, err
+	}
+
+	return rows, writer.Flush()
+}
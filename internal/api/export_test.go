@@ -0,0 +1,191 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// largeStatistics returns aggregate statistics for n synthetic campaigns
+func largeStatistics(n int) *AggregateStatistics {
+	stats := &AggregateStatistics{
+		CampaignStats:    make(map[string]CampaignStats, n),
+		TotalImpressions: n * 1000,
+		TotalClicks:      n * 20,
+		TotalSpend:       float64(n) * 12.5,
+		AvgCTR:           2,
+		AvgCPM:           12.5,
+		AvgCPC:           0.625,
+	}
+
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("Campaign %d", i)
+		if i%10 == 0 {
+			name = fmt.Sprintf(`Promo "%d", spring`, i)
+		}
+		id := fmt.Sprintf("%09d", i)
+		stats.CampaignStats[id] = CampaignStats{
+			CampaignID:       id,
+			Name:             name,
+			TotalImpressions: 1000 + i,
+			TotalClicks:      20 + i%7,
+			TotalSpend:       12.5 + float64(i%100)/3,
+			AvgCTR:           2.004,
+			AvgCPM:           12.345,
+			AvgCPC:           0.625,
+			TotalConversions: i % 5,
+			AvgCPA:           float64(i%5) * 1.115,
+			ROI:              -100,
+		}
+	}
+
+	return stats
+}
+
+// naiveStatisticsCSV builds the whole CSV output in memory, one formatted string per line
+func naiveStatisticsCSV(stats *AggregateStatistics) string {
+	ids := make([]string, 0, len(stats.CampaignStats))
+	for id := range stats.CampaignStats {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	output := "Campaign ID,Campaign Name,Impressions,Clicks,CTR (%),Spend ($),CPM ($),CPC ($),Conversions,CPA ($),ROI (%)\n"
+	var lines []string
+	for _, id := range ids {
+		campaign := stats.CampaignStats[id]
+		name := campaign.Name
+		if strings.ContainsAny(name, "\",\n\r") {
+			name = `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+		}
+		lines = append(lines, fmt.Sprintf("%s,%s,%d,%d,%.2f,%.2f,%.2f,%.2f,%d,%.2f,%.2f\n",
+			campaign.CampaignID, name, campaign.TotalImpressions, campaign.TotalClicks,
+			campaign.AvgCTR, campaign.TotalSpend, campaign.AvgCPM, campaign.AvgCPC,
+			campaign.TotalConversions, campaign.AvgCPA, campaign.ROI))
+	}
+	output += strings.Join(lines, "")
+
+	return output + fmt.Sprintf("\nTOTAL,All Campaigns,%d,%d,%.2f,%.2f,%.2f,%.2f,%d,%.2f,\n",
+		stats.TotalImpressions, stats.TotalClicks, stats.AvgCTR, stats.TotalSpend,
+		stats.AvgCPM, stats.AvgCPC, stats.TotalConversions, stats.AvgCPA)
+}
+
+// allocatedBytes returns the bytes allocated while running fn
+func allocatedBytes(fn func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	fn()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+func TestWriteStatisticsCSV_LargeResult(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping 100k row export in short mode")
+	}
+
+	stats := largeStatistics(100000)
+
+	// The streamed output matches the in-memory result byte for byte
+	var streamed bytes.Buffer
+	if err := WriteStatisticsCSV(&streamed, stats); err != nil {
+		t.Fatalf("WriteStatisticsCSV failed: %v", err)
+	}
+	expected := naiveStatisticsCSV(stats)
+	if streamed.String() != expected {
+		t.Fatalf("Streamed output differs from the in-memory result (%d vs %d bytes)", streamed.Len(), len(expected))
+	}
+
+	// Streaming allocates a small fraction of building the output in memory
+	var writeErr error
+	streamingBytes := allocatedBytes(func() {
+		writeErr = WriteStatisticsCSV(io.Discard, stats)
+	})
+	if writeErr != nil {
+		t.Fatalf("WriteStatisticsCSV failed: %v", writeErr)
+	}
+	naiveBytes := allocatedBytes(func() {
+		expected = naiveStatisticsCSV(stats)
+	})
+
+	if streamingBytes*4 > naiveBytes {
+		t.Errorf("Expected streaming to allocate well under the naive approach, got %d vs %d bytes", streamingBytes, naiveBytes)
+	}
+	// The remaining allocations are the sorted campaign IDs, not the output
+	if streamingBytes > uint64(len(expected))/2 {
+		t.Errorf("Expected streaming allocations to stay below half the output size, got %d bytes for %d bytes of output", streamingBytes, len(expected))
+	}
+}
+
+func BenchmarkWriteStatisticsCSV(b *testing.B) {
+	stats := largeStatistics(100000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := WriteStatisticsCSV(io.Discard, stats); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNaiveStatisticsCSV(b *testing.B) {
+	stats := largeStatistics(100000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		naiveStatisticsCSV(stats)
+	}
+}
+
+func TestExportDailyInsights(t *testing.T) {
+	pages := 0
+	collector := newFixtureCollector(t, "export-insights", func(req *http.Request) *http.Response {
+		pages++
+		if req.URL.Query().Get("after") == "" {
+			return jsonResponse(`{"data":[` +
+				`{"campaign_id":"111","campaign_name":"Alpha, Inc","spend":"10.5","impressions":"1000","clicks":"20","date_start":"2024-01-01"},` +
+				`{"campaign_id":"222","campaign_name":"Beta","spend":"5","impressions":"400","clicks":"4","date_start":"2024-01-01"}],` +
+				`"paging":{"next":"https://graph.facebook.com/v22.0/act_export-insights/insights?after=abc"}}`)
+		}
+		return jsonResponse(`{"data":[{"campaign_id":"111","campaign_name":"Alpha, Inc","spend":"7","impressions":"800","clicks":"10","date_start":"2024-01-02"}]}`)
+	})
+	timeRange := TimeRange{Since: "2024-01-01", Until: "2024-01-02"}
+
+	var csvOutput bytes.Buffer
+	rows, err := collector.ExportDailyInsights(&csvOutput, timeRange, "csv")
+	if err != nil {
+		t.Fatalf("CSV export failed: %v", err)
+	}
+	if rows != 3 || pages != 2 {
+		t.Errorf("Expected 3 rows from 2 pages, got %d rows from %d pages", rows, pages)
+	}
+
+	expectedCSV := "date,campaign_id,campaign_name,impressions,clicks,spend,conversions,ctr,cpm,cpc\n" +
+		"2024-01-01,111,\"Alpha, Inc\",1000,20,10.50,0,2.00,10.50,0.53\n" +
+		"2024-01-01,222,Beta,400,4,5.00,0,1.00,12.50,1.25\n" +
+		"2024-01-02,111,\"Alpha, Inc\",800,10,7.00,0,1.25,8.75,0.70\n"
+	if csvOutput.String() != expectedCSV {
+		t.Errorf("Unexpected CSV output:\n%s", csvOutput.String())
+	}
+
+	var ndjsonOutput bytes.Buffer
+	if _, err := collector.ExportDailyInsights(&ndjsonOutput, timeRange, "ndjson"); err != nil {
+		t.Fatalf("NDJSON export failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(ndjsonOutput.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[2], `"campaign_id":"111"`) || !strings.Contains(lines[2], `"spend":7`) {
+		t.Errorf("Unexpected NDJSON output:\n%s", ndjsonOutput.String())
+	}
+
+	if _, err := collector.ExportDailyInsights(io.Discard, timeRange, "xml"); err == nil {
+		t.Errorf("Expected an error for an unsupported format")
+	}
+}
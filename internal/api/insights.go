@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PerformanceInsightsFields are the insights fields the performance analysis is based on
+var PerformanceInsightsFields = []string{
+	"campaign_id",
+	"campaign_name",
+	"spend",
+	"impressions",
+	"clicks",
+	"actions",
+	"action_values",
+	"purchase_roas",
+	"cpm",
+	"cpc",
+	"ctr",
+	"cost_per_action_type",
+}
+
+// InsightsLevels lists the levels insights can be collected at
+var InsightsLevels = []string{"campaign", "adset", "ad"}
+
+// InsightsRow is one row of an insights response, keyed by field name
+type InsightsRow map[string]interface{}
+
+// DefaultInsightsFields returns the performance fields plus the ID and name of the objects
+// of the level, so that ad set and ad rows can be told apart
+func DefaultInsightsFields(level string) []string {
+	var fields []string
+	switch level {
+	case "adset":
+		fields = []string{"adset_id", "adset_name"}
+	case "ad":
+		fields = []string{"adset_id", "ad_id", "ad_name"}
+	}
+	return append(fields, PerformanceInsightsFields...)
+}
+
+// CollectInsights collects the raw rows of an insights request, following every page.
+// The level defaults to campaign and the fields to DefaultInsightsFields.
+func (m *MetricsCollector) CollectInsights(request InsightsRequest) ([]InsightsRow, error) {
+	return m.CollectInsightsContext(context.Background(), request)
+}
+
+// CollectInsightsContext collects insights rows like CollectInsights. Cancelling the context
+// aborts the request in flight and stops the pagination.
+func (m *MetricsCollector) CollectInsightsContext(ctx context.Context, request InsightsRequest) ([]InsightsRow, error) {
+	var rows []InsightsRow
+	err := m.StreamInsightsContext(ctx, request, func(page []InsightsRow) error {
+		rows = append(rows, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// StreamInsights hands the rows of an insights request to handle one API page at a time,
+// so callers exporting large accounts never hold the whole result. Defaults are the same
+// as for CollectInsights.
+func (m *MetricsCollector) StreamInsights(request InsightsRequest, handle func(page []InsightsRow) error) error {
+	return m.StreamInsightsContext(context.Background(), request, handle)
+}
+
+// StreamInsightsContext streams insights rows like StreamInsights, stopping when ctx is cancelled
+func (m *MetricsCollector) StreamInsightsContext(ctx context.Context, request InsightsRequest, handle func(page []InsightsRow) error) error {
+	if request.Level == "" {
+		request.Level = "campaign"
+	}
+	if len(request.Fields) == 0 {
+		request.Fields = DefaultInsightsFields(request.Level)
+	}
+
+	req, err := m.newInsightsRequest(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	return m.fetchInsightsPages(req, func(page []map[string]interface{}) error {
+		rows := make([]InsightsRow, len(page))
+		for i, row := range page {
+			rows[i] = InsightsRow(row)
+		}
+		return handle(rows)
+	})
+}
+
+// InsightsColumns returns the columns of the rows of an insights request: the reporting
+// period, then the breakdowns, then the requested fields
+func InsightsColumns(request InsightsRequest) []string {
+	columns := []string{"date_start", "date_stop"}
+	if request.BreakdownsType != "" {
+		columns = append(columns, strings.Split(request.BreakdownsType, ",")...)
+	}
+
+	fields := request.Fields
+	if len(fields) == 0 {
+		fields = DefaultInsightsFields(request.Level)
+	}
+	return append(columns, fields...)
+}
+
+// Value returns a field of the row as text. Lists such as actions are written as JSON;
+// missing fields are empty.
+func (r InsightsRow) Value(field string) string {
+	switch value := r[field].(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	}
+}
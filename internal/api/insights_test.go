@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCollectInsights_BreakdownsAndPages(t *testing.T) {
+	var queries []string
+	collector := newFixtureCollector(t, "insights", func(req *http.Request) *http.Response {
+		queries = append(queries, req.URL.RawQuery)
+		if req.URL.Query().Get("after") == "" {
+			return jsonResponse(`{"data":[{"adset_id":"111","ad_id":"121","ad_name":"Hero","age":"25-34","gender":"female","spend":"12.50"}],` +
+				`"paging":{"next":"https://graph.facebook.com/v22.0/act_insights/insights?after=abc"}}`)
+		}
+		return jsonResponse(`{"data":[{"adset_id":"111","ad_id":"122","ad_name":"Lifestyle","age":"25-34","gender":"male","spend":"3"}]}`)
+	})
+
+	request := InsightsRequest{
+		Level:          "ad",
+		TimeRange:      TimeRange{Since: "2024-01-01", Until: "2024-01-31"},
+		BreakdownsType: "age,gender",
+	}
+	rows, err := collector.CollectInsights(request)
+	if err != nil {
+		t.Fatalf("CollectInsights failed: %v", err)
+	}
+
+	if len(rows) != 2 || rows[1].Value("ad_id") != "122" {
+		t.Fatalf("Expected the rows of both pages, got %v", rows)
+	}
+	first := queries[0]
+	for _, expected := range []string{"level=ad", "breakdowns=age%2Cgender", "fields=adset_id%2Cad_id%2Cad_name%2Ccampaign_id"} {
+		if !strings.Contains(first, expected) {
+			t.Errorf("Expected %s in the query, got %s", expected, first)
+		}
+	}
+
+	columns := strings.Join(InsightsColumns(request), ",")
+	if !strings.HasPrefix(columns, "date_start,date_stop,age,gender,adset_id,ad_id,ad_name,campaign_id") {
+		t.Errorf("Unexpected columns: %s", columns)
+	}
+}
+
+func TestStreamInsights_HandsOverEachPage(t *testing.T) {
+	collector := newFixtureCollector(t, "insights", func(req *http.Request) *http.Response {
+		if req.URL.Query().Get("after") == "" {
+			return jsonResponse(`{"data":[{"campaign_id":"101"},{"campaign_id":"102"}],` +
+				`"paging":{"next":"https://graph.facebook.com/v22.0/act_insights/insights?after=abc"}}`)
+		}
+		return jsonResponse(`{"data":[{"campaign_id":"103"}]}`)
+	})
+
+	var pages [][]string
+	err := collector.StreamInsights(InsightsRequest{TimeRange: TimeRange{Since: "2024-01-01", Until: "2024-01-31"}}, func(page []InsightsRow) error {
+		var ids []string
+		for _, row := range page {
+			ids = append(ids, row.Value("campaign_id"))
+		}
+		pages = append(pages, ids)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamInsights failed: %v", err)
+	}
+
+	if len(pages) != 2 || strings.Join(pages[0], ",") != "101,102" || strings.Join(pages[1], ",") != "103" {
+		t.Errorf("Expected the rows page by page, got %v", pages)
+	}
+}
+
+func TestInsightsRow_Value(t *testing.T) {
+	row := InsightsRow{
+		"spend":   "12.50",
+		"reach":   float64(1200),
+		"actions": []interface{}{map[string]interface{}{"action_type": "link_click", "value": "4"}},
+	}
+
+	tests := map[string]string{
+		"spend":   "12.50",
+		"reach":   "1200",
+		"actions": `[{"action_type":"link_click","value":"4"}]`,
+		"missing": "",
+	}
+	for field, expected := range tests {
+		if value := row.Value(field); value != expected {
+			t.Errorf("Value(%s) = %q, expected %q", field, value, expected)
+		}
+	}
+}
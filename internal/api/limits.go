@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+// ObjectCounts holds the number of objects in an ad account
+type ObjectCounts struct {
+	Campaigns int `json:"campaigns"`
+	AdSets    int `json:"adsets"`
+}
+
+// AccountLimitError is returned when a run would exceed the account object limits
+type AccountLimitError struct {
+	Object  string // "campaigns" or "ad sets"
+	Current int
+	Planned int
+	Limit   int
+}
+
+func (e *AccountLimitError) Error() string {
+	return fmt.Sprintf("creating %d %s would bring the account to %d of the %d allowed (currently %d); "+
+		"archive unused campaigns with 'fbads update --id=<campaign_id> --status=ARCHIVED' or delete them with 'fbads delete <campaign_id>', "+
+		"or raise the limit under \"limits\" in config.json",
+		e.Planned, e.Object, e.Current+e.Planned, e.Limit, e.Current)
+}
+
+// GetObjectCounts returns the number of non-archived, non-deleted campaigns and ad sets in the account
+func (c *Client) GetObjectCounts() (*ObjectCounts, error) {
+	campaigns, err := c.countObjects("campaigns")
+	if err != nil {
+		return nil, err
+	}
+
+	adSets, err := c.countObjects("adsets")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ObjectCounts{Campaigns: campaigns, AdSets: adSets}, nil
+}
+
+// countObjects returns the total count of an account edge from its summary
+func (c *Client) countObjects(edge string) (int, error) {
+	params := url.Values{}
+	params.Set("fields", "id")
+	params.Set("limit", "1")
+	params.Set("summary", "total_count")
+
+	endpoint := fmt.Sprintf("act_%s/%s", c.accountID, edge)
+
+	req, err := c.auth.GetAuthenticatedRequest(endpoint, params)
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return 0, fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("error reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
+	}
+
+	var result struct {
+		Summary struct {
+			TotalCount int `json:"total_count"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, fmt.Errorf("error parsing response: %w", err)
+	}
+
+	return result.Summary.TotalCount, nil
+}
+
+// CheckAccountLimits returns an *AccountLimitError when adding the planned objects
+// to the current counts would exceed the limits. Limits of zero are not enforced.
+func CheckAccountLimits(current, planned ObjectCounts, limits config.AccountLimits) error {
+	if limits.MaxCampaigns > 0 && current.Campaigns+planned.Campaigns > limits.MaxCampaigns {
+		return &AccountLimitError{Object: "campaigns", Current: current.Campaigns, Planned: planned.Campaigns, Limit: limits.MaxCampaigns}
+	}
+
+	if limits.MaxAdSets > 0 && current.AdSets+planned.AdSets > limits.MaxAdSets {
+		return &AccountLimitError{Object: "ad sets", Current: current.AdSets, Planned: planned.AdSets, Limit: limits.MaxAdSets}
+	}
+
+	return nil
+}
+
+// EnsureCapacity fetches the current object counts and checks that the planned objects fit within the limits
+func (c *Client) EnsureCapacity(planned ObjectCounts, limits config.AccountLimits) error {
+	current, err := c.GetObjectCounts()
+	if err != nil {
+		return fmt.Errorf("error counting account objects: %w", err)
+	}
+
+	return CheckAccountLimits(*current, planned, limits)
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/internal/config"
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestCheckAccountLimits(t *testing.T) {
+	limits := config.DefaultAccountLimits()
+
+	tests := []struct {
+		name        string
+		current     ObjectCounts
+		planned     ObjectCounts
+		expectError string
+	}{
+		{
+			name:    "well below the limits",
+			current: ObjectCounts{Campaigns: 100, AdSets: 300},
+			planned: ObjectCounts{Campaigns: 50, AdSets: 50},
+		},
+		{
+			name:    "exactly reaching the campaign limit",
+			current: ObjectCounts{Campaigns: 4990, AdSets: 5000},
+			planned: ObjectCounts{Campaigns: 10, AdSets: 10},
+		},
+		{
+			name:        "one campaign over the limit",
+			current:     ObjectCounts{Campaigns: 4990, AdSets: 5000},
+			planned:     ObjectCounts{Campaigns: 11, AdSets: 11},
+			expectError: "creating 11 campaigns would bring the account to 5001 of the 5000 allowed (currently 4990)",
+		},
+		{
+			name:        "ad sets over the limit",
+			current:     ObjectCounts{Campaigns: 1000, AdSets: 9900},
+			planned:     ObjectCounts{Campaigns: 20, AdSets: 120},
+			expectError: "creating 120 ad sets would bring the account to 10020 of the 10000 allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckAccountLimits(tt.current, tt.planned, limits)
+
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			var limitErr *AccountLimitError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("Expected an AccountLimitError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing %q, got %q", tt.expectError, err.Error())
+			}
+			if !strings.Contains(err.Error(), "--status=ARCHIVED") {
+				t.Errorf("Expected the error to point to archiving campaigns, got %q", err.Error())
+			}
+		})
+	}
+
+	// A zero limit is not enforced
+	if err := CheckAccountLimits(ObjectCounts{Campaigns: 9000}, ObjectCounts{Campaigns: 1}, config.AccountLimits{}); err != nil {
+		t.Errorf("Expected zero limits to be ignored, got %v", err)
+	}
+}
+
+func TestEnsureCapacity_NearLimit(t *testing.T) {
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			if req.URL.Query().Get("summary") != "total_count" {
+				t.Errorf("Expected a total_count summary request, got %s", req.URL)
+			}
+			if strings.HasSuffix(req.URL.Path, "/act_limits/campaigns") {
+				return jsonResponse(`{"data":[{"id":"1"}],"summary":{"total_count":4998}}`)
+			}
+			return jsonResponse(`{"data":[{"id":"2"}],"summary":{"total_count":7000}}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "limits",
+	}
+
+	counts, err := client.GetObjectCounts()
+	if err != nil {
+		t.Fatalf("GetObjectCounts failed: %v", err)
+	}
+	if counts.Campaigns != 4998 || counts.AdSets != 7000 {
+		t.Errorf("Unexpected counts: %+v", counts)
+	}
+
+	limits := config.DefaultAccountLimits()
+
+	// Two more campaigns fit, a third does not
+	if err := client.EnsureCapacity(ObjectCounts{Campaigns: 2, AdSets: 2}, limits); err != nil {
+		t.Errorf("Expected 2 campaigns to fit, got %v", err)
+	}
+	if err := client.EnsureCapacity(ObjectCounts{Campaigns: 3, AdSets: 3}, limits); err == nil {
+		t.Errorf("Expected 3 campaigns to exceed the limit")
+	}
+}
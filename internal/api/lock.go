@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// CollectLockFile is the name of the lock file a collector holds in its statistics directory
+const CollectLockFile = "collect.lock"
+
+// LockedError is returned when another process holds a lock file
+type LockedError struct {
+	Path string
+	PID  int
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s is held by process %d", e.Path, e.PID)
+}
+
+// LockStatisticsDir makes sure only one collector stores statistics in a directory. The
+// lock file holds the ID of the process; a lock left behind by a process that no longer
+// runs is taken over. The returned function releases the lock.
+func LockStatisticsDir(dir string) (unlock func() error, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating statistics directory: %w", err)
+	}
+	path := filepath.Join(dir, CollectLockFile)
+
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = fmt.Fprintf(file, "%d\n", os.Getpid())
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("error writing lock file: %w", err)
+			}
+			return func() error { return os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("error creating lock file: %w", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error reading lock file: %w", err)
+		}
+		pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+		if pid > 0 && processRunning(pid) {
+			return nil, &LockedError{Path: path, PID: pid}
+		}
+
+		// The process that held the lock is gone
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error removing stale lock file: %w", err)
+		}
+	}
+
+	return nil, fmt.Errorf("could not acquire %s", path)
+}
+
+// processRunning reports whether a process with the ID exists. Where signal 0 isn't
+// supported, processes are reported as gone so a stale lock never blocks collection.
+func processRunning(pid int) bool {
+	if pid == os.Getpid() {
+		return true
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
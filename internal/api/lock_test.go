@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestLockStatisticsDir(t *testing.T) {
+	dir := t.TempDir()
+
+	unlock, err := LockStatisticsDir(dir)
+	if err != nil {
+		t.Fatalf("LockStatisticsDir failed: %v", err)
+	}
+
+	// A second collector is refused while the first holds the lock
+	_, err = LockStatisticsDir(dir)
+	var lockErr *LockedError
+	if !errors.As(err, &lockErr) || lockErr.PID != os.Getpid() {
+		t.Fatalf("Expected the directory to be locked by this process, got %v", err)
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatalf("unlock failed: %v", err)
+	}
+	unlock, err = LockStatisticsDir(dir)
+	if err != nil {
+		t.Fatalf("Expected the released lock to be acquired again, got %v", err)
+	}
+	unlock()
+}
+
+func TestLockStatisticsDir_TakesOverStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, CollectLockFile)
+
+	// No process runs with the largest PID Linux allows
+	if err := os.WriteFile(path, []byte(strconv.Itoa(1<<22)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := LockStatisticsDir(dir)
+	if err != nil {
+		t.Fatalf("Expected the stale lock to be taken over, got %v", err)
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != strconv.Itoa(os.Getpid())+"\n" {
+		t.Errorf("Expected the lock file to hold this process, got %q (%v)", data, err)
+	}
+}
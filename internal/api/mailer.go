@@ -0,0 +1,220 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/internal/config"
+)
+
+// smtpTimeout bounds connecting to the SMTP server and sending one report
+const smtpTimeout = 60 * time.Second
+
+// ReportMailer sends generated reports by email
+type ReportMailer struct {
+	settings config.EmailSettings
+}
+
+// NewReportMailer creates a mailer for the SMTP server and recipients of the settings.
+// FBADS_SMTP_PASSWORD, when set, overrides the configured password.
+func NewReportMailer(settings config.EmailSettings) (*ReportMailer, error) {
+	if settings.Host == "" {
+		return nil, fmt.Errorf("no SMTP server configured (email.smtp_host)")
+	}
+	if settings.Port <= 0 {
+		settings.Port = config.DefaultEmailSettings().Port
+	}
+	if settings.From == "" {
+		return nil, fmt.Errorf("no sender configured (email.from)")
+	}
+	if len(settings.To) == 0 {
+		return nil, fmt.Errorf("no recipients configured (email.to)")
+	}
+	switch settings.TLS {
+	case "":
+		settings.TLS = config.EmailTLSStartTLS
+	case config.EmailTLSStartTLS, config.EmailTLSImplicit, config.EmailTLSNone:
+	default:
+		return nil, fmt.Errorf("unknown email.tls %q (use starttls, tls or none)", settings.TLS)
+	}
+	if password := os.Getenv("FBADS_SMTP_PASSWORD"); password != "" {
+		settings.Password = password
+	}
+
+	return &ReportMailer{settings: settings}, nil
+}
+
+// Recipients returns the addresses reports are sent to
+func (m *ReportMailer) Recipients() []string {
+	return m.settings.To
+}
+
+// SendReport mails a report with an HTML body and the file at attachmentPath attached,
+// if given
+func (m *ReportMailer) SendReport(subject string, htmlBody []byte, attachmentPath string) error {
+	return m.SendReportContext(context.Background(), subject, htmlBody, attachmentPath)
+}
+
+// SendReportContext mails a report like SendReport. Cancelling the context closes the
+// connection to the SMTP server.
+func (m *ReportMailer) SendReportContext(ctx context.Context, subject string, htmlBody []byte, attachmentPath string) error {
+	var attachment []byte
+	if attachmentPath != "" {
+		var err error
+		if attachment, err = os.ReadFile(attachmentPath); err != nil {
+			return fmt.Errorf("error reading attachment: %w", err)
+		}
+	}
+
+	message, err := buildReportMessage(m.settings.From, m.settings.To, subject, htmlBody, filepath.Base(attachmentPath), attachment, time.Now())
+	if err != nil {
+		return err
+	}
+	return m.send(ctx, message)
+}
+
+// send delivers a message to every recipient through the SMTP server
+func (m *ReportMailer) send(ctx context.Context, message []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	host := m.settings.Host
+	addr := net.JoinHostPort(host, strconv.Itoa(m.settings.Port))
+	tlsConfig := &tls.Config{ServerName: host}
+
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{}
+	if m.settings.TLS == config.EmailTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("error connecting to %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	// The SMTP client has no context support, so the connection is closed on cancellation
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return fmt.Errorf("error greeting %s: %w", addr, err)
+	}
+	defer client.Close()
+
+	if m.settings.TLS == config.EmailTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s doesn't support STARTTLS; set email.tls to tls or none", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("error starting TLS: %w", err)
+		}
+	}
+
+	if m.settings.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("%s doesn't support authentication", addr)
+		}
+		auth := smtp.PlainAuth("", m.settings.Username, m.settings.Password, host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("error authenticating as %s: %w", m.settings.Username, err)
+		}
+	}
+
+	if err := client.Mail(m.settings.From); err != nil {
+		return fmt.Errorf("error setting the sender: %w", err)
+	}
+	for _, to := range m.settings.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("error adding recipient %s: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error sending the message: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("error sending the message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error sending the message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildReportMessage builds a multipart/mixed message with an HTML body and, when
+// attachmentName is set, the attachment encoded in base64
+func buildReportMessage(from string, to []string, subject string, htmlBody []byte, attachmentName string, attachment []byte, date time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	htmlHeader := textproto.MIMEHeader{}
+	htmlHeader.Set("Content-Type", "text/html; charset=UTF-8")
+	htmlHeader.Set("Content-Transfer-Encoding", "base64")
+	part, err := writer.CreatePart(htmlHeader)
+	if err != nil {
+		return nil, fmt.Errorf("error building the message: %w", err)
+	}
+	writeBase64Lines(part, htmlBody)
+
+	if attachmentName != "" {
+		contentType := mime.TypeByExtension(filepath.Ext(attachmentName))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		attachmentHeader := textproto.MIMEHeader{}
+		attachmentHeader.Set("Content-Type", contentType)
+		attachmentHeader.Set("Content-Transfer-Encoding", "base64")
+		attachmentHeader.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachmentName}))
+		part, err := writer.CreatePart(attachmentHeader)
+		if err != nil {
+			return nil, fmt.Errorf("error building the message: %w", err)
+		}
+		writeBase64Lines(part, attachment)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error building the message: %w", err)
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", writer.Boundary())
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
+
+// writeBase64Lines writes data in base64, in lines of 76 characters as MIME requires
+func writeBase64Lines(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/internal/config"
+)
+
+// fakeSMTPServer accepts one message over plain SMTP and records its envelope and data
+type fakeSMTPServer struct {
+	listener   net.Listener
+	from       string
+	recipients []string
+	data       string
+	done       chan struct{}
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeSMTPServer{listener: listener, done: make(chan struct{})}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		defer close(server.done)
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.TrimSpace(line)
+			switch upper := strings.ToUpper(command); {
+			case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(upper, "MAIL FROM:"):
+				server.from = strings.Trim(command[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(upper, "RCPT TO:"):
+				server.recipients = append(server.recipients, strings.Trim(command[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case upper == "DATA":
+				reply("354 Go ahead")
+				var data strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				server.data = data.String()
+				reply("250 OK")
+			case upper == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("502 Not implemented")
+			}
+		}
+	}()
+	return server
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestReportMailer_SendReport(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	mailer, err := NewReportMailer(config.EmailSettings{
+		Host: "127.0.0.1",
+		Port: server.port(),
+		TLS:  config.EmailTLSNone,
+		From: "reports@example.com",
+		To:   []string{"marketing@example.com", "founder@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attachment := filepath.Join(t.TempDir(), "weekly_report.csv")
+	if err := os.WriteFile(attachment, []byte("campaign_id,spend\n111,12.50\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	body := "<h1>Performance report</h1>" + strings.Repeat("<p>Spend</p>", 20)
+	if err := mailer.SendReport("Performance report 2025-01-06 to 2025-01-12", []byte(body), attachment); err != nil {
+		t.Fatalf("SendReport failed: %v", err)
+	}
+	<-server.done
+
+	if server.from != "reports@example.com" || strings.Join(server.recipients, ",") != "marketing@example.com,founder@example.com" {
+		t.Errorf("Unexpected envelope: from %q to %v", server.from, server.recipients)
+	}
+
+	message, err := mail.ReadMessage(strings.NewReader(server.data))
+	if err != nil {
+		t.Fatalf("Error reading the message: %v\n%s", err, server.data)
+	}
+	if subject := message.Header.Get("Subject"); subject != "Performance report 2025-01-06 to 2025-01-12" {
+		t.Errorf("Unexpected subject %q", subject)
+	}
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Expected a multipart/mixed message, got %q", message.Header.Get("Content-Type"))
+	}
+
+	parts := multipart.NewReader(message.Body, params["boundary"])
+	var contents, filenames []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+		if err != nil {
+			t.Fatalf("Error decoding part: %v", err)
+		}
+		contents = append(contents, string(decoded))
+		filenames = append(filenames, part.FileName())
+	}
+
+	if len(contents) != 2 {
+		t.Fatalf("Expected the HTML body and one attachment, got %d parts", len(contents))
+	}
+	if contents[0] != body {
+		t.Errorf("Expected the HTML body to be the report, got %q", contents[0])
+	}
+	if filenames[1] != "weekly_report.csv" || !strings.Contains(contents[1], "111,12.50") {
+		t.Errorf("Expected the CSV report attached, got %q: %q", filenames[1], contents[1])
+	}
+}
+
+func TestNewReportMailer(t *testing.T) {
+	valid := config.EmailSettings{Host: "smtp.example.com", From: "a@example.com", To: []string{"b@example.com"}}
+
+	mailer, err := NewReportMailer(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mailer.settings.Port != 587 || mailer.settings.TLS != config.EmailTLSStartTLS {
+		t.Errorf("Expected STARTTLS on port 587 by default, got %s on %d", mailer.settings.TLS, mailer.settings.Port)
+	}
+
+	for name, modify := range map[string]func(*config.EmailSettings){
+		"no host":       func(s *config.EmailSettings) { s.Host = "" },
+		"no sender":     func(s *config.EmailSettings) { s.From = "" },
+		"no recipients": func(s *config.EmailSettings) { s.To = nil },
+		"unknown TLS":   func(s *config.EmailSettings) { s.TLS = "ssl" },
+	} {
+		settings := valid
+		modify(&settings)
+		if _, err := NewReportMailer(settings); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -1,9 +1,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
 	"github.com/user/fb-ads/pkg/utils"
 )
 
@@ -42,45 +43,91 @@ type MetricsCollector struct {
 	httpClient *http.Client
 	auth       *auth.FacebookAuth
 	accountID  string
+	clock      *AccountClock
 }
 
 // NewMetricsCollector creates a new metrics collector
 func NewMetricsCollector(auth *auth.FacebookAuth, accountID string) *MetricsCollector {
 	return &MetricsCollector{
-		httpClient: &http.Client{},
+		httpClient: auth.HTTPClient(),
 		auth:       auth,
 		accountID:  accountID,
+		clock:      NewAccountClock(auth, accountID),
 	}
 }
 
-// CollectCampaignMetrics collects metrics for campaigns
+// Clock returns the clock used for date ranges in the account timezone
+func (m *MetricsCollector) Clock() *AccountClock {
+	return m.clock
+}
+
+// CollectCampaignMetrics collects metrics from the insights API, one performance per row.
+// Every page of the result is fetched. IDs, when given, limit the rows to those objects.
 func (m *MetricsCollector) CollectCampaignMetrics(request InsightsRequest) ([]utils.CampaignPerformance, error) {
+	return m.CollectCampaignMetricsContext(context.Background(), request)
+}
+
+// CollectCampaignMetricsContext collects metrics like CollectCampaignMetrics. Cancelling the
+// context aborts the request in flight and stops the pagination.
+func (m *MetricsCollector) CollectCampaignMetricsContext(ctx context.Context, request InsightsRequest) ([]utils.CampaignPerformance, error) {
+	if request.Level == "" {
+		request.Level = "campaign"
+	}
+
 	// Set default fields if not provided
 	if len(request.Fields) == 0 {
 		request.Fields = []string{
+			"campaign_id",
 			"campaign_name",
 			"spend",
 			"impressions",
 			"clicks",
 			"actions",
-			"cpm",
-			"cpc",
-			"ctr",
-			"cost_per_action_type",
+			"action_values",
+			"purchase_roas",
 		}
 	}
 
+	req, err := m.newInsightsRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var performances []utils.CampaignPerformance
+	err = m.fetchInsightsPages(req, func(rows []map[string]interface{}) error {
+		for _, row := range rows {
+			performances = append(performances, parsePerformance(row))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return performances, nil
+}
+
+// newInsightsRequest creates the account insights request for the level, fields, time range,
+// filters and breakdowns of request
+func (m *MetricsCollector) newInsightsRequest(ctx context.Context, request InsightsRequest) (*http.Request, error) {
 	params := url.Values{}
 	params.Set("level", request.Level)
 	params.Set("fields", strings.Join(request.Fields, ","))
+	params.Set("limit", "500")
 
 	// Add time range
 	timeRangeJSON, _ := json.Marshal(request.TimeRange)
 	params.Set("time_range", string(timeRangeJSON))
 
+	// Restrict to the requested objects of the level, e.g. campaign.id IN [...]
+	filtering := request.Filtering
+	if len(request.IDs) > 0 {
+		filtering = append(filtering, Filter{Field: request.Level + ".id", Operator: "IN", Value: request.IDs})
+	}
+
 	// Add filtering if present
-	if len(request.Filtering) > 0 {
-		filteringJSON, _ := json.Marshal(request.Filtering)
+	if len(filtering) > 0 {
+		filteringJSON, _ := json.Marshal(filtering)
 		params.Set("filtering", string(filteringJSON))
 	}
 
@@ -91,101 +138,192 @@ func (m *MetricsCollector) CollectCampaignMetrics(request InsightsRequest) ([]ut
 
 	endpoint := fmt.Sprintf("act_%s/insights", m.accountID)
 
-	req, err := m.auth.GetAuthenticatedRequest(endpoint, params)
+	req, err := m.auth.GetAuthenticatedRequestContext(ctx, endpoint, params)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
+	return req, nil
+}
+
+// CollectDailyMetrics collects the account totals of every day in the time range
+func (m *MetricsCollector) CollectDailyMetrics(timeRange TimeRange) ([]DailyPerformance, error) {
+	return m.CollectDailyMetricsContext(context.Background(), timeRange)
+}
+
+// CollectDailyMetricsContext collects the account totals of every day in the time range,
+// oldest first. Days without delivery are returned as zero rows, so the result has one
+// entry per day of the range.
+func (m *MetricsCollector) CollectDailyMetricsContext(ctx context.Context, timeRange TimeRange) ([]DailyPerformance, error) {
+	since, err := time.Parse("2006-01-02", timeRange.Since)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date: %w", err)
+	}
+	until, err := time.Parse("2006-01-02", timeRange.Until)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("level", "account")
+	params.Set("fields", "spend,impressions,clicks,actions,action_values")
+	params.Set("time_increment", "1")
+	params.Set("limit", "500")
 
-	resp, err := m.httpClient.Do(req)
+	timeRangeJSON, _ := json.Marshal(timeRange)
+	params.Set("time_range", string(timeRangeJSON))
+
+	endpoint := fmt.Sprintf("act_%s/insights", m.accountID)
+	req, err := m.auth.GetAuthenticatedRequestContext(ctx, endpoint, params)
 	if err != nil {
-		return nil, fmt.Errorf("error executing request: %w", err)
+		return nil, fmt.Errorf("error creating request: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	byDay := make(map[string]DailyPerformance)
+	err = m.fetchInsightsPages(req, func(rows []map[string]interface{}) error {
+		for _, row := range rows {
+			date := getString(row, "date_start")
+			perf := parsePerformance(row)
+			byDay[date] = DailyPerformance{
+				Date:        date,
+				Spend:       perf.Spend,
+				Impressions: perf.Impressions,
+				Clicks:      perf.Clicks,
+				Conversions: perf.Conversions,
+				CTR:         perf.CTR,
+				CPC:         perf.CPC,
+				CPM:         perf.CPM,
+				CPA:         perf.CPA,
+				ROAS:        perf.ROAS,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Parse the response into a raw map first
-	var rawResponse map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&rawResponse); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	// Facebook leaves out days without delivery; keep them so the chart has no gaps
+	var result []DailyPerformance
+	for day := since; !day.After(until); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		perf, ok := byDay[date]
+		if !ok {
+			perf = DailyPerformance{Date: date}
+		}
+		result = append(result, perf)
 	}
 
-	// Extract the data array
-	dataArray, ok := rawResponse["data"].([]interface{})
+	return result, nil
+}
+
+// Action types counted as conversions. Facebook reports the same event under several
+// types (e.g. purchase, omni_purchase and offsite_conversion.fb_pixel_purchase), so only
+// the pixel and on-Facebook lead types are counted to avoid counting an event twice.
+const (
+	actionTypePixelConversions = "offsite_conversion"             // Total of all pixel conversions, when reported
+	actionTypePixelPrefix      = "offsite_conversion."            // One pixel event or custom conversion
+	actionTypeLeadForms        = "onsite_conversion.lead_grouped" // Leads from instant forms
+)
+
+// conversionTotal adds up the conversions of an actions or action_values list.
+// The pixel total is used when present, otherwise the pixel events are summed.
+func conversionTotal(row map[string]interface{}, key string) float64 {
+	actions, ok := row[key].([]interface{})
 	if !ok {
-		return nil, fmt.Errorf("unexpected response format")
+		return 0
 	}
 
-	// Process the data into campaign performances
-	var performances []utils.CampaignPerformance
-
-	for _, item := range dataArray {
-		itemMap, ok := item.(map[string]interface{})
+	var pixelTotal, pixelEvents, leads float64
+	hasPixelTotal := false
+	for _, action := range actions {
+		actionMap, ok := action.(map[string]interface{})
 		if !ok {
 			continue
 		}
 
-		// Extract campaign ID from the response
-		campaignID, _ := itemMap["campaign_id"].(string)
-
-		// Extract campaign name
-		campaignName, _ := itemMap["campaign_name"].(string)
-
-		// Extract metrics
-		spend, _ := itemMap["spend"].(float64)
-		impressions, _ := itemMap["impressions"].(float64)
-		clicks, _ := itemMap["clicks"].(float64)
-		ctr, _ := itemMap["ctr"].(float64)
-		cpm, _ := itemMap["cpm"].(float64)
-
-		// Calculate conversions from actions
-		var conversions int
-		if actions, ok := itemMap["actions"].([]interface{}); ok {
-			for _, action := range actions {
-				actionMap, ok := action.(map[string]interface{})
-				if !ok {
-					continue
-				}
-
-				actionType, _ := actionMap["action_type"].(string)
-				if actionType == "offsite_conversion" {
-					value, _ := actionMap["value"].(float64)
-					conversions += int(value)
-				}
-			}
+		actionType := getString(actionMap, "action_type")
+		value := getFloat(actionMap, "value")
+		switch {
+		case actionType == actionTypePixelConversions:
+			pixelTotal += value
+			hasPixelTotal = true
+		case strings.HasPrefix(actionType, actionTypePixelPrefix):
+			pixelEvents += value
+		case actionType == actionTypeLeadForms:
+			leads += value
 		}
+	}
 
-		// Calculate ROAS
-		var roas float64 = 0
-		if spend > 0 && conversions > 0 {
-			// This is a simplified ROAS calculation
-			// In a real implementation, you would need to get the actual conversion value
-			averageOrderValue := 50.0 // Example: average order is worth $50
-			roas = float64(conversions) * averageOrderValue / spend
-		}
+	if hasPixelTotal {
+		return pixelTotal + leads
+	}
+	return pixelEvents + leads
+}
 
-		// Create campaign performance object
-		performance := utils.CampaignPerformance{
-			CampaignID:  campaignID,
-			Name:        campaignName,
-			Spend:       spend,
-			Impressions: int(impressions),
-			Clicks:      int(clicks),
-			Conversions: conversions,
-			CPC:         calculateSafeCPC(spend, clicks),
-			CPM:         cpm,
-			CTR:         ctr * 100, // Convert to percentage
-			ROAS:        roas,
-			LastUpdated: time.Now(),
+// parsePerformance converts one insights row into a campaign performance.
+// Rates are derived from the totals rather than read from the row.
+func parsePerformance(itemMap map[string]interface{}) utils.CampaignPerformance {
+	// Extract metrics (the API returns numbers as strings)
+	spend := getFloat(itemMap, "spend")
+	impressions := getFloat(itemMap, "impressions")
+	clicks := getFloat(itemMap, "clicks")
+	conversions := int(conversionTotal(itemMap, "actions"))
+	conversionValue := conversionTotal(itemMap, "action_values")
+
+	perf := utils.CampaignPerformance{
+		CampaignID:  getString(itemMap, "campaign_id"),
+		Name:        getString(itemMap, "campaign_name"),
+		Spend:       spend,
+		Impressions: int(impressions),
+		Clicks:      int(clicks),
+		Conversions: conversions,
+		CPC:         calculateSafeCPC(spend, clicks),
+		LastUpdated: time.Now(),
+	}
+	if impressions > 0 {
+		perf.CTR = clicks / impressions * 100
+		perf.CPM = spend / impressions * 1000
+	}
+	if conversions > 0 {
+		perf.CPA = spend / float64(conversions)
+	}
+	if conversionValue == 0 {
+		// Some accounts only report the purchase ROAS
+		conversionValue = purchaseROAS(itemMap) * spend
+	}
+	if conversionValue > 0 {
+		perf.ConversionValue = conversionValue
+		perf.ROASSource = models.ROASSourceReported
+		if spend > 0 {
+			perf.ROAS = conversionValue / spend
 		}
+	}
+
+	return perf
+}
 
-		performances = append(performances, performance)
+// purchaseROAS returns the purchase_roas of an insights row, or 0 when it isn't reported.
+// Facebook lists it per action type; omni_purchase, which covers every channel, is preferred.
+func purchaseROAS(row map[string]interface{}) float64 {
+	entries, ok := row["purchase_roas"].([]interface{})
+	if !ok {
+		return 0
 	}
 
-	return performances, nil
+	var roas float64
+	for _, entry := range entries {
+		entryMap, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if getString(entryMap, "action_type") == "omni_purchase" {
+			return getFloat(entryMap, "value")
+		}
+		if roas == 0 {
+			roas = getFloat(entryMap, "value")
+		}
+	}
+	return roas
 }
 
 // StoreMetrics stores collected metrics to a file or database
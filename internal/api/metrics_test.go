@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestConversionTotal(t *testing.T) {
+	tests := []struct {
+		name     string
+		actions  string
+		expected float64
+	}{
+		{
+			name:     "pixel total",
+			actions:  `[{"action_type":"link_click","value":"40"},{"action_type":"offsite_conversion","value":"5"}]`,
+			expected: 5,
+		},
+		{
+			name: "pixel events without a total",
+			actions: `[{"action_type":"offsite_conversion.fb_pixel_purchase","value":"3"},` +
+				`{"action_type":"offsite_conversion.custom.123","value":"2"}]`,
+			expected: 5,
+		},
+		{
+			name: "the same purchase under several types counts once",
+			actions: `[{"action_type":"purchase","value":"3"},{"action_type":"omni_purchase","value":"3"},` +
+				`{"action_type":"offsite_conversion.fb_pixel_purchase","value":"3"}]`,
+			expected: 3,
+		},
+		{
+			name:     "pixel total with its events",
+			actions:  `[{"action_type":"offsite_conversion","value":"4"},{"action_type":"offsite_conversion.fb_pixel_lead","value":"4"}]`,
+			expected: 4,
+		},
+		{
+			name:     "instant form leads",
+			actions:  `[{"action_type":"lead","value":"7"},{"action_type":"onsite_conversion.lead_grouped","value":"7"}]`,
+			expected: 7,
+		},
+		{
+			name:     "no actions",
+			actions:  `null`,
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actions interface{}
+			if err := json.Unmarshal([]byte(tt.actions), &actions); err != nil {
+				t.Fatal(err)
+			}
+
+			if total := conversionTotal(map[string]interface{}{"actions": actions}, "actions"); total != tt.expected {
+				t.Errorf("Expected %.0f conversions, got %.0f", tt.expected, total)
+			}
+		})
+	}
+}
+
+func TestCollectCampaignMetrics_PaginatesAndDerivesRates(t *testing.T) {
+	var filtering []string
+	collector := newFixtureCollector(t, "metrics", func(req *http.Request) *http.Response {
+		filtering = append(filtering, req.URL.Query().Get("filtering"))
+		if req.URL.Query().Get("after") == "" {
+			return jsonResponse(`{"data":[{"campaign_id":"111","campaign_name":"Alpha","spend":"100","impressions":"20000","clicks":"400",` +
+				`"actions":[{"action_type":"offsite_conversion.fb_pixel_purchase","value":"8"}],` +
+				`"action_values":[{"action_type":"offsite_conversion.fb_pixel_purchase","value":"320.00"}]}],` +
+				`"paging":{"next":"https://graph.facebook.com/v22.0/act_metrics/insights?after=abc"}}`)
+		}
+		return jsonResponse(`{"data":[{"campaign_id":"222","campaign_name":"Beta","spend":"0","impressions":"0","clicks":"0"}]}`)
+	})
+
+	performances, err := collector.CollectCampaignMetrics(InsightsRequest{
+		IDs:       []string{"111", "222"},
+		TimeRange: TimeRange{Since: "2024-01-01", Until: "2024-01-31"},
+	})
+	if err != nil {
+		t.Fatalf("CollectCampaignMetrics failed: %v", err)
+	}
+
+	if len(performances) != 2 || performances[1].CampaignID != "222" {
+		t.Fatalf("Expected both pages, got %+v", performances)
+	}
+	if filtering[0] != `[{"field":"campaign.id","operator":"IN","value":["111","222"]}]` {
+		t.Errorf("Expected the IDs as a campaign filter, got %s", filtering[0])
+	}
+
+	alpha := performances[0]
+	if alpha.Conversions != 8 || alpha.CTR != 2 || alpha.CPM != 5 || alpha.CPC != 0.25 || alpha.CPA != 12.5 {
+		t.Errorf("Unexpected derived metrics: %+v", alpha)
+	}
+	if math.Abs(alpha.ROAS-3.2) > 1e-9 {
+		t.Errorf("Expected ROAS from the purchase value, got %.2f", alpha.ROAS)
+	}
+
+	// Rows without delivery have zero rates rather than NaN
+	beta := performances[1]
+	if beta.CTR != 0 || beta.CPM != 0 || beta.CPA != 0 || beta.ROAS != 0 {
+		t.Errorf("Expected zero rates without delivery, got %+v", beta)
+	}
+}
+
+func TestCollectCampaignMetricsContext_CancelStopsPagination(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requests := 0
+	collector := newFixtureCollector(t, "metrics", func(req *http.Request) *http.Response {
+		requests++
+		// The user presses Ctrl-C while the first page is loading
+		cancel()
+		return jsonResponse(`{"data":[{"campaign_id":"111","spend":"1"}],` +
+			`"paging":{"next":"https://graph.facebook.com/v22.0/act_metrics/insights?after=abc"}}`)
+	})
+
+	_, err := collector.CollectCampaignMetricsContext(ctx, InsightsRequest{
+		TimeRange: TimeRange{Since: "2024-01-01", Until: "2024-01-31"},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the cancellation to be returned, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected pagination to stop after the first page, got %d requests", requests)
+	}
+}
+
+func TestCollectDailyMetrics_FillsDaysWithoutDelivery(t *testing.T) {
+	collector := newFixtureCollector(t, "daily", func(req *http.Request) *http.Response {
+		q := req.URL.Query()
+		if q.Get("level") != "account" || q.Get("time_increment") != "1" {
+			t.Errorf("Expected daily account insights, got %s", req.URL.RawQuery)
+		}
+		return jsonResponse(`{"data":[` +
+			`{"date_start":"2024-03-01","spend":"20","impressions":"1000","clicks":"50","actions":[{"action_type":"offsite_conversion","value":"2"}],"action_values":[{"action_type":"offsite_conversion","value":"60"}]},` +
+			`{"date_start":"2024-03-03","spend":"10","impressions":"500","clicks":"10"}]}`)
+	})
+
+	days, err := collector.CollectDailyMetrics(TimeRange{Since: "2024-03-01", Until: "2024-03-04"})
+	if err != nil {
+		t.Fatalf("CollectDailyMetrics failed: %v", err)
+	}
+
+	var dates []string
+	for _, day := range days {
+		dates = append(dates, day.Date)
+	}
+	if strings.Join(dates, ",") != "2024-03-01,2024-03-02,2024-03-03,2024-03-04" {
+		t.Fatalf("Expected one row per day, got %v", dates)
+	}
+
+	first := days[0]
+	if first.Spend != 20 || first.Conversions != 2 || first.CTR != 5 || first.CPA != 10 || first.ROAS != 3 {
+		t.Errorf("Unexpected metrics for the first day: %+v", first)
+	}
+	if days[1] != (DailyPerformance{Date: "2024-03-02"}) || days[3] != (DailyPerformance{Date: "2024-03-04"}) {
+		t.Errorf("Expected zero rows for days without delivery, got %+v and %+v", days[1], days[3])
+	}
+}
+
+func TestParsePerformance_ConversionValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		row      string
+		value    float64
+		roas     float64
+		reported bool
+	}{
+		{
+			name:     "action values",
+			row:      `{"spend":"100","action_values":[{"action_type":"offsite_conversion.fb_pixel_purchase","value":"450"}],"purchase_roas":[{"action_type":"omni_purchase","value":"9"}]}`,
+			value:    450,
+			roas:     4.5,
+			reported: true,
+		},
+		{
+			name:     "purchase ROAS only",
+			row:      `{"spend":"100","purchase_roas":[{"action_type":"purchase","value":"2"},{"action_type":"omni_purchase","value":"2.5"}]}`,
+			value:    250,
+			roas:     2.5,
+			reported: true,
+		},
+		{
+			name: "neither",
+			row:  `{"spend":"100","actions":[{"action_type":"offsite_conversion","value":"3"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var row map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.row), &row); err != nil {
+				t.Fatal(err)
+			}
+
+			perf := parsePerformance(row)
+			if perf.ConversionValue != tt.value || perf.ROAS != tt.roas || (perf.ROASSource == models.ROASSourceReported) != tt.reported {
+				t.Errorf("Expected value %v and ROAS %v (reported %v), got %v, %v from %q",
+					tt.value, tt.roas, tt.reported, perf.ConversionValue, perf.ROAS, perf.ROASSource)
+			}
+		})
+	}
+}
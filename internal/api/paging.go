@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// maxPageRequests caps the requests a paginated listing makes, so a paging cursor that
+// never ends can't keep it looping
+const maxPageRequests = 100
+
+// getPaged requests every page of a list endpoint and calls decode with the data array
+// of each page, in order. Pages are requested with the after cursor of the previous
+// page until a page has no next link. Cancelling the context stops the pagination; an
+// error from decode stops it and is returned.
+func (c *Client) getPaged(ctx context.Context, endpoint string, params url.Values, decode func(data []byte) error) error {
+	pageParams := url.Values{}
+	for key, values := range params {
+		pageParams[key] = values
+	}
+
+	for requests := 0; ; requests++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if requests == maxPageRequests {
+			fmt.Printf("Warning: stopped listing %s after %d pages\n", endpoint, requests)
+			return nil
+		}
+
+		req, err := c.auth.GetAuthenticatedRequestContext(ctx, endpoint, pageParams)
+		if err != nil {
+			return fmt.Errorf("error creating request: %w", err)
+		}
+
+		var page struct {
+			Data   json.RawMessage `json:"data"`
+			Paging struct {
+				Cursors struct {
+					After string `json:"after"`
+				} `json:"cursors"`
+				Next string `json:"next"`
+			} `json:"paging"`
+		}
+		if err := doJSON(c.doWithRetry, req, &page); err != nil {
+			return err
+		}
+
+		if len(page.Data) > 0 {
+			if err := decode(page.Data); err != nil {
+				return err
+			}
+		}
+
+		if page.Paging.Next == "" || page.Paging.Cursors.After == "" {
+			return nil
+		}
+		pageParams.Set("after", page.Paging.Cursors.After)
+	}
+}
+
+// listPage is one page of a list endpoint
+type listPage struct {
+	Data   []map[string]interface{} `json:"data"`
+	Paging models.Paging            `json:"paging"`
+}
+
+// getPage requests one page of a list endpoint with the fields, holding up to limit
+// objects after the cursor. A zero limit or empty cursor leaves the API default.
+func (c *Client) getPage(ctx context.Context, endpoint, fields string, limit int, after string) (*listPage, error) {
+	params := url.Values{}
+	params.Set("fields", fields)
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	if after != "" {
+		params.Set("after", after)
+	}
+
+	req, err := c.auth.GetAuthenticatedRequestContext(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	var page listPage
+	if err := doJSON(c.doWithRetry, req, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestGetPaged(t *testing.T) {
+	var queries []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+		switch r.URL.Query().Get("after") {
+		case "":
+			w.Write([]byte(`{"data":[{"id":"1"},{"id":"2"}],"paging":{"cursors":{"before":"a","after":"b"},"next":"` + "http://" + r.Host + r.URL.Path + `?after=b"}}`))
+		case "b":
+			w.Write([]byte(`{"data":[{"id":"3"}],"paging":{"cursors":{"before":"b","after":"c"}}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"Invalid cursor","code":100}}`))
+		}
+	}))
+	defer server.Close()
+
+	authClient := auth.NewFacebookAuth("app", "secret", "token", "v22.0")
+	authClient.BaseURL = server.URL
+	client := NewClient(authClient, "123")
+
+	params := url.Values{"fields": {"id"}}
+	var ids []string
+	err := client.getPaged(context.Background(), "act_123/things", params, func(data []byte) error {
+		var page []struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+		for _, item := range page {
+			ids = append(ids, item.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("getPaged failed: %v", err)
+	}
+
+	if strings.Join(ids, ",") != "1,2,3" {
+		t.Errorf("Expected the items of both pages, got %v", ids)
+	}
+	if len(queries) != 2 || queries[1].Get("fields") != "id" || queries[1].Get("after") != "b" {
+		t.Errorf("Expected the second page to be requested with the same fields after the cursor, got %v", queries)
+	}
+	if params.Get("after") != "" || params.Get("access_token") != "" {
+		t.Errorf("Expected the caller's parameters to be left unchanged, got %v", params)
+	}
+
+	// Errors of the decoder and the API stop the pagination
+	stop := errors.New("stop")
+	queries = nil
+	err = client.getPaged(context.Background(), "act_123/things", nil, func([]byte) error { return stop })
+	if !errors.Is(err, stop) || len(queries) != 1 {
+		t.Errorf("Expected the decoder error after one request, got %v after %d", err, len(queries))
+	}
+
+	err = client.getPaged(context.Background(), "act_123/things", url.Values{"after": {"x"}}, func([]byte) error { return nil })
+	var apiErr *auth.FacebookAPIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusBadRequest {
+		t.Errorf("Expected the API error, got %v", err)
+	}
+}
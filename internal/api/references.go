@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// audienceTargetingKeys are the targeting keys that reference custom audiences
+var audienceTargetingKeys = []string{"custom_audiences", "excluded_custom_audiences"}
+
+// CheckAccountReferences reports the Facebook Pages and custom audiences a configuration
+// uses that can't be used from the ad account of the client
+func (c *Client) CheckAccountReferences(config *models.CampaignConfig) (models.ValidationErrors, error) {
+	return c.CheckAccountReferencesContext(context.Background(), config)
+}
+
+// CheckAccountReferencesContext reports the Facebook Pages and custom audiences a
+// configuration uses that can't be used from the ad account of the client: pages the
+// token doesn't manage and custom audiences the account doesn't own. Checking before
+// anything is created keeps a copy into another account from failing half-way.
+func (c *Client) CheckAccountReferencesContext(ctx context.Context, config *models.CampaignConfig) (models.ValidationErrors, error) {
+	var problems models.ValidationErrors
+
+	type pageRef struct{ path, id string }
+	var pageRefs []pageRef
+	forEachAd(config, func(path string, ad *models.AdConfig) {
+		if ad.Creative.PageID != "" {
+			pageRefs = append(pageRefs, pageRef{path + ".creative.page_id", ad.Creative.PageID})
+		}
+	})
+	if len(pageRefs) > 0 {
+		pages, err := c.GetPagesContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing pages: %w", err)
+		}
+		managed := make(map[string]bool)
+		for _, page := range pages {
+			managed[page.ID] = true
+		}
+		for _, ref := range pageRefs {
+			if !managed[ref.id] {
+				problems.Add(ref.path, "page %s is not managed by this token; pick one listed by 'fbads pages'", ref.id)
+			}
+		}
+	}
+
+	type audienceRef struct{ path, id string }
+	var audienceRefs []audienceRef
+	for i, adSet := range config.AdSets {
+		for _, key := range audienceTargetingKeys {
+			audiences, _ := adSet.Targeting[key].([]interface{})
+			for j, audience := range audiences {
+				if id := audienceID(audience); id != "" {
+					audienceRefs = append(audienceRefs, audienceRef{fmt.Sprintf("adsets[%d].targeting.%s[%d]", i, key, j), id})
+				}
+			}
+		}
+	}
+	if len(audienceRefs) > 0 {
+		owned, err := c.customAudienceIDs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing custom audiences: %w", err)
+		}
+		for _, ref := range audienceRefs {
+			if !owned[ref.id] {
+				problems.Add(ref.path, "custom audience %s does not exist in account %s; share it with the account or remove it from the targeting", ref.id, c.accountID)
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+// customAudienceIDs returns the IDs of the custom audiences of the account
+func (c *Client) customAudienceIDs(ctx context.Context) (map[string]bool, error) {
+	params := url.Values{}
+	params.Set("fields", "id")
+
+	ids := make(map[string]bool)
+	err := c.getPaged(ctx, fmt.Sprintf("act_%s/customaudiences", c.accountID), params, func(data []byte) error {
+		var audiences []struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(data, &audiences); err != nil {
+			return fmt.Errorf("error parsing custom audiences: %w", err)
+		}
+		for _, audience := range audiences {
+			ids[audience.ID] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// audienceID returns the ID of a custom audience in targeting, given either as an object
+// with an id or as the ID itself
+func audienceID(audience interface{}) string {
+	switch value := audience.(type) {
+	case map[string]interface{}:
+		id, _ := value["id"].(string)
+		return id
+	case string:
+		return value
+	}
+	return ""
+}
+
+// forEachAd calls fn with every ad of a configuration and its path, as validation
+// addresses them
+func forEachAd(config *models.CampaignConfig, fn func(path string, ad *models.AdConfig)) {
+	for i := range config.AdSets {
+		for j := range config.AdSets[i].Ads {
+			fn(fmt.Sprintf("adsets[%d].ads[%d]", i, j), &config.AdSets[i].Ads[j])
+		}
+	}
+	for i := range config.Ads {
+		fn(fmt.Sprintf("ads[%d]", i), &config.Ads[i])
+	}
+}
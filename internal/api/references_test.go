@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestCheckAccountReferences(t *testing.T) {
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			switch req.URL.Path {
+			case "/v22.0/me/accounts":
+				return jsonResponse(`{"data":[{"id":"555","name":"Acme"}]}`)
+			case "/v22.0/act_456/customaudiences":
+				return jsonResponse(`{"data":[{"id":"900"}]}`)
+			}
+			t.Errorf("Unexpected request %s", req.URL.Path)
+			return jsonResponse(`{"data":[]}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "456",
+	}
+
+	config := &models.CampaignConfig{
+		AdSets: []models.AdSetConfig{{
+			Name: "Broad",
+			Targeting: map[string]interface{}{
+				"custom_audiences":          []interface{}{map[string]interface{}{"id": "900"}, map[string]interface{}{"id": "901"}},
+				"excluded_custom_audiences": []interface{}{"902"},
+			},
+			Ads: []models.AdConfig{{Name: "Hero", Creative: models.CreativeConfig{PageID: "555"}}},
+		}},
+		Ads: []models.AdConfig{{Name: "Other", Creative: models.CreativeConfig{PageID: "777"}}},
+	}
+
+	problems, err := client.CheckAccountReferences(config)
+	if err != nil {
+		t.Fatalf("CheckAccountReferences failed: %v", err)
+	}
+
+	var paths []string
+	for _, problem := range problems {
+		paths = append(paths, problem.Path)
+	}
+	want := "ads[0].creative.page_id adsets[0].targeting.custom_audiences[1] adsets[0].targeting.excluded_custom_audiences[0]"
+	if got := strings.Join(paths, " "); got != want {
+		t.Errorf("Expected problems at %s, got %s", want, got)
+	}
+	if len(problems) > 1 && !strings.Contains(problems[1].Message, "901") {
+		t.Errorf("Expected the message to name the audience, got %q", problems[1].Message)
+	}
+}
+
+func TestCheckAccountReferences_NothingToCheck(t *testing.T) {
+	client := &Client{
+		httpClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			t.Errorf("Expected no requests, got %s", req.URL.Path)
+			return jsonResponse(`{"data":[]}`)
+		})},
+		auth:      auth.NewFacebookAuth("app", "secret", "token", "v22.0"),
+		accountID: "456",
+	}
+
+	problems, err := client.CheckAccountReferences(&models.CampaignConfig{AdSets: []models.AdSetConfig{{Name: "Broad"}}})
+	if err != nil || len(problems) != 0 {
+		t.Errorf("Expected no problems, got %v, %v", problems, err)
+	}
+}
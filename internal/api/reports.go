@@ -1,10 +1,17 @@
 package api
 
 import (
+	"bytes"
+	_ "embed"
 	"fmt"
+	"html/template"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/user/fb-ads/pkg/utils"
 )
 
 // ReportGenerator handles generation of various reports
@@ -12,6 +19,7 @@ type ReportGenerator struct {
 	analyzer         *PerformanceAnalyzer
 	metricsCollector *MetricsCollector
 	outputDir        string
+	mailer           *ReportMailer
 }
 
 // NewReportGenerator creates a new report generator
@@ -23,94 +31,120 @@ func NewReportGenerator(analyzer *PerformanceAnalyzer, metricsCollector *Metrics
 	}
 }
 
-// GenerateDailyReport generates a daily performance report
-func (r *ReportGenerator) GenerateDailyReport() error {
-	// Create time range for yesterday
-	yesterday := time.Now().AddDate(0, 0, -1)
-	yesterdayStr := yesterday.Format("2006-01-02")
+// SetMailer makes the generator mail every report it writes
+func (r *ReportGenerator) SetMailer(mailer *ReportMailer) {
+	r.mailer = mailer
+}
+
+// Report file formats
+const (
+	ReportFormatJSON = "json"
+	ReportFormatCSV  = "csv"
+	ReportFormatHTML = "html"
+)
+
+// ParseReportFormat checks a report format name, returning JSON for an empty name
+func ParseReportFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", ReportFormatJSON:
+		return ReportFormatJSON, nil
+	case ReportFormatCSV:
+		return ReportFormatCSV, nil
+	case ReportFormatHTML:
+		return ReportFormatHTML, nil
+	}
+	return "", fmt.Errorf("unsupported report format %q (use json, csv or html)", format)
+}
+
+// GenerateDailyReport generates a report of yesterday's performance and returns its path
+func (r *ReportGenerator) GenerateDailyReport(format string) (string, error) {
+	// Create time range for yesterday in the account timezone
+	yesterdayStr := r.metricsCollector.Clock().Yesterday().Format("2006-01-02")
 
 	timeRange := TimeRange{
 		Since: yesterdayStr,
 		Until: yesterdayStr,
 	}
 
-	// Generate analysis
-	analysis, err := r.analyzer.AnalyzeCampaignPerformance(timeRange)
-	if err != nil {
-		return fmt.Errorf("error analyzing performance: %w", err)
-	}
-
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(r.outputDir, 0755); err != nil {
-		return fmt.Errorf("error creating output directory: %w", err)
-	}
+	return r.generateReport(timeRange, "daily_report_"+yesterdayStr, format)
+}
 
-	// Generate report file name
-	reportFileName := fmt.Sprintf("daily_report_%s.json", yesterdayStr)
-	reportPath := filepath.Join(r.outputDir, reportFileName)
+// GenerateWeeklyReport generates a report of the last 7 days and returns its path
+func (r *ReportGenerator) GenerateWeeklyReport(format string) (string, error) {
+	// Create time range for last week in the account timezone
+	timeRange := r.metricsCollector.Clock().LastDays(7)
 
-	// Save report
-	return r.analyzer.GenerateReport(analysis, reportPath)
+	return r.generateReport(timeRange, fmt.Sprintf("weekly_report_%s_to_%s", timeRange.Since, timeRange.Until), format)
 }
 
-// GenerateWeeklyReport generates a weekly performance report
-func (r *ReportGenerator) GenerateWeeklyReport() error {
-	// Create time range for last week
-	today := time.Now()
-	endDate := today.AddDate(0, 0, -1)
-	startDate := today.AddDate(0, 0, -7)
-
+// GenerateCustomReport generates a report of a custom date range and returns its path
+func (r *ReportGenerator) GenerateCustomReport(startDate, endDate time.Time, format string) (string, error) {
 	timeRange := TimeRange{
 		Since: startDate.Format("2006-01-02"),
 		Until: endDate.Format("2006-01-02"),
 	}
 
+	return r.generateReport(timeRange, fmt.Sprintf("custom_report_%s_to_%s", timeRange.Since, timeRange.Until), format)
+}
+
+// generateReport analyzes the time range and writes the report to <name>.<format> in the output directory
+func (r *ReportGenerator) generateReport(timeRange TimeRange, name, format string) (string, error) {
+	format, err := ParseReportFormat(format)
+	if err != nil {
+		return "", err
+	}
+
 	// Generate analysis
 	analysis, err := r.analyzer.AnalyzeCampaignPerformance(timeRange)
 	if err != nil {
-		return fmt.Errorf("error analyzing performance: %w", err)
+		return "", fmt.Errorf("error analyzing performance: %w", err)
 	}
 
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(r.outputDir, 0755); err != nil {
-		return fmt.Errorf("error creating output directory: %w", err)
+		return "", fmt.Errorf("error creating output directory: %w", err)
 	}
 
-	// Generate report file name
-	weekNum := int(today.Day()/7) + 1
-	reportFileName := fmt.Sprintf("weekly_report_%s_week%d.json", today.Format("2006-01"), weekNum)
-	reportPath := filepath.Join(r.outputDir, reportFileName)
+	reportPath := filepath.Join(r.outputDir, name+"."+format)
 
-	// Save report
-	return r.analyzer.GenerateReport(analysis, reportPath)
-}
-
-// GenerateCustomReport generates a custom date range report
-func (r *ReportGenerator) GenerateCustomReport(startDate, endDate time.Time) error {
-	timeRange := TimeRange{
-		Since: startDate.Format("2006-01-02"),
-		Until: endDate.Format("2006-01-02"),
+	switch format {
+	case ReportFormatCSV:
+		err = r.ExportReportCSV(analysis, reportPath)
+	case ReportFormatHTML:
+		err = r.ExportReportHTML(analysis, reportPath)
+	default:
+		err = r.analyzer.GenerateReport(analysis, reportPath)
 	}
-
-	// Generate analysis
-	analysis, err := r.analyzer.AnalyzeCampaignPerformance(timeRange)
 	if err != nil {
-		return fmt.Errorf("error analyzing performance: %w", err)
+		return "", err
 	}
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(r.outputDir, 0755); err != nil {
-		return fmt.Errorf("error creating output directory: %w", err)
+	if r.mailer != nil {
+		if err := r.mailReport(analysis, reportPath); err != nil {
+			return reportPath, fmt.Errorf("report written to %s, but mailing it failed: %w", reportPath, err)
+		}
 	}
+	return reportPath, nil
+}
 
-	// Generate report file name
-	reportFileName := fmt.Sprintf("custom_report_%s_to_%s.json",
-		startDate.Format("2006-01-02"),
-		endDate.Format("2006-01-02"))
-	reportPath := filepath.Join(r.outputDir, reportFileName)
+// mailReport mails a written report: the HTML report as the body, with the report file
+// attached unless it is the HTML report itself
+func (r *ReportGenerator) mailReport(analysis *PerformanceAnalysis, reportPath string) error {
+	var body []byte
+	var err error
+	if filepath.Ext(reportPath) == "."+ReportFormatHTML {
+		body, err = os.ReadFile(reportPath)
+		reportPath = ""
+	} else {
+		body, err = renderReportHTML(analysis, r.metricsCollector)
+	}
+	if err != nil {
+		return err
+	}
 
-	// Save report
-	return r.analyzer.GenerateReport(analysis, reportPath)
+	// The subject matches the title of the HTML report
+	subject := fmt.Sprintf("Performance report %s to %s", analysis.Period.Since, analysis.Period.Until)
+	return r.mailer.SendReport(subject, body, reportPath)
 }
 
 // GenerateAudienceInsightsReport generates a report on audience insights
@@ -119,14 +153,137 @@ func (r *ReportGenerator) GenerateAudienceInsightsReport() error {
 	return nil
 }
 
-// ExportReportCSV exports a performance analysis as CSV
+// ExportReportCSV exports a performance analysis as CSV, with the same columns as
+// StatisticsManager.ExportStatisticsCSV: one row per campaign and a total row
 func (r *ReportGenerator) ExportReportCSV(analysis *PerformanceAnalysis, filePath string) error {
-	// TODO: Implement CSV export
-	return nil
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating CSV file: %w", err)
+	}
+	defer file.Close()
+
+	if err := WriteStatisticsCSV(file, reportStatistics(analysis)); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// reportStatistics converts the campaigns of an analysis to the statistics the CSV export writes
+func reportStatistics(analysis *PerformanceAnalysis) *AggregateStatistics {
+	campaigns := analysis.campaigns
+	if campaigns == nil {
+		// Analyses loaded from JSON only have the top and worst campaigns
+		seen := make(map[string]bool)
+		for _, perf := range append(append([]utils.CampaignPerformance(nil), analysis.TopCampaigns...), analysis.WorstCampaigns...) {
+			if !seen[perf.CampaignID] {
+				seen[perf.CampaignID] = true
+				campaigns = append(campaigns, perf)
+			}
+		}
+	}
+
+	stats := &AggregateStatistics{CampaignStats: make(map[string]CampaignStats, len(campaigns))}
+	for _, perf := range campaigns {
+		campaign := CampaignStats{
+			CampaignID:       perf.CampaignID,
+			Name:             perf.Name,
+			TotalSpend:       perf.Spend,
+			TotalImpressions: perf.Impressions,
+			TotalClicks:      perf.Clicks,
+			TotalConversions: perf.Conversions,
+			AvgCTR:           finiteOrZero(perf.CTR),
+			AvgCPM:           finiteOrZero(perf.CPM),
+			AvgCPC:           finiteOrZero(perf.CPC),
+			AvgCPA:           finiteOrZero(perf.CPA),
+		}
+		// ROAS is revenue per dollar spent, so ROI is the part above the spend
+		if perf.ROAS > 0 {
+			campaign.ROI = finiteOrZero((perf.ROAS - 1) * 100)
+		}
+		stats.CampaignStats[perf.CampaignID] = campaign
+
+		stats.TotalSpend += perf.Spend
+		stats.TotalImpressions += perf.Impressions
+		stats.TotalClicks += perf.Clicks
+		stats.TotalConversions += perf.Conversions
+	}
+
+	if stats.TotalImpressions > 0 {
+		stats.AvgCTR = float64(stats.TotalClicks) / float64(stats.TotalImpressions) * 100
+		stats.AvgCPM = stats.TotalSpend / float64(stats.TotalImpressions) * 1000
+	}
+	if stats.TotalClicks > 0 {
+		stats.AvgCPC = stats.TotalSpend / float64(stats.TotalClicks)
+	}
+	if stats.TotalConversions > 0 {
+		stats.AvgCPA = stats.TotalSpend / float64(stats.TotalConversions)
+	}
+	return stats
+}
+
+// finiteOrZero returns 0 for NaN and infinite values
+func finiteOrZero(value float64) float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0
+	}
+	return value
 }
 
-// ExportReportHTML generates an HTML report from a performance analysis
+//go:embed templates/report.html
+var reportTemplateSource string
+
+// reportTemplate renders the standalone HTML report
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"money":     func(v float64) string { return fmt.Sprintf("$%.2f", finiteOrZero(v)) },
+	"percent":   func(v float64) string { return fmt.Sprintf("%.2f%%", finiteOrZero(v)) },
+	"ratio":     func(v float64) string { return fmt.Sprintf("%.2f", finiteOrZero(v)) },
+	"thousands": formatThousands,
+}).Parse(reportTemplateSource))
+
+// htmlReport is the data the HTML report template is rendered with
+type htmlReport struct {
+	*PerformanceAnalysis
+	DailyTrend []DailyPerformance
+}
+
+// ExportReportHTML generates a standalone HTML report from a performance analysis,
+// see PerformanceAnalyzer.GenerateReportHTML
 func (r *ReportGenerator) ExportReportHTML(analysis *PerformanceAnalysis, filePath string) error {
-	// TODO: Implement HTML report generation
+	return writeReportHTML(analysis, r.metricsCollector, filePath)
+}
+
+// writeReportHTML renders the HTML report of an analysis to a file. The daily trend of
+// the period is collected when a collector is given.
+func writeReportHTML(analysis *PerformanceAnalysis, collector *MetricsCollector, filePath string) error {
+	data, err := renderReportHTML(analysis, collector)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("error writing report: %w", err)
+	}
 	return nil
 }
+
+// renderReportHTML renders the HTML report of an analysis, with the daily trend of the
+// period when a collector is given
+func renderReportHTML(analysis *PerformanceAnalysis, collector *MetricsCollector) ([]byte, error) {
+	sanitizeAnalysis(analysis)
+
+	report := htmlReport{PerformanceAnalysis: analysis}
+	if collector != nil && analysis.Period.Since != "" && analysis.Period.Until != "" {
+		daily, err := collector.CollectDailyMetrics(analysis.Period)
+		if err != nil {
+			return nil, fmt.Errorf("error collecting the daily trend: %w", err)
+		}
+		report.DailyTrend = daily
+	}
+
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, report); err != nil {
+		return nil, fmt.Errorf("error rendering HTML report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+// reportFixture answers campaign insights with two campaigns and account insights with one day
+func reportFixture(req *http.Request) *http.Response {
+	if req.URL.Query().Get("level") == "account" {
+		return jsonResponse(`{"data":[{"spend":"30","impressions":"3000","clicks":"60","date_start":"2024-01-02","date_stop":"2024-01-02"}]}`)
+	}
+	return jsonResponse(`{"data":[
+		{"campaign_id":"111","campaign_name":"Alpha, Inc","spend":"20","impressions":"2000","clicks":"40","actions":[{"action_type":"offsite_conversion.fb_pixel_purchase","value":"4"}],"action_values":[{"action_type":"offsite_conversion.fb_pixel_purchase","value":"60"}]},
+		{"campaign_id":"222","campaign_name":"Beta","spend":"10","impressions":"1000","clicks":"20"}
+	]}`)
+}
+
+func TestGenerateCustomReport_Formats(t *testing.T) {
+	collector := newFixtureCollector(t, "reports", reportFixture)
+	dir := t.TempDir()
+	generator := NewReportGenerator(NewPerformanceAnalyzer(collector, nil), collector, dir)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		format   string
+		file     string
+		contains []string
+	}{
+		{
+			format:   "",
+			file:     "custom_report_2024-01-01_to_2024-01-03.json",
+			contains: []string{`"total_spend": 30`, `"since": "2024-01-01"`},
+		},
+		{
+			format: ReportFormatCSV,
+			file:   "custom_report_2024-01-01_to_2024-01-03.csv",
+			contains: []string{
+				"Campaign ID,Campaign Name,Impressions,Clicks,CTR (%),Spend ($),CPM ($),CPC ($),Conversions,CPA ($),ROI (%)\n",
+				"111,\"Alpha, Inc\",2000,40,2.00,20.00,10.00,0.50,4,5.00,200.00\n",
+				"222,Beta,1000,20,2.00,10.00,10.00,0.50,0,0.00,0.00\n",
+				"TOTAL,All Campaigns,3000,60,2.00,30.00,10.00,0.50,4,7.50,\n",
+			},
+		},
+		{
+			format: ReportFormatHTML,
+			file:   "custom_report_2024-01-01_to_2024-01-03.html",
+			contains: []string{
+				"<title>Performance report 2024-01-01 to 2024-01-03</title>",
+				"<td>Alpha, Inc</td>",
+				"<td>2024-01-02</td><td class=\"num\">$30.00</td>",
+				"<td>2024-01-03</td><td class=\"num\">$0.00</td>",
+				"<li>",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path, err := generator.GenerateCustomReport(start, end, tt.format)
+			if err != nil {
+				t.Fatalf("GenerateCustomReport failed: %v", err)
+			}
+			if path != filepath.Join(dir, tt.file) {
+				t.Errorf("Expected the report at %s, got %s", tt.file, path)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("Expected the report to contain %q, got:\n%s", want, data)
+				}
+			}
+		})
+	}
+
+	if _, err := generator.GenerateCustomReport(start, end, "pdf"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
+
+func TestGenerateReportHTML(t *testing.T) {
+	analysis := &PerformanceAnalysis{
+		TopCampaigns:    []utils.CampaignPerformance{{CampaignID: "1", Name: "<script>alert(1)</script>", Spend: 12.5, ROAS: 3}},
+		TotalSpend:      12.5,
+		AverageCPA:      math.NaN(),
+		Recommendations: []string{"Increase the budget of Spring"},
+		Period:          TimeRange{Since: "2024-01-01", Until: "2024-01-07"},
+	}
+	path := filepath.Join(t.TempDir(), "report.html")
+
+	// Without a collector the report has no daily trend and needs no API calls
+	if err := NewPerformanceAnalyzer(nil, nil).GenerateReportHTML(analysis, path); err != nil {
+		t.Fatalf("GenerateReportHTML failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(data)
+
+	for _, want := range []string{
+		"<td>&lt;script&gt;alert(1)&lt;/script&gt;</td>",
+		`<div class="value">$12.50</div>`,
+		`<div class="value">$0.00</div>`,
+		"<li>Increase the budget of Spring</li>",
+		"No daily data.",
+		"#1877f2",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected the report to contain %q, got:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("Campaign names must be escaped")
+	}
+}
@@ -1,14 +1,20 @@
 package api
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/user/fb-ads/pkg/models"
 	"github.com/user/fb-ads/pkg/utils"
 )
 
@@ -24,12 +30,25 @@ const (
 	DefaultStatsDir = "stats"
 )
 
+// ParseStorageType returns the storage type of a name, "file" or "memory"
+func ParseStorageType(name string) (StorageType, error) {
+	switch StorageType(strings.ToLower(name)) {
+	case StorageTypeFile, "":
+		return StorageTypeFile, nil
+	case StorageTypeMemory:
+		return StorageTypeMemory, nil
+	}
+	return "", fmt.Errorf("unknown statistics storage %q (use file or memory)", name)
+}
+
 // StatisticsManager handles the storage, analysis, and retrieval of campaign performance statistics
 type StatisticsManager struct {
 	metricsCollector *MetricsCollector
 	storageType      StorageType
 	storageDir       string
 	memoryStore      map[string][]utils.CampaignPerformance
+	conversionValues models.ConversionValues
+	importedDays     map[string]bool // Days stored by StoreDailyStatistics in memory mode
 	mu               sync.RWMutex
 }
 
@@ -57,6 +76,8 @@ type AggregateStatistics struct {
 	AvgCPM          float64                    `json:"avg_cpm"`
 	AvgCPC          float64                    `json:"avg_cpc"`
 	AvgCPA          float64                    `json:"avg_cpa"`
+	SkippedRecords  int                        `json:"skipped_records,omitempty"` // Records left out for missing timestamps
+	ROIMethod       string                     `json:"roi_method,omitempty"`      // How the conversion values behind ROI were obtained
 	TrendImpressions *StatisticsTrend          `json:"trend_impressions,omitempty"`
 	TrendClicks      *StatisticsTrend          `json:"trend_clicks,omitempty"`
 	TrendCTR         *StatisticsTrend          `json:"trend_ctr,omitempty"`
@@ -97,23 +118,20 @@ func NewStatisticsManager(metricsCollector *MetricsCollector, storageType Storag
 		storageType:      storageType,
 		storageDir:       storageDir,
 		memoryStore:      make(map[string][]utils.CampaignPerformance),
+		conversionValues: models.ConversionValues{Default: models.DefaultConversionValue},
 		mu:               sync.RWMutex{},
 	}
 }
 
-// CollectAndStoreStatistics collects statistics for the given time range and stores them
-func (s *StatisticsManager) CollectAndStoreStatistics(timeRange TimeRange) error {
-	// Collect metrics
-	performances, err := s.metricsCollector.CollectCampaignMetrics(InsightsRequest{
-		Level:     "campaign",
-		TimeRange: timeRange,
-	})
-	if err != nil {
-		return fmt.Errorf("error collecting metrics: %w", err)
-	}
+// SetConversionValues sets the values of a conversion used for ROI when the stored
+// statistics have no conversion value reported by Facebook
+func (s *StatisticsManager) SetConversionValues(values models.ConversionValues) {
+	s.conversionValues = values
+}
 
-	// Store metrics
-	return s.StoreStatistics(performances)
+// ConversionValues returns the values of a conversion used for ROI
+func (s *StatisticsManager) ConversionValues() models.ConversionValues {
+	return s.conversionValues
 }
 
 // StoreStatistics stores collected campaign performance data
@@ -124,45 +142,10 @@ func (s *StatisticsManager) StoreStatistics(performances []utils.CampaignPerform
 
 	switch s.storageType {
 	case StorageTypeFile:
-		// Create date-based filename for today's statistics
-		today := time.Now().Format("2006-01-02")
-		dirPath := filepath.Join(s.storageDir, "daily")
-		
-		// Ensure directory exists
-		if err := os.MkdirAll(dirPath, 0755); err != nil {
-			return fmt.Errorf("error creating statistics directory: %w", err)
-		}
-		
-		// Create a file for each campaign to allow easier retrieval by campaign ID
-		for _, perf := range performances {
-			// Use campaign ID in filename for easy lookup
-			filename := fmt.Sprintf("%s_%s.json", perf.CampaignID, today)
-			filePath := filepath.Join(dirPath, filename)
-			
-			// Write performance data to file
-			data, err := json.MarshalIndent(perf, "", "  ")
-			if err != nil {
-				return fmt.Errorf("error marshaling performance data: %w", err)
-			}
-			
-			if err := os.WriteFile(filePath, data, 0644); err != nil {
-				return fmt.Errorf("error writing performance data to file: %w", err)
-			}
-		}
-		
-		// Also store aggregated data for the day
-		aggregatedFilename := fmt.Sprintf("aggregated_%s.json", today)
-		aggregatedFilePath := filepath.Join(dirPath, aggregatedFilename)
-		
-		// Marshal to JSON
-		aggregatedData, err := json.MarshalIndent(performances, "", "  ")
-		if err != nil {
-			return fmt.Errorf("error marshaling aggregated performance data: %w", err)
-		}
-		
-		// Write to file
-		if err := os.WriteFile(aggregatedFilePath, aggregatedData, 0644); err != nil {
-			return fmt.Errorf("error writing aggregated performance data to file: %w", err)
+		// Create date-based files for today's statistics in the account timezone
+		today := s.accountDay(time.Now()).Format("2006-01-02")
+		if err := s.writeDailyFiles(today, performances); err != nil {
+			return err
 		}
 		
 	case StorageTypeMemory:
@@ -178,6 +161,108 @@ func (s *StatisticsManager) StoreStatistics(performances []utils.CampaignPerform
 	return nil
 }
 
+// StoreDailyStatistics stores the performance records of one data day, replacing
+// whatever was stored for that day before. An empty slice marks the day as imported.
+func (s *StatisticsManager) StoreDailyStatistics(day time.Time, performances []utils.CampaignPerformance) error {
+	switch s.storageType {
+	case StorageTypeFile:
+		return s.writeDailyFiles(day.Format("2006-01-02"), performances)
+
+	case StorageTypeMemory:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// Drop records of the same day so re-imports don't duplicate data
+		date := day.Format("2006-01-02")
+		for campaignID, perfs := range s.memoryStore {
+			kept := perfs[:0]
+			for _, perf := range perfs {
+				if perf.LastUpdated.Format("2006-01-02") != date {
+					kept = append(kept, perf)
+				}
+			}
+			s.memoryStore[campaignID] = kept
+		}
+
+		for _, perf := range performances {
+			s.memoryStore[perf.CampaignID] = append(s.memoryStore[perf.CampaignID], perf)
+		}
+		if s.importedDays == nil {
+			s.importedDays = make(map[string]bool)
+		}
+		s.importedDays[date] = true
+	}
+
+	return nil
+}
+
+// HasDailyStatistics reports whether statistics for the given day have been stored
+func (s *StatisticsManager) HasDailyStatistics(day time.Time) bool {
+	date := day.Format("2006-01-02")
+
+	switch s.storageType {
+	case StorageTypeFile:
+		_, err := os.Stat(filepath.Join(s.storageDir, "daily", fmt.Sprintf("aggregated_%s.json", date)))
+		return err == nil
+
+	case StorageTypeMemory:
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return s.importedDays[date]
+	}
+
+	return false
+}
+
+// writeDailyFiles writes one file per campaign and an aggregated file for a day.
+// The aggregated file is written last and marks the day as complete.
+func (s *StatisticsManager) writeDailyFiles(date string, performances []utils.CampaignPerformance) error {
+	dirPath := filepath.Join(s.storageDir, "daily")
+
+	// Ensure directory exists
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return fmt.Errorf("error creating statistics directory: %w", err)
+	}
+
+	// Create a file for each campaign to allow easier retrieval by campaign ID
+	for _, perf := range performances {
+		// Use campaign ID in filename for easy lookup
+		filename := fmt.Sprintf("%s_%s.json", perf.CampaignID, date)
+		filePath := filepath.Join(dirPath, filename)
+
+		// Write performance data to file
+		data, err := json.MarshalIndent(perf, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling performance data: %w", err)
+		}
+
+		if err := os.WriteFile(filePath, data, 0644); err != nil {
+			return fmt.Errorf("error writing performance data to file: %w", err)
+		}
+	}
+
+	// Also store aggregated data for the day
+	aggregatedFilename := fmt.Sprintf("aggregated_%s.json", date)
+	aggregatedFilePath := filepath.Join(dirPath, aggregatedFilename)
+
+	if performances == nil {
+		performances = []utils.CampaignPerformance{}
+	}
+
+	// Marshal to JSON
+	aggregatedData, err := json.MarshalIndent(performances, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling aggregated performance data: %w", err)
+	}
+
+	// Write to file
+	if err := os.WriteFile(aggregatedFilePath, aggregatedData, 0644); err != nil {
+		return fmt.Errorf("error writing aggregated performance data to file: %w", err)
+	}
+
+	return nil
+}
+
 // GetCampaignStatistics retrieves statistics for a specific campaign for the given time range
 func (s *StatisticsManager) GetCampaignStatistics(campaignID string, startDate, endDate time.Time) ([]utils.CampaignPerformance, error) {
 	var performances []utils.CampaignPerformance
@@ -255,7 +340,7 @@ func (s *StatisticsManager) GetAllCampaignStatistics(startDate, endDate time.Tim
 		// Process each file within the date range
 		for _, file := range files {
 			// Skip aggregated files
-			if file.IsDir() || len(file.Name()) < 10 {
+			if file.IsDir() || len(file.Name()) < 10 || strings.HasPrefix(file.Name(), "aggregated_") {
 				continue
 			}
 			
@@ -344,6 +429,7 @@ func (s *StatisticsManager) AnalyzeStatistics(startDate, endDate time.Time) (*Ag
 	allCTR := make(map[time.Time]float64)
 	allCPM := make(map[time.Time]float64)
 	allConversions := make(map[time.Time]int)
+	var valued []utils.CampaignPerformance // Every record with its conversion value, for ROIMethod
 	
 	// Process each campaign's statistics
 	for campaignID, performances := range allStats {
@@ -353,6 +439,19 @@ func (s *StatisticsManager) AnalyzeStatistics(startDate, endDate time.Time) (*Ag
 			MinCPM:     math.MaxFloat64,
 		}
 		
+		// Records without a timestamp can't be placed on a day, so leave them out
+		dated := performances[:0:0]
+		for _, perf := range performances {
+			if perf.LastUpdated.IsZero() {
+				stats.SkippedRecords++
+				continue
+			}
+			s.conversionValues.Apply(&perf)
+			dated = append(dated, perf)
+			valued = append(valued, perf)
+		}
+		performances = dated
+		
 		if len(performances) == 0 {
 			continue
 		}
@@ -365,6 +464,7 @@ func (s *StatisticsManager) AnalyzeStatistics(startDate, endDate time.Time) (*Ag
 		campaignStats.LastDataPoint = performances[0].LastUpdated
 		
 		// Accumulate statistics across all performance records
+		var revenue float64
 		for _, perf := range performances {
 			// Update first/last data points
 			if perf.LastUpdated.Before(campaignStats.FirstDataPoint) {
@@ -380,6 +480,7 @@ func (s *StatisticsManager) AnalyzeStatistics(startDate, endDate time.Time) (*Ag
 			campaignStats.TotalClicks += perf.Clicks
 			campaignStats.TotalConversions += perf.Conversions
 			campaignStats.NumDataPoints++
+			revenue += perf.ConversionValue
 			
 			// Track min/max CPM
 			if perf.CPM < campaignStats.MinCPM {
@@ -390,7 +491,7 @@ func (s *StatisticsManager) AnalyzeStatistics(startDate, endDate time.Time) (*Ag
 			}
 			
 			// Aggregate for global trends
-			day := time.Date(perf.LastUpdated.Year(), perf.LastUpdated.Month(), perf.LastUpdated.Day(), 0, 0, 0, 0, time.Local)
+			day := s.accountDay(perf.LastUpdated)
 			allImpressions[day] += perf.Impressions
 			allClicks[day] += perf.Clicks
 			allSpend[day] += perf.Spend
@@ -415,9 +516,11 @@ func (s *StatisticsManager) AnalyzeStatistics(startDate, endDate time.Time) (*Ag
 		
 		if campaignStats.TotalConversions > 0 {
 			campaignStats.AvgCPA = campaignStats.TotalSpend / float64(campaignStats.TotalConversions)
-			// Calculate ROI - assuming $50 average order value per conversion
-			avgOrderValue := 50.0
-			campaignStats.ROI = (float64(campaignStats.TotalConversions) * avgOrderValue - campaignStats.TotalSpend) / campaignStats.TotalSpend * 100
+		}
+		
+		// ROI from the reported conversion values, or the conversions valued at the configured value
+		if revenue > 0 && campaignStats.TotalSpend > 0 {
+			campaignStats.ROI = (revenue - campaignStats.TotalSpend) / campaignStats.TotalSpend * 100
 		}
 		
 		// Add to total statistics
@@ -430,6 +533,8 @@ func (s *StatisticsManager) AnalyzeStatistics(startDate, endDate time.Time) (*Ag
 		stats.CampaignStats[campaignID] = campaignStats
 	}
 	
+	stats.ROIMethod = s.conversionValues.Method(valued)
+	
 	// Calculate global averages
 	if stats.TotalClicks > 0 {
 		stats.AvgCPC = stats.TotalSpend / float64(stats.TotalClicks)
@@ -494,6 +599,14 @@ func (s *StatisticsManager) AnalyzeStatistics(startDate, endDate time.Time) (*Ag
 	return stats, nil
 }
 
+// accountDay returns midnight of the account day containing t
+func (s *StatisticsManager) accountDay(t time.Time) time.Time {
+	if s.metricsCollector == nil {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	return s.metricsCollector.Clock().Day(t)
+}
+
 // createTrend creates a trend analysis for a specific metric
 func (s *StatisticsManager) createTrend(metricName string, dates []time.Time, valueFunc func(time.Time) float64) *StatisticsTrend {
 	if len(dates) == 0 {
@@ -573,30 +686,56 @@ func (s *StatisticsManager) ExportStatisticsCSV(stats *AggregateStatistics, file
 	}
 	defer file.Close()
 	
+	if err := WriteStatisticsCSV(file, stats); err != nil {
+		return err
+	}
+	
+	return file.Close()
+}
+
+// WriteStatisticsCSV writes campaign statistics as CSV, ordered by campaign ID.
+// Rows are formatted into a reused buffer and streamed to w, so memory use does
+// not grow with the number of campaigns beyond the list of their IDs.
+func WriteStatisticsCSV(w io.Writer, stats *AggregateStatistics) error {
+	out := bufio.NewWriter(w)
+	
 	// Write header
 	header := "Campaign ID,Campaign Name,Impressions,Clicks,CTR (%),Spend ($),CPM ($),CPC ($),Conversions,CPA ($),ROI (%)\n"
-	if _, err := file.WriteString(header); err != nil {
+	if _, err := out.WriteString(header); err != nil {
 		return fmt.Errorf("error writing CSV header: %w", err)
 	}
 	
+	campaignIDs := make([]string, 0, len(stats.CampaignStats))
+	for campaignID := range stats.CampaignStats {
+		campaignIDs = append(campaignIDs, campaignID)
+	}
+	sort.Strings(campaignIDs)
+	
 	// Write campaign data
-	for _, campaign := range stats.CampaignStats {
-		line := fmt.Sprintf(
-			"%s,%s,%d,%d,%.2f,%.2f,%.2f,%.2f,%d,%.2f,%.2f\n",
-			campaign.CampaignID,
-			escapeCsvField(campaign.Name),
-			campaign.TotalImpressions,
-			campaign.TotalClicks,
-			campaign.AvgCTR,
-			campaign.TotalSpend,
-			campaign.AvgCPM,
-			campaign.AvgCPC,
-			campaign.TotalConversions,
-			campaign.AvgCPA,
-			campaign.ROI,
-		)
+	line := make([]byte, 0, 256)
+	for _, campaignID := range campaignIDs {
+		campaign := stats.CampaignStats[campaignID]
 		
-		if _, err := file.WriteString(line); err != nil {
+		line = append(line[:0], campaign.CampaignID...)
+		line = append(line, ',')
+		line = appendCsvField(line, campaign.Name)
+		line = append(line, ',')
+		line = strconv.AppendInt(line, int64(campaign.TotalImpressions), 10)
+		line = append(line, ',')
+		line = strconv.AppendInt(line, int64(campaign.TotalClicks), 10)
+		for _, value := range [...]float64{campaign.AvgCTR, campaign.TotalSpend, campaign.AvgCPM, campaign.AvgCPC} {
+			line = append(line, ',')
+			line = strconv.AppendFloat(line, value, 'f', 2, 64)
+		}
+		line = append(line, ',')
+		line = strconv.AppendInt(line, int64(campaign.TotalConversions), 10)
+		for _, value := range [...]float64{campaign.AvgCPA, campaign.ROI} {
+			line = append(line, ',')
+			line = strconv.AppendFloat(line, value, 'f', 2, 64)
+		}
+		line = append(line, '\n')
+		
+		if _, err := out.Write(line); err != nil {
 			return fmt.Errorf("error writing CSV line: %w", err)
 		}
 	}
@@ -614,37 +753,31 @@ func (s *StatisticsManager) ExportStatisticsCSV(stats *AggregateStatistics, file
 		stats.AvgCPA,
 	)
 	
-	if _, err := file.WriteString("\n" + totalsLine); err != nil {
+	if _, err := out.WriteString("\n" + totalsLine); err != nil {
 		return fmt.Errorf("error writing CSV totals: %w", err)
 	}
 	
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("error writing CSV file: %w", err)
+	}
+	
 	return nil
 }
 
-// Escape CSV field to handle commas and quotes
-func escapeCsvField(field string) string {
-	needsQuotes := false
-	for i := 0; i < len(field); i++ {
-		if field[i] == '"' || field[i] == ',' || field[i] == '\n' || field[i] == '\r' {
-			needsQuotes = true
-			break
-		}
-	}
-	
-	if !needsQuotes {
-		return field
+// appendCsvField appends a CSV field to buf, quoting it if it contains commas, quotes or newlines
+func appendCsvField(buf []byte, field string) []byte {
+	if !strings.ContainsAny(field, "\",\n\r") {
+		return append(buf, field...)
 	}
 	
 	// Replace double quotes with two double quotes and wrap in quotes
-	result := `"`
+	buf = append(buf, '"')
 	for i := 0; i < len(field); i++ {
 		if field[i] == '"' {
-			result += "\"\""
+			buf = append(buf, '"', '"')
 		} else {
-			result += string(field[i])
+			buf = append(buf, field[i])
 		}
 	}
-	result += `"`
-	
-	return result
-}
\ No newline at end of file
+	return append(buf, '"')
+}
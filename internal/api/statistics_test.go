@@ -0,0 +1,78 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/pkg/models"
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+func TestAnalyzeStatistics_SkipsZeroTimestamps(t *testing.T) {
+	dir := t.TempDir()
+	stats := NewStatisticsManager(nil, StorageTypeFile, dir)
+
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	err := stats.StoreDailyStatistics(day, []utils.CampaignPerformance{
+		{CampaignID: "111", Name: "Alpha", Impressions: 1000, Clicks: 10, LastUpdated: day},
+		{CampaignID: "222", Name: "Beta", Impressions: 500, Clicks: 5},
+	})
+	if err != nil {
+		t.Fatalf("Error storing statistics: %v", err)
+	}
+
+	analysis, err := stats.AnalyzeStatistics(day.AddDate(0, 0, -1), day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("AnalyzeStatistics failed: %v", err)
+	}
+
+	if analysis.SkippedRecords != 1 {
+		t.Errorf("Expected 1 skipped record, got %d", analysis.SkippedRecords)
+	}
+	if _, ok := analysis.CampaignStats["222"]; ok {
+		t.Errorf("Expected the undated campaign record to be left out")
+	}
+	if analysis.TotalImpressions != 1000 {
+		t.Errorf("Expected only dated impressions in totals, got %d", analysis.TotalImpressions)
+	}
+
+	// No trend point lands in year 1
+	if analysis.TrendImpressions != nil {
+		for _, point := range analysis.TrendImpressions.Timestamps {
+			if point.Year() == 1 {
+				t.Errorf("Unexpected trend point at %s", point)
+			}
+		}
+	}
+}
+
+func TestAnalyzeStatistics_ConversionValues(t *testing.T) {
+	stats := NewStatisticsManager(nil, StorageTypeFile, t.TempDir())
+	stats.SetConversionValues(models.ConversionValues{Default: 180})
+
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	err := stats.StoreDailyStatistics(day, []utils.CampaignPerformance{
+		{CampaignID: "111", Name: "Estimated", Spend: 100, Conversions: 2, LastUpdated: day},
+		{CampaignID: "222", Name: "Reported", Spend: 100, Conversions: 2, ConversionValue: 150, ROASSource: models.ROASSourceReported, LastUpdated: day},
+	})
+	if err != nil {
+		t.Fatalf("Error storing statistics: %v", err)
+	}
+
+	analysis, err := stats.AnalyzeStatistics(day, day)
+	if err != nil {
+		t.Fatalf("AnalyzeStatistics failed: %v", err)
+	}
+
+	// 2 conversions at $180 against $100 spend, and $150 reported against $100
+	if roi := analysis.CampaignStats["111"].ROI; roi != 260 {
+		t.Errorf("Expected an ROI of 260%% at the configured value, got %v", roi)
+	}
+	if roi := analysis.CampaignStats["222"].ROI; roi != 50 {
+		t.Errorf("Expected an ROI of 50%% from the reported value, got %v", roi)
+	}
+	if !strings.Contains(analysis.ROIMethod, "where available, otherwise estimated at $180.00") {
+		t.Errorf("Expected the ROI method to name both sources, got %q", analysis.ROIMethod)
+	}
+}
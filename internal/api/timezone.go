@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+// accountLocations caches the timezone of each ad account for the life of the process
+var (
+	accountLocations   = make(map[string]*time.Location)
+	accountLocationsMu sync.Mutex
+)
+
+// accountLocationRetryDelay is how long the host timezone stands in for an account whose
+// timezone could not be fetched, before fetching it again
+const accountLocationRetryDelay = 5 * time.Minute
+
+// accountLocationRetries holds when the timezone of an account that failed to load is
+// fetched again; guarded by accountLocationsMu
+var accountLocationRetries = make(map[string]time.Time)
+
+// AccountClock computes dates in the ad account's timezone.
+// Facebook reports insights by the account's day, so "yesterday" or "last 7 days"
+// built from the host clock can be off by one day compared to Ads Manager.
+type AccountClock struct {
+	httpClient *http.Client
+	auth       *auth.FacebookAuth
+	accountID  string
+	now        func() time.Time
+}
+
+// NewAccountClock creates a new clock for an ad account
+func NewAccountClock(auth *auth.FacebookAuth, accountID string) *AccountClock {
+	return &AccountClock{
+		httpClient: auth.HTTPClient(),
+		auth:       auth,
+		accountID:  accountID,
+		now:        time.Now,
+	}
+}
+
+// Location returns the timezone of the ad account.
+// The timezone is fetched once per account. When it cannot be fetched the host timezone
+// is used, and the fetch is tried again after accountLocationRetryDelay.
+func (c *AccountClock) Location() *time.Location {
+	return c.LocationContext(context.Background())
+}
+
+// LocationContext returns the timezone of the ad account like Location. Cancelling the
+// context stops the fetch and the host timezone is used, without waiting for the retry.
+func (c *AccountClock) LocationContext(ctx context.Context) *time.Location {
+	accountLocationsMu.Lock()
+	loc, ok := accountLocations[c.accountID]
+	retryAt, failed := accountLocationRetries[c.accountID]
+	accountLocationsMu.Unlock()
+
+	if ok {
+		return loc
+	}
+	if failed && c.now().Before(retryAt) {
+		return time.Local
+	}
+
+	// The fetch runs without the lock so a slow account doesn't hold up the others
+	loc, err := c.fetchLocation(ctx)
+	if err != nil && ctx.Err() != nil {
+		return time.Local
+	}
+
+	accountLocationsMu.Lock()
+	defer accountLocationsMu.Unlock()
+	if err != nil {
+		// stdout may carry an export, so the warning goes to stderr
+		fmt.Fprintf(os.Stderr, "Warning: could not determine account timezone, using %s: %v\n", time.Local, err)
+		accountLocationRetries[c.accountID] = c.now().Add(accountLocationRetryDelay)
+		return time.Local
+	}
+
+	delete(accountLocationRetries, c.accountID)
+	accountLocations[c.accountID] = loc
+	return loc
+}
+
+// fetchLocation reads timezone_name from the ad account. Rate limited requests are
+// retried like every other API call, so a passing throttle doesn't cost the timezone.
+func (c *AccountClock) fetchLocation(ctx context.Context) (*time.Location, error) {
+	params := url.Values{}
+	params.Set("fields", "timezone_name")
+
+	endpoint := fmt.Sprintf("act_%s", c.accountID)
+
+	req, err := c.auth.GetAuthenticatedRequestContext(ctx, endpoint, params)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := auth.DoWithRetry(c.httpClient, req, c.auth.Retry, c.accountID)
+	if err != nil {
+		return nil, fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
+	}
+
+	var account struct {
+		TimezoneName string `json:"timezone_name"`
+	}
+	if err := json.Unmarshal(body, &account); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+
+	if account.TimezoneName == "" {
+		return nil, fmt.Errorf("account has no timezone_name")
+	}
+
+	loc, err := time.LoadLocation(account.TimezoneName)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", account.TimezoneName, err)
+	}
+
+	return loc, nil
+}
+
+// Now returns the current time in the account timezone
+func (c *AccountClock) Now() time.Time {
+	return c.now().In(c.Location())
+}
+
+// Today returns midnight of the current day in the account timezone
+func (c *AccountClock) Today() time.Time {
+	return c.Day(c.now())
+}
+
+// Yesterday returns midnight of the previous day in the account timezone
+func (c *AccountClock) Yesterday() time.Time {
+	return c.Today().AddDate(0, 0, -1)
+}
+
+// Day returns midnight of the account day containing t
+func (c *AccountClock) Day(t time.Time) time.Time {
+	local := t.In(c.Location())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+}
+
+// LastDays returns the range of the last n complete days, ending yesterday (like "last_7d")
+func (c *AccountClock) LastDays(n int) TimeRange {
+	yesterday := c.Yesterday()
+	return TimeRange{
+		Since: yesterday.AddDate(0, 0, -(n - 1)).Format("2006-01-02"),
+		Until: yesterday.Format("2006-01-02"),
+	}
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+// roundTripFunc answers HTTP requests without a network
+type roundTripFunc func(req *http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+// newTestClock returns a clock whose account reports the given timezone
+func newTestClock(t *testing.T, accountID, timezoneName string, now time.Time) (*AccountClock, *int) {
+	t.Helper()
+
+	requests := 0
+	clock := NewAccountClock(auth.NewFacebookAuth("app", "secret", "token", "v22.0"), accountID)
+	clock.now = func() time.Time { return now }
+	clock.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		requests++
+		if !strings.HasSuffix(req.URL.Path, "/act_"+accountID) || req.URL.Query().Get("fields") != "timezone_name" {
+			t.Errorf("Unexpected request: %s", req.URL)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"timezone_name":"` + timezoneName + `","id":"act_` + accountID + `"}`)),
+			Header:     make(http.Header),
+		}
+	})}
+
+	return clock, &requests
+}
+
+func TestAccountClock_UsesAccountTimezone(t *testing.T) {
+	// 03:30 UTC on March 10 is still the evening of March 9 in Los Angeles
+	now := time.Date(2024, 3, 10, 3, 30, 0, 0, time.UTC)
+
+	// Run as if the host were in Tokyo, where it is already midday on March 10
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone database not available: %v", err)
+	}
+	hostLocal := time.Local
+	time.Local = tokyo
+	defer func() { time.Local = hostLocal }()
+
+	clock, _ := newTestClock(t, "tz-la", "America/Los_Angeles", now)
+
+	if got := clock.Location().String(); got != "America/Los_Angeles" {
+		t.Fatalf("Expected America/Los_Angeles, got %s", got)
+	}
+
+	if got := clock.Today().Format("2006-01-02"); got != "2024-03-09" {
+		t.Errorf("Expected today 2024-03-09, got %s", got)
+	}
+	if got := clock.Yesterday().Format("2006-01-02"); got != "2024-03-08" {
+		t.Errorf("Expected yesterday 2024-03-08, got %s", got)
+	}
+
+	timeRange := clock.LastDays(7)
+	if timeRange.Since != "2024-03-02" || timeRange.Until != "2024-03-08" {
+		t.Errorf("Expected last 7 days 2024-03-02..2024-03-08, got %s..%s", timeRange.Since, timeRange.Until)
+	}
+
+	// The host clock would have put the same instant on another day
+	if hostDay := now.In(time.Local).Format("2006-01-02"); hostDay == clock.Today().Format("2006-01-02") {
+		t.Errorf("Expected the account day to differ from the host day %s", hostDay)
+	}
+}
+
+func TestAccountClock_DayBucketing(t *testing.T) {
+	clock, _ := newTestClock(t, "tz-bucket", "America/New_York", time.Now())
+
+	// 02:00 UTC on June 1 belongs to May 31 in New York
+	day := clock.Day(time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC))
+	if got := day.Format("2006-01-02 15:04"); got != "2024-05-31 00:00" {
+		t.Errorf("Expected bucket 2024-05-31 00:00, got %s", got)
+	}
+
+	stats := NewStatisticsManager(&MetricsCollector{clock: clock}, StorageTypeMemory, "")
+	if got := stats.accountDay(time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC)); !got.Equal(day) {
+		t.Errorf("Expected statistics to bucket into %s, got %s", day, got)
+	}
+}
+
+func TestAccountClock_CachesTimezone(t *testing.T) {
+	clock, requests := newTestClock(t, "tz-cache", "Europe/Berlin", time.Now())
+
+	clock.Location()
+	clock.Today()
+	clock.LastDays(30)
+
+	// A second clock for the same account reuses the cached timezone
+	other, otherRequests := newTestClock(t, "tz-cache", "Europe/Berlin", time.Now())
+	other.Yesterday()
+
+	if *requests != 1 || *otherRequests != 0 {
+		t.Errorf("Expected a single timezone request, got %d and %d", *requests, *otherRequests)
+	}
+}
+
+func TestAccountClock_RetriesFailedTimezone(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock, requests := newTestClock(t, "tz-retry", "Europe/Berlin", now)
+	transport := clock.httpClient.Transport
+	failing := true
+	clock.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		if failing {
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Body:       io.NopCloser(strings.NewReader(`{"error":{"message":"Service temporarily unavailable","code":2}}`)),
+				Header:     make(http.Header),
+			}
+		}
+		resp, _ := transport.RoundTrip(req)
+		return resp
+	})}
+
+	if got := clock.Location(); got != time.Local {
+		t.Fatalf("Expected the host timezone after a failure, got %s", got)
+	}
+
+	// Within the retry delay the host timezone is used without another request
+	failing = false
+	clock.now = func() time.Time { return now.Add(time.Minute) }
+	if got := clock.Location(); got != time.Local || *requests != 0 {
+		t.Errorf("Expected the host timezone without a request, got %s after %d requests", got, *requests)
+	}
+
+	// Afterwards the timezone is fetched again and kept
+	clock.now = func() time.Time { return now.Add(accountLocationRetryDelay) }
+	if got := clock.Location().String(); got != "Europe/Berlin" || *requests != 1 {
+		t.Errorf("Expected Europe/Berlin after one more request, got %s after %d requests", got, *requests)
+	}
+}
+
+func TestAccountClock_RetriesRateLimitedTimezone(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock, requests := newTestClock(t, "tz-throttled", "Europe/Berlin", now)
+	clock.auth.Retry = auth.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	transport := clock.httpClient.Transport
+	throttled := 0
+	clock.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		if throttled == 0 {
+			throttled++
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Body:       io.NopCloser(strings.NewReader(`{"error":{"message":"User request limit reached","code":17}}`)),
+				Header:     make(http.Header),
+			}
+		}
+		resp, _ := transport.RoundTrip(req)
+		return resp
+	})}
+
+	// A passing rate limit is retried instead of falling back to the host timezone
+	if got := clock.Location().String(); got != "Europe/Berlin" || throttled != 1 || *requests != 1 {
+		t.Errorf("Expected Europe/Berlin after a retry, got %s after %d throttled and %d requests", got, throttled, *requests)
+	}
+}
+
+func TestAccountClock_CancelledTimezoneFetch(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock, requests := newTestClock(t, "tz-cancelled", "Europe/Berlin", now)
+	clock.auth.Retry = auth.RetryPolicy{MaxRetries: 2, BaseDelay: time.Minute, MaxDelay: time.Minute}
+	transport := clock.httpClient.Transport
+
+	// The command is cancelled while the fetch waits out a rate limit
+	ctx, cancel := context.WithCancel(context.Background())
+	clock.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		if ctx.Err() == nil {
+			cancel()
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Body:       io.NopCloser(strings.NewReader(`{"error":{"message":"User request limit reached","code":17}}`)),
+				Header:     make(http.Header),
+			}
+		}
+		resp, _ := transport.RoundTrip(req)
+		return resp
+	})}
+
+	if got := clock.LocationContext(ctx); got != time.Local {
+		t.Errorf("Expected the host timezone for a cancelled fetch, got %s", got)
+	}
+
+	// A cancelled fetch is not a failure, the next call fetches the timezone right away
+	if got := clock.Location().String(); got != "Europe/Berlin" || *requests != 1 {
+		t.Errorf("Expected Europe/Berlin after one request, got %s after %d requests", got, *requests)
+	}
+}
@@ -1,13 +1,13 @@
 package audience
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
 	"net/url"
-	"os"
 	"sort"
 	"strings"
 	"time"
@@ -58,21 +58,56 @@ type AudienceAnalyzer struct {
 	httpClient *http.Client
 	auth       *auth.FacebookAuth
 	accountID  string
-	segments   map[string]AudienceSegment // Cache for audience segments
+	segments   *segmentCache  // Cache for researched audience segments
+	location   *time.Location // Ad account timezone used for date ranges
+	debug      io.Writer      // Receives raw API responses when set
 }
 
 // NewAudienceAnalyzer creates a new audience analyzer
 func NewAudienceAnalyzer(auth *auth.FacebookAuth, accountID string) *AudienceAnalyzer {
 	return &AudienceAnalyzer{
-		httpClient: &http.Client{},
+		httpClient: auth.HTTPClient(),
 		auth:       auth,
 		accountID:  accountID,
-		segments:   make(map[string]AudienceSegment),
+		segments:   newSegmentCache(DefaultSegmentCacheSize, DefaultSegmentCacheTTL),
+		location:   time.Local,
 	}
 }
 
+// doWithRetry sends a request, retrying it while Facebook rejects it with a rate limit
+func (a *AudienceAnalyzer) doWithRetry(req *http.Request) (*http.Response, error) {
+	return auth.DoWithRetry(a.httpClient, req, a.auth.Retry, a.accountID)
+}
+
+// SetLocation sets the ad account timezone used to build date ranges
+func (a *AudienceAnalyzer) SetLocation(loc *time.Location) {
+	a.location = loc
+}
+
+// SetDebug writes the raw search and delivery estimate responses to w, e.g. os.Stderr so
+// they don't mix with the output of a command. A nil writer turns this off.
+func (a *AudienceAnalyzer) SetDebug(w io.Writer) {
+	a.debug = w
+}
+
+// SetCacheLimits bounds the segment cache by size and age.
+// A zero maxSize or ttl disables that bound.
+func (a *AudienceAnalyzer) SetCacheLimits(maxSize int, ttl time.Duration) {
+	a.segments.setLimits(maxSize, ttl)
+}
+
+// Segments returns a copy of the cached segments that haven't expired
+func (a *AudienceAnalyzer) Segments() []AudienceSegment {
+	return a.segments.snapshot()
+}
+
 // Search retrieves targeting options
 func (a *AudienceAnalyzer) Search(searchType string, class string, query string) ([]AudienceSegment, error) {
+	return a.SearchContext(context.Background(), searchType, class, query)
+}
+
+// SearchContext retrieves targeting options
+func (a *AudienceAnalyzer) SearchContext(ctx context.Context, searchType string, class string, query string) ([]AudienceSegment, error) {
 	params := url.Values{}
 	params.Set("type", searchType)
 	if len(class) > 0 {
@@ -82,12 +117,12 @@ func (a *AudienceAnalyzer) Search(searchType string, class string, query string)
 		params.Set("q", query)
 	}
 
-	req, err := a.auth.GetAuthenticatedRequest("search", params)
+	req, err := a.auth.GetAuthenticatedRequestContext(ctx, "search", params)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
-	resp, err := a.httpClient.Do(req)
+	resp, err := a.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("error executing request: %w", err)
 	}
@@ -95,17 +130,15 @@ func (a *AudienceAnalyzer) Search(searchType string, class string, query string)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+		return nil, fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
 	}
 
-	// Read response body for debugging
+	// Read the response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("error reading response body: %w", err)
 	}
-
-	// Print raw response for debugging (uncomment if needed)
-	//fmt.Printf("Raw API response: %s\n", string(body))
+	a.debugResponse(req, body)
 
 	// Decode the JSON response
 	var audienceResp AudienceResponse
@@ -114,54 +147,11 @@ func (a *AudienceAnalyzer) Search(searchType string, class string, query string)
 	}
 
 	// Update our segments cache
-	for _, segment := range audienceResp.Data {
-		a.segments[segment.ID] = segment
-	}
+	a.segments.put(audienceResp.Data)
 
 	return audienceResp.Data, nil
 }
 
-// CollectSegmentStatistics gathers performance statistics for audience segments
-func (a *AudienceAnalyzer) CollectSegmentStatistics(campaignID string, days int) error {
-	// Set up endpoint and parameters for insights API call
-	endpoint := fmt.Sprintf("/%s/insights", campaignID)
-	params := url.Values{}
-
-	// Get data from last N days
-	endDate := time.Now().Format("2006-01-02")
-	startDate := time.Now().AddDate(0, 0, -days).Format("2006-01-02")
-	params.Set("time_range", fmt.Sprintf(`{"since":"%s","until":"%s"}`, startDate, endDate))
-
-	// Try a simplified approach with a single demographic breakdown
-	// This avoids potential conflicts with action_type that cause API errors
-	params.Set("breakdowns", "age")
-
-	// Explicitly request only standard metrics that don't require action_type
-	params.Set("fields", "impressions,clicks,spend,cpm,ctr")
-
-	req, err := a.auth.GetAuthenticatedRequest(endpoint, params)
-	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
-	}
-
-	resp, err := a.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("error executing request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error: %s - %s", resp.Status, string(body))
-	}
-
-	// Process the response and update segment statistics
-	// This is a simplified implementation; in a real system,
-	// you would parse the response and update the appropriate segments
-
-	return nil
-}
-
 // FilterAudiences filters audience segments based on criteria
 func (a *AudienceAnalyzer) FilterAudiences(options map[string]interface{}) ([]AudienceSegment, error) {
 	var filtered []AudienceSegment
@@ -172,8 +162,8 @@ func (a *AudienceAnalyzer) FilterAudiences(options map[string]interface{}) ([]Au
 	types, hasTypes := options["types"].([]string)
 	keywords, hasKeywords := options["keywords"].([]string)
 
-	// Apply filters to all segments
-	for _, segment := range a.segments {
+	// Apply filters to a snapshot so concurrent searches don't race with filtering
+	for _, segment := range a.Segments() {
 		// Filter by size
 		if hasMinSize && segment.LowerBound < minSize {
 			continue
@@ -223,29 +213,26 @@ func (a *AudienceAnalyzer) FilterAudiences(options map[string]interface{}) ([]Au
 	return filtered, nil
 }
 
-// ExportAudienceData exports audience data to a file
-func (a *AudienceAnalyzer) ExportAudienceData(filePath string, data []AudienceSegment) error {
-	jsonData, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return fmt.Errorf("error marshaling audience data: %w", err)
-	}
-
-	err = os.WriteFile(filePath, jsonData, 0644)
-	if err != nil {
-		return fmt.Errorf("error writing audience data to file: %w", err)
-	}
+// ReachEstimateResponse represents the API response from the reach_estimate endpoint
+type ReachEstimateResponse struct {
+	Data []ReachEstimate `json:"data"`
+}
 
-	return nil
+// ReachEstimate is a single audience size estimate
+type ReachEstimate struct {
+	EstimateReady bool  `json:"estimate_ready"`
+	Users         int64 `json:"users"`
+	LowerBound    int64 `json:"lower_bound"`
+	UpperBound    int64 `json:"upper_bound"`
 }
 
-// ReachEstimateResponse represents the API response from the reach_estimate endpoint
-type ReachEstimateResponse struct {
-	Data []struct {
-		EstimateReady bool  `json:"estimate_ready"`
-		Users         int64 `json:"users"`
-		LowerBound    int64 `json:"lower_bound"`
-		UpperBound    int64 `json:"upper_bound"`
-	} `json:"data"`
+// Bounds returns the range of the estimate, or the number of users for both bounds when
+// Facebook returned no range
+func (r ReachEstimate) Bounds() (int64, int64) {
+	if r.LowerBound == 0 && r.UpperBound == 0 {
+		return r.Users, r.Users
+	}
+	return r.LowerBound, r.UpperBound
 }
 
 // FormatNumberReadable formats a number to a human-readable string (e.g., 1.2M, 450K)
@@ -290,22 +277,98 @@ func FormatAudienceRange(lower, upper int64) string {
 	return fmt.Sprintf("%s - %s", FormatNumberReadable(lower), FormatNumberReadable(upper))
 }
 
-// GetAudienceSize retrieves the estimated audience size for a specific interest
-func (a *AudienceAnalyzer) GetAudienceSize(interestID string) (int64, error) {
-	// Construct the targeting spec for the interest
-	targetingSpec := map[string]interface{}{
-		"geo_locations": map[string]interface{}{
-			"countries": []string{"US"}, // Default to US, could be made configurable
-		},
-		"interests": []map[string]string{
-			{"id": interestID},
-		},
+// DefaultAudienceCountries are the countries audience sizes are estimated for when the
+// targeting has no geo_locations
+var DefaultAudienceCountries = []string{"US"}
+
+// GetAudienceSize retrieves the lower and upper bound of the estimated audience of a
+// targeting spec
+func (a *AudienceAnalyzer) GetAudienceSize(spec TargetingSpec) (int64, int64, error) {
+	return a.GetAudienceSizeContext(context.Background(), spec)
+}
+
+// GetAudienceSizeContext retrieves the lower and upper bound of the estimated audience of
+// a targeting spec. Without countries the default countries are used.
+func (a *AudienceAnalyzer) GetAudienceSizeContext(ctx context.Context, spec TargetingSpec) (int64, int64, error) {
+	if err := spec.Validate(); err != nil {
+		return 0, 0, fmt.Errorf("invalid targeting: %w", err)
+	}
+	estimate, err := a.deliveryEstimate(ctx, spec.Map())
+	if err != nil {
+		return 0, 0, err
+	}
+	lower, upper := estimate.Bounds()
+	return lower, upper, nil
+}
+
+// GetAudienceSizeForTargeting retrieves the estimated audience size for an interest within
+// a targeting spec, e.g. with geo_locations, age_min, age_max and genders
+func (a *AudienceAnalyzer) GetAudienceSizeForTargeting(interestID string, targeting map[string]interface{}) (int64, error) {
+	return a.GetAudienceSizeForTargetingContext(context.Background(), interestID, targeting)
+}
+
+// GetAudienceSizeForTargetingContext retrieves the estimated audience size for an interest
+// within a targeting spec. The spec is not modified; without geo_locations the default
+// countries are used.
+func (a *AudienceAnalyzer) GetAudienceSizeForTargetingContext(ctx context.Context, interestID string, targeting map[string]interface{}) (int64, error) {
+	return a.GetAudienceSizeForInterestsContext(ctx, []string{interestID}, targeting)
+}
+
+// GetAudienceSizeForInterests retrieves the estimated number of people within a targeting
+// spec who have any of the interests
+func (a *AudienceAnalyzer) GetAudienceSizeForInterests(interestIDs []string, targeting map[string]interface{}) (int64, error) {
+	return a.GetAudienceSizeForInterestsContext(context.Background(), interestIDs, targeting)
+}
+
+// GetAudienceSizeForInterestsContext retrieves the estimated number of people within a
+// targeting spec who have any of the interests. The spec is not modified; without
+// geo_locations the default countries are used.
+func (a *AudienceAnalyzer) GetAudienceSizeForInterestsContext(ctx context.Context, interestIDs []string, targeting map[string]interface{}) (int64, error) {
+	if len(interestIDs) == 0 {
+		return 0, fmt.Errorf("no interests to estimate the audience of")
 	}
+	return a.EstimateReachContext(ctx, interestsTargeting(interestIDs, targeting))
+}
 
+// interestsTargeting returns a copy of the targeting spec narrowed to people with any of
+// the interests
+func interestsTargeting(interestIDs []string, targeting map[string]interface{}) map[string]interface{} {
+	spec := make(map[string]interface{}, len(targeting)+2)
+	for key, value := range targeting {
+		spec[key] = value
+	}
+
+	if _, ok := spec["geo_locations"]; !ok {
+		spec["geo_locations"] = map[string]interface{}{
+			"countries": DefaultAudienceCountries,
+		}
+	}
+	spec["interests"] = targetingIDs(interestIDs)
+
+	return spec
+}
+
+// EstimateReach returns the estimated number of people reached by a targeting spec
+func (a *AudienceAnalyzer) EstimateReach(targetingSpec map[string]interface{}) (int64, error) {
+	return a.EstimateReachContext(context.Background(), targetingSpec)
+}
+
+// EstimateReachContext returns the estimated number of people reached by a targeting spec
+func (a *AudienceAnalyzer) EstimateReachContext(ctx context.Context, targetingSpec map[string]interface{}) (int64, error) {
+	estimate, err := a.deliveryEstimate(ctx, targetingSpec)
+	if err != nil {
+		return 0, err
+	}
+
+	return estimate.Users, nil
+}
+
+// deliveryEstimate queries the delivery_estimate endpoint for a targeting spec
+func (a *AudienceAnalyzer) deliveryEstimate(ctx context.Context, targetingSpec map[string]interface{}) (*ReachEstimate, error) {
 	// Marshal to JSON
 	targetingJSON, err := json.Marshal(targetingSpec)
 	if err != nil {
-		return 0, fmt.Errorf("error marshaling targeting spec: %w", err)
+		return nil, fmt.Errorf("error marshaling targeting spec: %w", err)
 	}
 
 	// Set up the parameters for the reach_estimate endpoint
@@ -316,42 +379,46 @@ func (a *AudienceAnalyzer) GetAudienceSize(interestID string) (int64, error) {
 	// Build the endpoint with account ID
 	endpoint := fmt.Sprintf("act_%s/delivery_estimate", a.accountID)
 
-	req, err := a.auth.GetAuthenticatedRequest(endpoint, params)
+	req, err := a.auth.GetAuthenticatedRequestContext(ctx, endpoint, params)
 	if err != nil {
-		return 0, fmt.Errorf("error creating request: %w", err)
+		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
-	resp, err := a.httpClient.Do(req)
+	resp, err := a.doWithRetry(req)
 	if err != nil {
-		return 0, fmt.Errorf("error executing request: %w", err)
+		return nil, fmt.Errorf("error executing request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return 0, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+		return nil, fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
 	}
 
-	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return 0, fmt.Errorf("error reading response body: %w", err)
+		return nil, fmt.Errorf("error reading response body: %w", err)
 	}
-	fmt.Printf("Raw `delivery_estimate` response: %s\n", string(body))
+	a.debugResponse(req, body)
 
 	// Decode the JSON response
 	var estimateResp ReachEstimateResponse
 	if err := json.Unmarshal(body, &estimateResp); err != nil {
-		return 0, fmt.Errorf("error decoding response: %w", err)
+		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
 	// Check if we have data
 	if len(estimateResp.Data) == 0 {
-		return 0, fmt.Errorf("no reach estimate data returned")
+		return nil, fmt.Errorf("no reach estimate data returned")
 	}
 
-	fmt.Printf("Audience size for %s: %s\n", interestID, FormatAudienceRange(estimateResp.Data[0].LowerBound, estimateResp.Data[0].UpperBound))
+	return &estimateResp.Data[0], nil
+}
 
-	// Return the estimated audience size
-	return estimateResp.Data[0].Users, nil
+// debugResponse writes the raw response to a request to the debug writer, if any
+func (a *AudienceAnalyzer) debugResponse(req *http.Request, body []byte) {
+	if a.debug == nil {
+		return
+	}
+	fmt.Fprintf(a.debug, "GET %s\n%s\n", req.URL.Path, body)
 }
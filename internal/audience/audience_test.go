@@ -0,0 +1,86 @@
+package audience
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestGetAudienceSizeForTargeting(t *testing.T) {
+	var specs []map[string]interface{}
+	analyzer := NewAudienceAnalyzer(auth.NewFacebookAuth("app", "secret", "token", "v22.0"), "123")
+	analyzer.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		var spec map[string]interface{}
+		if err := json.Unmarshal([]byte(req.URL.Query().Get("targeting_spec")), &spec); err != nil {
+			t.Fatalf("Invalid targeting spec: %v", err)
+		}
+		specs = append(specs, spec)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"data":[{"users":42000,"lower_bound":40000,"upper_bound":44000}]}`)),
+			Header:     make(http.Header),
+		}
+	})}
+
+	targeting := map[string]interface{}{
+		"geo_locations": map[string]interface{}{"countries": []string{"DE", "AT"}},
+		"age_min":       25,
+	}
+	size, err := analyzer.GetAudienceSizeForTargeting("6003", targeting)
+	if err != nil {
+		t.Fatalf("GetAudienceSizeForTargeting failed: %v", err)
+	}
+	if size != 42000 {
+		t.Errorf("Expected 42000 users, got %d", size)
+	}
+	if _, ok := targeting["interests"]; ok {
+		t.Error("Expected the caller's targeting to be left unchanged")
+	}
+
+	lower, upper, err := analyzer.GetAudienceSize(TargetingSpec{Interests: []string{"6003"}})
+	if err != nil {
+		t.Fatalf("GetAudienceSize failed: %v", err)
+	}
+	if lower != 40000 || upper != 44000 {
+		t.Errorf("Expected 40000 - 44000 users, got %d - %d", lower, upper)
+	}
+	if _, _, err := analyzer.GetAudienceSize(TargetingSpec{AgeMin: 50, AgeMax: 30}); err == nil {
+		t.Error("Expected an error for an inverted age range")
+	}
+
+	if len(specs) != 2 {
+		t.Fatalf("Expected two estimates, got %d", len(specs))
+	}
+	tests := []struct {
+		name      string
+		spec      map[string]interface{}
+		countries string
+	}{
+		{name: "with targeting", spec: specs[0], countries: "DE,AT"},
+		{name: "default", spec: specs[1], countries: "US"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			geo, _ := tt.spec["geo_locations"].(map[string]interface{})
+			var countries []string
+			for _, country := range geo["countries"].([]interface{}) {
+				countries = append(countries, country.(string))
+			}
+			if got := strings.Join(countries, ","); got != tt.countries {
+				t.Errorf("Expected countries %s, got %s", tt.countries, got)
+			}
+
+			interests, _ := tt.spec["interests"].([]interface{})
+			if len(interests) != 1 || interests[0].(map[string]interface{})["id"] != "6003" {
+				t.Errorf("Expected the interest in the spec, got %v", tt.spec["interests"])
+			}
+		})
+	}
+	if specs[0]["age_min"] != float64(25) {
+		t.Errorf("Expected age_min to be passed through, got %v", specs[0]["age_min"])
+	}
+}
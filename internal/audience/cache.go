@@ -0,0 +1,195 @@
+package audience
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default segment cache limits
+const (
+	DefaultSegmentCacheSize = 5000
+	DefaultSegmentCacheTTL  = 7 * 24 * time.Hour
+)
+
+// DefaultSegmentCacheFile is the name of the segment cache file in the config directory
+const DefaultSegmentCacheFile = "audience_cache.json"
+
+// DefaultSegmentCachePath returns where the segment cache is kept under a config directory
+func DefaultSegmentCachePath(configDir string) string {
+	return filepath.Join(configDir, DefaultSegmentCacheFile)
+}
+
+// segmentCache holds researched audience segments and is safe for concurrent use.
+// It keeps at most maxSize segments and drops segments older than ttl.
+type segmentCache struct {
+	mu       sync.RWMutex
+	segments map[string]AudienceSegment
+	maxSize  int
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// newSegmentCache creates a segment cache with the given bounds.
+// A zero maxSize or ttl disables that bound.
+func newSegmentCache(maxSize int, ttl time.Duration) *segmentCache {
+	return &segmentCache{
+		segments: make(map[string]AudienceSegment),
+		maxSize:  maxSize,
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// setLimits changes the cache bounds and applies them to the cached segments
+func (c *segmentCache) setLimits(maxSize int, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxSize = maxSize
+	c.ttl = ttl
+	c.prune()
+}
+
+// put stores segments and stamps them with the time they were cached
+func (c *segmentCache) put(segments []AudienceSegment) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for _, segment := range segments {
+		segment.LastUpdated = now
+		c.segments[segment.ID] = segment
+	}
+	c.prune()
+}
+
+// load adds previously cached segments, keeping the time they were cached. Segments without
+// that time or older than the TTL are skipped; newer cached copies are kept.
+func (c *segmentCache) load(segments []AudienceSegment) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for _, segment := range segments {
+		if segment.ID == "" || segment.LastUpdated.IsZero() || c.expired(segment, now) {
+			continue
+		}
+		if cached, ok := c.segments[segment.ID]; ok && cached.LastUpdated.After(segment.LastUpdated) {
+			continue
+		}
+		c.segments[segment.ID] = segment
+	}
+	c.prune()
+}
+
+// snapshot returns a copy of the segments that haven't expired
+func (c *segmentCache) snapshot() []AudienceSegment {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := c.now()
+	segments := make([]AudienceSegment, 0, len(c.segments))
+	for _, segment := range c.segments {
+		if c.expired(segment, now) {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// prune drops expired segments, then the oldest ones while the cache is over its size bound.
+// The caller must hold the write lock.
+func (c *segmentCache) prune() {
+	now := c.now()
+	for id, segment := range c.segments {
+		if c.expired(segment, now) {
+			delete(c.segments, id)
+		}
+	}
+
+	if c.maxSize <= 0 || len(c.segments) <= c.maxSize {
+		return
+	}
+
+	// Evict the least recently updated segments first
+	ids := make([]string, 0, len(c.segments))
+	for id := range c.segments {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := c.segments[ids[i]], c.segments[ids[j]]
+		if !a.LastUpdated.Equal(b.LastUpdated) {
+			return a.LastUpdated.Before(b.LastUpdated)
+		}
+		return ids[i] < ids[j]
+	})
+	for _, id := range ids[:len(ids)-c.maxSize] {
+		delete(c.segments, id)
+	}
+}
+
+// expired reports whether a segment is older than the cache TTL
+func (c *segmentCache) expired(segment AudienceSegment, now time.Time) bool {
+	return c.ttl > 0 && now.Sub(segment.LastUpdated) > c.ttl
+}
+
+// SaveCache writes the cached segments that haven't expired to a JSON file, keyed by ID,
+// so a later run can reuse them with LoadCache
+func (a *AudienceAnalyzer) SaveCache(path string) error {
+	segments := make(map[string]AudienceSegment)
+	for _, segment := range a.Segments() {
+		segments[segment.ID] = segment
+	}
+
+	data, err := json.MarshalIndent(segments, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing segment cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating segment cache directory: %w", err)
+	}
+
+	// Write to a temp file first so an interrupted run never leaves a truncated cache
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("error writing segment cache: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("error writing segment cache: %w", err)
+	}
+
+	return nil
+}
+
+// LoadCache adds the segments saved by SaveCache to the cache. Segments older than the
+// cache TTL (see SetCacheLimits) are skipped. A missing file leaves the cache as it is.
+func (a *AudienceAnalyzer) LoadCache(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading segment cache: %w", err)
+	}
+
+	var cached map[string]AudienceSegment
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return fmt.Errorf("error parsing segment cache %s: %w", path, err)
+	}
+
+	segments := make([]AudienceSegment, 0, len(cached))
+	for id, segment := range cached {
+		segment.ID = id
+		segments = append(segments, segment)
+	}
+	a.segments.load(segments)
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package audience
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+type roundTripFunc func(req *http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+// newFixtureAnalyzer returns an analyzer whose searches return one segment named after the query
+func newFixtureAnalyzer() *AudienceAnalyzer {
+	analyzer := NewAudienceAnalyzer(auth.NewFacebookAuth("app", "secret", "token", "v22.0"), "x")
+	analyzer.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		query := req.URL.Query().Get("q")
+		body := fmt.Sprintf(`{"data":[{"id":"%s","name":"%s interest","type":"interests","audience_size_upper_bound":1000}]}`, query, query)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     make(http.Header),
+		}
+	})}
+	return analyzer
+}
+
+func TestSearchAndFilterConcurrently(t *testing.T) {
+	analyzer := newFixtureAnalyzer()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := analyzer.Search("adinterest", "", fmt.Sprintf("q%d", i)); err != nil {
+				t.Errorf("Search failed: %v", err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := analyzer.FilterAudiences(map[string]interface{}{"keywords": []string{"interest"}}); err != nil {
+				t.Errorf("FilterAudiences failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	filtered, err := analyzer.FilterAudiences(map[string]interface{}{"keywords": []string{"interest"}})
+	if err != nil {
+		t.Fatalf("FilterAudiences failed: %v", err)
+	}
+	if len(filtered) != 20 {
+		t.Errorf("Expected 20 cached segments, got %d", len(filtered))
+	}
+}
+
+func TestSegmentsReturnsCopy(t *testing.T) {
+	analyzer := newFixtureAnalyzer()
+	if _, err := analyzer.Search("adinterest", "", "golf"); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	segments := analyzer.Segments()
+	if len(segments) != 1 || segments[0].LastUpdated.IsZero() {
+		t.Fatalf("Expected one timestamped segment, got %+v", segments)
+	}
+
+	segments[0].Name = "changed"
+	if analyzer.Segments()[0].Name != "golf interest" {
+		t.Errorf("Expected Segments to return a copy")
+	}
+}
+
+func TestSegmentCacheBounds(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache := newSegmentCache(2, 24*time.Hour)
+	cache.now = func() time.Time { return now }
+
+	cache.put([]AudienceSegment{{ID: "a"}})
+	now = now.Add(time.Hour)
+	cache.put([]AudienceSegment{{ID: "b"}})
+	now = now.Add(time.Hour)
+	cache.put([]AudienceSegment{{ID: "c"}})
+
+	// The oldest segment is evicted once the cache is over its size
+	if ids := segmentIDs(cache.snapshot()); ids != "b,c" {
+		t.Errorf("Expected segments b,c after eviction, got %s", ids)
+	}
+
+	// Refreshing a segment makes it recent again
+	now = now.Add(time.Hour)
+	cache.put([]AudienceSegment{{ID: "b"}})
+	now = now.Add(23*time.Hour + 30*time.Minute)
+	if ids := segmentIDs(cache.snapshot()); ids != "b" {
+		t.Errorf("Expected only the refreshed segment to survive the TTL, got %s", ids)
+	}
+}
+
+func TestSaveAndLoadCache(t *testing.T) {
+	path := DefaultSegmentCachePath(filepath.Join(t.TempDir(), "config"))
+
+	analyzer := newFixtureAnalyzer()
+	for _, query := range []string{"golf", "tennis"} {
+		if _, err := analyzer.Search("adinterest", "", query); err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+	}
+	if err := analyzer.SaveCache(path); err != nil {
+		t.Fatalf("SaveCache failed: %v", err)
+	}
+
+	// A later run filters the saved segments without searching again
+	later := NewAudienceAnalyzer(auth.NewFacebookAuth("app", "secret", "token", "v22.0"), "x")
+	if err := later.LoadCache(path); err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+	filtered, err := later.FilterAudiences(map[string]interface{}{"keywords": []string{"golf"}})
+	if err != nil {
+		t.Fatalf("FilterAudiences failed: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].Name != "golf interest" || filtered[0].LastUpdated.IsZero() {
+		t.Errorf("Expected the cached golf segment with its cache time, got %+v", filtered)
+	}
+
+	// A missing file leaves the cache empty
+	empty := newFixtureAnalyzer()
+	if err := empty.LoadCache(filepath.Join(t.TempDir(), "missing.json")); err != nil || len(empty.Segments()) != 0 {
+		t.Errorf("Expected a missing cache file to be ignored, got %v", err)
+	}
+}
+
+func TestLoadCache_SkipsExpiredSegments(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "audience_cache.json")
+	data := fmt.Sprintf(`{
+		"fresh": {"name": "Fresh", "last_updated": %q},
+		"stale": {"name": "Stale", "last_updated": %q},
+		"undated": {"name": "Undated"}
+	}`, now.Add(-2*time.Hour).Format(time.RFC3339), now.Add(-48*time.Hour).Format(time.RFC3339))
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analyzer := newFixtureAnalyzer()
+	analyzer.segments.now = func() time.Time { return now }
+	analyzer.SetCacheLimits(DefaultSegmentCacheSize, 24*time.Hour)
+	if err := analyzer.LoadCache(path); err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+
+	if ids := segmentIDs(analyzer.Segments()); ids != "fresh" {
+		t.Errorf("Expected only the fresh segment to be loaded, got %s", ids)
+	}
+}
+
+// segmentIDs returns the sorted IDs of segments joined by commas
+func segmentIDs(segments []AudienceSegment) string {
+	ids := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		ids = append(ids, segment.ID)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
@@ -0,0 +1,212 @@
+package audience
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Audience export formats
+const (
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+)
+
+// exportColumns are the columns of a CSV export
+var exportColumns = []string{"id", "name", "type", "path", "audience_size_lower_bound", "audience_size_upper_bound", "description"}
+
+// ExportOptions controls how audience segments are written to a file
+type ExportOptions struct {
+	// Format is ExportFormatJSON or ExportFormatCSV; empty picks CSV for .csv files and
+	// JSON otherwise
+	Format string
+
+	// Merge keeps the segments already in the file. Segments are deduplicated by ID, with
+	// the exported copy replacing the one in the file.
+	Merge bool
+}
+
+// ExportFormatForPath returns the export format matching the extension of a file
+func ExportFormatForPath(filePath string) string {
+	if strings.EqualFold(filepath.Ext(filePath), ".csv") {
+		return ExportFormatCSV
+	}
+	return ExportFormatJSON
+}
+
+// ExportAudienceData exports audience data to a file, as CSV for .csv files and as a
+// JSON array otherwise
+func (a *AudienceAnalyzer) ExportAudienceData(filePath string, data []AudienceSegment) error {
+	_, err := a.ExportAudienceDataWithOptions(filePath, data, ExportOptions{})
+	return err
+}
+
+// ExportAudienceDataWithOptions exports audience data to a file and returns the number of
+// segments the file holds
+func (a *AudienceAnalyzer) ExportAudienceDataWithOptions(filePath string, data []AudienceSegment, options ExportOptions) (int, error) {
+	format := options.Format
+	if format == "" {
+		format = ExportFormatForPath(filePath)
+	}
+	if format != ExportFormatJSON && format != ExportFormatCSV {
+		return 0, fmt.Errorf("unknown export format %q (use %s or %s)", format, ExportFormatJSON, ExportFormatCSV)
+	}
+
+	if options.Merge {
+		existing, err := readAudienceData(filePath, format)
+		if err != nil {
+			return 0, err
+		}
+		data = mergeSegments(existing, data)
+	}
+
+	var content []byte
+	var err error
+	if format == ExportFormatCSV {
+		content, err = audienceCSV(data)
+	} else {
+		content, err = json.MarshalIndent(data, "", "  ")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error marshaling audience data: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		return 0, fmt.Errorf("error writing audience data to file: %w", err)
+	}
+
+	return len(data), nil
+}
+
+// mergeSegments returns the existing segments followed by the new ones, one per ID.
+// A new segment replaces an existing one with the same ID in place.
+func mergeSegments(existing, added []AudienceSegment) []AudienceSegment {
+	merged := make([]AudienceSegment, 0, len(existing)+len(added))
+	index := make(map[string]int)
+	for _, segments := range [][]AudienceSegment{existing, added} {
+		for _, segment := range segments {
+			if i, ok := index[segment.ID]; ok && segment.ID != "" {
+				merged[i] = segment
+				continue
+			}
+			index[segment.ID] = len(merged)
+			merged = append(merged, segment)
+		}
+	}
+	return merged
+}
+
+// readAudienceData reads the segments of an earlier export. A missing file holds none.
+func readAudienceData(filePath, format string) ([]AudienceSegment, error) {
+	content, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading audience data: %w", err)
+	}
+
+	var segments []AudienceSegment
+	if format == ExportFormatCSV {
+		segments, err = parseAudienceCSV(content)
+	} else if len(strings.TrimSpace(string(content))) > 0 {
+		err = json.Unmarshal(content, &segments)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing audience data %s: %w", filePath, err)
+	}
+	return segments, nil
+}
+
+// audienceCSV writes segments as CSV rows with a header. Category paths are flattened
+// with " > " separators.
+func audienceCSV(segments []AudienceSegment) ([]byte, error) {
+	var b strings.Builder
+	writer := csv.NewWriter(&b)
+	if err := writer.Write(exportColumns); err != nil {
+		return nil, err
+	}
+
+	for _, segment := range segments {
+		record := []string{
+			segment.ID,
+			segment.Name,
+			segment.Type,
+			FlattenPath(segment.Path),
+			strconv.FormatInt(segment.LowerBound, 10),
+			strconv.FormatInt(segment.UpperBound, 10),
+			segment.Description,
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return []byte(b.String()), writer.Error()
+}
+
+// parseAudienceCSV reads segments written by audienceCSV. Columns are matched by the
+// header, so files with reordered or extra columns are read too.
+func parseAudienceCSV(content []byte) ([]AudienceSegment, error) {
+	records, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["id"]; !ok {
+		return nil, fmt.Errorf("missing id column")
+	}
+
+	var segments []AudienceSegment
+	for _, record := range records[1:] {
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+		segment := AudienceSegment{
+			ID:          field("id"),
+			Name:        field("name"),
+			Type:        field("type"),
+			Description: field("description"),
+		}
+		if path := field("path"); path != "" {
+			segment.Path = path
+		}
+		segment.LowerBound, _ = strconv.ParseInt(field("audience_size_lower_bound"), 10, 64)
+		segment.UpperBound, _ = strconv.ParseInt(field("audience_size_upper_bound"), 10, 64)
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// FlattenPath returns a segment path as text. The API returns the path as a string or
+// as a list of categories, which are joined with " > ".
+func FlattenPath(path interface{}) string {
+	switch p := path.(type) {
+	case nil:
+		return ""
+	case string:
+		return p
+	case []string:
+		return strings.Join(p, " > ")
+	case []interface{}:
+		parts := make([]string, 0, len(p))
+		for _, part := range p {
+			parts = append(parts, fmt.Sprint(part))
+		}
+		return strings.Join(parts, " > ")
+	default:
+		return fmt.Sprint(p)
+	}
+}
@@ -0,0 +1,103 @@
+package audience
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportAudienceData(t *testing.T) {
+	hiking := AudienceSegment{ID: "1", Name: "Hiking", Type: "interests", Path: []interface{}{"Hobbies", "Outdoors"}, LowerBound: 1000, UpperBound: 2000}
+	camping := AudienceSegment{ID: "2", Name: "Camping, \"wild\"", Type: "interests", Path: "Outdoors", Description: "Tents\nand stoves"}
+	updated := hiking
+	updated.UpperBound = 3000
+
+	tests := []struct {
+		name    string
+		file    string
+		options ExportOptions
+		expect  []string // IDs and upper bounds in the file after both exports
+	}{
+		{name: "json replaces", file: "segments.json", expect: []string{"1:3000"}},
+		{name: "json merges", file: "segments.json", options: ExportOptions{Merge: true}, expect: []string{"1:3000", "2:0"}},
+		{name: "csv replaces", file: "segments.csv", expect: []string{"1:3000"}},
+		{name: "csv merges", file: "segments.csv", options: ExportOptions{Merge: true}, expect: []string{"1:3000", "2:0"}},
+		{name: "format flag", file: "segments.txt", options: ExportOptions{Format: ExportFormatCSV, Merge: true}, expect: []string{"1:3000", "2:0"}},
+	}
+
+	analyzer := newFixtureAnalyzer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if _, err := analyzer.ExportAudienceDataWithOptions(path, []AudienceSegment{hiking, camping}, tt.options); err != nil {
+				t.Fatalf("First export failed: %v", err)
+			}
+			total, err := analyzer.ExportAudienceDataWithOptions(path, []AudienceSegment{updated}, tt.options)
+			if err != nil {
+				t.Fatalf("Second export failed: %v", err)
+			}
+
+			format := tt.options.Format
+			if format == "" {
+				format = ExportFormatForPath(path)
+			}
+			segments, err := readAudienceData(path, format)
+			if err != nil {
+				t.Fatalf("Reading the export failed: %v", err)
+			}
+
+			var got []string
+			for _, segment := range segments {
+				got = append(got, fmt.Sprintf("%s:%d", segment.ID, segment.UpperBound))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expect, ",") || total != len(tt.expect) {
+				t.Errorf("Expected %v, got %v (total %d)", tt.expect, got, total)
+			}
+
+			// Quoted fields survive the round trip
+			for _, segment := range segments {
+				if segment.ID == "2" && (segment.Name != camping.Name || segment.Description != camping.Description) {
+					t.Errorf("Expected the camping segment unchanged, got %+v", segment)
+				}
+			}
+		})
+	}
+}
+
+func TestExportAudienceData_CSVColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "segments.csv")
+	segments := []AudienceSegment{{ID: "1", Name: "Hiking", Type: "interests", Path: []interface{}{"Hobbies", "Outdoors"}, LowerBound: 1000, UpperBound: 2000, Description: "People, who hike"}}
+	if err := newFixtureAnalyzer().ExportAudienceData(path, segments); err != nil {
+		t.Fatalf("ExportAudienceData failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "id,name,type,path,audience_size_lower_bound,audience_size_upper_bound,description\n" +
+		"1,Hiking,interests,Hobbies > Outdoors,1000,2000,\"People, who hike\"\n"
+	if string(data) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestFlattenPath(t *testing.T) {
+	tests := []struct {
+		path     interface{}
+		expected string
+	}{
+		{nil, ""},
+		{"Outdoors", "Outdoors"},
+		{[]interface{}{"Hobbies", "Outdoors"}, "Hobbies > Outdoors"},
+		{[]string{"Shopping", "Fashion"}, "Shopping > Fashion"},
+	}
+
+	for _, tt := range tests {
+		if got := FlattenPath(tt.path); got != tt.expected {
+			t.Errorf("FlattenPath(%v) = %q, want %q", tt.path, got, tt.expected)
+		}
+	}
+}
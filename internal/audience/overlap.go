@@ -0,0 +1,221 @@
+package audience
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultOverlapThreshold is the overlap ratio from which two interests count as targeting
+// nearly the same people
+const DefaultOverlapThreshold = 0.5
+
+// MaxOverlapInterests is the most interests compared at once; every pair costs a delivery
+// estimate, so 20 interests already take 210 requests
+const MaxOverlapInterests = 20
+
+// overlapColumns are the columns of a CSV overlap export
+var overlapColumns = []string{"interest_a_id", "interest_a_name", "interest_b_id", "interest_b_name", "size_a", "size_b", "combined_size", "overlap_size", "overlap_ratio", "above_threshold"}
+
+// OverlapInterest is an interest compared for overlap, with its estimated audience size
+type OverlapInterest struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Size int64  `json:"size"`
+}
+
+// InterestOverlap is the estimated overlap of the audiences of two interests
+type InterestOverlap struct {
+	A            OverlapInterest `json:"a"`
+	B            OverlapInterest `json:"b"`
+	CombinedSize int64           `json:"combined_size"` // People with either interest
+	OverlapSize  int64           `json:"overlap_size"`  // People with both interests
+	Ratio        float64         `json:"ratio"`         // Overlap as a share of the smaller audience
+}
+
+// OverlapAnalysis holds the overlap of every pair of a set of interests
+type OverlapAnalysis struct {
+	Interests []OverlapInterest      `json:"interests"`
+	Pairs     []InterestOverlap      `json:"pairs"`
+	Threshold float64                `json:"threshold"`
+	Targeting map[string]interface{} `json:"targeting,omitempty"`
+}
+
+// EstimateOverlap returns the number of people in both of two audiences from their sizes
+// and the size of their union, and that overlap as a share of the smaller audience.
+// Estimates are rounded by Facebook, so the overlap is kept between 0 and the smaller size.
+func EstimateOverlap(sizeA, sizeB, combined int64) (int64, float64) {
+	smaller := sizeA
+	if sizeB < smaller {
+		smaller = sizeB
+	}
+	if smaller <= 0 {
+		return 0, 0
+	}
+
+	overlap := sizeA + sizeB - combined
+	if overlap < 0 {
+		overlap = 0
+	}
+	if overlap > smaller {
+		overlap = smaller
+	}
+	return overlap, float64(overlap) / float64(smaller)
+}
+
+// AnalyzeOverlap estimates how much the audiences of each pair of interests overlap
+// within a targeting spec, such as one with geo_locations. Names of the interests are
+// kept for display; a threshold of 0 uses DefaultOverlapThreshold.
+func (a *AudienceAnalyzer) AnalyzeOverlap(interests []AudienceSegment, targeting map[string]interface{}, threshold float64) (*OverlapAnalysis, error) {
+	return a.AnalyzeOverlapContext(context.Background(), interests, targeting, threshold)
+}
+
+// AnalyzeOverlapContext estimates audience overlap like AnalyzeOverlap. It requests a
+// delivery estimate for each interest and for each pair of interests combined.
+func (a *AudienceAnalyzer) AnalyzeOverlapContext(ctx context.Context, interests []AudienceSegment, targeting map[string]interface{}, threshold float64) (*OverlapAnalysis, error) {
+	if threshold <= 0 {
+		threshold = DefaultOverlapThreshold
+	}
+	if threshold > 1 {
+		return nil, fmt.Errorf("overlap threshold must be between 0 and 1, got %g", threshold)
+	}
+
+	// Each interest is compared once
+	var unique []AudienceSegment
+	seen := make(map[string]bool)
+	for _, interest := range interests {
+		if interest.ID == "" || seen[interest.ID] {
+			continue
+		}
+		seen[interest.ID] = true
+		unique = append(unique, interest)
+	}
+	if len(unique) < 2 {
+		return nil, fmt.Errorf("at least two different interests are needed to compare, got %d", len(unique))
+	}
+	if len(unique) > MaxOverlapInterests {
+		return nil, fmt.Errorf("at most %d interests can be compared at once, got %d", MaxOverlapInterests, len(unique))
+	}
+
+	analysis := &OverlapAnalysis{Threshold: threshold, Targeting: targeting}
+	for _, interest := range unique {
+		size, err := a.GetAudienceSizeForInterestsContext(ctx, []string{interest.ID}, targeting)
+		if err != nil {
+			return nil, fmt.Errorf("error estimating the audience of %s: %w", interest.ID, err)
+		}
+		analysis.Interests = append(analysis.Interests, OverlapInterest{ID: interest.ID, Name: interest.Name, Size: size})
+	}
+
+	for i, first := range analysis.Interests {
+		for _, second := range analysis.Interests[i+1:] {
+			combined, err := a.GetAudienceSizeForInterestsContext(ctx, []string{first.ID, second.ID}, targeting)
+			if err != nil {
+				return nil, fmt.Errorf("error estimating the combined audience of %s and %s: %w", first.ID, second.ID, err)
+			}
+			overlap, ratio := EstimateOverlap(first.Size, second.Size, combined)
+			analysis.Pairs = append(analysis.Pairs, InterestOverlap{
+				A:            first,
+				B:            second,
+				CombinedSize: combined,
+				OverlapSize:  overlap,
+				Ratio:        ratio,
+			})
+		}
+	}
+
+	return analysis, nil
+}
+
+// Ratio returns the overlap ratio of two interests by ID, and false for a pair that wasn't compared
+func (o *OverlapAnalysis) Ratio(a, b string) (float64, bool) {
+	for _, pair := range o.Pairs {
+		if (pair.A.ID == a && pair.B.ID == b) || (pair.A.ID == b && pair.B.ID == a) {
+			return pair.Ratio, true
+		}
+	}
+	return 0, false
+}
+
+// AboveThreshold returns the pairs whose overlap ratio reaches the threshold, highest first
+func (o *OverlapAnalysis) AboveThreshold() []InterestOverlap {
+	var pairs []InterestOverlap
+	for _, pair := range o.Pairs {
+		if pair.Ratio >= o.Threshold {
+			pairs = append(pairs, pair)
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Ratio > pairs[j].Ratio
+	})
+	return pairs
+}
+
+// ExportOverlap writes an overlap analysis to a file: as CSV with one row per pair for
+// .csv files or the csv format, as JSON otherwise
+func ExportOverlap(filePath string, analysis *OverlapAnalysis, format string) error {
+	if format == "" {
+		format = ExportFormatForPath(filePath)
+	}
+
+	var content []byte
+	var err error
+	switch format {
+	case ExportFormatJSON:
+		content, err = json.MarshalIndent(analysis, "", "  ")
+	case ExportFormatCSV:
+		content, err = overlapCSV(analysis)
+	default:
+		return fmt.Errorf("unknown export format %q (use %s or %s)", format, ExportFormatJSON, ExportFormatCSV)
+	}
+	if err != nil {
+		return fmt.Errorf("error marshaling overlap analysis: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		return fmt.Errorf("error writing overlap analysis to file: %w", err)
+	}
+	return nil
+}
+
+// overlapCSV writes the pairs of an overlap analysis as CSV rows with a header
+func overlapCSV(analysis *OverlapAnalysis) ([]byte, error) {
+	var b strings.Builder
+	writer := csv.NewWriter(&b)
+	if err := writer.Write(overlapColumns); err != nil {
+		return nil, err
+	}
+	for _, pair := range analysis.Pairs {
+		row := []string{
+			pair.A.ID,
+			pair.A.Name,
+			pair.B.ID,
+			pair.B.Name,
+			strconv.FormatInt(pair.A.Size, 10),
+			strconv.FormatInt(pair.B.Size, 10),
+			strconv.FormatInt(pair.CombinedSize, 10),
+			strconv.FormatInt(pair.OverlapSize, 10),
+			strconv.FormatFloat(math.Round(pair.Ratio*10000)/10000, 'f', -1, 64),
+			strconv.FormatBool(pair.Ratio >= analysis.Threshold),
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return []byte(b.String()), writer.Error()
+}
+
+// LoadSegments reads the segments of a file written by the search or filter --output
+// option, as CSV for .csv files and as JSON otherwise
+func LoadSegments(filePath string) ([]AudienceSegment, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, fmt.Errorf("error reading audience data: %w", err)
+	}
+	return readAudienceData(filePath, ExportFormatForPath(filePath))
+}
@@ -0,0 +1,171 @@
+package audience
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestEstimateOverlap(t *testing.T) {
+	tests := []struct {
+		name                string
+		sizeA, sizeB, union int64
+		expectedOverlap     int64
+		expectedRatio       float64
+	}{
+		{name: "partial overlap", sizeA: 1000, sizeB: 400, union: 1200, expectedOverlap: 200, expectedRatio: 0.5},
+		{name: "disjoint", sizeA: 1000, sizeB: 400, union: 1400, expectedOverlap: 0, expectedRatio: 0},
+		{name: "rounded union above the sum", sizeA: 1000, sizeB: 400, union: 1500, expectedOverlap: 0, expectedRatio: 0},
+		{name: "contained", sizeA: 1000, sizeB: 400, union: 1000, expectedOverlap: 400, expectedRatio: 1},
+		{name: "rounded union below the larger", sizeA: 1000, sizeB: 400, union: 900, expectedOverlap: 400, expectedRatio: 1},
+		{name: "empty audience", sizeA: 1000, sizeB: 0, union: 1000, expectedOverlap: 0, expectedRatio: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overlap, ratio := EstimateOverlap(tt.sizeA, tt.sizeB, tt.union)
+			if overlap != tt.expectedOverlap || ratio != tt.expectedRatio {
+				t.Errorf("EstimateOverlap = %d, %v, want %d, %v", overlap, ratio, tt.expectedOverlap, tt.expectedRatio)
+			}
+		})
+	}
+}
+
+func TestAnalyzeOverlap(t *testing.T) {
+	// Hiking and trekking share most of their audience, knitting is on its own
+	sizes := map[string]int64{
+		"1":   1000000,
+		"2":   400000,
+		"3":   300000,
+		"1,2": 1100000,
+		"1,3": 1300000,
+		"2,3": 700000,
+	}
+	var countries []string
+	analyzer := NewAudienceAnalyzer(auth.NewFacebookAuth("app", "secret", "token", "v22.0"), "123")
+	analyzer.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		var spec struct {
+			GeoLocations struct {
+				Countries []string `json:"countries"`
+			} `json:"geo_locations"`
+			Interests []struct {
+				ID string `json:"id"`
+			} `json:"interests"`
+		}
+		if err := json.Unmarshal([]byte(req.URL.Query().Get("targeting_spec")), &spec); err != nil {
+			t.Fatalf("Invalid targeting spec: %v", err)
+		}
+		var ids []string
+		for _, interest := range spec.Interests {
+			ids = append(ids, interest.ID)
+		}
+		sort.Strings(ids)
+		countries = spec.GeoLocations.Countries
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(fmt.Sprintf(`{"data":[{"users":%d}]}`, sizes[strings.Join(ids, ",")]))),
+			Header:     make(http.Header),
+		}
+	})}
+
+	interests := []AudienceSegment{{ID: "1", Name: "Hiking"}, {ID: "2", Name: "Trekking"}, {ID: "3", Name: "Knitting"}, {ID: "2"}}
+	targeting := map[string]interface{}{"geo_locations": map[string]interface{}{"countries": []string{"DE", "AT"}}}
+	analysis, err := analyzer.AnalyzeOverlap(interests, targeting, 0)
+	if err != nil {
+		t.Fatalf("AnalyzeOverlap failed: %v", err)
+	}
+
+	if strings.Join(countries, ",") != "DE,AT" {
+		t.Errorf("Expected estimates for the targeted countries, got %v", countries)
+	}
+	if len(analysis.Interests) != 3 || len(analysis.Pairs) != 3 {
+		t.Fatalf("Expected 3 interests and 3 pairs, got %d and %d", len(analysis.Interests), len(analysis.Pairs))
+	}
+	if analysis.Threshold != DefaultOverlapThreshold {
+		t.Errorf("Expected the default threshold, got %v", analysis.Threshold)
+	}
+
+	tests := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"1", "2", 0.75},
+		{"2", "1", 0.75},
+		{"1", "3", 0},
+		{"2", "3", 0},
+	}
+	for _, tt := range tests {
+		if ratio, ok := analysis.Ratio(tt.a, tt.b); !ok || ratio != tt.expected {
+			t.Errorf("Ratio(%s, %s) = %v, %v, want %v", tt.a, tt.b, ratio, ok, tt.expected)
+		}
+	}
+
+	above := analysis.AboveThreshold()
+	if len(above) != 1 || above[0].A.Name != "Hiking" || above[0].B.Name != "Trekking" || above[0].OverlapSize != 300000 {
+		t.Errorf("Expected only hiking and trekking above the threshold, got %+v", above)
+	}
+
+	if _, err := analyzer.AnalyzeOverlap(interests[:1], nil, 0); err == nil {
+		t.Error("Expected an error for a single interest")
+	}
+	if _, err := analyzer.AnalyzeOverlap(interests, nil, 1.5); err == nil {
+		t.Error("Expected an error for a threshold above 1")
+	}
+}
+
+func TestExportOverlap(t *testing.T) {
+	analysis := &OverlapAnalysis{
+		Threshold: 0.5,
+		Pairs: []InterestOverlap{
+			{A: OverlapInterest{ID: "1", Name: "Hiking", Size: 1000}, B: OverlapInterest{ID: "2", Name: "Trekking, alpine", Size: 400}, CombinedSize: 1100, OverlapSize: 300, Ratio: 0.75},
+			{A: OverlapInterest{ID: "1", Name: "Hiking", Size: 1000}, B: OverlapInterest{ID: "3", Name: "Knitting", Size: 300}, CombinedSize: 1300, Ratio: 0},
+		},
+	}
+
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "overlap.csv")
+	if err := ExportOverlap(csvPath, analysis, ""); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || strings.Join(rows[0], ",") != strings.Join(overlapColumns, ",") {
+		t.Fatalf("Expected a header and two rows, got %v", rows)
+	}
+	if got := strings.Join(rows[1], "|"); got != "1|Hiking|2|Trekking, alpine|1000|400|1100|300|0.75|true" {
+		t.Errorf("Unexpected row %q", got)
+	}
+
+	jsonPath := filepath.Join(dir, "overlap.json")
+	if err := ExportOverlap(jsonPath, analysis, ""); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded OverlapAnalysis
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Pairs) != 2 || decoded.Pairs[0].Ratio != 0.75 {
+		t.Errorf("Expected the analysis back from JSON, got %+v (%v)", decoded, err)
+	}
+
+	if err := ExportOverlap(filepath.Join(dir, "overlap.txt"), analysis, "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
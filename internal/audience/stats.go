@@ -0,0 +1,235 @@
+package audience
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+// DefaultStatsFile is the name of the audience statistics file in the config directory
+const DefaultStatsFile = "audience_stats.json"
+
+// DefaultStatsPath returns where audience statistics are kept under a config directory
+func DefaultStatsPath(configDir string) string {
+	return filepath.Join(configDir, DefaultStatsFile)
+}
+
+// statsBreakdowns are the breakdowns tried in order. Facebook rejects some combinations
+// for some campaigns, in which case the age breakdown alone is used.
+var statsBreakdowns = []string{"age,gender", "age"}
+
+// BreakdownStats is the performance of a campaign in one breakdown bucket
+type BreakdownStats struct {
+	Age         string             `json:"age,omitempty"`
+	Gender      string             `json:"gender,omitempty"`
+	Performance SegmentPerformance `json:"performance"`
+}
+
+// Label returns the bucket as shown in tables, e.g. "25-34 female"
+func (b BreakdownStats) Label() string {
+	if b.Gender == "" {
+		return b.Age
+	}
+	return b.Age + " " + b.Gender
+}
+
+// CampaignAudienceStats holds the performance of a campaign broken down by audience
+type CampaignAudienceStats struct {
+	CampaignID  string           `json:"campaign_id"`
+	Since       string           `json:"since"`
+	Until       string           `json:"until"`
+	Breakdowns  string           `json:"breakdowns"` // Breakdowns the rows are split by, e.g. "age,gender"
+	CollectedAt time.Time        `json:"collected_at"`
+	Rows        []BreakdownStats `json:"rows"`
+}
+
+// CollectSegmentStatistics gathers the performance of a campaign over the last days,
+// broken down by age and gender
+func (a *AudienceAnalyzer) CollectSegmentStatistics(campaignID string, days int) (*CampaignAudienceStats, error) {
+	return a.CollectSegmentStatisticsContext(context.Background(), campaignID, days)
+}
+
+// CollectSegmentStatisticsContext gathers the performance of a campaign over the last
+// days, broken down by age and gender, or by age alone when Facebook rejects the gender
+// breakdown. Cancelling the context stops the pagination.
+func (a *AudienceAnalyzer) CollectSegmentStatisticsContext(ctx context.Context, campaignID string, days int) (*CampaignAudienceStats, error) {
+	// Get data from last N days in the account timezone
+	now := time.Now().In(a.location)
+	stats := &CampaignAudienceStats{
+		CampaignID:  campaignID,
+		Since:       now.AddDate(0, 0, -days).Format("2006-01-02"),
+		Until:       now.Format("2006-01-02"),
+		CollectedAt: now,
+	}
+
+	var err error
+	for _, breakdowns := range statsBreakdowns {
+		stats.Breakdowns = breakdowns
+		stats.Rows, err = a.breakdownStats(ctx, stats, breakdowns)
+
+		var apiErr *auth.FacebookAPIError
+		if err == nil || !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusBadRequest {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// breakdownStats reads the insights of a campaign split by the breakdowns, following the
+// paging links
+func (a *AudienceAnalyzer) breakdownStats(ctx context.Context, stats *CampaignAudienceStats, breakdowns string) ([]BreakdownStats, error) {
+	params := url.Values{}
+	params.Set("time_range", fmt.Sprintf(`{"since":"%s","until":"%s"}`, stats.Since, stats.Until))
+	params.Set("breakdowns", breakdowns)
+
+	// Only standard metrics; action breakdowns conflict with demographic breakdowns
+	params.Set("fields", "impressions,clicks,spend")
+
+	req, err := a.auth.GetAuthenticatedRequestContext(ctx, stats.CampaignID+"/insights", params)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	var rows []BreakdownStats
+	for req != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Data   []map[string]interface{} `json:"data"`
+			Paging struct {
+				Next string `json:"next"`
+			} `json:"paging"`
+		}
+		if err := a.getJSON(req, &page); err != nil {
+			return nil, err
+		}
+
+		for _, row := range page.Data {
+			rows = append(rows, parseBreakdownRow(row))
+		}
+
+		req = nil
+		if page.Paging.Next != "" {
+			if req, err = http.NewRequestWithContext(ctx, "GET", page.Paging.Next, nil); err != nil {
+				return nil, fmt.Errorf("error creating request: %w", err)
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+// getJSON sends a request and decodes its JSON response
+func (a *AudienceAnalyzer) getJSON(req *http.Request, v interface{}) error {
+	resp, err := a.doWithRetry(req)
+	if err != nil {
+		return fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+	return nil
+}
+
+// parseBreakdownRow converts an insights row into the performance of its bucket. The
+// API returns numbers as strings; the rates are derived from the totals.
+func parseBreakdownRow(row map[string]interface{}) BreakdownStats {
+	number := func(key string) float64 {
+		value, _ := strconv.ParseFloat(fmt.Sprint(row[key]), 64)
+		return value
+	}
+	text := func(key string) string {
+		value, _ := row[key].(string)
+		return value
+	}
+
+	performance := SegmentPerformance{
+		Impressions: int64(number("impressions")),
+		Clicks:      int64(number("clicks")),
+		Spend:       number("spend"),
+	}
+	if performance.Impressions > 0 {
+		performance.CTR = float64(performance.Clicks) / float64(performance.Impressions) * 100
+		performance.CPM = performance.Spend / float64(performance.Impressions) * 1000
+	}
+	if performance.Clicks > 0 {
+		performance.CPC = performance.Spend / float64(performance.Clicks)
+	}
+
+	return BreakdownStats{Age: text("age"), Gender: text("gender"), Performance: performance}
+}
+
+// SaveStats stores the statistics of a campaign in a JSON file keyed by campaign ID,
+// replacing statistics collected for it before
+func SaveStats(path string, stats *CampaignAudienceStats) error {
+	all, err := LoadStats(path)
+	if err != nil {
+		return err
+	}
+	all[stats.CampaignID] = stats
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing audience statistics: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating audience statistics directory: %w", err)
+	}
+
+	// Write to a temp file first so an interrupted run never leaves a truncated file
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("error writing audience statistics: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("error writing audience statistics: %w", err)
+	}
+
+	return nil
+}
+
+// LoadStats reads the statistics saved by SaveStats, by campaign ID. A missing file
+// holds no statistics.
+func LoadStats(path string) (map[string]*CampaignAudienceStats, error) {
+	all := make(map[string]*CampaignAudienceStats)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading audience statistics: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("error parsing audience statistics %s: %w", path, err)
+	}
+	return all, nil
+}
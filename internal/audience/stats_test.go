@@ -0,0 +1,101 @@
+package audience
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestCollectSegmentStatistics(t *testing.T) {
+	pages := map[string]string{
+		"age,gender": `{"data":[` +
+			`{"age":"25-34","gender":"female","impressions":"4000","clicks":"80","spend":"20.00","date_start":"2025-04-01","date_stop":"2025-04-30"},` +
+			`{"age":"25-34","gender":"male","impressions":"2000","clicks":"20","spend":"12.50"}],` +
+			`"paging":{"next":"https://graph.facebook.com/v22.0/100/insights?after=abc"}}`,
+		"after": `{"data":[{"age":"35-44","gender":"unknown","impressions":"0","clicks":"0","spend":"0"}]}`,
+		"age":   `{"data":[{"age":"18-24","impressions":"1000","clicks":"5","spend":"4"}]}`,
+	}
+
+	tests := []struct {
+		name       string
+		rejectAge  bool // Reject the age and gender breakdown like Facebook does for some campaigns
+		breakdowns string
+		labels     []string
+	}{
+		{name: "age and gender", breakdowns: "age,gender", labels: []string{"25-34 female", "25-34 male", "35-44 unknown"}},
+		{name: "age only", rejectAge: true, breakdowns: "age", labels: []string{"18-24"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAudienceAnalyzer(auth.NewFacebookAuth("app", "secret", "token", "v22.0"), "123")
+			analyzer.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+				query := req.URL.Query()
+				status, body := http.StatusOK, pages[query.Get("breakdowns")]
+				if query.Get("after") != "" {
+					body = pages["after"]
+				} else if tt.rejectAge && query.Get("breakdowns") == "age,gender" {
+					status, body = http.StatusBadRequest, `{"error":{"message":"Invalid breakdowns","code":100}}`
+				}
+				return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header)}
+			})}
+
+			stats, err := analyzer.CollectSegmentStatistics("100", 30)
+			if err != nil {
+				t.Fatalf("CollectSegmentStatistics failed: %v", err)
+			}
+			if stats.Breakdowns != tt.breakdowns || stats.CampaignID != "100" {
+				t.Errorf("Expected campaign 100 broken down by %s, got %s by %s", tt.breakdowns, stats.CampaignID, stats.Breakdowns)
+			}
+
+			var labels []string
+			for _, row := range stats.Rows {
+				labels = append(labels, row.Label())
+			}
+			if strings.Join(labels, ",") != strings.Join(tt.labels, ",") {
+				t.Errorf("Expected buckets %v, got %v", tt.labels, labels)
+			}
+		})
+	}
+}
+
+func TestParseBreakdownRow(t *testing.T) {
+	row := parseBreakdownRow(map[string]interface{}{"age": "25-34", "gender": "female", "impressions": "4000", "clicks": "80", "spend": "20.00"})
+
+	p := row.Performance
+	if p.Impressions != 4000 || p.Clicks != 80 || p.Spend != 20 {
+		t.Errorf("Unexpected totals: %+v", p)
+	}
+	if p.CTR != 2 || p.CPM != 5 || p.CPC != 0.25 {
+		t.Errorf("Expected CTR 2%%, CPM 5 and CPC 0.25, got %+v", p)
+	}
+
+	if empty := parseBreakdownRow(map[string]interface{}{"age": "65+", "impressions": "0"}); empty.Performance.CTR != 0 || empty.Performance.CPM != 0 {
+		t.Errorf("Expected no rates without impressions, got %+v", empty.Performance)
+	}
+}
+
+func TestSaveAndLoadStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fbads", DefaultStatsFile)
+
+	first := &CampaignAudienceStats{CampaignID: "100", Breakdowns: "age", Rows: []BreakdownStats{{Age: "18-24"}}}
+	second := &CampaignAudienceStats{CampaignID: "200", Breakdowns: "age,gender"}
+	updated := &CampaignAudienceStats{CampaignID: "100", Breakdowns: "age,gender"}
+	for _, stats := range []*CampaignAudienceStats{first, second, updated} {
+		if err := SaveStats(path, stats); err != nil {
+			t.Fatalf("SaveStats failed: %v", err)
+		}
+	}
+
+	all, err := LoadStats(path)
+	if err != nil {
+		t.Fatalf("LoadStats failed: %v", err)
+	}
+	if len(all) != 2 || all["100"].Breakdowns != "age,gender" || all["200"] == nil {
+		t.Errorf("Expected the latest statistics of both campaigns, got %+v", all)
+	}
+}
@@ -0,0 +1,237 @@
+package audience
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// interestValidity is an entry of the adinterestvalid search
+type interestValidity struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Valid bool   `json:"valid"`
+}
+
+// ValidInterests checks interest IDs with Facebook
+func (a *AudienceAnalyzer) ValidInterests(ids []string) (map[string]bool, error) {
+	return a.ValidInterestsContext(context.Background(), ids)
+}
+
+// ValidInterestsContext checks interest IDs with Facebook and reports whether each can
+// still be targeted. IDs Facebook doesn't return are invalid.
+func (a *AudienceAnalyzer) ValidInterestsContext(ctx context.Context, ids []string) (map[string]bool, error) {
+	valid := make(map[string]bool, len(ids))
+	if len(ids) == 0 {
+		return valid, nil
+	}
+	for _, id := range ids {
+		valid[id] = false
+	}
+
+	list, err := json.Marshal(ids)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding interest IDs: %w", err)
+	}
+	params := url.Values{}
+	params.Set("type", "adinterestvalid")
+	params.Set("interest_fbid_list", string(list))
+	req, err := a.auth.GetAuthenticatedRequestContext(ctx, "search", params)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	var resp struct {
+		Data []interestValidity `json:"data"`
+	}
+	if err := a.getJSON(req, &resp); err != nil {
+		return nil, err
+	}
+	for _, entry := range resp.Data {
+		if _, requested := valid[entry.ID]; requested {
+			valid[entry.ID] = entry.Valid
+		}
+	}
+	return valid, nil
+}
+
+// CheckInterests checks the interests targeted by the ad sets of a configuration
+func (a *AudienceAnalyzer) CheckInterests(config *models.CampaignConfig) (models.ValidationErrors, error) {
+	return a.CheckInterestsContext(context.Background(), config)
+}
+
+// CheckInterestsContext checks the interests targeted by the ad sets of a configuration
+// with Facebook. Each interest that can't be targeted is reported at its ad set and
+// targeting key, e.g. adsets[0].targeting.flexible_spec[0].interests[1].
+func (a *AudienceAnalyzer) CheckInterestsContext(ctx context.Context, config *models.CampaignConfig) (models.ValidationErrors, error) {
+	return a.CheckTargetingInterestsContext(ctx, models.AdSetTargeting(config))
+}
+
+// CheckTargetingInterestsContext checks the interests of targeting specs with Facebook, in
+// one request. Each interest that can't be targeted is reported below the path of its spec.
+// An error means the check itself failed.
+func (a *AudienceAnalyzer) CheckTargetingInterestsContext(ctx context.Context, specs []models.TargetingSpec) (models.ValidationErrors, error) {
+	type reference struct {
+		path string
+		id   string
+	}
+	var references []reference
+	var ids []string
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		for _, interest := range models.TargetingInterests(spec.Targeting) {
+			references = append(references, reference{path: spec.Path + "." + interest.Path, id: interest.ID})
+			if !seen[interest.ID] {
+				seen[interest.ID] = true
+				ids = append(ids, interest.ID)
+			}
+		}
+	}
+
+	var problems models.ValidationErrors
+	if len(ids) == 0 {
+		return problems, nil
+	}
+	valid, err := a.ValidInterestsContext(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error checking interests: %w", err)
+	}
+	for _, ref := range references {
+		if !valid[ref.id] {
+			problems.Add(ref.path, "interest %s doesn't exist or can no longer be targeted", ref.id)
+		}
+	}
+	return problems, nil
+}
+
+// Genders of a TargetingSpec, as Facebook numbers them
+const (
+	GenderMale   = 1
+	GenderFemale = 2
+)
+
+// Ages Facebook can target
+const (
+	MinTargetingAge = 13
+	MaxTargetingAge = 65
+)
+
+// TargetingSpec describes the people an audience size is estimated for
+type TargetingSpec struct {
+	Countries []string // Country codes; empty for DefaultAudienceCountries
+	Interests []string // Interest IDs, people with any of them are counted
+	Behaviors []string // Behavior IDs
+	AgeMin    int      // 0 for no lower age limit
+	AgeMax    int      // 0 for no upper age limit
+	Genders   []int    // GenderMale and GenderFemale; empty for all genders
+}
+
+// Validate reports an age range or gender Facebook can't target
+func (s TargetingSpec) Validate() error {
+	for _, age := range []int{s.AgeMin, s.AgeMax} {
+		if age != 0 && (age < MinTargetingAge || age > MaxTargetingAge) {
+			return fmt.Errorf("age %d is outside %d-%d", age, MinTargetingAge, MaxTargetingAge)
+		}
+	}
+	if s.AgeMin != 0 && s.AgeMax != 0 && s.AgeMin > s.AgeMax {
+		return fmt.Errorf("minimum age %d is above the maximum age %d", s.AgeMin, s.AgeMax)
+	}
+	for _, gender := range s.Genders {
+		if gender != GenderMale && gender != GenderFemale {
+			return fmt.Errorf("unknown gender %d (use %d for male or %d for female)", gender, GenderMale, GenderFemale)
+		}
+	}
+	return nil
+}
+
+// Map returns the spec as the targeting of a delivery estimate. Unset fields are left out.
+func (s TargetingSpec) Map() map[string]interface{} {
+	countries := s.Countries
+	if len(countries) == 0 {
+		countries = DefaultAudienceCountries
+	}
+	spec := map[string]interface{}{
+		"geo_locations": map[string]interface{}{"countries": countries},
+	}
+	if len(s.Interests) > 0 {
+		spec["interests"] = targetingIDs(s.Interests)
+	}
+	if len(s.Behaviors) > 0 {
+		spec["behaviors"] = targetingIDs(s.Behaviors)
+	}
+	if s.AgeMin != 0 {
+		spec["age_min"] = s.AgeMin
+	}
+	if s.AgeMax != 0 {
+		spec["age_max"] = s.AgeMax
+	}
+	if len(s.Genders) > 0 {
+		spec["genders"] = s.Genders
+	}
+	return spec
+}
+
+// MarshalJSON encodes the spec as Facebook targeting
+func (s TargetingSpec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Map())
+}
+
+// ForSegment returns the spec narrowed to a segment found by search, as an interest or a
+// behavior. Segments of other types, such as demographics, can't be estimated this way.
+func (s TargetingSpec) ForSegment(segment AudienceSegment) (TargetingSpec, bool) {
+	switch segment.Type {
+	case "", "interest", "interests":
+		s.Interests = []string{segment.ID}
+		s.Behaviors = nil
+	case "behavior", "behaviors":
+		s.Interests = nil
+		s.Behaviors = []string{segment.ID}
+	default:
+		return s, false
+	}
+	return s, true
+}
+
+// targetingIDs returns IDs in the form Facebook targeting lists them
+func targetingIDs(ids []string) []map[string]string {
+	list := make([]map[string]string, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, map[string]string{"id": id})
+	}
+	return list
+}
+
+// ParseAgeRange parses an age range like "25-44", or "25+" for no upper limit
+func ParseAgeRange(value string) (int, int, error) {
+	var minText, maxText string
+	if prefix, ok := strings.CutSuffix(value, "+"); ok {
+		minText = prefix
+	} else {
+		var found bool
+		minText, maxText, found = strings.Cut(value, "-")
+		if !found {
+			return 0, 0, fmt.Errorf("invalid age range %q (use MIN-MAX or MIN+, e.g. 25-44)", value)
+		}
+	}
+
+	min, err := strconv.Atoi(strings.TrimSpace(minText))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid age range %q (use MIN-MAX or MIN+, e.g. 25-44)", value)
+	}
+	max := 0
+	if maxText != "" {
+		if max, err = strconv.Atoi(strings.TrimSpace(maxText)); err != nil {
+			return 0, 0, fmt.Errorf("invalid age range %q (use MIN-MAX or MIN+, e.g. 25-44)", value)
+		}
+	}
+
+	if err := (TargetingSpec{AgeMin: min, AgeMax: max}).Validate(); err != nil {
+		return 0, 0, fmt.Errorf("invalid age range %q: %w", value, err)
+	}
+	return min, max, nil
+}
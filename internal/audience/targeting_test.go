@@ -0,0 +1,139 @@
+package audience
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestCheckInterests(t *testing.T) {
+	var requested string
+	analyzer := NewAudienceAnalyzer(auth.NewFacebookAuth("app", "secret", "token", "v22.0"), "x")
+	analyzer.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		if req.URL.Query().Get("type") != "adinterestvalid" {
+			t.Errorf("Expected an adinterestvalid search, got %s", req.URL)
+		}
+		requested = req.URL.Query().Get("interest_fbid_list")
+		body := `{"data":[{"id":"6003107902433","name":"Running","valid":true},{"id":"6003000000001","name":"Retired","valid":false}]}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     make(http.Header),
+		}
+	})}
+
+	config := &models.CampaignConfig{AdSets: []models.AdSetConfig{
+		{Name: "Runners", Targeting: map[string]interface{}{
+			"interests": []interface{}{map[string]interface{}{"id": "6003107902433"}},
+		}},
+		{Name: "Mixed", Targeting: map[string]interface{}{
+			"flexible_spec": []interface{}{map[string]interface{}{"interests": []interface{}{
+				map[string]interface{}{"id": "6003107902433"},
+				map[string]interface{}{"id": "6003000000001"},
+			}}},
+			"exclusions": map[string]interface{}{"interests": []interface{}{map[string]interface{}{"id": "6003999999999"}}},
+		}},
+	}}
+
+	problems, err := analyzer.CheckInterests(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requested != `["6003107902433","6003000000001","6003999999999"]` {
+		t.Errorf("Expected each interest to be checked once, got %s", requested)
+	}
+
+	var paths []string
+	for _, problem := range problems.Errors() {
+		paths = append(paths, problem.Path)
+	}
+	expected := "adsets[1].targeting.flexible_spec[0].interests[1] adsets[1].targeting.exclusions.interests[0]"
+	if strings.Join(paths, " ") != expected {
+		t.Errorf("Expected problems at %s, got %v", expected, paths)
+	}
+}
+
+func TestTargetingSpecJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     TargetingSpec
+		expected string
+	}{
+		{
+			name:     "default countries",
+			spec:     TargetingSpec{},
+			expected: `{"geo_locations":{"countries":["US"]}}`,
+		},
+		{
+			name:     "interests and ages",
+			spec:     TargetingSpec{Countries: []string{"GB", "DE"}, Interests: []string{"6003", "6004"}, AgeMin: 25, AgeMax: 44},
+			expected: `{"age_max":44,"age_min":25,"geo_locations":{"countries":["GB","DE"]},"interests":[{"id":"6003"},{"id":"6004"}]}`,
+		},
+		{
+			name:     "behaviors and genders",
+			spec:     TargetingSpec{Countries: []string{"FR"}, Behaviors: []string{"6015"}, AgeMin: 30, Genders: []int{GenderFemale}},
+			expected: `{"age_min":30,"behaviors":[{"id":"6015"}],"genders":[2],"geo_locations":{"countries":["FR"]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.spec)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestTargetingSpecForSegment(t *testing.T) {
+	base := TargetingSpec{Countries: []string{"GB"}, AgeMin: 25}
+
+	spec, ok := base.ForSegment(AudienceSegment{ID: "6003"})
+	if !ok || len(spec.Interests) != 1 || spec.Interests[0] != "6003" || spec.AgeMin != 25 {
+		t.Errorf("Expected a segment without type to be estimated as an interest, got %+v", spec)
+	}
+	spec, ok = base.ForSegment(AudienceSegment{ID: "6015", Type: "behaviors"})
+	if !ok || len(spec.Interests) != 0 || len(spec.Behaviors) != 1 {
+		t.Errorf("Expected a behavior, got %+v", spec)
+	}
+	if _, ok := base.ForSegment(AudienceSegment{ID: "6100", Type: "demographics"}); ok {
+		t.Error("Expected demographics to be left out")
+	}
+}
+
+func TestParseAgeRange(t *testing.T) {
+	tests := []struct {
+		value    string
+		min, max int
+		wantErr  bool
+	}{
+		{value: "25-44", min: 25, max: 44},
+		{value: "18+", min: 18},
+		{value: "25-", min: 25},
+		{value: "44-25", wantErr: true},
+		{value: "10-20", wantErr: true},
+		{value: "25", wantErr: true},
+		{value: "young", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			min, max, err := ParseAgeRange(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAgeRange(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && (min != tt.min || max != tt.max) {
+				t.Errorf("ParseAgeRange(%q) = %d, %d, want %d, %d", tt.value, min, max, tt.min, tt.max)
+			}
+		})
+	}
+}
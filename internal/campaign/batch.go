@@ -0,0 +1,376 @@
+package campaign
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// MaxBatchSize is the largest number of requests Facebook accepts in one batch call
+const MaxBatchSize = 50
+
+// errNotRun is the error of a batched request that Facebook skipped, which happens when
+// a request it depends on failed
+var errNotRun = errors.New("request was not run because a request it depends on failed")
+
+// batchReference matches an encoded {result=<name>:$.id} reference, which must reach
+// Facebook unescaped to be replaced with the ID created by the named request
+var batchReference = regexp.MustCompile(`%7Bresult%3D([A-Za-z0-9_]+)%3A%24\.id%7D`)
+
+// BatchCreator creates many campaign structures with few API calls. The campaign, ad set,
+// creative and ad requests of each configuration are packed into Graph API batch requests,
+// where later requests reference the IDs created by earlier ones. Requests that fail
+// inside a batch are retried one at a time through the campaign creator.
+type BatchCreator struct {
+	creator   *CampaignCreator
+	batchSize int
+}
+
+// NewBatchCreator creates a batch creator that sends up to MaxBatchSize requests per call
+func NewBatchCreator(creator *CampaignCreator) *BatchCreator {
+	return &BatchCreator{creator: creator, batchSize: MaxBatchSize}
+}
+
+// SetBatchSize sets the number of requests sent per batch call, capped at MaxBatchSize
+func (b *BatchCreator) SetBatchSize(size int) {
+	if size <= 0 || size > MaxBatchSize {
+		size = MaxBatchSize
+	}
+	b.batchSize = size
+}
+
+// BatchResult is the outcome of creating one campaign configuration. CampaignID is set
+// whenever the campaign was created, also when creating one of its ad sets or ads failed.
+type BatchResult struct {
+	CampaignID string
+	Err        error
+}
+
+// batchRequest is one request of a batch call
+type batchRequest struct {
+	Method      string `json:"method"`
+	RelativeURL string `json:"relative_url"`
+	Body        string `json:"body,omitempty"`
+	Name        string `json:"name,omitempty"`
+
+	// Facebook leaves out the response of referenced requests unless told otherwise,
+	// but their IDs are needed to retry the requests that depend on them
+	OmitResponseOnSuccess bool `json:"omit_response_on_success"`
+}
+
+// batchResponse is the response to one request of a batch call
+type batchResponse struct {
+	Code int    `json:"code"`
+	Body string `json:"body"`
+}
+
+// plannedAd is an ad of a batch plan with the requests creating its creative and itself.
+// An ad reusing an existing creative has no creative request, and creative is -1.
+type plannedAd struct {
+	config   *models.AdConfig
+	adSet    int
+	creative int
+	ad       int
+}
+
+// batchPlan holds the requests that create one campaign configuration and, once sent,
+// the ID or error of each request
+type batchPlan struct {
+	index    int
+	config   *models.CampaignConfig
+	requests []batchRequest
+	adSets   []int
+	ads      []plannedAd
+
+	ids  []string
+	errs []error
+}
+
+// CreateCampaigns creates every campaign configuration and returns one result per
+// configuration, in order
+func (b *BatchCreator) CreateCampaigns(configs []*models.CampaignConfig) []BatchResult {
+	return b.CreateCampaignsContext(context.Background(), configs)
+}
+
+// CreateCampaignsContext creates campaign configurations like CreateCampaigns. The requests
+// of one configuration always go into the same batch call, so a configuration that needs
+// more requests than fit in a batch is created one request at a time.
+func (b *BatchCreator) CreateCampaignsContext(ctx context.Context, configs []*models.CampaignConfig) []BatchResult {
+	results := make([]BatchResult, len(configs))
+	labels := b.ownershipLabels(ctx)
+
+	var plans []*batchPlan
+	for i, config := range configs {
+		if err := ValidateMessagingConfig(config); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err := b.creator.UploadCreativeImagesContext(ctx, config); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		plan, err := b.creator.planCampaign(fmt.Sprintf("c%d", i), config, labels)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		plan.index = i
+
+		if len(plan.requests) > b.batchSize {
+			results[i].CampaignID, results[i].Err = b.creator.CreateFromConfigWithIDContext(ctx, config)
+			continue
+		}
+		plans = append(plans, plan)
+	}
+
+	for len(plans) > 0 {
+		count, size := 0, 0
+		for count < len(plans) && size+len(plans[count].requests) <= b.batchSize {
+			size += len(plans[count].requests)
+			count++
+		}
+		chunk := plans[:count]
+		plans = plans[count:]
+
+		var requests []batchRequest
+		for _, plan := range chunk {
+			requests = append(requests, plan.requests...)
+		}
+
+		responses, err := b.creator.sendBatch(ctx, requests)
+		if err != nil {
+			for _, plan := range chunk {
+				results[plan.index].Err = fmt.Errorf("error sending batch request: %w", err)
+			}
+			continue
+		}
+
+		for _, plan := range chunk {
+			plan.record(responses[:len(plan.requests)])
+			responses = responses[len(plan.requests):]
+			results[plan.index] = b.creator.completePlan(ctx, plan)
+		}
+	}
+
+	return results
+}
+
+// ownershipLabels returns the adlabels parameter that labels created objects as ours, or ""
+// if the label can't be found or created, in which case objects are created unlabeled
+func (b *BatchCreator) ownershipLabels(ctx context.Context) string {
+	labelID, err := b.creator.ownershipLabelID(ctx)
+	if err != nil {
+		fmt.Printf("Warning: could not label the new objects as created by fbads: %v\n", err)
+		return ""
+	}
+
+	labels, _ := json.Marshal([]map[string]string{{"id": labelID}})
+	return string(labels)
+}
+
+// planCampaign builds the requests that create a campaign configuration. Requests are
+// named after prefix, so plans with different prefixes can share a batch call.
+func (c *CampaignCreator) planCampaign(prefix string, config *models.CampaignConfig, labels string) (*batchPlan, error) {
+	plan := &batchPlan{config: config}
+	add := func(edge, name string, params url.Values) int {
+		plan.requests = append(plan.requests, batchRequest{
+			Method:      http.MethodPost,
+			RelativeURL: fmt.Sprintf("act_%s/%s", c.accountID, edge),
+			Body:        batchBody(params),
+			Name:        name,
+		})
+		return len(plan.requests) - 1
+	}
+	owned := func(params url.Values) url.Values {
+		if labels != "" {
+			params.Set("adlabels", labels)
+		}
+		return params
+	}
+
+	campaignName := prefix
+	add("campaigns", campaignName, owned(campaignParams(config, c.normalizeObjectives)))
+
+	adSetNames := make([]string, len(config.AdSets))
+	for i := range config.AdSets {
+		params, err := adSetParams(batchResult(campaignName), &config.AdSets[i])
+		if err != nil {
+			return nil, fmt.Errorf("error creating ad set: %w", err)
+		}
+		adSetNames[i] = fmt.Sprintf("%s_s%d", prefix, i)
+		plan.adSets = append(plan.adSets, add("adsets", adSetNames[i], owned(params)))
+	}
+
+	// Nested ads go to their own ad set, top-level ads cycle through the ad sets
+	addAd := func(adSetIndex int, config *models.AdConfig) error {
+		ad := plannedAd{config: config, adSet: adSetIndex, creative: -1}
+		if creativeID := config.Creative.CreativeID; creativeID != "" {
+			ad.ad = add("ads", "", owned(adParams(batchResult(adSetNames[adSetIndex]), creativeID, config)))
+			plan.ads = append(plan.ads, ad)
+			return nil
+		}
+
+		destination := plan.config.AdSets[adSetIndex].DestinationType
+		params, err := creativeParams(config.Creative, destination)
+		if err != nil {
+			return fmt.Errorf("error creating creative: %w", err)
+		}
+
+		creativeName := fmt.Sprintf("%s_cr%d", prefix, len(plan.ads))
+		ad.creative = add("adcreatives", creativeName, params)
+		ad.ad = add("ads", "", owned(adParams(batchResult(adSetNames[adSetIndex]), batchResult(creativeName), config)))
+		plan.ads = append(plan.ads, ad)
+		return nil
+	}
+
+	for i := range config.AdSets {
+		for j := range config.AdSets[i].Ads {
+			if err := addAd(i, &config.AdSets[i].Ads[j]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i := range config.Ads {
+		if len(config.AdSets) == 0 {
+			return nil, fmt.Errorf("ad %s needs an ad set", config.Ads[i].Name)
+		}
+		if err := addAd(i%len(config.AdSets), &config.Ads[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// batchResult returns a reference to the ID created by the named request of the same batch
+func batchResult(name string) string {
+	return fmt.Sprintf("{result=%s:$.id}", name)
+}
+
+// batchBody encodes the parameters of a batched request, leaving references unescaped
+func batchBody(params url.Values) string {
+	return batchReference.ReplaceAllString(params.Encode(), "{result=$1:$.id}")
+}
+
+// record stores the ID or error of each request of the plan from the batch responses
+func (p *batchPlan) record(responses []*batchResponse) {
+	p.ids = make([]string, len(p.requests))
+	p.errs = make([]error, len(p.requests))
+
+	for i, resp := range responses {
+		if resp == nil {
+			p.errs[i] = errNotRun
+			continue
+		}
+		if resp.Code != http.StatusOK {
+			p.errs[i] = fmt.Errorf("API error: %w", auth.ParseAPIError(resp.Code, []byte(resp.Body)))
+			continue
+		}
+
+		var result struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal([]byte(resp.Body), &result); err != nil || result.ID == "" {
+			p.errs[i] = fmt.Errorf("no ID in the response: %s", resp.Body)
+			continue
+		}
+		p.ids[i] = result.ID
+	}
+}
+
+// completePlan retries the requests of a sent plan that failed, one at a time. Without a
+// campaign nothing else was created, so the whole configuration is created again.
+func (c *CampaignCreator) completePlan(ctx context.Context, plan *batchPlan) BatchResult {
+	if plan.errs[0] != nil {
+		fmt.Printf("Batched campaign %s failed (%v), creating it request by request\n", plan.config.Name, plan.errs[0])
+		campaignID, err := c.CreateFromConfigWithIDContext(ctx, plan.config)
+		return BatchResult{CampaignID: campaignID, Err: err}
+	}
+	campaignID := plan.ids[0]
+
+	adSetIDs := make([]string, len(plan.adSets))
+	for i, request := range plan.adSets {
+		adSetIDs[i] = plan.ids[request]
+		if plan.errs[request] == nil {
+			continue
+		}
+
+		id, err := c.CreateAdSetContext(ctx, campaignID, &plan.config.AdSets[i])
+		if err != nil {
+			return BatchResult{CampaignID: campaignID, Err: fmt.Errorf("error creating ad set: %w", err)}
+		}
+		adSetIDs[i] = id
+	}
+
+	for _, ad := range plan.ads {
+		if plan.errs[ad.ad] == nil {
+			continue
+		}
+
+		var err error
+		if ad.creative >= 0 && plan.ids[ad.creative] != "" {
+			endpoint := fmt.Sprintf("act_%s/ads", c.accountID)
+			_, err = c.createOwned(ctx, endpoint, adParams(adSetIDs[ad.adSet], plan.ids[ad.creative], ad.config))
+		} else {
+			_, err = c.createAd(ctx, adSetIDs[ad.adSet], ad.config, plan.config.AdSets[ad.adSet].DestinationType)
+		}
+		if err != nil {
+			return BatchResult{CampaignID: campaignID, Err: fmt.Errorf("error creating ad: %w", err)}
+		}
+	}
+
+	return BatchResult{CampaignID: campaignID}
+}
+
+// sendBatch sends requests as one batch call and returns a response per request. The
+// response of a request Facebook did not run is nil.
+func (c *CampaignCreator) sendBatch(ctx context.Context, requests []batchRequest) ([]*batchResponse, error) {
+	data, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding batch: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("access_token", c.auth.AccessToken)
+	params.Set("batch", string(data))
+	params.Set("include_headers", "false")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.auth.GetAPIBaseURL(), strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
+	}
+
+	var responses []*batchResponse
+	if err := json.Unmarshal(body, &responses); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w - %s", err, string(body))
+	}
+	if len(responses) != len(requests) {
+		return nil, fmt.Errorf("expected %d batch responses, got %d", len(requests), len(responses))
+	}
+	return responses, nil
+}
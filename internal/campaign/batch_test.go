@@ -0,0 +1,206 @@
+package campaign
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// batchReferencePattern matches a reference to the ID created by a named batched request
+var batchReferencePattern = regexp.MustCompile(`\{result=(\w+):\$\.id\}`)
+
+// batchAPI is a fake Graph API answering batch calls. The ad set requests named in
+// failAdSets fail, and requests referencing a failed request are not run.
+type batchAPI struct {
+	labels     labelAPI
+	failAdSets map[string]bool
+	calls      int
+	sequential []string // Requests sent outside batch calls
+	bodies     []string // Bodies of the batched requests
+	nextID     int
+}
+
+func (f *batchAPI) roundTrip(req *http.Request) (*http.Response, error) {
+	path := strings.TrimPrefix(req.URL.Path, "/v22.0")
+	if strings.Contains(path, "adlabels") {
+		return f.labels.roundTrip(req)
+	}
+
+	data, _ := io.ReadAll(req.Body)
+	form, _ := url.ParseQuery(string(data))
+	var body string
+	if path == "" {
+		f.calls++
+		var requests []batchRequest
+		if err := json.Unmarshal([]byte(form.Get("batch")), &requests); err != nil {
+			return nil, err
+		}
+
+		created := make(map[string]string)
+		var responses []interface{}
+		for _, request := range requests {
+			f.bodies = append(f.bodies, request.Body)
+
+			resolved := true
+			request.Body = batchReferencePattern.ReplaceAllStringFunc(request.Body, func(ref string) string {
+				id, ok := created[batchReferencePattern.FindStringSubmatch(ref)[1]]
+				resolved = resolved && ok
+				return id
+			})
+			if !resolved {
+				responses = append(responses, nil)
+				continue
+			}
+			if f.failAdSets[request.Name] {
+				responses = append(responses, batchResponse{Code: 400, Body: `{"error":{"message":"Invalid targeting","code":100}}`})
+				continue
+			}
+
+			id := f.newID()
+			created[request.Name] = id
+			responses = append(responses, batchResponse{Code: 200, Body: `{"id":"` + id + `"}`})
+		}
+
+		encoded, _ := json.Marshal(responses)
+		body = string(encoded)
+	} else {
+		body = fmt.Sprintf(`{"id":"%s"}`, f.newID())
+		f.sequential = append(f.sequential, strings.TrimPrefix(path, "/act_123/")+" "+form.Get("creative"))
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func (f *batchAPI) newID() string {
+	f.nextID++
+	return fmt.Sprint(f.nextID)
+}
+
+func TestBatchCreator_CreateCampaigns(t *testing.T) {
+	newConfig := func(name string) *models.CampaignConfig {
+		return &models.CampaignConfig{
+			Name:       name,
+			Objective:  "OUTCOME_TRAFFIC",
+			BuyingType: "AUCTION",
+			AdSets:     []models.AdSetConfig{{Name: name + " set"}},
+			Ads: []models.AdConfig{{
+				Name:     name + " ad",
+				Creative: models.CreativeConfig{PageID: "1", LinkURL: "https://example.com", ImageHash: "abc"},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		batchSize  int
+		failAdSets map[string]bool
+		calls      int
+		sequential int
+	}{
+		{name: "one call", calls: 1},
+		{name: "one call per campaign", batchSize: 4, calls: 2},
+		{name: "campaigns larger than a batch", batchSize: 3, calls: 0, sequential: 8},
+		{
+			name:       "failed ad set retried",
+			failAdSets: map[string]bool{"c1_s0": true},
+			calls:      1,
+			sequential: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &batchAPI{labels: labelAPI{existing: "[]"}, failAdSets: tt.failAdSets}
+			creator := newLabelCreator(&fake.labels)
+			creator.httpClient.Transport = roundTripFunc(fake.roundTrip)
+
+			batchCreator := NewBatchCreator(creator)
+			batchCreator.SetBatchSize(tt.batchSize)
+			results := batchCreator.CreateCampaigns([]*models.CampaignConfig{newConfig("A"), newConfig("B")})
+
+			for i, result := range results {
+				if result.Err != nil || result.CampaignID == "" {
+					t.Errorf("Campaign %d: got ID %q and error %v", i+1, result.CampaignID, result.Err)
+				}
+			}
+			if fake.calls != tt.calls {
+				t.Errorf("Expected %d batch calls, got %d", tt.calls, fake.calls)
+			}
+			if len(fake.sequential) != tt.sequential {
+				t.Errorf("Expected %d sequential requests, got %v", tt.sequential, fake.sequential)
+			}
+		})
+	}
+}
+
+func TestBatchCreator_RequestBodies(t *testing.T) {
+	fake := &batchAPI{labels: labelAPI{existing: "[]"}, failAdSets: map[string]bool{"c0_s0": true}}
+	creator := newLabelCreator(&fake.labels)
+	creator.httpClient.Transport = roundTripFunc(fake.roundTrip)
+
+	config := &models.CampaignConfig{
+		Name:       "A",
+		Objective:  "OUTCOME_TRAFFIC",
+		BuyingType: "AUCTION",
+		AdSets:     []models.AdSetConfig{{Name: "Broad"}},
+		Ads:        []models.AdConfig{{Name: "Ad", Creative: models.CreativeConfig{PageID: "1", LinkURL: "https://example.com"}}},
+	}
+	results := NewBatchCreator(creator).CreateCampaigns([]*models.CampaignConfig{config})
+	if results[0].Err != nil {
+		t.Fatalf("Unexpected error: %v", results[0].Err)
+	}
+
+	// References reach Facebook unescaped and created objects carry the ownership label
+	for i, want := range []string{"adlabels=%5B%7B%22id%22%3A%22555%22%7D%5D", "campaign_id={result=c0:$.id}", "", "adset_id={result=c0_s0:$.id}"} {
+		if !strings.Contains(fake.bodies[i], want) {
+			t.Errorf("Expected request %d to contain %s, got %s", i+1, want, fake.bodies[i])
+		}
+	}
+	if !strings.Contains(fake.bodies[3], "creative=%7B%22creative_id%22%3A%22{result=c0_cr0:$.id}%22%7D") {
+		t.Errorf("Expected the ad to reference its creative, got %s", fake.bodies[3])
+	}
+
+	// The ad set is retried and the ad reuses the creative created in the batch
+	expected := []string{"adsets ", `ads {"creative_id":"2"}`}
+	if strings.Join(fake.sequential, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected sequential requests %v, got %v", expected, fake.sequential)
+	}
+}
+
+func TestBatchCreator_ReusedCreative(t *testing.T) {
+	fake := &batchAPI{labels: labelAPI{existing: "[]"}, failAdSets: map[string]bool{"c0_s0": true}}
+	creator := newLabelCreator(&fake.labels)
+	creator.httpClient.Transport = roundTripFunc(fake.roundTrip)
+
+	config := &models.CampaignConfig{
+		Name:       "A",
+		Objective:  "OUTCOME_TRAFFIC",
+		BuyingType: "AUCTION",
+		AdSets:     []models.AdSetConfig{{Name: "Broad"}},
+		Ads:        []models.AdConfig{{Name: "Ad", Creative: models.CreativeConfig{CreativeID: "777"}}},
+	}
+	results := NewBatchCreator(creator).CreateCampaigns([]*models.CampaignConfig{config})
+	if results[0].Err != nil {
+		t.Fatalf("Unexpected error: %v", results[0].Err)
+	}
+
+	// No creative is created; the ad references the existing one in the batch and on retry
+	if len(fake.bodies) != 3 || !strings.Contains(fake.bodies[2], "creative=%7B%22creative_id%22%3A%22777%22%7D") {
+		t.Errorf("Expected the campaign, ad set and an ad with the reused creative, got %v", fake.bodies)
+	}
+	expected := []string{"adsets ", `ads {"creative_id":"777"}`}
+	if strings.Join(fake.sequential, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected sequential requests %v, got %v", expected, fake.sequential)
+	}
+}
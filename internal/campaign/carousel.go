@@ -0,0 +1,48 @@
+package campaign
+
+import (
+	"fmt"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// MinCarouselCards is the fewest cards a carousel ad can have
+const MinCarouselCards = models.MinCarouselCards
+
+// MaxCarouselCards is the most cards a carousel ad can have
+const MaxCarouselCards = models.MaxCarouselCards
+
+// carouselAttachments builds the child_attachments of a carousel ad's link_data
+func carouselAttachments(cards []models.CreativeCard) ([]map[string]interface{}, error) {
+	if len(cards) < MinCarouselCards || len(cards) > MaxCarouselCards {
+		return nil, fmt.Errorf("a carousel needs %d to %d cards, got %d", MinCarouselCards, MaxCarouselCards, len(cards))
+	}
+
+	attachments := make([]map[string]interface{}, 0, len(cards))
+	for i, card := range cards {
+		if card.LinkURL == "" {
+			return nil, fmt.Errorf("link_url is required for carousel card %d", i+1)
+		}
+
+		attachment := map[string]interface{}{
+			"link": card.LinkURL,
+		}
+		if card.Title != "" {
+			attachment["name"] = card.Title
+		}
+		if card.Body != "" {
+			attachment["description"] = card.Body
+		}
+		if card.ImageHash != "" {
+			attachment["image_hash"] = card.ImageHash
+		}
+		if card.CallToAction != "" {
+			attachment["call_to_action"] = map[string]interface{}{
+				"type":  card.CallToAction,
+				"value": map[string]string{"link": card.LinkURL},
+			}
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
@@ -0,0 +1,115 @@
+package campaign
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestCreativeParams_Carousel(t *testing.T) {
+	cards := []models.CreativeCard{
+		{Title: "Tents", Body: "From $99", LinkURL: "https://example.com/tents", ImageHash: "h1", CallToAction: "SHOP_NOW"},
+		{Title: "Boots", LinkURL: "https://example.com/boots", ImageHash: "h2"},
+	}
+
+	tests := []struct {
+		name        string
+		config      models.CreativeConfig
+		link        string
+		expectError string
+	}{
+		{
+			name:   "see more link",
+			config: models.CreativeConfig{PageID: "1", Body: "Spring gear", LinkURL: "https://example.com", Cards: cards},
+			link:   "https://example.com",
+		},
+		{
+			name:   "first card link",
+			config: models.CreativeConfig{PageID: "1", Cards: cards},
+			link:   "https://example.com/tents",
+		},
+		{
+			name:        "one card",
+			config:      models.CreativeConfig{PageID: "1", Cards: cards[:1]},
+			expectError: "2 to 10 cards",
+		},
+		{
+			name:        "card without link",
+			config:      models.CreativeConfig{PageID: "1", LinkURL: "https://example.com", Cards: []models.CreativeCard{cards[0], {Title: "Boots"}}},
+			expectError: "carousel card 2",
+		},
+		{
+			name:        "video and cards",
+			config:      models.CreativeConfig{PageID: "1", VideoID: "v1", ImageHash: "abc", Cards: cards},
+			expectError: "not both",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := creativeParams(tt.config, "")
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("creativeParams failed: %v", err)
+			}
+
+			var spec struct {
+				LinkData struct {
+					Link             string            `json:"link"`
+					ChildAttachments []json.RawMessage `json:"child_attachments"`
+				} `json:"link_data"`
+			}
+			if err := json.Unmarshal([]byte(params.Get("object_story_spec")), &spec); err != nil {
+				t.Fatal(err)
+			}
+			if spec.LinkData.Link != tt.link {
+				t.Errorf("Expected link %s, got %s", tt.link, spec.LinkData.Link)
+			}
+
+			expected := []string{
+				`{"call_to_action":{"type":"SHOP_NOW","value":{"link":"https://example.com/tents"}},"description":"From $99","image_hash":"h1","link":"https://example.com/tents","name":"Tents"}`,
+				`{"image_hash":"h2","link":"https://example.com/boots","name":"Boots"}`,
+			}
+			if len(spec.LinkData.ChildAttachments) != len(expected) {
+				t.Fatalf("Expected %d cards, got %d", len(expected), len(spec.LinkData.ChildAttachments))
+			}
+			for i, want := range expected {
+				if got := string(spec.LinkData.ChildAttachments[i]); got != want {
+					t.Errorf("Card %d = %s, want %s", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestUploadCreativeImages_CarouselCards(t *testing.T) {
+	fake := &imageAPI{}
+	authClient := auth.NewFacebookAuth("app", "secret", "token", "v22.0")
+	authClient.Transport = roundTripFunc(fake.roundTrip)
+	creator := NewCampaignCreator(authClient, "123")
+
+	config := &models.CampaignConfig{Ads: []models.AdConfig{{Creative: models.CreativeConfig{Cards: []models.CreativeCard{
+		{LinkURL: "https://example.com/a", ImageURL: "https://cdn.example.com/a.jpg"},
+		{LinkURL: "https://example.com/b", ImageHash: "existing"},
+	}}}}}
+
+	if err := creator.UploadCreativeImages(config); err != nil {
+		t.Fatalf("UploadCreativeImages failed: %v", err)
+	}
+
+	cards := config.Ads[0].Creative.Cards
+	if cards[0].ImageHash != "hash-remote-bytes" || cards[1].ImageHash != "existing" {
+		t.Errorf("Unexpected card image hashes %q and %q", cards[0].ImageHash, cards[1].ImageHash)
+	}
+	if len(fake.uploads) != 1 {
+		t.Errorf("Expected one upload, got %v", fake.uploads)
+	}
+}
@@ -1,12 +1,15 @@
 package campaign
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/user/fb-ads/pkg/auth"
 	"github.com/user/fb-ads/pkg/models"
@@ -17,78 +20,168 @@ type CampaignCreator struct {
 	httpClient *http.Client
 	auth       *auth.FacebookAuth
 	accountID  string
+
+	labelMu sync.Mutex
+	labelID string // Cached ID of the ownership label
+
+	imageMu     sync.Mutex
+	imageHashes map[string]string // Hashes of uploaded images by path or URL
+
+	normalizeObjectives bool // Send legacy objectives as their outcome-based equivalents
 }
 
-// NewCampaignCreator creates a new campaign creator
+// NewCampaignCreator creates a new campaign creator. Its requests go through the auth
+// client's Transport when one is set, so tests and demo mode can answer them in-process.
 func NewCampaignCreator(auth *auth.FacebookAuth, accountID string) *CampaignCreator {
 	return &CampaignCreator{
-		httpClient: &http.Client{},
-		auth:       auth,
-		accountID:  accountID,
+		httpClient:          auth.HTTPClient(),
+		auth:                auth,
+		accountID:           accountID,
+		normalizeObjectives: true,
 	}
 }
 
+// SetNormalizeObjectives sets whether legacy objectives such as CONVERSIONS are sent as
+// their outcome-based equivalents, which is the default. Turning it off sends objectives
+// as configured, for API versions that still accept the legacy names.
+func (c *CampaignCreator) SetNormalizeObjectives(normalize bool) {
+	c.normalizeObjectives = normalize
+}
+
+// doWithRetry sends a request, retrying it while Facebook rejects it with a rate limit
+func (c *CampaignCreator) doWithRetry(req *http.Request) (*http.Response, error) {
+	return auth.DoWithRetry(c.httpClient, req, c.auth.Retry, c.accountID)
+}
+
 // CreateFromConfig creates a full campaign structure from a configuration file
 func (c *CampaignCreator) CreateFromConfig(config *models.CampaignConfig) error {
+	_, err := c.CreateFromConfigWithID(config)
+	return err
+}
+
+// CreateFromConfigContext creates a full campaign structure from a configuration file
+func (c *CampaignCreator) CreateFromConfigContext(ctx context.Context, config *models.CampaignConfig) error {
+	_, err := c.CreateFromConfigWithIDContext(ctx, config)
+	return err
+}
+
+// CreateFromConfigWithID creates a full campaign structure and returns the campaign ID.
+// The ID is also returned when creating an ad set or ad fails, so callers can clean up.
+func (c *CampaignCreator) CreateFromConfigWithID(config *models.CampaignConfig) (string, error) {
+	return c.CreateFromConfigWithIDContext(context.Background(), config)
+}
+
+// CreateFromConfigWithIDContext creates a full campaign structure and returns the campaign ID.
+// Cancelling the context stops before the next object is created.
+func (c *CampaignCreator) CreateFromConfigWithIDContext(ctx context.Context, config *models.CampaignConfig) (string, error) {
+	// Make sure messaging destinations fit the campaign objective before creating anything
+	if err := ValidateMessagingConfig(config); err != nil {
+		return "", err
+	}
+
+	// Creatives reference images by hash, so images are uploaded first
+	if err := c.UploadCreativeImagesContext(ctx, config); err != nil {
+		return "", err
+	}
+
 	// Create the campaign
-	campaignID, err := c.CreateCampaign(config)
+	campaignID, err := c.CreateCampaignContext(ctx, config)
 	if err != nil {
-		return fmt.Errorf("error creating campaign: %w", err)
+		return "", fmt.Errorf("error creating campaign: %w", err)
 	}
 
 	fmt.Printf("Campaign created with ID: %s\n", campaignID)
 	
 	// Store adSet IDs to link with ads later
 	adSetIDs := make([]string, 0, len(config.AdSets))
+	destinations := make([]string, 0, len(config.AdSets))
 	
 	// Create ad sets
 	for i, adSetConfig := range config.AdSets {
 		fmt.Printf("Creating ad set %d/%d: %s\n", i+1, len(config.AdSets), adSetConfig.Name)
-		adSetID, err := c.CreateAdSet(campaignID, &adSetConfig)
+		adSetID, err := c.CreateAdSetContext(ctx, campaignID, &adSetConfig)
 		if err != nil {
-			return fmt.Errorf("error creating ad set: %w", err)
+			return campaignID, fmt.Errorf("error creating ad set: %w", err)
 		}
 		
 		fmt.Printf("Ad set created with ID: %s\n", adSetID)
 		adSetIDs = append(adSetIDs, adSetID)
+		destinations = append(destinations, adSetConfig.DestinationType)
 	}
 	
-	// Create ads (link each ad to an ad set)
-	for i, adConfig := range config.Ads {
-		// Find the right ad set for this ad
-		adSetIndex := i % len(adSetIDs) // Simple distribution - cycle through ad sets
+	// Create ads: nested ads go to their own ad set, top-level ads cycle through the ad sets
+	total := len(config.AllAds())
+	created := 0
+	createAd := func(adSetIndex int, adConfig *models.AdConfig) error {
 		adSetID := adSetIDs[adSetIndex]
-		
-		fmt.Printf("Creating ad %d/%d: %s (in ad set: %s)\n", i+1, len(config.Ads), adConfig.Name, adSetID)
-		adID, err := c.CreateAd(adSetID, &adConfig)
+		created++
+
+		fmt.Printf("Creating ad %d/%d: %s (in ad set: %s)\n", created, total, adConfig.Name, adSetID)
+		adID, err := c.createAd(ctx, adSetID, adConfig, destinations[adSetIndex])
 		if err != nil {
 			return fmt.Errorf("error creating ad: %w", err)
 		}
-		
+
 		fmt.Printf("Ad created with ID: %s\n", adID)
+		return nil
+	}
+
+	for i := range config.AdSets {
+		for j := range config.AdSets[i].Ads {
+			if err := createAd(i, &config.AdSets[i].Ads[j]); err != nil {
+				return campaignID, err
+			}
+		}
+	}
+	for i := range config.Ads {
+		if err := createAd(i%len(adSetIDs), &config.Ads[i]); err != nil {
+			return campaignID, err
+		}
 	}
 	
-	return nil
+	return campaignID, nil
 }
 
 // CreateCampaign creates a new campaign
 func (c *CampaignCreator) CreateCampaign(config *models.CampaignConfig) (string, error) {
+	return c.CreateCampaignContext(context.Background(), config)
+}
+
+// CreateCampaignContext creates a new campaign
+func (c *CampaignCreator) CreateCampaignContext(ctx context.Context, config *models.CampaignConfig) (string, error) {
+	params := campaignParams(config, c.normalizeObjectives)
+
+	// Create the endpoint
+	endpoint := fmt.Sprintf("act_%s/campaigns", c.accountID)
+	
+	// Make the API request
+	return c.createOwned(ctx, endpoint, params)
+}
+
+// campaignParams builds the request parameters for creating a campaign. With normalize,
+// legacy objectives are translated to their outcome-based equivalents.
+func campaignParams(config *models.CampaignConfig, normalize bool) url.Values {
 	params := url.Values{}
 	
+	objective := config.Objective
+	if normalize {
+		objective = models.NormalizeObjective(objective)
+	}
+	
 	// Required parameters
 	params.Set("name", config.Name)
-	params.Set("objective", config.Objective)
+	params.Set("objective", objective)
 	params.Set("status", getStatusOrDefault(config.Status, "PAUSED")) // Default to PAUSED for safety
 	params.Set("buying_type", config.BuyingType)
 	params.Set("special_ad_categories", "[]") // Default to empty list
 	
 	// Budget (convert to cents as required by the API)
 	if config.DailyBudget > 0 {
-		params.Set("daily_budget", fmt.Sprintf("%d", int64(config.DailyBudget*100)))
+		params.Set("daily_budget", strconv.FormatInt(models.DollarsToCents(config.DailyBudget), 10))
 	}
 	
 	if config.LifetimeBudget > 0 {
-		params.Set("lifetime_budget", fmt.Sprintf("%d", int64(config.LifetimeBudget*100)))
+		params.Set("lifetime_budget", strconv.FormatInt(models.DollarsToCents(config.LifetimeBudget), 10))
 	}
 	
 	// Optional parameters
@@ -110,15 +203,30 @@ func (c *CampaignCreator) CreateCampaign(config *models.CampaignConfig) (string,
 		params.Set("end_time", config.EndTime)
 	}
 	
+	return params
+}
+
+// CreateAdSet creates a new ad set
+func (c *CampaignCreator) CreateAdSet(campaignID string, config *models.AdSetConfig) (string, error) {
+	return c.CreateAdSetContext(context.Background(), campaignID, config)
+}
+
+// CreateAdSetContext creates a new ad set
+func (c *CampaignCreator) CreateAdSetContext(ctx context.Context, campaignID string, config *models.AdSetConfig) (string, error) {
+	params, err := adSetParams(campaignID, config)
+	if err != nil {
+		return "", err
+	}
+
 	// Create the endpoint
-	endpoint := fmt.Sprintf("act_%s/campaigns", c.accountID)
+	endpoint := fmt.Sprintf("act_%s/adsets", c.accountID)
 	
 	// Make the API request
-	return c.createEntity(endpoint, params)
+	return c.createOwned(ctx, endpoint, params)
 }
 
-// CreateAdSet creates a new ad set
-func (c *CampaignCreator) CreateAdSet(campaignID string, config *models.AdSetConfig) (string, error) {
+// adSetParams builds the request parameters for creating an ad set
+func adSetParams(campaignID string, config *models.AdSetConfig) (url.Values, error) {
 	params := url.Values{}
 	
 	// Required parameters
@@ -130,14 +238,14 @@ func (c *CampaignCreator) CreateAdSet(campaignID string, config *models.AdSetCon
 	
 	// Bid amount (convert to cents as required by the API)
 	if config.BidAmount > 0 {
-		params.Set("bid_amount", fmt.Sprintf("%d", int64(config.BidAmount*100)))
+		params.Set("bid_amount", strconv.FormatInt(models.DollarsToCents(config.BidAmount), 10))
 	}
 	
 	// Targeting
 	if len(config.Targeting) > 0 {
 		targetingJSON, err := json.Marshal(config.Targeting)
 		if err != nil {
-			return "", fmt.Errorf("error marshaling targeting: %w", err)
+			return nil, fmt.Errorf("error marshaling targeting: %w", err)
 		}
 		params.Set("targeting", string(targetingJSON))
 	}
@@ -151,21 +259,46 @@ func (c *CampaignCreator) CreateAdSet(campaignID string, config *models.AdSetCon
 		params.Set("end_time", config.EndTime)
 	}
 	
-	// Create the endpoint
-	endpoint := fmt.Sprintf("act_%s/adsets", c.accountID)
+	// Click-to-message ad sets deliver conversations to the given app
+	if config.DestinationType != "" {
+		params.Set("destination_type", strings.ToUpper(config.DestinationType))
+	}
 	
-	// Make the API request
-	return c.createEntity(endpoint, params)
+	return params, nil
 }
 
 // CreateAd creates a new ad
 func (c *CampaignCreator) CreateAd(adSetID string, config *models.AdConfig) (string, error) {
-	// First, create the creative
-	creativeID, err := c.CreateCreative(config.Creative)
-	if err != nil {
-		return "", fmt.Errorf("error creating creative: %w", err)
+	return c.CreateAdContext(context.Background(), adSetID, config)
+}
+
+// CreateAdContext creates a new ad
+func (c *CampaignCreator) CreateAdContext(ctx context.Context, adSetID string, config *models.AdConfig) (string, error) {
+	return c.createAd(ctx, adSetID, config, "")
+}
+
+// createAd creates a new ad whose creative targets the ad set's destination type. An ad
+// with a creative_id shows that creative instead of a new one.
+func (c *CampaignCreator) createAd(ctx context.Context, adSetID string, config *models.AdConfig, destinationType string) (string, error) {
+	// First, create the creative unless an existing one is reused
+	creativeID := config.Creative.CreativeID
+	if creativeID == "" {
+		var err error
+		creativeID, err = c.createCreative(ctx, config.Creative, destinationType)
+		if err != nil {
+			return "", fmt.Errorf("error creating creative: %w", err)
+		}
 	}
 	
+	// Create the endpoint
+	endpoint := fmt.Sprintf("act_%s/ads", c.accountID)
+	
+	// Make the API request
+	return c.createOwned(ctx, endpoint, adParams(adSetID, creativeID, config))
+}
+
+// adParams builds the request parameters for creating an ad that shows the given creative
+func adParams(adSetID, creativeID string, config *models.AdConfig) url.Values {
 	params := url.Values{}
 	
 	// Required parameters
@@ -174,20 +307,40 @@ func (c *CampaignCreator) CreateAd(adSetID string, config *models.AdConfig) (str
 	params.Set("status", getStatusOrDefault(config.Status, "PAUSED")) // Default to PAUSED for safety
 	params.Set("creative", fmt.Sprintf("{\"creative_id\":\"%s\"}", creativeID))
 	
+	return params
+}
+
+// CreateCreative creates a new creative
+func (c *CampaignCreator) CreateCreative(config models.CreativeConfig) (string, error) {
+	return c.CreateCreativeContext(context.Background(), config)
+}
+
+// CreateCreativeContext creates a new creative
+func (c *CampaignCreator) CreateCreativeContext(ctx context.Context, config models.CreativeConfig) (string, error) {
+	return c.createCreative(ctx, config, "")
+}
+
+// createCreative creates a new creative for the given ad set destination type
+func (c *CampaignCreator) createCreative(ctx context.Context, config models.CreativeConfig, destinationType string) (string, error) {
+	params, err := creativeParams(config, destinationType)
+	if err != nil {
+		return "", err
+	}
+	
 	// Create the endpoint
-	endpoint := fmt.Sprintf("act_%s/ads", c.accountID)
+	endpoint := fmt.Sprintf("act_%s/adcreatives", c.accountID)
 	
 	// Make the API request
-	return c.createEntity(endpoint, params)
+	return c.createEntity(ctx, endpoint, params)
 }
 
-// CreateCreative creates a new creative
-func (c *CampaignCreator) CreateCreative(config models.CreativeConfig) (string, error) {
+// creativeParams builds the request parameters for creating an ad creative
+func creativeParams(config models.CreativeConfig, destinationType string) (url.Values, error) {
 	params := url.Values{}
 	
 	// Check for required page_id
 	if config.PageID == "" {
-		return "", fmt.Errorf("page_id is required for creating ad creatives")
+		return nil, fmt.Errorf("page_id is required for creating ad creatives")
 	}
 	
 	// Create object_story_spec with page_id
@@ -196,15 +349,66 @@ func (c *CampaignCreator) CreateCreative(config models.CreativeConfig) (string,
 	// Add page_id to the story spec
 	objectStorySpec["page_id"] = config.PageID
 	
+	// Video ads carry their content in video_data instead of link_data
+	if config.VideoID != "" && len(config.Cards) > 0 {
+		return nil, fmt.Errorf("a creative can have a video_id or carousel cards, not both")
+	}
+	if config.VideoID != "" {
+		videoData, err := videoStoryData(config, destinationType)
+		if err != nil {
+			return nil, err
+		}
+		objectStorySpec["video_data"] = videoData
+	} else {
+		linkData, err := linkStoryData(config, destinationType)
+		if err != nil {
+			return nil, err
+		}
+		objectStorySpec["link_data"] = linkData
+	}
+	
+	// Marshal the object_story_spec to JSON
+	objectJSON, err := json.Marshal(objectStorySpec)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling creative object: %w", err)
+	}
+	
+	params.Set("object_story_spec", string(objectJSON))
+	
+	return params, nil
+}
+
+// linkStoryData builds the link_data of a link ad's object story spec
+func linkStoryData(config models.CreativeConfig, destinationType string) (map[string]interface{}, error) {
 	// Create link_data object
 	linkData := make(map[string]interface{})
 	
+	messaging := IsMessagingDestination(destinationType)
+	
+	// Click-to-message ads fall back to the destination's default link
+	linkURL := config.LinkURL
+	if linkURL == "" && messaging {
+		linkURL = messagingDefaults[strings.ToUpper(destinationType)].link
+	}
+	
+	// Carousels show each card in child_attachments; the "see more" link defaults to the first card's
+	if len(config.Cards) > 0 {
+		attachments, err := carouselAttachments(config.Cards)
+		if err != nil {
+			return nil, err
+		}
+		linkData["child_attachments"] = attachments
+		if linkURL == "" {
+			linkURL = config.Cards[0].LinkURL
+		}
+	}
+	
 	// Validate that LinkURL is not empty, as it's required by the Facebook API
-	if config.LinkURL == "" {
-		return "", fmt.Errorf("link_url is required for ad creatives and cannot be empty")
+	if linkURL == "" {
+		return nil, fmt.Errorf("link_url is required for ad creatives and cannot be empty")
 	}
 	
-	linkData["link"] = config.LinkURL
+	linkData["link"] = linkURL
 	
 	// Note: As per the API error, title is not supported directly in link_data
 	// Instead, we'll use name for the title/name field
@@ -224,50 +428,37 @@ func (c *CampaignCreator) CreateCreative(config models.CreativeConfig) (string,
 		linkData["message"] = config.Body
 	}
 	
-	// NOTE: ImageURL is no longer supported in link_data of object_story_spec per Facebook API
-	// Images should be uploaded separately or referenced by ID
-	// This code is commented out to prevent API errors
-	/*
-	if config.ImageURL != "" {
-		linkData["image_url"] = config.ImageURL
+	// link_data doesn't accept image_url; images are uploaded first and referenced by hash
+	if config.ImageHash != "" {
+		linkData["image_hash"] = config.ImageHash
 	}
-	*/
 	
-	if config.CallToAction != "" {
+	if messaging {
+		linkData["call_to_action"] = messagingCallToAction(config, destinationType)
+	} else if config.CallToAction != "" {
 		callToAction := map[string]string{
 			"type": config.CallToAction,
 		}
 		linkData["call_to_action"] = callToAction
 	}
 	
-	// Add link_data to story spec
-	objectStorySpec["link_data"] = linkData
-	
-	// Marshal the object_story_spec to JSON
-	objectJSON, err := json.Marshal(objectStorySpec)
-	if err != nil {
-		return "", fmt.Errorf("error marshaling creative object: %w", err)
+	if messaging && config.PageWelcomeMessage != "" {
+		linkData["page_welcome_message"] = config.PageWelcomeMessage
 	}
 	
-	params.Set("object_story_spec", string(objectJSON))
-	
-	// Create the endpoint
-	endpoint := fmt.Sprintf("act_%s/adcreatives", c.accountID)
-	
-	// Make the API request
-	return c.createEntity(endpoint, params)
+	return linkData, nil
 }
 
 // createEntity is a helper function to create an entity and return its ID
-func (c *CampaignCreator) createEntity(endpoint string, params url.Values) (string, error) {
+func (c *CampaignCreator) createEntity(ctx context.Context, endpoint string, params url.Values) (string, error) {
 	// Add access token to parameters
 	params.Set("access_token", c.auth.AccessToken)
 	
 	// Build the request URL
-	baseURL := fmt.Sprintf("https://graph.facebook.com/%s/%s", c.auth.APIVersion, endpoint)
+	baseURL := fmt.Sprintf("%s/%s", c.auth.GetAPIBaseURL(), endpoint)
 	
 	// Create the POST request
-	req, err := http.NewRequest("POST", baseURL, strings.NewReader(params.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL, strings.NewReader(params.Encode()))
 	if err != nil {
 		return "", fmt.Errorf("error creating request: %w", err)
 	}
@@ -275,8 +466,8 @@ func (c *CampaignCreator) createEntity(endpoint string, params url.Values) (stri
 	// Set the content type
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	
-	// Send the request
-	resp, err := c.httpClient.Do(req)
+	// Send the request; rate limited requests are retried and account throttles returned as errors
+	resp, err := c.doWithRetry(req)
 	if err != nil {
 		return "", fmt.Errorf("error sending request: %w", err)
 	}
@@ -290,18 +481,14 @@ func (c *CampaignCreator) createEntity(endpoint string, params url.Values) (stri
 	
 	// Check for errors
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+		return "", fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
 	}
 	
 	// Parse the response
 	var result struct {
-		ID      string `json:"id"`
-		Success bool   `json:"success"`
-		Error   struct {
-			Message string `json:"message"`
-			Type    string `json:"type"`
-			Code    int    `json:"code"`
-		} `json:"error"`
+		ID      string                 `json:"id"`
+		Success bool                   `json:"success"`
+		Error   *auth.FacebookAPIError `json:"error"`
 	}
 	
 	if err := json.Unmarshal(body, &result); err != nil {
@@ -309,9 +496,9 @@ func (c *CampaignCreator) createEntity(endpoint string, params url.Values) (stri
 	}
 	
 	// Check for API-level errors
-	if result.Error.Message != "" {
-		return "", fmt.Errorf("API error: %s (code: %d, type: %s)", 
-			result.Error.Message, result.Error.Code, result.Error.Type)
+	if result.Error != nil && result.Error.Message != "" {
+		result.Error.HTTPStatus = resp.StatusCode
+		return "", fmt.Errorf("API error: %w", result.Error)
 	}
 	
 	// Return the ID
@@ -324,17 +511,8 @@ func getStatusOrDefault(status, defaultStatus string) string {
 		return defaultStatus
 	}
 	
-	validStatuses := map[string]bool{
-		"ACTIVE":    true,
-		"PAUSED":    true,
-		"DELETED":   true,
-		"ARCHIVED":  true,
-		"SCHEDULED": true,
-	}
-	
-	upperStatus := strings.ToUpper(status)
-	if validStatuses[upperStatus] {
-		return upperStatus
+	if parsed, err := models.ParseCampaignStatus(status); err == nil {
+		return string(parsed)
 	}
 	
 	return defaultStatus
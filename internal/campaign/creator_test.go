@@ -0,0 +1,202 @@
+package campaign
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestCampaignParams_BudgetsInCents(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   models.CampaignConfig
+		field    string
+		expected string
+	}{
+		{name: "daily budget", config: models.CampaignConfig{DailyBudget: 19.99}, field: "daily_budget", expected: "1999"},
+		{name: "lifetime budget", config: models.CampaignConfig{LifetimeBudget: 1234.56}, field: "lifetime_budget", expected: "123456"},
+		{name: "no budget", config: models.CampaignConfig{}, field: "daily_budget", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := campaignParams(&tt.config, true)
+			if got := params.Get(tt.field); got != tt.expected {
+				t.Errorf("%s = %q, want %q", tt.field, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCampaignParams_NormalizesObjective(t *testing.T) {
+	config := &models.CampaignConfig{Objective: "CONVERSIONS"}
+
+	if got := campaignParams(config, true).Get("objective"); got != "OUTCOME_SALES" {
+		t.Errorf("objective = %q, want OUTCOME_SALES", got)
+	}
+	if got := campaignParams(config, false).Get("objective"); got != "CONVERSIONS" {
+		t.Errorf("objective = %q, want CONVERSIONS without normalization", got)
+	}
+}
+
+func TestAdSetParams_BidAmountInCents(t *testing.T) {
+	params, err := adSetParams("123", &models.AdSetConfig{Name: "Test Ad Set", BidAmount: 4.55})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 4.55 * 100 is 454.99999... in floating point
+	if got := params.Get("bid_amount"); got != "455" {
+		t.Errorf("bid_amount = %q, want %q", got, "455")
+	}
+}
+
+func TestCreateFromConfig_AdPlacement(t *testing.T) {
+	labels := &labelAPI{existing: "[]"}
+	adSets := 0
+	placed := make(map[string][]string) // Ad names by ad set ID
+
+	authClient := auth.NewFacebookAuth("app", "secret", "token", "v22.0")
+	authClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		path := strings.TrimPrefix(req.URL.Path, "/v22.0/")
+		if req.Method != http.MethodPost || (path != "act_123/adsets" && path != "act_123/ads") {
+			return labels.roundTrip(req)
+		}
+
+		data, _ := io.ReadAll(req.Body)
+		form, _ := url.ParseQuery(string(data))
+		id := "ad"
+		if path == "act_123/adsets" {
+			adSets++
+			id = fmt.Sprintf("set%d", adSets)
+		} else {
+			placed[form.Get("adset_id")] = append(placed[form.Get("adset_id")], form.Get("name"))
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"id":"` + id + `"}`)),
+		}, nil
+	})
+	creator := NewCampaignCreator(authClient, "123")
+
+	ad := func(name string) models.AdConfig {
+		return models.AdConfig{Name: name, Creative: models.CreativeConfig{Title: name, LinkURL: "https://example.com", PageID: "1"}}
+	}
+	config := &models.CampaignConfig{
+		Name:        "Spring",
+		Objective:   "OUTCOME_TRAFFIC",
+		DailyBudget: 50,
+		AdSets: []models.AdSetConfig{
+			{Name: "US", Ads: []models.AdConfig{ad("us-1"), ad("us-2")}},
+			{Name: "CA", Ads: []models.AdConfig{ad("ca-1")}},
+		},
+		// Top-level ads still cycle through the ad sets
+		Ads: []models.AdConfig{ad("flat-1"), ad("flat-2")},
+	}
+
+	if _, err := creator.CreateFromConfigWithID(config); err != nil {
+		t.Fatalf("CreateFromConfigWithID failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"set1": "us-1,us-2,flat-1",
+		"set2": "ca-1,flat-2",
+	}
+	for adSetID, names := range expected {
+		if got := strings.Join(placed[adSetID], ","); got != names {
+			t.Errorf("Ad set %s got ads %s, want %s", adSetID, got, names)
+		}
+	}
+}
+
+// serverTransport sends requests for the Graph API to a test server instead
+type serverTransport struct {
+	server *httptest.Server
+}
+
+// RoundTrip implements http.RoundTripper
+func (s serverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, _ := url.Parse(s.server.URL)
+	req.URL.Scheme, req.URL.Host = target.Scheme, target.Host
+	return s.server.Client().Transport.RoundTrip(req)
+}
+
+func TestCreateFromConfig_RequestSequence(t *testing.T) {
+	var requests []string
+	ids := map[string]string{"campaigns": "c1", "adsets": "s1", "adcreatives": "cr1", "ads": "a1"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Error parsing form: %v", err)
+		}
+
+		// Ownership labels are covered by the label tests
+		if strings.HasSuffix(r.URL.Path, "/adlabels") {
+			if r.Method == http.MethodGet {
+				fmt.Fprintf(w, `{"data":[{"id":"l1","name":%q}]}`, OwnershipLabel)
+				return
+			}
+			w.Write([]byte(`{"success":true}`))
+			return
+		}
+
+		edge := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		var fields []string
+		for _, key := range []string{"daily_budget", "campaign_id", "bid_amount", "targeting", "object_story_spec", "adset_id", "creative"} {
+			if value := r.PostForm.Get(key); value != "" {
+				fields = append(fields, key+"="+value)
+			}
+		}
+		requests = append(requests, r.Method+" "+edge+" "+strings.Join(fields, " "))
+		fmt.Fprintf(w, `{"id":%q}`, ids[edge])
+	}))
+	defer server.Close()
+
+	authClient := auth.NewFacebookAuth("app", "secret", "token", "v22.0")
+	authClient.Transport = serverTransport{server}
+	creator := NewCampaignCreator(authClient, "123")
+
+	config := &models.CampaignConfig{
+		Name:        "Spring",
+		Objective:   "OUTCOME_TRAFFIC",
+		DailyBudget: 19.99,
+		AdSets: []models.AdSetConfig{{
+			Name:      "US",
+			BidAmount: 4.55,
+			Targeting: map[string]interface{}{"age_min": 25},
+			Ads: []models.AdConfig{{
+				Name:     "Hero",
+				Creative: models.CreativeConfig{Title: "Sale", Body: "30% off", LinkURL: "https://example.com", PageID: "9"},
+			}, {
+				Name:     "Approved",
+				Creative: models.CreativeConfig{CreativeID: "777"},
+			}},
+		}},
+	}
+	if _, err := creator.CreateFromConfigWithID(config); err != nil {
+		t.Fatalf("CreateFromConfigWithID failed: %v", err)
+	}
+
+	expected := []string{
+		"POST campaigns daily_budget=1999",
+		`POST adsets campaign_id=c1 bid_amount=455 targeting={"age_min":25}`,
+		`POST adcreatives object_story_spec={"link_data":{"link":"https://example.com","message":"30% off","name":"Sale"},"page_id":"9"}`,
+		`POST ads adset_id=s1 creative={"creative_id":"cr1"}`,
+		`POST ads adset_id=s1 creative={"creative_id":"777"}`,
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("Expected %d requests, got %d:\n%s", len(expected), len(requests), strings.Join(requests, "\n"))
+	}
+	for i := range expected {
+		if requests[i] != expected[i] {
+			t.Errorf("Request %d = %s, want %s", i+1, requests[i], expected[i])
+		}
+	}
+}
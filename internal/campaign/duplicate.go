@@ -0,0 +1,231 @@
+package campaign
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// DuplicateOptions controls how a campaign is copied
+type DuplicateOptions struct {
+	Name         string  // Name of the copy; empty names it "Copy of <original name>"
+	Status       string  // Status of the copy and its ad sets and ads
+	BudgetFactor float64 // Multiplier for the budgets; 0 keeps them
+
+	// CrossAccount copies the campaign into another ad account. Image hashes only exist
+	// in the account they were uploaded to, so images are uploaded again from their URL.
+	CrossAccount bool
+
+	// ReuseCreatives shows the original creatives in the copied ads by creative_id instead
+	// of creating new ones, so the ads keep their likes and comments. Creatives belong to
+	// their account, so this only works within the source account.
+	ReuseCreatives bool
+}
+
+// ConfigFromDetails converts campaign details to a configuration. Budgets and bid amounts
+// come from the API in cents and are converted to the dollars configurations use.
+func ConfigFromDetails(details *models.CampaignDetails) *models.CampaignConfig {
+	return configFromDetails(details, false)
+}
+
+// configFromDetails converts campaign details to a configuration. With reuseCreatives the
+// ads reference their creatives by ID instead of describing them.
+func configFromDetails(details *models.CampaignDetails, reuseCreatives bool) *models.CampaignConfig {
+	config := &models.CampaignConfig{
+		Name:                details.Name,
+		Status:              details.Status,
+		Objective:           details.ObjectiveType,
+		BuyingType:          details.BuyingType,
+		SpecialAdCategories: details.SpecialAdCategories,
+		BidStrategy:         details.BidStrategy,
+		DailyBudget:         models.CentsToDollars(details.DailyBudget),
+		LifetimeBudget:      models.CentsToDollars(details.LifetimeBudget),
+		AdSets:              []models.AdSetConfig{},
+		Ads:                 []models.AdConfig{},
+	}
+
+	// Add start/end times if available
+	if !details.StartTime.IsZero() {
+		config.StartTime = details.StartTime.Format(time.RFC3339)
+	}
+
+	if !details.StopTime.IsZero() {
+		config.EndTime = details.StopTime.Format(time.RFC3339)
+	}
+
+	// Process AdSets
+	for _, adset := range details.AdSets {
+		adsetConfig := models.AdSetConfig{
+			Name:             adset.Name,
+			Status:           adset.Status,
+			Targeting:        adset.Targeting,
+			OptimizationGoal: adset.OptimizationGoal,
+			BillingEvent:     adset.BillingEvent,
+			BidAmount:        models.CentsToDollars(adset.BidAmount),
+			DestinationType:  adset.DestinationType,
+		}
+
+		// Add start/end times if available
+		if !adset.StartTime.IsZero() {
+			adsetConfig.StartTime = adset.StartTime.Format(time.RFC3339)
+		}
+
+		if !adset.EndTime.IsZero() {
+			adsetConfig.EndTime = adset.EndTime.Format(time.RFC3339)
+		}
+
+		config.AdSets = append(config.AdSets, adsetConfig)
+	}
+
+	// Process Ads, keeping each ad in the ad set it belongs to
+	adSetIndex := make(map[string]int, len(details.AdSets))
+	for i, adset := range details.AdSets {
+		adSetIndex[adset.ID] = i
+	}
+	for _, ad := range details.Ads {
+		adConfig := models.AdConfig{
+			Name:   ad.Name,
+			Status: ad.Status,
+			Creative: models.CreativeConfig{
+				Name:               ad.Creative.Title, // Use name field for title value per API requirements
+				Body:               ad.Creative.Body,
+				ImageURL:           ad.Creative.ImageURL,
+				ImageHash:          ad.Creative.ImageHash,
+				VideoID:            ad.Creative.VideoID,
+				Cards:              ad.Creative.Cards,
+				LinkURL:            ad.Creative.LinkURL,
+				CallToAction:       ad.Creative.CallToActionType,
+				PageID:             ad.Creative.PageID,
+				WhatsAppNumber:     ad.Creative.WhatsAppNumber,
+				PageWelcomeMessage: ad.Creative.PageWelcomeMessage,
+			},
+		}
+		if reuseCreatives && ad.Creative.ID != "" {
+			adConfig.Creative = models.CreativeConfig{CreativeID: ad.Creative.ID}
+		}
+
+		// Ads of an unknown ad set stay top-level and are spread across the ad sets
+		if i, ok := adSetIndex[ad.AdSetID]; ok {
+			config.AdSets[i].Ads = append(config.AdSets[i].Ads, adConfig)
+			continue
+		}
+		config.Ads = append(config.Ads, adConfig)
+	}
+
+	return config
+}
+
+// DuplicateConfig converts campaign details into a configuration for a copy of the campaign.
+// The copy is not validated; callers check it with models.ValidateCampaignConfig.
+func DuplicateConfig(details *models.CampaignDetails, options DuplicateOptions) *models.CampaignConfig {
+	// If no custom name provided, create a default name
+	campaignName := options.Name
+	if campaignName == "" {
+		campaignName = "Copy of " + details.Name
+	}
+	status := options.Status
+	budgetFactor := options.BudgetFactor
+	if budgetFactor == 0 {
+		budgetFactor = 1.0
+	}
+
+	// Convert to a campaign configuration
+	campaignConfig := configFromDetails(details, options.ReuseCreatives)
+
+	// For duplication, we need to ensure we're not carrying over any IDs
+	// The Create function will assign new IDs
+
+	// Update the campaign config with the new parameters
+	campaignConfig.Name = campaignName
+	campaignConfig.Status = status
+
+	// Apply budget factor to the budgets in dollars
+	if budgetFactor != 1.0 {
+		if campaignConfig.DailyBudget > 0 {
+			campaignConfig.DailyBudget = campaignConfig.DailyBudget * budgetFactor
+		}
+		if campaignConfig.LifetimeBudget > 0 {
+			campaignConfig.LifetimeBudget = campaignConfig.LifetimeBudget * budgetFactor
+		}
+	}
+
+	// Clear any ID fields from the AdSets and Ads to ensure new ones are created
+	for i := range campaignConfig.AdSets {
+		// Update ad set names to indicate they're copies
+		if !strings.HasPrefix(campaignConfig.AdSets[i].Name, "Copy of ") {
+			campaignConfig.AdSets[i].Name = "Copy of " + campaignConfig.AdSets[i].Name
+		}
+		// Set the status to match the campaign
+		campaignConfig.AdSets[i].Status = status
+	}
+
+	for _, ad := range campaignConfig.AllAds() {
+		// Update ad names to indicate they're copies
+		if !strings.HasPrefix(ad.Name, "Copy of ") {
+			ad.Name = "Copy of " + ad.Name
+		}
+		// Set the status to match the campaign
+		ad.Status = status
+
+		// A reused creative keeps its images and link
+		if ad.Creative.CreativeID != "" {
+			continue
+		}
+
+		// The copy reuses the original image by hash; an image known only by URL is
+		// downloaded and uploaded again when the copy is created. Hashes belong to the
+		// account, so a copy into another account uploads every image it has a URL for.
+		ad.Creative.ImageHash, ad.Creative.ImageURL = copyImage(ad.Creative.ImageHash, ad.Creative.ImageURL, options.CrossAccount)
+		ad.Creative.Cards = append([]models.CreativeCard(nil), ad.Creative.Cards...)
+		for i := range ad.Creative.Cards {
+			card := &ad.Creative.Cards[i]
+			card.ImageHash, card.ImageURL = copyImage(card.ImageHash, card.ImageURL, options.CrossAccount)
+		}
+
+		// A missing link is kept as it is: validating the copy reports it at creative.link_url
+		// when the ad needs one, instead of the copy pointing somewhere the original didn't
+	}
+
+	return campaignConfig
+}
+
+// copyImage returns the image hash and URL a copy of a creative uses
+func copyImage(hash, imageURL string, crossAccount bool) (string, string) {
+	if crossAccount && imageURL != "" {
+		return "", imageURL
+	}
+	if hash != "" {
+		return hash, ""
+	}
+	return hash, imageURL
+}
+
+// CheckCrossAccountImages reports the images of a copy into another ad account that are
+// only known by hash. Image hashes belong to the account they were uploaded to, so such
+// an image needs an image_url to be uploaded again.
+func CheckCrossAccountImages(config *models.CampaignConfig) models.ValidationErrors {
+	var problems models.ValidationErrors
+
+	check := func(path string, ad models.AdConfig) {
+		if ad.Creative.ImageHash != "" && ad.Creative.ImageURL == "" {
+			problems.Add(path+".creative.image_hash", "image %s exists only in the source account; set image_url so it can be uploaded", ad.Creative.ImageHash)
+		}
+		for k, card := range ad.Creative.Cards {
+			if card.ImageHash != "" && card.ImageURL == "" {
+				problems.Add(fmt.Sprintf("%s.creative.cards[%d].image_hash", path, k), "image %s exists only in the source account; set image_url so it can be uploaded", card.ImageHash)
+			}
+		}
+	}
+	for i, adSet := range config.AdSets {
+		for j, ad := range adSet.Ads {
+			check(fmt.Sprintf("adsets[%d].ads[%d]", i, j), ad)
+		}
+	}
+	for i, ad := range config.Ads {
+		check(fmt.Sprintf("ads[%d]", i), ad)
+	}
+
+	return problems
+}
@@ -0,0 +1,243 @@
+package campaign
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestConfigFromDetails_BudgetsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		details *models.CampaignDetails
+	}{
+		{
+			name: "daily budget",
+			details: &models.CampaignDetails{
+				ID:          "101",
+				DailyBudget: 1999,
+				AdSets:      []models.AdSetDetails{{ID: "111", BidAmount: 455}},
+			},
+		},
+		{
+			name: "lifetime budget",
+			details: &models.CampaignDetails{
+				ID:             "102",
+				LifetimeBudget: 123456,
+				AdSets:         []models.AdSetDetails{{ID: "112", BidAmount: 1}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Export writes the configuration to a file that create reads back
+			data, err := json.Marshal(ConfigFromDetails(tt.details))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var config models.CampaignConfig
+			if err := json.Unmarshal(data, &config); err != nil {
+				t.Fatal(err)
+			}
+
+			// Create sends the configured dollars back as cents
+			if cents := models.DollarsToCents(config.DailyBudget); cents != int64(tt.details.DailyBudget) {
+				t.Errorf("Expected a daily budget of %.0f cents after the round trip, got %d", tt.details.DailyBudget, cents)
+			}
+			if cents := models.DollarsToCents(config.LifetimeBudget); cents != int64(tt.details.LifetimeBudget) {
+				t.Errorf("Expected a lifetime budget of %.0f cents after the round trip, got %d", tt.details.LifetimeBudget, cents)
+			}
+			if cents := models.DollarsToCents(config.AdSets[0].BidAmount); cents != int64(tt.details.AdSets[0].BidAmount) {
+				t.Errorf("Expected a bid amount of %.0f cents after the round trip, got %d", tt.details.AdSets[0].BidAmount, cents)
+			}
+		})
+	}
+}
+
+func TestConfigFromDetails_NestsAdsInTheirAdSet(t *testing.T) {
+	details := &models.CampaignDetails{
+		ID: "101",
+		AdSets: []models.AdSetDetails{
+			{ID: "111", Name: "US"},
+			{ID: "112", Name: "CA"},
+		},
+		Ads: []models.AdDetails{
+			{ID: "1", Name: "us-hero", AdSetID: "111"},
+			{ID: "2", Name: "ca-hero", AdSetID: "112"},
+			{ID: "3", Name: "us-carousel", AdSetID: "111"},
+			{ID: "4", Name: "orphan", AdSetID: "999"},
+		},
+	}
+
+	config := ConfigFromDetails(details)
+
+	var names []string
+	for _, adSet := range config.AdSets {
+		var ads []string
+		for _, ad := range adSet.Ads {
+			ads = append(ads, ad.Name)
+		}
+		names = append(names, adSet.Name+"="+strings.Join(ads, ","))
+	}
+	if got := strings.Join(names, " "); got != "US=us-hero,us-carousel CA=ca-hero" {
+		t.Errorf("Expected each ad in its own ad set, got %s", got)
+	}
+	if len(config.Ads) != 1 || config.Ads[0].Name != "orphan" {
+		t.Errorf("Expected the ad of an unknown ad set to stay top-level, got %+v", config.Ads)
+	}
+
+	// Duplicates rename the nested ads too
+	duplicate := DuplicateConfig(details, DuplicateOptions{Status: "PAUSED"})
+	if ads := duplicate.AllAds(); len(ads) != 4 || ads[0].Name != "Copy of us-hero" || ads[0].Status != "PAUSED" {
+		t.Errorf("Expected every ad to be copied, got %d ads starting with %+v", len(ads), ads[0])
+	}
+}
+
+func TestDuplicateConfig_BudgetsInDollars(t *testing.T) {
+	details := &models.CampaignDetails{
+		ID:          "101",
+		Name:        "Spring",
+		DailyBudget: 5000,
+		AdSets:      []models.AdSetDetails{{ID: "111", Name: "Broad", BidAmount: 250}},
+	}
+
+	config := DuplicateConfig(details, DuplicateOptions{Status: "PAUSED", BudgetFactor: 1.5})
+	if config.DailyBudget != 75 {
+		t.Errorf("Expected $75.00 after the budget factor, got $%.2f", config.DailyBudget)
+	}
+	if config.AdSets[0].BidAmount != 2.5 {
+		t.Errorf("Expected a $2.50 bid amount, got $%.2f", config.AdSets[0].BidAmount)
+	}
+}
+
+func TestDuplicateConfig_MissingLink(t *testing.T) {
+	details := &models.CampaignDetails{
+		ID:            "101",
+		Name:          "Spring",
+		ObjectiveType: "OUTCOME_TRAFFIC",
+		BuyingType:    "AUCTION",
+		DailyBudget:   5000,
+		AdSets: []models.AdSetDetails{{
+			ID:               "111",
+			Name:             "Broad",
+			OptimizationGoal: "LINK_CLICKS",
+			BillingEvent:     "IMPRESSIONS",
+			Targeting:        map[string]interface{}{"geo_locations": map[string]interface{}{"countries": []interface{}{"US"}}},
+		}},
+		Ads: []models.AdDetails{{ID: "1", Name: "hero", AdSetID: "111", Creative: models.CreativeDetails{Title: "Sale", PageID: "123"}}},
+	}
+
+	// The copy keeps the missing link, and validation reports it
+	config := DuplicateConfig(details, DuplicateOptions{Status: "PAUSED"})
+	if link := config.AllAds()[0].Creative.LinkURL; link != "" {
+		t.Errorf("Expected no link to be made up, got %q", link)
+	}
+	problems := models.ValidateCampaignConfig(config)
+	if err := problems.Err(); err == nil || !strings.Contains(err.Error(), "adsets[0].ads[0].creative.link_url") {
+		t.Errorf("Expected a validation problem for the missing link, got %v", err)
+	}
+}
+
+func TestDuplicateConfig_CrossAccountImages(t *testing.T) {
+	details := &models.CampaignDetails{
+		ID:     "101",
+		Name:   "Spring",
+		AdSets: []models.AdSetDetails{{ID: "111", Name: "Broad"}},
+		Ads: []models.AdDetails{
+			{ID: "1", Name: "hero", AdSetID: "111", Creative: models.CreativeDetails{
+				ImageHash: "abc123",
+				ImageURL:  "https://example.com/hero.jpg",
+				LinkURL:   "https://example.com",
+			}},
+			{ID: "2", Name: "carousel", AdSetID: "111", Creative: models.CreativeDetails{
+				LinkURL: "https://example.com",
+				Cards: []models.CreativeCard{
+					{ImageHash: "card1", ImageURL: "https://example.com/1.jpg"},
+					{ImageHash: "card2"},
+				},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		crossAccount bool
+		heroHash     string
+		heroURL      string
+		cardHash     string
+		cardURL      string
+	}{
+		{"same account reuses hashes", false, "abc123", "", "card1", ""},
+		{"other account uploads by URL", true, "", "https://example.com/hero.jpg", "", "https://example.com/1.jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DuplicateConfig(details, DuplicateOptions{Status: "PAUSED", CrossAccount: tt.crossAccount})
+			ads := config.AllAds()
+			if len(ads) != 2 {
+				t.Fatalf("Expected 2 ads, got %d", len(ads))
+			}
+
+			hero := ads[0].Creative
+			if hero.ImageHash != tt.heroHash || hero.ImageURL != tt.heroURL {
+				t.Errorf("Expected hash %q and URL %q, got %q and %q", tt.heroHash, tt.heroURL, hero.ImageHash, hero.ImageURL)
+			}
+			card := ads[1].Creative.Cards[0]
+			if card.ImageHash != tt.cardHash || card.ImageURL != tt.cardURL {
+				t.Errorf("Expected card hash %q and URL %q, got %q and %q", tt.cardHash, tt.cardURL, card.ImageHash, card.ImageURL)
+			}
+			// A card with only a hash keeps it; the account check reports it
+			if ads[1].Creative.Cards[1].ImageHash != "card2" {
+				t.Errorf("Expected the hash-only card to keep its hash, got %+v", ads[1].Creative.Cards[1])
+			}
+		})
+	}
+
+	// The details are left untouched for later copies
+	if details.Ads[1].Creative.Cards[0].ImageURL == "" || details.Ads[1].Creative.Cards[0].ImageHash == "" {
+		t.Errorf("Expected the original cards to be unchanged, got %+v", details.Ads[1].Creative.Cards[0])
+	}
+}
+
+func TestDuplicateConfig_ReuseCreatives(t *testing.T) {
+	details := &models.CampaignDetails{
+		ID:     "101",
+		Name:   "Spring",
+		AdSets: []models.AdSetDetails{{ID: "111", Name: "Broad"}},
+		Ads: []models.AdDetails{
+			{ID: "1", Name: "hero", AdSetID: "111", Creative: models.CreativeDetails{
+				ID:        "301",
+				Title:     "Spring Sale",
+				ImageHash: "abc123",
+				PageID:    "555",
+			}},
+		},
+	}
+
+	config := DuplicateConfig(details, DuplicateOptions{Status: "PAUSED", ReuseCreatives: true})
+	ads := config.AllAds()
+	if len(ads) != 1 {
+		t.Fatalf("Expected 1 ad, got %d", len(ads))
+	}
+	if !reflect.DeepEqual(ads[0].Creative, models.CreativeConfig{CreativeID: "301"}) {
+		t.Errorf("Expected the copy to reference the original creative only, got %+v", ads[0].Creative)
+	}
+	if ads[0].Name != "Copy of hero" || ads[0].Status != "PAUSED" {
+		t.Errorf("Expected the ad itself to be copied, got %+v", ads[0])
+	}
+	for _, problem := range models.ValidateCampaignConfig(config).Errors() {
+		if strings.Contains(problem.Path, "creative") {
+			t.Errorf("Expected the reused creative to be valid, got %v", problem)
+		}
+	}
+
+	recreated := DuplicateConfig(details, DuplicateOptions{Status: "PAUSED"}).AllAds()[0].Creative
+	if recreated.CreativeID != "" || recreated.Name != "Spring Sale" {
+		t.Errorf("Expected a new creative without ReuseCreatives, got %+v", recreated)
+	}
+}
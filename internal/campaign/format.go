@@ -0,0 +1,136 @@
+package campaign
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// ConfigFormat is the file format of a campaign configuration
+type ConfigFormat string
+
+// Configuration formats
+const (
+	ConfigFormatJSON ConfigFormat = "json"
+	ConfigFormatYAML ConfigFormat = "yaml"
+)
+
+// ParseConfigFormat parses a format name, defaulting to JSON
+func ParseConfigFormat(name string) (ConfigFormat, error) {
+	switch format := ConfigFormat(strings.ToLower(name)); format {
+	case "":
+		return ConfigFormatJSON, nil
+	case "yml":
+		return ConfigFormatYAML, nil
+	case ConfigFormatJSON, ConfigFormatYAML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown format %q (expected json or yaml)", name)
+	}
+}
+
+// ConfigFormatFromPath returns the format of a configuration file by its extension:
+// YAML for .yaml and .yml, JSON otherwise
+func ConfigFormatFromPath(path string) ConfigFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ConfigFormatYAML
+	default:
+		return ConfigFormatJSON
+	}
+}
+
+// Extension returns the file extension of the format, including the dot
+func (f ConfigFormat) Extension() string {
+	return "." + string(f)
+}
+
+// MarshalExportConfigFormat encodes a configuration for the profile in the format.
+// YAML is converted from the JSON encoding, so it has the same fields in the same
+// order, and ParseCampaignConfig reads it back into the same configuration.
+func MarshalExportConfigFormat(config *models.CampaignConfig, profile ExportProfile, format ConfigFormat) ([]byte, error) {
+	data, err := MarshalExportConfig(config, profile)
+	if err != nil || format != ConfigFormatYAML {
+		return data, err
+	}
+	return jsonToYAML(data)
+}
+
+// ParseCampaignConfig decodes a campaign configuration in the format. YAML is converted
+// to JSON first, so both formats are decoded by the same rules.
+func ParseCampaignConfig(data []byte, format ConfigFormat) (*models.CampaignConfig, error) {
+	if format == ConfigFormatYAML {
+		converted, err := yamlToJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		data = converted
+	}
+
+	var config models.CampaignConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing configuration: %w", err)
+	}
+	return &config, nil
+}
+
+// jsonToYAML converts a JSON document to block-style YAML. JSON is valid YAML, so the
+// document is parsed into nodes, which keeps the order of the keys, and written again
+// without the JSON flow style and quotes. Strings that would read as another type,
+// such as numeric IDs, stay quoted.
+func jsonToYAML(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("error converting configuration to YAML: %w", err)
+	}
+	setBlockStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, fmt.Errorf("error converting configuration to YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("error converting configuration to YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// yaml11Booleans are strings YAML 1.1 parsers read as booleans, e.g. the country code NO
+var yaml11Booleans = map[string]bool{"y": true, "n": true, "yes": true, "no": true, "on": true, "off": true}
+
+// setBlockStyle clears the styles of a node tree, except for empty lists and objects,
+// which are only expressible in flow style. Strings YAML 1.1 reads as booleans stay
+// quoted for tools that still speak it.
+func setBlockStyle(node *yaml.Node) {
+	if len(node.Content) > 0 || node.Kind == yaml.ScalarNode {
+		node.Style = 0
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && yaml11Booleans[strings.ToLower(node.Value)] {
+		node.Style = yaml.DoubleQuotedStyle
+	}
+	for _, child := range node.Content {
+		setBlockStyle(child)
+	}
+}
+
+// yamlToJSON converts a YAML document to JSON. Mapping keys must be strings.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("error parsing configuration: %w", err)
+	}
+
+	converted, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing configuration: %w", err)
+	}
+	return converted, nil
+}
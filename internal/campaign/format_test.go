@@ -0,0 +1,214 @@
+package campaign
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// formatFixture is an exported configuration with nested targeting, numeric-looking
+// strings and budgets with cents
+const formatFixture = `{
+  "name": "Spring: 2024",
+  "status": "PAUSED",
+  "objective": "OUTCOME_SALES",
+  "buying_type": "AUCTION",
+  "bid_strategy": "LOWEST_COST_WITH_BID_CAP",
+  "daily_budget": 19.99,
+  "start_time": "2024-03-01T00:00:00+0000",
+  "adsets": [
+    {
+      "name": "Broad",
+      "status": "ACTIVE",
+      "targeting": {
+        "age_max": 45,
+        "age_min": 18,
+        "custom_audiences": [
+          {
+            "id": "23850000000001",
+            "name": "Buyers"
+          }
+        ],
+        "excluded_custom_audiences": [],
+        "flexible_spec": [
+          {
+            "interests": [
+              {
+                "id": "6003107902433",
+                "name": "Running"
+              }
+            ]
+          }
+        ],
+        "geo_locations": {
+          "countries": [
+            "US",
+            "NO"
+          ],
+          "location_types": [
+            "home"
+          ]
+        },
+        "targeting_automation": {
+          "advantage_audience": 0
+        }
+      },
+      "optimization_goal": "OFFSITE_CONVERSIONS",
+      "billing_event": "IMPRESSIONS",
+      "bid_amount": 2.5,
+      "ads": [
+        {
+          "name": "On",
+          "status": "PAUSED",
+          "creative": {
+            "title": "Yes",
+            "body": "true",
+            "image_hash": "0123456789",
+            "link_url": "https://example.com/?a=1\u0026b=2",
+            "call_to_action": "SHOP_NOW",
+            "page_id": "123456789"
+          }
+        }
+      ]
+    }
+  ],
+  "ads": null
+}`
+
+func TestParseConfigFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		expected    ConfigFormat
+		expectError bool
+	}{
+		{name: "", expected: ConfigFormatJSON},
+		{name: "json", expected: ConfigFormatJSON},
+		{name: "YAML", expected: ConfigFormatYAML},
+		{name: "yml", expected: ConfigFormatYAML},
+		{name: "toml", expectError: true},
+	}
+
+	for _, tt := range tests {
+		format, err := ParseConfigFormat(tt.name)
+		if tt.expectError {
+			if err == nil {
+				t.Errorf("Expected an error for %q", tt.name)
+			}
+			continue
+		}
+		if err != nil || format != tt.expected {
+			t.Errorf("ParseConfigFormat(%q) = %q, %v; expected %q", tt.name, format, err, tt.expected)
+		}
+	}
+
+	for path, expected := range map[string]ConfigFormat{
+		"spring.json":    ConfigFormatJSON,
+		"spring.yaml":    ConfigFormatYAML,
+		"dir/Spring.YML": ConfigFormatYAML,
+		"spring":         ConfigFormatJSON,
+	} {
+		if got := ConfigFormatFromPath(path); got != expected {
+			t.Errorf("ConfigFormatFromPath(%q) = %q, expected %q", path, got, expected)
+		}
+	}
+}
+
+func TestConfigFormat_RoundTrip(t *testing.T) {
+	config, err := ParseCampaignConfig([]byte(formatFixture), ConfigFormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, profile := range []ExportProfile{ExportProfileFull, ExportProfileSlim} {
+		t.Run(string(profile), func(t *testing.T) {
+			original, err := MarshalExportConfigFormat(config, profile, ConfigFormatJSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// JSON -> YAML -> CampaignConfig -> JSON
+			yamlData, err := MarshalExportConfigFormat(config, profile, ConfigFormatYAML)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := ParseCampaignConfig(yamlData, ConfigFormatYAML)
+			if err != nil {
+				t.Fatalf("Error parsing the YAML:\n%s\n%v", yamlData, err)
+			}
+			roundTripped, err := MarshalExportConfigFormat(parsed, profile, ConfigFormatJSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(original, roundTripped) {
+				t.Errorf("Configuration changed in the round trip through YAML:\n%s\n\nYAML:\n%s\n\nGot:\n%s", original, yamlData, roundTripped)
+			}
+		})
+	}
+}
+
+func TestMarshalExportConfigFormat_YAML(t *testing.T) {
+	config, err := ParseCampaignConfig([]byte(formatFixture), ConfigFormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := MarshalExportConfigFormat(config, ExportProfileFull, ConfigFormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	yamlText := string(data)
+
+	for _, expected := range []string{
+		"daily_budget: 19.99\n", // Numbers stay numbers
+		"age_min: 18\n",         // Targeting numbers too
+		`page_id: "123456789"`,  // Numeric IDs stay strings
+		`body: "true"`,          // So do strings that read as booleans
+		"- US\n",                // Lists are written in block style
+		`- "NO"`,                // Country codes YAML 1.1 reads as false stay quoted
+		"excluded_custom_audiences: []\n",
+	} {
+		if !strings.Contains(yamlText, expected) {
+			t.Errorf("Expected the YAML to contain %q:\n%s", expected, yamlText)
+		}
+	}
+	if strings.Index(yamlText, "name:") > strings.Index(yamlText, "adsets:") {
+		t.Errorf("Expected the fields in the order of the JSON export:\n%s", yamlText)
+	}
+}
+
+func TestParseCampaignConfig_YAML(t *testing.T) {
+	yamlText := `
+name: Hand written
+objective: OUTCOME_TRAFFIC
+daily_budget: 25
+adsets:
+  - name: Nordics
+    targeting:
+      age_min: 21
+      geo_locations:
+        countries: [NO, SE]
+    optimization_goal: LINK_CLICKS
+    billing_event: IMPRESSIONS
+`
+	config, err := ParseCampaignConfig([]byte(yamlText), ConfigFormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.DailyBudget != 25 {
+		t.Errorf("Expected a daily budget of 25, got %v", config.DailyBudget)
+	}
+	targeting := config.AdSets[0].Targeting
+	if age, ok := targeting["age_min"].(float64); !ok || age != 21 {
+		t.Errorf("Expected age_min 21 as a number like in JSON, got %#v", targeting["age_min"])
+	}
+	geo, _ := targeting["geo_locations"].(map[string]interface{})
+	countries, _ := geo["countries"].([]interface{})
+	if len(countries) != 2 || countries[0] != "NO" {
+		t.Errorf("Expected countries [NO SE], got %#v", geo["countries"])
+	}
+
+	if _, err := ParseCampaignConfig([]byte("name: [unclosed"), ConfigFormatYAML); err == nil {
+		t.Error("Expected an error for invalid YAML")
+	}
+}
@@ -0,0 +1,256 @@
+package campaign
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// maxImageSize is the largest image accepted for upload; Facebook rejects bigger files anyway
+const maxImageSize = 30 << 20
+
+// IsRemoteImage reports whether an image reference is an http(s) URL rather than a local file
+func IsRemoteImage(pathOrURL string) bool {
+	return strings.HasPrefix(pathOrURL, "http://") || strings.HasPrefix(pathOrURL, "https://")
+}
+
+// UploadImage uploads an image to the ad account's image library and returns its hash,
+// which creatives reference as image_hash
+func (c *CampaignCreator) UploadImage(pathOrURL string) (string, error) {
+	return c.UploadImageContext(context.Background(), pathOrURL)
+}
+
+// UploadImageContext uploads an image like UploadImage. Local files are sent as they are;
+// remote images are downloaded first, since act_<id>/adimages only accepts image bytes.
+// Each path or URL is uploaded once per creator.
+func (c *CampaignCreator) UploadImageContext(ctx context.Context, pathOrURL string) (string, error) {
+	c.imageMu.Lock()
+	defer c.imageMu.Unlock()
+
+	if hash, ok := c.imageHashes[pathOrURL]; ok {
+		return hash, nil
+	}
+
+	name, data, err := c.readImage(ctx, pathOrURL)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := c.postImage(ctx, name, data)
+	if err != nil {
+		return "", fmt.Errorf("error uploading image %s: %w", pathOrURL, err)
+	}
+
+	if c.imageHashes == nil {
+		c.imageHashes = make(map[string]string)
+	}
+	c.imageHashes[pathOrURL] = hash
+	return hash, nil
+}
+
+// UploadCreativeImages uploads the image of every ad creative and carousel card that has an
+// image path or URL but no image hash yet, and sets the hash on the creative or card
+func (c *CampaignCreator) UploadCreativeImages(config *models.CampaignConfig) error {
+	return c.UploadCreativeImagesContext(context.Background(), config)
+}
+
+// UploadCreativeImagesContext uploads creative images like UploadCreativeImages
+func (c *CampaignCreator) UploadCreativeImagesContext(ctx context.Context, config *models.CampaignConfig) error {
+	for _, ad := range config.AllAds() {
+		creative := &ad.Creative
+		if err := c.uploadMissingImage(ctx, creative.ImageURL, &creative.ImageHash); err != nil {
+			return err
+		}
+		for i := range creative.Cards {
+			card := &creative.Cards[i]
+			if err := c.uploadMissingImage(ctx, card.ImageURL, &card.ImageHash); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// uploadMissingImage uploads an image and sets its hash, unless there is no image or it already has one
+func (c *CampaignCreator) uploadMissingImage(ctx context.Context, pathOrURL string, hash *string) error {
+	if *hash != "" || pathOrURL == "" {
+		return nil
+	}
+
+	uploaded, err := c.UploadImageContext(ctx, pathOrURL)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Uploaded image %s (hash: %s)\n", pathOrURL, uploaded)
+	*hash = uploaded
+	return nil
+}
+
+// readImage returns the file name and content of a local or remote image
+func (c *CampaignCreator) readImage(ctx context.Context, pathOrURL string) (string, []byte, error) {
+	if !IsRemoteImage(pathOrURL) {
+		data, err := os.ReadFile(pathOrURL)
+		if err != nil {
+			return "", nil, fmt.Errorf("error reading image: %w", err)
+		}
+		return filepath.Base(pathOrURL), data, nil
+	}
+
+	imageURL, err := url.Parse(pathOrURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid image URL %s: %w", pathOrURL, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", pathOrURL, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("error creating request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("error downloading image %s: %w", pathOrURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("error downloading image %s: %s", pathOrURL, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return "", nil, fmt.Errorf("error downloading image %s: %w", pathOrURL, err)
+	}
+	if len(data) > maxImageSize {
+		return "", nil, fmt.Errorf("image %s is larger than %d MB", pathOrURL, maxImageSize>>20)
+	}
+
+	name := path.Base(imageURL.Path)
+	if name == "/" || name == "." {
+		name = "image"
+	}
+	return name, data, nil
+}
+
+// postImage sends image bytes to the account's image library as a multipart upload
+func (c *CampaignCreator) postImage(ctx context.Context, name string, data []byte) (string, error) {
+	respBody, err := c.postFile(ctx, c.httpClient, "adimages", bytesUpload("filename", name, data), nil)
+	if err != nil {
+		return "", err
+	}
+
+	// Images are keyed by the uploaded file name
+	var result struct {
+		Images map[string]struct {
+			Hash string `json:"hash"`
+		} `json:"images"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("error parsing response: %w - %s", err, string(respBody))
+	}
+	for _, image := range result.Images {
+		if image.Hash != "" {
+			return image.Hash, nil
+		}
+	}
+	return "", fmt.Errorf("no image hash in the upload response: %s", string(respBody))
+}
+
+// uploadFile is the file of a multipart upload. open is called for every attempt, so a
+// retried upload reads the file again rather than keeping it in memory.
+type uploadFile struct {
+	field string
+	name  string
+	size  int64
+	open  func() (io.ReadCloser, error)
+}
+
+// bytesUpload returns an upload of data held in memory
+func bytesUpload(field, name string, data []byte) *uploadFile {
+	return &uploadFile{field: field, name: name, size: int64(len(data)), open: func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}}
+}
+
+// postFile posts a multipart form to an edge of the ad account with client and returns the
+// response body. The file, if any, is streamed between the form fields and the closing
+// boundary, so the request has a known length without the file being read into memory.
+func (c *CampaignCreator) postFile(ctx context.Context, client *http.Client, edge string, file *uploadFile, fields url.Values) ([]byte, error) {
+	var head bytes.Buffer
+	writer := multipart.NewWriter(&head)
+	for key, values := range fields {
+		for _, value := range values {
+			if err := writer.WriteField(key, value); err != nil {
+				return nil, fmt.Errorf("error building upload: %w", err)
+			}
+		}
+	}
+	if file != nil {
+		if _, err := writer.CreateFormFile(file.field, file.name); err != nil {
+			return nil, fmt.Errorf("error building upload: %w", err)
+		}
+	}
+	prefixLen := head.Len()
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error building upload: %w", err)
+	}
+	prefix, suffix := head.Bytes()[:prefixLen], head.Bytes()[prefixLen:]
+
+	newBody := func() (io.ReadCloser, error) {
+		if file == nil {
+			return io.NopCloser(bytes.NewReader(head.Bytes())), nil
+		}
+		content, err := file.open()
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", file.name, err)
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(prefix), content, bytes.NewReader(suffix)), content}, nil
+	}
+	body, err := newBody()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/act_%s/%s", c.auth.GetAPIBaseURL(), c.accountID, edge)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, body)
+	if err != nil {
+		body.Close()
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.GetBody = newBody
+	req.ContentLength = int64(head.Len())
+	if file != nil {
+		req.ContentLength += file.size
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	c.auth.AuthenticateRequest(req)
+
+	resp, err := auth.DoWithRetry(client, req, c.auth.Retry, c.accountID)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, respBody))
+	}
+	return respBody, nil
+}
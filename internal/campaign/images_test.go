@@ -0,0 +1,125 @@
+package campaign
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// imageAPI is a fake Graph API and image host recording uploads
+type imageAPI struct {
+	uploads   []string // File name and content of every upload
+	downloads int
+}
+
+func (f *imageAPI) roundTrip(req *http.Request) (*http.Response, error) {
+	body := `{}`
+	switch {
+	case req.URL.Host == "cdn.example.com":
+		f.downloads++
+		body = "remote-bytes"
+	case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/act_123/adimages"):
+		_, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil {
+			return nil, err
+		}
+		part, err := multipart.NewReader(req.Body, params["boundary"]).NextPart()
+		if err != nil {
+			return nil, err
+		}
+		data, _ := io.ReadAll(part)
+		f.uploads = append(f.uploads, part.FormName()+":"+part.FileName()+"="+string(data))
+		body = `{"images":{"` + part.FileName() + `":{"hash":"hash-` + string(data) + `"}}}`
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func TestUploadCreativeImages(t *testing.T) {
+	imagePath := filepath.Join(t.TempDir(), "hero.png")
+	if err := os.WriteFile(imagePath, []byte("local-bytes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &imageAPI{}
+	authClient := auth.NewFacebookAuth("app", "secret", "token", "v22.0")
+	authClient.Transport = roundTripFunc(fake.roundTrip)
+	creator := NewCampaignCreator(authClient, "123")
+
+	config := &models.CampaignConfig{
+		AdSets: []models.AdSetConfig{{Ads: []models.AdConfig{
+			{Creative: models.CreativeConfig{ImageURL: imagePath}},
+			{Creative: models.CreativeConfig{ImageURL: "https://cdn.example.com/img/banner.jpg"}},
+		}}},
+		Ads: []models.AdConfig{
+			{Creative: models.CreativeConfig{ImageURL: imagePath}},
+			{Creative: models.CreativeConfig{ImageURL: "https://cdn.example.com/old.jpg", ImageHash: "existing"}},
+		},
+	}
+
+	if err := creator.UploadCreativeImages(config); err != nil {
+		t.Fatalf("UploadCreativeImages failed: %v", err)
+	}
+
+	var hashes []string
+	for _, ad := range config.AllAds() {
+		hashes = append(hashes, ad.Creative.ImageHash)
+	}
+	if got := strings.Join(hashes, ","); got != "hash-local-bytes,hash-remote-bytes,hash-local-bytes,existing" {
+		t.Errorf("Unexpected image hashes %s", got)
+	}
+
+	// The repeated file is uploaded once and images with a hash are left alone
+	expected := "filename:hero.png=local-bytes,filename:banner.jpg=remote-bytes"
+	if got := strings.Join(fake.uploads, ","); got != expected {
+		t.Errorf("Uploads = %s, want %s", got, expected)
+	}
+	if fake.downloads != 1 {
+		t.Errorf("Expected one download, got %d", fake.downloads)
+	}
+}
+
+func TestUploadImage_MissingFile(t *testing.T) {
+	creator := NewCampaignCreator(auth.NewFacebookAuth("app", "secret", "token", "v22.0"), "123")
+	if _, err := creator.UploadImage(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("Expected an error for a missing image file")
+	}
+}
+
+func TestCreativeParams_ImageHash(t *testing.T) {
+	params, err := creativeParams(models.CreativeConfig{
+		PageID:    "1",
+		LinkURL:   "https://example.com",
+		ImageURL:  "hero.png",
+		ImageHash: "abc123",
+	}, "")
+	if err != nil {
+		t.Fatalf("creativeParams failed: %v", err)
+	}
+
+	var spec struct {
+		LinkData map[string]interface{} `json:"link_data"`
+	}
+	if err := json.Unmarshal([]byte(params.Get("object_story_spec")), &spec); err != nil {
+		t.Fatal(err)
+	}
+	if spec.LinkData["image_hash"] != "abc123" {
+		t.Errorf("Expected the image hash in link_data, got %v", spec.LinkData)
+	}
+	if _, ok := spec.LinkData["image_url"]; ok {
+		t.Error("Expected no image_url in link_data")
+	}
+}
@@ -0,0 +1,201 @@
+package campaign
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+// OwnershipLabelPrefix starts the name of the ad label fbads attaches to every object it creates
+const OwnershipLabelPrefix = "fbads:"
+
+// OwnershipLabelVersion is the version in the ownership label of newly created objects
+const OwnershipLabelVersion = "1"
+
+// OwnershipLabel is the ad label attached to campaigns, ad sets and ads created by fbads
+const OwnershipLabel = OwnershipLabelPrefix + "v" + OwnershipLabelVersion
+
+// IsOwned reports whether the label names include an fbads ownership label of any version
+func IsOwned(labels []string) bool {
+	for _, name := range labels {
+		if strings.HasPrefix(strings.ToLower(name), OwnershipLabelPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExperimentLabelPrefix starts the names of ad labels that assign campaigns to experiment arms
+const ExperimentLabelPrefix = "exp:"
+
+// ExperimentLabel returns the ad label assigning objects to an arm of an experiment
+func ExperimentLabel(name, arm string) (string, error) {
+	for _, part := range []string{name, arm} {
+		if strings.TrimSpace(part) == "" || strings.Contains(part, ":") {
+			return "", fmt.Errorf("experiment names and arms must be non-empty and must not contain ':', got %q", part)
+		}
+	}
+	return ExperimentLabelPrefix + name + ":" + arm, nil
+}
+
+// ParseExperimentLabel splits an ad label of the form exp:<name>:<arm>
+func ParseExperimentLabel(label string) (name, arm string, ok bool) {
+	if !strings.HasPrefix(strings.ToLower(label), ExperimentLabelPrefix) {
+		return "", "", false
+	}
+
+	parts := strings.Split(label[len(ExperimentLabelPrefix):], ":")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// ExperimentArms returns the arms of the named experiment the label names assign an object to.
+// Experiment names are matched case-insensitively.
+func ExperimentArms(labels []string, experiment string) []string {
+	var arms []string
+	for _, label := range labels {
+		if name, arm, ok := ParseExperimentLabel(label); ok && strings.EqualFold(name, experiment) {
+			arms = append(arms, arm)
+		}
+	}
+	return arms
+}
+
+// AttachLabel attaches the ad label with the given name to objects, creating the label
+// in the account if needed. It stops at the first object that cannot be labeled.
+func (c *CampaignCreator) AttachLabel(name string, objectIDs ...string) error {
+	return c.AttachLabelContext(context.Background(), name, objectIDs...)
+}
+
+// AttachLabelContext attaches the ad label with the given name to objects, creating the label if needed
+func (c *CampaignCreator) AttachLabelContext(ctx context.Context, name string, objectIDs ...string) error {
+	labelID, err := c.ensureLabel(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	for _, objectID := range objectIDs {
+		if err := c.attachLabelID(ctx, objectID, labelID); err != nil {
+			return fmt.Errorf("error labeling %s: %w", objectID, err)
+		}
+	}
+	return nil
+}
+
+// ownershipLabelID returns the ID of the account's ownership label, creating the label
+// the first time. The ID is cached, so the label is looked up once per creator.
+func (c *CampaignCreator) ownershipLabelID(ctx context.Context) (string, error) {
+	c.labelMu.Lock()
+	defer c.labelMu.Unlock()
+
+	if c.labelID != "" {
+		return c.labelID, nil
+	}
+
+	id, err := c.ensureLabel(ctx, OwnershipLabel)
+	if err != nil {
+		return "", err
+	}
+
+	c.labelID = id
+	return id, nil
+}
+
+// ensureLabel returns the ID of the account's ad label with the given name, creating it if needed
+func (c *CampaignCreator) ensureLabel(ctx context.Context, name string) (string, error) {
+	id, err := c.findLabel(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	if id == "" {
+		params := url.Values{}
+		params.Set("name", name)
+		id, err = c.createEntity(ctx, fmt.Sprintf("act_%s/adlabels", c.accountID), params)
+		if err != nil {
+			return "", fmt.Errorf("error creating ad label %s: %w", name, err)
+		}
+	}
+	return id, nil
+}
+
+// findLabel returns the ID of the account's ad label with the given name, or "" if there is none
+func (c *CampaignCreator) findLabel(ctx context.Context, name string) (string, error) {
+	params := url.Values{}
+	params.Set("fields", "id,name")
+	params.Set("limit", "500")
+
+	req, err := c.auth.GetAuthenticatedRequestContext(ctx, fmt.Sprintf("act_%s/adlabels", c.accountID), params)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return "", fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
+	}
+
+	var result struct {
+		Data []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("error decoding ad labels: %w", err)
+	}
+
+	for _, label := range result.Data {
+		if label.Name == name {
+			return label.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// markOwned attaches the ownership label to a created object. Failures only warn:
+// the object exists either way and an unlabeled object is merely not recognized as ours.
+func (c *CampaignCreator) markOwned(ctx context.Context, objectID string) {
+	labelID, err := c.ownershipLabelID(ctx)
+	if err == nil {
+		err = c.attachLabelID(ctx, objectID, labelID)
+	}
+
+	if err != nil {
+		fmt.Printf("Warning: could not label %s as created by fbads: %v\n", objectID, err)
+	}
+}
+
+// attachLabelID attaches an ad label to an object by the label's ID
+func (c *CampaignCreator) attachLabelID(ctx context.Context, objectID, labelID string) error {
+	labels, _ := json.Marshal([]map[string]string{{"id": labelID}})
+	params := url.Values{}
+	params.Set("adlabels", string(labels))
+	_, err := c.createEntity(ctx, objectID+"/adlabels", params)
+	return err
+}
+
+// createOwned creates an entity and attaches the ownership label to it
+func (c *CampaignCreator) createOwned(ctx context.Context, endpoint string, params url.Values) (string, error) {
+	id, err := c.createEntity(ctx, endpoint, params)
+	if err != nil {
+		return "", err
+	}
+
+	c.markOwned(ctx, id)
+	return id, nil
+}
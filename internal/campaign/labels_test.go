@@ -0,0 +1,179 @@
+package campaign
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// roundTripFunc answers HTTP requests with a function
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// labelAPI is a fake Graph API recording the ad label requests of a creator
+type labelAPI struct {
+	existing    string // Ad labels already in the account, as a JSON list
+	attachFails bool
+	lookups     int
+	creates     int
+	attached    []string
+}
+
+func (f *labelAPI) roundTrip(req *http.Request) (*http.Response, error) {
+	path := strings.TrimPrefix(req.URL.Path, "/v22.0/")
+	status, body := http.StatusOK, `{"id":"9001"}`
+
+	switch {
+	case req.Method == http.MethodGet && path == "act_123/adlabels":
+		f.lookups++
+		body = `{"data":` + f.existing + `}`
+	case req.Method == http.MethodPost && path == "act_123/adlabels":
+		f.creates++
+		body = `{"id":"555"}`
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/adlabels"):
+		if f.attachFails {
+			status, body = http.StatusBadRequest, `{"error":{"message":"Invalid ad label"}}`
+			break
+		}
+		data, _ := io.ReadAll(req.Body)
+		form, _ := url.ParseQuery(string(data))
+		f.attached = append(f.attached, strings.TrimSuffix(path, "/adlabels")+"="+form.Get("adlabels"))
+		body = `{"success":true}`
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+// newLabelCreator returns a creator talking to the fake API
+func newLabelCreator(fake *labelAPI) *CampaignCreator {
+	authClient := auth.NewFacebookAuth("app", "secret", "token", "v22.0")
+	authClient.Transport = roundTripFunc(fake.roundTrip)
+	return NewCampaignCreator(authClient, "123")
+}
+
+func TestOwnershipLabel_CreatedOnceAndCached(t *testing.T) {
+	fake := &labelAPI{existing: `[{"id":"1","name":"spring-sale"}]`}
+	creator := newLabelCreator(fake)
+	config := &models.CampaignConfig{Name: "Spring", Objective: "OUTCOME_TRAFFIC", BuyingType: "AUCTION"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := creator.CreateCampaign(config); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if _, err := creator.CreateAdSet("9001", &models.AdSetConfig{Name: "Broad"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if fake.lookups != 1 || fake.creates != 1 {
+		t.Errorf("Expected the label to be looked up and created once, got %d lookups and %d creates", fake.lookups, fake.creates)
+	}
+	if len(fake.attached) != 3 || fake.attached[0] != `9001=[{"id":"555"}]` {
+		t.Errorf("Expected every object to get the created label, got %v", fake.attached)
+	}
+}
+
+func TestOwnershipLabel_ReusesExistingLabel(t *testing.T) {
+	fake := &labelAPI{existing: `[{"id":"777","name":"` + OwnershipLabel + `"}]`}
+	creator := newLabelCreator(fake)
+
+	if _, err := creator.CreateCampaign(&models.CampaignConfig{Name: "Spring"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if fake.creates != 0 {
+		t.Errorf("Expected the existing label to be reused, got %d creates", fake.creates)
+	}
+	if len(fake.attached) != 1 || fake.attached[0] != `9001=[{"id":"777"}]` {
+		t.Errorf("Expected the existing label to be attached, got %v", fake.attached)
+	}
+}
+
+func TestOwnershipLabel_AttachFailureOnlyWarns(t *testing.T) {
+	fake := &labelAPI{existing: `[]`, attachFails: true}
+	creator := newLabelCreator(fake)
+
+	id, err := creator.CreateCampaign(&models.CampaignConfig{Name: "Spring"})
+	if err != nil || id != "9001" {
+		t.Errorf("Expected the campaign to be created despite the label failure, got %q, %v", id, err)
+	}
+}
+
+func TestIsOwned(t *testing.T) {
+	tests := []struct {
+		labels   []string
+		expected bool
+	}{
+		{labels: nil, expected: false},
+		{labels: []string{"spring-sale"}, expected: false},
+		{labels: []string{"spring-sale", OwnershipLabel}, expected: true},
+		{labels: []string{"fbads:v0"}, expected: true},
+		{labels: []string{"FBADS:v2"}, expected: true},
+		{labels: []string{"not-fbads:v1"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsOwned(tt.labels); got != tt.expected {
+			t.Errorf("IsOwned(%v) = %v; expected %v", tt.labels, got, tt.expected)
+		}
+	}
+}
+
+func TestParseExperimentLabel(t *testing.T) {
+	tests := []struct {
+		label string
+		name  string
+		arm   string
+		ok    bool
+	}{
+		{label: "exp:landing-page:a", name: "landing-page", arm: "a", ok: true},
+		{label: "EXP:Hero:control", name: "Hero", arm: "control", ok: true},
+		{label: "exp:landing-page", ok: false},
+		{label: "exp:landing-page:a:b", ok: false},
+		{label: "exp::a", ok: false},
+		{label: "spring-sale", ok: false},
+	}
+
+	for _, tt := range tests {
+		name, arm, ok := ParseExperimentLabel(tt.label)
+		if name != tt.name || arm != tt.arm || ok != tt.ok {
+			t.Errorf("ParseExperimentLabel(%q) = %q, %q, %v; expected %q, %q, %v", tt.label, name, arm, ok, tt.name, tt.arm, tt.ok)
+		}
+	}
+
+	if _, err := ExperimentLabel("landing:page", "a"); err == nil {
+		t.Errorf("Expected an error for an experiment name containing ':'")
+	}
+	if label, err := ExperimentLabel("landing-page", "b"); err != nil || label != "exp:landing-page:b" {
+		t.Errorf("ExperimentLabel = %q, %v; expected exp:landing-page:b", label, err)
+	}
+}
+
+func TestAttachLabel_CreatesLabelOnce(t *testing.T) {
+	fake := &labelAPI{existing: `[]`}
+	creator := newLabelCreator(fake)
+
+	if err := creator.AttachLabel("exp:landing-page:a", "101", "102"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if fake.lookups != 1 || fake.creates != 1 {
+		t.Errorf("Expected the label to be looked up and created once, got %d lookups and %d creates", fake.lookups, fake.creates)
+	}
+	if len(fake.attached) != 2 || fake.attached[1] != `102=[{"id":"555"}]` {
+		t.Errorf("Expected both campaigns to get the label, got %v", fake.attached)
+	}
+}
@@ -0,0 +1,71 @@
+package campaign
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// Destination types supported for click-to-message ad sets
+const (
+	DestinationWhatsApp        = string(models.DestinationWhatsApp)
+	DestinationMessenger       = string(models.DestinationMessenger)
+	DestinationInstagramDirect = string(models.DestinationInstagramDirect)
+)
+
+// messagingDefaults holds the call to action and fallback link used per destination
+var messagingDefaults = map[string]struct {
+	callToAction string
+	link         string
+}{
+	DestinationWhatsApp:        {callToAction: "WHATSAPP_MESSAGE", link: "https://api.whatsapp.com/send"},
+	DestinationMessenger:       {callToAction: "MESSAGE_PAGE", link: "https://fb.com/messenger_doc/"},
+	DestinationInstagramDirect: {callToAction: "INSTAGRAM_MESSAGE", link: "https://www.instagram.com/direct/inbox/"},
+}
+
+// IsMessagingDestination reports whether the destination type is a click-to-message destination
+func IsMessagingDestination(destinationType string) bool {
+	_, ok := messagingDefaults[strings.ToUpper(destinationType)]
+	return ok
+}
+
+// ValidateDestinationType checks that an ad set destination type is known and
+// compatible with the campaign objective
+func ValidateDestinationType(objective, destinationType string) error {
+	return models.ValidateDestinationType(objective, destinationType)
+}
+
+// ValidateMessagingConfig validates the messaging settings of every ad set in a campaign configuration
+func ValidateMessagingConfig(config *models.CampaignConfig) error {
+	for i, adSet := range config.AdSets {
+		if err := ValidateDestinationType(config.Objective, adSet.DestinationType); err != nil {
+			return fmt.Errorf("ad set #%d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// messagingCallToAction builds the call_to_action object for a click-to-message creative
+func messagingCallToAction(config models.CreativeConfig, destinationType string) map[string]interface{} {
+	destination := strings.ToUpper(destinationType)
+
+	ctaType := config.CallToAction
+	if ctaType == "" {
+		ctaType = messagingDefaults[destination].callToAction
+	}
+
+	value := map[string]interface{}{
+		"app_destination": destination,
+	}
+
+	if destination == DestinationWhatsApp && config.WhatsAppNumber != "" {
+		value["whatsapp_number"] = config.WhatsAppNumber
+	}
+
+	return map[string]interface{}{
+		"type":  ctaType,
+		"value": value,
+	}
+}
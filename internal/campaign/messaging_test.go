@@ -0,0 +1,233 @@
+package campaign
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestValidateDestinationType(t *testing.T) {
+	tests := []struct {
+		name            string
+		objective       string
+		destinationType string
+		expectError     bool
+		errorContains   string
+	}{
+		{
+			name:            "no destination",
+			objective:       "OUTCOME_TRAFFIC",
+			destinationType: "",
+			expectError:     false,
+		},
+		{
+			name:            "whatsapp with engagement",
+			objective:       "OUTCOME_ENGAGEMENT",
+			destinationType: DestinationWhatsApp,
+			expectError:     false,
+		},
+		{
+			name:            "messenger with sales",
+			objective:       "OUTCOME_SALES",
+			destinationType: DestinationMessenger,
+			expectError:     false,
+		},
+		{
+			name:            "lowercase instagram direct",
+			objective:       "outcome_engagement",
+			destinationType: "instagram_direct",
+			expectError:     false,
+		},
+		{
+			name:            "whatsapp with traffic objective",
+			objective:       "OUTCOME_TRAFFIC",
+			destinationType: DestinationWhatsApp,
+			expectError:     true,
+			errorContains:   "requires objective",
+		},
+		{
+			name:            "unknown destination",
+			objective:       "OUTCOME_ENGAGEMENT",
+			destinationType: "TELEGRAM",
+			expectError:     true,
+			errorContains:   "unsupported destination type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDestinationType(tt.objective, tt.destinationType)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("expected error containing %q, got %q", tt.errorContains, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateMessagingConfig(t *testing.T) {
+	config := &models.CampaignConfig{
+		Objective: "OUTCOME_AWARENESS",
+		AdSets: []models.AdSetConfig{
+			{Name: "Regular"},
+			{Name: "WhatsApp", DestinationType: DestinationWhatsApp},
+		},
+	}
+
+	err := ValidateMessagingConfig(config)
+	if err == nil {
+		t.Fatalf("expected error for awareness objective with WhatsApp destination")
+	}
+	if !strings.Contains(err.Error(), "ad set #2") {
+		t.Errorf("expected error to reference ad set #2, got %q", err.Error())
+	}
+
+	config.Objective = "OUTCOME_ENGAGEMENT"
+	if err := ValidateMessagingConfig(config); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAdSetParamsDestinationType(t *testing.T) {
+	tests := []struct {
+		name            string
+		destinationType string
+		expected        string
+	}{
+		{name: "none", destinationType: "", expected: ""},
+		{name: "whatsapp", destinationType: "whatsapp", expected: DestinationWhatsApp},
+		{name: "messenger", destinationType: DestinationMessenger, expected: DestinationMessenger},
+		{name: "instagram direct", destinationType: DestinationInstagramDirect, expected: DestinationInstagramDirect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &models.AdSetConfig{
+				Name:             "Test Ad Set",
+				OptimizationGoal: "CONVERSATIONS",
+				BillingEvent:     "IMPRESSIONS",
+				DestinationType:  tt.destinationType,
+			}
+
+			params, err := adSetParams("123", config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := params.Get("destination_type"); got != tt.expected {
+				t.Errorf("destination_type = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCreativeParamsMessaging(t *testing.T) {
+	tests := []struct {
+		name            string
+		destinationType string
+		creative        models.CreativeConfig
+		expectedCTA     string
+		expectedLink    string
+		expectedNumber  string
+		expectWelcome   bool
+	}{
+		{
+			name:            "whatsapp with number and welcome message",
+			destinationType: DestinationWhatsApp,
+			creative: models.CreativeConfig{
+				Name:               "Chat with us",
+				PageID:             "page_1",
+				WhatsAppNumber:     "+15551234567",
+				PageWelcomeMessage: "Hi! How can we help?",
+			},
+			expectedCTA:    "WHATSAPP_MESSAGE",
+			expectedLink:   "https://api.whatsapp.com/send",
+			expectedNumber: "+15551234567",
+			expectWelcome:  true,
+		},
+		{
+			name:            "messenger keeps explicit link and cta",
+			destinationType: DestinationMessenger,
+			creative: models.CreativeConfig{
+				Name:         "Message us",
+				PageID:       "page_1",
+				LinkURL:      "https://example.com",
+				CallToAction: "CONTACT_US",
+			},
+			expectedCTA:  "CONTACT_US",
+			expectedLink: "https://example.com",
+		},
+		{
+			name:            "instagram direct",
+			destinationType: DestinationInstagramDirect,
+			creative: models.CreativeConfig{
+				Name:           "DM us",
+				PageID:         "page_1",
+				WhatsAppNumber: "+15551234567", // Ignored outside WhatsApp
+			},
+			expectedCTA:  "INSTAGRAM_MESSAGE",
+			expectedLink: "https://www.instagram.com/direct/inbox/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := creativeParams(tt.creative, tt.destinationType)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var spec struct {
+				LinkData struct {
+					Link               string `json:"link"`
+					PageWelcomeMessage string `json:"page_welcome_message"`
+					CallToAction       struct {
+						Type  string `json:"type"`
+						Value struct {
+							AppDestination string `json:"app_destination"`
+							WhatsAppNumber string `json:"whatsapp_number"`
+						} `json:"value"`
+					} `json:"call_to_action"`
+				} `json:"link_data"`
+			}
+			if err := json.Unmarshal([]byte(params.Get("object_story_spec")), &spec); err != nil {
+				t.Fatalf("error parsing object_story_spec: %v", err)
+			}
+
+			if spec.LinkData.Link != tt.expectedLink {
+				t.Errorf("link = %q, want %q", spec.LinkData.Link, tt.expectedLink)
+			}
+			if spec.LinkData.CallToAction.Type != tt.expectedCTA {
+				t.Errorf("call_to_action type = %q, want %q", spec.LinkData.CallToAction.Type, tt.expectedCTA)
+			}
+			if spec.LinkData.CallToAction.Value.AppDestination != tt.destinationType {
+				t.Errorf("app_destination = %q, want %q", spec.LinkData.CallToAction.Value.AppDestination, tt.destinationType)
+			}
+			if spec.LinkData.CallToAction.Value.WhatsAppNumber != tt.expectedNumber {
+				t.Errorf("whatsapp_number = %q, want %q", spec.LinkData.CallToAction.Value.WhatsAppNumber, tt.expectedNumber)
+			}
+			if (spec.LinkData.PageWelcomeMessage != "") != tt.expectWelcome {
+				t.Errorf("page_welcome_message = %q, expected present: %v", spec.LinkData.PageWelcomeMessage, tt.expectWelcome)
+			}
+		})
+	}
+}
+
+func TestCreativeParamsRequiresLinkWithoutDestination(t *testing.T) {
+	creative := models.CreativeConfig{
+		Name:   "No link",
+		PageID: "page_1",
+	}
+
+	if _, err := creativeParams(creative, ""); err == nil {
+		t.Errorf("expected error for missing link_url")
+	}
+}
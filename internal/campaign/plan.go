@@ -0,0 +1,139 @@
+package campaign
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// RedactedToken replaces the access token in redacted planned requests
+const RedactedToken = "REDACTED"
+
+// PlannedRequest is an API request CreateFromConfig would send. IDs and image hashes that
+// are only known once an earlier request has been sent appear as placeholders such as
+// {campaign_id}, {adset_1_id}, {creative_2_id} and {image_1_hash}.
+type PlannedRequest struct {
+	Object   string     // campaign, adimage, adset, adcreative or ad
+	Name     string     // Name of the object, or the image path or URL for uploads
+	Method   string     // HTTP method
+	Endpoint string     // Path relative to the API base URL
+	Params   url.Values // Form parameters, including the access token
+	Ref      string     // Placeholder later requests use for the ID or hash this request returns
+}
+
+// Redacted returns a copy of the request with the access token replaced by RedactedToken
+func (r PlannedRequest) Redacted() PlannedRequest {
+	params := make(url.Values, len(r.Params))
+	for key, values := range r.Params {
+		params[key] = append([]string(nil), values...)
+	}
+	if params.Has("access_token") {
+		params.Set("access_token", RedactedToken)
+	}
+	r.Params = params
+	return r
+}
+
+// Plan builds the requests CreateFromConfig would send for a configuration, in order,
+// without sending any or changing the configuration. Images without a hash are planned as
+// uploads to act_<id>/adimages, which are sent as multipart forms with the image in the
+// filename field. The ownership label attached to each created campaign, ad set and ad is
+// not part of the plan.
+func (c *CampaignCreator) Plan(config *models.CampaignConfig) ([]PlannedRequest, error) {
+	if err := ValidateMessagingConfig(config); err != nil {
+		return nil, err
+	}
+
+	var plan []PlannedRequest
+	add := func(object, name, edge, ref string, params url.Values) {
+		params.Set("access_token", c.auth.AccessToken)
+		plan = append(plan, PlannedRequest{
+			Object:   object,
+			Name:     name,
+			Method:   "POST",
+			Endpoint: fmt.Sprintf("act_%s/%s", c.accountID, edge),
+			Params:   params,
+			Ref:      ref,
+		})
+	}
+
+	// Images are uploaded once per path or URL, before anything else is created
+	imageHashes := make(map[string]string)
+	imageHash := func(pathOrURL, hash string) string {
+		if hash != "" || pathOrURL == "" {
+			return hash
+		}
+		if ref, ok := imageHashes[pathOrURL]; ok {
+			return ref
+		}
+		ref := fmt.Sprintf("{image_%d_hash}", len(imageHashes)+1)
+		imageHashes[pathOrURL] = ref
+		params := url.Values{}
+		params.Set("filename", pathOrURL)
+		add("adimage", pathOrURL, "adimages", ref, params)
+		return ref
+	}
+
+	creatives := make([]models.CreativeConfig, 0, len(config.AllAds()))
+	for _, ad := range config.AllAds() {
+		creative := ad.Creative
+		creative.ImageHash = imageHash(creative.ImageURL, creative.ImageHash)
+		creative.Cards = append([]models.CreativeCard(nil), creative.Cards...)
+		for i := range creative.Cards {
+			creative.Cards[i].ImageHash = imageHash(creative.Cards[i].ImageURL, creative.Cards[i].ImageHash)
+		}
+		creatives = append(creatives, creative)
+	}
+
+	add("campaign", config.Name, "campaigns", "{campaign_id}", campaignParams(config, c.normalizeObjectives))
+
+	for i := range config.AdSets {
+		params, err := adSetParams("{campaign_id}", &config.AdSets[i])
+		if err != nil {
+			return nil, fmt.Errorf("ad set %s: %w", config.AdSets[i].Name, err)
+		}
+		add("adset", config.AdSets[i].Name, "adsets", fmt.Sprintf("{adset_%d_id}", i+1), params)
+	}
+
+	// Ads follow the order of CreateFromConfig: nested ads in their own ad set, then
+	// top-level ads cycling through the ad sets
+	planAd := func(n, adSetIndex int, ad *models.AdConfig) error {
+		adRef := fmt.Sprintf("{ad_%d_id}", n+1)
+		adSetRef := fmt.Sprintf("{adset_%d_id}", adSetIndex+1)
+		if creativeID := ad.Creative.CreativeID; creativeID != "" {
+			add("ad", ad.Name, "ads", adRef, adParams(adSetRef, creativeID, ad))
+			return nil
+		}
+
+		params, err := creativeParams(creatives[n], config.AdSets[adSetIndex].DestinationType)
+		if err != nil {
+			return fmt.Errorf("creative of ad %s: %w", ad.Name, err)
+		}
+		creativeRef := fmt.Sprintf("{creative_%d_id}", n+1)
+		add("adcreative", ad.Name, "adcreatives", creativeRef, params)
+		add("ad", ad.Name, "ads", adRef, adParams(adSetRef, creativeRef, ad))
+		return nil
+	}
+
+	n := 0
+	for i := range config.AdSets {
+		for j := range config.AdSets[i].Ads {
+			if err := planAd(n, i, &config.AdSets[i].Ads[j]); err != nil {
+				return nil, err
+			}
+			n++
+		}
+	}
+	if len(config.Ads) > 0 && len(config.AdSets) == 0 {
+		return nil, fmt.Errorf("top-level ads need at least one ad set")
+	}
+	for i := range config.Ads {
+		if err := planAd(n, i%len(config.AdSets), &config.Ads[i]); err != nil {
+			return nil, err
+		}
+		n++
+	}
+
+	return plan, nil
+}
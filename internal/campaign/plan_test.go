@@ -0,0 +1,150 @@
+package campaign
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestCampaignCreator_Plan(t *testing.T) {
+	config := &models.CampaignConfig{
+		Name:        "Spring Sale",
+		Objective:   "CONVERSIONS",
+		BuyingType:  "AUCTION",
+		DailyBudget: 19.99,
+		AdSets: []models.AdSetConfig{
+			{
+				Name:             "Women 25-45",
+				OptimizationGoal: "OFFSITE_CONVERSIONS",
+				BillingEvent:     "IMPRESSIONS",
+				BidAmount:        4.55,
+				Targeting:        map[string]interface{}{"age_min": 25, "age_max": 45},
+				Ads: []models.AdConfig{{
+					Name:     "Shoes",
+					Creative: models.CreativeConfig{Title: "Shoes", LinkURL: "https://example.com/shoes", ImageURL: "images/shoes.jpg", PageID: "42"},
+				}},
+			},
+			{Name: "Men 25-45", OptimizationGoal: "OFFSITE_CONVERSIONS", BillingEvent: "IMPRESSIONS"},
+		},
+		Ads: []models.AdConfig{
+			{Name: "Bags", Creative: models.CreativeConfig{LinkURL: "https://example.com/bags", ImageHash: "abc123", PageID: "42"}},
+			{Name: "Shoes again", Creative: models.CreativeConfig{LinkURL: "https://example.com/shoes", ImageURL: "images/shoes.jpg", PageID: "42"}},
+		},
+	}
+
+	creator := NewCampaignCreator(auth.NewFacebookAuth("app", "secret", "token", "v22.0"), "123")
+	plan, err := creator.Plan(config)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+
+	var summary []string
+	for _, request := range plan {
+		summary = append(summary, request.Endpoint+" "+request.Name)
+		if request.Method != "POST" || request.Params.Get("access_token") != "token" {
+			t.Errorf("%s: expected a POST with the access token, got %s %v", request.Endpoint, request.Method, request.Params)
+		}
+	}
+	expected := []string{
+		"act_123/adimages images/shoes.jpg",
+		"act_123/campaigns Spring Sale",
+		"act_123/adsets Women 25-45",
+		"act_123/adsets Men 25-45",
+		"act_123/adcreatives Shoes",
+		"act_123/ads Shoes",
+		"act_123/adcreatives Bags",
+		"act_123/ads Bags",
+		"act_123/adcreatives Shoes again",
+		"act_123/ads Shoes again",
+	}
+	if strings.Join(summary, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Unexpected plan:\n%s\nwant:\n%s", strings.Join(summary, "\n"), strings.Join(expected, "\n"))
+	}
+
+	tests := []struct {
+		request  int
+		param    string
+		expected string
+	}{
+		{request: 0, param: "filename", expected: "images/shoes.jpg"},
+		{request: 1, param: "objective", expected: "OUTCOME_SALES"},
+		{request: 1, param: "daily_budget", expected: "1999"},
+		{request: 1, param: "status", expected: "PAUSED"},
+		{request: 2, param: "campaign_id", expected: "{campaign_id}"},
+		{request: 2, param: "bid_amount", expected: "455"},
+		{request: 2, param: "targeting", expected: `{"age_max":45,"age_min":25}`},
+		{request: 5, param: "adset_id", expected: "{adset_1_id}"},
+		{request: 5, param: "creative", expected: `{"creative_id":"{creative_1_id}"}`},
+		{request: 7, param: "adset_id", expected: "{adset_1_id}"},
+		{request: 9, param: "adset_id", expected: "{adset_2_id}"},
+	}
+	for _, tt := range tests {
+		if got := plan[tt.request].Params.Get(tt.param); got != tt.expected {
+			t.Errorf("%s %s = %q, want %q", plan[tt.request].Endpoint, tt.param, got, tt.expected)
+		}
+	}
+
+	// Creatives reference the uploaded image by hash, never by image_url
+	for request, expectedHash := range map[int]string{4: "{image_1_hash}", 6: "abc123", 8: "{image_1_hash}"} {
+		var spec struct {
+			LinkData map[string]interface{} `json:"link_data"`
+		}
+		if err := json.Unmarshal([]byte(plan[request].Params.Get("object_story_spec")), &spec); err != nil {
+			t.Fatalf("Invalid object_story_spec: %v", err)
+		}
+		if spec.LinkData["image_hash"] != expectedHash {
+			t.Errorf("%s: image_hash = %v, want %s", plan[request].Name, spec.LinkData["image_hash"], expectedHash)
+		}
+	}
+
+	// Planning leaves the configuration as it is
+	if config.AdSets[0].Ads[0].Creative.ImageHash != "" {
+		t.Errorf("Expected Plan not to set image hashes on the configuration")
+	}
+
+	redacted := plan[1].Redacted()
+	if redacted.Params.Get("access_token") != RedactedToken || plan[1].Params.Get("access_token") != "token" {
+		t.Errorf("Expected Redacted to replace the token in a copy only")
+	}
+}
+
+func TestCampaignCreator_PlanInvalidCreative(t *testing.T) {
+	config := &models.CampaignConfig{
+		Name:   "No page",
+		AdSets: []models.AdSetConfig{{Name: "Ad set"}},
+		Ads:    []models.AdConfig{{Name: "Ad", Creative: models.CreativeConfig{LinkURL: "https://example.com"}}},
+	}
+
+	creator := NewCampaignCreator(auth.NewFacebookAuth("app", "secret", "token", "v22.0"), "123")
+	if _, err := creator.Plan(config); err == nil || !strings.Contains(err.Error(), "page_id") {
+		t.Errorf("Expected a page_id error, got %v", err)
+	}
+}
+
+func TestCampaignCreator_PlanReusedCreative(t *testing.T) {
+	config := &models.CampaignConfig{
+		Name:   "Reuse",
+		AdSets: []models.AdSetConfig{{Name: "Ad set"}},
+		Ads:    []models.AdConfig{{Name: "Ad", Creative: models.CreativeConfig{CreativeID: "777"}}},
+	}
+
+	creator := NewCampaignCreator(auth.NewFacebookAuth("app", "secret", "token", "v22.0"), "123")
+	plan, err := creator.Plan(config)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+
+	var objects []string
+	for _, request := range plan {
+		objects = append(objects, request.Object)
+	}
+	if strings.Join(objects, ",") != "campaign,adset,ad" {
+		t.Fatalf("Expected no creative request, got %v", objects)
+	}
+	if creative := plan[2].Params.Get("creative"); creative != `{"creative_id":"777"}` {
+		t.Errorf("Expected the ad to reference the reused creative, got %s", creative)
+	}
+}
@@ -0,0 +1,284 @@
+package campaign
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// ExportProfile selects which fields an exported campaign configuration keeps
+type ExportProfile string
+
+// Export profiles
+const (
+	// ExportProfileFull keeps every field
+	ExportProfileFull ExportProfile = "full"
+	// ExportProfileSlim drops timestamps, empty fields and fields equal to the creation defaults
+	ExportProfileSlim ExportProfile = "slim"
+	// ExportProfileStructure is slim with every creative replaced by a creative_ref placeholder
+	ExportProfileStructure ExportProfile = "structure"
+)
+
+// creationDefaultStatus is the status the creator uses when none is configured
+const creationDefaultStatus = string(models.CampaignStatusPaused)
+
+// ParseExportProfile parses a profile name, defaulting to the full profile
+func ParseExportProfile(name string) (ExportProfile, error) {
+	switch profile := ExportProfile(strings.ToLower(name)); profile {
+	case "":
+		return ExportProfileFull, nil
+	case ExportProfileFull, ExportProfileSlim, ExportProfileStructure:
+		return profile, nil
+	default:
+		return "", fmt.Errorf("unknown export profile %q (expected slim, full or structure)", name)
+	}
+}
+
+// ApplyExportProfile returns a copy of the configuration reduced to the fields of the profile.
+// The slim and structure profiles:
+//   - drop campaign and ad set start and end times
+//   - drop statuses equal to the creation default (PAUSED)
+//
+// The structure profile additionally moves every creative into the returned
+// library and leaves a creative_ref placeholder on the ad.
+func ApplyExportProfile(config *models.CampaignConfig, profile ExportProfile) (*models.CampaignConfig, CreativeLibrary) {
+	result := copyConfig(config)
+	if profile == ExportProfileFull {
+		return result, nil
+	}
+
+	result.StartTime = ""
+	result.EndTime = ""
+	if result.Status == creationDefaultStatus {
+		result.Status = ""
+	}
+
+	for i := range result.AdSets {
+		adSet := &result.AdSets[i]
+		adSet.StartTime = ""
+		adSet.EndTime = ""
+		if adSet.Status == creationDefaultStatus {
+			adSet.Status = ""
+		}
+	}
+
+	var library CreativeLibrary
+	if profile == ExportProfileStructure {
+		library = make(CreativeLibrary)
+	}
+
+	for _, ad := range result.AllAds() {
+		if ad.Status == creationDefaultStatus {
+			ad.Status = ""
+		}
+
+		if profile == ExportProfileStructure && ad.CreativeRef == "" {
+			ref := CreativeRef(ad.Creative)
+			library[ref] = ad.Creative
+			ad.CreativeRef = ref
+			ad.Creative = models.CreativeConfig{}
+		}
+	}
+
+	return result, library
+}
+
+// MarshalExportConfig encodes a configuration as indented JSON for the profile.
+// The full profile writes every field; the others leave out empty values
+// such as zero bid amounts and blank creative fields.
+// Targeting is kept verbatim, since zero values are meaningful there.
+func MarshalExportConfig(config *models.CampaignConfig, profile ExportProfile) ([]byte, error) {
+	if profile == ExportProfileFull {
+		return json.MarshalIndent(config, "", "  ")
+	}
+
+	var buf bytes.Buffer
+	if err := writeSlimJSON(&buf, reflect.ValueOf(*config)); err != nil {
+		return nil, err
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+		return nil, fmt.Errorf("error formatting configuration: %w", err)
+	}
+
+	return indented.Bytes(), nil
+}
+
+// writeSlimJSON writes a value as JSON, omitting empty struct fields and keeping field order
+func writeSlimJSON(buf *bytes.Buffer, value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Struct:
+		buf.WriteByte('{')
+		first := true
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" || !field.IsExported() || isEmptyValue(value.Field(i)) {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+
+			key, _ := json.Marshal(name)
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeSlimJSON(buf, value.Field(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.Struct {
+			return writeJSONValue(buf, value)
+		}
+		buf.WriteByte('[')
+		for i := 0; i < value.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeSlimJSON(buf, value.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+
+	default:
+		return writeJSONValue(buf, value)
+	}
+
+	return nil
+}
+
+// writeJSONValue writes a value with the standard JSON encoding
+func writeJSONValue(buf *bytes.Buffer, value reflect.Value) error {
+	data, err := json.Marshal(value.Interface())
+	if err != nil {
+		return fmt.Errorf("error serializing configuration: %w", err)
+	}
+	buf.Write(data)
+	return nil
+}
+
+// isEmptyValue reports whether a value is left out of slim output
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if !isEmptyValue(value.Field(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return value.IsZero()
+	}
+}
+
+// copyConfig returns a deep copy of a campaign configuration
+func copyConfig(config *models.CampaignConfig) *models.CampaignConfig {
+	data, _ := json.Marshal(config)
+
+	var result models.CampaignConfig
+	json.Unmarshal(data, &result)
+	return &result
+}
+
+// CreativeRef returns the placeholder reference of a creative, derived from its content
+func CreativeRef(creative models.CreativeConfig) string {
+	data, _ := json.Marshal(creative)
+	sum := sha256.Sum256(data)
+	return "creative-" + hex.EncodeToString(sum[:6])
+}
+
+// CreativeLibrary holds creatives by their creative_ref
+type CreativeLibrary map[string]models.CreativeConfig
+
+// LoadCreativeLibrary reads a creative library from a JSON file
+func LoadCreativeLibrary(path string) (CreativeLibrary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	library := make(CreativeLibrary)
+	if err := json.Unmarshal(data, &library); err != nil {
+		return nil, fmt.Errorf("error parsing creative library %s: %w", path, err)
+	}
+
+	return library, nil
+}
+
+// Merge adds the creatives of another library
+func (l CreativeLibrary) Merge(other CreativeLibrary) {
+	for ref, creative := range other {
+		l[ref] = creative
+	}
+}
+
+// Save writes the library to a JSON file
+func (l CreativeLibrary) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing creative library: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing creative library: %w", err)
+	}
+
+	return nil
+}
+
+// Resolve replaces the creative_ref placeholders of a configuration with creatives from the library
+func (l CreativeLibrary) Resolve(config *models.CampaignConfig) error {
+	var missing []string
+	for _, ad := range config.AllAds() {
+		if ad.CreativeRef == "" {
+			continue
+		}
+
+		creative, ok := l[ad.CreativeRef]
+		if !ok {
+			missing = append(missing, ad.CreativeRef)
+			continue
+		}
+
+		ad.Creative = creative
+		ad.CreativeRef = ""
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("creative library has no creatives for %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// HasCreativeRefs reports whether any ad of the configuration uses a creative_ref placeholder
+func HasCreativeRefs(config *models.CampaignConfig) bool {
+	for _, ad := range config.AllAds() {
+		if ad.CreativeRef != "" {
+			return true
+		}
+	}
+	return false
+}
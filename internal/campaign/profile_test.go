@@ -0,0 +1,186 @@
+package campaign
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// profileFixture returns an exported campaign with timestamps, defaults and two ads sharing a creative
+func profileFixture() *models.CampaignConfig {
+	creative := models.CreativeConfig{
+		Title:        "Spring sale",
+		Body:         "Everything 20% off",
+		LinkURL:      "https://example.com/sale",
+		CallToAction: "SHOP_NOW",
+		PageID:       "123",
+	}
+
+	return &models.CampaignConfig{
+		Name:        "Spring",
+		Status:      "PAUSED",
+		Objective:   "OUTCOME_TRAFFIC",
+		BuyingType:  "AUCTION",
+		BidStrategy: "LOWEST_COST_WITHOUT_CAP",
+		DailyBudget: 50,
+		StartTime:   "2024-03-01T00:00:00Z",
+		AdSets: []models.AdSetConfig{{
+			Name:             "Broad",
+			Status:           "ACTIVE",
+			Targeting:        map[string]interface{}{"age_min": 18, "geo_locations": map[string]interface{}{"countries": []string{"US"}}},
+			OptimizationGoal: "LINK_CLICKS",
+			BillingEvent:     "IMPRESSIONS",
+			StartTime:        "2024-03-01T00:00:00Z",
+			EndTime:          "2024-03-31T00:00:00Z",
+		}},
+		Ads: []models.AdConfig{
+			{Name: "Ad A", Status: "PAUSED", Creative: creative},
+			{Name: "Ad B", Status: "ACTIVE", Creative: creative},
+		},
+	}
+}
+
+func TestParseExportProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		expected    ExportProfile
+		expectError bool
+	}{
+		{name: "", expected: ExportProfileFull},
+		{name: "full", expected: ExportProfileFull},
+		{name: "SLIM", expected: ExportProfileSlim},
+		{name: "structure", expected: ExportProfileStructure},
+		{name: "minimal", expectError: true},
+	}
+
+	for _, tt := range tests {
+		profile, err := ParseExportProfile(tt.name)
+		if tt.expectError {
+			if err == nil {
+				t.Errorf("Expected an error for %q", tt.name)
+			}
+			continue
+		}
+		if err != nil || profile != tt.expected {
+			t.Errorf("ParseExportProfile(%q) = %q, %v; expected %q", tt.name, profile, err, tt.expected)
+		}
+	}
+}
+
+func TestExportProfiles(t *testing.T) {
+	tests := []struct {
+		profile  ExportProfile
+		contains []string
+		excludes []string
+	}{
+		{
+			profile:  ExportProfileFull,
+			contains: []string{`"status": "PAUSED"`, `"start_time"`, `"end_time"`, `"bid_amount": 0`, `"title": "Spring sale"`},
+			excludes: []string{`"creative_ref"`},
+		},
+		{
+			profile:  ExportProfileSlim,
+			contains: []string{`"status": "ACTIVE"`, `"title": "Spring sale"`, `"age_min": 18`},
+			excludes: []string{`"PAUSED"`, `"start_time"`, `"end_time"`, `"bid_amount"`, `"creative_ref"`},
+		},
+		{
+			profile:  ExportProfileStructure,
+			contains: []string{`"creative_ref": "creative-`, `"status": "ACTIVE"`},
+			excludes: []string{`"PAUSED"`, `"start_time"`, `"creative":`, `"Spring sale"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.profile), func(t *testing.T) {
+			config, creatives := ApplyExportProfile(profileFixture(), tt.profile)
+
+			data, err := MarshalExportConfig(config, tt.profile)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			output := string(data)
+
+			for _, s := range tt.contains {
+				if !strings.Contains(output, s) {
+					t.Errorf("Expected output to contain %s, got:\n%s", s, output)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(output, s) {
+					t.Errorf("Expected output not to contain %s, got:\n%s", s, output)
+				}
+			}
+
+			// Shared creatives collapse into a single library entry
+			expectedCreatives := 0
+			if tt.profile == ExportProfileStructure {
+				expectedCreatives = 1
+			}
+			if len(creatives) != expectedCreatives {
+				t.Errorf("Expected %d creatives, got %d", expectedCreatives, len(creatives))
+			}
+
+			// Every profile produces a configuration create can read back
+			var parsed models.CampaignConfig
+			if err := json.Unmarshal(data, &parsed); err != nil {
+				t.Fatalf("Output is not a valid configuration: %v", err)
+			}
+			if parsed.Name != "Spring" || len(parsed.AdSets) != 1 || len(parsed.Ads) != 2 {
+				t.Errorf("Expected the campaign structure to be kept, got %+v", parsed)
+			}
+		})
+	}
+}
+
+func TestApplyExportProfile_LeavesSourceUntouched(t *testing.T) {
+	source := profileFixture()
+	ApplyExportProfile(source, ExportProfileStructure)
+
+	if !reflect.DeepEqual(source, profileFixture()) {
+		t.Errorf("Expected source configuration to be unchanged, got %+v", source)
+	}
+}
+
+func TestCreativeLibrary_RoundTrip(t *testing.T) {
+	config, creatives := ApplyExportProfile(profileFixture(), ExportProfileStructure)
+	if !HasCreativeRefs(config) {
+		t.Fatal("Expected creative references in the structure export")
+	}
+
+	path := filepath.Join(t.TempDir(), "creatives.json")
+	if err := creatives.Save(path); err != nil {
+		t.Fatalf("Unexpected error saving library: %v", err)
+	}
+
+	library, err := LoadCreativeLibrary(path)
+	if err != nil {
+		t.Fatalf("Unexpected error loading library: %v", err)
+	}
+
+	if err := library.Resolve(config); err != nil {
+		t.Fatalf("Unexpected error resolving creatives: %v", err)
+	}
+	if HasCreativeRefs(config) {
+		t.Error("Expected all creative references to be resolved")
+	}
+
+	expected := profileFixture().Ads[0].Creative
+	for _, ad := range config.Ads {
+		if !reflect.DeepEqual(ad.Creative, expected) {
+			t.Errorf("Expected creative %+v for %s, got %+v", expected, ad.Name, ad.Creative)
+		}
+	}
+}
+
+func TestCreativeLibrary_ResolveMissing(t *testing.T) {
+	config := &models.CampaignConfig{Ads: []models.AdConfig{{Name: "Ad", CreativeRef: "creative-unknown"}}}
+
+	err := CreativeLibrary{}.Resolve(config)
+	if err == nil || !strings.Contains(err.Error(), "creative-unknown") {
+		t.Errorf("Expected an error naming the missing creative, got %v", err)
+	}
+}
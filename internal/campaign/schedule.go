@@ -0,0 +1,145 @@
+package campaign
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ScheduledResume is a paused campaign that should be turned back on at a given time
+type ScheduledResume struct {
+	CampaignID string    `json:"campaign_id"`
+	ResumeAt   time.Time `json:"resume_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	Attempts   int       `json:"attempts,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+// ScheduleEvent reports the outcome of one scheduled resume
+type ScheduleEvent struct {
+	CampaignID string
+	ResumeAt   time.Time
+	Err        error
+}
+
+// Schedule is the local list of scheduled resumes, persisted as JSON
+type Schedule struct {
+	path    string
+	Resumes []ScheduledResume `json:"resumes"`
+}
+
+// LoadSchedule reads the schedule file, returning an empty schedule when it doesn't exist yet
+func LoadSchedule(path string) (*Schedule, error) {
+	schedule := &Schedule{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return schedule, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading schedule: %w", err)
+	}
+
+	if err := json.Unmarshal(data, schedule); err != nil {
+		return nil, fmt.Errorf("error parsing schedule %s: %w", path, err)
+	}
+
+	return schedule, nil
+}
+
+// Save writes the schedule back to its file
+func (s *Schedule) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing schedule: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("error creating schedule directory: %w", err)
+	}
+
+	// Write to a temp file first so an interrupted run never loses the schedule
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("error writing schedule: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("error writing schedule: %w", err)
+	}
+
+	return nil
+}
+
+// ScheduleResume schedules a campaign to be resumed at resumeAt, replacing any earlier entry for it
+func (s *Schedule) ScheduleResume(campaignID string, resumeAt, now time.Time) {
+	s.Cancel(campaignID)
+	s.Resumes = append(s.Resumes, ScheduledResume{
+		CampaignID: campaignID,
+		ResumeAt:   resumeAt,
+		CreatedAt:  now,
+	})
+	sort.SliceStable(s.Resumes, func(i, j int) bool {
+		return s.Resumes[i].ResumeAt.Before(s.Resumes[j].ResumeAt)
+	})
+}
+
+// Cancel removes the scheduled resume of a campaign and reports whether there was one
+func (s *Schedule) Cancel(campaignID string) bool {
+	kept := s.Resumes[:0]
+	found := false
+	for _, resume := range s.Resumes {
+		if resume.CampaignID == campaignID {
+			found = true
+			continue
+		}
+		kept = append(kept, resume)
+	}
+	s.Resumes = kept
+	return found
+}
+
+// Due returns the scheduled resumes whose time has come
+func (s *Schedule) Due(now time.Time) []ScheduledResume {
+	var due []ScheduledResume
+	for _, resume := range s.Resumes {
+		if !resume.ResumeAt.After(now) {
+			due = append(due, resume)
+		}
+	}
+	return due
+}
+
+// RunDue resumes every campaign whose time has come and calls notify with each outcome.
+// Resumed campaigns are removed from the schedule; failed ones stay to be retried on the next run.
+func (s *Schedule) RunDue(now time.Time, resume func(campaignID string) error, notify func(ScheduleEvent)) []ScheduleEvent {
+	var events []ScheduleEvent
+	for _, due := range s.Due(now) {
+		err := resume(due.CampaignID)
+		event := ScheduleEvent{CampaignID: due.CampaignID, ResumeAt: due.ResumeAt, Err: err}
+		events = append(events, event)
+
+		if err == nil {
+			s.Cancel(due.CampaignID)
+		} else {
+			s.recordFailure(due.CampaignID, err)
+		}
+
+		if notify != nil {
+			notify(event)
+		}
+	}
+	return events
+}
+
+// recordFailure keeps the error of a failed resume on its schedule entry
+func (s *Schedule) recordFailure(campaignID string, err error) {
+	for i := range s.Resumes {
+		if s.Resumes[i].CampaignID == campaignID {
+			s.Resumes[i].Attempts++
+			s.Resumes[i].LastError = err.Error()
+		}
+	}
+}
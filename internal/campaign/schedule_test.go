@@ -0,0 +1,88 @@
+package campaign
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSchedulePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fbads", "schedule.json")
+	now := time.Date(2024, 6, 28, 15, 0, 0, 0, time.UTC)
+
+	schedule, err := LoadSchedule(path)
+	if err != nil {
+		t.Fatalf("Expected a missing schedule to load empty, got %v", err)
+	}
+	schedule.ScheduleResume("222", now.Add(72*time.Hour), now)
+	schedule.ScheduleResume("111", now.Add(24*time.Hour), now)
+	schedule.ScheduleResume("222", now.Add(48*time.Hour), now) // Rescheduling replaces the entry
+	if err := schedule.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadSchedule(path)
+	if err != nil {
+		t.Fatalf("LoadSchedule failed: %v", err)
+	}
+	if len(loaded.Resumes) != 2 {
+		t.Fatalf("Expected 2 scheduled resumes, got %+v", loaded.Resumes)
+	}
+	if loaded.Resumes[0].CampaignID != "111" || !loaded.Resumes[1].ResumeAt.Equal(now.Add(48*time.Hour)) {
+		t.Errorf("Expected resumes ordered by time with the rescheduled time, got %+v", loaded.Resumes)
+	}
+
+	if !loaded.Cancel("111") || loaded.Cancel("111") {
+		t.Errorf("Expected Cancel to report the removed entry once")
+	}
+}
+
+func TestScheduleRunDue(t *testing.T) {
+	now := time.Date(2024, 7, 1, 0, 5, 0, 0, time.UTC)
+	schedule := &Schedule{path: filepath.Join(t.TempDir(), "schedule.json")}
+	schedule.ScheduleResume("ok", now.Add(-5*time.Minute), now.Add(-72*time.Hour))
+	schedule.ScheduleResume("fails", now.Add(-time.Minute), now.Add(-72*time.Hour))
+	schedule.ScheduleResume("later", now.Add(time.Hour), now.Add(-72*time.Hour))
+
+	var resumed []string
+	var notified []ScheduleEvent
+	resume := func(campaignID string) error {
+		if campaignID == "fails" {
+			return errors.New("API error: 500")
+		}
+		resumed = append(resumed, campaignID)
+		return nil
+	}
+	notify := func(event ScheduleEvent) { notified = append(notified, event) }
+
+	events := schedule.RunDue(now, resume, notify)
+	if len(events) != 2 || !reflect.DeepEqual(events, notified) {
+		t.Fatalf("Expected a notification for each due resume, got %+v and %+v", events, notified)
+	}
+	if !reflect.DeepEqual(resumed, []string{"ok"}) {
+		t.Errorf("Expected only the due campaign to be resumed, got %v", resumed)
+	}
+	if events[1].CampaignID != "fails" || events[1].Err == nil {
+		t.Errorf("Expected the failure to be reported, got %+v", events[1])
+	}
+
+	// The failed resume stays scheduled with its error, the successful one is removed
+	if len(schedule.Resumes) != 2 || schedule.Resumes[0].CampaignID != "fails" || schedule.Resumes[1].CampaignID != "later" {
+		t.Fatalf("Unexpected schedule after run: %+v", schedule.Resumes)
+	}
+	if schedule.Resumes[0].Attempts != 1 || schedule.Resumes[0].LastError != "API error: 500" {
+		t.Errorf("Expected the failure to be recorded, got %+v", schedule.Resumes[0])
+	}
+
+	// Once the clock passes the last entry, the retry and the later resume both fire
+	resume = func(campaignID string) error {
+		resumed = append(resumed, campaignID)
+		return nil
+	}
+	schedule.RunDue(now.Add(2*time.Hour), resume, nil)
+	if !reflect.DeepEqual(resumed, []string{"ok", "fails", "later"}) || len(schedule.Resumes) != 0 {
+		t.Errorf("Expected every resume to be carried out, got %v with %+v left", resumed, schedule.Resumes)
+	}
+}
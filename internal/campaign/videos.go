@@ -0,0 +1,122 @@
+package campaign
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// maxVideoSize is the largest video accepted by a single-request upload to act_<id>/advideos
+const maxVideoSize = 1 << 30
+
+// defaultVideoCallToAction is the button of video ads with a link but no call_to_action,
+// since video_data only takes the link as part of a call to action
+const defaultVideoCallToAction = "LEARN_MORE"
+
+// UploadAdVideo uploads a video to the ad account's video library and returns its ID,
+// which creatives reference as video_id
+func (c *CampaignCreator) UploadAdVideo(path string) (string, error) {
+	return c.UploadAdVideoContext(context.Background(), path)
+}
+
+// UploadAdVideoContext uploads a video like UploadAdVideo. Local files are streamed from
+// disk; for http(s) URLs Facebook downloads the video itself. Uploads can take much longer
+// than other requests, so they are bounded by ctx only, not by the auth client's timeout.
+func (c *CampaignCreator) UploadAdVideoContext(ctx context.Context, path string) (string, error) {
+	fields := url.Values{}
+	var file *uploadFile
+
+	if IsRemoteImage(path) {
+		fields.Set("file_url", path)
+	} else {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", fmt.Errorf("error reading video: %w", err)
+		}
+		if info.Size() > maxVideoSize {
+			return "", fmt.Errorf("video %s is larger than %d MB", path, maxVideoSize>>20)
+		}
+		name := filepath.Base(path)
+		fields.Set("name", name)
+		file = &uploadFile{field: "source", name: name, size: info.Size(), open: func() (io.ReadCloser, error) {
+			return os.Open(path)
+		}}
+	}
+
+	uploadClient := &http.Client{Transport: c.httpClient.Transport}
+	respBody, err := c.postFile(ctx, uploadClient, "advideos", file, fields)
+	if err != nil {
+		return "", fmt.Errorf("error uploading video %s: %w", path, err)
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("error parsing response: %w - %s", err, string(respBody))
+	}
+	if result.ID == "" {
+		return "", fmt.Errorf("no video ID in the upload response: %s", string(respBody))
+	}
+	return result.ID, nil
+}
+
+// videoStoryData builds the video_data of a video ad's object story spec. Facebook requires
+// a thumbnail, and the link of a video ad is the target of its call to action.
+func videoStoryData(config models.CreativeConfig, destinationType string) (map[string]interface{}, error) {
+	videoData := map[string]interface{}{
+		"video_id": config.VideoID,
+	}
+
+	title := config.Title
+	if title == "" {
+		title = config.Name
+	}
+	if title != "" {
+		videoData["title"] = title
+	}
+	if config.Body != "" {
+		videoData["message"] = config.Body
+	}
+
+	switch {
+	case config.ImageHash != "":
+		videoData["image_hash"] = config.ImageHash
+	case IsRemoteImage(config.ImageURL):
+		videoData["image_url"] = config.ImageURL
+	default:
+		return nil, fmt.Errorf("video creatives need a thumbnail: set image_url or image_hash")
+	}
+
+	if IsMessagingDestination(destinationType) {
+		videoData["call_to_action"] = messagingCallToAction(config, destinationType)
+		if config.PageWelcomeMessage != "" {
+			videoData["page_welcome_message"] = config.PageWelcomeMessage
+		}
+		return videoData, nil
+	}
+
+	if config.LinkURL == "" {
+		if config.CallToAction != "" {
+			return nil, fmt.Errorf("call_to_action %s of a video creative needs a link_url", config.CallToAction)
+		}
+		return videoData, nil
+	}
+
+	ctaType := config.CallToAction
+	if ctaType == "" {
+		ctaType = defaultVideoCallToAction
+	}
+	videoData["call_to_action"] = map[string]interface{}{
+		"type":  ctaType,
+		"value": map[string]string{"link": config.LinkURL},
+	}
+	return videoData, nil
+}
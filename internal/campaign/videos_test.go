@@ -0,0 +1,197 @@
+package campaign
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+func TestCreativeParams_VideoData(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      models.CreativeConfig
+		destination string
+		expected    string
+		expectError string
+	}{
+		{
+			name:     "link becomes the call to action",
+			config:   models.CreativeConfig{PageID: "1", VideoID: "v1", Title: "Trailer", Body: "Watch", ImageHash: "abc", LinkURL: "https://example.com"},
+			expected: `{"call_to_action":{"type":"LEARN_MORE","value":{"link":"https://example.com"}},"image_hash":"abc","message":"Watch","title":"Trailer","video_id":"v1"}`,
+		},
+		{
+			name:     "remote thumbnail and button",
+			config:   models.CreativeConfig{PageID: "1", VideoID: "v1", ImageURL: "https://cdn.example.com/thumb.jpg", LinkURL: "https://example.com", CallToAction: "SHOP_NOW"},
+			expected: `{"call_to_action":{"type":"SHOP_NOW","value":{"link":"https://example.com"}},"image_url":"https://cdn.example.com/thumb.jpg","video_id":"v1"}`,
+		},
+		{
+			name:     "no link",
+			config:   models.CreativeConfig{PageID: "1", VideoID: "v1", ImageHash: "abc"},
+			expected: `{"image_hash":"abc","video_id":"v1"}`,
+		},
+		{
+			name:        "messaging",
+			config:      models.CreativeConfig{PageID: "1", VideoID: "v1", ImageHash: "abc"},
+			destination: DestinationMessenger,
+			expected:    `{"call_to_action":{"type":"MESSAGE_PAGE","value":{"app_destination":"MESSENGER"}},"image_hash":"abc","video_id":"v1"}`,
+		},
+		{
+			name:        "missing thumbnail",
+			config:      models.CreativeConfig{PageID: "1", VideoID: "v1", LinkURL: "https://example.com"},
+			expectError: "thumbnail",
+		},
+		{
+			name:        "button without link",
+			config:      models.CreativeConfig{PageID: "1", VideoID: "v1", ImageHash: "abc", CallToAction: "SHOP_NOW"},
+			expectError: "needs a link_url",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := creativeParams(tt.config, tt.destination)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("creativeParams failed: %v", err)
+			}
+
+			var spec map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(params.Get("object_story_spec")), &spec); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := spec["link_data"]; ok {
+				t.Error("Expected no link_data in a video creative")
+			}
+			if got := string(spec["video_data"]); got != tt.expected {
+				t.Errorf("video_data = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUploadAdVideo(t *testing.T) {
+	videoPath := filepath.Join(t.TempDir(), "trailer.mp4")
+	if err := os.WriteFile(videoPath, []byte("video-bytes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var uploads []string
+	authClient := auth.NewFacebookAuth("app", "secret", "token", "v22.0")
+	authClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodPost || !strings.HasSuffix(req.URL.Path, "/act_123/advideos") {
+			t.Fatalf("Unexpected request %s %s", req.Method, req.URL.Path)
+		}
+
+		_, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil {
+			return nil, err
+		}
+		reader := multipart.NewReader(req.Body, params["boundary"])
+		var fields []string
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			data, _ := io.ReadAll(part)
+			fields = append(fields, part.FormName()+"="+string(data))
+		}
+		uploads = append(uploads, strings.Join(fields, "&"))
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"id":"v42"}`)),
+		}, nil
+	})
+	creator := NewCampaignCreator(authClient, "123")
+
+	for _, path := range []string{videoPath, "https://cdn.example.com/trailer.mp4"} {
+		id, err := creator.UploadAdVideo(path)
+		if err != nil {
+			t.Fatalf("UploadAdVideo(%s) failed: %v", path, err)
+		}
+		if id != "v42" {
+			t.Errorf("Expected video ID v42, got %s", id)
+		}
+	}
+
+	// Local files are sent as the source, URLs are left for Facebook to download
+	expected := "name=trailer.mp4&source=video-bytes,file_url=https://cdn.example.com/trailer.mp4"
+	if got := strings.Join(uploads, ","); got != expected {
+		t.Errorf("Uploads = %s, want %s", got, expected)
+	}
+
+	if _, err := creator.UploadAdVideo(filepath.Join(t.TempDir(), "missing.mp4")); err == nil {
+		t.Error("Expected an error for a missing video file")
+	}
+}
+
+func TestUploadAdVideo_StreamsAndRetries(t *testing.T) {
+	content := strings.Repeat("frame", 1000)
+	videoPath := filepath.Join(t.TempDir(), "trailer.mp4")
+	if err := os.WriteFile(videoPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	attempts := 0
+	authClient := auth.NewFacebookAuth("app", "secret", "token", "v22.0")
+	authClient.Retry = auth.RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	authClient.Timeout = time.Minute
+	authClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if _, ok := req.Context().Deadline(); ok {
+			t.Errorf("Attempt %d: expected no request timeout on uploads", attempts)
+		}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(body)) != req.ContentLength {
+			t.Errorf("Attempt %d: Content-Length %d, body %d bytes", attempts, req.ContentLength, len(body))
+		}
+		if !strings.Contains(string(body), content) {
+			t.Errorf("Attempt %d: expected the whole video in the body", attempts)
+		}
+
+		// The first attempt is rate limited, so the file has to be sent again
+		if attempts == 1 {
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(`{"error":{"message":"Too many calls","code":4}}`)),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"id":"v43"}`)),
+		}, nil
+	})
+
+	id, err := NewCampaignCreator(authClient, "123").UploadAdVideo(videoPath)
+	if err != nil {
+		t.Fatalf("UploadAdVideo failed: %v", err)
+	}
+	if id != "v43" || attempts != 2 {
+		t.Errorf("Expected video v43 after 2 attempts, got %q after %d", id, attempts)
+	}
+}
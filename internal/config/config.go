@@ -2,19 +2,232 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
 )
 
+// DefaultProfileName is the profile used when none is selected. A config file without
+// profiles holds its credentials at the top level, which makes up this profile.
+const DefaultProfileName = "default"
+
+// profilesDirName is the directory next to the config file that holds one file per profile
+const profilesDirName = "profiles"
+
 // Config holds the application configuration
 type Config struct {
-	APIVersion     string `json:"api_version"`
-	AccessToken    string `json:"access_token"`
+	APIVersion      string                   `json:"api_version"`
+	MinAPIVersion   string                   `json:"min_api_version,omitempty"` // Oldest api_version accepted, auth.MinAPIVersion when empty
+	AccessToken     string                   `json:"access_token"`
+	TokenExpiresAt  string                   `json:"token_expires_at,omitempty"` // RFC3339 expiry of the access token, empty when unknown
+	AppID           string                   `json:"app_id"`
+	AppSecret       string                   `json:"app_secret"`
+	AccountID       string                   `json:"account_id"`
+	ConfigDir       string                   `json:"config_dir"`
+	OutputFormat    string                   `json:"output_format"`
+	Recommendations RecommendationThresholds `json:"recommendations"`
+	Limits          AccountLimits            `json:"limits"`
+	Retry           RetryPolicy              `json:"retry"`
+	AudienceCache   AudienceCachePolicy      `json:"audience_cache"`
+	Statistics      StatisticsStorage        `json:"statistics"`
+
+	// Value of one conversion, e.g. the average order value, used for ROAS and ROI when
+	// Facebook doesn't report conversion values; per-campaign values override it by ID
+	ConversionValue          float64            `json:"conversion_value"`
+	CampaignConversionValues map[string]float64 `json:"campaign_conversion_values,omitempty"`
+
+	// SMTP server and recipients reports are mailed to with report --email
+	Email EmailSettings `json:"email"`
+
+	// Jobs run by schedule daemon
+	Scheduler SchedulerSettings `json:"scheduler"`
+
+	// Named credentials of further ad accounts, selected with UseProfile. Profiles are
+	// stored in profiles/<name>.json next to the config file; those kept here are read
+	// for compatibility with files written before.
+	Profiles       map[string]Profile `json:"profiles,omitempty"`
+	DefaultProfile string             `json:"default_profile,omitempty"`
+
+	// Profile is the name of the profile in use, set by UseProfile
+	Profile string `json:"-"`
+
+	// Profiles stored in their own files, which take precedence over those in Profiles
+	profileFiles map[string]Profile
+}
+
+// Profile holds the credentials of one ad account
+type Profile struct {
 	AppID          string `json:"app_id"`
 	AppSecret      string `json:"app_secret"`
+	AccessToken    string `json:"access_token"`
+	TokenExpiresAt string `json:"token_expires_at,omitempty"`
 	AccountID      string `json:"account_id"`
-	ConfigDir      string `json:"config_dir"`
-	OutputFormat   string `json:"output_format"`
+	APIVersion     string `json:"api_version,omitempty"`
+}
+
+// RecommendationThresholds controls when report recommendations are emitted
+type RecommendationThresholds struct {
+	// Spend (in dollars) above which a campaign without conversions is flagged for pausing
+	HighSpendNoConversions float64 `json:"high_spend_no_conversions"`
+
+	// CTR (in percent) below which a campaign is flagged for creative changes
+	LowCTRPercent float64 `json:"low_ctr_percent"`
+
+	// Impressions a campaign needs beyond which its CTR is judged
+	LowCTRMinImpressions int `json:"low_ctr_min_impressions"`
+
+	// ROAS above which a campaign is recommended for more budget
+	HighROAS float64 `json:"high_roas"`
+
+	// Conversions a campaign needs beyond which its ROAS is judged
+	HighROASMinConversions int `json:"high_roas_min_conversions"`
+}
+
+// DefaultRecommendationThresholds returns the thresholds used when none are configured
+func DefaultRecommendationThresholds() RecommendationThresholds {
+	return RecommendationThresholds{
+		HighSpendNoConversions: 100,
+		LowCTRPercent:          0.5,
+		LowCTRMinImpressions:   1000,
+		HighROAS:               3.0,
+		HighROASMinConversions: 5,
+	}
+}
+
+// AccountLimits caps the number of objects kept in the ad account.
+// Facebook rejects creates beyond its own limits, so runs that would cross them are refused up front.
+type AccountLimits struct {
+	// Maximum number of non-archived, non-deleted campaigns
+	MaxCampaigns int `json:"max_campaigns"`
+
+	// Maximum number of non-archived, non-deleted ad sets
+	MaxAdSets int `json:"max_adsets"`
+}
+
+// DefaultAccountLimits returns the Facebook ad account limits
+func DefaultAccountLimits() AccountLimits {
+	return AccountLimits{
+		MaxCampaigns: 5000,
+		MaxAdSets:    10000,
+	}
+}
+
+// RetryPolicy controls how requests rejected by Facebook rate limits are retried
+type RetryPolicy struct {
+	// Retries after the first attempt; 0 disables retrying
+	MaxRetries int `json:"max_retries"`
+
+	// Seconds before the first retry, doubled for each further retry
+	BaseDelaySeconds float64 `json:"base_delay_seconds"`
+
+	// Upper bound in seconds for a single delay
+	MaxDelaySeconds float64 `json:"max_delay_seconds"`
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:       3,
+		BaseDelaySeconds: 2,
+		MaxDelaySeconds:  60,
+	}
+}
+
+// AudienceCachePolicy controls how long researched audience segments are reused between runs
+type AudienceCachePolicy struct {
+	// Hours a cached segment stays valid; 0 keeps segments until the cache is full
+	TTLHours float64 `json:"ttl_hours"`
+}
+
+// DefaultAudienceCachePolicy returns the cache policy used when none is configured
+func DefaultAudienceCachePolicy() AudienceCachePolicy {
+	return AudienceCachePolicy{
+		TTLHours: 7 * 24,
+	}
+}
+
+// StatisticsStorage controls where collected campaign statistics are kept
+type StatisticsStorage struct {
+	// "file" keeps daily statistics as JSON files, "memory" only for the running command
+	Storage string `json:"storage"`
+
+	// Directory of the statistics files; empty uses the stats directory under the config dir
+	Dir string `json:"dir,omitempty"`
+}
+
+// DefaultStatisticsStorage returns the statistics storage used when none is configured
+func DefaultStatisticsStorage() StatisticsStorage {
+	return StatisticsStorage{
+		Storage: "file",
+	}
+}
+
+// Connection security of the SMTP server
+const (
+	EmailTLSStartTLS = "starttls" // Plain connection upgraded with STARTTLS, usually port 587
+	EmailTLSImplicit = "tls"      // TLS from the start, usually port 465
+	EmailTLSNone     = "none"     // Unencrypted, e.g. a relay on localhost
+)
+
+// EmailSettings holds the SMTP server and recipients of mailed reports
+type EmailSettings struct {
+	Host     string   `json:"smtp_host"`
+	Port     int      `json:"smtp_port"`
+	Username string   `json:"smtp_username,omitempty"`
+	Password string   `json:"smtp_password,omitempty"` // FBADS_SMTP_PASSWORD overrides it
+	TLS      string   `json:"tls"`                     // starttls, tls or none
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// DefaultEmailSettings returns the email settings used when none are configured
+func DefaultEmailSettings() EmailSettings {
+	return EmailSettings{
+		Port: 587,
+		TLS:  EmailTLSStartTLS,
+	}
+}
+
+// Tasks of scheduled jobs
+const (
+	JobTaskReport = "report" // Generate a daily or weekly report, optionally mailed
+	JobTaskPrune  = "prune"  // Pause campaigns that trigger deactivation rules
+	JobTaskResume = "resume" // Resume campaigns whose scheduled resume is due
+)
+
+// SchedulerSettings holds the jobs schedule daemon runs
+type SchedulerSettings struct {
+	Jobs []ScheduledJob `json:"jobs"`
+}
+
+// ScheduledJob is a task run daily at a time of day in the ad account timezone, or at an
+// interval. Report and prune options apply to their task only.
+type ScheduledJob struct {
+	Name  string `json:"name"`
+	Task  string `json:"task"`            // report, prune or resume
+	At    string `json:"at,omitempty"`    // Daily time as HH:MM
+	Every string `json:"every,omitempty"` // Interval such as 30m or 6h
+
+	Report string `json:"report,omitempty"` // daily (default) or weekly
+	Format string `json:"format,omitempty"` // json (default), csv or html
+	Email  bool   `json:"email,omitempty"`  // Mail the report with the email settings
+
+	Rules  string `json:"rules,omitempty"`   // Deactivation rules file; empty uses the built-in rules
+	Since  string `json:"since,omitempty"`   // Period checked, e.g. 7d (default)
+	DryRun bool   `json:"dry_run,omitempty"` // Only log what would be paused
+}
+
+// StatisticsDir returns the directory statistics are stored in
+func (c *Config) StatisticsDir() string {
+	if c.Statistics.Dir != "" {
+		return c.Statistics.Dir
+	}
+	return filepath.Join(c.ConfigDir, "stats")
 }
 
 // DefaultConfig returns a config with default values
@@ -22,26 +235,232 @@ func DefaultConfig() *Config {
 	homeDir, _ := os.UserHomeDir()
 	
 	return &Config{
-		APIVersion:   "v22.0",
-		ConfigDir:    filepath.Join(homeDir, ".fbads"),
-		OutputFormat: "json",
+		APIVersion:      auth.DefaultAPIVersion,
+		ConfigDir:       filepath.Join(homeDir, ".fbads"),
+		OutputFormat:    "json",
+		Recommendations: DefaultRecommendationThresholds(),
+		Limits:          DefaultAccountLimits(),
+		Retry:           DefaultRetryPolicy(),
+		AudienceCache:   DefaultAudienceCachePolicy(),
+		Statistics:      DefaultStatisticsStorage(),
+		ConversionValue: models.DefaultConversionValue,
+		Email:           DefaultEmailSettings(),
 	}
 }
 
-// LoadConfig loads configuration from a file
+// ConversionValues returns the configured values of a conversion
+func (c *Config) ConversionValues() models.ConversionValues {
+	return models.ConversionValues{Default: c.ConversionValue, Campaigns: c.CampaignConversionValues}
+}
+
+// LoadConfig loads configuration from a file, along with the profiles stored in the
+// profiles directory next to it. The profiles are loaded even when the file doesn't exist.
 func LoadConfig(path string) (*Config, error) {
 	cfg := DefaultConfig()
 	
 	data, err := os.ReadFile(path)
-	if err != nil {
+	if err == nil {
+		err = json.Unmarshal(data, cfg)
+	}
+	if err != nil && !os.IsNotExist(err) {
 		return cfg, err
 	}
-	
-	err = json.Unmarshal(data, cfg)
+
+	if profileErr := cfg.loadProfileFiles(ProfilesDir(path)); profileErr != nil {
+		return cfg, profileErr
+	}
 	return cfg, err
 }
 
-// SaveConfig saves configuration to a file
+// LoadProfile loads the configuration at path with the credentials of the named profile
+// applied, or those of the default profile if name is empty
+func LoadProfile(path, name string) (*Config, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil && !os.IsNotExist(err) {
+		return cfg, err
+	}
+	if err := cfg.UseProfile(name); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// ListProfiles returns the names of the profiles of the configuration at path in
+// alphabetical order, both those in their own files and those in the config file
+func ListProfiles(path string) ([]string, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return cfg.ProfileNames(), nil
+}
+
+// ProfilesDir returns the directory holding the profiles of the config file at path
+func ProfilesDir(path string) string {
+	return filepath.Join(filepath.Dir(path), profilesDirName)
+}
+
+// ProfilePath returns the file the named profile of the config file at path is stored in
+func ProfilePath(path, name string) string {
+	return filepath.Join(ProfilesDir(path), name+".json")
+}
+
+// ValidateProfileName checks that a profile name can be used as a file name
+func ValidateProfileName(name string) error {
+	if name == "" {
+		return fmt.Errorf("profile name is empty")
+	}
+	if strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid profile name %q: must not contain path separators or start with a dot", name)
+	}
+	return nil
+}
+
+// loadProfileFiles reads the profiles stored as <name>.json in dir. A missing directory
+// holds no profiles.
+func (c *Config) loadProfileFiles(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading profiles: %w", err)
+	}
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok || ValidateProfileName(name) != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("error reading profile %s: %w", name, err)
+		}
+		var profile Profile
+		if err := json.Unmarshal(data, &profile); err != nil {
+			return fmt.Errorf("error parsing profile %s: %w", name, err)
+		}
+		if c.profileFiles == nil {
+			c.profileFiles = make(map[string]Profile)
+		}
+		c.profileFiles[name] = profile
+	}
+	return nil
+}
+
+// storedProfile returns the named profile from its own file or from the config file
+func (c *Config) storedProfile(name string) (Profile, bool) {
+	if profile, ok := c.profileFiles[name]; ok {
+		return profile, true
+	}
+	profile, ok := c.Profiles[name]
+	return profile, ok
+}
+
+// UseProfile applies the credentials of the named profile, or of the default profile if
+// name is empty. The default profile falls back to the top-level credentials when the
+// profiles don't define it, so config files written before profiles keep working. The
+// applied credentials replace the top-level ones, so changes to the file are made on a
+// freshly loaded config.
+func (c *Config) UseProfile(name string) error {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		name = DefaultProfileName
+	}
+
+	profile, ok := c.storedProfile(name)
+	if !ok {
+		if name != DefaultProfileName {
+			return fmt.Errorf("profile %q not found (available: %v)", name, c.ProfileNames())
+		}
+		c.Profile = name
+		return nil
+	}
+
+	c.AppID = profile.AppID
+	c.AppSecret = profile.AppSecret
+	c.AccessToken = profile.AccessToken
+	c.TokenExpiresAt = profile.TokenExpiresAt
+	c.AccountID = profile.AccountID
+	if profile.APIVersion != "" {
+		c.APIVersion = profile.APIVersion
+	}
+	c.Profile = name
+	return nil
+}
+
+// GetProfile returns the credentials stored for the named profile, and whether it exists
+func (c *Config) GetProfile(name string) (Profile, bool) {
+	if profile, ok := c.storedProfile(name); ok {
+		return profile, true
+	}
+	if name != DefaultProfileName {
+		return Profile{}, false
+	}
+
+	profile := Profile{
+		AppID:          c.AppID,
+		AppSecret:      c.AppSecret,
+		AccessToken:    c.AccessToken,
+		TokenExpiresAt: c.TokenExpiresAt,
+		AccountID:      c.AccountID,
+	}
+	return profile, profile != Profile{}
+}
+
+// SetProfile stores the credentials of the named profile, saved to its own file by
+// SaveConfig. Profiles already kept in the config file stay there, as do legacy
+// credentials at the top level, which leaves those files readable by older versions.
+func (c *Config) SetProfile(name string, profile Profile) {
+	if _, ok := c.profileFiles[name]; !ok {
+		if _, ok := c.Profiles[name]; ok {
+			c.Profiles[name] = profile
+			return
+		}
+		if _, legacy := c.GetProfile(name); legacy && name == DefaultProfileName {
+			c.AppID = profile.AppID
+			c.AppSecret = profile.AppSecret
+			c.AccessToken = profile.AccessToken
+			c.TokenExpiresAt = profile.TokenExpiresAt
+			c.AccountID = profile.AccountID
+			if profile.APIVersion != "" {
+				c.APIVersion = profile.APIVersion
+			}
+			return
+		}
+	}
+
+	if c.profileFiles == nil {
+		c.profileFiles = make(map[string]Profile)
+	}
+	c.profileFiles[name] = profile
+}
+
+// ProfileNames returns the names of the stored profiles in alphabetical order, including
+// the implicit default profile when the top level holds credentials
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.profileFiles)+len(c.Profiles)+1)
+	for name := range c.profileFiles {
+		names = append(names, name)
+	}
+	for name := range c.Profiles {
+		if _, ok := c.profileFiles[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	if _, ok := c.storedProfile(DefaultProfileName); !ok {
+		if _, ok := c.GetProfile(DefaultProfileName); ok {
+			names = append(names, DefaultProfileName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SaveConfig saves configuration to a file, and each profile stored in its own file to
+// the profiles directory next to it
 func (c *Config) SaveConfig(path string) error {
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
@@ -55,5 +474,33 @@ func (c *Config) SaveConfig(path string) error {
 		}
 	}
 	
-	return os.WriteFile(path, data, 0644)
-}
\ No newline at end of file
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	return c.saveProfileFiles(ProfilesDir(path))
+}
+
+// saveProfileFiles writes each profile stored in its own file to dir, readable by the
+// owner only since profiles hold credentials
+func (c *Config) saveProfileFiles(dir string) error {
+	if len(c.profileFiles) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("error creating profiles directory: %w", err)
+	}
+
+	for name, profile := range c.profileFiles {
+		if err := ValidateProfileName(name); err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(profile, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling profile %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".json"), data, 0600); err != nil {
+			return fmt.Errorf("error writing profile %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUseProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	legacy := `{"app_id":"app","access_token":"token","account_id":"111","api_version":"v21.0"}`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	cfg.SetProfile("work", Profile{AppID: "work-app", AccessToken: "work-token", AccountID: "222"})
+	if err := cfg.SaveConfig(path); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		profile        string
+		defaultProfile string
+		accountID      string
+		apiVersion     string
+		expectError    bool
+	}{
+		{name: "legacy credentials as default", accountID: "111", apiVersion: "v21.0"},
+		{name: "named profile", profile: "work", accountID: "222", apiVersion: "v21.0"},
+		{name: "default profile key", defaultProfile: "work", accountID: "222", apiVersion: "v21.0"},
+		{name: "explicit default", profile: "default", defaultProfile: "work", accountID: "111", apiVersion: "v21.0"},
+		{name: "unknown profile", profile: "home", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig failed: %v", err)
+			}
+			cfg.DefaultProfile = tt.defaultProfile
+
+			err = cfg.UseProfile(tt.profile)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Expected an error for profile %q", tt.profile)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UseProfile failed: %v", err)
+			}
+			if cfg.AccountID != tt.accountID || cfg.APIVersion != tt.apiVersion {
+				t.Errorf("Expected account %s on %s, got %s on %s", tt.accountID, tt.apiVersion, cfg.AccountID, cfg.APIVersion)
+			}
+		})
+	}
+
+	cfg, _ = LoadConfig(path)
+	if names := cfg.ProfileNames(); !reflect.DeepEqual(names, []string{"default", "work"}) {
+		t.Errorf("Expected profiles [default work], got %v", names)
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	stored := `{"api_version":"v22.0","profiles":{"work":{"access_token":"old","account_id":"111"},"home":{"access_token":"home","account_id":"333"}}}`
+	if err := os.WriteFile(path, []byte(stored), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	cfg.SetProfile(DefaultProfileName, Profile{AccessToken: "token", AccountID: "100"})
+	cfg.SetProfile("client-b", Profile{AccessToken: "b", AccountID: "222", APIVersion: "v21.0"})
+	if err := cfg.SaveConfig(path); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+	if err := os.WriteFile(ProfilePath(path, "work"), []byte(`{"access_token":"new","account_id":"444"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{DefaultProfileName, "client-b"} {
+		if _, err := os.Stat(ProfilePath(path, name)); err != nil {
+			t.Errorf("Expected profile %s in its own file: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		profile    string
+		accountID  string
+		apiVersion string
+	}{
+		{name: "default profile file", accountID: "100", apiVersion: "v22.0"},
+		{name: "own API version", profile: "client-b", accountID: "222", apiVersion: "v21.0"},
+		{name: "file before config", profile: "work", accountID: "444", apiVersion: "v22.0"},
+		{name: "kept in config", profile: "home", accountID: "333", apiVersion: "v22.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadProfile(path, tt.profile)
+			if err != nil {
+				t.Fatalf("LoadProfile failed: %v", err)
+			}
+			if cfg.AccountID != tt.accountID || cfg.APIVersion != tt.apiVersion {
+				t.Errorf("Expected account %s on %s, got %s on %s", tt.accountID, tt.apiVersion, cfg.AccountID, cfg.APIVersion)
+			}
+		})
+	}
+
+	if _, err := LoadProfile(path, "missing"); err == nil {
+		t.Error("Expected an error for an unknown profile")
+	}
+
+	names, err := ListProfiles(path)
+	if err != nil {
+		t.Fatalf("ListProfiles failed: %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"client-b", "default", "home", "work"}) {
+		t.Errorf("Expected profiles [client-b default home work], got %v", names)
+	}
+
+	// Profiles are found without a config file
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := LoadProfile(path, "client-b"); err != nil || cfg.AccountID != "222" {
+		t.Errorf("Expected the profile file without a config file, got %v", err)
+	}
+}
+
+func TestValidateProfileName(t *testing.T) {
+	for _, name := range []string{"work", "client-b", "default"} {
+		if err := ValidateProfileName(name); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "../work", "a/b", `a\b`, ".hidden"} {
+		if err := ValidateProfileName(name); err == nil {
+			t.Errorf("Expected %q to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,1072 @@
+package demo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// campaign is a demo campaign with the baseline its insights are generated from
+type campaign struct {
+	ID             string
+	Name           string
+	Status         models.CampaignStatus
+	Objective      models.Objective
+	BidStrategy    models.BidStrategy
+	DailyBudget    int64 // Cents, as the API returns budgets
+	LifetimeBudget int64
+	SpendCap       int64
+	Labels         []string
+	Created        time.Time
+	Updated        time.Time
+	StartTime      time.Time
+	StopTime       time.Time
+	PausedAt       time.Time // Delivery stops from this day on; zero while running
+
+	CPM float64 // Baseline cost per 1000 impressions in dollars
+	CTR float64 // Baseline clicks per impression
+	CVR float64 // Baseline conversions per click
+}
+
+// adSet is a demo ad set
+type adSet struct {
+	ID               string
+	CampaignID       string
+	Name             string
+	Status           models.CampaignStatus
+	OptimizationGoal models.OptimizationGoal
+	BillingEvent     models.BillingEvent
+	BidAmount        int64
+	DestinationType  string
+	Targeting        map[string]interface{}
+	StartTime        time.Time
+	EndTime          time.Time
+	Labels           []string
+	LearningStage    string  // learning_stage_info status: LEARNING, SUCCESS or FAIL
+	Weight           float64 // Share of the campaign delivery relative to its sibling ad sets
+}
+
+// ad is a demo ad
+type ad struct {
+	ID         string
+	AdSetID    string
+	CampaignID string
+	Name       string
+	Status     models.CampaignStatus
+	CreativeID string
+	Labels     []string
+	Weight     float64 // Share of the ad set delivery relative to its sibling ads
+	CTRFactor  float64 // CTR relative to the campaign baseline, 0 means 1
+}
+
+// creative is a demo ad creative
+type creative struct {
+	ID               string
+	Name             string
+	Title            string
+	Body             string
+	LinkURL          string
+	ImageHash        string
+	VideoID          string
+	Cards            []models.CreativeCard // Cards of a carousel ad
+	CallToActionType string
+	PageID           string
+}
+
+// page is a demo Facebook Page
+type page struct {
+	ID       string
+	Name     string
+	Category string
+}
+
+// activity is a change in the demo account activity log
+type activity struct {
+	Time       time.Time
+	EventType  string
+	ActorName  string
+	ObjectID   string
+	ObjectName string
+	ObjectType string
+	OldValue   interface{}
+	NewValue   interface{}
+}
+
+// demoActor is the name changes made through the provider are logged under
+const demoActor = "You (demo)"
+
+// seed fills the account with sample objects dated relative to today
+func (p *Provider) seed() {
+	today := p.today()
+	days := func(n int) time.Time { return today.AddDate(0, 0, n).Add(10 * time.Hour) }
+
+	p.pages = []page{
+		{ID: "104000000000001", Name: "Acme Outdoor", Category: "Sporting Goods Store"},
+		{ID: "104000000000002", Name: "Acme Outdoor Outlet", Category: "Outlet Store"},
+	}
+	storePage := p.pages[0].ID
+
+	// The spring sale hero was created separately in both spring campaigns,
+	// so creative rollups show it as one creative across campaigns
+	springHero := func(id string) *creative {
+		return &creative{
+			ID: id, Name: "Spring Sale - Hero", Title: "Spring Sale: 30% off tents",
+			Body: "Gear up for the season. Free shipping over $50.", LinkURL: "https://acme-outdoor.example/spring",
+			ImageHash: "a1b2c3d4e5f60718293a4b5c6d7e8f90", CallToActionType: "SHOP_NOW", PageID: storePage,
+		}
+	}
+	p.creatives = []*creative{
+		springHero("120200000000131"),
+		{ID: "120200000000132", Name: "Spring Sale - Carousel", Title: "Tents, packs and more", Body: "Everything for your next trip, 30% off.",
+			LinkURL: "https://acme-outdoor.example/spring/gear", ImageHash: "0f1e2d3c4b5a69788796a5b4c3d2e1f0", CallToActionType: "SHOP_NOW", PageID: storePage},
+		springHero("120200000000133"),
+		springHero("120200000000231"),
+		{ID: "120200000000232", Name: "Spring Sale - Reminder", Title: "Still thinking it over?", Body: "Your tent is waiting. The sale ends Sunday.",
+			LinkURL: "https://acme-outdoor.example/cart", ImageHash: "9a8b7c6d5e4f30211203f4e5d6c7b8a9", CallToActionType: "SHOP_NOW", PageID: storePage},
+		{ID: "120200000000331", Name: "Trail Shoes - Lifestyle", Title: "Run further on any trail", Body: "Meet the Ridgeback 3, now with better grip.",
+			LinkURL: "https://acme-outdoor.example/trail-shoes", ImageHash: "1234abcd5678ef901234abcd5678ef90", CallToActionType: "LEARN_MORE", PageID: storePage},
+		{ID: "120200000000431", Name: "Newsletter - Lead Form", Title: "Get 10% off your first order", Body: "Join 50,000 hikers getting our weekly trail tips.",
+			LinkURL: "https://acme-outdoor.example/newsletter", ImageHash: "abcdef0123456789abcdef0123456789", CallToActionType: "SIGN_UP", PageID: storePage},
+		{ID: "120200000000531", Name: "Brand Film", Title: "Made for the outdoors", Body: "Forty years of gear built to last.",
+			LinkURL: "https://acme-outdoor.example/story", ImageHash: "fedcba9876543210fedcba9876543210", CallToActionType: "WATCH_MORE", PageID: storePage},
+		{ID: "120200000000631", Name: "Winter Clearance", Title: "Winter clearance: up to 50% off", Body: "Last chance on jackets and boots.",
+			LinkURL: "https://acme-outdoor.example/clearance", ImageHash: "00112233445566778899aabbccddeeff", CallToActionType: "SHOP_NOW", PageID: p.pages[1].ID},
+	}
+
+	p.campaigns = []*campaign{
+		{ID: "120200000000101", Name: "Spring Sale - Prospecting", Status: models.CampaignStatusActive, Objective: models.ObjectiveSales,
+			BidStrategy: models.BidStrategyLowestCostWithoutCap, DailyBudget: 6000, Labels: []string{"spring-sale", "exp:landing-page:a"},
+			Created: days(-45), Updated: days(-3), CPM: 9.5, CTR: 0.014, CVR: 0.035},
+		{ID: "120200000000201", Name: "Spring Sale - Retargeting", Status: models.CampaignStatusActive, Objective: models.ObjectiveSales,
+			BidStrategy: models.BidStrategyCostCap, DailyBudget: 2500, Labels: []string{"spring-sale", "exp:landing-page:b"},
+			Created: days(-30), Updated: days(-30), CPM: 14, CTR: 0.022, CVR: 0.06},
+		{ID: "120200000000301", Name: "Trail Running Shoes - Traffic", Status: models.CampaignStatusActive, Objective: models.ObjectiveTraffic,
+			BidStrategy: models.BidStrategyLowestCostWithoutCap, DailyBudget: 3000, Labels: []string{"fbads:v1"},
+			Created: days(-60), Updated: days(-10), CPM: 7, CTR: 0.018, CVR: 0.012},
+		{ID: "120200000000401", Name: "Newsletter Signup - Leads", Status: models.CampaignStatusActive, Objective: models.ObjectiveLeads,
+			BidStrategy: models.BidStrategyLowestCostWithBidCap, DailyBudget: 1500, SpendCap: 100000,
+			Created: days(-20), Updated: days(-20), CPM: 18, CTR: 0.006, CVR: 0.02},
+		{ID: "120200000000501", Name: "Brand Video - Awareness", Status: models.CampaignStatusPaused, Objective: models.ObjectiveAwareness,
+			BidStrategy: models.BidStrategyLowestCostWithoutCap, LifetimeBudget: 200000,
+			Created: days(-40), Updated: days(-12), StartTime: days(-40), StopTime: days(20), PausedAt: today.AddDate(0, 0, -12),
+			CPM: 4, CTR: 0.004, CVR: 0.001},
+		{ID: "120200000000601", Name: "Winter Clearance", Status: models.CampaignStatusArchived, Objective: models.ObjectiveSales,
+			BidStrategy: models.BidStrategyLowestCostWithoutCap, DailyBudget: 4000, Labels: []string{"winter"},
+			Created: days(-120), Updated: days(-75), StopTime: days(-75), PausedAt: today.AddDate(0, 0, -75),
+			CPM: 11, CTR: 0.016, CVR: 0.04},
+	}
+
+	us := func(ageMin, ageMax int, interests ...string) map[string]interface{} {
+		return targeting([]string{"US"}, ageMin, ageMax, interests...)
+	}
+	p.adSets = []*adSet{
+		{ID: "120200000000111", CampaignID: "120200000000101", Name: "US 25-44 Hiking", Status: models.CampaignStatusActive,
+			OptimizationGoal: models.OptimizationGoalOffsiteConversions, BillingEvent: models.BillingEventImpressions,
+			Targeting: us(25, 44, "6003384248805"), Weight: 3},
+		{ID: "120200000000112", CampaignID: "120200000000101", Name: "CA Broad", Status: models.CampaignStatusActive,
+			OptimizationGoal: models.OptimizationGoalOffsiteConversions, BillingEvent: models.BillingEventImpressions,
+			Targeting: targeting([]string{"CA"}, 18, 65), Weight: 1},
+		{ID: "120200000000211", CampaignID: "120200000000201", Name: "Site Visitors 30d", Status: models.CampaignStatusActive,
+			OptimizationGoal: models.OptimizationGoalOffsiteConversions, BillingEvent: models.BillingEventImpressions,
+			Targeting: us(18, 65), Weight: 1},
+		{ID: "120200000000311", CampaignID: "120200000000301", Name: "US and GB Runners", Status: models.CampaignStatusActive,
+			OptimizationGoal: models.OptimizationGoalLinkClicks, BillingEvent: models.BillingEventImpressions,
+			Targeting: targeting([]string{"US", "GB"}, 18, 54, "6003277229526"), Weight: 1},
+		{ID: "120200000000411", CampaignID: "120200000000401", Name: "US Outdoor Enthusiasts", Status: models.CampaignStatusActive,
+			OptimizationGoal: models.OptimizationGoalLeadGeneration, BillingEvent: models.BillingEventImpressions, BidAmount: 400,
+			Targeting: us(21, 65, "6003384248805", "6003020834693"), LearningStage: "FAIL", Weight: 1},
+		{ID: "120200000000511", CampaignID: "120200000000501", Name: "US Broad Reach", Status: models.CampaignStatusActive,
+			OptimizationGoal: models.OptimizationGoalReach, BillingEvent: models.BillingEventImpressions,
+			Targeting: us(18, 65), Weight: 1},
+		{ID: "120200000000611", CampaignID: "120200000000601", Name: "US Past Buyers", Status: models.CampaignStatusActive,
+			OptimizationGoal: models.OptimizationGoalOffsiteConversions, BillingEvent: models.BillingEventImpressions,
+			Targeting: us(18, 65), Weight: 1},
+	}
+
+	p.ads = []*ad{
+		{ID: "120200000000121", AdSetID: "120200000000111", CampaignID: "120200000000101", Name: "Hero Image", Status: models.CampaignStatusActive, CreativeID: "120200000000131", Weight: 3},
+		{ID: "120200000000122", AdSetID: "120200000000111", CampaignID: "120200000000101", Name: "Carousel", Status: models.CampaignStatusActive, CreativeID: "120200000000132", Weight: 2},
+		{ID: "120200000000123", AdSetID: "120200000000112", CampaignID: "120200000000101", Name: "Hero Image", Status: models.CampaignStatusActive, CreativeID: "120200000000133", Weight: 1},
+		{ID: "120200000000221", AdSetID: "120200000000211", CampaignID: "120200000000201", Name: "Hero Image", Status: models.CampaignStatusActive, CreativeID: "120200000000231", Weight: 1, CTRFactor: 1.6},
+		{ID: "120200000000222", AdSetID: "120200000000211", CampaignID: "120200000000201", Name: "Reminder", Status: models.CampaignStatusActive, CreativeID: "120200000000232", Weight: 2, CTRFactor: 0.6},
+		{ID: "120200000000321", AdSetID: "120200000000311", CampaignID: "120200000000301", Name: "Lifestyle", Status: models.CampaignStatusActive, CreativeID: "120200000000331", Weight: 1},
+		{ID: "120200000000421", AdSetID: "120200000000411", CampaignID: "120200000000401", Name: "Lead Form", Status: models.CampaignStatusActive, CreativeID: "120200000000431", Weight: 1},
+		{ID: "120200000000521", AdSetID: "120200000000511", CampaignID: "120200000000501", Name: "Brand Film", Status: models.CampaignStatusActive, CreativeID: "120200000000531", Weight: 1},
+		{ID: "120200000000621", AdSetID: "120200000000611", CampaignID: "120200000000601", Name: "Clearance", Status: models.CampaignStatusActive, CreativeID: "120200000000631", Weight: 1},
+	}
+
+	p.interests = sampleInterests()
+
+	p.activities = []activity{
+		{Time: days(-75), EventType: "update_campaign_run_status", ActorName: "Alex Chen", ObjectID: "120200000000601",
+			ObjectName: "Winter Clearance", ObjectType: "CAMPAIGN", OldValue: "Active", NewValue: "Archived"},
+		{Time: days(-12), EventType: "update_campaign_run_status", ActorName: "Alex Chen", ObjectID: "120200000000501",
+			ObjectName: "Brand Video - Awareness", ObjectType: "CAMPAIGN", OldValue: "Active", NewValue: "Paused"},
+		{Time: days(-3), EventType: "update_campaign_budget", ActorName: "Jamie Rivera", ObjectID: "120200000000101",
+			ObjectName: "Spring Sale - Prospecting", ObjectType: "CAMPAIGN",
+			OldValue: budgetValue("old_value", 4500), NewValue: budgetValue("new_value", 6000)},
+		{Time: days(-1), EventType: "update_ad_set_target_spec", ActorName: "Jamie Rivera", ObjectID: "120200000000111",
+			ObjectName: "US 25-44 Hiking", ObjectType: "AD_SET"},
+	}
+}
+
+// targeting builds a targeting spec for countries, an age range and interest IDs
+func targeting(countries []string, ageMin, ageMax int, interestIDs ...string) map[string]interface{} {
+	geo := make([]interface{}, len(countries))
+	for i, country := range countries {
+		geo[i] = country
+	}
+
+	spec := map[string]interface{}{
+		"geo_locations": map[string]interface{}{"countries": geo},
+		"age_min":       float64(ageMin),
+		"age_max":       float64(ageMax),
+	}
+
+	if len(interestIDs) > 0 {
+		var interests []interface{}
+		for _, id := range interestIDs {
+			interests = append(interests, map[string]interface{}{"id": id, "name": interestName(id)})
+		}
+		spec["flexible_spec"] = []interface{}{map[string]interface{}{"interests": interests}}
+	}
+
+	return spec
+}
+
+// budgetValue formats a budget change the way extra_data of the activity log does
+func budgetValue(key string, cents int64) map[string]interface{} {
+	return map[string]interface{}{key: float64(cents), "currency": "USD"}
+}
+
+// findCampaign returns the campaign with the ID or nil
+func (p *Provider) findCampaign(id string) *campaign {
+	for _, c := range p.campaigns {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// findAdSet returns the ad set with the ID or nil
+func (p *Provider) findAdSet(id string) *adSet {
+	for _, s := range p.adSets {
+		if s.ID == id {
+			return s
+		}
+	}
+	return nil
+}
+
+// findAd returns the ad with the ID or nil
+func (p *Provider) findAd(id string) *ad {
+	for _, a := range p.ads {
+		if a.ID == id {
+			return a
+		}
+	}
+	return nil
+}
+
+// findCreative returns the creative with the ID or nil
+func (p *Provider) findCreative(id string) *creative {
+	for _, c := range p.creatives {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// campaignAdSets returns the ad sets of a campaign
+func (p *Provider) campaignAdSets(campaignID string) []*adSet {
+	var result []*adSet
+	for _, s := range p.adSets {
+		if s.CampaignID == campaignID {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// adSetAds returns the ads of an ad set
+func (p *Provider) adSetAds(adSetID string) []*ad {
+	var result []*ad
+	for _, a := range p.ads {
+		if a.AdSetID == adSetID {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// counted reports whether an object counts towards the account object limits
+func counted(status models.CampaignStatus) bool {
+	return status != models.CampaignStatusArchived && status != models.CampaignStatusDeleted
+}
+
+// listCampaigns answers act_<id>/campaigns; deleted campaigns are left out like in the API
+func (p *Provider) listCampaigns(params url.Values) map[string]interface{} {
+	rows := []interface{}{}
+	total := 0
+	for _, c := range p.campaigns {
+		if c.Status == models.CampaignStatusDeleted {
+			continue
+		}
+		rows = append(rows, p.campaignFields(c))
+		if counted(c.Status) {
+			total++
+		}
+	}
+
+	response := dataResponse(rows)
+	if params.Get("summary") != "" {
+		response["summary"] = map[string]interface{}{"total_count": total}
+	}
+	return response
+}
+
+// listAdSets answers act_<id>/adsets
+func (p *Provider) listAdSets(params url.Values) map[string]interface{} {
+	rows := []interface{}{}
+	total := 0
+	for _, s := range p.adSets {
+		c := p.findCampaign(s.CampaignID)
+		if s.Status == models.CampaignStatusDeleted || c.Status == models.CampaignStatusDeleted {
+			continue
+		}
+		rows = append(rows, p.adSetFields(s))
+		if counted(s.Status) && counted(c.Status) {
+			total++
+		}
+	}
+
+	response := dataResponse(rows)
+	if params.Get("summary") != "" {
+		response["summary"] = map[string]interface{}{"total_count": total}
+	}
+	return response
+}
+
+// listCampaignAdSets answers <campaign_id>/adsets
+func (p *Provider) listCampaignAdSets(campaignID string) (interface{}, error) {
+	c := p.findCampaign(campaignID)
+	if c == nil {
+		return nil, badRequest("Unsupported get request. Object with ID '%s' does not exist", campaignID)
+	}
+
+	rows := []interface{}{}
+	for _, s := range p.campaignAdSets(c.ID) {
+		if s.Status != models.CampaignStatusDeleted {
+			rows = append(rows, p.adSetFields(s))
+		}
+	}
+	return dataResponse(rows), nil
+}
+
+// listAds answers act_<id>/ads
+func (p *Provider) listAds() map[string]interface{} {
+	rows := []interface{}{}
+	for _, a := range p.ads {
+		if a.Status != models.CampaignStatusDeleted {
+			rows = append(rows, p.adFields(a))
+		}
+	}
+	return dataResponse(rows)
+}
+
+// listCreatives answers act_<id>/adcreatives
+func (p *Provider) listCreatives() map[string]interface{} {
+	rows := []interface{}{}
+	for _, c := range p.creatives {
+		rows = append(rows, c.fields())
+	}
+	return dataResponse(rows)
+}
+
+// listPages answers me/accounts
+func (p *Provider) listPages() map[string]interface{} {
+	rows := []interface{}{}
+	for _, pg := range p.pages {
+		rows = append(rows, map[string]interface{}{"id": pg.ID, "name": pg.Name, "category": pg.Category})
+	}
+	return dataResponse(rows)
+}
+
+// campaignFields returns the fields of a campaign as the API lists them
+func (p *Provider) campaignFields(c *campaign) map[string]interface{} {
+
+	fields := map[string]interface{}{
+		"id":                    c.ID,
+		"name":                  c.Name,
+		"status":                string(c.Status),
+		"effective_status":      string(c.Status),
+		"objective":             string(c.Objective),
+		"bid_strategy":          string(c.BidStrategy),
+		"buying_type":           "AUCTION",
+		"created_time":          graphTime(c.Created),
+		"updated_time":          graphTime(c.Updated),
+		"special_ad_categories": []interface{}{},
+		"adlabels":              labelList(c.Labels),
+	}
+	setCents(fields, "daily_budget", c.DailyBudget)
+	setCents(fields, "lifetime_budget", c.LifetimeBudget)
+	setCents(fields, "spend_cap", c.SpendCap)
+	setTime(fields, "start_time", c.StartTime)
+	setTime(fields, "stop_time", c.StopTime)
+	return fields
+}
+
+// campaignDetails returns a campaign with its ad sets and ads
+func (p *Provider) campaignDetails(c *campaign) map[string]interface{} {
+	fields := p.campaignFields(c)
+
+	adSets := []interface{}{}
+	ads := []interface{}{}
+	for _, s := range p.campaignAdSets(c.ID) {
+		adSets = append(adSets, p.adSetFields(s))
+		for _, a := range p.adSetAds(s.ID) {
+			ads = append(ads, p.adFields(a))
+		}
+	}
+	fields["adsets"] = dataResponse(adSets)
+	fields["ads"] = dataResponse(ads)
+	return fields
+}
+
+// adSetFields returns the fields of an ad set
+func (p *Provider) adSetFields(s *adSet) map[string]interface{} {
+	fields := map[string]interface{}{
+		"id":                s.ID,
+		"campaign_id":       s.CampaignID,
+		"name":              s.Name,
+		"status":            string(s.Status),
+		"effective_status":  p.effectiveStatus(s.Status, s.CampaignID, ""),
+		"optimization_goal": string(s.OptimizationGoal),
+		"billing_event":     string(s.BillingEvent),
+		"targeting":         s.Targeting,
+		"adlabels":          labelList(s.Labels),
+	}
+	if s.BidAmount > 0 {
+		fields["bid_amount"] = s.BidAmount
+	}
+	if s.DestinationType != "" {
+		fields["destination_type"] = s.DestinationType
+	}
+	if s.LearningStage != "" {
+		fields["learning_stage_info"] = map[string]interface{}{"status": s.LearningStage}
+	}
+	setTime(fields, "start_time", s.StartTime)
+	setTime(fields, "end_time", s.EndTime)
+	return fields
+}
+
+// adFields returns the fields of an ad with its creative
+func (p *Provider) adFields(a *ad) map[string]interface{} {
+	fields := map[string]interface{}{
+		"id":               a.ID,
+		"adset_id":         a.AdSetID,
+		"campaign_id":      a.CampaignID,
+		"name":             a.Name,
+		"status":           string(a.Status),
+		"effective_status": p.effectiveStatus(a.Status, a.CampaignID, a.AdSetID),
+		"adlabels":         labelList(a.Labels),
+	}
+	if c := p.findCreative(a.CreativeID); c != nil {
+		fields["creative"] = c.fields()
+	}
+	return fields
+}
+
+// effectiveStatus returns the status an object delivers with: its own unless it is active
+// and a parent is not, as in CAMPAIGN_PAUSED or ADSET_PAUSED
+func (p *Provider) effectiveStatus(status models.CampaignStatus, campaignID, adSetID string) string {
+	if status != models.CampaignStatusActive {
+		return string(status)
+	}
+	if c := p.findCampaign(campaignID); c != nil && c.Status != models.CampaignStatusActive {
+		return "CAMPAIGN_PAUSED"
+	}
+	if s := p.findAdSet(adSetID); s != nil && s.Status != models.CampaignStatusActive {
+		return "ADSET_PAUSED"
+	}
+	return string(status)
+}
+
+// fields returns the fields of a creative; link and video ads keep their content in the object story spec
+func (c *creative) fields() map[string]interface{} {
+	storySpec := map[string]interface{}{"page_id": c.PageID}
+	if c.VideoID != "" {
+		storySpec["video_data"] = map[string]interface{}{
+			"video_id":       c.VideoID,
+			"title":          c.Title,
+			"message":        c.Body,
+			"image_hash":     c.ImageHash,
+			"call_to_action": map[string]interface{}{"type": c.CallToActionType, "value": map[string]interface{}{"link": c.LinkURL}},
+		}
+	} else {
+		linkData := map[string]interface{}{
+			"name":           c.Title,
+			"message":        c.Body,
+			"link":           c.LinkURL,
+			"image_hash":     c.ImageHash,
+			"call_to_action": map[string]interface{}{"type": c.CallToActionType},
+		}
+		if len(c.Cards) > 0 {
+			var attachments []interface{}
+			for _, card := range c.Cards {
+				attachments = append(attachments, map[string]interface{}{
+					"name":           card.Title,
+					"description":    card.Body,
+					"link":           card.LinkURL,
+					"image_hash":     card.ImageHash,
+					"call_to_action": map[string]interface{}{"type": card.CallToAction},
+				})
+			}
+			linkData["child_attachments"] = attachments
+		}
+		storySpec["link_data"] = linkData
+	}
+
+	fields := map[string]interface{}{
+		"id":                  c.ID,
+		"name":                c.Name,
+		"title":               c.Title,
+		"body":                c.Body,
+		"link_url":            c.LinkURL,
+		"image_hash":          c.ImageHash,
+		"call_to_action_type": c.CallToActionType,
+		"object_story_spec":   storySpec,
+	}
+	// Like Facebook, the creative links to its image in the image library
+	if c.ImageHash != "" {
+		fields["image_url"] = demoImageURL(c.ImageHash)
+	}
+	return fields
+}
+
+// getObject answers a request for a single object by ID
+func (p *Provider) getObject(id string) (interface{}, error) {
+	if c := p.findCampaign(id); c != nil {
+		return p.campaignDetails(c), nil
+	}
+	if s := p.findAdSet(id); s != nil {
+		fields := p.adSetFields(s)
+		ads := []interface{}{}
+		for _, a := range p.adSetAds(s.ID) {
+			ads = append(ads, p.adFields(a))
+		}
+		fields["ads"] = dataResponse(ads)
+		return fields, nil
+	}
+	if a := p.findAd(id); a != nil {
+		return p.adFields(a), nil
+	}
+	if c := p.findCreative(id); c != nil {
+		return c.fields(), nil
+	}
+	if _, ok := p.reportRuns[id]; ok {
+		return map[string]interface{}{"id": id, "async_status": "Job Completed", "async_percent_completion": 100}, nil
+	}
+
+	return nil, badRequest("Unsupported get request. Object with ID '%s' does not exist", id)
+}
+
+// updateObject applies a POST to a campaign, ad set or ad
+func (p *Provider) updateObject(id string, params url.Values) (interface{}, error) {
+	now := p.now()
+
+	if c := p.findCampaign(id); c != nil {
+		if err := p.updateCampaign(c, params, now); err != nil {
+			return nil, err
+		}
+		c.Updated = now
+		return map[string]interface{}{"success": true}, nil
+	}
+
+	if s := p.findAdSet(id); s != nil {
+		if err := p.updateAdSet(s, params, now); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"success": true}, nil
+	}
+
+	if a := p.findAd(id); a != nil {
+		if status := params.Get("status"); status != "" {
+			parsed, err := parseStatus(status)
+			if err != nil {
+				return nil, err
+			}
+			a.Status = parsed
+		}
+		if name := params.Get("name"); name != "" {
+			a.Name = name
+		}
+		return map[string]interface{}{"success": true}, nil
+	}
+
+	return nil, badRequest("Unsupported post request. Object with ID '%s' does not exist", id)
+}
+
+// deleteObject answers a DELETE of a campaign, ad set or ad; its children are deleted with it
+func (p *Provider) deleteObject(id string) (interface{}, error) {
+	result, err := p.updateObject(id, url.Values{"status": {string(models.CampaignStatusDeleted)}})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range p.adSets {
+		if s.CampaignID == id {
+			s.Status = models.CampaignStatusDeleted
+		}
+	}
+	for _, a := range p.ads {
+		if a.CampaignID == id || a.AdSetID == id {
+			a.Status = models.CampaignStatusDeleted
+		}
+	}
+	return result, nil
+}
+
+// updateCampaign applies update parameters to a campaign and logs the changes
+func (p *Provider) updateCampaign(c *campaign, params url.Values, now time.Time) error {
+	if status := params.Get("status"); status != "" {
+		parsed, err := parseStatus(status)
+		if err != nil {
+			return err
+		}
+		if parsed != c.Status {
+			p.logChange(now, "update_campaign_run_status", c.ID, c.Name, "CAMPAIGN", statusLabel(c.Status), statusLabel(parsed))
+		}
+
+		// Delivery stops today when the campaign stops running and resumes when it is activated
+		if parsed == models.CampaignStatusActive {
+			c.PausedAt = time.Time{}
+		} else if c.PausedAt.IsZero() {
+			c.PausedAt = startOfDay(now, p.location)
+		}
+		c.Status = parsed
+	}
+
+	if name := params.Get("name"); name != "" && name != c.Name {
+		p.logChange(now, "update_campaign_name", c.ID, c.Name, "CAMPAIGN", c.Name, name)
+		c.Name = name
+	}
+
+	if bidStrategy := params.Get("bid_strategy"); bidStrategy != "" {
+		parsed, err := models.ParseBidStrategy(bidStrategy)
+		if err != nil {
+			return badRequest("(#100) %v", err)
+		}
+		c.BidStrategy = parsed
+	}
+
+	for _, budget := range []struct {
+		key   string
+		value *int64
+	}{{"daily_budget", &c.DailyBudget}, {"lifetime_budget", &c.LifetimeBudget}, {"spend_cap", &c.SpendCap}} {
+		if !params.Has(budget.key) {
+			continue
+		}
+		cents, err := parseCents(budget.key, params.Get(budget.key))
+		if err != nil {
+			return err
+		}
+		if budget.key != "spend_cap" && cents != *budget.value {
+			p.logChange(now, "update_campaign_budget", c.ID, c.Name, "CAMPAIGN",
+				budgetValue("old_value", *budget.value), budgetValue("new_value", cents))
+		}
+		*budget.value = cents
+	}
+
+	for _, key := range []string{"stop_time", "end_time"} {
+		if params.Has(key) {
+			stop, err := parseOptionalTime(key, params.Get(key))
+			if err != nil {
+				return err
+			}
+			c.StopTime = stop
+		}
+	}
+
+	return nil
+}
+
+// updateAdSet applies update parameters to an ad set and logs the changes
+func (p *Provider) updateAdSet(s *adSet, params url.Values, now time.Time) error {
+	if status := params.Get("status"); status != "" {
+		parsed, err := parseStatus(status)
+		if err != nil {
+			return err
+		}
+		if parsed != s.Status {
+			p.logChange(now, "update_ad_set_run_status", s.ID, s.Name, "AD_SET", statusLabel(s.Status), statusLabel(parsed))
+		}
+		s.Status = parsed
+	}
+
+	if name := params.Get("name"); name != "" {
+		s.Name = name
+	}
+
+	if params.Has("bid_amount") {
+		cents, err := parseCents("bid_amount", params.Get("bid_amount"))
+		if err != nil {
+			return err
+		}
+		p.logChange(now, "update_ad_set_bid", s.ID, s.Name, "AD_SET", float64(s.BidAmount), float64(cents))
+		s.BidAmount = cents
+	}
+
+	if raw := params.Get("targeting"); raw != "" {
+		var spec map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &spec); err != nil {
+			return badRequest("(#100) Invalid targeting: %v", err)
+		}
+		p.logChange(now, "update_ad_set_target_spec", s.ID, s.Name, "AD_SET", nil, nil)
+		s.Targeting = spec
+	}
+
+	if params.Has("end_time") {
+		end, err := parseOptionalTime("end_time", params.Get("end_time"))
+		if err != nil {
+			return err
+		}
+		s.EndTime = end
+	}
+
+	return nil
+}
+
+// createCampaign answers a POST to act_<id>/campaigns
+func (p *Provider) createCampaign(params url.Values) (interface{}, error) {
+	name := params.Get("name")
+	if name == "" {
+		return nil, badRequest("(#100) The parameter name is required")
+	}
+
+	objective, err := models.ParseObjective(params.Get("objective"))
+	if err != nil {
+		return nil, badRequest("(#100) %v", err)
+	}
+
+	now := p.now()
+	c := &campaign{
+		ID:          p.newID(),
+		Name:        name,
+		Status:      models.CampaignStatusPaused,
+		Objective:   objective,
+		BidStrategy: models.BidStrategyLowestCostWithoutCap,
+		Created:     now,
+		Updated:     now,
+	}
+
+	// New campaigns deliver like an average campaign of the account
+	c.CPM, c.CTR, c.CVR = 10, 0.012, 0.03
+
+	// Apply the initial settings without logging them as changes
+	logged := len(p.activities)
+	if err := p.updateCampaign(c, params, now); err != nil {
+		return nil, err
+	}
+	p.activities = p.activities[:logged]
+	if params.Has("start_time") {
+		if c.StartTime, err = parseOptionalTime("start_time", params.Get("start_time")); err != nil {
+			return nil, err
+		}
+	}
+
+	// A campaign created paused has not delivered on its first day
+	if c.Status != models.CampaignStatusActive {
+		c.PausedAt = startOfDay(now, p.location)
+	}
+
+	p.campaigns = append(p.campaigns, c)
+	p.logChange(now, "create_campaign_group", c.ID, c.Name, "CAMPAIGN", nil, nil)
+	return map[string]interface{}{"id": c.ID}, nil
+}
+
+// createAdSet answers a POST to act_<id>/adsets
+func (p *Provider) createAdSet(params url.Values) (interface{}, error) {
+	c := p.findCampaign(params.Get("campaign_id"))
+	if c == nil {
+		return nil, badRequest("(#100) Invalid campaign_id %q", params.Get("campaign_id"))
+	}
+
+	s := &adSet{
+		ID:              p.newID(),
+		CampaignID:      c.ID,
+		Name:            params.Get("name"),
+		Status:          models.CampaignStatusPaused,
+		DestinationType: params.Get("destination_type"),
+		LearningStage:   "LEARNING",
+		Weight:          1,
+	}
+
+	var err error
+	if s.OptimizationGoal, err = models.ParseOptimizationGoal(params.Get("optimization_goal")); err != nil {
+		return nil, badRequest("(#100) %v", err)
+	}
+	if s.BillingEvent, err = models.ParseBillingEvent(params.Get("billing_event")); err != nil {
+		return nil, badRequest("(#100) %v", err)
+	}
+	if params.Has("start_time") {
+		if s.StartTime, err = parseOptionalTime("start_time", params.Get("start_time")); err != nil {
+			return nil, err
+		}
+	}
+
+	// Apply the initial settings without logging them as changes
+	now := p.now()
+	logged := len(p.activities)
+	if err := p.updateAdSet(s, params, now); err != nil {
+		return nil, err
+	}
+	p.activities = p.activities[:logged]
+
+	p.adSets = append(p.adSets, s)
+	p.logChange(now, "create_ad_set", s.ID, s.Name, "AD_SET", nil, nil)
+	return map[string]interface{}{"id": s.ID}, nil
+}
+
+// createCreative answers a POST to act_<id>/adcreatives
+func (p *Provider) createCreative(params url.Values) (interface{}, error) {
+	c := &creative{ID: p.newID(), Name: params.Get("name")}
+
+	var spec struct {
+		PageID   string `json:"page_id"`
+		LinkData struct {
+			Name         string `json:"name"`
+			Message      string `json:"message"`
+			Link         string `json:"link"`
+			ImageHash    string `json:"image_hash"`
+			CallToAction struct {
+				Type string `json:"type"`
+			} `json:"call_to_action"`
+			ChildAttachments []struct {
+				Name         string `json:"name"`
+				Description  string `json:"description"`
+				Link         string `json:"link"`
+				ImageHash    string `json:"image_hash"`
+				CallToAction struct {
+					Type string `json:"type"`
+				} `json:"call_to_action"`
+			} `json:"child_attachments"`
+		} `json:"link_data"`
+		VideoData *struct {
+			VideoID      string `json:"video_id"`
+			Title        string `json:"title"`
+			Message      string `json:"message"`
+			ImageHash    string `json:"image_hash"`
+			CallToAction struct {
+				Type  string `json:"type"`
+				Value struct {
+					Link string `json:"link"`
+				} `json:"value"`
+			} `json:"call_to_action"`
+		} `json:"video_data"`
+	}
+	if raw := params.Get("object_story_spec"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &spec); err != nil {
+			return nil, badRequest("(#100) Invalid object_story_spec: %v", err)
+		}
+	}
+
+	c.PageID = spec.PageID
+	c.Title = spec.LinkData.Name
+	c.Body = spec.LinkData.Message
+	c.LinkURL = spec.LinkData.Link
+	c.ImageHash = spec.LinkData.ImageHash
+	c.CallToActionType = spec.LinkData.CallToAction.Type
+	for _, attachment := range spec.LinkData.ChildAttachments {
+		c.Cards = append(c.Cards, models.CreativeCard{
+			Title:        attachment.Name,
+			Body:         attachment.Description,
+			LinkURL:      attachment.Link,
+			ImageHash:    attachment.ImageHash,
+			CallToAction: attachment.CallToAction.Type,
+		})
+	}
+
+	if video := spec.VideoData; video != nil {
+		c.VideoID = video.VideoID
+		c.Title = video.Title
+		c.Body = video.Message
+		c.LinkURL = video.CallToAction.Value.Link
+		c.ImageHash = video.ImageHash
+		c.CallToActionType = video.CallToAction.Type
+	}
+
+	p.creatives = append(p.creatives, c)
+	return map[string]interface{}{"id": c.ID}, nil
+}
+
+// createAd answers a POST to act_<id>/ads
+func (p *Provider) createAd(params url.Values) (interface{}, error) {
+	s := p.findAdSet(params.Get("adset_id"))
+	if s == nil {
+		return nil, badRequest("(#100) Invalid adset_id %q", params.Get("adset_id"))
+	}
+
+	var creativeRef struct {
+		CreativeID string `json:"creative_id"`
+	}
+	json.Unmarshal([]byte(params.Get("creative")), &creativeRef)
+	if p.findCreative(creativeRef.CreativeID) == nil {
+		return nil, badRequest("(#100) Invalid creative %q", params.Get("creative"))
+	}
+
+	status := models.CampaignStatusPaused
+	if value := params.Get("status"); value != "" {
+		parsed, err := parseStatus(value)
+		if err != nil {
+			return nil, err
+		}
+		status = parsed
+	}
+
+	a := &ad{
+		ID:         p.newID(),
+		AdSetID:    s.ID,
+		CampaignID: s.CampaignID,
+		Name:       params.Get("name"),
+		Status:     status,
+		CreativeID: creativeRef.CreativeID,
+		Weight:     1,
+	}
+
+	p.ads = append(p.ads, a)
+	p.logChange(p.now(), "create_ad", a.ID, a.Name, "AD", nil, nil)
+	return map[string]interface{}{"id": a.ID}, nil
+}
+
+// logChange records a change in the activity log
+func (p *Provider) logChange(at time.Time, eventType, objectID, objectName, objectType string, oldValue, newValue interface{}) {
+	p.activities = append(p.activities, activity{
+		Time:       at,
+		EventType:  eventType,
+		ActorName:  demoActor,
+		ObjectID:   objectID,
+		ObjectName: objectName,
+		ObjectType: objectType,
+		OldValue:   oldValue,
+		NewValue:   newValue,
+	})
+}
+
+// listActivities answers act_<id>/activities, newest first like the API
+func (p *Provider) listActivities(params url.Values) (interface{}, error) {
+	since, until := time.Time{}, p.now()
+	if value := params.Get("since"); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, badRequest("(#100) Invalid since: %s", value)
+		}
+		since = time.Unix(seconds, 0)
+	}
+	if value := params.Get("until"); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, badRequest("(#100) Invalid until: %s", value)
+		}
+		until = time.Unix(seconds, 0)
+	}
+
+	var selected []activity
+	for _, a := range p.activities {
+		if !a.Time.Before(since) && !a.Time.After(until) {
+			selected = append(selected, a)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].Time.After(selected[j].Time)
+	})
+
+	rows := []interface{}{}
+	for _, a := range selected {
+		row := map[string]interface{}{
+			"event_type":  a.EventType,
+			"event_time":  graphTime(a.Time.In(p.location)),
+			"actor_name":  a.ActorName,
+			"object_id":   a.ObjectID,
+			"object_name": a.ObjectName,
+			"object_type": a.ObjectType,
+		}
+		if a.OldValue != nil || a.NewValue != nil {
+			extra, _ := json.Marshal(map[string]interface{}{"old_value": a.OldValue, "new_value": a.NewValue})
+			row["extra_data"] = string(extra)
+		}
+		rows = append(rows, row)
+	}
+
+	return dataResponse(rows), nil
+}
+
+// parseStatus parses a status parameter
+func parseStatus(value string) (models.CampaignStatus, error) {
+	status, err := models.ParseCampaignStatus(value)
+	if err != nil {
+		return "", badRequest("(#100) %v", err)
+	}
+	return status, nil
+}
+
+// statusLabel formats a status the way the activity log shows it
+func statusLabel(status models.CampaignStatus) string {
+	value := strings.ToLower(string(status))
+	return strings.ToUpper(value[:1]) + value[1:]
+}
+
+// parseCents parses an amount in cents; an empty value clears it
+func parseCents(key, value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	cents, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || cents < 0 {
+		return 0, badRequest("(#100) Invalid %s: %s", key, value)
+	}
+	return cents, nil
+}
+
+// parseOptionalTime parses a timestamp parameter; an empty value or 0 clears it
+func parseOptionalTime(key, value string) (time.Time, error) {
+	if value == "" || value == "0" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05-0700", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Time{}, badRequest("(#100) Invalid %s: %s", key, value)
+}
+
+// setCents sets an amount field when it is set, as a string like the API returns it
+func setCents(fields map[string]interface{}, key string, cents int64) {
+	if cents > 0 {
+		fields[key] = strconv.FormatInt(cents, 10)
+	}
+}
+
+// setTime sets a timestamp field when it is set
+func setTime(fields map[string]interface{}, key string, t time.Time) {
+	if !t.IsZero() {
+		fields[key] = graphTime(t)
+	}
+}
+
+// labelID derives a stable ad label ID from its name
+func labelID(name string) string {
+	return fmt.Sprintf("1202%011d", int64(hashUnit(name, "label")*1e11))
+}
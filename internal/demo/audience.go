@@ -0,0 +1,194 @@
+package demo
+
+import (
+	"encoding/json"
+	"math"
+	"net/url"
+	"strings"
+)
+
+// interest is a demo targeting interest
+type interest struct {
+	ID         string
+	Name       string
+	Path       []string
+	LowerBound int64
+	UpperBound int64
+}
+
+// sampleInterests returns the interests the demo search answers from
+func sampleInterests() []interest {
+	return []interest{
+		{ID: "6003384248805", Name: "Hiking", Path: []string{"Interests", "Fitness and wellness", "Hiking"}, LowerBound: 180000000, UpperBound: 212000000},
+		{ID: "6003277229526", Name: "Trail running", Path: []string{"Interests", "Fitness and wellness", "Running", "Trail running"}, LowerBound: 21000000, UpperBound: 24700000},
+		{ID: "6003020834693", Name: "Camping", Path: []string{"Interests", "Hobbies and activities", "Outdoor recreation", "Camping"}, LowerBound: 310000000, UpperBound: 365000000},
+		{ID: "6003139266461", Name: "Backpacking", Path: []string{"Interests", "Travel", "Backpacking"}, LowerBound: 98000000, UpperBound: 115000000},
+		{ID: "6003012317397", Name: "Rock climbing", Path: []string{"Interests", "Sports and outdoors", "Rock climbing"}, LowerBound: 45000000, UpperBound: 53000000},
+		{ID: "6003397425735", Name: "Mountain biking", Path: []string{"Interests", "Sports and outdoors", "Cycling", "Mountain biking"}, LowerBound: 62000000, UpperBound: 73000000},
+		{ID: "6003107902433", Name: "Outdoor recreation", Path: []string{"Interests", "Hobbies and activities", "Outdoor recreation"}, LowerBound: 420000000, UpperBound: 494000000},
+		{ID: "6003348604581", Name: "Fishing", Path: []string{"Interests", "Sports and outdoors", "Fishing"}, LowerBound: 260000000, UpperBound: 306000000},
+		{ID: "6003263791114", Name: "Kayaking", Path: []string{"Interests", "Sports and outdoors", "Water sports", "Kayaking"}, LowerBound: 38000000, UpperBound: 44700000},
+		{ID: "6003305411105", Name: "National parks", Path: []string{"Interests", "Travel", "National parks"}, LowerBound: 75000000, UpperBound: 88000000},
+		{ID: "6003156321008", Name: "Running shoes", Path: []string{"Interests", "Shopping and fashion", "Footwear", "Running shoes"}, LowerBound: 33000000, UpperBound: 38800000},
+		{ID: "6003455782914", Name: "Outdoor clothing", Path: []string{"Interests", "Shopping and fashion", "Clothing", "Outdoor clothing"}, LowerBound: 54000000, UpperBound: 63500000},
+	}
+}
+
+// interestName returns the name of a sample interest by ID
+func interestName(id string) string {
+	for _, i := range sampleInterests() {
+		if i.ID == id {
+			return i.Name
+		}
+	}
+	return ""
+}
+
+// listCustomAudiences answers act_<id>/customaudiences with the audiences of the demo account
+func (p *Provider) listCustomAudiences() map[string]interface{} {
+	return dataResponse([]interface{}{
+		map[string]interface{}{"id": "23850000000001", "name": "Website visitors (30 days)"},
+		map[string]interface{}{"id": "23850000000002", "name": "Newsletter subscribers"},
+	})
+}
+
+// searchInterests answers the targeting search endpoint; q matches anywhere in the name
+func (p *Provider) searchInterests(params url.Values) map[string]interface{} {
+	if params.Get("type") == "adinterestvalid" {
+		return p.validInterests(params)
+	}
+
+	query := strings.ToLower(strings.TrimSpace(params.Get("q")))
+
+	rows := []interface{}{}
+	for _, i := range p.interests {
+		if query != "" && !strings.Contains(strings.ToLower(i.Name), query) {
+			continue
+		}
+		rows = append(rows, map[string]interface{}{
+			"id":                        i.ID,
+			"name":                      i.Name,
+			"type":                      "interests",
+			"path":                      i.Path,
+			"audience_size_lower_bound": i.LowerBound,
+			"audience_size_upper_bound": i.UpperBound,
+		})
+	}
+	return dataResponse(rows)
+}
+
+// validInterests answers the adinterestvalid search: the sample interests are valid,
+// IDs the demo doesn't know aren't returned
+func (p *Provider) validInterests(params url.Values) map[string]interface{} {
+	var ids []string
+	json.Unmarshal([]byte(params.Get("interest_fbid_list")), &ids)
+
+	rows := []interface{}{}
+	for _, id := range ids {
+		if name := interestName(id); name != "" {
+			rows = append(rows, map[string]interface{}{"id": id, "name": name, "valid": true})
+		}
+	}
+	return dataResponse(rows)
+}
+
+// countryAudiences is the monthly active audience of the countries the demo knows
+var countryAudiences = map[string]float64{
+	"US": 240000000, "CA": 30000000, "GB": 52000000, "DE": 55000000, "FR": 48000000,
+	"AU": 20000000, "ES": 33000000, "IT": 38000000, "NL": 13000000, "SE": 7500000,
+}
+
+// worldAudience is the audience interest sizes are measured against
+const worldAudience = 3000000000
+
+// deliveryEstimate answers act_<id>/delivery_estimate from the countries, ages and
+// interests of the targeting spec
+func (p *Provider) deliveryEstimate(params url.Values) (interface{}, error) {
+	var spec struct {
+		GeoLocations struct {
+			Countries []string `json:"countries"`
+		} `json:"geo_locations"`
+		AgeMin    float64 `json:"age_min"`
+		AgeMax    float64 `json:"age_max"`
+		Interests []struct {
+			ID string `json:"id"`
+		} `json:"interests"`
+		FlexibleSpec []struct {
+			Interests []struct {
+				ID string `json:"id"`
+			} `json:"interests"`
+		} `json:"flexible_spec"`
+	}
+	if err := json.Unmarshal([]byte(params.Get("targeting_spec")), &spec); err != nil {
+		return nil, badRequest("(#100) Invalid targeting_spec: %v", err)
+	}
+	if len(spec.GeoLocations.Countries) == 0 {
+		return nil, badRequest("(#100) Targeting spec must include geo_locations")
+	}
+
+	users := 0.0
+	for _, country := range spec.GeoLocations.Countries {
+		size, ok := countryAudiences[strings.ToUpper(country)]
+		if !ok {
+			size = 10000000
+		}
+		users += size
+	}
+
+	// Ages 18 to 65+ span 48 years of the audience
+	ageMin, ageMax := math.Max(spec.AgeMin, 18), spec.AgeMax
+	if ageMax == 0 || ageMax > 65 {
+		ageMax = 65
+	}
+	users *= math.Max(ageMax-ageMin+1, 1) / 48
+
+	// Interests narrow the audience by their share of the world audience
+	var ids []string
+	for _, i := range spec.Interests {
+		ids = append(ids, i.ID)
+	}
+	for _, flexible := range spec.FlexibleSpec {
+		for _, i := range flexible.Interests {
+			ids = append(ids, i.ID)
+		}
+	}
+	if len(ids) > 0 {
+		users *= math.Min(1, p.interestReach(ids)/worldAudience)
+	}
+
+	estimate := int64(math.Round(users))
+	return dataResponse([]interface{}{map[string]interface{}{
+		"estimate_ready": true,
+		"users":          estimate,
+		"lower_bound":    estimate * 9 / 10,
+		"upper_bound":    estimate * 11 / 10,
+	}}), nil
+}
+
+// interestReach returns the people reached by any of the interests. Interests of the same
+// category share most of their audience, others a small part, so audience overlap has
+// something to find.
+func (p *Provider) interestReach(ids []string) float64 {
+	var matched []interest
+	for _, id := range ids {
+		for _, i := range p.interests {
+			if i.ID == id {
+				matched = append(matched, i)
+			}
+		}
+	}
+
+	reach, largest := 0.0, 0.0
+	for n, i := range matched {
+		reach += float64(i.UpperBound)
+		largest = math.Max(largest, float64(i.UpperBound))
+		for _, earlier := range matched[:n] {
+			shared := 0.15
+			if len(i.Path) > 1 && len(earlier.Path) > 1 && i.Path[1] == earlier.Path[1] {
+				shared = 0.7
+			}
+			reach -= shared * math.Min(float64(i.UpperBound), float64(earlier.UpperBound))
+		}
+	}
+	return math.Max(reach, largest)
+}
@@ -0,0 +1,90 @@
+package demo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxBatchRequests is the largest number of requests in one batch call
+const maxBatchRequests = 50
+
+// batchReference matches a reference to the ID created by an earlier request of a batch
+var batchReference = regexp.MustCompile(`\{result=([A-Za-z0-9_]+):\$\.id\}`)
+
+// runBatch answers a batch call by running its requests in order. A request referencing
+// a request that failed is skipped and answered with null, like the Graph API does.
+func (p *Provider) runBatch(params url.Values) (interface{}, error) {
+	var requests []struct {
+		Method      string `json:"method"`
+		RelativeURL string `json:"relative_url"`
+		Body        string `json:"body"`
+		Name        string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(params.Get("batch")), &requests); err != nil {
+		return nil, badRequest("(#100) Invalid batch: %v", err)
+	}
+	if len(requests) > maxBatchRequests {
+		return nil, badRequest("(#100) Too many requests in batch message. Maximum batch size is %d", maxBatchRequests)
+	}
+
+	ids := make(map[string]string)
+	responses := make([]interface{}, len(requests))
+	for i, request := range requests {
+		resolved := true
+		resolve := func(text string) string {
+			return batchReference.ReplaceAllStringFunc(text, func(ref string) string {
+				id, ok := ids[batchReference.FindStringSubmatch(ref)[1]]
+				resolved = resolved && ok
+				return id
+			})
+		}
+		relativeURL := resolve(request.RelativeURL)
+		body := resolve(request.Body)
+		if !resolved {
+			continue
+		}
+
+		target, err := url.Parse("/" + strings.TrimPrefix(relativeURL, "/"))
+		if err != nil {
+			return nil, badRequest("(#100) Invalid relative_url %q", request.RelativeURL)
+		}
+		values := target.Query()
+		form, err := url.ParseQuery(body)
+		if err != nil {
+			return nil, badRequest("(#100) Invalid body of request %d: %v", i+1, err)
+		}
+		for key, value := range form {
+			values[key] = value
+		}
+
+		method := strings.ToUpper(request.Method)
+		if method == "" {
+			method = http.MethodGet
+		}
+		result, err := p.route(method, graphPath(target.Path), values)
+
+		// Objects can be labeled as they are created
+		created, _ := result.(map[string]interface{})
+		id, _ := created["id"].(string)
+		if err == nil && id != "" && values.Has("adlabels") && p.findCreative(id) == nil {
+			_, err = p.attachLabels(id, values)
+		}
+
+		status := http.StatusOK
+		if err != nil {
+			status, result = errorResponse(err)
+		} else if request.Name != "" && id != "" {
+			ids[request.Name] = id
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = map[string]interface{}{"code": status, "body": string(data)}
+	}
+	return responses, nil
+}
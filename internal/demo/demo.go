@@ -0,0 +1,365 @@
+// Package demo provides a sample ad account for trying fbads without Facebook credentials.
+//
+// The Provider answers Graph API requests in-process: API clients send their requests
+// through it instead of the network (see auth.FacebookAuth.Transport), so every command
+// runs its real code path against consistent sample data. Campaigns, ad sets, ads,
+// creatives, pages, insights, activities and audiences all refer to each other, and
+// writes change the sample account for the rest of the process.
+package demo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // The demo account timezone must resolve on hosts without a zoneinfo database
+)
+
+// graphHost is the host of the Graph API the provider answers for
+const graphHost = "graph.facebook.com"
+
+// EnvVar enables demo mode when set to 1
+const EnvVar = "FBADS_DEMO"
+
+// AccountID is the ad account ID used in demo mode when none is configured
+const AccountID = "100000000000001"
+
+// TimezoneName is the timezone of the demo ad account
+const TimezoneName = "America/New_York"
+
+// Enabled reports whether demo mode is requested with the --demo flag or FBADS_DEMO=1
+func Enabled(flag bool) bool {
+	return flag || os.Getenv(EnvVar) == "1"
+}
+
+// Provider is an in-memory demo ad account answering Graph API requests.
+// It implements http.RoundTripper and is safe for concurrent use.
+type Provider struct {
+	mu         sync.Mutex
+	now        func() time.Time
+	location   *time.Location
+	campaigns  []*campaign
+	adSets     []*adSet
+	ads        []*ad
+	creatives  []*creative
+	pages      []page
+	interests  []interest
+	activities []activity
+	labels     []string              // Names of ad labels created through the API
+	reportRuns map[string]url.Values // Parameters of async insights jobs by report run ID
+	nextID     int64
+}
+
+// NewProvider creates a demo ad account with sample data relative to the current time
+func NewProvider() *Provider {
+	return newProvider(time.Now)
+}
+
+// newProvider creates a demo ad account whose data is relative to now
+func newProvider(now func() time.Time) *Provider {
+	loc, err := time.LoadLocation(TimezoneName)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	p := &Provider{
+		now:        now,
+		location:   loc,
+		reportRuns: make(map[string]url.Values),
+		nextID:     120210000000001,
+	}
+	p.seed()
+	return p
+}
+
+// graphError is an error answered in the Graph API error format
+type graphError struct {
+	status  int
+	message string
+}
+
+func (e *graphError) Error() string {
+	return e.message
+}
+
+// badRequest returns a Graph API error for an invalid parameter or unsupported request
+func badRequest(format string, args ...interface{}) error {
+	return &graphError{status: http.StatusBadRequest, message: fmt.Sprintf(format, args...)}
+}
+
+// RoundTrip answers a Graph API request from the demo account. Requests to other hosts,
+// such as image downloads, get a placeholder image so nothing leaves the process.
+func (p *Provider) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != "" && req.URL.Host != graphHost {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "200 OK",
+			Header:     http.Header{"Content-Type": {"image/png"}},
+			Body:       io.NopCloser(bytes.NewReader(placeholderImage)),
+			Request:    req,
+		}, nil
+	}
+
+	params := req.URL.Query()
+	if req.Method == http.MethodPost && req.Body != nil {
+		form, err := readForm(req)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range form {
+			params[key] = values
+		}
+	}
+	params.Del("access_token")
+
+	p.mu.Lock()
+	result, err := p.route(req.Method, graphPath(req.URL.Path), params)
+	p.mu.Unlock()
+
+	status := http.StatusOK
+	if err != nil {
+		status, result = errorResponse(err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding demo response: %w", err)
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(data)),
+		Request:    req,
+	}, nil
+}
+
+// errorResponse returns the status and Graph API error body answering a failed request
+func errorResponse(err error) (int, interface{}) {
+	status := http.StatusInternalServerError
+	if gerr, ok := err.(*graphError); ok {
+		status = gerr.status
+	}
+	return status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"message": err.Error(),
+			"type":    "GraphMethodException",
+			"code":    100,
+		},
+	}
+}
+
+// readForm returns the parameters of a POST body, URL-encoded or multipart. The content of
+// an uploaded file is returned under its field name, with the file name as "name".
+func readForm(req *http.Request) (url.Values, error) {
+	defer req.Body.Close()
+
+	mediaType, mediaParams, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error reading request body: %w", err)
+		}
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing request body: %w", err)
+		}
+		return form, nil
+	}
+
+	form := url.Values{}
+	reader := multipart.NewReader(req.Body, mediaParams["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return form, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing request body: %w", err)
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return nil, fmt.Errorf("error reading request body: %w", err)
+		}
+		form.Set(part.FormName(), string(data))
+		if part.FileName() != "" && form.Get("name") == "" {
+			form.Set("name", part.FileName())
+		}
+	}
+}
+
+// graphPath splits a request path into its segments without the API version
+func graphPath(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) > 0 && strings.HasPrefix(segments[0], "v") && strings.Contains(segments[0], ".") {
+		segments = segments[1:]
+	}
+	return segments
+}
+
+// route dispatches a request to the handler of its endpoint
+func (p *Provider) route(method string, path []string, params url.Values) (interface{}, error) {
+	if len(path) == 0 {
+		if method == http.MethodPost && params.Has("batch") {
+			return p.runBatch(params)
+		}
+		return nil, badRequest("Unsupported request in demo mode: %s /", method)
+	}
+
+	root := path[0]
+	edge := ""
+	if len(path) > 1 {
+		edge = strings.Join(path[1:], "/")
+	}
+
+	switch {
+	case root == "me" && edge == "accounts" && method == http.MethodGet:
+		return p.listPages(), nil
+	case root == "debug_token" && edge == "" && method == http.MethodGet:
+		return p.debugToken(), nil
+	case root == "search" && edge == "" && method == http.MethodGet:
+		return p.searchInterests(params), nil
+	case strings.HasPrefix(root, "act_"):
+		return p.routeAccount(method, root, edge, params)
+	}
+
+	// Everything else addresses an object by ID
+	switch {
+	case edge == "" && method == http.MethodGet:
+		return p.getObject(root)
+	case edge == "" && method == http.MethodPost:
+		return p.updateObject(root, params)
+	case edge == "" && method == http.MethodDelete:
+		return p.deleteObject(root)
+	case edge == "insights" && method == http.MethodGet:
+		return p.objectInsights(root, params)
+	case edge == "adsets" && method == http.MethodGet:
+		return p.listCampaignAdSets(root)
+	case edge == "adlabels" && method == http.MethodPost:
+		return p.attachLabels(root, params)
+	}
+
+	return nil, badRequest("Unsupported request in demo mode: %s /%s", method, strings.Join(path, "/"))
+}
+
+// routeAccount dispatches a request to an ad account edge
+func (p *Provider) routeAccount(method, account, edge string, params url.Values) (interface{}, error) {
+	if method == http.MethodGet {
+		switch edge {
+		case "":
+			return p.accountInfo(account), nil
+		case "campaigns":
+			return p.listCampaigns(params), nil
+		case "adsets":
+			return p.listAdSets(params), nil
+		case "ads":
+			return p.listAds(), nil
+		case "adcreatives":
+			return p.listCreatives(), nil
+		case "insights":
+			rows, err := p.insights(params, nil)
+			if err != nil {
+				return nil, err
+			}
+			return dataResponse(rows), nil
+		case "activities":
+			return p.listActivities(params)
+		case "delivery_estimate":
+			return p.deliveryEstimate(params)
+		case "adlabels":
+			return p.listLabels(), nil
+		case "customaudiences":
+			return p.listCustomAudiences(), nil
+		}
+	}
+
+	if method == http.MethodPost {
+		switch edge {
+		case "campaigns":
+			return p.createCampaign(params)
+		case "adsets":
+			return p.createAdSet(params)
+		case "ads":
+			return p.createAd(params)
+		case "adcreatives":
+			return p.createCreative(params)
+		case "insights":
+			return p.startReportRun(params)
+		case "adlabels":
+			return p.createLabel(params)
+		case "adimages":
+			return p.uploadImage(params)
+		case "advideos":
+			return p.uploadVideo(params)
+		}
+	}
+
+	return nil, badRequest("Unsupported request in demo mode: %s /%s/%s", method, account, edge)
+}
+
+// accountInfo describes the demo ad account
+func (p *Provider) accountInfo(account string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             account,
+		"account_id":     strings.TrimPrefix(account, "act_"),
+		"name":           "Acme Outdoor (demo)",
+		"currency":       "USD",
+		"account_status": 1,
+		"timezone_name":  p.location.String(),
+	}
+}
+
+// debugToken describes the demo access token, which has the permissions fbads needs and doesn't expire
+func (p *Provider) debugToken() map[string]interface{} {
+	return dataResponse(map[string]interface{}{
+		"app_id":      "100000000000001",
+		"type":        "USER",
+		"application": "fbads demo",
+		"is_valid":    true,
+		"issued_at":   p.now().Unix(),
+		"expires_at":  0,
+		"scopes":      []string{"ads_management", "ads_read", "business_management", "pages_show_list"},
+		"user_id":     "100000000000002",
+	})
+}
+
+// dataResponse wraps rows in the Graph API list format
+func dataResponse(rows interface{}) map[string]interface{} {
+	return map[string]interface{}{"data": rows}
+}
+
+// newID returns a new object ID
+func (p *Provider) newID() string {
+	p.nextID++
+	return fmt.Sprintf("%d", p.nextID)
+}
+
+// today returns the start of the current day in the account timezone
+func (p *Provider) today() time.Time {
+	return startOfDay(p.now(), p.location)
+}
+
+// startOfDay returns midnight of t's day in loc
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// graphTime formats a timestamp the way the Graph API returns it
+func graphTime(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05-0700")
+}
@@ -0,0 +1,223 @@
+package demo
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/audience"
+	internal_campaign "github.com/user/fb-ads/internal/campaign"
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// testNow is the fixed time the demo account is built around in tests
+var testNow = time.Date(2024, 7, 15, 16, 0, 0, 0, time.UTC)
+
+// newTestAuth returns an auth client answering from a demo account built around testNow
+func newTestAuth() (*auth.FacebookAuth, *Provider) {
+	provider := newProvider(func() time.Time { return testNow })
+	authClient := auth.NewFacebookAuth("", "", "", "v22.0")
+	authClient.Transport = provider
+	return authClient, provider
+}
+
+func TestEnabled(t *testing.T) {
+	t.Setenv(EnvVar, "")
+	if Enabled(false) {
+		t.Errorf("Expected demo mode to be off by default")
+	}
+	if !Enabled(true) {
+		t.Errorf("Expected --demo to enable demo mode")
+	}
+
+	t.Setenv(EnvVar, "1")
+	if !Enabled(false) {
+		t.Errorf("Expected %s=1 to enable demo mode", EnvVar)
+	}
+}
+
+func TestDemoNeedsNoAccessToken(t *testing.T) {
+	authClient, _ := newTestAuth()
+	if err := authClient.CheckAccessToken(); err != nil {
+		t.Errorf("Expected no token check in demo mode, got %v", err)
+	}
+
+	authClient.Transport = nil
+	if err := authClient.CheckAccessToken(); !errors.Is(err, auth.ErrNoAccessToken) {
+		t.Errorf("Expected ErrNoAccessToken without the demo provider, got %v", err)
+	}
+}
+
+func TestDemoCampaigns(t *testing.T) {
+	authClient, _ := newTestAuth()
+	client := api.NewClient(authClient, "demo-campaigns")
+
+	campaigns, err := client.GetAllCampaigns()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(campaigns) != 6 {
+		t.Fatalf("Expected 6 demo campaigns, got %d", len(campaigns))
+	}
+
+	details, err := client.GetCampaignDetails("120200000000101")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(details.AdSets) == 0 || len(details.Ads) == 0 {
+		t.Fatalf("Expected ad sets and ads in the campaign details, got %+v", details)
+	}
+	for _, ad := range details.Ads {
+		if ad.Creative.ID == "" {
+			t.Errorf("Expected ad %s to reference its creative", ad.ID)
+		}
+	}
+
+	pages, err := client.GetPages()
+	if err != nil || len(pages) == 0 {
+		t.Fatalf("Expected demo pages, got %v %v", pages, err)
+	}
+
+	// Writes change the account for later reads
+	if err := client.UpdateCampaign("120200000000101", url.Values{"status": {"PAUSED"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	details, err = client.GetCampaignDetails("120200000000101")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if details.Status != "PAUSED" {
+		t.Errorf("Expected the campaign to be paused, got %s", details.Status)
+	}
+
+	if err := client.UpdateCampaign("999", url.Values{"status": {"PAUSED"}}); err == nil {
+		t.Errorf("Expected an error for an unknown campaign")
+	}
+}
+
+func TestDemoInsights(t *testing.T) {
+	authClient, _ := newTestAuth()
+	metrics := api.NewMetricsCollector(authClient, "demo-insights")
+	timeRange := api.TimeRange{Since: "2024-07-01", Until: "2024-07-14"}
+
+	analysis, err := api.NewPerformanceAnalyzer(metrics, audience.NewAudienceAnalyzer(authClient, "demo-insights")).AnalyzeCampaignPerformance(timeRange)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if analysis.TotalSpend <= 0 || analysis.TotalConversions <= 0 {
+		t.Errorf("Expected spend and conversions, got %+v", analysis)
+	}
+
+	// Daily rows add up to the totals of the period
+	daily, err := metrics.CollectDailyCampaignMetrics(timeRange)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	spend := 0.0
+	for _, row := range daily {
+		spend += row.Spend
+	}
+	if diff := spend - analysis.TotalSpend; diff > 0.01 || diff < -0.01 {
+		t.Errorf("Expected daily spend %.2f to match the total %.2f", spend, analysis.TotalSpend)
+	}
+
+	// The same request answers the same numbers
+	again, err := metrics.CollectDailyCampaignMetrics(timeRange)
+	if err != nil || len(again) != len(daily) || again[0] != daily[0] {
+		t.Errorf("Expected deterministic insights")
+	}
+
+	rollup, err := metrics.GenerateCreativeRollup(timeRange)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rollup.Creatives) == 0 {
+		t.Errorf("Expected creatives in the rollup")
+	}
+}
+
+func TestDemoCreateCampaign(t *testing.T) {
+	authClient, provider := newTestAuth()
+	creator := internal_campaign.NewCampaignCreator(authClient, "demo-create")
+
+	config := &models.CampaignConfig{
+		Name:        "Summer Sale",
+		Objective:   "OUTCOME_TRAFFIC",
+		BuyingType:  "AUCTION",
+		BidStrategy: "LOWEST_COST_WITHOUT_CAP",
+		DailyBudget: 40,
+		AdSets: []models.AdSetConfig{{
+			Name:             "Broad",
+			Targeting:        map[string]interface{}{"geo_locations": map[string]interface{}{"countries": []string{"US"}}},
+			OptimizationGoal: "LINK_CLICKS",
+			BillingEvent:     "IMPRESSIONS",
+		}},
+		Ads: []models.AdConfig{{
+			Name: "Summer Ad",
+			Creative: models.CreativeConfig{
+				Title:   "Summer sale",
+				Body:    "Everything 20% off",
+				LinkURL: "https://example.com/summer",
+				PageID:  provider.pages[0].ID,
+			},
+		}},
+	}
+
+	campaignID, err := creator.CreateFromConfigWithID(config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	details, err := api.NewClient(authClient, "demo-create").GetCampaignDetails(campaignID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if details.Name != "Summer Sale" || details.Status != "PAUSED" || len(details.AdSets) != 1 || len(details.Ads) != 1 {
+		t.Errorf("Expected the created campaign to be readable, got %+v", details)
+	}
+	if !internal_campaign.IsOwned(details.Labels) {
+		t.Errorf("Expected the created campaign to carry the ownership label, got %v", details.Labels)
+	}
+}
+
+func TestDemoAudience(t *testing.T) {
+	authClient, _ := newTestAuth()
+	analyzer := audience.NewAudienceAnalyzer(authClient, "demo-audience")
+
+	segments, err := analyzer.Search("adinterest", "", "hik")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(segments) != 1 || segments[0].Name != "Hiking" {
+		t.Fatalf("Expected the Hiking interest, got %+v", segments)
+	}
+
+	lower, upper, err := analyzer.GetAudienceSize(audience.TargetingSpec{Interests: []string{segments[0].ID}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lower <= 0 || upper < lower {
+		t.Errorf("Expected an audience size range, got %d - %d", lower, upper)
+	}
+
+	// Narrower targeting reaches fewer people
+	broad, err := analyzer.EstimateReach(map[string]interface{}{"geo_locations": map[string]interface{}{"countries": []string{"US"}}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	narrow, err := analyzer.EstimateReach(map[string]interface{}{
+		"geo_locations": map[string]interface{}{"countries": []string{"US"}},
+		"age_min":       25,
+		"age_max":       34,
+		"interests":     []map[string]interface{}{{"id": segments[0].ID}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if narrow <= 0 || narrow >= broad {
+		t.Errorf("Expected 0 < %d < %d", narrow, broad)
+	}
+}
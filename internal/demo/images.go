@@ -0,0 +1,57 @@
+package demo
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"net/url"
+)
+
+// placeholderImage is a 1x1 PNG answered for image downloads in demo mode
+var placeholderImage = []byte{
+	0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0x00, 0x00, 0x0d, 0x49, 0x48, 0x44, 0x52,
+	0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x08, 0x02, 0x00, 0x00, 0x00, 0x90, 0x77, 0x53,
+	0xde, 0x00, 0x00, 0x00, 0x0c, 0x49, 0x44, 0x41, 0x54, 0x78, 0x9c, 0x63, 0xd0, 0xeb, 0x0e, 0x07,
+	0x00, 0x01, 0xfb, 0x01, 0x11, 0x74, 0x26, 0x14, 0xe9, 0x00, 0x00, 0x00, 0x00, 0x49, 0x45, 0x4e,
+	0x44, 0xae, 0x42, 0x60, 0x82,
+}
+
+// uploadImage answers a POST to act_<id>/adimages. Like Facebook, the hash is the MD5 of
+// the image, so uploading the same image twice gives the same hash.
+func (p *Provider) uploadImage(params url.Values) (interface{}, error) {
+	data := params.Get("filename")
+	if data == "" {
+		data = params.Get("bytes")
+	}
+	if data == "" {
+		return nil, badRequest("(#100) No image was uploaded")
+	}
+
+	name := params.Get("name")
+	if name == "" {
+		name = "image"
+	}
+
+	sum := md5.Sum([]byte(data))
+	hash := hex.EncodeToString(sum[:])
+	return map[string]interface{}{
+		"images": map[string]interface{}{
+			name: map[string]interface{}{
+				"hash": hash,
+				"url":  demoImageURL(hash),
+			},
+		},
+	}, nil
+}
+
+// uploadVideo answers a POST to act_<id>/advideos with the ID of the new video
+func (p *Provider) uploadVideo(params url.Values) (interface{}, error) {
+	if params.Get("source") == "" && params.Get("file_url") == "" {
+		return nil, badRequest("(#100) No video was uploaded")
+	}
+	return map[string]interface{}{"id": p.newID()}, nil
+}
+
+// demoImageURL returns where an image of the demo image library is served
+func demoImageURL(hash string) string {
+	return "https://scontent.example/demo/" + hash + ".png"
+}
@@ -0,0 +1,510 @@
+package demo
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// delivery is what an object delivered in a period
+type delivery struct {
+	SpendCents  int64
+	Impressions int64
+	Clicks      int64
+	Conversions int64
+}
+
+// add sums two deliveries
+func (d delivery) add(other delivery) delivery {
+	return delivery{
+		SpendCents:  d.SpendCents + other.SpendCents,
+		Impressions: d.Impressions + other.Impressions,
+		Clicks:      d.Clicks + other.Clicks,
+		Conversions: d.Conversions + other.Conversions,
+	}
+}
+
+// split divides a delivery by weights so the parts add up to the whole
+func (d delivery) split(weights []float64) []delivery {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+
+	parts := make([]delivery, len(weights))
+	if total <= 0 {
+		return parts
+	}
+
+	left := d
+	for i, w := range weights {
+		if i == len(weights)-1 {
+			parts[i] = left
+			break
+		}
+		share := w / total
+		parts[i] = delivery{
+			SpendCents:  int64(float64(d.SpendCents) * share),
+			Impressions: int64(float64(d.Impressions) * share),
+			Clicks:      int64(float64(d.Clicks) * share),
+			Conversions: int64(float64(d.Conversions) * share),
+		}
+		left.SpendCents -= parts[i].SpendCents
+		left.Impressions -= parts[i].Impressions
+		left.Clicks -= parts[i].Clicks
+		left.Conversions -= parts[i].Conversions
+	}
+	return parts
+}
+
+// hashUnit returns a stable pseudo-random number in [0, 1) for the keys
+func hashUnit(keys ...string) float64 {
+	h := fnv.New64a()
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+	}
+	return float64(h.Sum64()>>11) / float64(1<<53)
+}
+
+// delivers reports whether a campaign delivered on a day
+func (p *Provider) delivers(c *campaign, day time.Time) bool {
+	if c.Status == models.CampaignStatusDeleted || day.After(p.today()) {
+		return false
+	}
+
+	start := c.Created
+	if !c.StartTime.IsZero() {
+		start = c.StartTime
+	}
+	if day.Before(startOfDay(start, p.location)) {
+		return false
+	}
+	if !c.StopTime.IsZero() && day.After(startOfDay(c.StopTime, p.location)) {
+		return false
+	}
+	return c.PausedAt.IsZero() || day.Before(c.PausedAt)
+}
+
+// dailyBudgetCents returns the budget a campaign spends from on one day
+func dailyBudgetCents(c *campaign) int64 {
+	if c.DailyBudget > 0 || c.LifetimeBudget == 0 {
+		return c.DailyBudget
+	}
+
+	// Lifetime budgets are paced evenly over the schedule
+	days := 30.0
+	if !c.StartTime.IsZero() && !c.StopTime.IsZero() {
+		days = math.Max(1, c.StopTime.Sub(c.StartTime).Hours()/24)
+	}
+	return int64(float64(c.LifetimeBudget) / days)
+}
+
+// campaignDay returns the delivery of a campaign on a day.
+// Numbers vary from day to day around the campaign baseline but are the same on every run.
+func (p *Provider) campaignDay(c *campaign, day time.Time) delivery {
+	if !p.delivers(c, day) {
+		return delivery{}
+	}
+
+	date := day.Format("2006-01-02")
+	jitter := func(salt string, spread float64) float64 {
+		return 1 - spread + 2*spread*hashUnit(c.ID, date, salt)
+	}
+
+	spend := float64(dailyBudgetCents(c)) * (0.85 + 0.15*hashUnit(c.ID, date, "pacing"))
+	impressions := spend / 100 / c.CPM * 1000 * jitter("cpm", 0.1)
+	clicks := impressions * c.CTR * jitter("ctr", 0.2)
+	conversions := clicks * c.CVR * jitter("cvr", 0.3)
+
+	return delivery{
+		SpendCents:  int64(math.Round(spend)),
+		Impressions: int64(math.Round(impressions)),
+		Clicks:      int64(math.Round(clicks)),
+		Conversions: int64(math.Round(conversions)),
+	}
+}
+
+// insightsUnit is an object insights are reported for at the requested level
+type insightsUnit struct {
+	fields   map[string]interface{} // Object IDs and names of the row
+	delivery delivery
+}
+
+// levelUnits returns the delivery of a campaign on a day split into units of the level
+func (p *Provider) levelUnits(c *campaign, day time.Time, level string) []insightsUnit {
+	total := p.campaignDay(c, day)
+	campaignFields := map[string]interface{}{"campaign_id": c.ID, "campaign_name": c.Name}
+
+	switch level {
+	case "account":
+		return []insightsUnit{{fields: map[string]interface{}{}, delivery: total}}
+	case "", "campaign":
+		return []insightsUnit{{fields: campaignFields, delivery: total}}
+	}
+
+	adSets := p.campaignAdSets(c.ID)
+	weights := make([]float64, len(adSets))
+	for i, s := range adSets {
+		weights[i] = s.Weight
+	}
+
+	var units []insightsUnit
+	for i, part := range total.split(weights) {
+		s := adSets[i]
+		adSetFields := copyFields(campaignFields)
+		adSetFields["adset_id"] = s.ID
+		adSetFields["adset_name"] = s.Name
+
+		if level == "adset" {
+			units = append(units, insightsUnit{fields: adSetFields, delivery: part})
+			continue
+		}
+
+		// Clicks and the conversions they bring follow the delivery of each ad weighted by its CTR
+		ads := p.adSetAds(s.ID)
+		adWeights := make([]float64, len(ads))
+		clickWeights := make([]float64, len(ads))
+		for j, a := range ads {
+			adWeights[j] = a.Weight
+			clickWeights[j] = a.Weight
+			if a.CTRFactor > 0 {
+				clickWeights[j] *= a.CTRFactor
+			}
+		}
+		clickParts := part.split(clickWeights)
+		for j, adPart := range part.split(adWeights) {
+			adPart.Clicks = clickParts[j].Clicks
+			adPart.Conversions = clickParts[j].Conversions
+			adFields := copyFields(adSetFields)
+			adFields["ad_id"] = ads[j].ID
+			adFields["ad_name"] = ads[j].Name
+			units = append(units, insightsUnit{fields: adFields, delivery: adPart})
+		}
+	}
+	return units
+}
+
+// insightsFilter is one entry of the filtering parameter
+type insightsFilter struct {
+	Field    string      `json:"field"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value"`
+}
+
+// insights answers an insights request. When only is set just that campaign is reported.
+// Objects without delivery in the period are left out, like the API does.
+func (p *Provider) insights(params url.Values, only *campaign) ([]map[string]interface{}, error) {
+	since, until, err := p.insightsRange(params)
+	if err != nil {
+		return nil, err
+	}
+
+	level := params.Get("level")
+	if level == "" && only == nil {
+		level = "account"
+	}
+	switch level {
+	case "", "account", "campaign", "adset", "ad":
+	default:
+		return nil, badRequest("(#100) level must be one of account, campaign, adset, ad")
+	}
+
+	daily := false
+	switch params.Get("time_increment") {
+	case "", "all_days":
+	case "1":
+		daily = true
+	default:
+		return nil, badRequest("demo mode supports time_increment 1 or all_days, got %s", params.Get("time_increment"))
+	}
+
+	var filters []insightsFilter
+	if raw := params.Get("filtering"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &filters); err != nil {
+			return nil, badRequest("(#100) Invalid filtering: %v", err)
+		}
+	}
+
+	// Sum the delivery of every unit per period
+	type periodKey struct{ start, unit string }
+	totals := make(map[periodKey]*insightsUnit)
+	var keys []periodKey
+
+	for day := since; !day.After(until); day = day.AddDate(0, 0, 1) {
+		for _, c := range p.campaigns {
+			if only != nil && c != only {
+				continue
+			}
+			for _, unit := range p.levelUnits(c, day, level) {
+				key := periodKey{unit: fmt.Sprint(unit.fields["campaign_id"], unit.fields["adset_id"], unit.fields["ad_id"])}
+				if daily {
+					key.start = day.Format("2006-01-02")
+				}
+				if total, ok := totals[key]; ok {
+					total.delivery = total.delivery.add(unit.delivery)
+					continue
+				}
+				u := unit
+				totals[key] = &u
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	rows := []map[string]interface{}{}
+	for _, key := range keys {
+		unit := totals[key]
+		if unit.delivery.Impressions == 0 {
+			continue
+		}
+
+		row := insightsRow(unit.fields, unit.delivery)
+		if daily {
+			row["date_start"], row["date_stop"] = key.start, key.start
+		} else {
+			row["date_start"], row["date_stop"] = since.Format("2006-01-02"), until.Format("2006-01-02")
+		}
+
+		keep, err := matchesFilters(row, filters)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			rows = append(rows, row)
+		}
+	}
+
+	if breakdown := params.Get("breakdowns"); breakdown != "" {
+		return breakDown(rows, breakdown)
+	}
+	return rows, nil
+}
+
+// insightsRange returns the days of time_range in the account timezone.
+// Without a time range the last 30 days before today are reported, like the API does.
+func (p *Provider) insightsRange(params url.Values) (time.Time, time.Time, error) {
+	today := p.today()
+	if params.Get("time_range") == "" {
+		return today.AddDate(0, 0, -30), today.AddDate(0, 0, -1), nil
+	}
+
+	var timeRange struct {
+		Since string `json:"since"`
+		Until string `json:"until"`
+	}
+	if err := json.Unmarshal([]byte(params.Get("time_range")), &timeRange); err != nil {
+		return time.Time{}, time.Time{}, badRequest("(#100) Invalid time_range: %v", err)
+	}
+
+	since, err := time.ParseInLocation("2006-01-02", timeRange.Since, p.location)
+	if err != nil {
+		return time.Time{}, time.Time{}, badRequest("(#100) Invalid time_range since: %s", timeRange.Since)
+	}
+	until, err := time.ParseInLocation("2006-01-02", timeRange.Until, p.location)
+	if err != nil {
+		return time.Time{}, time.Time{}, badRequest("(#100) Invalid time_range until: %s", timeRange.Until)
+	}
+	if until.Before(since) {
+		return time.Time{}, time.Time{}, badRequest("(#100) time_range since must not be after until")
+	}
+
+	return since, until, nil
+}
+
+// conversionValue is the order value of a demo conversion in dollars
+const conversionValue = 50.0
+
+// insightsRow formats a delivery as an insights row; the API returns numbers as strings
+func insightsRow(fields map[string]interface{}, d delivery) map[string]interface{} {
+	row := copyFields(fields)
+	spend := float64(d.SpendCents) / 100
+
+	row["spend"] = fmt.Sprintf("%.2f", spend)
+	row["impressions"] = strconv.FormatInt(d.Impressions, 10)
+	row["clicks"] = strconv.FormatInt(d.Clicks, 10)
+	if d.Impressions > 0 {
+		row["cpm"] = fmt.Sprintf("%.6f", spend/float64(d.Impressions)*1000)
+		row["ctr"] = fmt.Sprintf("%.6f", float64(d.Clicks)/float64(d.Impressions)*100)
+	}
+	if d.Clicks > 0 {
+		row["cpc"] = fmt.Sprintf("%.6f", spend/float64(d.Clicks))
+	}
+	if d.Conversions > 0 {
+		row["actions"] = []interface{}{
+			map[string]interface{}{"action_type": "link_click", "value": strconv.FormatInt(d.Clicks, 10)},
+			map[string]interface{}{"action_type": "offsite_conversion", "value": strconv.FormatInt(d.Conversions, 10)},
+		}
+		row["action_values"] = []interface{}{
+			map[string]interface{}{"action_type": "offsite_conversion", "value": fmt.Sprintf("%.2f", float64(d.Conversions)*conversionValue)},
+		}
+	}
+	return row
+}
+
+// matchesFilters reports whether a row passes the filters.
+// Object IDs can be filtered with IN or EQUAL, metrics with GREATER_THAN or LESS_THAN.
+func matchesFilters(row map[string]interface{}, filters []insightsFilter) (bool, error) {
+	for _, filter := range filters {
+		switch filter.Field {
+		case "campaign.id", "adset.id", "ad.id":
+			column := map[string]string{"campaign.id": "campaign_id", "adset.id": "adset_id", "ad.id": "ad_id"}[filter.Field]
+			id := fmt.Sprint(row[column])
+
+			var values []string
+			switch v := filter.Value.(type) {
+			case string:
+				values = []string{v}
+			case []interface{}:
+				for _, item := range v {
+					values = append(values, fmt.Sprint(item))
+				}
+			}
+
+			if filter.Operator != "IN" && filter.Operator != "EQUAL" {
+				return false, badRequest("demo mode does not support operator %s on %s", filter.Operator, filter.Field)
+			}
+			if !containsString(values, id) {
+				return false, nil
+			}
+
+		case "impressions", "clicks", "spend":
+			value, _ := strconv.ParseFloat(fmt.Sprint(row[filter.Field]), 64)
+			threshold, _ := strconv.ParseFloat(fmt.Sprint(filter.Value), 64)
+
+			switch filter.Operator {
+			case "GREATER_THAN":
+				if value <= threshold {
+					return false, nil
+				}
+			case "LESS_THAN":
+				if value >= threshold {
+					return false, nil
+				}
+			default:
+				return false, badRequest("demo mode does not support operator %s on %s", filter.Operator, filter.Field)
+			}
+
+		default:
+			return false, badRequest("demo mode does not support filtering on %s", filter.Field)
+		}
+	}
+	return true, nil
+}
+
+// ageBuckets is how delivery is distributed over age groups
+var ageBuckets = []struct {
+	Age    string
+	Weight float64
+}{
+	{"18-24", 0.12}, {"25-34", 0.30}, {"35-44", 0.26}, {"45-54", 0.17}, {"55-64", 0.10}, {"65+", 0.05},
+}
+
+// breakDown splits every row by the breakdown dimension; only age is supported
+func breakDown(rows []map[string]interface{}, breakdown string) ([]map[string]interface{}, error) {
+	if breakdown != "age" {
+		return nil, badRequest("demo mode does not support breakdowns=%s", breakdown)
+	}
+
+	weights := make([]float64, len(ageBuckets))
+	for i, bucket := range ageBuckets {
+		weights[i] = bucket.Weight
+	}
+
+	var result []map[string]interface{}
+	for _, row := range rows {
+		total := rowDelivery(row)
+		for i, part := range total.split(weights) {
+			if part.Impressions == 0 {
+				continue
+			}
+			fields := map[string]interface{}{}
+			for _, key := range []string{"campaign_id", "campaign_name", "adset_id", "adset_name", "ad_id", "ad_name", "date_start", "date_stop"} {
+				if value, ok := row[key]; ok {
+					fields[key] = value
+				}
+			}
+			fields["age"] = ageBuckets[i].Age
+			result = append(result, insightsRow(fields, part))
+		}
+	}
+	return result, nil
+}
+
+// rowDelivery reads the delivery back from an insights row
+func rowDelivery(row map[string]interface{}) delivery {
+	number := func(key string) int64 {
+		value, _ := strconv.ParseInt(fmt.Sprint(row[key]), 10, 64)
+		return value
+	}
+	spend, _ := strconv.ParseFloat(fmt.Sprint(row["spend"]), 64)
+
+	d := delivery{SpendCents: int64(math.Round(spend * 100)), Impressions: number("impressions"), Clicks: number("clicks")}
+	if actions, ok := row["actions"].([]interface{}); ok {
+		for _, action := range actions {
+			if a, ok := action.(map[string]interface{}); ok && a["action_type"] == "offsite_conversion" {
+				d.Conversions, _ = strconv.ParseInt(fmt.Sprint(a["value"]), 10, 64)
+			}
+		}
+	}
+	return d
+}
+
+// objectInsights answers <id>/insights for a campaign or the result of an async report run
+func (p *Provider) objectInsights(id string, params url.Values) (interface{}, error) {
+	if runParams, ok := p.reportRuns[id]; ok {
+		rows, err := p.insights(runParams, nil)
+		if err != nil {
+			return nil, err
+		}
+		return dataResponse(rows), nil
+	}
+
+	c := p.findCampaign(id)
+	if c == nil {
+		return nil, badRequest("demo mode only reports insights of campaigns, %s is not one", id)
+	}
+
+	rows, err := p.insights(params, c)
+	if err != nil {
+		return nil, err
+	}
+	return dataResponse(rows), nil
+}
+
+// startReportRun answers a POST to act_<id>/insights; the job completes immediately
+func (p *Provider) startReportRun(params url.Values) (interface{}, error) {
+	// Validate now so errors surface when the job is started
+	if _, err := p.insights(params, nil); err != nil {
+		return nil, err
+	}
+
+	id := p.newID()
+	p.reportRuns[id] = params
+	return map[string]interface{}{"report_run_id": id}, nil
+}
+
+// copyFields returns a shallow copy of a field map
+func copyFields(fields map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields)+4)
+	for key, value := range fields {
+		result[key] = value
+	}
+	return result
+}
+
+// containsString reports whether value is one of values
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
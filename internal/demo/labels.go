@@ -0,0 +1,109 @@
+package demo
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+)
+
+// labelList returns an ad label list in the Graph API format
+func labelList(names []string) []interface{} {
+	labels := []interface{}{}
+	for _, name := range names {
+		labels = append(labels, map[string]interface{}{"id": labelID(name), "name": name})
+	}
+	return labels
+}
+
+// labelNames returns the names of every ad label in the account
+func (p *Provider) labelNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(labels []string) {
+		for _, name := range labels {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	add(p.labels)
+	for _, c := range p.campaigns {
+		add(c.Labels)
+	}
+	for _, s := range p.adSets {
+		add(s.Labels)
+	}
+	for _, a := range p.ads {
+		add(a.Labels)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// listLabels answers act_<id>/adlabels
+func (p *Provider) listLabels() map[string]interface{} {
+	return dataResponse(labelList(p.labelNames()))
+}
+
+// createLabel answers a POST to act_<id>/adlabels; label names are unique in an account
+func (p *Provider) createLabel(params url.Values) (interface{}, error) {
+	name := params.Get("name")
+	if name == "" {
+		return nil, badRequest("(#100) The parameter name is required")
+	}
+
+	for _, existing := range p.labelNames() {
+		if existing == name {
+			return nil, badRequest("(#100) An ad label with the name %q already exists", name)
+		}
+	}
+
+	p.labels = append(p.labels, name)
+	return map[string]interface{}{"id": labelID(name)}, nil
+}
+
+// attachLabels answers a POST to <object_id>/adlabels
+func (p *Provider) attachLabels(id string, params url.Values) (interface{}, error) {
+	var refs []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(params.Get("adlabels")), &refs); err != nil {
+		return nil, badRequest("(#100) Invalid adlabels: %v", err)
+	}
+
+	var names []string
+	for _, ref := range refs {
+		name := ref.Name
+		for _, existing := range p.labelNames() {
+			if ref.ID != "" && labelID(existing) == ref.ID {
+				name = existing
+			}
+		}
+		if name == "" {
+			return nil, badRequest("(#100) Ad label %s does not exist", ref.ID)
+		}
+		names = append(names, name)
+	}
+
+	var labels *[]string
+	if c := p.findCampaign(id); c != nil {
+		labels = &c.Labels
+	} else if s := p.findAdSet(id); s != nil {
+		labels = &s.Labels
+	} else if a := p.findAd(id); a != nil {
+		labels = &a.Labels
+	} else {
+		return nil, badRequest("Unsupported post request. Object with ID '%s' does not exist", id)
+	}
+
+	for _, name := range names {
+		if !containsString(*labels, name) {
+			*labels = append(*labels, name)
+		}
+	}
+	return map[string]interface{}{"success": true}, nil
+}
@@ -7,10 +7,10 @@ import (
 
 // CampaignAdjustment represents CPM adjustment data for a campaign
 type CampaignAdjustment struct {
-	CampaignID   string
-	CurrentCPM   float64
-	AdjustedCPM  float64
-	AdjustmentTS time.Time
+	CampaignID   string    `json:"campaign_id"`
+	CurrentCPM   float64   `json:"current_cpm"`
+	AdjustedCPM  float64   `json:"adjusted_cpm"`
+	AdjustmentTS time.Time `json:"adjusted_at"`
 }
 
 // Adjuster provides methods for adjusting campaign CPM bids
@@ -21,6 +21,7 @@ type Adjuster struct {
 	incrementPercent float64
 	decrementPercent float64
 	waitHours        int // Hours to wait before applying another adjustment
+	now              func() time.Time
 }
 
 // NewAdjuster creates a new instance of Adjuster
@@ -32,9 +33,15 @@ func NewAdjuster(maxCPM, minCPM, incrementPercent, decrementPercent float64, wai
 		incrementPercent: incrementPercent,
 		decrementPercent: decrementPercent,
 		waitHours:        waitHours,
+		now:              time.Now,
 	}
 }
 
+// SetClock replaces the time source used for adjustment timestamps and cooldowns
+func (a *Adjuster) SetClock(now func() time.Time) {
+	a.now = now
+}
+
 // CalculateAdjustments calculates CPM adjustments for campaigns based on performance
 func (a *Adjuster) CalculateAdjustments(
 	campaigns []CampaignPerformance,
@@ -61,7 +68,7 @@ func (a *Adjuster) CalculateAdjustments(
 
 	// Generate new adjustments
 	adjustments := make([]CampaignAdjustment, 0, len(campaigns))
-	now := time.Now()
+	now := a.now()
 
 	for _, campaign := range campaigns {
 		// Skip campaigns that were adjusted recently (within waitHours)
@@ -143,7 +150,7 @@ func (a *Adjuster) GetEligibleCampaigns(
 
 	// Find eligible campaigns
 	eligible := make([]string, 0)
-	now := time.Now()
+	now := a.now()
 
 	for _, id := range campaignIDs {
 		// If no previous adjustment, campaign is eligible
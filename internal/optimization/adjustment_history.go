@@ -0,0 +1,89 @@
+package optimization
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// AdjustmentHistory is the local record of CPM adjustments applied to campaigns, persisted
+// as JSON so the adjuster's cooldown holds across runs
+type AdjustmentHistory struct {
+	path        string
+	Adjustments []CampaignAdjustment `json:"adjustments"`
+}
+
+// LoadAdjustmentHistory reads the history file, returning an empty history when it doesn't
+// exist yet. With an empty path the history is kept in memory only.
+func LoadAdjustmentHistory(path string) (*AdjustmentHistory, error) {
+	history := &AdjustmentHistory{path: path}
+	if path == "" {
+		return history, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading adjustment history: %w", err)
+	}
+
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, fmt.Errorf("error parsing adjustment history %s: %w", path, err)
+	}
+
+	return history, nil
+}
+
+// Save writes the history back to its file
+func (h *AdjustmentHistory) Save() error {
+	if h.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing adjustment history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("error creating adjustment history directory: %w", err)
+	}
+
+	// Write to a temp file first so an interrupted run never loses the history
+	tmpPath := h.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("error writing adjustment history: %w", err)
+	}
+	if err := os.Rename(tmpPath, h.path); err != nil {
+		return fmt.Errorf("error writing adjustment history: %w", err)
+	}
+
+	return nil
+}
+
+// Add records applied adjustments
+func (h *AdjustmentHistory) Add(adjustments ...CampaignAdjustment) {
+	h.Adjustments = append(h.Adjustments, adjustments...)
+}
+
+// Latest returns the most recent adjustment of every campaign, the previous adjustments the
+// adjuster and GetEligibleCampaigns expect
+func (h *AdjustmentHistory) Latest() []CampaignAdjustment {
+	index := make(map[string]int)
+	var latest []CampaignAdjustment
+	for _, adjustment := range h.Adjustments {
+		i, ok := index[adjustment.CampaignID]
+		if !ok {
+			index[adjustment.CampaignID] = len(latest)
+			latest = append(latest, adjustment)
+			continue
+		}
+		if adjustment.AdjustmentTS.After(latest[i].AdjustmentTS) {
+			latest[i] = adjustment
+		}
+	}
+	return latest
+}
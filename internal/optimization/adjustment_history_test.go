@@ -0,0 +1,42 @@
+package optimization
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAdjustmentHistory_CooldownAcrossRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats", "cpm_adjustments.json")
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	history, err := LoadAdjustmentHistory(path)
+	if err != nil {
+		t.Fatalf("LoadAdjustmentHistory failed: %v", err)
+	}
+	history.Add(
+		CampaignAdjustment{CampaignID: "a", CurrentCPM: 8, AdjustedCPM: 8.8, AdjustmentTS: now.Add(-72 * time.Hour)},
+		CampaignAdjustment{CampaignID: "a", CurrentCPM: 8.8, AdjustedCPM: 9.7, AdjustmentTS: now.Add(-24 * time.Hour)},
+		CampaignAdjustment{CampaignID: "b", CurrentCPM: 12, AdjustedCPM: 10.8, AdjustmentTS: now.Add(-50 * time.Hour)},
+	)
+	if err := history.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// A later run reads the history back
+	loaded, err := LoadAdjustmentHistory(path)
+	if err != nil {
+		t.Fatalf("LoadAdjustmentHistory failed: %v", err)
+	}
+	latest := loaded.Latest()
+	if len(latest) != 2 || latest[0].CampaignID != "a" || latest[0].AdjustedCPM != 9.7 {
+		t.Fatalf("Expected the latest adjustment of each campaign, got %+v", latest)
+	}
+
+	adjuster := NewAdjuster(15, 1, 10, 10, 48)
+	adjuster.SetClock(func() time.Time { return now })
+	eligible := adjuster.GetEligibleCampaigns([]string{"a", "b", "c"}, latest)
+	if len(eligible) != 2 || eligible[0] != "b" || eligible[1] != "c" {
+		t.Errorf("Expected a to be in its cooldown, got eligible %v", eligible)
+	}
+}
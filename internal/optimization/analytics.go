@@ -186,7 +186,7 @@ func (a *Analyzer) AnalyzeCampaign(
 	// If there are no other campaigns to compare with, return basic analytics
 	if len(allCampaigns) <= 1 {
 		analytics.PerformanceScore = 50.0 // Neutral score
-		analytics.RecommendedAction = "maintain"
+		analytics.RecommendedAction = ActionMaintain
 		return analytics
 	}
 	
@@ -247,31 +247,31 @@ func (a *Analyzer) determineRecommendedAction(
 ) string {
 	// Check if impressions are too low
 	if analytics.Impressions < a.minImpressions {
-		return "wait_for_data"
+		return ActionWaitForData
 	}
 	
 	// If the campaign is performing exceptionally well (top 10% score)
 	if analytics.PerformanceScore >= 90 {
-		return "increase_budget"
+		return ActionIncreaseBudget
 	}
 	
 	// If the CPC is higher than reference CPC (benchmark)
 	if analytics.CPC > a.referenceCPC * 1.2 {
-		return "optimize_creative"
+		return ActionOptimizeCreative
 	}
 	
 	// If the campaign is performing poorly (bottom 20% score)
 	if analytics.PerformanceScore <= 20 {
-		return "terminate"
+		return ActionTerminate
 	}
 	
 	// If the campaign is an anomaly with high CPC
 	if analytics.IsAnomaly && analytics.CPC > averageCPC {
-		return "decrease_budget"
+		return ActionDecreaseBudget
 	}
 	
 	// Default recommendation for average performing campaigns
-	return "maintain"
+	return ActionMaintain
 }
 
 // SortCampaignsByPerformance sorts campaigns by their performance (best to worst)
@@ -43,19 +43,56 @@ func (bc *BudgetCalculator) GetMainBudget() float64 {
 	return bc.TotalBudget - bc.GetTestBudget()
 }
 
-// GetBudgetPerCampaign calculates the budget for each test campaign
+// GetBudgetPerCampaign calculates the budget for each test campaign when the test budget
+// is split evenly
 func (bc *BudgetCalculator) GetBudgetPerCampaign(numCampaigns int) (float64, error) {
 	if numCampaigns <= 0 {
 		return 0, fmt.Errorf("number of campaigns must be greater than 0")
 	}
 	
-	testBudget := bc.GetTestBudget()
-	budgetPerCampaign := testBudget / float64(numCampaigns)
+	weights := make([]float64, numCampaigns)
+	for i := range weights {
+		weights[i] = 1
+	}
+	budgets, err := bc.GetWeightedBudgets(weights)
+	if err != nil {
+		return 0, err
+	}
+	
+	return budgets[0], nil
+}
+
+// GetWeightedBudgets splits the test budget across test campaigns in proportion to their
+// weights. Weights are normalized, so only their ratios matter; each must be positive.
+func (bc *BudgetCalculator) GetWeightedBudgets(weights []float64) ([]float64, error) {
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("number of campaigns must be greater than 0")
+	}
 	
-	// Round to 2 decimal places for currency
-	budgetPerCampaign = math.Round(budgetPerCampaign*100) / 100
+	totalWeight := 0.0
+	for i, weight := range weights {
+		if err := ValidateBudgetWeight(weight); err != nil {
+			return nil, fmt.Errorf("campaign %d: %w", i+1, err)
+		}
+		totalWeight += weight
+	}
+	
+	testBudget := bc.GetTestBudget()
+	budgets := make([]float64, len(weights))
+	for i, weight := range weights {
+		// Round to 2 decimal places for currency
+		budgets[i] = math.Round(testBudget*weight/totalWeight*100) / 100
+	}
 	
-	return budgetPerCampaign, nil
+	return budgets, nil
+}
+
+// ValidateBudgetWeight checks that a budget weight is a positive, finite number
+func ValidateBudgetWeight(weight float64) error {
+	if math.IsNaN(weight) || math.IsInf(weight, 0) || weight <= 0 {
+		return fmt.Errorf("weight must be a positive number, got %v", weight)
+	}
+	return nil
 }
 
 // CalculateImpressions estimates the number of impressions a campaign will get
@@ -1,6 +1,8 @@
 package optimization
 
 import (
+	"math"
+	"reflect"
 	"testing"
 )
 
@@ -146,6 +148,39 @@ func TestBudgetCalculator_GetBudgetPerCampaign(t *testing.T) {
 	}
 }
 
+func TestBudgetCalculator_GetWeightedBudgets(t *testing.T) {
+	bc, _ := NewBudgetCalculator(1000, 20, 15)
+
+	tests := []struct {
+		name    string
+		weights []float64
+		want    []float64
+		wantErr bool
+	}{
+		{name: "equal weights", weights: []float64{1, 1, 1, 1}, want: []float64{50, 50, 50, 50}},
+		{name: "weighted", weights: []float64{3, 1}, want: []float64{150, 50}},
+		{name: "normalized", weights: []float64{0.3, 0.1}, want: []float64{150, 50}},
+		{name: "rounded", weights: []float64{1, 1, 1}, want: []float64{66.67, 66.67, 66.67}},
+		{name: "no campaigns", weights: nil, wantErr: true},
+		{name: "zero weight", weights: []float64{1, 0}, wantErr: true},
+		{name: "negative weight", weights: []float64{2, -1}, wantErr: true},
+		{name: "infinite weight", weights: []float64{1, math.Inf(1)}, wantErr: true},
+		{name: "NaN weight", weights: []float64{math.NaN()}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := bc.GetWeightedBudgets(tt.weights)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetWeightedBudgets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetWeightedBudgets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestBudgetCalculator_CalculateImpressions(t *testing.T) {
 	bc, _ := NewBudgetCalculator(1000, 20, 15)
 	
@@ -0,0 +1,110 @@
+package optimization
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DefaultExperimentConfidence is the confidence an arm needs before it is called the winner
+const DefaultExperimentConfidence = 0.95
+
+// ExperimentCampaign is the delivery of one campaign assigned to an experiment arm
+type ExperimentCampaign struct {
+	CampaignID  string
+	Name        string
+	Arm         string
+	Spend       float64
+	Impressions int
+	Clicks      int
+	Conversions int
+}
+
+// ExperimentArm is the delivery of every campaign of one arm combined
+type ExperimentArm struct {
+	Arm            string   `json:"arm"`
+	CampaignIDs    []string `json:"campaign_ids"`
+	Spend          float64  `json:"spend"`
+	Impressions    int      `json:"impressions"`
+	Clicks         int      `json:"clicks"`
+	Conversions    int      `json:"conversions"`
+	CPA            float64  `json:"cpa"`             // 0 without conversions
+	ConversionRate float64  `json:"conversion_rate"` // Conversions per click
+}
+
+// ExperimentReport compares the arms of an experiment
+type ExperimentReport struct {
+	Name        string          `json:"name"`
+	Arms        []ExperimentArm `json:"arms"`
+	Leader      string          `json:"leader,omitempty"`    // Arm with the highest conversion rate
+	RunnerUp    string          `json:"runner_up,omitempty"` // Arm the leader is tested against
+	ZScore      float64         `json:"z_score"`
+	Confidence  float64         `json:"confidence"` // 1 - two-sided p-value
+	Significant bool            `json:"significant"`
+	Verdict     string          `json:"verdict"`
+}
+
+// AnalyzeExperiment aggregates campaigns per arm and tests whether the arm with the best
+// conversion rate (conversions per click) beats the runner-up at the required confidence.
+// Arms are ordered by name.
+func AnalyzeExperiment(name string, campaigns []ExperimentCampaign, confidence float64) ExperimentReport {
+	report := ExperimentReport{Name: name}
+
+	byArm := make(map[string]*ExperimentArm)
+	for _, c := range campaigns {
+		arm, ok := byArm[c.Arm]
+		if !ok {
+			arm = &ExperimentArm{Arm: c.Arm}
+			byArm[c.Arm] = arm
+		}
+		arm.CampaignIDs = append(arm.CampaignIDs, c.CampaignID)
+		arm.Spend += c.Spend
+		arm.Impressions += c.Impressions
+		arm.Clicks += c.Clicks
+		arm.Conversions += c.Conversions
+	}
+
+	for _, arm := range byArm {
+		if arm.Conversions > 0 {
+			arm.CPA = arm.Spend / float64(arm.Conversions)
+		}
+		if arm.Clicks > 0 {
+			arm.ConversionRate = float64(arm.Conversions) / float64(arm.Clicks)
+		}
+		report.Arms = append(report.Arms, *arm)
+	}
+	sort.Slice(report.Arms, func(i, j int) bool {
+		return report.Arms[i].Arm < report.Arms[j].Arm
+	})
+
+	// Only arms with clicks can be compared
+	var ranked []ExperimentArm
+	for _, arm := range report.Arms {
+		if arm.Clicks > 0 {
+			ranked = append(ranked, arm)
+		}
+	}
+	if len(ranked) < 2 {
+		report.Verdict = "Not enough data: at least two arms need clicks to be compared"
+		return report
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].ConversionRate > ranked[j].ConversionRate
+	})
+
+	leader, runnerUp := ranked[0], ranked[1]
+	report.Leader = leader.Arm
+	report.RunnerUp = runnerUp.Arm
+	report.ZScore = proportionZScore(leader.Conversions, leader.Clicks, runnerUp.Conversions, runnerUp.Clicks)
+	report.Confidence = 1 - math.Erfc(math.Abs(report.ZScore)/math.Sqrt2)
+	report.Significant = report.ZScore > 0 && report.Confidence >= confidence
+
+	if report.Significant {
+		report.Verdict = fmt.Sprintf("Arm %s wins: %.2f%% conversion rate vs %.2f%% for %s (%.1f%% confidence)",
+			leader.Arm, leader.ConversionRate*100, runnerUp.ConversionRate*100, runnerUp.Arm, report.Confidence*100)
+	} else {
+		report.Verdict = fmt.Sprintf("No significant difference yet: %s leads %s with %.1f%% confidence, %.0f%% needed",
+			leader.Arm, runnerUp.Arm, report.Confidence*100, confidence*100)
+	}
+	return report
+}
@@ -0,0 +1,85 @@
+package optimization
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeExperiment_AggregatesArms(t *testing.T) {
+	campaigns := []ExperimentCampaign{
+		{CampaignID: "1", Arm: "a", Spend: 300, Impressions: 40000, Clicks: 1000, Conversions: 30},
+		{CampaignID: "2", Arm: "b", Spend: 250, Impressions: 35000, Clicks: 900, Conversions: 60},
+		{CampaignID: "3", Arm: "a", Spend: 200, Impressions: 30000, Clicks: 1000, Conversions: 20},
+		{CampaignID: "4", Arm: "b", Spend: 250, Impressions: 35000, Clicks: 1100, Conversions: 70},
+	}
+
+	report := AnalyzeExperiment("landing-page", campaigns, DefaultExperimentConfidence)
+
+	if len(report.Arms) != 2 || report.Arms[0].Arm != "a" || report.Arms[1].Arm != "b" {
+		t.Fatalf("Expected arms a and b, got %+v", report.Arms)
+	}
+	a, b := report.Arms[0], report.Arms[1]
+	if len(a.CampaignIDs) != 2 || a.Spend != 500 || a.Clicks != 2000 || a.Conversions != 50 || a.CPA != 10 {
+		t.Errorf("Unexpected totals for arm a: %+v", a)
+	}
+	if b.Spend != 500 || b.Conversions != 130 || b.ConversionRate != 0.065 {
+		t.Errorf("Unexpected totals for arm b: %+v", b)
+	}
+
+	// 6.5% vs 2.5% on 2000 clicks each is far beyond noise
+	if !report.Significant || report.Leader != "b" || report.RunnerUp != "a" {
+		t.Errorf("Expected b to win significantly, got %+v", report)
+	}
+	if report.Confidence < 0.99 || !strings.HasPrefix(report.Verdict, "Arm b wins") {
+		t.Errorf("Expected a confident verdict for b, got %.4f: %s", report.Confidence, report.Verdict)
+	}
+}
+
+func TestAnalyzeExperiment_SignificanceCall(t *testing.T) {
+	tests := []struct {
+		name        string
+		campaigns   []ExperimentCampaign
+		significant bool
+		leader      string
+	}{
+		{
+			name: "difference within noise",
+			campaigns: []ExperimentCampaign{
+				{CampaignID: "1", Arm: "a", Clicks: 500, Conversions: 20},
+				{CampaignID: "2", Arm: "b", Clicks: 500, Conversions: 25},
+			},
+			significant: false,
+			leader:      "b",
+		},
+		{
+			name: "significant difference",
+			campaigns: []ExperimentCampaign{
+				{CampaignID: "1", Arm: "a", Clicks: 5000, Conversions: 200},
+				{CampaignID: "2", Arm: "b", Clicks: 5000, Conversions: 260},
+			},
+			significant: true,
+			leader:      "b",
+		},
+		{
+			name: "one arm without clicks",
+			campaigns: []ExperimentCampaign{
+				{CampaignID: "1", Arm: "a", Clicks: 5000, Conversions: 200},
+				{CampaignID: "2", Arm: "b"},
+			},
+			significant: false,
+			leader:      "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := AnalyzeExperiment("test", tt.campaigns, DefaultExperimentConfidence)
+			if report.Significant != tt.significant || report.Leader != tt.leader {
+				t.Errorf("Expected significant=%v leader=%q, got %+v", tt.significant, tt.leader, report)
+			}
+			if report.Verdict == "" {
+				t.Errorf("Expected a verdict")
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package optimization
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/user/fb-ads/pkg/models"
@@ -19,8 +20,11 @@ type CampaignCombination struct {
 	PlacementName   string
 	PlacementParams string
 	Budget          float64
+	Weight          float64 // Share of the test budget relative to the other combinations
 	BidAmount       float64
-	TargetingType   string // "audience" or "placement"
+	TargetingType   string    // "audience" or "placement"
+	StartTime       time.Time // When the test campaign starts, from the campaign schedule
+	EndTime         time.Time // When the test campaign ends, from the campaign schedule
 }
 
 // CampaignGenerator handles the generation of test campaign combinations
@@ -74,20 +78,10 @@ func (g *CampaignGenerator) GenerateAllCombinations() error {
 	// Reset combinations
 	g.Combinations = []CampaignCombination{}
 
-	// Calculate total number of combinations
-	totalCombinations := len(g.Config.Creatives) *
-		(len(g.Config.TargetingOptions.Audiences) + len(g.Config.TargetingOptions.Placements))
-
-	// If limit is set, adjust the total
-	actualTotal := totalCombinations
-	if g.Limit > 0 && g.Limit < totalCombinations {
-		actualTotal = g.Limit
-	}
-
-	// Calculate budget per campaign
-	budgetPerCampaign, err := g.BudgetCalc.GetBudgetPerCampaign(actualTotal)
+	// Every test campaign runs on the same schedule
+	startTime, endTime, err := g.Config.Campaign.Schedule.Window(time.Now())
 	if err != nil {
-		return fmt.Errorf("error calculating budget per campaign: %w", err)
+		return fmt.Errorf("error in campaign schedule: %w", err)
 	}
 
 	// Generate creative + audience combinations
@@ -100,9 +94,11 @@ func (g *CampaignGenerator) GenerateAllCombinations() error {
 				AudienceID:     audience.ID,
 				AudienceName:   audience.Name,
 				AudienceParams: audience.Parameters,
-				Budget:         budgetPerCampaign,
+				Weight:         audience.BudgetWeight(),
 				BidAmount:      g.Config.Campaign.MaxCPM,
 				TargetingType:  "audience",
+				StartTime:      startTime,
+				EndTime:        endTime,
 			}
 			audienceCombinations = append(audienceCombinations, combination)
 		}
@@ -118,9 +114,11 @@ func (g *CampaignGenerator) GenerateAllCombinations() error {
 				PlacementID:     placement.ID,
 				PlacementName:   placement.Name,
 				PlacementParams: placement.Position,
-				Budget:          budgetPerCampaign,
+				Weight:          placement.BudgetWeight(),
 				BidAmount:       g.Config.Campaign.MaxCPM,
 				TargetingType:   "placement",
+				StartTime:       startTime,
+				EndTime:         endTime,
 			}
 			placementCombinations = append(placementCombinations, combination)
 		}
@@ -138,6 +136,19 @@ func (g *CampaignGenerator) GenerateAllCombinations() error {
 		g.Combinations = g.Combinations[:g.Limit]
 	}
 
+	// Split the test budget across the generated combinations by their weights
+	weights := make([]float64, len(g.Combinations))
+	for i, combination := range g.Combinations {
+		weights[i] = combination.Weight
+	}
+	budgets, err := g.BudgetCalc.GetWeightedBudgets(weights)
+	if err != nil {
+		return fmt.Errorf("error calculating budget per campaign: %w", err)
+	}
+	for i := range g.Combinations {
+		g.Combinations[i].Budget = budgets[i]
+	}
+
 	return nil
 }
 
@@ -169,6 +180,21 @@ func (g *CampaignGenerator) TotalCombinations() int {
 	return len(g.Combinations)
 }
 
+// BudgetRange returns the smallest and largest budget of the generated combinations,
+// which differ when audiences or placements have weights
+func (g *CampaignGenerator) BudgetRange() (float64, float64) {
+	if len(g.Combinations) == 0 {
+		return 0, 0
+	}
+
+	lowest, highest := g.Combinations[0].Budget, g.Combinations[0].Budget
+	for _, combination := range g.Combinations[1:] {
+		lowest = math.Min(lowest, combination.Budget)
+		highest = math.Max(highest, combination.Budget)
+	}
+	return lowest, highest
+}
+
 // TotalBatches returns the total number of batches
 func (g *CampaignGenerator) TotalBatches() int {
 	if len(g.Combinations) == 0 {
@@ -183,11 +209,72 @@ func (g *CampaignGenerator) TotalBatches() int {
 	return batches
 }
 
-// ConvertToFacebookCampaign converts a combination to Facebook campaign config
+// ValidateTargeting checks the targeting specs of the configuration, see TargetingSpecs
+func (g *CampaignGenerator) ValidateTargeting() models.ValidationErrors {
+	var problems models.ValidationErrors
+	for _, spec := range g.TargetingSpecs() {
+		problems = append(problems, models.ValidateTargeting(spec.Path, spec.Targeting)...)
+	}
+	return problems
+}
+
+// TargetingSpecs returns the targeting every audience and placement of the configuration
+// produces, merged with the template. Each is reported at the audience or placement of the
+// YAML configuration it comes from, e.g. targeting_options.audiences[1].parameters, so a
+// problem is reported once rather than for every creative.
+func (g *CampaignGenerator) TargetingSpecs() []models.TargetingSpec {
+	var specs []models.TargetingSpec
+	for i, audience := range g.Config.TargetingOptions.Audiences {
+		combination := CampaignCombination{Name: audience.Name, AudienceParams: audience.Parameters, TargetingType: "audience"}
+		specs = append(specs, models.TargetingSpec{
+			Path:      fmt.Sprintf("targeting_options.audiences[%d].parameters", i),
+			Targeting: g.combinationTargeting(combination),
+		})
+	}
+	for i, placement := range g.Config.TargetingOptions.Placements {
+		combination := CampaignCombination{Name: placement.Name, PlacementParams: placement.Position, TargetingType: "placement"}
+		specs = append(specs, models.TargetingSpec{
+			Path:      fmt.Sprintf("targeting_options.placements[%d]", i),
+			Targeting: g.combinationTargeting(combination),
+		})
+	}
+	return specs
+}
+
+// combinationTargeting returns the targeting of the ad set generated for a combination
+func (g *CampaignGenerator) combinationTargeting(combination CampaignCombination) map[string]interface{} {
+	campaign := g.ConvertToFacebookCampaign(combination)
+	if len(campaign.AdSets) == 0 {
+		return nil
+	}
+	return campaign.AdSets[0].Targeting
+}
+
+// combinationWindow returns the start and end time of a combination's campaign. Combinations
+// built outside GenerateAllCombinations take them from the campaign schedule.
+func (g *CampaignGenerator) combinationWindow(combination CampaignCombination) (time.Time, time.Time) {
+	if !combination.StartTime.IsZero() && !combination.EndTime.IsZero() {
+		return combination.StartTime, combination.EndTime
+	}
+
+	now := time.Now()
+	start, end, err := g.Config.Campaign.Schedule.Window(now)
+	if err != nil {
+		// ParseYAMLConfig rejects invalid schedules, so this only guards hand-built configs
+		return now, now.AddDate(0, 0, DefaultTestDurationDays)
+	}
+	return start, end
+}
+
+// ConvertToFacebookCampaign converts a combination to Facebook campaign config. The
+// campaign and ad set run on the campaign schedule; a template keeps its own times
+// unless the configuration has a schedule.
 func (g *CampaignGenerator) ConvertToFacebookCampaign(combination CampaignCombination) *models.CampaignConfig {
 	// Generate a unique name with timestamp
 	timestamp := time.Now().Format("20060102-150405")
 	campaignName := fmt.Sprintf("%s (%s)", combination.Name, timestamp)
+	startTime, endTime := g.combinationWindow(combination)
+	scheduled := g.Config.Campaign.Schedule != nil
 
 	var campaign *models.CampaignConfig
 
@@ -200,8 +287,14 @@ func (g *CampaignGenerator) ConvertToFacebookCampaign(combination CampaignCombin
 		campaignCopy.Name = campaignName
 		campaignCopy.Status = "PAUSED" // Always start paused for safety
 		campaignCopy.LifetimeBudget = combination.Budget
+		campaignCopy.DailyBudget = 0 // Test campaigns spend their lifetime budget instead
+		if scheduled {
+			campaignCopy.StartTime = startTime.Format(time.RFC3339)
+			campaignCopy.EndTime = endTime.Format(time.RFC3339)
+		}
 
 		campaign = &campaignCopy
+		templateAds := campaign.AllAds()
 
 		// Add ad set specific for this combination
 		if len(campaign.AdSets) > 0 {
@@ -210,10 +303,16 @@ func (g *CampaignGenerator) ConvertToFacebookCampaign(combination CampaignCombin
 			adSetCopy.Name = fmt.Sprintf("AdSet - %s", campaignName)
 			adSetCopy.Status = "PAUSED"
 			adSetCopy.BidAmount = combination.BidAmount
+			adSetCopy.Ads = nil // The combination's ad is added below
+			if scheduled {
+				adSetCopy.StartTime = startTime.Format(time.RFC3339)
+				adSetCopy.EndTime = endTime.Format(time.RFC3339)
+			}
 
-			// Initialize targeting if needed
-			if adSetCopy.Targeting == nil {
-				adSetCopy.Targeting = make(map[string]interface{})
+			// Copy the targeting, so combinations don't write into the template and each other
+			adSetCopy.Targeting = make(map[string]interface{}, len(campaign.AdSets[0].Targeting))
+			for key, value := range campaign.AdSets[0].Targeting {
+				adSetCopy.Targeting[key] = value
 			}
 
 			// Apply targeting from combination
@@ -223,14 +322,14 @@ func (g *CampaignGenerator) ConvertToFacebookCampaign(combination CampaignCombin
 			campaign.AdSets = []models.AdSetConfig{adSetCopy}
 		} else {
 			// Create new ad set if none exists in template
-			adSet := createAdSet(campaignName, combination)
+			adSet := createAdSet(campaignName, combination, startTime, endTime)
 			campaign.AdSets = []models.AdSetConfig{adSet}
 		}
 
 		// Add ad specific for this combination
-		if len(campaign.Ads) > 0 {
+		if len(templateAds) > 0 {
 			// Use the first ad from template as a base
-			adCopy := campaign.Ads[0]
+			adCopy := *templateAds[0]
 			adCopy.Name = fmt.Sprintf("Ad - %s", campaignName)
 			adCopy.Status = "PAUSED"
 
@@ -250,17 +349,13 @@ func (g *CampaignGenerator) ConvertToFacebookCampaign(combination CampaignCombin
 			campaign.Ads = []models.AdConfig{ad}
 		}
 	} else {
-		// Calculate start and end times for lifetime budget
-		startTime := time.Now()
-		endTime := startTime.Add(7 * 24 * time.Hour) // End time is 7 days from now
-
 		// Create base campaign config
 		campaign = &models.CampaignConfig{
 			Name:           campaignName,
-			Status:         "PAUSED",            // Always start paused for safety
-			Objective:      "OUTCOME_AWARENESS", // Using awareness for test campaigns
+			Status:         string(models.CampaignStatusPaused), // Always start paused for safety
+			Objective:      string(models.ObjectiveAwareness),   // Using awareness for test campaigns
 			BuyingType:     "AUCTION",
-			BidStrategy:    "LOWEST_COST_WITH_BID_CAP",
+			BidStrategy:    string(models.BidStrategyLowestCostWithBidCap),
 			LifetimeBudget: combination.Budget,
 			StartTime:      startTime.Format(time.RFC3339),
 			EndTime:        endTime.Format(time.RFC3339), // Required for lifetime budget
@@ -269,7 +364,7 @@ func (g *CampaignGenerator) ConvertToFacebookCampaign(combination CampaignCombin
 		}
 
 		// Create ad set
-		adSet := createAdSet(campaignName, combination)
+		adSet := createAdSet(campaignName, combination, startTime, endTime)
 		campaign.AdSets = append(campaign.AdSets, adSet)
 
 		// Create ad
@@ -280,17 +375,13 @@ func (g *CampaignGenerator) ConvertToFacebookCampaign(combination CampaignCombin
 	return campaign
 }
 
-// createAdSet creates a new ad set for a combination
-func createAdSet(campaignName string, combination CampaignCombination) models.AdSetConfig {
-	// Calculate start and end times
-	startTime := time.Now()
-	endTime := startTime.Add(7 * 24 * time.Hour) // End time is 7 days from now
-
+// createAdSet creates a new ad set for a combination running from startTime to endTime
+func createAdSet(campaignName string, combination CampaignCombination, startTime, endTime time.Time) models.AdSetConfig {
 	adSet := models.AdSetConfig{
 		Name:             fmt.Sprintf("AdSet - %s", campaignName),
-		Status:           "PAUSED",
-		OptimizationGoal: "REACH",
-		BillingEvent:     "IMPRESSIONS",
+		Status:           string(models.CampaignStatusPaused),
+		OptimizationGoal: string(models.OptimizationGoalReach),
+		BillingEvent:     string(models.BillingEventImpressions),
 		BidAmount:        combination.BidAmount,
 		StartTime:        startTime.Format(time.RFC3339),
 		EndTime:          endTime.Format(time.RFC3339), // Required for lifetime budget
@@ -1,8 +1,11 @@
 package optimization
 
 import (
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/user/fb-ads/pkg/models"
 )
 
 func TestCampaignGenerator_GenerateAllCombinations(t *testing.T) {
@@ -250,4 +253,101 @@ func TestCampaignGenerator_ConvertToFacebookCampaign(t *testing.T) {
 	if expected, got := "123456789", ad.Creative.PageID; expected != got {
 		t.Errorf("Expected creative page ID %q, got %q", expected, got)
 	}
-}
\ No newline at end of file
+}
+func TestCampaignGenerator_ValidateTargeting(t *testing.T) {
+	config := &CampaignOptimizationConfig{
+		Campaign: CampaignConfig{Name: "Test Campaign", TotalBudget: 1000, TestBudgetPercentage: 20, MaxCPM: 15},
+		TargetingOptions: TargetingOptions{
+			Audiences: []AudienceConfig{
+				{ID: "audience1", Name: "Adults", Parameters: map[string]interface{}{
+					"age_min":       18,
+					"geo_locations": map[string]interface{}{"countries": []interface{}{"US"}},
+				}},
+				{ID: "audience2", Name: "Seniors", Parameters: map[string]interface{}{"age_min": 66}},
+			},
+			Placements: []PlacementConfig{{ID: "placement1", Name: "Feed", Position: "feed"}},
+		},
+	}
+	budgetCalc, _ := NewBudgetCalculator(1000, 20, 15)
+	generator := NewCampaignGenerator(config, budgetCalc)
+
+	var paths []string
+	for _, problem := range generator.ValidateTargeting().Errors() {
+		paths = append(paths, problem.Path)
+	}
+	expected := "targeting_options.audiences[1].parameters.geo_locations targeting_options.audiences[1].parameters.age_min"
+	if strings.Join(paths, " ") != expected {
+		t.Errorf("Expected problems at %s, got %v", expected, paths)
+	}
+}
+
+func TestCampaignGenerator_WeightsAndSchedule(t *testing.T) {
+	heavy := 3.0
+	config := &CampaignOptimizationConfig{
+		Campaign: CampaignConfig{
+			Name:                 "Test Campaign",
+			TotalBudget:          1000.00,
+			TestBudgetPercentage: 20,
+			MaxCPM:               15.00,
+			Schedule:             &ScheduleConfig{Start: "2030-06-01T00:00:00Z", End: "2030-06-15T00:00:00Z"},
+		},
+		Creatives: []CreativeConfig{{ID: "creative1", Title: "Creative 1", ImageURL: "https://example.com/image1.jpg"}},
+		TargetingOptions: TargetingOptions{
+			Audiences: []AudienceConfig{
+				{ID: "audience1", Name: "Audience 1", Parameters: map[string]interface{}{"age_min": 18}, Weight: &heavy},
+				{ID: "audience2", Name: "Audience 2", Parameters: map[string]interface{}{"age_min": 25}},
+			},
+			Placements: []PlacementConfig{{ID: "placement1", Name: "Placement 1", Position: "feed"}},
+		},
+	}
+
+	budgetCalc, _ := NewBudgetCalculator(config.Campaign.TotalBudget, config.Campaign.TestBudgetPercentage, config.Campaign.MaxCPM)
+	generator := NewCampaignGenerator(config, budgetCalc)
+	if err := generator.GenerateAllCombinations(); err != nil {
+		t.Fatalf("Error generating combinations: %v", err)
+	}
+
+	// The test budget of 200 is split 3:1:1
+	var budgets []float64
+	for _, combination := range generator.Combinations {
+		budgets = append(budgets, combination.Budget)
+	}
+	if !reflect.DeepEqual(budgets, []float64{120, 40, 40}) {
+		t.Errorf("Expected budgets [120 40 40], got %v", budgets)
+	}
+
+	// A limit splits the whole test budget across the combinations that are kept
+	generator.SetLimit(2)
+	if err := generator.GenerateAllCombinations(); err != nil {
+		t.Fatalf("Error generating combinations with limit: %v", err)
+	}
+	if got := []float64{generator.Combinations[0].Budget, generator.Combinations[1].Budget}; !reflect.DeepEqual(got, []float64{150, 50}) {
+		t.Errorf("Expected budgets [150 50] with a limit, got %v", got)
+	}
+
+	campaign := generator.ConvertToFacebookCampaign(generator.Combinations[0])
+	if campaign.StartTime != "2030-06-01T00:00:00Z" || campaign.EndTime != "2030-06-15T00:00:00Z" {
+		t.Errorf("Expected the campaign to run on the schedule, got %s to %s", campaign.StartTime, campaign.EndTime)
+	}
+	if adSet := campaign.AdSets[0]; adSet.StartTime != campaign.StartTime || adSet.EndTime != campaign.EndTime {
+		t.Errorf("Expected the ad set to run on the schedule, got %s to %s", adSet.StartTime, adSet.EndTime)
+	}
+
+	// The schedule replaces the times of a template
+	generator.SetTemplate(&models.CampaignConfig{
+		Name:      "Template",
+		StartTime: "2029-01-01T00:00:00Z",
+		EndTime:   "2029-01-08T00:00:00Z",
+		AdSets:    []models.AdSetConfig{{Name: "Template ad set", StartTime: "2029-01-01T00:00:00Z", Targeting: map[string]interface{}{}}},
+	})
+	campaign = generator.ConvertToFacebookCampaign(generator.Combinations[0])
+	if campaign.StartTime != "2030-06-01T00:00:00Z" || campaign.AdSets[0].EndTime != "2030-06-15T00:00:00Z" {
+		t.Errorf("Expected the schedule to replace the template times, got %s and %s", campaign.StartTime, campaign.AdSets[0].EndTime)
+	}
+
+	// Schedules are checked again when combinations are generated
+	config.Campaign.Schedule = &ScheduleConfig{Start: "2030-06-15", End: "2030-06-01"}
+	if err := generator.GenerateAllCombinations(); err == nil || !strings.Contains(err.Error(), "schedule") {
+		t.Errorf("Expected a schedule error, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package optimization
+
+import (
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// FromModel converts the shared campaign performance into the optimizer's representation
+func FromModel(perf models.CampaignPerformance) CampaignPerformance {
+	return CampaignPerformance{
+		CampaignID:  perf.CampaignID,
+		Impressions: perf.Impressions,
+		Clicks:      perf.Clicks,
+		Conversions: perf.Conversions,
+		Cost:        perf.Spend,
+		CPM:         perf.CPM,
+		CTR:         perf.CTR,
+		CPC:         perf.CPC,
+	}
+}
+
+// FromModels converts a slice of shared campaign performances
+func FromModels(perfs []models.CampaignPerformance) []CampaignPerformance {
+	result := make([]CampaignPerformance, len(perfs))
+	for i, perf := range perfs {
+		result[i] = FromModel(perf)
+	}
+	return result
+}
+
+// ToModel converts the optimizer's representation into the shared campaign performance.
+// Fields the optimizer does not track (name, CPA, ROAS, timestamps) are derived or left empty.
+func (c CampaignPerformance) ToModel() models.CampaignPerformance {
+	perf := models.CampaignPerformance{
+		CampaignID:  c.CampaignID,
+		Spend:       c.Cost,
+		Impressions: c.Impressions,
+		Clicks:      c.Clicks,
+		Conversions: c.Conversions,
+		CPC:         c.CPC,
+		CPM:         c.CPM,
+		CTR:         c.CTR,
+	}
+
+	if c.Conversions > 0 {
+		perf.CPA = c.Cost / float64(c.Conversions)
+	}
+
+	return perf
+}
@@ -0,0 +1,94 @@
+package optimization
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/models"
+	"github.com/user/fb-ads/pkg/utils"
+)
+
+// optimizerFieldNames maps optimizer fields to the shared model fields with a different name
+var optimizerFieldNames = map[string]string{
+	"Cost": "Spend",
+}
+
+func TestCampaignPerformance_FieldParity(t *testing.T) {
+	optimizerType := reflect.TypeOf(CampaignPerformance{})
+	modelType := reflect.TypeOf(models.CampaignPerformance{})
+
+	// Every optimizer field must exist in the shared model with the same type
+	for i := 0; i < optimizerType.NumField(); i++ {
+		field := optimizerType.Field(i)
+
+		name := field.Name
+		if mapped, ok := optimizerFieldNames[name]; ok {
+			name = mapped
+		}
+
+		modelField, ok := modelType.FieldByName(name)
+		if !ok {
+			t.Errorf("Field %s has no counterpart %s in models.CampaignPerformance", field.Name, name)
+			continue
+		}
+		if modelField.Type != field.Type {
+			t.Errorf("Field %s has type %s, model field %s has type %s", field.Name, field.Type, name, modelField.Type)
+		}
+	}
+
+	// The utils type is the shared model
+	if reflect.TypeOf(utils.CampaignPerformance{}) != modelType {
+		t.Errorf("utils.CampaignPerformance should be an alias of models.CampaignPerformance")
+	}
+}
+
+func TestCampaignPerformance_RoundTrip(t *testing.T) {
+	// Fill every optimizer field with a distinct non-zero value so a field
+	// missing from the conversion shows up as a zero after the round trip
+	original := CampaignPerformance{}
+	value := reflect.ValueOf(&original).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString("campaign-" + value.Type().Field(i).Name)
+		case reflect.Int:
+			field.SetInt(int64(100 + i))
+		case reflect.Float64:
+			field.SetFloat(float64(i) + 0.5)
+		default:
+			t.Fatalf("Unhandled field kind %s for %s", field.Kind(), value.Type().Field(i).Name)
+		}
+	}
+
+	model := original.ToModel()
+	if model.Spend != original.Cost {
+		t.Errorf("Expected Spend %.2f, got %.2f", original.Cost, model.Spend)
+	}
+
+	roundTrip := FromModel(model)
+	if !reflect.DeepEqual(roundTrip, original) {
+		t.Errorf("Round trip lost data:\n  got  %+v\n  want %+v", roundTrip, original)
+	}
+}
+
+func TestFromModels(t *testing.T) {
+	perfs := []models.CampaignPerformance{
+		{CampaignID: "1", Spend: 10, Impressions: 1000},
+		{CampaignID: "2", Spend: 20, Impressions: 2000},
+	}
+
+	converted := FromModels(perfs)
+	if len(converted) != 2 {
+		t.Fatalf("Expected 2 campaigns, got %d", len(converted))
+	}
+	if converted[1].CampaignID != "2" || converted[1].Cost != 20 || converted[1].Impressions != 2000 {
+		t.Errorf("Unexpected conversion: %+v", converted[1])
+	}
+
+	// Converted data can be fed straight into the analyzer
+	metrics := NewAnalyzer(0, 1.0).CalculatePerformanceMetrics(converted)
+	if metrics.TotalCost != 30 {
+		t.Errorf("Expected total cost 30, got %.2f", metrics.TotalCost)
+	}
+}
@@ -2,12 +2,22 @@ package optimization
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
+	"os"
+	"sync"
 	"time"
+
+	"github.com/user/fb-ads/pkg/auth"
 )
 
+// retryNotices receives the notices of the rate limiter. It is stderr, so the notices
+// don't end up in JSON or CSV written to stdout.
+var retryNotices io.Writer = os.Stderr
+
 // RateLimiter manages API rate limiting with exponential backoff
 type RateLimiter struct {
 	// Base delay for backoff (in milliseconds)
@@ -27,6 +37,10 @@ type RateLimiter struct {
 
 	// Minimum time between requests (in milliseconds)
 	MinRequestInterval time.Duration
+
+	// Ad accounts that hit their account level limit and when they regain access
+	accountCooldowns map[string]time.Time
+	mu               sync.Mutex
 }
 
 // NewRateLimiter creates a new rate limiter with default settings
@@ -57,9 +71,27 @@ func (r *RateLimiter) Wait() {
 
 // Execute executes a function with rate limiting and exponential backoff
 func (r *RateLimiter) Execute(ctx context.Context, operation func() error) error {
+	return r.ExecuteForAccount(ctx, "", operation)
+}
+
+// ExecuteForAccount executes a function against an ad account with rate limiting
+// and exponential backoff. When the account hits its account level limit the
+// operation is not retried; the account is put on cool-down and further calls
+// for it fail fast while other accounts keep running.
+func (r *RateLimiter) ExecuteForAccount(ctx context.Context, accountID string, operation func() error) error {
 	var lastErr error
 	
 	for retry := 0; retry <= r.MaxRetries; retry++ {
+		// Skip accounts that are still cooling down
+		if until, throttled := r.AccountThrottledUntil(accountID); throttled {
+			return &auth.ThrottleError{
+				AccountID:  accountID,
+				Scope:      auth.ThrottleScopeAccount,
+				RetryAfter: time.Until(until),
+				Until:      until,
+			}
+		}
+		
 		// Wait for rate limiting before attempting operation
 		r.Wait()
 		
@@ -72,6 +104,17 @@ func (r *RateLimiter) Execute(ctx context.Context, operation func() error) error
 		// Store the error
 		lastErr = err
 		
+		// Account level throttles need a long pause, so stop here and remember it
+		var throttle *auth.ThrottleError
+		if errors.As(err, &throttle) && throttle.Scope == auth.ThrottleScopeAccount {
+			if throttle.AccountID == "" {
+				throttle.AccountID = accountID
+			}
+			r.setAccountCooldown(throttle.AccountID, throttle.Until)
+			fmt.Fprintf(retryNotices, "Warning: %v\n", throttle)
+			return throttle
+		}
+		
 		// Check if context is cancelled before retrying
 		select {
 		case <-ctx.Done():
@@ -88,8 +131,16 @@ func (r *RateLimiter) Execute(ctx context.Context, operation func() error) error
 		// Calculate backoff delay
 		backoffDelay := r.calculateBackoff(retry)
 		
+		// App level throttles tell us how long to wait, bounded by the max delay
+		if throttle != nil && throttle.RetryAfter > backoffDelay {
+			backoffDelay = throttle.RetryAfter
+			if backoffDelay > r.MaxDelay {
+				backoffDelay = r.MaxDelay
+			}
+		}
+		
 		// Log or notify about the retry
-		fmt.Printf("Rate limit exceeded or error occurred. Retrying in %.2f seconds. Error: %v\n", 
+		fmt.Fprintf(retryNotices, "Rate limit exceeded or error occurred. Retrying in %.2f seconds. Error: %v\n",
 			backoffDelay.Seconds(), err)
 		
 		// Wait for backoff period
@@ -121,6 +172,43 @@ func (r *RateLimiter) calculateBackoff(retry int) time.Duration {
 	return time.Duration(delay) * time.Millisecond
 }
 
+// AccountThrottledUntil reports whether an ad account is cooling down and until when
+func (r *RateLimiter) AccountThrottledUntil(accountID string) (time.Time, bool) {
+	if accountID == "" {
+		return time.Time{}, false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until, ok := r.accountCooldowns[accountID]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	if time.Now().After(until) {
+		delete(r.accountCooldowns, accountID)
+		return time.Time{}, false
+	}
+
+	return until, true
+}
+
+// setAccountCooldown records when a throttled ad account regains access
+func (r *RateLimiter) setAccountCooldown(accountID string, until time.Time) {
+	if accountID == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.accountCooldowns == nil {
+		r.accountCooldowns = make(map[string]time.Time)
+	}
+	r.accountCooldowns[accountID] = until
+}
+
 // CanMakeRequest checks if a request can be made without waiting
 func (r *RateLimiter) CanMakeRequest() bool {
 	return time.Since(r.LastRequestTime) >= r.MinRequestInterval
@@ -3,8 +3,11 @@ package optimization
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/user/fb-ads/pkg/auth"
 )
 
 func TestRateLimiter_Wait(t *testing.T) {
@@ -191,4 +194,88 @@ func TestRateLimiter_CanMakeRequest(t *testing.T) {
 	if !limiter.CanMakeRequest() {
 		t.Error("After waiting the interval, CanMakeRequest should return true")
 	}
-}
\ No newline at end of file
+}
+
+func TestRateLimiter_AccountThrottleIsolation(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewRateLimiter()
+	limiter.SetRequestInterval(1 * time.Millisecond)
+	limiter.SetBaseDelay(1 * time.Millisecond)
+
+	// Account A hits its account level limit
+	callsA := 0
+	errA := limiter.ExecuteForAccount(ctx, "A", func() error {
+		callsA++
+		return &auth.ThrottleError{
+			AccountID:  "A",
+			Scope:      auth.ThrottleScopeAccount,
+			RetryAfter: time.Hour,
+			Until:      time.Now().Add(time.Hour),
+		}
+	})
+
+	var throttle *auth.ThrottleError
+	if !errors.As(errA, &throttle) {
+		t.Fatalf("Expected auth.ThrottleError, got %v", errA)
+	}
+
+	if callsA != 1 {
+		t.Errorf("Expected account throttle not to be retried, got %d calls", callsA)
+	}
+
+	// Further calls for account A fail fast without running the operation
+	errA = limiter.ExecuteForAccount(ctx, "A", func() error {
+		callsA++
+		return nil
+	})
+	if errA == nil || !strings.Contains(errA.Error(), "act_A throttled") {
+		t.Errorf("Expected account A to be throttled, got %v", errA)
+	}
+	if callsA != 1 {
+		t.Errorf("Expected no calls while account A cools down, got %d", callsA)
+	}
+
+	// Account B keeps running
+	callsB := 0
+	errB := limiter.ExecuteForAccount(ctx, "B", func() error {
+		callsB++
+		return nil
+	})
+	if errB != nil {
+		t.Errorf("Expected account B to succeed, got %v", errB)
+	}
+	if callsB != 1 {
+		t.Errorf("Expected 1 call for account B, got %d", callsB)
+	}
+
+	if _, throttled := limiter.AccountThrottledUntil("B"); throttled {
+		t.Errorf("Expected account B not to be throttled")
+	}
+}
+
+func TestRateLimiter_AppThrottleRetries(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewRateLimiter()
+	limiter.SetRequestInterval(1 * time.Millisecond)
+	limiter.SetBaseDelay(1 * time.Millisecond)
+	limiter.SetMaxDelay(5 * time.Millisecond) // Caps the app level retry-after
+
+	calls := 0
+	err := limiter.ExecuteForAccount(ctx, "A", func() error {
+		calls++
+		if calls < 3 {
+			return &auth.ThrottleError{Scope: auth.ThrottleScopeApp, RetryAfter: time.Minute}
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("Expected success after retries, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+	if _, throttled := limiter.AccountThrottledUntil("A"); throttled {
+		t.Errorf("Expected app level throttle not to put the account on cool-down")
+	}
+}
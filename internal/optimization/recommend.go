@@ -0,0 +1,53 @@
+package optimization
+
+// Actions recommended by the analyzer
+const (
+	ActionIncreaseBudget   = "increase_budget"
+	ActionDecreaseBudget   = "decrease_budget"
+	ActionTerminate        = "terminate"
+	ActionOptimizeCreative = "optimize_creative"
+	ActionMaintain         = "maintain"
+	ActionWaitForData      = "wait_for_data"
+)
+
+// BudgetStepPercent is how much a budget is raised or lowered for an increase_budget or
+// decrease_budget recommendation
+const BudgetStepPercent = 20.0
+
+// CampaignRecommendation combines the analyzer's view of a campaign with the CPM the
+// adjuster suggests for it
+type CampaignRecommendation struct {
+	Analytics  CampaignAnalytics
+	Adjustment CampaignAdjustment
+}
+
+// RecommendCampaignActions analyzes every campaign against the others and suggests a CPM
+// for it. Recommendations are returned in the order of campaigns.
+func RecommendCampaignActions(campaigns []CampaignPerformance, analyzer *Analyzer, adjuster *Adjuster) []CampaignRecommendation {
+	adjustments := make(map[string]CampaignAdjustment, len(campaigns))
+	for _, adjustment := range adjuster.CalculateAdjustments(campaigns, nil) {
+		adjustments[adjustment.CampaignID] = adjustment
+	}
+
+	recommendations := make([]CampaignRecommendation, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		recommendations = append(recommendations, CampaignRecommendation{
+			Analytics:  analyzer.AnalyzeCampaign(campaign, campaigns),
+			Adjustment: adjustments[campaign.CampaignID],
+		})
+	}
+	return recommendations
+}
+
+// BudgetFactor returns the factor a budget is multiplied by to follow an action, and
+// false for actions that do not change the budget
+func BudgetFactor(action string) (float64, bool) {
+	switch action {
+	case ActionIncreaseBudget:
+		return 1 + BudgetStepPercent/100, true
+	case ActionDecreaseBudget:
+		return 1 - BudgetStepPercent/100, true
+	default:
+		return 0, false
+	}
+}
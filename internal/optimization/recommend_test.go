@@ -0,0 +1,61 @@
+package optimization
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRecommendCampaignActions(t *testing.T) {
+	campaigns := []CampaignPerformance{
+		{CampaignID: "best", Impressions: 5000, Clicks: 100, Cost: 50, CPM: 10, CPC: 0.5},
+		{CampaignID: "middle", Impressions: 5000, Clicks: 80, Cost: 48, CPM: 9.6, CPC: 0.6},
+		{CampaignID: "worst", Impressions: 5000, Clicks: 40, Cost: 40, CPM: 8, CPC: 1.0},
+		{CampaignID: "new", Impressions: 200, Clicks: 2, Cost: 2, CPM: 10, CPC: 1.0},
+	}
+
+	analyzer := NewAnalyzer(1000, 2.0)
+	adjuster := NewAdjuster(15, 1, 10, 10, 24)
+	recommendations := RecommendCampaignActions(campaigns, analyzer, adjuster)
+
+	expected := map[string]string{
+		"best":   ActionIncreaseBudget,
+		"middle": ActionMaintain,
+		"worst":  ActionTerminate,
+		"new":    ActionWaitForData,
+	}
+	if len(recommendations) != len(campaigns) {
+		t.Fatalf("Expected %d recommendations, got %d", len(campaigns), len(recommendations))
+	}
+	for i, recommendation := range recommendations {
+		id := campaigns[i].CampaignID
+		if recommendation.Analytics.CampaignID != id {
+			t.Errorf("Expected recommendation %d for %s, got %s", i, id, recommendation.Analytics.CampaignID)
+		}
+		if recommendation.Analytics.RecommendedAction != expected[id] {
+			t.Errorf("Expected %s for %s, got %s", expected[id], id, recommendation.Analytics.RecommendedAction)
+		}
+		if recommendation.Adjustment.CampaignID != id || recommendation.Adjustment.CurrentCPM != campaigns[i].CPM {
+			t.Errorf("Unexpected adjustment for %s: %+v", id, recommendation.Adjustment)
+		}
+	}
+}
+
+func TestBudgetFactor(t *testing.T) {
+	tests := []struct {
+		action string
+		factor float64
+		ok     bool
+	}{
+		{ActionIncreaseBudget, 1.2, true},
+		{ActionDecreaseBudget, 0.8, true},
+		{ActionTerminate, 0, false},
+		{ActionMaintain, 0, false},
+	}
+
+	for _, tt := range tests {
+		factor, ok := BudgetFactor(tt.action)
+		if ok != tt.ok || math.Abs(factor-tt.factor) > 1e-9 {
+			t.Errorf("BudgetFactor(%s) = %v, %v; expected %v, %v", tt.action, factor, ok, tt.factor, tt.ok)
+		}
+	}
+}
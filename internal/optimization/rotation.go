@@ -0,0 +1,207 @@
+package optimization
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// RotationConfig controls how the delivery of sibling ads within an ad set is judged
+type RotationConfig struct {
+	MinAdSetImpressions int     // Ad set impressions before delivery shares are meaningful
+	MinAdImpressions    int     // Impressions an ad needs before it can be paused
+	StarvedShare        float64 // Ads below this share of the ad set impressions are flagged as starved
+	MinZScore           float64 // How many standard errors an ad's CTR must trail its siblings' to be significantly worse
+}
+
+// DefaultRotationConfig returns the default ad rotation settings
+func DefaultRotationConfig() RotationConfig {
+	return RotationConfig{
+		MinAdSetImpressions: 5000,
+		MinAdImpressions:    1000,
+		StarvedShare:        0.10,
+		MinZScore:           1.96, // 95% two-sided
+	}
+}
+
+// RotationAd is the delivery of one ad over the analyzed period
+type RotationAd struct {
+	AdID        string
+	AdName      string
+	AdSetID     string
+	AdSetName   string
+	Active      bool
+	Impressions int
+	Clicks      int
+	Conversions int
+	Spend       float64
+}
+
+// AdRotation is the analysis of one ad relative to its siblings
+type AdRotation struct {
+	RotationAd
+	Share       float64 `json:"share"`        // Share of the ad set impressions
+	CTR         float64 `json:"ctr"`          // Clicks per impression
+	CPA         float64 `json:"cpa"`          // 0 without conversions
+	RelativeCTR float64 `json:"relative_ctr"` // CTR divided by the siblings' CTR, 0 when siblings have no clicks
+	RelativeCPA float64 `json:"relative_cpa"` // CPA divided by the siblings' CPA, 0 when either has no conversions
+	ZScore      float64 `json:"z_score"`      // CTR difference to the siblings in standard errors
+	Starved     bool    `json:"starved"`
+	Pause       bool    `json:"pause"`
+	Reason      string  `json:"reason,omitempty"`
+}
+
+// AdSetRotationPlan is the rotation analysis of one ad set
+type AdSetRotationPlan struct {
+	AdSetID     string       `json:"adset_id"`
+	AdSetName   string       `json:"adset_name"`
+	Impressions int          `json:"impressions"`
+	Sufficient  bool         `json:"sufficient"` // Enough volume to judge delivery and performance
+	Ads         []AdRotation `json:"ads"`
+}
+
+// Pauses returns the ads the plan pauses
+func (p AdSetRotationPlan) Pauses() []AdRotation {
+	var pauses []AdRotation
+	for _, ad := range p.Ads {
+		if ad.Pause {
+			pauses = append(pauses, ad)
+		}
+	}
+	return pauses
+}
+
+// PlanAdRotation groups ads by ad set and decides which ads to pause.
+// An ad is paused when it is active, well delivered (not starved and with enough
+// impressions), its CTR is significantly below its siblings' and it does not make up
+// for it with a better CPA. An ad set always keeps at least one active ad.
+func PlanAdRotation(ads []RotationAd, config RotationConfig) []AdSetRotationPlan {
+	var order []string
+	byAdSet := make(map[string][]RotationAd)
+	for _, ad := range ads {
+		if _, ok := byAdSet[ad.AdSetID]; !ok {
+			order = append(order, ad.AdSetID)
+		}
+		byAdSet[ad.AdSetID] = append(byAdSet[ad.AdSetID], ad)
+	}
+
+	plans := make([]AdSetRotationPlan, 0, len(order))
+	for _, adSetID := range order {
+		plans = append(plans, planAdSet(byAdSet[adSetID], config))
+	}
+	return plans
+}
+
+// planAdSet analyzes the ads of one ad set
+func planAdSet(ads []RotationAd, config RotationConfig) AdSetRotationPlan {
+	plan := AdSetRotationPlan{AdSetID: ads[0].AdSetID, AdSetName: ads[0].AdSetName}
+
+	var clicks, conversions int
+	var spend float64
+	for _, ad := range ads {
+		plan.Impressions += ad.Impressions
+		clicks += ad.Clicks
+		conversions += ad.Conversions
+		spend += ad.Spend
+		if plan.AdSetName == "" {
+			plan.AdSetName = ad.AdSetName
+		}
+	}
+	plan.Sufficient = plan.Impressions >= config.MinAdSetImpressions
+
+	active := 0
+	for _, ad := range ads {
+		rotation := AdRotation{RotationAd: ad}
+		if ad.Active {
+			active++
+		}
+
+		if plan.Impressions > 0 {
+			rotation.Share = float64(ad.Impressions) / float64(plan.Impressions)
+		}
+		if ad.Impressions > 0 {
+			rotation.CTR = float64(ad.Clicks) / float64(ad.Impressions)
+		}
+		if ad.Conversions > 0 {
+			rotation.CPA = ad.Spend / float64(ad.Conversions)
+		}
+
+		// Siblings are every other ad of the ad set
+		siblingImpressions := plan.Impressions - ad.Impressions
+		siblingClicks := clicks - ad.Clicks
+		siblingConversions := conversions - ad.Conversions
+		if siblingImpressions > 0 && siblingClicks > 0 {
+			rotation.RelativeCTR = rotation.CTR / (float64(siblingClicks) / float64(siblingImpressions))
+		}
+		if rotation.CPA > 0 && siblingConversions > 0 {
+			rotation.RelativeCPA = rotation.CPA / ((spend - ad.Spend) / float64(siblingConversions))
+		}
+		rotation.ZScore = proportionZScore(ad.Clicks, ad.Impressions, siblingClicks, siblingImpressions)
+
+		if plan.Sufficient && ad.Active && len(ads) > 1 && rotation.Share < config.StarvedShare {
+			rotation.Starved = true
+		}
+
+		plan.Ads = append(plan.Ads, rotation)
+	}
+
+	if !plan.Sufficient {
+		return plan
+	}
+
+	// Pause the worst candidates first, as long as another active ad remains
+	var candidates []int
+	for i, rotation := range plan.Ads {
+		if pausable(rotation, conversions, config) {
+			candidates = append(candidates, i)
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return plan.Ads[candidates[a]].ZScore < plan.Ads[candidates[b]].ZScore
+	})
+
+	for _, i := range candidates {
+		if active <= 1 {
+			break
+		}
+		rotation := &plan.Ads[i]
+		rotation.Pause = true
+		rotation.Reason = fmt.Sprintf("CTR %.2f%% is %.1f standard errors below its siblings with %.0f%% of the delivery",
+			rotation.CTR*100, -rotation.ZScore, rotation.Share*100)
+		active--
+	}
+
+	return plan
+}
+
+// pausable reports whether an ad is well delivered and significantly worse than its siblings.
+// With conversions in the ad set, an ad converting at a CPA no worse than its siblings' is kept.
+func pausable(ad AdRotation, adSetConversions int, config RotationConfig) bool {
+	if !ad.Active || ad.Starved || ad.Impressions < config.MinAdImpressions {
+		return false
+	}
+	if ad.ZScore > -config.MinZScore {
+		return false
+	}
+	if adSetConversions > 0 && ad.Conversions > 0 && (ad.RelativeCPA == 0 || ad.RelativeCPA <= 1) {
+		return false
+	}
+	return true
+}
+
+// proportionZScore compares the click-through rates of two groups with a two-proportion z-test
+func proportionZScore(clicks1, impressions1, clicks2, impressions2 int) float64 {
+	if impressions1 == 0 || impressions2 == 0 {
+		return 0
+	}
+
+	p1 := float64(clicks1) / float64(impressions1)
+	p2 := float64(clicks2) / float64(impressions2)
+	pooled := float64(clicks1+clicks2) / float64(impressions1+impressions2)
+
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(impressions1) + 1/float64(impressions2)))
+	if se == 0 {
+		return 0
+	}
+	return (p1 - p2) / se
+}
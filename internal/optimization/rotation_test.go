@@ -0,0 +1,145 @@
+package optimization
+
+import (
+	"testing"
+)
+
+// rotationAd returns an active ad of ad set "s1"
+func rotationAd(id string, impressions, clicks int) RotationAd {
+	return RotationAd{AdID: id, AdSetID: "s1", AdSetName: "Broad", Active: true, Impressions: impressions, Clicks: clicks}
+}
+
+// pausedIDs returns the IDs of the ads a plan pauses
+func pausedIDs(plan AdSetRotationPlan) []string {
+	var ids []string
+	for _, ad := range plan.Pauses() {
+		ids = append(ids, ad.AdID)
+	}
+	return ids
+}
+
+func TestPlanAdRotation_PausesSignificantlyWorseAd(t *testing.T) {
+	plans := PlanAdRotation([]RotationAd{
+		rotationAd("a", 10000, 200), // 2.0%
+		rotationAd("b", 8000, 60),   // 0.75%
+		rotationAd("c", 500, 10),    // starved
+	}, DefaultRotationConfig())
+
+	if len(plans) != 1 {
+		t.Fatalf("Expected one ad set, got %d", len(plans))
+	}
+	plan := plans[0]
+	if ids := pausedIDs(plan); len(ids) != 1 || ids[0] != "b" {
+		t.Errorf("Expected ad b to be paused, got %v", ids)
+	}
+	if !plan.Ads[2].Starved || plan.Ads[0].Starved || plan.Ads[1].Starved {
+		t.Errorf("Expected only ad c to be flagged as starved, got %+v", plan.Ads)
+	}
+	if plan.Ads[2].Pause {
+		t.Errorf("Expected the starved ad not to be paused")
+	}
+}
+
+func TestPlanAdRotation_SignificanceGating(t *testing.T) {
+	tests := []struct {
+		name   string
+		ads    []RotationAd
+		paused int
+	}{
+		{
+			name:   "difference within noise",
+			ads:    []RotationAd{rotationAd("a", 5000, 60), rotationAd("b", 5000, 50)},
+			paused: 0,
+		},
+		{
+			name:   "ad set below minimum volume",
+			ads:    []RotationAd{rotationAd("a", 2000, 60), rotationAd("b", 2000, 5)},
+			paused: 0,
+		},
+		{
+			name:   "worse ad below minimum impressions",
+			ads:    []RotationAd{rotationAd("a", 5000, 150), rotationAd("b", 950, 1)},
+			paused: 0,
+		},
+		{
+			name: "worse CTR but better CPA",
+			ads: []RotationAd{
+				{AdID: "a", AdSetID: "s1", Active: true, Impressions: 6000, Clicks: 150, Conversions: 3, Spend: 60},
+				{AdID: "b", AdSetID: "s1", Active: true, Impressions: 6000, Clicks: 60, Conversions: 5, Spend: 60},
+			},
+			paused: 0,
+		},
+		{
+			name: "worse CTR and worse CPA",
+			ads: []RotationAd{
+				{AdID: "a", AdSetID: "s1", Active: true, Impressions: 6000, Clicks: 150, Conversions: 6, Spend: 60},
+				{AdID: "b", AdSetID: "s1", Active: true, Impressions: 6000, Clicks: 60, Conversions: 2, Spend: 60},
+			},
+			paused: 1,
+		},
+		{
+			name:   "significantly worse",
+			ads:    []RotationAd{rotationAd("a", 6000, 150), rotationAd("b", 6000, 60)},
+			paused: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plans := PlanAdRotation(tt.ads, DefaultRotationConfig())
+			if paused := pausedIDs(plans[0]); len(paused) != tt.paused {
+				t.Errorf("Expected %d paused ads, got %v", tt.paused, paused)
+			}
+		})
+	}
+}
+
+func TestPlanAdRotation_NeverPausesLastActiveAd(t *testing.T) {
+	// The good ad is already paused, so the remaining bad ad must keep running
+	good := rotationAd("a", 10000, 300)
+	good.Active = false
+	plans := PlanAdRotation([]RotationAd{good, rotationAd("b", 10000, 50)}, DefaultRotationConfig())
+	if paused := pausedIDs(plans[0]); len(paused) != 0 {
+		t.Errorf("Expected the last active ad to be kept, got %v", paused)
+	}
+
+	// Several bad ads: the worst are paused, one active ad always remains
+	ads := []RotationAd{
+		rotationAd("a", 10000, 80),
+		rotationAd("b", 10000, 20),
+		rotationAd("c", 10000, 40),
+	}
+	config := DefaultRotationConfig()
+	config.MinZScore = 0.5
+	plan := PlanAdRotation(ads, config)[0]
+
+	active := 0
+	for _, ad := range plan.Ads {
+		if ad.Active && !ad.Pause {
+			active++
+		}
+	}
+	if active < 1 {
+		t.Errorf("Expected at least one active ad, got plan %+v", plan.Ads)
+	}
+	if paused := pausedIDs(plan); len(paused) == 0 || paused[0] != "b" {
+		t.Errorf("Expected the worst ad b to be paused first, got %v", paused)
+	}
+}
+
+func TestPlanAdRotation_GroupsByAdSet(t *testing.T) {
+	other := rotationAd("x", 8000, 10)
+	other.AdSetID = "s2"
+	plans := PlanAdRotation([]RotationAd{rotationAd("a", 10000, 200), other, rotationAd("b", 8000, 60)}, DefaultRotationConfig())
+
+	if len(plans) != 2 || plans[0].AdSetID != "s1" || plans[1].AdSetID != "s2" {
+		t.Fatalf("Expected ad sets s1 and s2 in order of appearance, got %+v", plans)
+	}
+	if len(plans[0].Ads) != 2 || plans[0].Impressions != 18000 {
+		t.Errorf("Expected two ads with 18000 impressions in s1, got %+v", plans[0])
+	}
+	// An ad set with a single ad has no siblings to compare with
+	if len(pausedIDs(plans[1])) != 0 || plans[1].Ads[0].Starved {
+		t.Errorf("Expected the single ad of s2 to be left alone, got %+v", plans[1].Ads)
+	}
+}
@@ -0,0 +1,278 @@
+package optimization
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// Simulated action types
+const (
+	SimulatedActionPause     = "pause"
+	SimulatedActionAdjustCPM = "adjust_cpm"
+)
+
+// SimulationConfig controls how the optimizer is replayed over historical statistics
+type SimulationConfig struct {
+	WindowDays         int     // Days of statistics the optimizer sees on each simulated day
+	MinImpressions     int     // Minimum impressions for a campaign to be compared with others
+	ReferenceCPC       float64 // Benchmark CPC for the analyzer
+	CPCThresholdFactor float64 // Campaigns with a CPC this many times the median are paused
+	MaxCPM             float64
+	MinCPM             float64
+	IncrementPercent   float64
+	DecrementPercent   float64
+	CooldownHours      int // Hours between two CPM adjustments of the same campaign
+}
+
+// DefaultSimulationConfig returns the default simulation settings
+func DefaultSimulationConfig() SimulationConfig {
+	return SimulationConfig{
+		WindowDays:         7,
+		MinImpressions:     1000,
+		ReferenceCPC:       1.0,
+		CPCThresholdFactor: 1.5,
+		MaxCPM:             15.0,
+		MinCPM:             1.0,
+		IncrementPercent:   10,
+		DecrementPercent:   10,
+		CooldownHours:      24,
+	}
+}
+
+// SimulatedAction is an action the optimizer would have taken on a given day
+type SimulatedAction struct {
+	Date       time.Time `json:"date"`
+	CampaignID string    `json:"campaign_id"`
+	Action     string    `json:"action"`
+	OldCPM     float64   `json:"old_cpm,omitempty"`
+	NewCPM     float64   `json:"new_cpm,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+// SimulationImpact compares what paused campaigns actually did after the day
+// they would have been paused with the campaigns that would have been kept
+type SimulationImpact struct {
+	PausedCampaigns       []string `json:"paused_campaigns"`
+	Adjustments           int      `json:"adjustments"`
+	SpendAfterPause       float64  `json:"spend_after_pause"` // Spend the simulation would have saved
+	ConversionsAfterPause int      `json:"conversions_after_pause"`
+	PausedCPA             float64  `json:"paused_cpa"`
+	KeptSpend             float64  `json:"kept_spend"`
+	KeptConversions       int      `json:"kept_conversions"`
+	KeptCPA               float64  `json:"kept_cpa"`
+	// Conversions the saved spend would have bought at the kept campaigns' CPA,
+	// minus the conversions the paused campaigns actually delivered
+	NetConversions float64 `json:"net_conversions"`
+}
+
+// SimulationResult is the outcome of replaying the optimizer over a period
+type SimulationResult struct {
+	Start   time.Time         `json:"start"`
+	End     time.Time         `json:"end"`
+	Actions []SimulatedAction `json:"actions"`
+	Impact  SimulationImpact  `json:"impact"`
+}
+
+// SimulateOptimization replays daily statistics from start to end through the
+// validator, terminator, analyzer and adjuster as if the optimizer had run at
+// the end of every day. It only records what would have happened; nothing is
+// changed. history holds daily records per campaign keyed by LastUpdated and
+// should include WindowDays of data before start.
+func SimulateOptimization(history map[string][]models.CampaignPerformance, start, end time.Time, config SimulationConfig) *SimulationResult {
+	result := &SimulationResult{Start: start, End: end}
+
+	campaignIDs := make([]string, 0, len(history))
+	for campaignID := range history {
+		campaignIDs = append(campaignIDs, campaignID)
+	}
+	sort.Strings(campaignIDs)
+
+	validator := NewPerformanceValidator()
+	terminator := NewTerminator(config.MinImpressions)
+	analyzer := NewAnalyzer(config.MinImpressions, config.ReferenceCPC)
+
+	// The adjuster runs on simulated time so cooldowns are measured between simulated days
+	var now time.Time
+	adjuster := NewAdjuster(config.MaxCPM, config.MinCPM, config.IncrementPercent, config.DecrementPercent, config.CooldownHours)
+	adjuster.SetClock(func() time.Time { return now })
+
+	paused := make(map[string]string) // Campaign ID to the day it was paused
+	lastAdjustments := make(map[string]CampaignAdjustment)
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		// The optimizer runs once the day's statistics are complete
+		now = day.AddDate(0, 0, 1)
+		dayKey := day.Format("2006-01-02")
+		windowStart := day.AddDate(0, 0, 1-config.WindowDays).Format("2006-01-02")
+
+		var active []CampaignPerformance
+		for _, campaignID := range campaignIDs {
+			if _, ok := paused[campaignID]; ok {
+				continue
+			}
+
+			window := recordsBetween(history[campaignID], windowStart, dayKey)
+			if !validator.ValidateCampaignData(campaignID, window).IsValid {
+				continue
+			}
+			active = append(active, aggregatePerformance(campaignID, window))
+		}
+
+		if len(active) == 0 {
+			continue
+		}
+
+		// Pause campaigns whose CPC is far above the median
+		medianCPC := medianValidCPC(active, config.MinImpressions)
+		pausing := make(map[string]bool)
+		for _, campaignID := range terminator.GetUnderperformingCampaigns(active, config.CPCThresholdFactor) {
+			perf := findPerformance(active, campaignID)
+			analytics := analyzer.AnalyzeCampaign(perf, active)
+
+			pausing[campaignID] = true
+			paused[campaignID] = dayKey
+			result.Actions = append(result.Actions, SimulatedAction{
+				Date:       day,
+				CampaignID: campaignID,
+				Action:     SimulatedActionPause,
+				Reason: fmt.Sprintf("CPC $%.2f is %.1fx the median $%.2f (score %.0f, analyzer: %s)",
+					perf.CPC, perf.CPC/medianCPC, medianCPC, analytics.PerformanceScore, analytics.RecommendedAction),
+			})
+		}
+
+		// Adjust the CPM of the campaigns that keep running
+		var remaining []CampaignPerformance
+		for _, perf := range active {
+			if !pausing[perf.CampaignID] {
+				remaining = append(remaining, perf)
+			}
+		}
+
+		previous := make([]CampaignAdjustment, 0, len(lastAdjustments))
+		for _, adjustment := range lastAdjustments {
+			previous = append(previous, adjustment)
+		}
+
+		for _, adjustment := range adjuster.CalculateAdjustments(remaining, previous) {
+			// Campaigns still in their cooldown keep their previous timestamp
+			if !adjustment.AdjustmentTS.Equal(now) || math.Abs(adjustment.AdjustedCPM-adjustment.CurrentCPM) < 0.01 {
+				continue
+			}
+
+			lastAdjustments[adjustment.CampaignID] = adjustment
+			result.Actions = append(result.Actions, SimulatedAction{
+				Date:       day,
+				CampaignID: adjustment.CampaignID,
+				Action:     SimulatedActionAdjustCPM,
+				OldCPM:     adjustment.CurrentCPM,
+				NewCPM:     adjustment.AdjustedCPM,
+			})
+		}
+	}
+
+	result.Impact = estimateImpact(history, campaignIDs, paused, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	for _, action := range result.Actions {
+		if action.Action == SimulatedActionAdjustCPM {
+			result.Impact.Adjustments++
+		}
+	}
+
+	return result
+}
+
+// estimateImpact compares the realized results of paused campaigns after their
+// pause day with the campaigns that would have kept running
+func estimateImpact(history map[string][]models.CampaignPerformance, campaignIDs []string, paused map[string]string, startKey, endKey string) SimulationImpact {
+	var impact SimulationImpact
+
+	for _, campaignID := range campaignIDs {
+		pauseDay, isPaused := paused[campaignID]
+		if isPaused {
+			impact.PausedCampaigns = append(impact.PausedCampaigns, campaignID)
+		}
+
+		for _, perf := range history[campaignID] {
+			key := perf.LastUpdated.Format("2006-01-02")
+			if key < startKey || key > endKey {
+				continue
+			}
+
+			switch {
+			case isPaused && key > pauseDay:
+				impact.SpendAfterPause += perf.Spend
+				impact.ConversionsAfterPause += perf.Conversions
+			case !isPaused:
+				impact.KeptSpend += perf.Spend
+				impact.KeptConversions += perf.Conversions
+			}
+		}
+	}
+
+	if impact.ConversionsAfterPause > 0 {
+		impact.PausedCPA = impact.SpendAfterPause / float64(impact.ConversionsAfterPause)
+	}
+	if impact.KeptConversions > 0 {
+		impact.KeptCPA = impact.KeptSpend / float64(impact.KeptConversions)
+		impact.NetConversions = impact.SpendAfterPause/impact.KeptCPA - float64(impact.ConversionsAfterPause)
+	}
+
+	return impact
+}
+
+// recordsBetween returns the records dated from startKey to endKey inclusive
+func recordsBetween(records []models.CampaignPerformance, startKey, endKey string) []models.CampaignPerformance {
+	var window []models.CampaignPerformance
+	for _, perf := range records {
+		key := perf.LastUpdated.Format("2006-01-02")
+		if key >= startKey && key <= endKey {
+			window = append(window, perf)
+		}
+	}
+	return window
+}
+
+// aggregatePerformance sums daily records into one performance with derived rates
+func aggregatePerformance(campaignID string, records []models.CampaignPerformance) CampaignPerformance {
+	perf := CampaignPerformance{CampaignID: campaignID}
+	for _, record := range records {
+		perf.Impressions += record.Impressions
+		perf.Clicks += record.Clicks
+		perf.Conversions += record.Conversions
+		perf.Cost += record.Spend
+	}
+
+	if perf.Impressions > 0 {
+		perf.CPM = perf.Cost / float64(perf.Impressions) * 1000
+		perf.CTR = float64(perf.Clicks) / float64(perf.Impressions) * 100
+	}
+	if perf.Clicks > 0 {
+		perf.CPC = perf.Cost / float64(perf.Clicks)
+	}
+
+	return perf
+}
+
+// medianValidCPC returns the median CPC of the campaigns the terminator compares
+func medianValidCPC(campaigns []CampaignPerformance, minImpressions int) float64 {
+	var cpcValues []float64
+	for _, campaign := range campaigns {
+		if campaign.Impressions >= minImpressions {
+			cpcValues = append(cpcValues, campaign.CPC)
+		}
+	}
+	return calculateMedian(cpcValues)
+}
+
+// findPerformance returns the performance of a campaign from a list
+func findPerformance(campaigns []CampaignPerformance, campaignID string) CampaignPerformance {
+	for _, campaign := range campaigns {
+		if campaign.CampaignID == campaignID {
+			return campaign
+		}
+	}
+	return CampaignPerformance{CampaignID: campaignID}
+}
@@ -0,0 +1,112 @@
+package optimization
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// syntheticMonth returns 30 days of statistics for three efficient campaigns and one with a high CPC
+func syntheticMonth(start time.Time) map[string][]models.CampaignPerformance {
+	campaigns := []struct {
+		id          string
+		impressions int
+		clicks      int
+		spend       float64
+		conversions int
+	}{
+		{"a", 2000, 40, 20, 4},
+		{"b", 2500, 50, 30, 5},
+		{"c", 1800, 30, 18, 3},
+		{"d", 2000, 20, 40, 1},
+	}
+
+	history := make(map[string][]models.CampaignPerformance)
+	for day := 0; day < 30; day++ {
+		date := start.AddDate(0, 0, day)
+		for _, c := range campaigns {
+			history[c.id] = append(history[c.id], models.CampaignPerformance{
+				CampaignID:  c.id,
+				Impressions: c.impressions,
+				Clicks:      c.clicks,
+				Spend:       c.spend,
+				Conversions: c.conversions,
+				LastUpdated: date,
+			})
+		}
+	}
+	return history
+}
+
+func TestSimulateOptimization(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 29)
+	config := DefaultSimulationConfig()
+	config.CooldownHours = 72
+
+	result := SimulateOptimization(syntheticMonth(start), start, end, config)
+
+	// The first day has a single data point, so the high-CPC campaign is paused on the second
+	var pauses []SimulatedAction
+	for _, action := range result.Actions {
+		if action.Action == SimulatedActionPause {
+			pauses = append(pauses, action)
+		}
+		if action.CampaignID == "d" && action.Date.After(start.AddDate(0, 0, 1)) {
+			t.Errorf("Expected no actions on campaign d after it was paused, got %+v", action)
+		}
+	}
+	if len(pauses) != 1 || pauses[0].CampaignID != "d" || !pauses[0].Date.Equal(start.AddDate(0, 0, 1)) {
+		t.Fatalf("Expected campaign d to be paused on day 2, got %+v", pauses)
+	}
+
+	// CPM adjustments respect the cooldown
+	lastAdjusted := make(map[string]time.Time)
+	for _, action := range result.Actions {
+		if action.Action != SimulatedActionAdjustCPM {
+			continue
+		}
+		if last, ok := lastAdjusted[action.CampaignID]; ok && action.Date.Sub(last) < 72*time.Hour {
+			t.Errorf("Campaign %s adjusted on %s only %s after the previous adjustment",
+				action.CampaignID, action.Date.Format("2006-01-02"), action.Date.Sub(last))
+		}
+		lastAdjusted[action.CampaignID] = action.Date
+	}
+	if result.Impact.Adjustments == 0 {
+		t.Errorf("Expected some CPM adjustments")
+	}
+
+	// Impact is measured on what the paused campaign actually spent after day 2
+	impact := result.Impact
+	if !reflect.DeepEqual(impact.PausedCampaigns, []string{"d"}) {
+		t.Errorf("Expected campaign d to be reported as paused, got %v", impact.PausedCampaigns)
+	}
+	if impact.SpendAfterPause != 28*40 || impact.ConversionsAfterPause != 28 {
+		t.Errorf("Expected $1120 and 28 conversions after the pause, got $%.2f and %d", impact.SpendAfterPause, impact.ConversionsAfterPause)
+	}
+	if impact.PausedCPA != 40 {
+		t.Errorf("Expected paused CPA $40, got $%.2f", impact.PausedCPA)
+	}
+	if math.Abs(impact.KeptCPA-68.0/12) > 1e-9 {
+		t.Errorf("Expected kept CPA $%.2f, got $%.2f", 68.0/12, impact.KeptCPA)
+	}
+	if impact.NetConversions <= 0 {
+		t.Errorf("Expected the saved spend to buy more conversions elsewhere, got %.2f", impact.NetConversions)
+	}
+}
+
+func TestSimulateOptimization_Deterministic(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 29)
+
+	first := SimulateOptimization(syntheticMonth(start), start, end, DefaultSimulationConfig())
+	for i := 0; i < 5; i++ {
+		again := SimulateOptimization(syntheticMonth(start), start, end, DefaultSimulationConfig())
+		if !reflect.DeepEqual(first, again) {
+			t.Fatalf("Expected identical simulations, run %d differs:\n%+v\n%+v", i+2, first.Actions, again.Actions)
+		}
+	}
+}
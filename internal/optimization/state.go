@@ -0,0 +1,161 @@
+package optimization
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// CreatedCampaign is a test campaign created for one combination
+type CreatedCampaign struct {
+	Combination string    `json:"combination"` // CampaignCombination.Key
+	CampaignID  string    `json:"campaign_id"`
+	Name        string    `json:"name"`
+	CreatedAt   time.Time `json:"created_at"`
+	Incomplete  bool      `json:"incomplete,omitempty"` // Created, but an ad set or ad failed
+}
+
+// CreationState records which combinations of an optimization configuration already have a
+// campaign, so a later run creates only the rest. It is persisted as JSON.
+type CreationState struct {
+	path      string
+	Name      string            `json:"name"`
+	Campaigns []CreatedCampaign `json:"campaigns"`
+}
+
+// Key identifies the combination across runs: its creative and its audience or placement
+func (c CampaignCombination) Key() string {
+	if c.TargetingType == "placement" {
+		return fmt.Sprintf("%s/placement/%s", c.Creative.ID, c.PlacementID)
+	}
+	return fmt.Sprintf("%s/audience/%s", c.Creative.ID, c.AudienceID)
+}
+
+// CreationStatePath returns where the state of the configuration named name is kept:
+// <dir>/optimize/<name>/state.json, with the name reduced to a safe directory name
+func CreationStatePath(dir, name string) string {
+	slug := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, name)
+	slug = strings.Trim(slug, "-")
+	for strings.Contains(slug, "--") {
+		slug = strings.ReplaceAll(slug, "--", "-")
+	}
+	if slug == "" {
+		slug = "unnamed"
+	}
+	return filepath.Join(dir, "optimize", slug, "state.json")
+}
+
+// LoadCreationState reads the state file, returning an empty state when it doesn't exist yet.
+// With an empty path the state is kept in memory only.
+func LoadCreationState(path, name string) (*CreationState, error) {
+	state := &CreationState{path: path, Name: name}
+	if path == "" {
+		return state, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading creation state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("error parsing creation state %s: %w", path, err)
+	}
+
+	return state, nil
+}
+
+// Path returns the file the state is saved to
+func (s *CreationState) Path() string {
+	return s.path
+}
+
+// Save writes the state back to its file
+func (s *CreationState) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing creation state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("error creating state directory: %w", err)
+	}
+
+	// Write to a temp file first so an interrupted run never loses the created IDs
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("error writing creation state: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("error writing creation state: %w", err)
+	}
+
+	return nil
+}
+
+// Created reports whether the combination already has a campaign
+func (s *CreationState) Created(combination CampaignCombination) bool {
+	key := combination.Key()
+	for _, campaign := range s.Campaigns {
+		if campaign.Combination == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Record adds the campaign created for a combination
+func (s *CreationState) Record(combination CampaignCombination, campaignID, name string, now time.Time) {
+	s.Campaigns = append(s.Campaigns, CreatedCampaign{
+		Combination: combination.Key(),
+		CampaignID:  campaignID,
+		Name:        name,
+		CreatedAt:   now,
+	})
+}
+
+// RecordIncomplete adds a campaign that was created for a combination but failed part way,
+// e.g. while creating its ad set. The combination counts as created, so a resumed run
+// doesn't create a second campaign; the incomplete one has to be finished or deleted.
+func (s *CreationState) RecordIncomplete(combination CampaignCombination, campaignID, name string, now time.Time) {
+	s.Record(combination, campaignID, name, now)
+	s.Campaigns[len(s.Campaigns)-1].Incomplete = true
+}
+
+// Incomplete returns the campaigns that were left incomplete
+func (s *CreationState) Incomplete() []CreatedCampaign {
+	var incomplete []CreatedCampaign
+	for _, campaign := range s.Campaigns {
+		if campaign.Incomplete {
+			incomplete = append(incomplete, campaign)
+		}
+	}
+	return incomplete
+}
+
+// Remaining returns the combinations that have no campaign yet, in their original order
+func (s *CreationState) Remaining(combinations []CampaignCombination) []CampaignCombination {
+	var remaining []CampaignCombination
+	for _, combination := range combinations {
+		if !s.Created(combination) {
+			remaining = append(remaining, combination)
+		}
+	}
+	return remaining
+}
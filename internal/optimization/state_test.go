@@ -0,0 +1,107 @@
+package optimization
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCreationStatePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"Summer Sale", filepath.Join("/cfg", "optimize", "summer-sale", "state.json")},
+		{"  Test: A/B (v2) ", filepath.Join("/cfg", "optimize", "test-a-b-v2", "state.json")},
+		{"///", filepath.Join("/cfg", "optimize", "unnamed", "state.json")},
+	}
+
+	for _, tt := range tests {
+		if got := CreationStatePath("/cfg", tt.name); got != tt.expected {
+			t.Errorf("CreationStatePath(%q) = %s, expected %s", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestCreationState_RecordAndResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "optimize", "test", "state.json")
+
+	state, err := LoadCreationState(path, "Test")
+	if err != nil {
+		t.Fatalf("LoadCreationState failed: %v", err)
+	}
+	if len(state.Campaigns) != 0 {
+		t.Fatalf("Expected an empty state for a missing file, got %+v", state.Campaigns)
+	}
+
+	// Two creatives share an audience, so names alone would not tell them apart
+	combinations := []CampaignCombination{
+		{Name: "Test - Young", Creative: CreativeConfig{ID: "c1"}, AudienceID: "a1", TargetingType: "audience"},
+		{Name: "Test - Young", Creative: CreativeConfig{ID: "c2"}, AudienceID: "a1", TargetingType: "audience"},
+		{Name: "Test - Feed", Creative: CreativeConfig{ID: "c1"}, PlacementID: "p1", TargetingType: "placement"},
+	}
+
+	state.Record(combinations[0], "111", "Test - Young (20240101-120000)", time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	if err := state.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadCreationState(path, "Test")
+	if err != nil {
+		t.Fatalf("LoadCreationState failed: %v", err)
+	}
+	if len(loaded.Campaigns) != 1 || loaded.Campaigns[0].CampaignID != "111" || loaded.Campaigns[0].Combination != "c1/audience/a1" {
+		t.Fatalf("Unexpected campaigns after reload: %+v", loaded.Campaigns)
+	}
+
+	remaining := loaded.Remaining(combinations)
+	if len(remaining) != 2 || remaining[0].Creative.ID != "c2" || remaining[1].Key() != "c1/placement/p1" {
+		t.Errorf("Expected the two combinations without a campaign, got %+v", remaining)
+	}
+}
+
+func TestCreationState_RecordIncomplete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	state, err := LoadCreationState(path, "Test")
+	if err != nil {
+		t.Fatalf("LoadCreationState failed: %v", err)
+	}
+
+	combinations := []CampaignCombination{
+		{Creative: CreativeConfig{ID: "c1"}, AudienceID: "a1", TargetingType: "audience"},
+		{Creative: CreativeConfig{ID: "c1"}, AudienceID: "a2", TargetingType: "audience"},
+	}
+	state.Record(combinations[0], "111", "Test - A1", time.Now())
+	state.RecordIncomplete(combinations[1], "222", "Test - A2", time.Now())
+	if err := state.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadCreationState(path, "Test")
+	if err != nil {
+		t.Fatalf("LoadCreationState failed: %v", err)
+	}
+	if remaining := loaded.Remaining(combinations); len(remaining) != 0 {
+		t.Errorf("Expected an incomplete campaign not to be created again, got %+v", remaining)
+	}
+	incomplete := loaded.Incomplete()
+	if len(incomplete) != 1 || incomplete[0].CampaignID != "222" {
+		t.Errorf("Expected campaign 222 to be incomplete, got %+v", incomplete)
+	}
+}
+
+func TestCreationState_InMemory(t *testing.T) {
+	state, err := LoadCreationState("", "Test")
+	if err != nil {
+		t.Fatalf("LoadCreationState failed: %v", err)
+	}
+
+	combination := CampaignCombination{Creative: CreativeConfig{ID: "c1"}, AudienceID: "a1", TargetingType: "audience"}
+	state.Record(combination, "111", "Test", time.Now())
+	if err := state.Save(); err != nil {
+		t.Fatalf("Save without a path failed: %v", err)
+	}
+	if !state.Created(combination) {
+		t.Errorf("Expected the combination to be recorded in memory")
+	}
+}
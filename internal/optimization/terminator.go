@@ -4,7 +4,8 @@ import (
 	"sort"
 )
 
-// CampaignPerformance represents the performance metrics of a campaign
+// CampaignPerformance represents the performance metrics of a campaign as seen by the optimizer.
+// Cost corresponds to Spend in models.CampaignPerformance; use FromModel and ToModel to convert.
 type CampaignPerformance struct {
 	CampaignID   string
 	Impressions  int
@@ -114,6 +115,17 @@ func (t *Terminator) GetUnderperformingCampaigns(campaigns []CampaignPerformance
 	return underperforming
 }
 
+// MedianCPC returns the median CPC of the campaigns with the minimum impressions, the
+// baseline GetUnderperformingCampaigns compares against
+func (t *Terminator) MedianCPC(campaigns []CampaignPerformance) float64 {
+	validCampaigns := t.filterValidCampaigns(campaigns)
+	cpcValues := make([]float64, len(validCampaigns))
+	for i, campaign := range validCampaigns {
+		cpcValues[i] = campaign.CPC
+	}
+	return calculateMedian(cpcValues)
+}
+
 // calculateMedian calculates the median value of a slice of float64 values
 func calculateMedian(values []float64) float64 {
 	if len(values) == 0 {
@@ -207,3 +207,17 @@ func TestCalculateMedian(t *testing.T) {
 		})
 	}
 }
+
+func TestMedianCPC(t *testing.T) {
+	terminator := NewTerminator(1000)
+	campaigns := []CampaignPerformance{
+		{CampaignID: "a", Impressions: 2000, CPC: 0.5},
+		{CampaignID: "b", Impressions: 2000, CPC: 1.5},
+		{CampaignID: "c", Impressions: 2000, CPC: 1.0},
+		{CampaignID: "d", Impressions: 100, CPC: 9.0}, // Below the minimum, ignored
+	}
+
+	if median := terminator.MedianCPC(campaigns); median != 1.0 {
+		t.Errorf("Expected a median CPC of 1.0, got %v", median)
+	}
+}
@@ -98,8 +98,7 @@ func (v *PerformanceValidator) ValidateCampaignData(
 	}
 
 	// Find earliest and latest data points to calculate runtime
-	earliestTime := performances[0].LastUpdated
-	latestTime := performances[0].LastUpdated
+	var earliestTime, latestTime time.Time
 
 	// Accumulate metrics
 	for _, perf := range performances {
@@ -107,8 +106,13 @@ func (v *PerformanceValidator) ValidateCampaignData(
 		result.Metrics.TotalClicks += perf.Clicks
 		result.Metrics.TotalSpend += perf.Spend
 
+		// Records without a timestamp would stretch the runtime back to year 1
+		if perf.LastUpdated.IsZero() {
+			continue
+		}
+
 		// Update earliest/latest timestamps
-		if perf.LastUpdated.Before(earliestTime) {
+		if earliestTime.IsZero() || perf.LastUpdated.Before(earliestTime) {
 			earliestTime = perf.LastUpdated
 		}
 		if perf.LastUpdated.After(latestTime) {
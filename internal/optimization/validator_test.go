@@ -230,6 +230,27 @@ func TestValidateCampaignData(t *testing.T) {
 			t.Errorf("Did not expect reason about insufficient clicks, got: %v", result.Reasons)
 		}
 	})
+
+	// Test case: records without a timestamp don't count towards the runtime
+	t.Run("ZeroTimestamp", func(t *testing.T) {
+		now := time.Now()
+		performances := []utils.CampaignPerformance{
+			{CampaignID: "test-campaign", Impressions: 1000, Clicks: 20, Spend: 10.0},
+			{CampaignID: "test-campaign", Impressions: 1000, Clicks: 20, Spend: 10.0, LastUpdated: now.Add(-2 * time.Hour)},
+			{CampaignID: "test-campaign", Impressions: 1000, Clicks: 20, Spend: 10.0, LastUpdated: now},
+		}
+
+		result := validator.ValidateCampaignData("test-campaign", performances)
+		if result.RunningTime != 2*time.Hour {
+			t.Errorf("Expected running time of 2h from dated records, got %v", result.RunningTime)
+		}
+		if result.EarliestData.IsZero() {
+			t.Errorf("Expected earliest data to ignore the zero timestamp")
+		}
+		if result.Metrics.TotalImpressions != 3000 {
+			t.Errorf("Expected all impressions to be counted, got %d", result.Metrics.TotalImpressions)
+		}
+	})
 }
 
 func TestValidateCampaignsData(t *testing.T) {
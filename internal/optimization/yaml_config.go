@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
+	"github.com/user/fb-ads/pkg/models"
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,10 +19,71 @@ type CampaignOptimizationConfig struct {
 
 // CampaignConfig represents the campaign configuration section
 type CampaignConfig struct {
-	Name                 string  `yaml:"name"`
-	TotalBudget          float64 `yaml:"total_budget"`
-	TestBudgetPercentage float64 `yaml:"test_budget_percentage"`
-	MaxCPM               float64 `yaml:"max_cpm"`
+	Name                 string          `yaml:"name"`
+	TotalBudget          float64         `yaml:"total_budget"`
+	TestBudgetPercentage float64         `yaml:"test_budget_percentage"`
+	MaxCPM               float64         `yaml:"max_cpm"`
+	Schedule             *ScheduleConfig `yaml:"schedule,omitempty"` // When the test campaigns run, 7 days from creation when unset
+}
+
+// DefaultTestDurationDays is how long test campaigns run when the schedule doesn't say
+const DefaultTestDurationDays = 7
+
+// ScheduleConfig sets when the test campaigns run. Start and end are dates (YYYY-MM-DD)
+// or RFC3339 timestamps; without a start the campaigns start when they are created, and
+// without an end they run for duration_days.
+type ScheduleConfig struct {
+	Start        string `yaml:"start,omitempty"`
+	End          string `yaml:"end,omitempty"`
+	DurationDays int    `yaml:"duration_days,omitempty"`
+}
+
+// Window returns the start and end time of the test campaigns, with now as the start when
+// none is set. A nil schedule runs DefaultTestDurationDays from now.
+func (s *ScheduleConfig) Window(now time.Time) (time.Time, time.Time, error) {
+	if s == nil {
+		return now, now.AddDate(0, 0, DefaultTestDurationDays), nil
+	}
+
+	start := now
+	if s.Start != "" {
+		parsed, err := parseScheduleTime(s.Start)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start: %w", err)
+		}
+		start = parsed
+	}
+
+	var end time.Time
+	switch {
+	case s.End != "":
+		parsed, err := parseScheduleTime(s.End)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end: %w", err)
+		}
+		end = parsed
+	case s.DurationDays > 0:
+		end = start.AddDate(0, 0, s.DurationDays)
+	default:
+		end = start.AddDate(0, 0, DefaultTestDurationDays)
+	}
+
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end %s is not after start %s", end.Format(time.RFC3339), start.Format(time.RFC3339))
+	}
+	return start, end, nil
+}
+
+// parseScheduleTime parses a schedule date (YYYY-MM-DD, midnight local time) or RFC3339 timestamp
+func parseScheduleTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a date (YYYY-MM-DD) nor an RFC3339 timestamp", value)
+	}
+	return t, nil
 }
 
 // CreativeConfig represents an ad creative configuration
@@ -45,13 +108,33 @@ type AudienceConfig struct {
 	ID         string                 `yaml:"id"`
 	Name       string                 `yaml:"name"`
 	Parameters map[string]interface{} `yaml:"parameters"`
+	Weight     *float64               `yaml:"weight,omitempty"` // Share of the test budget relative to the others, 1 when unset
+}
+
+// BudgetWeight returns the weight of the audience in the test budget split
+func (a AudienceConfig) BudgetWeight() float64 {
+	return budgetWeight(a.Weight)
 }
 
 // PlacementConfig represents an ad placement configuration
 type PlacementConfig struct {
 	ID       string `yaml:"id"`
 	Name     string `yaml:"name"`
-	Position string `yaml:"position"`
+	Position string   `yaml:"position"`
+	Weight   *float64 `yaml:"weight,omitempty"` // Share of the test budget relative to the others, 1 when unset
+}
+
+// BudgetWeight returns the weight of the placement in the test budget split
+func (p PlacementConfig) BudgetWeight() float64 {
+	return budgetWeight(p.Weight)
+}
+
+// budgetWeight returns a configured weight, or 1 when none is set
+func budgetWeight(weight *float64) float64 {
+	if weight == nil {
+		return 1
+	}
+	return *weight
 }
 
 // ParseYAMLConfig parses a YAML file into a CampaignOptimizationConfig
@@ -81,97 +164,122 @@ func ParseYAMLReader(reader io.Reader) (*CampaignOptimizationConfig, error) {
 	return config, nil
 }
 
-// validateConfig checks if the configuration is valid
+// validateConfig checks if the configuration is valid.
+// Every problem is reported at once as models.ValidationErrors.
 func validateConfig(config *CampaignOptimizationConfig) error {
+	var problems models.ValidationErrors
+
 	// Validate campaign section
 	if config.Campaign.Name == "" {
-		return fmt.Errorf("campaign name is required")
+		problems.Add("campaign.name", "campaign name is required")
 	}
 	
 	if config.Campaign.TotalBudget <= 0 {
-		return fmt.Errorf("total budget must be greater than 0")
+		problems.Add("campaign.total_budget", "total budget must be greater than 0")
 	}
 	
 	if config.Campaign.TestBudgetPercentage <= 0 || config.Campaign.TestBudgetPercentage > 100 {
-		return fmt.Errorf("test budget percentage must be between 0 and 100")
+		problems.Add("campaign.test_budget_percentage", "test budget percentage must be between 0 and 100")
 	}
 	
 	if config.Campaign.MaxCPM <= 0 {
-		return fmt.Errorf("max CPM must be greater than 0")
+		problems.Add("campaign.max_cpm", "max CPM must be greater than 0")
+	}
+	
+	if schedule := config.Campaign.Schedule; schedule != nil {
+		if schedule.DurationDays < 0 {
+			problems.Add("campaign.schedule.duration_days", "duration must be a positive number of days")
+		}
+		if schedule.End != "" && schedule.DurationDays != 0 {
+			problems.Add("campaign.schedule", "set either end or duration_days, not both")
+		}
+		if _, _, err := schedule.Window(time.Now()); err != nil {
+			problems.Add("campaign.schedule", "%v", err)
+		}
 	}
 	
 	// Validate creatives
 	if len(config.Creatives) == 0 {
-		return fmt.Errorf("at least one creative is required")
+		problems.Add("creatives", "at least one creative is required")
 	}
 	
 	creativeIDs := make(map[string]bool)
 	for i, creative := range config.Creatives {
+		path := fmt.Sprintf("creatives[%d]", i)
+
 		if creative.ID == "" {
-			return fmt.Errorf("creative #%d missing ID", i+1)
+			problems.Add(path+".id", "missing ID")
+		} else if creativeIDs[creative.ID] {
+			problems.Add(path+".id", "duplicate creative ID: %s", creative.ID)
 		}
+		creativeIDs[creative.ID] = true
 		
 		if creative.Title == "" {
-			return fmt.Errorf("creative #%d (%s) missing title", i+1, creative.ID)
+			problems.Add(path+".title", "missing title")
 		}
 		
 		if creative.ImageURL == "" {
-			return fmt.Errorf("creative #%d (%s) missing image URL", i+1, creative.ID)
-		}
-		
-		if _, exists := creativeIDs[creative.ID]; exists {
-			return fmt.Errorf("duplicate creative ID: %s", creative.ID)
+			problems.Add(path+".image_url", "missing image URL")
 		}
-		creativeIDs[creative.ID] = true
 	}
 	
 	// Validate targeting options
 	if len(config.TargetingOptions.Audiences) == 0 {
-		return fmt.Errorf("at least one audience is required")
+		problems.Add("targeting_options.audiences", "at least one audience is required")
 	}
 	
 	audienceIDs := make(map[string]bool)
 	for i, audience := range config.TargetingOptions.Audiences {
+		path := fmt.Sprintf("targeting_options.audiences[%d]", i)
+
 		if audience.ID == "" {
-			return fmt.Errorf("audience #%d missing ID", i+1)
+			problems.Add(path+".id", "missing ID")
+		} else if audienceIDs[audience.ID] {
+			problems.Add(path+".id", "duplicate audience ID: %s", audience.ID)
 		}
+		audienceIDs[audience.ID] = true
 		
 		if audience.Name == "" {
-			return fmt.Errorf("audience #%d (%s) missing name", i+1, audience.ID)
+			problems.Add(path+".name", "missing name")
 		}
 		
 		if len(audience.Parameters) == 0 {
-			return fmt.Errorf("audience #%d (%s) has no targeting parameters", i+1, audience.ID)
+			problems.Add(path+".parameters", "has no targeting parameters")
 		}
 		
-		if _, exists := audienceIDs[audience.ID]; exists {
-			return fmt.Errorf("duplicate audience ID: %s", audience.ID)
+		if audience.Weight != nil {
+			if err := ValidateBudgetWeight(*audience.Weight); err != nil {
+				problems.Add(path+".weight", "%v", err)
+			}
 		}
-		audienceIDs[audience.ID] = true
 	}
 	
-	// At least one placement is required if placements section exists
-	if len(config.TargetingOptions.Placements) > 0 {
-		placementIDs := make(map[string]bool)
-		for i, placement := range config.TargetingOptions.Placements {
-			if placement.ID == "" {
-				return fmt.Errorf("placement #%d missing ID", i+1)
-			}
-			
-			if placement.Name == "" {
-				return fmt.Errorf("placement #%d (%s) missing name", i+1, placement.ID)
-			}
-			
-			if placement.Position == "" {
-				return fmt.Errorf("placement #%d (%s) missing position", i+1, placement.ID)
-			}
-			
-			if _, exists := placementIDs[placement.ID]; exists {
-				return fmt.Errorf("duplicate placement ID: %s", placement.ID)
+	// Placements are optional, but listed ones must be complete
+	placementIDs := make(map[string]bool)
+	for i, placement := range config.TargetingOptions.Placements {
+		path := fmt.Sprintf("targeting_options.placements[%d]", i)
+
+		if placement.ID == "" {
+			problems.Add(path+".id", "missing ID")
+		} else if placementIDs[placement.ID] {
+			problems.Add(path+".id", "duplicate placement ID: %s", placement.ID)
+		}
+		placementIDs[placement.ID] = true
+		
+		if placement.Name == "" {
+			problems.Add(path+".name", "missing name")
+		}
+		
+		if placement.Position == "" {
+			problems.Add(path+".position", "missing position")
+		}
+		
+		if placement.Weight != nil {
+			if err := ValidateBudgetWeight(*placement.Weight); err != nil {
+				problems.Add(path+".weight", "%v", err)
 			}
-			placementIDs[placement.ID] = true
 		}
 	}
 	
-	return nil
-}
\ No newline at end of file
+	return problems.Err()
+}
@@ -1,8 +1,10 @@
 package optimization
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseYAMLReader(t *testing.T) {
@@ -178,4 +180,160 @@ targeting_options:
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestParseYAMLReader_ReportsAllProblems(t *testing.T) {
+	yamlConfig := `
+campaign:
+  name: "Test"
+  total_budget: 0
+  test_budget_percentage: 20
+  max_cpm: 15.00
+
+creatives:
+  - id: "creative1"
+    title: "Summer Sale"
+  - id: "creative1"
+    title: "New Arrivals"
+    image_url: "https://example.com/image2.jpg"
+
+targeting_options:
+  audiences:
+    - id: "audience1"
+      name: "18-24 Male"
+      parameters:
+        age_min: 18
+  placements:
+    - id: "placement1"
+      name: "Facebook Feed"
+`
+
+	_, err := ParseYAMLReader(strings.NewReader(yamlConfig))
+	if err == nil {
+		t.Fatal("Expected validation errors")
+	}
+
+	expected := []string{
+		"1. campaign.total_budget: total budget must be greater than 0",
+		"2. creatives[0].image_url: missing image URL",
+		"3. creatives[1].id: duplicate creative ID: creative1",
+		"4. targeting_options.placements[0].position: missing position",
+	}
+	for _, s := range expected {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("Expected error to contain %q, got:\n%v", s, err)
+		}
+	}
+}
+
+func TestParseYAMLReader_WeightsAndSchedule(t *testing.T) {
+	base := `
+campaign:
+  name: "Test"
+  total_budget: 1000
+  test_budget_percentage: 20
+  max_cpm: 15.00
+%s
+creatives:
+  - id: "creative1"
+    title: "Summer Sale"
+    image_url: "https://example.com/image1.jpg"
+
+targeting_options:
+  audiences:
+    - id: "audience1"
+      name: "18-24 Male"
+      parameters:
+        age_min: 18
+      %s
+  placements:
+    - id: "placement1"
+      name: "Facebook Feed"
+      position: "feed"
+      %s
+`
+
+	tests := []struct {
+		name              string
+		schedule          string
+		audienceWeight    string
+		placementWeight   string
+		expectedError     string
+		expectedStart     string
+		expectedEnd       string
+		expectedAudience  float64
+		expectedPlacement float64
+	}{
+		{
+			name:              "weights and dates",
+			schedule:          "  schedule: {start: \"2030-06-01\", end: \"2030-06-15\"}",
+			audienceWeight:    "weight: 3",
+			placementWeight:   "weight: 0.5",
+			expectedStart:     "2030-06-01",
+			expectedEnd:       "2030-06-15",
+			expectedAudience:  3,
+			expectedPlacement: 0.5,
+		},
+		{
+			name:              "start and duration",
+			schedule:          "  schedule: {start: \"2030-06-01T09:00:00Z\", duration_days: 10}",
+			expectedStart:     "2030-06-01",
+			expectedEnd:       "2030-06-11",
+			expectedAudience:  1,
+			expectedPlacement: 1,
+		},
+		{name: "zero weight", audienceWeight: "weight: 0", expectedError: "targeting_options.audiences[0].weight: weight must be a positive number"},
+		{name: "negative weight", placementWeight: "weight: -2", expectedError: "targeting_options.placements[0].weight: weight must be a positive number"},
+		{name: "infinite weight", audienceWeight: "weight: .inf", expectedError: "targeting_options.audiences[0].weight"},
+		{name: "end before start", schedule: "  schedule: {start: \"2030-06-15\", end: \"2030-06-01\"}", expectedError: "campaign.schedule: end 2030-06-01"},
+		{name: "end at start", schedule: "  schedule: {start: \"2030-06-15\", end: \"2030-06-15\"}", expectedError: "is not after start"},
+		{name: "end in the past", schedule: "  schedule: {end: \"2020-01-01\"}", expectedError: "is not after start"},
+		{name: "end and duration", schedule: "  schedule: {end: \"2030-06-15\", duration_days: 5}", expectedError: "set either end or duration_days"},
+		{name: "negative duration", schedule: "  schedule: {duration_days: -1}", expectedError: "campaign.schedule.duration_days"},
+		{name: "malformed date", schedule: "  schedule: {start: \"June 1st\"}", expectedError: "invalid start"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yamlConfig := fmt.Sprintf(base, tt.schedule, tt.audienceWeight, tt.placementWeight)
+			config, err := ParseYAMLReader(strings.NewReader(yamlConfig))
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseYAMLReader failed: %v", err)
+			}
+
+			start, end, err := config.Campaign.Schedule.Window(time.Now())
+			if err != nil {
+				t.Fatalf("Window failed: %v", err)
+			}
+			if start.Format("2006-01-02") != tt.expectedStart || end.Format("2006-01-02") != tt.expectedEnd {
+				t.Errorf("Expected %s to %s, got %s to %s", tt.expectedStart, tt.expectedEnd, start, end)
+			}
+			if weight := config.TargetingOptions.Audiences[0].BudgetWeight(); weight != tt.expectedAudience {
+				t.Errorf("Expected audience weight %v, got %v", tt.expectedAudience, weight)
+			}
+			if weight := config.TargetingOptions.Placements[0].BudgetWeight(); weight != tt.expectedPlacement {
+				t.Errorf("Expected placement weight %v, got %v", tt.expectedPlacement, weight)
+			}
+		})
+	}
+}
+
+func TestScheduleConfig_Window(t *testing.T) {
+	now := time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var unset *ScheduleConfig
+	start, end, err := unset.Window(now)
+	if err != nil || !start.Equal(now) || !end.Equal(now.AddDate(0, 0, DefaultTestDurationDays)) {
+		t.Errorf("Expected 7 days from now without a schedule, got %s to %s (%v)", start, end, err)
+	}
+
+	start, end, err = (&ScheduleConfig{DurationDays: 3}).Window(now)
+	if err != nil || !start.Equal(now) || !end.Equal(now.AddDate(0, 0, 3)) {
+		t.Errorf("Expected 3 days from now, got %s to %s (%v)", start, end, err)
+	}
+}
@@ -0,0 +1,181 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// DefaultTick is how often the scheduler checks for due jobs
+const DefaultTick = 30 * time.Second
+
+// Job is a task run daily at a time of day or at a fixed interval
+type Job struct {
+	Name  string
+	At    string        // Daily time of day as HH:MM in the scheduler's location
+	Every time.Duration // Interval between runs; the first run is when the scheduler starts
+	Run   func(ctx context.Context) error
+}
+
+// entry is a scheduled job with its next run time
+type entry struct {
+	job    Job
+	hour   int
+	minute int
+	next   time.Time
+}
+
+// Scheduler runs jobs when they are due. A failing or panicking job is logged and runs
+// again at its next time; it never stops the scheduler.
+type Scheduler struct {
+	location *time.Location
+	entries  []*entry
+	tick     time.Duration
+	now      func() time.Time
+	log      io.Writer
+}
+
+// NewScheduler creates a scheduler whose daily times are in the given location
+func NewScheduler(location *time.Location) *Scheduler {
+	if location == nil {
+		location = time.Local
+	}
+	return &Scheduler{
+		location: location,
+		tick:     DefaultTick,
+		now:      time.Now,
+		log:      os.Stdout,
+	}
+}
+
+// SetTick sets how often the scheduler checks for due jobs
+func (s *Scheduler) SetTick(tick time.Duration) {
+	s.tick = tick
+}
+
+// SetLog sets where runs are logged, standard output by default
+func (s *Scheduler) SetLog(w io.Writer) {
+	s.log = w
+}
+
+// ParseTimeOfDay parses a time of day written as HH:MM in 24-hour format
+func ParseTimeOfDay(value string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time of day %q, use HH:MM such as 07:30", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// Add schedules a job. A job needs either At or Every, not both.
+func (s *Scheduler) Add(job Job) error {
+	if job.Run == nil {
+		return fmt.Errorf("job %s has nothing to run", job.Name)
+	}
+
+	e := &entry{job: job}
+	switch {
+	case job.At != "" && job.Every != 0:
+		return fmt.Errorf("job %s: set either a time of day or an interval, not both", job.Name)
+	case job.At != "":
+		hour, minute, err := ParseTimeOfDay(job.At)
+		if err != nil {
+			return fmt.Errorf("job %s: %w", job.Name, err)
+		}
+		e.hour, e.minute = hour, minute
+		e.next = s.nextRun(e, s.now())
+	case job.Every > 0:
+		e.next = s.now()
+	default:
+		return fmt.Errorf("job %s needs a time of day or a positive interval", job.Name)
+	}
+
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+// NextRuns returns the next run time of each job by name
+func (s *Scheduler) NextRuns() map[string]time.Time {
+	runs := make(map[string]time.Time, len(s.entries))
+	for _, e := range s.entries {
+		runs[e.job.Name] = e.next
+	}
+	return runs
+}
+
+// nextRun returns the first run time of a job after the given time. Daily jobs run at
+// their time of day; a day on which that time doesn't exist, such as during a DST change,
+// runs at the normalized time.
+func (s *Scheduler) nextRun(e *entry, after time.Time) time.Time {
+	if e.job.Every > 0 {
+		return after.Add(e.job.Every)
+	}
+
+	local := after.In(s.location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), e.hour, e.minute, 0, 0, s.location)
+	if !next.After(after) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, e.hour, e.minute, 0, 0, s.location)
+	}
+	return next
+}
+
+// Run runs due jobs until ctx is cancelled, checking every tick
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+
+	for {
+		s.RunDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs every job whose run time has come, one after the other, and schedules its
+// next run. Runs missed while the scheduler wasn't running are made up once.
+func (s *Scheduler) RunDue(ctx context.Context) {
+	for _, e := range s.entries {
+		if ctx.Err() != nil {
+			return
+		}
+		if s.now().Before(e.next) {
+			continue
+		}
+
+		s.runJob(ctx, e)
+		e.next = s.nextRun(e, s.now())
+		fmt.Fprintf(s.log, "[%s] %s: next run at %s\n", s.stamp(), e.job.Name, e.next.In(s.location).Format("2006-01-02 15:04 MST"))
+	}
+}
+
+// runJob runs a job, logging its start, duration and outcome. Panics are logged as failures.
+func (s *Scheduler) runJob(ctx context.Context, e *entry) {
+	fmt.Fprintf(s.log, "[%s] %s: started\n", s.stamp(), e.job.Name)
+	started := s.now()
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return e.job.Run(ctx)
+	}()
+
+	took := s.now().Sub(started).Round(time.Second)
+	if err != nil {
+		fmt.Fprintf(s.log, "[%s] %s: failed after %s: %v\n", s.stamp(), e.job.Name, took, err)
+		return
+	}
+	fmt.Fprintf(s.log, "[%s] %s: finished in %s\n", s.stamp(), e.job.Name, took)
+}
+
+// stamp returns the current time for log lines
+func (s *Scheduler) stamp() string {
+	return s.now().In(s.location).Format("2006-01-02 15:04:05")
+}
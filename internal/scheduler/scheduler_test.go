@@ -0,0 +1,162 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScheduler_NextRun(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		job      Job
+		after    time.Time
+		expected time.Time
+	}{
+		{
+			name:     "later today",
+			job:      Job{At: "07:30"},
+			after:    time.Date(2025, 3, 3, 6, 0, 0, 0, location),
+			expected: time.Date(2025, 3, 3, 7, 30, 0, 0, location),
+		},
+		{
+			name:     "already passed today",
+			job:      Job{At: "07:30"},
+			after:    time.Date(2025, 3, 3, 7, 30, 0, 0, location),
+			expected: time.Date(2025, 3, 4, 7, 30, 0, 0, location),
+		},
+		{
+			name:     "in the scheduler's location",
+			job:      Job{At: "07:30"},
+			after:    time.Date(2025, 3, 3, 13, 0, 0, 0, time.UTC), // 08:00 in New York
+			expected: time.Date(2025, 3, 4, 7, 30, 0, 0, location),
+		},
+		{
+			name:     "across the DST change",
+			job:      Job{At: "07:30"},
+			after:    time.Date(2025, 3, 8, 8, 0, 0, 0, location),
+			expected: time.Date(2025, 3, 9, 7, 30, 0, 0, location),
+		},
+		{
+			name:     "interval",
+			job:      Job{Every: 6 * time.Hour},
+			after:    time.Date(2025, 3, 3, 22, 0, 0, 0, location),
+			expected: time.Date(2025, 3, 4, 4, 0, 0, 0, location),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(location)
+			tt.job.Run = func(context.Context) error { return nil }
+			if err := s.Add(tt.job); err != nil {
+				t.Fatal(err)
+			}
+			if got := s.nextRun(s.entries[0], tt.after); !got.Equal(tt.expected) {
+				t.Errorf("nextRun = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScheduler_RunDueSurvivesFailures(t *testing.T) {
+	now := time.Date(2025, 3, 3, 6, 0, 0, 0, time.UTC)
+	s := NewScheduler(time.UTC)
+	s.now = func() time.Time { return now }
+	var log strings.Builder
+	s.SetLog(&log)
+
+	var runs []string
+	add := func(job Job, run func() error) {
+		job.Run = func(context.Context) error {
+			runs = append(runs, job.Name)
+			return run()
+		}
+		if err := s.Add(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add(Job{Name: "failing", Every: time.Hour}, func() error { return errors.New("API down") })
+	add(Job{Name: "panicking", Every: time.Hour}, func() error { panic("nil map") })
+	add(Job{Name: "report", At: "07:00"}, func() error { return nil })
+
+	// Interval jobs run at start, the daily job at its time
+	s.RunDue(context.Background())
+	if strings.Join(runs, ",") != "failing,panicking" {
+		t.Fatalf("Unexpected runs at start: %v", runs)
+	}
+	if !strings.Contains(log.String(), "failing: failed after 0s: API down") || !strings.Contains(log.String(), "panicking: failed after 0s: panic: nil map") {
+		t.Errorf("Expected both failures logged, got:\n%s", log.String())
+	}
+
+	runs = nil
+	now = now.Add(time.Hour)
+	s.RunDue(context.Background())
+	if strings.Join(runs, ",") != "failing,panicking,report" {
+		t.Fatalf("Expected every job to run an hour later, got %v", runs)
+	}
+	if next := s.NextRuns()["report"]; !next.Equal(time.Date(2025, 3, 4, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the report next at 07:00 tomorrow, got %s", next)
+	}
+
+	runs = nil
+	now = now.Add(30 * time.Minute)
+	s.RunDue(context.Background())
+	if len(runs) != 0 {
+		t.Errorf("Expected nothing due, got %v", runs)
+	}
+}
+
+func TestScheduler_Add(t *testing.T) {
+	run := func(context.Context) error { return nil }
+	for name, job := range map[string]Job{
+		"no schedule":       {Name: "a", Run: run},
+		"both":              {Name: "a", At: "07:00", Every: time.Hour, Run: run},
+		"invalid time":      {Name: "a", At: "7am", Run: run},
+		"out of range":      {Name: "a", At: "24:00", Run: run},
+		"nothing to run":    {Name: "a", Every: time.Hour},
+		"negative interval": {Name: "a", Every: -time.Hour, Run: run},
+	} {
+		s := NewScheduler(time.UTC)
+		s.SetLog(io.Discard)
+		if err := s.Add(job); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestScheduler_RunStopsOnCancel(t *testing.T) {
+	s := NewScheduler(time.UTC)
+	s.SetLog(io.Discard)
+	s.SetTick(time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := 0
+	s.Add(Job{Name: "collect", Every: time.Hour, Run: func(context.Context) error {
+		runs++
+		cancel()
+		return nil
+	}})
+
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run didn't return after the context was cancelled")
+	}
+	if runs != 1 {
+		t.Errorf("Expected one run, got %d", runs)
+	}
+}
@@ -1,18 +1,54 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
+// PlaceholderAccessToken is the access token shipped in the example configuration
+const PlaceholderAccessToken = "YOUR_FACEBOOK_ACCESS_TOKEN"
+
+// ErrNoAccessToken is returned instead of calling the API without a real access token
+var ErrNoAccessToken = errors.New("no Facebook access token configured: run 'fbads config', or use --demo to explore with sample data")
+
 // FacebookAuth handles authentication with Facebook API
 type FacebookAuth struct {
-	AppID       string
-	AppSecret   string
-	AccessToken string
-	APIVersion  string
+	AppID         string
+	AppSecret     string
+	AccessToken   string
+	APIVersion    string
+	MinAPIVersion string            // Oldest APIVersion accepted by Validate; MinAPIVersion when empty
+	Transport     http.RoundTripper // Answers API requests instead of Facebook when set, e.g. the demo provider
+	BaseURL       string            // Graph API root used instead of DefaultBaseURL when set, e.g. a test server
+	Retry         RetryPolicy       // How requests rejected by rate limits are retried
+	Timeout       time.Duration     // Limit for a single request including reading the response; 0 means none
+}
+
+// DefaultBaseURL is the root of the Graph API; requests go to <root>/<version>/<endpoint>
+const DefaultBaseURL = "https://graph.facebook.com"
+
+// DefaultRequestTimeout is how long a single API request may take before it is abandoned
+const DefaultRequestTimeout = 60 * time.Second
+
+// RetryPolicy controls how requests rejected by Facebook rate limits are retried
+type RetryPolicy struct {
+	MaxRetries int           // Retries after the first attempt; 0 disables retrying
+	BaseDelay  time.Duration // Delay before the first retry, doubled for each further retry
+	MaxDelay   time.Duration // Upper bound for a single delay
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  2 * time.Second,
+		MaxDelay:   60 * time.Second,
+	}
 }
 
 // NewFacebookAuth creates a new FacebookAuth instance
@@ -22,26 +58,85 @@ func NewFacebookAuth(appID, appSecret, accessToken, apiVersion string) *Facebook
 		AppSecret:   appSecret,
 		AccessToken: accessToken,
 		APIVersion:  apiVersion,
+		Retry:       DefaultRetryPolicy(),
+		Timeout:     DefaultRequestTimeout,
 	}
 }
 
-// ValidateToken checks if the access token is valid
+// CheckAccessToken returns ErrNoAccessToken when requests would go to Facebook
+// without a real access token
+func (fa *FacebookAuth) CheckAccessToken() error {
+	if fa.Transport == nil && (fa.AccessToken == "" || fa.AccessToken == PlaceholderAccessToken) {
+		return ErrNoAccessToken
+	}
+	return nil
+}
+
+// HTTPClient returns the HTTP client API clients send their requests with.
+// Requests go through Transport when it is set; otherwise they are refused
+// without a real access token. A request that takes longer than Timeout fails
+// instead of blocking the caller.
+func (fa *FacebookAuth) HTTPClient() *http.Client {
+	if fa.Transport != nil {
+		return &http.Client{Transport: fa.Transport, Timeout: fa.Timeout}
+	}
+	return &http.Client{Transport: tokenCheckTransport{auth: fa}, Timeout: fa.Timeout}
+}
+
+// tokenCheckTransport refuses requests before they reach the network when no real token
+// or no valid API version is configured
+type tokenCheckTransport struct {
+	auth *FacebookAuth
+}
+
+// RoundTrip implements http.RoundTripper
+func (t tokenCheckTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.auth.CheckAccessToken(); err != nil {
+		return nil, err
+	}
+	if err := t.auth.Validate(); err != nil {
+		return nil, err
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// ValidateToken checks if the access token is valid by inspecting it with DebugToken
 func (fa *FacebookAuth) ValidateToken() (bool, error) {
 	if fa.AccessToken == "" {
 		return false, errors.New("access token is empty")
 	}
 
-	// TODO: Implement actual validation by making a request to Facebook Graph API
-	return true, nil
+	info, err := fa.DebugToken()
+	if err != nil {
+		return false, err
+	}
+	return info.IsValid, nil
 }
 
-// GetAPIBaseURL returns the base URL for the Facebook API
+// GetAPIBaseURL returns the base URL for the Facebook API, including the API version
 func (fa *FacebookAuth) GetAPIBaseURL() string {
-	return fmt.Sprintf("https://graph.facebook.com/%s", fa.APIVersion)
+	baseURL := DefaultBaseURL
+	if fa.BaseURL != "" {
+		baseURL = strings.TrimSuffix(fa.BaseURL, "/")
+	}
+	return fmt.Sprintf("%s/%s", baseURL, fa.APIVersion)
 }
 
 // GetAuthenticatedRequest returns an http request with authentication
 func (fa *FacebookAuth) GetAuthenticatedRequest(endpoint string, params url.Values) (*http.Request, error) {
+	return fa.GetAuthenticatedRequestContext(context.Background(), endpoint, params)
+}
+
+// GetAuthenticatedRequestContext returns an http request with authentication that is
+// cancelled with the context
+func (fa *FacebookAuth) GetAuthenticatedRequestContext(ctx context.Context, endpoint string, params url.Values) (*http.Request, error) {
+	if err := fa.CheckAccessToken(); err != nil {
+		return nil, err
+	}
+	if err := fa.Validate(); err != nil {
+		return nil, err
+	}
+
 	baseURL := fmt.Sprintf("%s/%s", fa.GetAPIBaseURL(), endpoint)
 	
 	if params == nil {
@@ -50,7 +145,7 @@ func (fa *FacebookAuth) GetAuthenticatedRequest(endpoint string, params url.Valu
 	
 	params.Set("access_token", fa.AccessToken)
 	
-	req, err := http.NewRequest("GET", baseURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// hangingTransport never answers; requests end only when their context is done
+type hangingTransport struct{}
+
+// RoundTrip implements http.RoundTripper
+func (hangingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestHTTPClient_Timeout(t *testing.T) {
+	fa := NewFacebookAuth("app", "secret", "token", "v22.0")
+	if fa.Timeout != DefaultRequestTimeout {
+		t.Errorf("Expected the default timeout %s, got %s", DefaultRequestTimeout, fa.Timeout)
+	}
+
+	fa.Transport = hangingTransport{}
+	fa.Timeout = 20 * time.Millisecond
+
+	req, err := fa.GetAuthenticatedRequest("act_1/campaigns", nil)
+	if err != nil {
+		t.Fatalf("GetAuthenticatedRequest failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := fa.HTTPClient().Do(req)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		urlErr, ok := err.(*url.Error)
+		if !ok || !urlErr.Timeout() {
+			t.Errorf("Expected a timeout error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the hung request to time out")
+	}
+}
+
+func TestGetAPIBaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL  string
+		expected string
+	}{
+		{baseURL: "", expected: "https://graph.facebook.com/v22.0"},
+		{baseURL: "http://127.0.0.1:8080", expected: "http://127.0.0.1:8080/v22.0"},
+		{baseURL: "http://127.0.0.1:8080/", expected: "http://127.0.0.1:8080/v22.0"},
+	}
+
+	for _, tt := range tests {
+		fa := NewFacebookAuth("app", "secret", "token", "v22.0")
+		fa.BaseURL = tt.baseURL
+		if got := fa.GetAPIBaseURL(); got != tt.expected {
+			t.Errorf("GetAPIBaseURL() with base URL %q = %s, want %s", tt.baseURL, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Graph API error codes the predicates of FacebookAPIError check
+const (
+	ErrorCodeUnknown          = 1
+	ErrorCodeService          = 2
+	ErrorCodeAppLimit         = 4
+	ErrorCodeUserLimit        = 17
+	ErrorCodePageLimit        = 32
+	ErrorCodeInvalidParameter = 100
+	ErrorCodeAccessToken      = 190
+	ErrorCodeCallsLimit       = 613
+	ErrorCodeAdAccountLimit   = 80004
+
+	ErrorSubcodeAdAccountLimit = 2446079
+)
+
+// FacebookAPIError is an error returned by the Graph API in the {"error": {...}} format
+type FacebookAPIError struct {
+	Message     string `json:"message"`
+	Type        string `json:"type"`
+	Code        int    `json:"code"`
+	Subcode     int    `json:"error_subcode"`
+	UserTitle   string `json:"error_user_title,omitempty"` // Short headline of UserMessage
+	UserMessage string `json:"error_user_msg,omitempty"`   // Explanation meant for the person using the app
+	Transient   bool   `json:"is_transient,omitempty"`     // Set by Facebook when retrying may succeed
+	FBTraceID   string `json:"fbtrace_id,omitempty"`
+	HTTPStatus  int    `json:"-"` // Status code of the response, 0 when unknown
+}
+
+// GraphError is the previous name of FacebookAPIError
+//
+// Deprecated: use FacebookAPIError.
+type GraphError = FacebookAPIError
+
+// Error implements the error interface. The user title and message are included when
+// Facebook sends them, and the trace ID so failures can be reported to Facebook support.
+func (e *FacebookAPIError) Error() string {
+	// Responses without the envelope only have a status and body
+	if e.Code == 0 && e.Type == "" && e.HTTPStatus != 0 {
+		return fmt.Sprintf("%d %s - %s", e.HTTPStatus, http.StatusText(e.HTTPStatus), e.Message)
+	}
+
+	msg := fmt.Sprintf("%s (code: %d, type: %s)", e.Message, e.Code, e.Type)
+	switch {
+	case e.UserTitle != "" && e.UserMessage != "":
+		msg += ": " + e.UserTitle + " - " + e.UserMessage
+	case e.UserTitle != "":
+		msg += ": " + e.UserTitle
+	case e.UserMessage != "" && e.UserMessage != e.Message:
+		msg += ": " + e.UserMessage
+	}
+	if e.FBTraceID != "" {
+		msg += " [fbtrace_id: " + e.FBTraceID + "]"
+	}
+	return msg
+}
+
+// IsRateLimit reports whether the request was rejected by an app, user or ad account rate limit
+func (e *FacebookAPIError) IsRateLimit() bool {
+	switch e.Code {
+	case ErrorCodeAppLimit, ErrorCodeUserLimit, ErrorCodePageLimit, ErrorCodeCallsLimit, ErrorCodeAdAccountLimit:
+		return true
+	}
+	return e.Subcode == ErrorSubcodeAdAccountLimit || e.HTTPStatus == http.StatusTooManyRequests
+}
+
+// IsInvalidParameter reports whether Facebook rejected a parameter of the request
+func (e *FacebookAPIError) IsInvalidParameter() bool {
+	return e.Code == ErrorCodeInvalidParameter
+}
+
+// IsAccessToken reports whether the access token is invalid or expired
+func (e *FacebookAPIError) IsAccessToken() bool {
+	return e.Code == ErrorCodeAccessToken
+}
+
+// IsTransient reports whether sending the request again later may succeed: rate limits,
+// temporary service errors and errors Facebook marks as transient
+func (e *FacebookAPIError) IsTransient() bool {
+	if e.Transient || e.IsRateLimit() {
+		return true
+	}
+	if e.Code == ErrorCodeUnknown || e.Code == ErrorCodeService {
+		return true
+	}
+	return e.HTTPStatus >= http.StatusInternalServerError
+}
+
+// ParseAPIError returns the error of a failed response. The {"error": {...}} envelope is
+// parsed when the body has one; otherwise the body becomes the message.
+func ParseAPIError(status int, body []byte) *FacebookAPIError {
+	var envelope struct {
+		Error *FacebookAPIError `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error != nil && envelope.Error.Message != "" {
+		envelope.Error.HTTPStatus = status
+		return envelope.Error
+	}
+
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return &FacebookAPIError{Message: message, HTTPStatus: status}
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParseAPIError(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		body             string
+		code             int
+		message          string
+		rateLimit        bool
+		invalidParameter bool
+		transient        bool
+	}{
+		{
+			name:             "invalid parameter",
+			status:           http.StatusBadRequest,
+			body:             `{"error":{"message":"Invalid parameter","type":"OAuthException","code":100,"error_subcode":1885621,"error_user_msg":"Bid amount too low","fbtrace_id":"AbC"}}`,
+			code:             100,
+			message:          "Invalid parameter (code: 100, type: OAuthException): Bid amount too low [fbtrace_id: AbC]",
+			invalidParameter: true,
+		},
+		{
+			name:             "user title",
+			status:           http.StatusBadRequest,
+			body:             `{"error":{"message":"Invalid parameter","type":"OAuthException","code":100,"error_subcode":1487242,"error_user_title":"Budget Too Low","error_user_msg":"Raise the daily budget to at least $1.00"}}`,
+			code:             100,
+			message:          "Invalid parameter (code: 100, type: OAuthException): Budget Too Low - Raise the daily budget to at least $1.00",
+			invalidParameter: true,
+		},
+		{
+			name:      "user rate limit",
+			status:    http.StatusBadRequest,
+			body:      `{"error":{"message":"User request limit reached","type":"OAuthException","code":17}}`,
+			code:      17,
+			message:   "User request limit reached (code: 17, type: OAuthException)",
+			rateLimit: true,
+			transient: true,
+		},
+		{
+			name:      "ad account throttle",
+			status:    http.StatusBadRequest,
+			body:      `{"error":{"message":"Too many calls","type":"OAuthException","code":80004,"error_subcode":2446079}}`,
+			code:      80004,
+			message:   "Too many calls (code: 80004, type: OAuthException)",
+			rateLimit: true,
+			transient: true,
+		},
+		{
+			name:      "marked transient",
+			status:    http.StatusBadRequest,
+			body:      `{"error":{"message":"Please retry","type":"OAuthException","code":2,"is_transient":true}}`,
+			code:      2,
+			message:   "Please retry (code: 2, type: OAuthException)",
+			transient: true,
+		},
+		{
+			name:      "no envelope",
+			status:    http.StatusBadGateway,
+			body:      "upstream unavailable\n",
+			message:   "502 Bad Gateway - upstream unavailable",
+			transient: true,
+		},
+		{
+			name:      "bare 429",
+			status:    http.StatusTooManyRequests,
+			message:   "429 Too Many Requests - Too Many Requests",
+			rateLimit: true,
+			transient: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ParseAPIError(tt.status, []byte(tt.body))
+			if err.Code != tt.code || err.HTTPStatus != tt.status {
+				t.Errorf("Expected code %d and status %d, got %d and %d", tt.code, tt.status, err.Code, err.HTTPStatus)
+			}
+			if err.Error() != tt.message {
+				t.Errorf("Error() = %q, want %q", err.Error(), tt.message)
+			}
+			if err.IsRateLimit() != tt.rateLimit {
+				t.Errorf("IsRateLimit() = %v, want %v", err.IsRateLimit(), tt.rateLimit)
+			}
+			if err.IsInvalidParameter() != tt.invalidParameter {
+				t.Errorf("IsInvalidParameter() = %v, want %v", err.IsInvalidParameter(), tt.invalidParameter)
+			}
+			if err.IsTransient() != tt.transient {
+				t.Errorf("IsTransient() = %v, want %v", err.IsTransient(), tt.transient)
+			}
+		})
+	}
+
+	if err := ParseAPIError(http.StatusBadRequest, []byte(`{"error":{"message":"x","fbtrace_id":"AbC"}}`)); err.FBTraceID != "AbC" {
+		t.Errorf("Expected the trace ID to be kept, got %q", err.FBTraceID)
+	}
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"time"
+)
+
+// retryJitter is the largest fraction added at random to a backoff delay, so that
+// clients throttled together do not retry together
+const retryJitter = 0.2
+
+// retrySleep waits between retries; tests replace it to record the delays
+var retrySleep = func(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryNotices receives the retry notices. It is stderr, so the notices don't end up in
+// JSON or CSV written to stdout; tests replace it.
+var retryNotices io.Writer = os.Stderr
+
+// RetriesExhaustedError is returned when a request is still rate limited after every retry
+type RetriesExhaustedError struct {
+	Retries  int
+	Throttle *ThrottleError
+}
+
+// Error implements the error interface
+func (e *RetriesExhaustedError) Error() string {
+	return fmt.Sprintf("still rate limited after %d retries: %v", e.Retries, e.Throttle)
+}
+
+// Unwrap returns the throttle of the last attempt
+func (e *RetriesExhaustedError) Unwrap() error {
+	return e.Throttle
+}
+
+// DoWithRetry sends a request and retries it with exponential backoff and jitter while
+// Facebook rejects it with an app or user rate limit (error codes 4, 17, 32 and 613, or
+// HTTP 429). The usage headers can lengthen a delay up to the policy's maximum.
+//
+// Ad account throttles last minutes, so they are returned as a *ThrottleError without
+// retrying. Other responses, successful or not, are returned to the caller unchanged.
+func DoWithRetry(httpClient *http.Client, req *http.Request, policy RetryPolicy, accountID string) (*http.Response, error) {
+	for retry := 0; ; retry++ {
+		// Resend the body of POST requests
+		if retry > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("error rewinding request body: %w", err)
+			}
+			req.Body = body
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 400 {
+			return resp, nil
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading response body: %w", err)
+		}
+
+		throttle := ClassifyThrottle(accountID, resp.Header, body)
+		if throttle != nil {
+			throttle.APIError.HTTPStatus = resp.StatusCode
+		}
+		if throttle == nil && resp.StatusCode == http.StatusTooManyRequests {
+			throttle = &ThrottleError{
+				AccountID: accountID,
+				Scope:     ThrottleScopeApp,
+				Message:   resp.Status,
+			}
+		}
+		if throttle == nil {
+			// Not a rate limit: hand the response back for the caller to report
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			return resp, nil
+		}
+		if throttle.Scope == ThrottleScopeAccount {
+			return nil, throttle
+		}
+		if retry >= policy.MaxRetries {
+			return nil, &RetriesExhaustedError{Retries: retry, Throttle: throttle}
+		}
+
+		delay := retryDelay(policy, retry, resp.Header)
+		throttle.RetryAfter = delay
+		throttle.Until = time.Now().Add(delay)
+		fmt.Fprintf(retryNotices, "Rate limit reached: %s. Retrying in %.1f seconds (%d/%d)...\n",
+			throttle.Message, delay.Seconds(), retry+1, policy.MaxRetries)
+
+		if err := retrySleep(req.Context(), delay); err != nil {
+			return nil, fmt.Errorf("retry cancelled: %w", err)
+		}
+	}
+}
+
+// retryDelay returns how long to wait before a retry: exponential backoff with jitter,
+// lengthened when the usage headers say the limit lasts longer, and capped at the maximum
+func retryDelay(policy RetryPolicy, retry int, header http.Header) time.Duration {
+	delay := time.Duration(float64(policy.BaseDelay) * math.Pow(2, float64(retry)) * (1 + rand.Float64()*retryJitter))
+
+	if hint := usageRetryAfter(header, policy.MaxDelay); hint > delay {
+		delay = hint
+	}
+	if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	return delay
+}
+
+// usageRetryAfter works out from the usage headers how long the limit lasts. The business
+// use case header reports minutes to regain access; the app usage header only reports
+// percentages, so an exhausted app waits the longest delay allowed.
+func usageRetryAfter(header http.Header, maxDelay time.Duration) time.Duration {
+	var wait time.Duration
+
+	if usage, err := ParseBusinessUseCaseUsage(header.Get("X-Business-Use-Case-Usage")); err == nil {
+		for _, entries := range usage {
+			for _, entry := range entries {
+				regain := time.Duration(entry.EstimatedTimeToRegainAccess) * time.Minute
+				if regain > wait {
+					wait = regain
+				}
+			}
+		}
+	}
+
+	if usage, err := ParseAppUsage(header.Get("X-App-Usage")); err == nil && usage != nil && usage.Exhausted() && maxDelay > wait {
+		wait = maxDelay
+	}
+
+	return wait
+}
@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedResponse is a response a scriptedTransport answers with
+type scriptedResponse struct {
+	status int
+	body   string
+	header http.Header
+}
+
+// scriptedTransport answers requests with the scripted responses in order, repeating the
+// last one, and records the request bodies
+type scriptedTransport struct {
+	responses []scriptedResponse
+	bodies    []string
+}
+
+// RoundTrip implements http.RoundTripper
+func (s *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		raw, _ := io.ReadAll(req.Body)
+		body = string(raw)
+	}
+	s.bodies = append(s.bodies, body)
+
+	resp := s.responses[0]
+	if len(s.responses) > 1 {
+		s.responses = s.responses[1:]
+	}
+	header := resp.header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: resp.status,
+		Status:     http.StatusText(resp.status),
+		Body:       io.NopCloser(strings.NewReader(resp.body)),
+		Header:     header,
+	}, nil
+}
+
+// recordSleeps replaces retrySleep for the test and returns the delays it was asked to wait
+func recordSleeps(t *testing.T) *[]time.Duration {
+	var delays []time.Duration
+	original := retrySleep
+	retrySleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	t.Cleanup(func() { retrySleep = original })
+	return &delays
+}
+
+func TestDoWithRetry_BacksOffUntilSuccess(t *testing.T) {
+	delays := recordSleeps(t)
+	var notices strings.Builder
+	retryNotices = &notices
+	t.Cleanup(func() { retryNotices = os.Stderr })
+	transport := &scriptedTransport{responses: []scriptedResponse{
+		{http.StatusTooManyRequests, `Too Many Requests`, nil},
+		{http.StatusBadRequest, `{"error":{"message":"User request limit reached","code":17}}`, nil},
+		{http.StatusOK, `{"id":"123"}`, nil},
+	}}
+
+	req, _ := http.NewRequest("POST", "https://graph.facebook.com/v22.0/act_1/campaigns", strings.NewReader("name=Test"))
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: time.Minute}
+	resp, err := DoWithRetry(&http.Client{Transport: transport}, req, policy, "1")
+	if err != nil {
+		t.Fatalf("DoWithRetry failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != `{"id":"123"}` {
+		t.Errorf("Expected the successful response, got %d %s", resp.StatusCode, body)
+	}
+
+	// Each attempt resends the POST body
+	if len(transport.bodies) != 3 || transport.bodies[2] != "name=Test" {
+		t.Errorf("Expected three attempts with the body, got %q", transport.bodies)
+	}
+
+	// Retries are announced on stderr, away from the output on stdout
+	if strings.Count(notices.String(), "Rate limit reached") != 2 {
+		t.Errorf("Expected a notice per retry, got %q", notices.String())
+	}
+
+	// Delays double from the base delay, plus up to 20% jitter
+	if len(*delays) != 2 {
+		t.Fatalf("Expected two backoff delays, got %v", *delays)
+	}
+	for i, expected := range []time.Duration{time.Second, 2 * time.Second} {
+		d := (*delays)[i]
+		if d < expected || d > time.Duration(float64(expected)*(1+retryJitter)) {
+			t.Errorf("Expected delay %d between %s and +20%%, got %s", i, expected, d)
+		}
+	}
+}
+
+func TestDoWithRetry_ExhaustedRetries(t *testing.T) {
+	delays := recordSleeps(t)
+	transport := &scriptedTransport{responses: []scriptedResponse{
+		{http.StatusBadRequest, `{"error":{"message":"Calls to this api have exceeded the rate limit","code":613}}`, nil},
+	}}
+
+	req, _ := http.NewRequest("GET", "https://graph.facebook.com/v22.0/act_1/campaigns", nil)
+	policy := RetryPolicy{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: time.Minute}
+	_, err := DoWithRetry(&http.Client{Transport: transport}, req, policy, "1")
+
+	var exhausted *RetriesExhaustedError
+	if !errors.As(err, &exhausted) || exhausted.Retries != 2 {
+		t.Fatalf("Expected RetriesExhaustedError after 2 retries, got %v", err)
+	}
+	var throttle *ThrottleError
+	if !errors.As(err, &throttle) || throttle.Code != 613 {
+		t.Errorf("Expected the last throttle to be wrapped, got %v", err)
+	}
+	var apiErr *FacebookAPIError
+	if !errors.As(err, &apiErr) || !apiErr.IsRateLimit() || apiErr.HTTPStatus != http.StatusBadRequest {
+		t.Errorf("Expected the Graph API error to be reachable, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "still rate limited after 2 retries") {
+		t.Errorf("Expected a clear message, got %q", err)
+	}
+	if len(transport.bodies) != 3 || len(*delays) != 2 {
+		t.Errorf("Expected 3 attempts and 2 delays, got %d and %v", len(transport.bodies), *delays)
+	}
+}
+
+func TestDoWithRetry_DoesNotRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   scriptedResponse
+		expectErr  bool
+		expectCode int
+	}{
+		{
+			name:      "account throttle",
+			response:  scriptedResponse{http.StatusBadRequest, `{"error":{"message":"There have been too many calls to this ad-account","code":80004,"error_subcode":2446079}}`, nil},
+			expectErr: true,
+		},
+		{
+			name:       "other API error",
+			response:   scriptedResponse{http.StatusBadRequest, `{"error":{"message":"Invalid parameter","code":100}}`, nil},
+			expectCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delays := recordSleeps(t)
+			transport := &scriptedTransport{responses: []scriptedResponse{tt.response}}
+
+			req, _ := http.NewRequest("GET", "https://graph.facebook.com/v22.0/act_1/campaigns", nil)
+			resp, err := DoWithRetry(&http.Client{Transport: transport}, req, DefaultRetryPolicy(), "1")
+
+			if len(transport.bodies) != 1 || len(*delays) != 0 {
+				t.Errorf("Expected a single attempt, got %d attempts and delays %v", len(transport.bodies), *delays)
+			}
+			if tt.expectErr {
+				var throttle *ThrottleError
+				if !errors.As(err, &throttle) || throttle.Scope != ThrottleScopeAccount {
+					t.Errorf("Expected an account ThrottleError, got %v", err)
+				}
+				return
+			}
+
+			// The caller still reads the error body
+			if err != nil || resp.StatusCode != tt.expectCode {
+				t.Fatalf("Expected the response to be returned, got %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if !strings.Contains(string(body), "Invalid parameter") {
+				t.Errorf("Expected the error body to be readable, got %q", body)
+			}
+		})
+	}
+}
+
+func TestRetryDelay_UsageHeaders(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 5 * time.Minute}
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		minDelay time.Duration
+		maxDelay time.Duration
+	}{
+		{
+			name:     "no usage headers",
+			header:   http.Header{},
+			minDelay: time.Second,
+			maxDelay: 1200 * time.Millisecond,
+		},
+		{
+			name:     "business use case regain time",
+			header:   http.Header{"X-Business-Use-Case-Usage": {`{"123":[{"type":"ads_management","estimated_time_to_regain_access":2}]}`}},
+			minDelay: 2 * time.Minute,
+			maxDelay: 2 * time.Minute,
+		},
+		{
+			name:     "regain time capped at the max delay",
+			header:   http.Header{"X-Business-Use-Case-Usage": {`{"123":[{"type":"ads_management","estimated_time_to_regain_access":30}]}`}},
+			minDelay: 5 * time.Minute,
+			maxDelay: 5 * time.Minute,
+		},
+		{
+			name:     "app usage exhausted",
+			header:   http.Header{"X-App-Usage": {`{"call_count":100,"total_cputime":20,"total_time":25}`}},
+			minDelay: 5 * time.Minute,
+			maxDelay: 5 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay := retryDelay(policy, 0, tt.header)
+			if delay < tt.minDelay || delay > tt.maxDelay {
+				t.Errorf("Expected a delay between %s and %s, got %s", tt.minDelay, tt.maxDelay, delay)
+			}
+		})
+	}
+}
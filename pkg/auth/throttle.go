@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ThrottleScope tells which limit a throttled request ran into
+type ThrottleScope string
+
+const (
+	// ThrottleScopeApp is the app or user level limit shared by all accounts
+	ThrottleScopeApp ThrottleScope = "app"
+
+	// ThrottleScopeAccount is the ad account level limit for the Marketing API
+	ThrottleScopeAccount ThrottleScope = "account"
+)
+
+// Default pauses used when the API does not say how long to wait
+const (
+	defaultAccountCooldown = 5 * time.Minute
+	defaultAppCooldown     = 60 * time.Second
+)
+
+// ThrottleError is returned when Facebook rejects a request because of rate limits
+type ThrottleError struct {
+	AccountID  string
+	Scope      ThrottleScope
+	Code       int
+	Subcode    int
+	RetryAfter time.Duration
+	Until      time.Time
+	Message    string
+	APIError   *FacebookAPIError // The Graph API error of the response, nil for a bare HTTP 429
+}
+
+// Error implements the error interface
+func (e *ThrottleError) Error() string {
+	if e.Scope == ThrottleScopeAccount {
+		return fmt.Sprintf("account %s throttled until ~%s", formatAccountID(e.AccountID), e.Until.Format("15:04"))
+	}
+	return fmt.Sprintf("app rate limit reached, retry after %s: %s", e.RetryAfter.Round(time.Second), e.Message)
+}
+
+// Unwrap returns the Graph API error of the throttled response
+func (e *ThrottleError) Unwrap() error {
+	if e.APIError == nil {
+		return nil
+	}
+	return e.APIError
+}
+
+// AccountUsage is the parsed X-Ad-Account-Usage header
+type AccountUsage struct {
+	UtilizationPct    float64 `json:"acc_id_util_pct"`
+	ResetTimeDuration int     `json:"reset_time_duration"` // Seconds until the account regains access
+	AccessTier        string  `json:"ads_api_access_tier"`
+}
+
+// BusinessUseCaseUsage is one entry of the X-Business-Use-Case-Usage header
+type BusinessUseCaseUsage struct {
+	Type                        string `json:"type"`
+	CallCount                   int    `json:"call_count"`
+	TotalCPUTime                int    `json:"total_cputime"`
+	TotalTime                   int    `json:"total_time"`
+	EstimatedTimeToRegainAccess int    `json:"estimated_time_to_regain_access"` // Minutes
+}
+
+// AppUsage is the parsed X-App-Usage header. Each value is the percentage of the app's
+// hourly allowance used.
+type AppUsage struct {
+	CallCount    float64 `json:"call_count"`
+	TotalCPUTime float64 `json:"total_cputime"`
+	TotalTime    float64 `json:"total_time"`
+}
+
+// Exhausted reports whether any of the app's allowances is used up
+func (u *AppUsage) Exhausted() bool {
+	return u.CallCount >= 100 || u.TotalCPUTime >= 100 || u.TotalTime >= 100
+}
+
+// ParseAppUsage parses the X-App-Usage header value
+func ParseAppUsage(header string) (*AppUsage, error) {
+	if header == "" {
+		return nil, nil
+	}
+
+	var usage AppUsage
+	if err := json.Unmarshal([]byte(header), &usage); err != nil {
+		return nil, fmt.Errorf("error parsing app usage header: %w", err)
+	}
+
+	return &usage, nil
+}
+
+// ParseAccountUsage parses the X-Ad-Account-Usage header value
+func ParseAccountUsage(header string) (*AccountUsage, error) {
+	if header == "" {
+		return nil, nil
+	}
+
+	var usage AccountUsage
+	if err := json.Unmarshal([]byte(header), &usage); err != nil {
+		return nil, fmt.Errorf("error parsing account usage header: %w", err)
+	}
+
+	return &usage, nil
+}
+
+// ParseBusinessUseCaseUsage parses the X-Business-Use-Case-Usage header value.
+// The result is keyed by business object ID (usually the ad account ID).
+func ParseBusinessUseCaseUsage(header string) (map[string][]BusinessUseCaseUsage, error) {
+	if header == "" {
+		return nil, nil
+	}
+
+	var usage map[string][]BusinessUseCaseUsage
+	if err := json.Unmarshal([]byte(header), &usage); err != nil {
+		return nil, fmt.Errorf("error parsing business use case usage header: %w", err)
+	}
+
+	return usage, nil
+}
+
+// ClassifyThrottle inspects a failed API response and returns a ThrottleError
+// when it was caused by app level or ad account level rate limiting
+func ClassifyThrottle(accountID string, header http.Header, body []byte) *ThrottleError {
+	apiErr := ParseAPIError(0, body)
+
+	var scope ThrottleScope
+	switch {
+	case apiErr.Code == ErrorCodeAdAccountLimit || apiErr.Subcode == ErrorSubcodeAdAccountLimit:
+		scope = ThrottleScopeAccount
+	case apiErr.IsRateLimit():
+		scope = ThrottleScopeApp
+	default:
+		return nil
+	}
+
+	throttle := &ThrottleError{
+		AccountID: accountID,
+		Scope:     scope,
+		Code:      apiErr.Code,
+		Subcode:   apiErr.Subcode,
+		Message:   apiErr.Message,
+		APIError:  apiErr,
+	}
+
+	if scope == ThrottleScopeAccount {
+		throttle.RetryAfter = accountRetryAfter(accountID, header)
+	} else {
+		throttle.RetryAfter = defaultAppCooldown
+	}
+	throttle.Until = time.Now().Add(throttle.RetryAfter)
+
+	return throttle
+}
+
+// accountRetryAfter works out how long an ad account has to wait from the usage headers
+func accountRetryAfter(accountID string, header http.Header) time.Duration {
+	var wait time.Duration
+
+	if usage, err := ParseAccountUsage(header.Get("X-Ad-Account-Usage")); err == nil && usage != nil {
+		wait = time.Duration(usage.ResetTimeDuration) * time.Second
+	}
+
+	// The business use case header reports minutes to regain access per object
+	if usage, err := ParseBusinessUseCaseUsage(header.Get("X-Business-Use-Case-Usage")); err == nil {
+		for id, entries := range usage {
+			if accountID != "" && strings.TrimPrefix(id, "act_") != strings.TrimPrefix(accountID, "act_") {
+				continue
+			}
+			for _, entry := range entries {
+				regain := time.Duration(entry.EstimatedTimeToRegainAccess) * time.Minute
+				if regain > wait {
+					wait = regain
+				}
+			}
+		}
+	}
+
+	if wait <= 0 {
+		wait = defaultAccountCooldown
+	}
+
+	return wait
+}
+
+// formatAccountID returns the account ID with the act_ prefix
+func formatAccountID(accountID string) string {
+	if strings.HasPrefix(accountID, "act_") {
+		return accountID
+	}
+	return "act_" + accountID
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClassifyThrottle(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		header        http.Header
+		expectNil     bool
+		expectedScope ThrottleScope
+		minRetry      time.Duration
+		maxRetry      time.Duration
+	}{
+		{
+			name:      "not a throttle error",
+			body:      `{"error":{"message":"Invalid parameter","code":100}}`,
+			header:    http.Header{},
+			expectNil: true,
+		},
+		{
+			name:      "invalid body",
+			body:      `not json`,
+			header:    http.Header{},
+			expectNil: true,
+		},
+		{
+			name:          "app level limit",
+			body:          `{"error":{"message":"Application request limit reached","code":4}}`,
+			header:        http.Header{},
+			expectedScope: ThrottleScopeApp,
+			minRetry:      defaultAppCooldown,
+			maxRetry:      defaultAppCooldown,
+		},
+		{
+			name: "account level limit with usage header",
+			body: `{"error":{"message":"There have been too many calls to this ad-account","code":80004,"error_subcode":2446079}}`,
+			header: http.Header{
+				"X-Ad-Account-Usage": []string{`{"acc_id_util_pct":100,"reset_time_duration":120,"ads_api_access_tier":"development_access"}`},
+			},
+			expectedScope: ThrottleScopeAccount,
+			minRetry:      120 * time.Second,
+			maxRetry:      120 * time.Second,
+		},
+		{
+			name: "account level limit with business use case header",
+			body: `{"error":{"message":"User request limit reached","code":17,"error_subcode":2446079}}`,
+			header: http.Header{
+				"X-Business-Use-Case-Usage": []string{`{"123":[{"type":"ads_management","call_count":100,"total_cputime":30,"total_time":40,"estimated_time_to_regain_access":14}],"999":[{"type":"ads_management","estimated_time_to_regain_access":60}]}`},
+			},
+			expectedScope: ThrottleScopeAccount,
+			minRetry:      14 * time.Minute,
+			maxRetry:      14 * time.Minute,
+		},
+		{
+			name:          "account level limit without headers",
+			body:          `{"error":{"message":"Too many calls","code":80004}}`,
+			header:        http.Header{},
+			expectedScope: ThrottleScopeAccount,
+			minRetry:      defaultAccountCooldown,
+			maxRetry:      defaultAccountCooldown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throttle := ClassifyThrottle("123", tt.header, []byte(tt.body))
+			if tt.expectNil {
+				if throttle != nil {
+					t.Errorf("Expected nil, got %v", throttle)
+				}
+				return
+			}
+
+			if throttle == nil {
+				t.Fatalf("Expected throttle error, got nil")
+			}
+
+			if throttle.Scope != tt.expectedScope {
+				t.Errorf("Expected scope %s, got %s", tt.expectedScope, throttle.Scope)
+			}
+
+			if throttle.RetryAfter < tt.minRetry || throttle.RetryAfter > tt.maxRetry {
+				t.Errorf("Expected retry after between %v and %v, got %v", tt.minRetry, tt.maxRetry, throttle.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestThrottleError_Message(t *testing.T) {
+	until := time.Date(2025, 3, 1, 14, 32, 0, 0, time.Local)
+	err := &ThrottleError{AccountID: "123", Scope: ThrottleScopeAccount, Until: until}
+
+	expected := "account act_123 throttled until ~14:32"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
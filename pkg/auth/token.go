@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// RequiredScopes are the permissions a token needs to manage campaigns
+var RequiredScopes = []string{"ads_management", "ads_read"}
+
+// TokenInfo describes an access token as reported by the debug_token endpoint
+type TokenInfo struct {
+	IsValid             bool      `json:"is_valid"`
+	Type                string    `json:"type,omitempty"` // USER, PAGE or APP
+	AppID               string    `json:"app_id,omitempty"`
+	Application         string    `json:"application,omitempty"`
+	UserID              string    `json:"user_id,omitempty"`
+	Scopes              []string  `json:"scopes,omitempty"`
+	IssuedAt            time.Time `json:"issued_at"`
+	ExpiresAt           time.Time `json:"expires_at"` // Zero when the token doesn't expire
+	DataAccessExpiresAt time.Time `json:"data_access_expires_at"`
+	Error               string    `json:"error,omitempty"` // Why the token is invalid
+}
+
+// MissingScopes returns the scopes of required the token wasn't granted
+func (ti TokenInfo) MissingScopes(required ...string) []string {
+	granted := make(map[string]bool, len(ti.Scopes))
+	for _, scope := range ti.Scopes {
+		granted[scope] = true
+	}
+
+	var missing []string
+	for _, scope := range required {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// DebugToken inspects the access token with the debug_token endpoint
+func (fa *FacebookAuth) DebugToken() (TokenInfo, error) {
+	return fa.DebugTokenContext(context.Background())
+}
+
+// DebugTokenContext inspects the access token like DebugToken. The request is made with
+// the app access token when the app ID and secret are configured, and with the token
+// itself otherwise. A token Facebook rejects is reported as invalid rather than as an error.
+func (fa *FacebookAuth) DebugTokenContext(ctx context.Context) (TokenInfo, error) {
+	if err := fa.CheckAccessToken(); err != nil {
+		return TokenInfo{}, err
+	}
+
+	params := url.Values{}
+	params.Set("input_token", fa.AccessToken)
+	params.Set("access_token", fa.AccessToken)
+	if fa.AppID != "" && fa.AppSecret != "" {
+		params.Set("access_token", fa.AppID+"|"+fa.AppSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/debug_token", fa.GetAPIBaseURL()), nil)
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("error creating request: %w", err)
+	}
+	req.URL.RawQuery = params.Encode()
+
+	resp, err := fa.HTTPClient().Do(req)
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := ParseAPIError(resp.StatusCode, body)
+		// Inspecting a token with itself fails when the token is invalid
+		if apiErr.IsAccessToken() {
+			return TokenInfo{Error: apiErr.Message}, nil
+		}
+		return TokenInfo{}, fmt.Errorf("API error: %w", apiErr)
+	}
+
+	var result struct {
+		Data struct {
+			IsValid             bool     `json:"is_valid"`
+			Type                string   `json:"type"`
+			AppID               string   `json:"app_id"`
+			Application         string   `json:"application"`
+			UserID              string   `json:"user_id"`
+			Scopes              []string `json:"scopes"`
+			IssuedAt            int64    `json:"issued_at"`
+			ExpiresAt           int64    `json:"expires_at"` // Unix time, 0 for tokens that don't expire
+			DataAccessExpiresAt int64    `json:"data_access_expires_at"`
+			Error               *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return TokenInfo{}, fmt.Errorf("error parsing response: %w - %s", err, string(body))
+	}
+
+	data := result.Data
+	info := TokenInfo{
+		IsValid:             data.IsValid,
+		Type:                data.Type,
+		AppID:               data.AppID,
+		Application:         data.Application,
+		UserID:              data.UserID,
+		Scopes:              data.Scopes,
+		IssuedAt:            unixTime(data.IssuedAt),
+		ExpiresAt:           unixTime(data.ExpiresAt),
+		DataAccessExpiresAt: unixTime(data.DataAccessExpiresAt),
+	}
+	sort.Strings(info.Scopes)
+	if data.Error != nil {
+		info.Error = data.Error.Message
+	}
+	return info, nil
+}
+
+// unixTime converts seconds since the epoch to a time, leaving 0 as the zero time
+func unixTime(seconds int64) time.Time {
+	if seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+// ExchangeForLongLivedToken exchanges the short-lived user access token for a long-lived one,
+// which lasts about 60 days. AccessToken is replaced with the new token. The returned expiry
+// is zero when Facebook does not report one.
+func (fa *FacebookAuth) ExchangeForLongLivedToken() (string, time.Time, error) {
+	if fa.AppID == "" || fa.AppSecret == "" {
+		return "", time.Time{}, errors.New("an app ID and app secret are required to exchange the access token")
+	}
+	if err := fa.CheckAccessToken(); err != nil {
+		return "", time.Time{}, err
+	}
+
+	params := url.Values{}
+	params.Set("grant_type", "fb_exchange_token")
+	params.Set("client_id", fa.AppID)
+	params.Set("client_secret", fa.AppSecret)
+	params.Set("fb_exchange_token", fa.AccessToken)
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/oauth/access_token", fa.GetAPIBaseURL()), nil)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error creating request: %w", err)
+	}
+	req.URL.RawQuery = params.Encode()
+
+	requested := time.Now()
+	resp, err := fa.HTTPClient().Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	var result struct {
+		AccessToken string            `json:"access_token"`
+		TokenType   string            `json:"token_type"`
+		ExpiresIn   int64             `json:"expires_in"` // Seconds
+		Error       *FacebookAPIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", time.Time{}, fmt.Errorf("error parsing response: %w - %s", err, string(body))
+	}
+
+	if result.Error != nil {
+		result.Error.HTTPStatus = resp.StatusCode
+		return "", time.Time{}, fmt.Errorf("error exchanging access token: %w", result.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("API error: %w", ParseAPIError(resp.StatusCode, body))
+	}
+	if result.AccessToken == "" {
+		return "", time.Time{}, errors.New("no access token in the exchange response")
+	}
+
+	var expires time.Time
+	if result.ExpiresIn > 0 {
+		expires = requested.Add(time.Duration(result.ExpiresIn) * time.Second)
+	}
+
+	fa.AccessToken = result.AccessToken
+	return result.AccessToken, expires, nil
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc answers requests with a function instead of the network
+type roundTripFunc func(*http.Request) *http.Response
+
+// RoundTrip implements http.RoundTripper
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+func response(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+	}
+}
+
+func TestExchangeForLongLivedToken(t *testing.T) {
+	fa := NewFacebookAuth("app", "secret", "short", "v22.0")
+	fa.Transport = roundTripFunc(func(req *http.Request) *http.Response {
+		q := req.URL.Query()
+		if req.URL.Path != "/v22.0/oauth/access_token" || q.Get("grant_type") != "fb_exchange_token" ||
+			q.Get("client_id") != "app" || q.Get("client_secret") != "secret" || q.Get("fb_exchange_token") != "short" {
+			t.Errorf("Unexpected exchange request: %s", req.URL)
+		}
+		return response(http.StatusOK, `{"access_token":"long","token_type":"bearer","expires_in":5184000}`)
+	})
+
+	before := time.Now()
+	token, expires, err := fa.ExchangeForLongLivedToken()
+	if err != nil {
+		t.Fatalf("ExchangeForLongLivedToken failed: %v", err)
+	}
+
+	if token != "long" || fa.AccessToken != "long" {
+		t.Errorf("Expected the long-lived token to replace the short one, got %q and %q", token, fa.AccessToken)
+	}
+	if expires.Before(before.Add(60*24*time.Hour)) || expires.After(time.Now().Add(60*24*time.Hour)) {
+		t.Errorf("Expected an expiry 60 days out, got %s", expires)
+	}
+}
+
+func TestExchangeForLongLivedToken_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		appSecret   string
+		status      int
+		body        string
+		expectError string
+	}{
+		{
+			name:        "graph error",
+			appSecret:   "secret",
+			status:      http.StatusBadRequest,
+			body:        `{"error":{"message":"Error validating access token: Session has expired","type":"OAuthException","code":190,"error_subcode":463}}`,
+			expectError: "Session has expired (code: 190, type: OAuthException)",
+		},
+		{
+			name:        "no token in response",
+			appSecret:   "secret",
+			status:      http.StatusOK,
+			body:        `{}`,
+			expectError: "no access token",
+		},
+		{
+			name:        "missing app secret",
+			expectError: "app secret are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fa := NewFacebookAuth("app", tt.appSecret, "short", "v22.0")
+			fa.Transport = roundTripFunc(func(req *http.Request) *http.Response {
+				return response(tt.status, tt.body)
+			})
+
+			_, _, err := fa.ExchangeForLongLivedToken()
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+			}
+			if fa.AccessToken != "short" {
+				t.Errorf("Expected the access token to be kept on failure, got %q", fa.AccessToken)
+			}
+		})
+	}
+
+	// Graph errors can be inspected by callers
+	fa := NewFacebookAuth("app", "secret", "short", "v22.0")
+	fa.Transport = roundTripFunc(func(req *http.Request) *http.Response {
+		return response(http.StatusBadRequest, `{"error":{"message":"Invalid","type":"OAuthException","code":190}}`)
+	})
+	_, _, err := fa.ExchangeForLongLivedToken()
+	var apiErr *FacebookAPIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 190 {
+		t.Errorf("Expected a FacebookAPIError with code 190, got %v", err)
+	}
+}
+
+func TestDebugToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		appSecret     string
+		status        int
+		body          string
+		expectedToken string
+		expected      TokenInfo
+		expectError   string
+	}{
+		{
+			name:          "valid token inspected with the app token",
+			appSecret:     "secret",
+			status:        http.StatusOK,
+			body:          `{"data":{"app_id":"app","type":"USER","application":"Shop","is_valid":true,"expires_at":1767225600,"scopes":["ads_read","ads_management"],"user_id":"7"}}`,
+			expectedToken: "app|secret",
+			expected: TokenInfo{IsValid: true, Type: "USER", AppID: "app", Application: "Shop", UserID: "7",
+				Scopes: []string{"ads_management", "ads_read"}, ExpiresAt: time.Unix(1767225600, 0)},
+		},
+		{
+			name:          "token that doesn't expire",
+			status:        http.StatusOK,
+			body:          `{"data":{"is_valid":true,"expires_at":0}}`,
+			expectedToken: "token",
+			expected:      TokenInfo{IsValid: true},
+		},
+		{
+			name:          "invalid token reported by Facebook",
+			appSecret:     "secret",
+			status:        http.StatusOK,
+			body:          `{"data":{"is_valid":false,"error":{"code":190,"message":"Session has expired"}}}`,
+			expectedToken: "app|secret",
+			expected:      TokenInfo{Error: "Session has expired"},
+		},
+		{
+			name:          "token rejected when inspecting itself",
+			status:        http.StatusBadRequest,
+			body:          `{"error":{"message":"Invalid OAuth access token.","type":"OAuthException","code":190}}`,
+			expectedToken: "token",
+			expected:      TokenInfo{Error: "Invalid OAuth access token."},
+		},
+		{
+			name:          "other API error",
+			status:        http.StatusInternalServerError,
+			body:          `{"error":{"message":"Service unavailable","code":2}}`,
+			expectedToken: "token",
+			expectError:   "Service unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fa := NewFacebookAuth("app", tt.appSecret, "token", "v22.0")
+			fa.Transport = roundTripFunc(func(req *http.Request) *http.Response {
+				q := req.URL.Query()
+				if req.URL.Path != "/v22.0/debug_token" || q.Get("input_token") != "token" || q.Get("access_token") != tt.expectedToken {
+					t.Errorf("Unexpected debug request: %s", req.URL)
+				}
+				return response(tt.status, tt.body)
+			})
+
+			info, err := fa.DebugToken()
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DebugToken failed: %v", err)
+			}
+			if !reflect.DeepEqual(info, tt.expected) {
+				t.Errorf("DebugToken() = %+v, want %+v", info, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTokenInfoMissingScopes(t *testing.T) {
+	info := TokenInfo{Scopes: []string{"ads_read", "pages_show_list"}}
+	if missing := info.MissingScopes(RequiredScopes...); !reflect.DeepEqual(missing, []string{"ads_management"}) {
+		t.Errorf("Expected ads_management to be missing, got %v", missing)
+	}
+	info.Scopes = append(info.Scopes, "ads_management")
+	if missing := info.MissingScopes(RequiredScopes...); len(missing) != 0 {
+		t.Errorf("Expected no missing scopes, got %v", missing)
+	}
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// DefaultAPIVersion is the Graph API version used when none is configured
+const DefaultAPIVersion = "v22.0"
+
+// MinAPIVersion is the oldest Graph API version accepted when no other minimum is set.
+// Facebook retires each version about two years after its release.
+const MinAPIVersion = "v18.0"
+
+// apiVersionPattern matches Graph API versions such as v22.0
+var apiVersionPattern = regexp.MustCompile(`^v(\d+)\.(\d+)$`)
+
+// ParseAPIVersion returns the major and minor number of a Graph API version in the vNN.N format
+func ParseAPIVersion(version string) (int, int, error) {
+	if version == "" {
+		return 0, 0, fmt.Errorf("no Graph API version configured: set api_version, e.g. to %q", DefaultAPIVersion)
+	}
+
+	match := apiVersionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return 0, 0, fmt.Errorf("invalid Graph API version %q: expected the vNN.N format, e.g. %s", version, DefaultAPIVersion)
+	}
+	major, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid Graph API version %q: %w", version, err)
+	}
+	minor, err := strconv.Atoi(match[2])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid Graph API version %q: %w", version, err)
+	}
+	return major, minor, nil
+}
+
+// ValidateAPIVersion checks that version is in the vNN.N format and not older than
+// minimum; an empty minimum means MinAPIVersion
+func ValidateAPIVersion(version, minimum string) error {
+	if minimum == "" {
+		minimum = MinAPIVersion
+	}
+
+	major, minor, err := ParseAPIVersion(version)
+	if err != nil {
+		return err
+	}
+	minMajor, minMinor, err := ParseAPIVersion(minimum)
+	if err != nil {
+		return fmt.Errorf("invalid minimum Graph API version: %w", err)
+	}
+
+	if major < minMajor || (major == minMajor && minor < minMinor) {
+		return fmt.Errorf("Graph API version %s is older than the minimum %s: set api_version to %s or later, e.g. %s",
+			version, minimum, minimum, DefaultAPIVersion)
+	}
+	return nil
+}
+
+// Validate checks that the API version is well-formed and not older than MinAPIVersion
+func (fa *FacebookAuth) Validate() error {
+	return ValidateAPIVersion(fa.APIVersion, fa.MinAPIVersion)
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateAPIVersion(t *testing.T) {
+	tests := []struct {
+		version     string
+		minimum     string
+		expectError string
+	}{
+		{version: "v22.0"},
+		{version: DefaultAPIVersion},
+		{version: MinAPIVersion},
+		{version: "v100.1"},
+		{version: "v18.1", minimum: "v18.1"},
+		{version: "v16.0", minimum: "v15.0"},
+		{version: "", expectError: "no Graph API version configured"},
+		{version: "22.0", expectError: "vNN.N format"},
+		{version: "v22", expectError: "vNN.N format"},
+		{version: "V22.0", expectError: "vNN.N format"},
+		{version: "v22.0/", expectError: "vNN.N format"},
+		{version: " v22.0", expectError: "vNN.N format"},
+		{version: "v22.x", expectError: "vNN.N format"},
+		{version: "latest", expectError: "vNN.N format"},
+		{version: "v17.0", expectError: "older than the minimum v18.0"},
+		{version: "v20.0", minimum: "v21.0", expectError: "older than the minimum v21.0"},
+		{version: "v21.0", minimum: "v21.1", expectError: "older than the minimum v21.1"},
+		{version: "v22.0", minimum: "21", expectError: "invalid minimum"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.minimum, func(t *testing.T) {
+			err := ValidateAPIVersion(tt.version, tt.minimum)
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("Expected %q to be valid, got %v", tt.version, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing %q for %q, got %v", tt.expectError, tt.version, err)
+			}
+		})
+	}
+}
+
+func TestGetAuthenticatedRequest_RequiresAPIVersion(t *testing.T) {
+	fa := NewFacebookAuth("app", "secret", "token", "")
+	if _, err := fa.GetAuthenticatedRequest("act_1/campaigns", nil); err == nil {
+		t.Error("Expected a request without API version to be refused")
+	}
+
+	// Requests built from the base URL are refused before they reach the network
+	fa.APIVersion = "v17.0"
+	if _, err := fa.HTTPClient().Get(fa.GetAPIBaseURL() + "/me"); err == nil || !strings.Contains(err.Error(), "older than the minimum") {
+		t.Errorf("Expected an outdated API version to be refused, got %v", err)
+	}
+}
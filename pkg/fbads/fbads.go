@@ -0,0 +1,345 @@
+// Package fbads exposes the Facebook Ads tooling as a Go library.
+//
+// The CLI keeps its implementation under internal/, so this package wraps the
+// pieces other programs need (campaign reads and writes, audience search and
+// metrics) behind a single Client and re-exports the model types they use.
+package fbads
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/user/fb-ads/internal/api"
+	"github.com/user/fb-ads/internal/audience"
+	"github.com/user/fb-ads/internal/campaign"
+	"github.com/user/fb-ads/internal/demo"
+	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+)
+
+// DefaultAPIVersion is the Graph API version used when none is given
+const DefaultAPIVersion = auth.DefaultAPIVersion
+
+// MinAPIVersion is the oldest Graph API version accepted when no other minimum is given
+const MinAPIVersion = auth.MinAPIVersion
+
+// Model and error types re-exported for library users
+type (
+	Campaign            = models.Campaign
+	CampaignDetails     = models.CampaignDetails
+	AdSetDetails        = models.AdSetDetails
+	AdDetails           = models.AdDetails
+	CreativeDetails     = models.CreativeDetails
+	CreativeResponse    = models.CreativeResponse
+	CampaignConfig      = models.CampaignConfig
+	AdSetConfig         = models.AdSetConfig
+	AdConfig            = models.AdConfig
+	CreativeConfig      = models.CreativeConfig
+	Page                = models.Page
+	CampaignPerformance = models.CampaignPerformance
+	AudienceSegment     = audience.AudienceSegment
+	FacebookAPIError    = auth.FacebookAPIError
+)
+
+// Credentials holds what is needed to talk to the Marketing API
+type Credentials struct {
+	AppID         string
+	AppSecret     string
+	AccessToken   string
+	APIVersion    string // Defaults to DefaultAPIVersion
+	MinAPIVersion string // Oldest APIVersion accepted, defaults to MinAPIVersion
+	AccountID     string // Ad account ID without the act_ prefix
+	Demo          bool   // Answer every request from a sample ad account instead of Facebook
+}
+
+// Client is the entry point for using fb-ads as a library
+type Client struct {
+	auth      *auth.FacebookAuth
+	accountID string
+	api       *api.Client
+	creator   *campaign.CampaignCreator
+	audience  *audience.AudienceAnalyzer
+	metrics   *api.MetricsCollector
+}
+
+// Option changes how a Client sends its requests
+type Option func(*auth.FacebookAuth)
+
+// WithHTTPClient sends requests with the transport and timeout of httpClient, e.g. the
+// client of an httptest.Server or one with a recording transport
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(fa *auth.FacebookAuth) {
+		fa.Transport = httpClient.Transport
+		if fa.Transport == nil {
+			fa.Transport = http.DefaultTransport
+		}
+		fa.Timeout = httpClient.Timeout
+	}
+}
+
+// WithBaseURL sends requests to baseURL instead of https://graph.facebook.com; the API
+// version is still added to the path
+func WithBaseURL(baseURL string) Option {
+	return func(fa *auth.FacebookAuth) {
+		fa.BaseURL = baseURL
+	}
+}
+
+// NewClient creates a new library client from credentials. Options apply to every request
+// of the client, so tests can point it at a fake server.
+func NewClient(creds Credentials, opts ...Option) (*Client, error) {
+	if creds.AccountID == "" {
+		return nil, fmt.Errorf("account ID is required")
+	}
+
+	if creds.APIVersion == "" {
+		creds.APIVersion = DefaultAPIVersion
+	}
+
+	authClient := auth.NewFacebookAuth(creds.AppID, creds.AppSecret, creds.AccessToken, creds.APIVersion)
+	authClient.MinAPIVersion = creds.MinAPIVersion
+	if err := authClient.Validate(); err != nil {
+		return nil, err
+	}
+	if creds.Demo {
+		authClient.Transport = demo.NewProvider()
+	}
+	for _, opt := range opts {
+		opt(authClient)
+	}
+
+	return &Client{
+		auth:      authClient,
+		accountID: creds.AccountID,
+		api:       api.NewClient(authClient, creds.AccountID),
+		creator:   campaign.NewCampaignCreator(authClient, creds.AccountID),
+		audience:  audience.NewAudienceAnalyzer(authClient, creds.AccountID),
+		metrics:   api.NewMetricsCollector(authClient, creds.AccountID),
+	}, nil
+}
+
+// ListCampaigns returns every campaign in the ad account
+func (c *Client) ListCampaigns() ([]Campaign, error) {
+	return c.ListCampaignsContext(context.Background())
+}
+
+// ListCampaignsContext returns every campaign in the ad account, stopping when ctx is cancelled
+func (c *Client) ListCampaignsContext(ctx context.Context) ([]Campaign, error) {
+	return c.api.GetAllCampaignsContext(ctx)
+}
+
+// GetCampaign returns a campaign with its ad sets and ads
+func (c *Client) GetCampaign(campaignID string) (*CampaignDetails, error) {
+	return c.GetCampaignContext(context.Background(), campaignID)
+}
+
+// GetCampaignContext returns a campaign with its ad sets and ads
+func (c *Client) GetCampaignContext(ctx context.Context, campaignID string) (*CampaignDetails, error) {
+	return c.api.GetCampaignDetailsContext(ctx, campaignID)
+}
+
+// CreateCampaign creates a campaign with its ad sets and ads from a configuration
+func (c *Client) CreateCampaign(config *CampaignConfig) error {
+	return c.CreateCampaignContext(context.Background(), config)
+}
+
+// CreateCampaignContext creates a campaign with its ad sets and ads from a configuration
+func (c *Client) CreateCampaignContext(ctx context.Context, config *CampaignConfig) error {
+	return c.creator.CreateFromConfigContext(ctx, config)
+}
+
+// UploadImage uploads a local image file or an image URL to the account's image library and
+// returns the hash creatives reference it by
+func (c *Client) UploadImage(pathOrURL string) (string, error) {
+	return c.UploadImageContext(context.Background(), pathOrURL)
+}
+
+// UploadImageContext uploads an image like UploadImage
+func (c *Client) UploadImageContext(ctx context.Context, pathOrURL string) (string, error) {
+	return c.creator.UploadImageContext(ctx, pathOrURL)
+}
+
+// UploadAdVideo uploads a local video file or a video URL to the account's video library and
+// returns the ID creatives reference it by as video_id
+func (c *Client) UploadAdVideo(path string) (string, error) {
+	return c.UploadAdVideoContext(context.Background(), path)
+}
+
+// UploadAdVideoContext uploads a video like UploadAdVideo
+func (c *Client) UploadAdVideoContext(ctx context.Context, path string) (string, error) {
+	return c.creator.UploadAdVideoContext(ctx, path)
+}
+
+// UpdateCampaign updates campaign fields with raw Graph API parameters
+func (c *Client) UpdateCampaign(campaignID string, params url.Values) error {
+	return c.UpdateCampaignContext(context.Background(), campaignID, params)
+}
+
+// UpdateCampaignContext updates campaign fields with raw Graph API parameters
+func (c *Client) UpdateCampaignContext(ctx context.Context, campaignID string, params url.Values) error {
+	return c.api.UpdateCampaignContext(ctx, campaignID, params)
+}
+
+// SetCampaignStatus changes the status of a campaign (ACTIVE, PAUSED, ARCHIVED)
+func (c *Client) SetCampaignStatus(campaignID, status string) error {
+	return c.SetCampaignStatusContext(context.Background(), campaignID, status)
+}
+
+// SetCampaignStatusContext changes the status of a campaign (ACTIVE, PAUSED, ARCHIVED)
+func (c *Client) SetCampaignStatusContext(ctx context.Context, campaignID, status string) error {
+	params := url.Values{}
+	params.Set("status", status)
+	return c.api.UpdateCampaignContext(ctx, campaignID, params)
+}
+
+// DeleteCampaign deletes a campaign
+func (c *Client) DeleteCampaign(campaignID string) error {
+	return c.DeleteCampaignContext(context.Background(), campaignID)
+}
+
+// DeleteCampaignContext deletes a campaign
+func (c *Client) DeleteCampaignContext(ctx context.Context, campaignID string) error {
+	return c.api.DeleteCampaignContext(ctx, campaignID)
+}
+
+// DuplicateOptions controls how DuplicateCampaign copies a campaign
+type DuplicateOptions struct {
+	Name            string  // Name of the copy; empty names it "Copy of <original name>"
+	Status          string  // Status of the copy; empty creates it PAUSED
+	BudgetFactor    float64 // Multiplier for the budgets; 0 keeps them
+	TargetAccountID string  // Ad account to create the copy in; empty uses the client's account
+	ReuseCreatives  bool    // Show the original creatives instead of recreating them; only within the account
+}
+
+// DuplicateCampaign copies a campaign with its ad sets and ads and returns the ID of the copy
+func (c *Client) DuplicateCampaign(campaignID string, options DuplicateOptions) (string, error) {
+	return c.DuplicateCampaignContext(context.Background(), campaignID, options)
+}
+
+// DuplicateCampaignContext copies a campaign like DuplicateCampaign. A copy into another
+// ad account is checked first: its pages, custom audiences and images must be usable
+// from that account, otherwise nothing is created and the problems are returned.
+func (c *Client) DuplicateCampaignContext(ctx context.Context, campaignID string, options DuplicateOptions) (string, error) {
+	details, err := c.api.GetCampaignDetailsContext(ctx, campaignID)
+	if err != nil {
+		return "", err
+	}
+
+	targetID := strings.TrimPrefix(options.TargetAccountID, "act_")
+	if targetID == "" {
+		targetID = c.accountID
+	}
+	crossAccount := targetID != c.accountID
+	if crossAccount && options.ReuseCreatives {
+		return "", fmt.Errorf("cannot reuse the creatives of campaign %s in account %s: creatives belong to the account they were created in", campaignID, targetID)
+	}
+
+	status := options.Status
+	if status == "" {
+		status = "PAUSED"
+	}
+	config := campaign.DuplicateConfig(details, campaign.DuplicateOptions{
+		Name:           options.Name,
+		Status:         status,
+		BudgetFactor:   options.BudgetFactor,
+		CrossAccount:   crossAccount,
+		ReuseCreatives: options.ReuseCreatives,
+	})
+
+	problems := models.ValidateCampaignConfig(config)
+	creator := c.creator
+	if crossAccount {
+		problems = append(problems, campaign.CheckCrossAccountImages(config)...)
+		references, err := api.NewClient(c.auth, targetID).CheckAccountReferencesContext(ctx, config)
+		if err != nil {
+			return "", err
+		}
+		problems = append(problems, references...)
+		creator = campaign.NewCampaignCreator(c.auth, targetID)
+	}
+	if err := problems.Err(); err != nil {
+		return "", fmt.Errorf("cannot copy campaign %s to account %s: %w", campaignID, targetID, err)
+	}
+
+	return creator.CreateFromConfigWithIDContext(ctx, config)
+}
+
+// ListCreatives returns a page of the account's ad creatives. Pass the after cursor of a
+// page to get the next one.
+func (c *Client) ListCreatives(limit int, after string) (*CreativeResponse, error) {
+	return c.ListCreativesContext(context.Background(), limit, after)
+}
+
+// ListCreativesContext returns a page of the account's ad creatives
+func (c *Client) ListCreativesContext(ctx context.Context, limit int, after string) (*CreativeResponse, error) {
+	return c.api.GetAdCreativesContext(ctx, limit, after)
+}
+
+// ListPages returns the Facebook Pages available to the access token
+func (c *Client) ListPages() ([]Page, error) {
+	return c.ListPagesContext(context.Background())
+}
+
+// ListPagesContext returns the Facebook Pages available to the access token
+func (c *Client) ListPagesContext(ctx context.Context) ([]Page, error) {
+	return c.api.GetPagesContext(ctx)
+}
+
+// SearchAudience searches targeting options, e.g. type "adinterest"
+func (c *Client) SearchAudience(searchType, query string) ([]AudienceSegment, error) {
+	return c.SearchAudienceContext(context.Background(), searchType, query)
+}
+
+// SearchAudienceContext searches targeting options, e.g. type "adinterest"
+func (c *Client) SearchAudienceContext(ctx context.Context, searchType, query string) ([]AudienceSegment, error) {
+	return c.audience.SearchContext(ctx, searchType, "", query)
+}
+
+// AudienceSize returns the estimated audience size for an interest in the US
+func (c *Client) AudienceSize(interestID string) (int64, error) {
+	return c.AudienceSizeContext(context.Background(), interestID)
+}
+
+// AudienceSizeContext returns the estimated audience size for an interest
+func (c *Client) AudienceSizeContext(ctx context.Context, interestID string) (int64, error) {
+	return c.audience.GetAudienceSizeForTargetingContext(ctx, interestID, nil)
+}
+
+// AudienceSizeForTargeting returns the estimated audience size for an interest within a
+// targeting spec, e.g. with geo_locations, age_min, age_max and genders
+func (c *Client) AudienceSizeForTargeting(interestID string, targeting map[string]interface{}) (int64, error) {
+	return c.AudienceSizeForTargetingContext(context.Background(), interestID, targeting)
+}
+
+// AudienceSizeForTargetingContext returns the estimated audience size for an interest
+// within a targeting spec
+func (c *Client) AudienceSizeForTargetingContext(ctx context.Context, interestID string, targeting map[string]interface{}) (int64, error) {
+	return c.audience.GetAudienceSizeForTargetingContext(ctx, interestID, targeting)
+}
+
+// AudienceSizeForInterests returns the estimated number of people within a targeting spec
+// who have any of the interests
+func (c *Client) AudienceSizeForInterests(interestIDs []string, targeting map[string]interface{}) (int64, error) {
+	return c.AudienceSizeForInterestsContext(context.Background(), interestIDs, targeting)
+}
+
+// AudienceSizeForInterestsContext returns the estimated number of people within a
+// targeting spec who have any of the interests
+func (c *Client) AudienceSizeForInterestsContext(ctx context.Context, interestIDs []string, targeting map[string]interface{}) (int64, error) {
+	return c.audience.GetAudienceSizeForInterestsContext(ctx, interestIDs, targeting)
+}
+
+// CampaignMetrics returns campaign level performance between two dates
+func (c *Client) CampaignMetrics(since, until time.Time) ([]CampaignPerformance, error) {
+	return c.metrics.CollectCampaignMetrics(api.InsightsRequest{
+		Level: "campaign",
+		TimeRange: api.TimeRange{
+			Since: since.Format("2006-01-02"),
+			Until: until.Format("2006-01-02"),
+		},
+	})
+}
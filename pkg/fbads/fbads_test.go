@@ -0,0 +1,161 @@
+package fbads
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestNewClientRequiresAccount(t *testing.T) {
+	if _, err := NewClient(Credentials{AccessToken: "token"}); err == nil {
+		t.Errorf("Expected error for missing account ID")
+	}
+}
+
+func TestNewClientValidatesAPIVersion(t *testing.T) {
+	if _, err := NewClient(Credentials{AccountID: "123", AccessToken: "token", APIVersion: "22.0"}); err == nil {
+		t.Error("Expected error for a malformed API version")
+	}
+	if _, err := NewClient(Credentials{AccountID: "123", AccessToken: "token", APIVersion: "v16.0"}); err == nil {
+		t.Error("Expected error for an API version below the minimum")
+	}
+	if _, err := NewClient(Credentials{AccountID: "123", AccessToken: "token", APIVersion: "v16.0", MinAPIVersion: "v16.0"}); err != nil {
+		t.Errorf("Expected a lowered minimum to accept v16.0, got %v", err)
+	}
+}
+
+func TestClientDemo(t *testing.T) {
+	client, err := NewClient(Credentials{AccountID: "123", Demo: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	campaigns, err := client.ListCampaigns()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(campaigns) == 0 {
+		t.Fatalf("Expected demo campaigns")
+	}
+
+	details, err := client.GetCampaign(campaigns[0].ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if details.Name != campaigns[0].Name {
+		t.Errorf("Expected campaign %q, got %q", campaigns[0].Name, details.Name)
+	}
+
+	// Writes change the demo account
+	if err := client.SetCampaignStatus(campaigns[0].ID, "PAUSED"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	details, err = client.GetCampaign(campaigns[0].ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if details.Status != "PAUSED" {
+		t.Errorf("Expected the campaign to be paused, got %s", details.Status)
+	}
+}
+
+func TestClientRefusesMissingToken(t *testing.T) {
+	for _, token := range []string{"", auth.PlaceholderAccessToken} {
+		client, err := NewClient(Credentials{AccountID: "123", AccessToken: token})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if _, err := client.ListCampaigns(); !errors.Is(err, auth.ErrNoAccessToken) {
+			t.Errorf("Token %q: expected ErrNoAccessToken, got %v", token, err)
+		}
+	}
+}
+
+func TestClientOptions_FakeServer(t *testing.T) {
+	var updates []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v22.0/act_123/campaigns":
+			// Two pages linked by the after cursor
+			if r.URL.Query().Get("after") == "" {
+				fmt.Fprint(w, `{"data":[{"id":"1","name":"Spring"},{"id":"2","name":"Summer"}],"paging":{"cursors":{"after":"page2"},"next":"https://graph.facebook.com/next"}}`)
+				return
+			}
+			if r.URL.Query().Get("after") != "page2" {
+				t.Errorf("Unexpected cursor %q", r.URL.Query().Get("after"))
+			}
+			fmt.Fprint(w, `{"data":[{"id":"3","name":"Autumn"}],"paging":{"cursors":{"after":"end"}}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/v22.0/1":
+			if err := r.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+			updates = append(updates, r.Form)
+			fmt.Fprint(w, `{"success":true}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"error":{"message":"Unexpected %s %s","type":"GraphMethodException","code":100}}`, r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Credentials{AccountID: "123", AccessToken: "token"}, WithHTTPClient(server.Client()), WithBaseURL(server.URL+"/"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	campaigns, err := client.ListCampaigns()
+	if err != nil {
+		t.Fatalf("ListCampaigns failed: %v", err)
+	}
+	var names []string
+	for _, campaign := range campaigns {
+		names = append(names, campaign.Name)
+	}
+	if strings.Join(names, ",") != "Spring,Summer,Autumn" {
+		t.Errorf("Expected the campaigns of both pages, got %v", names)
+	}
+
+	if err := client.UpdateCampaign("1", url.Values{"name": {"Spring 2"}}); err != nil {
+		t.Fatalf("UpdateCampaign failed: %v", err)
+	}
+	if len(updates) != 1 || updates[0].Get("name") != "Spring 2" || updates[0].Get("access_token") != "token" {
+		t.Errorf("Expected one authenticated update, got %v", updates)
+	}
+
+	// Facebook errors from the server are returned as API errors
+	var apiErr *FacebookAPIError
+	if _, err := client.GetCampaign("missing"); !errors.As(err, &apiErr) || !apiErr.IsInvalidParameter() {
+		t.Errorf("Expected an invalid parameter error, got %v", err)
+	}
+}
+
+func TestDuplicateCampaign_OtherAccount(t *testing.T) {
+	client, err := NewClient(Credentials{AccountID: "123", Demo: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	campaigns, err := client.ListCampaigns()
+	if err != nil || len(campaigns) == 0 {
+		t.Fatalf("Expected demo campaigns, got %v", err)
+	}
+
+	copyID, err := client.DuplicateCampaign(campaigns[0].ID, DuplicateOptions{TargetAccountID: "act_456"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	duplicate, err := client.GetCampaign(copyID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if duplicate.Name != "Copy of "+campaigns[0].Name || duplicate.Status != "PAUSED" {
+		t.Errorf("Expected a paused copy, got %q (%s)", duplicate.Name, duplicate.Status)
+	}
+}
@@ -20,12 +20,16 @@ type Campaign struct {
 	StartTime            time.Time `json:"start_time,omitempty"`
 	StopTime             time.Time `json:"stop_time,omitempty"`
 	SpecialAdCategories  []string  `json:"special_ad_categories,omitempty"`
+	Labels               []string  `json:"labels,omitempty"` // Names of the campaign's ad labels
 	
 	// Raw time strings for parsing flexibility
 	CreatedTimeString    string    `json:"created_time_string,omitempty"`
 	UpdatedTimeString    string    `json:"updated_time_string,omitempty"`
 	StartTimeString      string    `json:"start_time_string,omitempty"`
 	StopTimeString       string    `json:"stop_time_string,omitempty"`
+	
+	// Fields that could not be parsed from the API response
+	Warnings             []string  `json:"warnings,omitempty"`
 }
 
 // CampaignResponse represents the Facebook API response for campaigns
@@ -35,6 +39,24 @@ type CampaignResponse struct {
 	Summary Summary    `json:"summary,omitempty"`
 }
 
+// AdSetResponse represents the Facebook API response for ad sets
+type AdSetResponse struct {
+	Data   []AdSetDetails `json:"data"`
+	Paging Paging         `json:"paging"`
+}
+
+// AdResponse represents the Facebook API response for ads
+type AdResponse struct {
+	Data   []AdDetails `json:"data"`
+	Paging Paging      `json:"paging"`
+}
+
+// CreativeResponse represents the Facebook API response for ad creatives
+type CreativeResponse struct {
+	Data   []CreativeDetails `json:"data"`
+	Paging Paging            `json:"paging"`
+}
+
 // Paging represents pagination information from Facebook API responses
 type Paging struct {
 	Cursors Cursors `json:"cursors"`
@@ -60,8 +82,8 @@ type CampaignDetails struct {
 	Status              string                 `json:"status"`
 	ObjectiveType       string                 `json:"objective_type"`
 	SpendCap            float64                `json:"spend_cap,omitempty"`
-	DailyBudget         float64                `json:"daily_budget,omitempty"`
-	LifetimeBudget      float64                `json:"lifetime_budget,omitempty"`
+	DailyBudget         float64                `json:"daily_budget,omitempty"`    // In cents
+	LifetimeBudget      float64                `json:"lifetime_budget,omitempty"` // In cents
 	BidStrategy         string                 `json:"bid_strategy,omitempty"`
 	BuyingType          string                 `json:"buying_type"`
 	Created             time.Time              `json:"created_time"`
@@ -70,41 +92,55 @@ type CampaignDetails struct {
 	StopTime            time.Time              `json:"stop_time,omitempty"`
 	SpecialAdCategories []string               `json:"special_ad_categories,omitempty"`
 	Targeting           map[string]interface{} `json:"targeting,omitempty"`
+	Labels              []string               `json:"labels,omitempty"` // Names of the campaign's ad labels
 	AdSets              []AdSetDetails         `json:"adsets,omitempty"`
 	Ads                 []AdDetails            `json:"ads,omitempty"`
+	Warnings            []string               `json:"warnings,omitempty"` // Fields that could not be parsed from the API response
 }
 
 // AdSetDetails represents detailed information about an ad set
 type AdSetDetails struct {
 	ID               string                 `json:"id"`
+	CampaignID       string                 `json:"campaign_id,omitempty"`
 	Name             string                 `json:"name"`
 	Status           string                 `json:"status"`
 	OptimizationGoal string                 `json:"optimization_goal"`
 	BillingEvent     string                 `json:"billing_event"`
-	BidAmount        float64                `json:"bid_amount"`
+	BidAmount        float64                `json:"bid_amount"` // In cents
+	BidStrategy      string                 `json:"bid_strategy,omitempty"`
+	DailyBudget      float64                `json:"daily_budget,omitempty"`    // In cents, with ad set budgets
+	LifetimeBudget   float64                `json:"lifetime_budget,omitempty"` // In cents, with ad set budgets
 	StartTime        time.Time              `json:"start_time,omitempty"`
 	EndTime          time.Time              `json:"end_time,omitempty"`
 	Targeting        map[string]interface{} `json:"targeting,omitempty"`
+	DestinationType  string                 `json:"destination_type,omitempty"`
 }
 
 // AdDetails represents detailed information about an ad
 type AdDetails struct {
-	ID       string          `json:"id"`
-	Name     string          `json:"name"`
-	Status   string          `json:"status"`
-	Creative CreativeDetails `json:"creative,omitempty"`
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Status     string          `json:"status"`
+	AdSetID    string          `json:"adset_id,omitempty"`
+	CampaignID string          `json:"campaign_id,omitempty"`
+	Creative   CreativeDetails `json:"creative,omitempty"`
 }
 
 // CreativeDetails represents detailed information about an ad creative
 type CreativeDetails struct {
-	ID               string `json:"id"`
-	Name             string `json:"name"`
-	Title            string `json:"title,omitempty"`
-	Body             string `json:"body,omitempty"`
-	ImageURL         string `json:"image_url,omitempty"`
-	LinkURL          string `json:"link_url,omitempty"`
-	CallToActionType string `json:"call_to_action_type,omitempty"`
-	PageID           string `json:"page_id,omitempty"`
+	ID                 string         `json:"id"`
+	Name               string         `json:"name"`
+	Title              string         `json:"title,omitempty"`
+	Body               string         `json:"body,omitempty"`
+	ImageURL           string         `json:"image_url,omitempty"`
+	ImageHash          string         `json:"image_hash,omitempty"`
+	VideoID            string         `json:"video_id,omitempty"`
+	Cards              []CreativeCard `json:"cards,omitempty"`
+	LinkURL            string         `json:"link_url,omitempty"`
+	CallToActionType   string         `json:"call_to_action_type,omitempty"`
+	PageID             string         `json:"page_id,omitempty"`
+	WhatsAppNumber     string         `json:"whatsapp_number,omitempty"`
+	PageWelcomeMessage string         `json:"page_welcome_message,omitempty"`
 }
 
 // CampaignConfig represents a campaign configuration for creating or exporting campaigns
@@ -115,12 +151,27 @@ type CampaignConfig struct {
 	BuyingType          string          `json:"buying_type"`
 	SpecialAdCategories []string        `json:"special_ad_categories,omitempty"`
 	BidStrategy         string          `json:"bid_strategy"`
-	DailyBudget         float64         `json:"daily_budget,omitempty"`
-	LifetimeBudget      float64         `json:"lifetime_budget,omitempty"`
+	DailyBudget         float64         `json:"daily_budget,omitempty"`    // In dollars
+	LifetimeBudget      float64         `json:"lifetime_budget,omitempty"` // In dollars
 	StartTime           string          `json:"start_time,omitempty"`
 	EndTime             string          `json:"end_time,omitempty"`
 	AdSets              []AdSetConfig   `json:"adsets"`
-	Ads                 []AdConfig      `json:"ads"`
+	Ads                 []AdConfig      `json:"ads"` // Ads spread across the ad sets in turn; ads nested in an ad set stay in it
+}
+
+// AllAds returns every ad of the config: the ads nested in each ad set, then the top-level
+// ones. The ads are returned by pointer so they can be updated in place.
+func (c *CampaignConfig) AllAds() []*AdConfig {
+	var ads []*AdConfig
+	for i := range c.AdSets {
+		for j := range c.AdSets[i].Ads {
+			ads = append(ads, &c.AdSets[i].Ads[j])
+		}
+	}
+	for i := range c.Ads {
+		ads = append(ads, &c.Ads[i])
+	}
+	return ads
 }
 
 // AdSetConfig represents configuration for an ad set
@@ -130,9 +181,11 @@ type AdSetConfig struct {
 	Targeting        map[string]interface{} `json:"targeting"`
 	OptimizationGoal string                 `json:"optimization_goal"`
 	BillingEvent     string                 `json:"billing_event"`
-	BidAmount        float64                `json:"bid_amount"`
+	BidAmount        float64                `json:"bid_amount"` // In dollars
 	StartTime        string                 `json:"start_time,omitempty"`
 	EndTime          string                 `json:"end_time,omitempty"`
+	DestinationType  string                 `json:"destination_type,omitempty"` // WHATSAPP, MESSENGER or INSTAGRAM_DIRECT for click-to-message ad sets
+	Ads              []AdConfig             `json:"ads,omitempty"`              // Ads created in this ad set
 }
 
 // AdConfig represents configuration for an ad
@@ -140,17 +193,40 @@ type AdConfig struct {
 	Name     string          `json:"name"`
 	Status   string          `json:"status,omitempty"`
 	Creative CreativeConfig  `json:"creative"`
+	CreativeRef string       `json:"creative_ref,omitempty"` // Placeholder resolved from a creative library instead of an inline creative
 }
 
 // CreativeConfig represents configuration for an ad creative
 type CreativeConfig struct {
-	Title            string `json:"title,omitempty"`
-	Name             string `json:"name,omitempty"`  // Added to support templates using name instead of title
-	Body             string `json:"body,omitempty"`
-	ImageURL         string `json:"image_url,omitempty"`
-	LinkURL          string `json:"link_url,omitempty"`
-	CallToAction     string `json:"call_to_action,omitempty"`
-	PageID           string `json:"page_id"`
+	CreativeID         string         `json:"creative_id,omitempty"` // Existing creative the ad reuses instead of creating one; no other field may be set
+	Title              string         `json:"title,omitempty"`
+	Name               string         `json:"name,omitempty"` // Added to support templates using name instead of title
+	Body               string         `json:"body,omitempty"`
+	ImageURL           string         `json:"image_url,omitempty"`  // Image file path or URL, uploaded when the campaign is created
+	ImageHash          string         `json:"image_hash,omitempty"` // Hash of an image in the account's image library
+	VideoID            string         `json:"video_id,omitempty"`   // Video in the account's library; makes this a video ad with the image as thumbnail
+	Cards              []CreativeCard `json:"cards,omitempty"`      // Cards of a carousel ad; link_url is then the "see more" link
+	LinkURL            string         `json:"link_url,omitempty"`
+	CallToAction       string         `json:"call_to_action,omitempty"`
+	PageID             string         `json:"page_id"`
+	WhatsAppNumber     string         `json:"whatsapp_number,omitempty"`      // Business number for click-to-WhatsApp ads
+	PageWelcomeMessage string         `json:"page_welcome_message,omitempty"` // Greeting shown when the conversation opens
+}
+
+// MinCarouselCards is the fewest cards a carousel ad can have
+const MinCarouselCards = 2
+
+// MaxCarouselCards is the most cards a carousel ad can have
+const MaxCarouselCards = 10
+
+// CreativeCard is one card of a carousel ad
+type CreativeCard struct {
+	Title        string `json:"title,omitempty"`
+	Body         string `json:"body,omitempty"`
+	LinkURL      string `json:"link_url"`
+	ImageURL     string `json:"image_url,omitempty"` // Image file path or URL, uploaded when the campaign is created
+	ImageHash    string `json:"image_hash,omitempty"`
+	CallToAction string `json:"call_to_action,omitempty"`
 }
 
 // Page represents a Facebook Page
@@ -0,0 +1,285 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CampaignStatus is the configured status of a campaign, ad set or ad
+type CampaignStatus string
+
+// Campaign statuses
+const (
+	CampaignStatusActive   CampaignStatus = "ACTIVE"
+	CampaignStatusPaused   CampaignStatus = "PAUSED"
+	CampaignStatusArchived CampaignStatus = "ARCHIVED"
+	CampaignStatusDeleted  CampaignStatus = "DELETED"
+)
+
+// CampaignStatuses lists every campaign status
+var CampaignStatuses = []CampaignStatus{
+	CampaignStatusActive, CampaignStatusPaused, CampaignStatusArchived, CampaignStatusDeleted,
+}
+
+// SettableCampaignStatuses lists the statuses an object can be updated to; deleting has its own call
+var SettableCampaignStatuses = []CampaignStatus{
+	CampaignStatusActive, CampaignStatusPaused, CampaignStatusArchived,
+}
+
+// Objective is a campaign objective (the outcome-based objectives of the Marketing API)
+type Objective string
+
+// Campaign objectives
+const (
+	ObjectiveAwareness    Objective = "OUTCOME_AWARENESS"
+	ObjectiveTraffic      Objective = "OUTCOME_TRAFFIC"
+	ObjectiveEngagement   Objective = "OUTCOME_ENGAGEMENT"
+	ObjectiveLeads        Objective = "OUTCOME_LEADS"
+	ObjectiveAppPromotion Objective = "OUTCOME_APP_PROMOTION"
+	ObjectiveSales        Objective = "OUTCOME_SALES"
+)
+
+// Objectives lists every campaign objective
+var Objectives = []Objective{
+	ObjectiveAwareness, ObjectiveTraffic, ObjectiveEngagement, ObjectiveLeads, ObjectiveAppPromotion, ObjectiveSales,
+}
+
+// LegacyObjectives maps the objectives used before the outcome-based objectives (ODAX) to
+// their outcome-based equivalents. Newer API versions reject the legacy names.
+var LegacyObjectives = map[string]Objective{
+	"BRAND_AWARENESS":       ObjectiveAwareness,
+	"REACH":                 ObjectiveAwareness,
+	"LOCAL_AWARENESS":       ObjectiveAwareness,
+	"LINK_CLICKS":           ObjectiveTraffic,
+	"POST_ENGAGEMENT":       ObjectiveEngagement,
+	"PAGE_LIKES":            ObjectiveEngagement,
+	"EVENT_RESPONSES":       ObjectiveEngagement,
+	"VIDEO_VIEWS":           ObjectiveEngagement,
+	"MESSAGES":              ObjectiveEngagement,
+	"LEAD_GENERATION":       ObjectiveLeads,
+	"APP_INSTALLS":          ObjectiveAppPromotion,
+	"CONVERSIONS":           ObjectiveSales,
+	"PRODUCT_CATALOG_SALES": ObjectiveSales,
+}
+
+// NormalizeObjective translates a legacy objective to its outcome-based equivalent. Known
+// objectives are returned in the case the API expects; anything else is returned unchanged.
+func NormalizeObjective(value string) string {
+	normalized := strings.ToUpper(strings.TrimSpace(value))
+	if objective, ok := LegacyObjectives[normalized]; ok {
+		return string(objective)
+	}
+	if Objective(normalized).IsValid() {
+		return normalized
+	}
+	return value
+}
+
+// BuyingType is how a campaign buys its ads
+type BuyingType string
+
+// Buying types
+const (
+	BuyingTypeAuction  BuyingType = "AUCTION"
+	BuyingTypeReserved BuyingType = "RESERVED"
+)
+
+// BuyingTypes lists every buying type
+var BuyingTypes = []BuyingType{BuyingTypeAuction, BuyingTypeReserved}
+
+// BidStrategy is a campaign or ad set bid strategy
+type BidStrategy string
+
+// Bid strategies
+const (
+	BidStrategyLowestCostWithoutCap  BidStrategy = "LOWEST_COST_WITHOUT_CAP"
+	BidStrategyLowestCostWithBidCap  BidStrategy = "LOWEST_COST_WITH_BID_CAP"
+	BidStrategyCostCap               BidStrategy = "COST_CAP"
+	BidStrategyLowestCostWithMinROAS BidStrategy = "LOWEST_COST_WITH_MIN_ROAS"
+)
+
+// BidStrategies lists every bid strategy
+var BidStrategies = []BidStrategy{
+	BidStrategyLowestCostWithoutCap, BidStrategyLowestCostWithBidCap, BidStrategyCostCap, BidStrategyLowestCostWithMinROAS,
+}
+
+// BillingEvent is the event an ad set is charged for
+type BillingEvent string
+
+// Billing events
+const (
+	BillingEventImpressions        BillingEvent = "IMPRESSIONS"
+	BillingEventLinkClicks         BillingEvent = "LINK_CLICKS"
+	BillingEventClicks             BillingEvent = "CLICKS"
+	BillingEventAppInstalls        BillingEvent = "APP_INSTALLS"
+	BillingEventPageLikes          BillingEvent = "PAGE_LIKES"
+	BillingEventPostEngagement     BillingEvent = "POST_ENGAGEMENT"
+	BillingEventThruPlay           BillingEvent = "THRUPLAY"
+	BillingEventPurchase           BillingEvent = "PURCHASE"
+	BillingEventListingInteraction BillingEvent = "LISTING_INTERACTION"
+)
+
+// BillingEvents lists every billing event
+var BillingEvents = []BillingEvent{
+	BillingEventImpressions, BillingEventLinkClicks, BillingEventClicks, BillingEventAppInstalls, BillingEventPageLikes,
+	BillingEventPostEngagement, BillingEventThruPlay, BillingEventPurchase, BillingEventListingInteraction,
+}
+
+// OptimizationGoal is what an ad set's delivery is optimized for
+type OptimizationGoal string
+
+// Optimization goals
+const (
+	OptimizationGoalNone               OptimizationGoal = "NONE"
+	OptimizationGoalAppInstalls        OptimizationGoal = "APP_INSTALLS"
+	OptimizationGoalAdRecallLift       OptimizationGoal = "AD_RECALL_LIFT"
+	OptimizationGoalEngagedUsers       OptimizationGoal = "ENGAGED_USERS"
+	OptimizationGoalEventResponses     OptimizationGoal = "EVENT_RESPONSES"
+	OptimizationGoalImpressions        OptimizationGoal = "IMPRESSIONS"
+	OptimizationGoalLeadGeneration     OptimizationGoal = "LEAD_GENERATION"
+	OptimizationGoalQualityLead        OptimizationGoal = "QUALITY_LEAD"
+	OptimizationGoalLinkClicks         OptimizationGoal = "LINK_CLICKS"
+	OptimizationGoalOffsiteConversions OptimizationGoal = "OFFSITE_CONVERSIONS"
+	OptimizationGoalPageLikes          OptimizationGoal = "PAGE_LIKES"
+	OptimizationGoalPostEngagement     OptimizationGoal = "POST_ENGAGEMENT"
+	OptimizationGoalQualityCall        OptimizationGoal = "QUALITY_CALL"
+	OptimizationGoalReach              OptimizationGoal = "REACH"
+	OptimizationGoalLandingPageViews   OptimizationGoal = "LANDING_PAGE_VIEWS"
+	OptimizationGoalVisitInstagram     OptimizationGoal = "VISIT_INSTAGRAM_PROFILE"
+	OptimizationGoalValue              OptimizationGoal = "VALUE"
+	OptimizationGoalThruPlay           OptimizationGoal = "THRUPLAY"
+	OptimizationGoalDerivedEvents      OptimizationGoal = "DERIVED_EVENTS"
+	OptimizationGoalConversations      OptimizationGoal = "CONVERSATIONS"
+	OptimizationGoalInAppValue         OptimizationGoal = "IN_APP_VALUE"
+	OptimizationGoalSubscribers        OptimizationGoal = "SUBSCRIBERS"
+	OptimizationGoalRemindersSet       OptimizationGoal = "REMINDERS_SET"
+	OptimizationGoalProfileVisit       OptimizationGoal = "PROFILE_VISIT"
+)
+
+// OptimizationGoals lists every optimization goal
+var OptimizationGoals = []OptimizationGoal{
+	OptimizationGoalNone, OptimizationGoalAppInstalls, OptimizationGoalAdRecallLift, OptimizationGoalEngagedUsers,
+	OptimizationGoalEventResponses, OptimizationGoalImpressions, OptimizationGoalLeadGeneration, OptimizationGoalQualityLead,
+	OptimizationGoalLinkClicks, OptimizationGoalOffsiteConversions, OptimizationGoalPageLikes, OptimizationGoalPostEngagement,
+	OptimizationGoalQualityCall, OptimizationGoalReach, OptimizationGoalLandingPageViews, OptimizationGoalVisitInstagram,
+	OptimizationGoalValue, OptimizationGoalThruPlay, OptimizationGoalDerivedEvents, OptimizationGoalConversations,
+	OptimizationGoalInAppValue, OptimizationGoalSubscribers, OptimizationGoalRemindersSet, OptimizationGoalProfileVisit,
+}
+
+// DestinationType is where a click-to-message ad set sends people
+type DestinationType string
+
+// Destination types of click-to-message ad sets
+const (
+	DestinationWhatsApp        DestinationType = "WHATSAPP"
+	DestinationMessenger       DestinationType = "MESSENGER"
+	DestinationInstagramDirect DestinationType = "INSTAGRAM_DIRECT"
+)
+
+// DestinationTypes lists every click-to-message destination type
+var DestinationTypes = []DestinationType{DestinationWhatsApp, DestinationMessenger, DestinationInstagramDirect}
+
+// IsValid reports whether the status is known (case-sensitive, as sent to the API)
+func (s CampaignStatus) IsValid() bool { return containsEnum(CampaignStatuses, s) }
+
+// IsValid reports whether the objective is known (case-sensitive, as sent to the API)
+func (o Objective) IsValid() bool { return containsEnum(Objectives, o) }
+
+// IsValid reports whether the buying type is known (case-sensitive, as sent to the API)
+func (b BuyingType) IsValid() bool { return containsEnum(BuyingTypes, b) }
+
+// IsValid reports whether the bid strategy is known (case-sensitive, as sent to the API)
+func (b BidStrategy) IsValid() bool { return containsEnum(BidStrategies, b) }
+
+// IsValid reports whether the billing event is known (case-sensitive, as sent to the API)
+func (b BillingEvent) IsValid() bool { return containsEnum(BillingEvents, b) }
+
+// IsValid reports whether the optimization goal is known (case-sensitive, as sent to the API)
+func (g OptimizationGoal) IsValid() bool { return containsEnum(OptimizationGoals, g) }
+
+// Values returns the campaign statuses as strings
+func (CampaignStatus) Values() []string { return enumStrings(CampaignStatuses) }
+
+// Values returns the objectives as strings
+func (Objective) Values() []string { return enumStrings(Objectives) }
+
+// Values returns the buying types as strings
+func (BuyingType) Values() []string { return enumStrings(BuyingTypes) }
+
+// Values returns the bid strategies as strings
+func (BidStrategy) Values() []string { return enumStrings(BidStrategies) }
+
+// Values returns the billing events as strings
+func (BillingEvent) Values() []string { return enumStrings(BillingEvents) }
+
+// Values returns the optimization goals as strings
+func (OptimizationGoal) Values() []string { return enumStrings(OptimizationGoals) }
+
+// ParseCampaignStatus parses a status case-insensitively
+func ParseCampaignStatus(value string) (CampaignStatus, error) {
+	return parseEnum("status", value, CampaignStatuses)
+}
+
+// ParseSettableCampaignStatus parses a status an object can be updated to, case-insensitively
+func ParseSettableCampaignStatus(value string) (CampaignStatus, error) {
+	return parseEnum("status", value, SettableCampaignStatuses)
+}
+
+// ParseObjective parses an objective case-insensitively
+func ParseObjective(value string) (Objective, error) {
+	return parseEnum("objective", value, Objectives)
+}
+
+// ParseBuyingType parses a buying type case-insensitively
+func ParseBuyingType(value string) (BuyingType, error) {
+	return parseEnum("buying type", value, BuyingTypes)
+}
+
+// ParseBidStrategy parses a bid strategy case-insensitively
+func ParseBidStrategy(value string) (BidStrategy, error) {
+	return parseEnum("bid strategy", value, BidStrategies)
+}
+
+// ParseBillingEvent parses a billing event case-insensitively
+func ParseBillingEvent(value string) (BillingEvent, error) {
+	return parseEnum("billing event", value, BillingEvents)
+}
+
+// ParseOptimizationGoal parses an optimization goal case-insensitively
+func ParseOptimizationGoal(value string) (OptimizationGoal, error) {
+	return parseEnum("optimization goal", value, OptimizationGoals)
+}
+
+// ParseDestinationType parses a click-to-message destination type case-insensitively
+func ParseDestinationType(value string) (DestinationType, error) {
+	return parseEnum("destination type", value, DestinationTypes)
+}
+
+// containsEnum reports whether value is one of values
+func containsEnum[T ~string](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// enumStrings converts enum values to plain strings
+func enumStrings[T ~string](values []T) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = string(v)
+	}
+	return result
+}
+
+// parseEnum finds value among values ignoring case and surrounding spaces.
+// The error lists the valid values.
+func parseEnum[T ~string](kind, value string, values []T) (T, error) {
+	normalized := T(strings.ToUpper(strings.TrimSpace(value)))
+	if containsEnum(values, normalized) {
+		return normalized, nil
+	}
+	return "", fmt.Errorf("invalid %s %q (valid values: %s)", kind, value, strings.Join(enumStrings(values), ", "))
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEnums(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) (string, error)
+		value string
+		want  string
+	}{
+		{"status lower case", wrap(ParseCampaignStatus), "paused", "PAUSED"},
+		{"status mixed case with spaces", wrap(ParseCampaignStatus), " Active ", "ACTIVE"},
+		{"objective", wrap(ParseObjective), "outcome_sales", "OUTCOME_SALES"},
+		{"bid strategy", wrap(ParseBidStrategy), "Lowest_Cost_Without_Cap", "LOWEST_COST_WITHOUT_CAP"},
+		{"billing event", wrap(ParseBillingEvent), "impressions", "IMPRESSIONS"},
+		{"optimization goal", wrap(ParseOptimizationGoal), "landing_page_views", "LANDING_PAGE_VIEWS"},
+		{"buying type", wrap(ParseBuyingType), "auction", "AUCTION"},
+		{"destination type", wrap(ParseDestinationType), "whatsapp", "WHATSAPP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.value)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseEnums_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		parse     func(string) (string, error)
+		value     string
+		wantInMsg string
+	}{
+		{"typo in bid strategy", wrap(ParseBidStrategy), "LOWEST_COST_WITHOUT_CAPP", "LOWEST_COST_WITH_BID_CAP"},
+		{"legacy objective", wrap(ParseObjective), "CONVERSIONS", "OUTCOME_SALES"},
+		{"unknown status", wrap(ParseCampaignStatus), "RUNNING", "ACTIVE, PAUSED, ARCHIVED, DELETED"},
+		{"empty billing event", wrap(ParseBillingEvent), "", "IMPRESSIONS"},
+		{"unknown optimization goal", wrap(ParseOptimizationGoal), "CLICKS", "LINK_CLICKS"},
+		{"unknown buying type", wrap(ParseBuyingType), "FIXED", "AUCTION, RESERVED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.parse(tt.value)
+			if err == nil {
+				t.Fatalf("Expected an error for %q", tt.value)
+			}
+			if !strings.Contains(err.Error(), "valid values: ") || !strings.Contains(err.Error(), tt.wantInMsg) {
+				t.Errorf("Expected the error to list the valid values, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEnumIsValidAndValues(t *testing.T) {
+	if !BidStrategyCostCap.IsValid() || BidStrategy("cost_cap").IsValid() {
+		t.Errorf("Expected IsValid to accept only the exact API value")
+	}
+
+	values := Objective("").Values()
+	if len(values) != len(Objectives) || values[0] != string(ObjectiveAwareness) {
+		t.Errorf("Unexpected objective values: %v", values)
+	}
+}
+
+func TestNormalizeObjective(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"BRAND_AWARENESS", "OUTCOME_AWARENESS"},
+		{"REACH", "OUTCOME_AWARENESS"},
+		{"LOCAL_AWARENESS", "OUTCOME_AWARENESS"},
+		{"VIDEO_VIEWS", "OUTCOME_ENGAGEMENT"},
+		{"LINK_CLICKS", "OUTCOME_TRAFFIC"},
+		{"POST_ENGAGEMENT", "OUTCOME_ENGAGEMENT"},
+		{"PAGE_LIKES", "OUTCOME_ENGAGEMENT"},
+		{"EVENT_RESPONSES", "OUTCOME_ENGAGEMENT"},
+		{"MESSAGES", "OUTCOME_ENGAGEMENT"},
+		{"LEAD_GENERATION", "OUTCOME_LEADS"},
+		{"APP_INSTALLS", "OUTCOME_APP_PROMOTION"},
+		{"CONVERSIONS", "OUTCOME_SALES"},
+		{"PRODUCT_CATALOG_SALES", "OUTCOME_SALES"},
+		{" conversions ", "OUTCOME_SALES"},
+		{"outcome_traffic", "OUTCOME_TRAFFIC"},
+		{"OUTCOME_LEADS", "OUTCOME_LEADS"},
+		{"STORE_VISITS", "STORE_VISITS"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := NormalizeObjective(tt.value); got != tt.expected {
+				t.Errorf("NormalizeObjective(%q) = %q, want %q", tt.value, got, tt.expected)
+			}
+		})
+	}
+
+	for legacy := range LegacyObjectives {
+		if !Objective(NormalizeObjective(legacy)).IsValid() {
+			t.Errorf("Expected %s to normalize to a valid objective", legacy)
+		}
+	}
+}
+
+// wrap converts a typed parse function into one returning a plain string
+func wrap[T ~string](parse func(string) (T, error)) func(string) (string, error) {
+	return func(value string) (string, error) {
+		parsed, err := parse(value)
+		return string(parsed), err
+	}
+}
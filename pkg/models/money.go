@@ -0,0 +1,19 @@
+package models
+
+import "math"
+
+// The Graph API reports and accepts budgets and bid amounts in cents of the account
+// currency, so Campaign, CampaignDetails and AdSetDetails hold cents. Configurations
+// (CampaignConfig, AdSetConfig) are written by hand and hold dollars. Amounts are
+// converted when a configuration is built from the API and when it is sent back.
+
+// CentsToDollars converts an amount reported by the API to dollars
+func CentsToDollars(cents float64) float64 {
+	return cents / 100
+}
+
+// DollarsToCents converts a configured amount to the cents the API expects, rounded to
+// the nearest cent so that e.g. 19.99 becomes 1999 rather than 1998
+func DollarsToCents(dollars float64) int64 {
+	return int64(math.Round(dollars * 100))
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// CampaignPerformance contains performance metrics for a campaign.
+// It is the shared representation used by the API, statistics and optimization code.
+type CampaignPerformance struct {
+	CampaignID      string    `json:"campaign_id"`
+	Name            string    `json:"name"`
+	Spend           float64   `json:"spend"`
+	Impressions     int       `json:"impressions"`
+	Clicks          int       `json:"clicks"`
+	Conversions     int       `json:"conversions"`
+	CPC             float64   `json:"cpc"`
+	CPM             float64   `json:"cpm"`
+	CTR             float64   `json:"ctr"`
+	CPA             float64   `json:"cpa"`
+	ROAS            float64   `json:"roas"`
+	ConversionValue float64   `json:"conversion_value,omitempty"` // Revenue of the conversions
+	ROASSource      string    `json:"roas_source,omitempty"`      // How ConversionValue was obtained, see ROASSourceReported
+	LastUpdated     time.Time `json:"last_updated"`
+}
+
+// How the conversion value, and so the ROAS, of a performance was obtained
+const (
+	ROASSourceReported  = "reported"  // Reported by Facebook in action_values or purchase_roas
+	ROASSourceEstimated = "estimated" // Conversions times the configured conversion value
+)
+
+// DefaultConversionValue is the value of a conversion used when none is configured
+const DefaultConversionValue = 50.0
+
+// ConversionValues are the values of one conversion, e.g. the average order value of a
+// store, used to estimate revenue when Facebook doesn't report it
+type ConversionValues struct {
+	Default   float64
+	Campaigns map[string]float64 // Overrides by campaign ID
+}
+
+// For returns the value of a conversion of a campaign
+func (v ConversionValues) For(campaignID string) float64 {
+	if value, ok := v.Campaigns[campaignID]; ok {
+		return value
+	}
+	return v.Default
+}
+
+// Apply sets the conversion value and ROAS of a performance. The value Facebook reported
+// is preferred; otherwise the conversions are valued at the campaign's conversion value.
+// Performances whose source is already set are left alone, so Apply can run repeatedly.
+func (v ConversionValues) Apply(perf *CampaignPerformance) {
+	if perf.ROASSource != "" {
+		return
+	}
+	if perf.ConversionValue == 0 && perf.ROAS > 0 {
+		// Statistics stored before the conversion value was kept only have the ROAS
+		perf.ConversionValue = perf.ROAS * perf.Spend
+	}
+
+	switch value := v.For(perf.CampaignID); {
+	case perf.ConversionValue > 0:
+		perf.ROASSource = ROASSourceReported
+	case perf.Conversions > 0 && value > 0:
+		perf.ConversionValue = float64(perf.Conversions) * value
+		perf.ROASSource = ROASSourceEstimated
+	default:
+		return
+	}
+	if perf.Spend > 0 {
+		perf.ROAS = perf.ConversionValue / perf.Spend
+	}
+}
+
+// Method describes for reports how the ROAS of performances was computed, after Apply.
+// It is empty when no performance has a conversion value.
+func (v ConversionValues) Method(performances []CampaignPerformance) string {
+	reported, estimated := false, false
+	overridden := false
+	for _, perf := range performances {
+		switch perf.ROASSource {
+		case ROASSourceReported:
+			reported = true
+		case ROASSourceEstimated:
+			estimated = true
+			_, ok := v.Campaigns[perf.CampaignID]
+			overridden = overridden || ok
+		}
+	}
+
+	estimate := fmt.Sprintf("estimated at $%.2f per conversion (conversion_value)", v.Default)
+	if overridden {
+		estimate = "estimated from the configured conversion values (conversion_value and campaign_conversion_values)"
+	}
+	switch {
+	case reported && estimated:
+		return "reported by Facebook where available, otherwise " + estimate
+	case reported:
+		return "reported by Facebook (action_values)"
+	case estimated:
+		return estimate
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConversionValues_Apply(t *testing.T) {
+	values := ConversionValues{Default: 180, Campaigns: map[string]float64{"222": 40}}
+
+	tests := []struct {
+		name   string
+		perf   CampaignPerformance
+		value  float64
+		roas   float64
+		source string
+	}{
+		{
+			name:   "reported value",
+			perf:   CampaignPerformance{CampaignID: "111", Spend: 100, Conversions: 2, ConversionValue: 500},
+			value:  500,
+			roas:   5,
+			source: ROASSourceReported,
+		},
+		{
+			name:   "estimated at the default",
+			perf:   CampaignPerformance{CampaignID: "111", Spend: 100, Conversions: 2},
+			value:  360,
+			roas:   3.6,
+			source: ROASSourceEstimated,
+		},
+		{
+			name:   "estimated at the campaign override",
+			perf:   CampaignPerformance{CampaignID: "222", Spend: 100, Conversions: 2},
+			value:  80,
+			roas:   0.8,
+			source: ROASSourceEstimated,
+		},
+		{
+			name:   "stored ROAS without a value",
+			perf:   CampaignPerformance{CampaignID: "111", Spend: 50, Conversions: 1, ROAS: 2},
+			value:  100,
+			roas:   2,
+			source: ROASSourceReported,
+		},
+		{
+			name: "no conversions",
+			perf: CampaignPerformance{CampaignID: "111", Spend: 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			perf := tt.perf
+			values.Apply(&perf)
+			values.Apply(&perf) // Applying again changes nothing
+
+			if perf.ConversionValue != tt.value || perf.ROAS != tt.roas || perf.ROASSource != tt.source {
+				t.Errorf("Expected value %v, ROAS %v from %q, got %v, %v from %q",
+					tt.value, tt.roas, tt.source, perf.ConversionValue, perf.ROAS, perf.ROASSource)
+			}
+		})
+	}
+}
+
+func TestConversionValues_Method(t *testing.T) {
+	values := ConversionValues{Default: 180}
+	reported := CampaignPerformance{ROASSource: ROASSourceReported}
+	estimated := CampaignPerformance{ROASSource: ROASSourceEstimated}
+
+	tests := []struct {
+		performances []CampaignPerformance
+		expected     string
+	}{
+		{[]CampaignPerformance{reported}, "reported by Facebook"},
+		{[]CampaignPerformance{estimated}, "estimated at $180.00 per conversion"},
+		{[]CampaignPerformance{reported, estimated}, "where available, otherwise estimated at $180.00"},
+	}
+
+	for _, tt := range tests {
+		if method := values.Method(tt.performances); !strings.Contains(method, tt.expected) {
+			t.Errorf("Expected the method to contain %q, got %q", tt.expected, method)
+		}
+	}
+	if method := values.Method([]CampaignPerformance{{}}); method != "" {
+		t.Errorf("Expected no method without conversion values, got %q", method)
+	}
+}
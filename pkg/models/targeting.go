@@ -0,0 +1,247 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Age bounds Facebook accepts in targeting
+const (
+	MinTargetingAge = 13
+	MaxTargetingAge = 65
+)
+
+// geoLocationKeys are the keys of geo_locations that select where ads are delivered;
+// at least one of them is required
+var geoLocationKeys = []string{"countries", "regions", "cities", "zips", "places", "custom_locations", "geo_markets", "electoral_districts", "country_groups", "subneighborhoods", "neighborhoods", "large_geo_areas", "medium_geo_areas", "small_geo_areas", "metro_areas"}
+
+// knownTargetingKeys are the targeting keys of the Marketing API. Others are reported as
+// warnings: Facebook adds keys over time, but a typo would otherwise only fail on creation.
+var knownTargetingKeys = map[string]bool{
+	"geo_locations": true, "excluded_geo_locations": true, "age_min": true, "age_max": true, "age_range": true,
+	"genders": true, "locales": true, "interests": true, "behaviors": true, "flexible_spec": true, "exclusions": true,
+	"custom_audiences": true, "excluded_custom_audiences": true, "connections": true, "excluded_connections": true,
+	"friends_of_connections": true, "publisher_platforms": true, "facebook_positions": true, "instagram_positions": true,
+	"audience_network_positions": true, "messenger_positions": true, "threads_positions": true, "device_platforms": true,
+	"user_os": true, "user_device": true, "excluded_user_device": true, "wireless_carrier": true,
+	"excluded_publisher_categories": true, "excluded_publisher_list_ids": true, "targeting_automation": true,
+	"targeting_optimization": true, "targeting_relaxation_types": true, "brand_safety_content_filter_levels": true,
+	"life_events": true, "industries": true, "income": true, "family_statuses": true, "education_statuses": true,
+	"education_schools": true, "education_majors": true, "college_years": true, "work_employers": true,
+	"work_positions": true, "relationship_statuses": true, "interested_in": true, "home_type": true,
+	"home_ownership": true, "home_value": true, "household_composition": true, "moms": true, "generation": true,
+	"politics": true, "ethnic_affinity": true, "net_worth": true, "office_type": true, "user_adclusters": true,
+	"app_install_state": true, "product_audience_specs": true, "excluded_product_audience_specs": true,
+	"contextual_targeting_categories": true, "instream_video_skippable_excluded": true,
+}
+
+// TargetingSpec is a targeting spec with the path it is reported at,
+// e.g. adsets[0].targeting
+type TargetingSpec struct {
+	Path      string
+	Targeting map[string]interface{}
+}
+
+// AdSetTargeting returns the targeting specs of the ad sets of a configuration
+func AdSetTargeting(config *CampaignConfig) []TargetingSpec {
+	specs := make([]TargetingSpec, len(config.AdSets))
+	for i, adSet := range config.AdSets {
+		specs[i] = TargetingSpec{Path: fmt.Sprintf("adsets[%d].targeting", i), Targeting: adSet.Targeting}
+	}
+	return specs
+}
+
+// TargetingInterest is an interest referenced by a targeting spec
+type TargetingInterest struct {
+	Path string // Path of the interest below the targeting, e.g. flexible_spec[0].interests[1]
+	ID   string
+}
+
+// ValidateTargeting checks the structure of an ad set targeting spec and returns its
+// problems, each at path followed by the targeting key, e.g. adsets[0].targeting.age_min.
+// Only local rules are checked; whether interest IDs exist is up to the API.
+func ValidateTargeting(path string, targeting map[string]interface{}) ValidationErrors {
+	var problems ValidationErrors
+	validateTargeting(&problems, path, targeting)
+	return problems
+}
+
+// validateTargeting adds the problems of a targeting spec to problems
+func validateTargeting(problems *ValidationErrors, path string, targeting map[string]interface{}) {
+	if len(targeting) == 0 {
+		problems.Add(path, "targeting is required")
+		return
+	}
+
+	keys := make([]string, 0, len(targeting))
+	for key := range targeting {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !knownTargetingKeys[key] {
+			problems.Warn(path+"."+key, "unknown targeting key %q; Facebook may reject it", key)
+		}
+	}
+
+	validateGeoLocations(problems, path+".geo_locations", targeting["geo_locations"])
+
+	ageMin, hasMin := validateAge(problems, path+".age_min", targeting["age_min"])
+	ageMax, hasMax := validateAge(problems, path+".age_max", targeting["age_max"])
+	if hasMin && hasMax && ageMin > ageMax {
+		problems.Add(path+".age_min", "age_min %d is greater than age_max %d", ageMin, ageMax)
+	}
+
+	if value, ok := targeting["genders"]; ok {
+		genders, isList := targetingList(value)
+		if !isList {
+			problems.Add(path+".genders", "genders must be a list of 1 (men) and 2 (women)")
+		}
+		for i, gender := range genders {
+			if number, ok := targetingNumber(gender); !ok || (number != 1 && number != 2) {
+				problems.Add(fmt.Sprintf("%s.genders[%d]", path, i), "gender must be 1 (men) or 2 (women), got %v", gender)
+			}
+		}
+	}
+
+	for _, key := range []string{"flexible_spec", "exclusions"} {
+		if value, ok := targeting[key]; ok {
+			if key == "flexible_spec" {
+				if _, isList := targetingList(value); !isList {
+					problems.Add(path+"."+key, "flexible_spec must be a list of objects")
+				}
+			} else if _, isMap := value.(map[string]interface{}); !isMap {
+				problems.Add(path+"."+key, "exclusions must be an object")
+			}
+		}
+	}
+
+	for _, interest := range targetingInterests(problems, path, targeting) {
+		if !isNumericID(interest.ID) {
+			problems.Add(path+"."+interest.Path, "interest ID %q is not numeric; look it up with 'fbads audience search'", interest.ID)
+		}
+	}
+}
+
+// validateGeoLocations checks that geo_locations selects at least one location
+func validateGeoLocations(problems *ValidationErrors, path string, value interface{}) {
+	if value == nil {
+		problems.Add(path, "geo_locations is required, e.g. {\"countries\": [\"US\"]}")
+		return
+	}
+	geo, ok := value.(map[string]interface{})
+	if !ok {
+		problems.Add(path, "geo_locations must be an object")
+		return
+	}
+	for _, key := range geoLocationKeys {
+		if list, isList := targetingList(geo[key]); isList && len(list) > 0 {
+			return
+		}
+	}
+	problems.Add(path, "geo_locations needs at least one of countries, regions, cities or zips")
+}
+
+// validateAge checks an age bound; ok is false when it is missing or invalid
+func validateAge(problems *ValidationErrors, path string, value interface{}) (age int, ok bool) {
+	if value == nil {
+		return 0, false
+	}
+	number, isNumber := targetingNumber(value)
+	if !isNumber || number != float64(int(number)) {
+		problems.Add(path, "age must be a whole number, got %v", value)
+		return 0, false
+	}
+	if number < MinTargetingAge || number > MaxTargetingAge {
+		problems.Add(path, "age must be between %d and %d, got %v", MinTargetingAge, MaxTargetingAge, number)
+		return 0, false
+	}
+	return int(number), true
+}
+
+// TargetingInterests returns the interests of a targeting spec: the top-level interests,
+// those of each flexible_spec entry and the excluded ones
+func TargetingInterests(targeting map[string]interface{}) []TargetingInterest {
+	var ignored ValidationErrors
+	return targetingInterests(&ignored, "", targeting)
+}
+
+// targetingInterests collects the interests of a targeting spec, adding an error for
+// each entry without an ID. Paths are relative to the targeting.
+func targetingInterests(problems *ValidationErrors, path string, targeting map[string]interface{}) []TargetingInterest {
+	var interests []TargetingInterest
+	collect := func(prefix string, value interface{}) {
+		entries, _ := targetingList(value)
+		for i, entry := range entries {
+			entryPath := fmt.Sprintf("%sinterests[%d]", prefix, i)
+			id := ""
+			switch v := entry.(type) {
+			case string:
+				id = v
+			case map[string]interface{}:
+				if s, ok := v["id"].(string); ok {
+					id = s
+				} else if n, ok := targetingNumber(v["id"]); ok {
+					id = strconv.FormatFloat(n, 'f', -1, 64)
+				}
+			}
+			if id == "" {
+				problems.Add(path+"."+entryPath, "interest needs an id")
+				continue
+			}
+			interests = append(interests, TargetingInterest{Path: entryPath, ID: id})
+		}
+	}
+
+	collect("", targeting["interests"])
+	specs, _ := targetingList(targeting["flexible_spec"])
+	for i, spec := range specs {
+		if spec, ok := spec.(map[string]interface{}); ok {
+			collect(fmt.Sprintf("flexible_spec[%d].", i), spec["interests"])
+		}
+	}
+	if exclusions, ok := targeting["exclusions"].(map[string]interface{}); ok {
+		collect("exclusions.", exclusions["interests"])
+	}
+	return interests
+}
+
+// targetingList returns the elements of a list in targeting. Targeting decoded from JSON
+// holds []interface{}, while targeting built in code may hold typed slices such as []string.
+func targetingList(value interface{}) ([]interface{}, bool) {
+	if list, ok := value.([]interface{}); ok {
+		return list, true
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		return nil, false
+	}
+	list := make([]interface{}, v.Len())
+	for i := range list {
+		list[i] = v.Index(i).Interface()
+	}
+	return list, true
+}
+
+// targetingNumber returns a number in targeting, which is float64 when decoded from JSON
+// and int when built in code
+func targetingNumber(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// isNumericID reports whether an ID consists of digits only
+func isNumericID(id string) bool {
+	return id != "" && strings.Trim(id, "0123456789") == ""
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateTargeting(t *testing.T) {
+	us := map[string]interface{}{"countries": []interface{}{"US"}}
+	running := map[string]interface{}{"id": "6003107902433", "name": "Running"}
+
+	tests := []struct {
+		name      string
+		targeting map[string]interface{}
+		paths     []string
+		warnings  []string
+	}{
+		{
+			name:      "valid",
+			targeting: map[string]interface{}{"geo_locations": us, "age_min": 18.0, "age_max": 65, "genders": []int{1, 2}},
+		},
+		{
+			name:      "empty",
+			targeting: map[string]interface{}{},
+			paths:     []string{"adsets[0].targeting"},
+		},
+		{
+			name:      "missing locations",
+			targeting: map[string]interface{}{"age_min": 18, "geo_locations": map[string]interface{}{"location_types": []string{"home"}}},
+			paths:     []string{"adsets[0].targeting.geo_locations"},
+		},
+		{
+			name:      "ages",
+			targeting: map[string]interface{}{"geo_locations": us, "age_min": 12, "age_max": 70.5},
+			paths:     []string{"adsets[0].targeting.age_min", "adsets[0].targeting.age_max"},
+		},
+		{
+			name:      "age_min above age_max",
+			targeting: map[string]interface{}{"geo_locations": us, "age_min": 40, "age_max": 30},
+			paths:     []string{"adsets[0].targeting.age_min"},
+		},
+		{
+			name:      "genders",
+			targeting: map[string]interface{}{"geo_locations": us, "genders": []interface{}{1.0, 3.0}},
+			paths:     []string{"adsets[0].targeting.genders[1]"},
+		},
+		{
+			name: "interests",
+			targeting: map[string]interface{}{
+				"geo_locations": us,
+				"flexible_spec": []interface{}{map[string]interface{}{"interests": []interface{}{running, map[string]interface{}{"id": "Running"}}}},
+				"exclusions":    map[string]interface{}{"interests": []interface{}{map[string]interface{}{"name": "Golf"}}},
+			},
+			paths: []string{"adsets[0].targeting.exclusions.interests[0]", "adsets[0].targeting.flexible_spec[0].interests[1]"},
+		},
+		{
+			name:      "unknown key",
+			targeting: map[string]interface{}{"geo_locations": us, "age_minimum": 18},
+			warnings:  []string{"adsets[0].targeting.age_minimum"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := ValidateTargeting("adsets[0].targeting", tt.targeting)
+
+			var paths, warnings []string
+			for _, problem := range problems.Errors() {
+				paths = append(paths, problem.Path)
+			}
+			for _, problem := range problems.Warnings() {
+				warnings = append(warnings, problem.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.paths) || !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("Expected problems at %v and warnings at %v, got %v", tt.paths, tt.warnings, problems)
+			}
+		})
+	}
+}
+
+func TestTargetingInterests(t *testing.T) {
+	targeting := map[string]interface{}{
+		"interests":     []interface{}{"6003107902433"},
+		"flexible_spec": []interface{}{map[string]interface{}{"interests": []interface{}{map[string]interface{}{"id": 6003139266461.0}}}},
+	}
+
+	expected := []TargetingInterest{
+		{Path: "interests[0]", ID: "6003107902433"},
+		{Path: "flexible_spec[0].interests[0]", ID: "6003139266461"},
+	}
+	if got := TargetingInterests(targeting); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
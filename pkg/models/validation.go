@@ -0,0 +1,367 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ValidationProblem is one problem found in a configuration
+type ValidationProblem struct {
+	Path    string `json:"path"` // Index-addressed field path, e.g. adsets[3].billing_event
+	Message string `json:"message"`
+	Warning bool   `json:"warning,omitempty"` // Warnings are reported but don't make the configuration invalid
+}
+
+func (p ValidationProblem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+// ValidationErrors collects every problem found in a configuration, in the order they were found
+type ValidationErrors []ValidationProblem
+
+// Add records an error at path
+func (e *ValidationErrors) Add(path, format string, args ...interface{}) {
+	*e = append(*e, ValidationProblem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Warn records a warning at path
+func (e *ValidationErrors) Warn(path, format string, args ...interface{}) {
+	*e = append(*e, ValidationProblem{Path: path, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+// Errors returns the problems that are not warnings
+func (e ValidationErrors) Errors() ValidationErrors {
+	var errs ValidationErrors
+	for _, problem := range e {
+		if !problem.Warning {
+			errs = append(errs, problem)
+		}
+	}
+	return errs
+}
+
+// Warnings returns the problems that are only warnings
+func (e ValidationErrors) Warnings() ValidationErrors {
+	var warnings ValidationErrors
+	for _, problem := range e {
+		if problem.Warning {
+			warnings = append(warnings, problem)
+		}
+	}
+	return warnings
+}
+
+// Err returns the errors as an error, or nil when there are only warnings
+func (e ValidationErrors) Err() error {
+	if errs := e.Errors(); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Error lists the problems as a numbered list
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].String()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d problems found:", len(e))
+	for i, problem := range e {
+		fmt.Fprintf(&b, "\n  %d. %s", i+1, problem)
+	}
+	return b.String()
+}
+
+// messagingObjectives lists the campaign objectives that can deliver to a messaging destination
+var messagingObjectives = map[Objective]bool{
+	ObjectiveEngagement: true,
+	ObjectiveSales:      true,
+}
+
+// configTimeLayouts are the formats accepted for start and end times: RFC 3339 and the
+// Graph API's own format without the colon in the offset
+var configTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05-0700"}
+
+// ParseConfigTime parses a start or end time of a configuration
+func ParseConfigTime(value string) (time.Time, error) {
+	for _, layout := range configTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (expected a time like 2025-06-01T00:00:00+02:00)", value)
+}
+
+// ValidateDestinationType checks that an ad set destination type is known and
+// compatible with the campaign objective
+func ValidateDestinationType(objective, destinationType string) error {
+	if destinationType == "" {
+		return nil
+	}
+
+	destination, err := ParseDestinationType(destinationType)
+	if err != nil {
+		return fmt.Errorf("unsupported destination type %q (expected %s, %s or %s)",
+			destinationType, DestinationWhatsApp, DestinationMessenger, DestinationInstagramDirect)
+	}
+
+	if parsed, _ := ParseObjective(NormalizeObjective(objective)); !messagingObjectives[parsed] {
+		return fmt.Errorf("destination type %s requires objective %s or %s, got %q",
+			destination, ObjectiveEngagement, ObjectiveSales, objective)
+	}
+
+	return nil
+}
+
+// ValidateCampaignConfig returns every problem in a campaign configuration, including
+// warnings, with the path of the field each one is about. It only looks at the
+// configuration itself; image files are checked by whoever uploads them.
+func ValidateCampaignConfig(config *CampaignConfig) ValidationErrors {
+	var problems ValidationErrors
+
+	if config.Name == "" {
+		problems.Add("name", "campaign name is required")
+	}
+
+	// Legacy objectives still work on older API versions, and are translated unless turned off
+	if config.Objective == "" {
+		problems.Add("objective", "campaign objective is required")
+	} else if objective, legacy := LegacyObjectives[strings.ToUpper(strings.TrimSpace(config.Objective))]; legacy {
+		problems.Warn("objective", "legacy objective %s is rejected by newer API versions and is sent as %s unless normalization is turned off", config.Objective, objective)
+	} else if _, err := ParseObjective(config.Objective); err != nil {
+		problems.Add("objective", "%v", err)
+	}
+
+	if config.BuyingType == "" {
+		problems.Add("buying_type", "campaign buying type is required")
+	} else if _, err := ParseBuyingType(config.BuyingType); err != nil {
+		problems.Add("buying_type", "%v", err)
+	}
+
+	switch {
+	case config.DailyBudget < 0:
+		problems.Add("daily_budget", "budget can't be negative")
+	case config.LifetimeBudget < 0:
+		problems.Add("lifetime_budget", "budget can't be negative")
+	case config.DailyBudget == 0 && config.LifetimeBudget == 0:
+		problems.Add("daily_budget", "either daily budget or lifetime budget is required")
+	case config.DailyBudget > 0 && config.LifetimeBudget > 0:
+		problems.Add("lifetime_budget", "set either a daily budget or a lifetime budget, not both")
+	}
+
+	if config.BidStrategy != "" {
+		if _, err := ParseBidStrategy(config.BidStrategy); err != nil {
+			problems.Add("bid_strategy", "%v", err)
+		}
+	}
+
+	// Unknown statuses are replaced with PAUSED when the campaign is created
+	if _, err := ParseCampaignStatus(config.Status); config.Status != "" && err != nil {
+		problems.Warn("status", "unknown status %q, PAUSED will be used", config.Status)
+	}
+
+	validateSchedule(&problems, "", config.StartTime, config.EndTime)
+
+	// Lifetime budgets are spent over a fixed period, set on the campaign or on each ad set
+	if config.LifetimeBudget > 0 {
+		if !hasSchedule(config.StartTime, config.AdSets, func(adSet AdSetConfig) string { return adSet.StartTime }) {
+			problems.Add("start_time", "a lifetime budget needs a start time on the campaign or on every ad set")
+		}
+		if !hasSchedule(config.EndTime, config.AdSets, func(adSet AdSetConfig) string { return adSet.EndTime }) {
+			problems.Add("end_time", "a lifetime budget needs an end time on the campaign or on every ad set")
+		}
+	}
+
+	if len(config.AdSets) == 0 {
+		problems.Add("adsets", "at least one ad set is required")
+	}
+
+	for i, adSet := range config.AdSets {
+		path := fmt.Sprintf("adsets[%d]", i)
+
+		if adSet.Name == "" {
+			problems.Add(path+".name", "name is required")
+		}
+
+		if adSet.OptimizationGoal == "" {
+			problems.Add(path+".optimization_goal", "optimization goal is required")
+		} else if _, err := ParseOptimizationGoal(adSet.OptimizationGoal); err != nil {
+			problems.Add(path+".optimization_goal", "%v", err)
+		}
+
+		if adSet.BillingEvent == "" {
+			problems.Add(path+".billing_event", "billing event is required")
+		} else if _, err := ParseBillingEvent(adSet.BillingEvent); err != nil {
+			problems.Add(path+".billing_event", "%v", err)
+		}
+
+		if adSet.BidAmount < 0 {
+			problems.Add(path+".bid_amount", "bid amount can't be negative")
+		}
+
+		validateTargeting(&problems, path+".targeting", adSet.Targeting)
+
+		// Click-to-message destinations only work with engagement and sales objectives
+		if err := ValidateDestinationType(config.Objective, adSet.DestinationType); err != nil {
+			problems.Add(path+".destination_type", "%v", err)
+		}
+
+		if _, err := ParseCampaignStatus(adSet.Status); adSet.Status != "" && err != nil {
+			problems.Warn(path+".status", "unknown status %q, PAUSED will be used", adSet.Status)
+		}
+
+		validateSchedule(&problems, path+".", adSet.StartTime, adSet.EndTime)
+
+		for j, ad := range adSet.Ads {
+			validateAdConfig(&problems, fmt.Sprintf("%s.ads[%d]", path, j), ad, adSet.DestinationType)
+		}
+	}
+
+	if len(config.AllAds()) == 0 {
+		problems.Add("ads", "at least one ad is required")
+	}
+
+	for i, ad := range config.Ads {
+		// Top-level ads are spread across the ad sets in turn
+		destination := ""
+		if len(config.AdSets) > 0 {
+			destination = config.AdSets[i%len(config.AdSets)].DestinationType
+		}
+		validateAdConfig(&problems, fmt.Sprintf("ads[%d]", i), ad, destination)
+	}
+
+	return problems
+}
+
+// validateAdConfig adds the problems of an ad to problems. The destination is the
+// destination type of the ad set the ad is created in.
+func validateAdConfig(problems *ValidationErrors, path string, ad AdConfig, destination string) {
+	creative := ad.Creative
+
+	if ad.Name == "" {
+		problems.Add(path+".name", "name is required")
+	}
+
+	if _, err := ParseCampaignStatus(ad.Status); ad.Status != "" && err != nil {
+		problems.Warn(path+".status", "unknown status %q, PAUSED will be used", ad.Status)
+	}
+
+	// A reused creative keeps its own content, so content next to its ID is ambiguous
+	if creative.CreativeID != "" {
+		if fields := inlineCreativeFields(creative); len(fields) > 0 {
+			problems.Add(path+".creative.creative_id", "creative_id reuses an existing creative and can't be combined with %s", strings.Join(fields, ", "))
+		}
+		return
+	}
+
+	// Creatives take their headline from title, or from name in configurations built from templates
+	if creative.Title == "" && creative.Name == "" {
+		problems.Add(path+".creative.title", "creative title/name is required")
+	}
+
+	// Click-to-message ads get a default link for their destination; video ads may have none
+	// and carousels use the link of their first card
+	_, err := ParseDestinationType(destination)
+	messaging := destination != "" && err == nil
+	carousel := len(creative.Cards) > 0
+	if creative.VideoID == "" && creative.LinkURL == "" && !messaging && !carousel {
+		problems.Add(path+".creative.link_url", "creative link URL or video_id is required")
+	}
+
+	if carousel {
+		if creative.VideoID != "" {
+			problems.Add(path+".creative.cards", "a creative can have a video_id or carousel cards, not both")
+		}
+		if n := len(creative.Cards); n < MinCarouselCards || n > MaxCarouselCards {
+			problems.Add(path+".creative.cards", "a carousel needs %d to %d cards, got %d", MinCarouselCards, MaxCarouselCards, n)
+		}
+		for i, card := range creative.Cards {
+			if card.LinkURL == "" {
+				problems.Add(fmt.Sprintf("%s.creative.cards[%d].link_url", path, i), "card link URL is required")
+			}
+		}
+	}
+
+	// Video ads need a thumbnail, and their link is the target of the call to action
+	if creative.VideoID != "" {
+		if creative.ImageURL == "" && creative.ImageHash == "" {
+			problems.Add(path+".creative.image_url", "video creatives need a thumbnail image_url or image_hash")
+		}
+		if creative.CallToAction != "" && creative.LinkURL == "" && !messaging {
+			problems.Add(path+".creative.link_url", "call_to_action %s of a video creative needs a link URL", creative.CallToAction)
+		}
+	}
+
+	if creative.PageID == "" {
+		problems.Add(path+".creative.page_id", "creative page_id is required")
+	}
+}
+
+// inlineCreativeFields returns the names of the content fields set on a creative
+func inlineCreativeFields(creative CreativeConfig) []string {
+	var fields []string
+	for _, field := range []struct {
+		name string
+		set  bool
+	}{
+		{"title", creative.Title != ""},
+		{"name", creative.Name != ""},
+		{"body", creative.Body != ""},
+		{"image_url", creative.ImageURL != ""},
+		{"image_hash", creative.ImageHash != ""},
+		{"video_id", creative.VideoID != ""},
+		{"cards", len(creative.Cards) > 0},
+		{"link_url", creative.LinkURL != ""},
+		{"call_to_action", creative.CallToAction != ""},
+		{"page_id", creative.PageID != ""},
+		{"whatsapp_number", creative.WhatsAppNumber != ""},
+		{"page_welcome_message", creative.PageWelcomeMessage != ""},
+	} {
+		if field.set {
+			fields = append(fields, field.name)
+		}
+	}
+	return fields
+}
+
+// validateSchedule adds the problems of a start and end time. Field paths start with prefix.
+func validateSchedule(problems *ValidationErrors, prefix, startTime, endTime string) {
+	var start, end time.Time
+	var err error
+
+	if startTime != "" {
+		if start, err = ParseConfigTime(startTime); err != nil {
+			problems.Add(prefix+"start_time", "%v", err)
+		}
+	}
+	if endTime != "" {
+		if end, err = ParseConfigTime(endTime); err != nil {
+			problems.Add(prefix+"end_time", "%v", err)
+		}
+	}
+
+	if !start.IsZero() && !end.IsZero() && !end.After(start) {
+		problems.Add(prefix+"end_time", "end time %s is not after start time %s", endTime, startTime)
+	}
+}
+
+// hasSchedule reports whether a time is set on the campaign or else on every ad set
+func hasSchedule(campaignTime string, adSets []AdSetConfig, adSetTime func(AdSetConfig) string) bool {
+	if campaignTime != "" {
+		return true
+	}
+	if len(adSets) == 0 {
+		return false
+	}
+	for _, adSet := range adSets {
+		if adSetTime(adSet) == "" {
+			return false
+		}
+	}
+	return true
+}
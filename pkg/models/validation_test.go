@@ -0,0 +1,129 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateCampaignConfig(t *testing.T) {
+	valid := func() *CampaignConfig {
+		return &CampaignConfig{
+			Name:        "Spring",
+			Objective:   "OUTCOME_TRAFFIC",
+			BuyingType:  "AUCTION",
+			DailyBudget: 50,
+			AdSets: []AdSetConfig{
+				{Name: "Broad", OptimizationGoal: "LINK_CLICKS", BillingEvent: "IMPRESSIONS", Targeting: map[string]interface{}{"age_min": 18, "geo_locations": map[string]interface{}{"countries": []string{"US"}}}},
+			},
+			Ads: []AdConfig{
+				{Name: "Ad", Creative: CreativeConfig{Title: "Sale", LinkURL: "https://example.com", PageID: "123"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*CampaignConfig)
+		paths  []string
+	}{
+		{
+			name:   "valid",
+			modify: func(c *CampaignConfig) {},
+		},
+		{
+			name: "unknown enum values",
+			modify: func(c *CampaignConfig) {
+				c.Objective = "SALES"
+				c.BuyingType = "FIXED"
+				c.BidStrategy = "CHEAPEST"
+				c.AdSets[0].OptimizationGoal = "CLICKS"
+				c.AdSets[0].BillingEvent = "VIEWS"
+			},
+			paths: []string{"objective", "buying_type", "bid_strategy", "adsets[0].optimization_goal", "adsets[0].billing_event"},
+		},
+		{
+			name: "legacy objective",
+			modify: func(c *CampaignConfig) {
+				c.Objective = "CONVERSIONS"
+			},
+		},
+		{
+			name: "lifetime budget without schedule",
+			modify: func(c *CampaignConfig) {
+				c.DailyBudget, c.LifetimeBudget = 0, 500
+			},
+			paths: []string{"start_time", "end_time"},
+		},
+		{
+			name: "lifetime budget scheduled on the ad sets",
+			modify: func(c *CampaignConfig) {
+				c.DailyBudget, c.LifetimeBudget = 0, 500
+				c.AdSets[0].StartTime = "2025-04-15T00:00:00+0000"
+				c.AdSets[0].EndTime = "2025-05-15T00:00:00+0000"
+			},
+		},
+		{
+			name: "lifetime budget with an unscheduled ad set",
+			modify: func(c *CampaignConfig) {
+				c.DailyBudget, c.LifetimeBudget = 0, 500
+				c.StartTime = "2025-04-15T00:00:00Z"
+				c.AdSets[0].EndTime = "2025-05-15T00:00:00Z"
+				c.AdSets = append(c.AdSets, c.AdSets[0])
+				c.AdSets[1].EndTime = ""
+			},
+			paths: []string{"end_time"},
+		},
+		{
+			name: "both budgets",
+			modify: func(c *CampaignConfig) {
+				c.LifetimeBudget = 500
+				c.StartTime, c.EndTime = "2025-04-15T00:00:00Z", "2025-05-15T00:00:00Z"
+			},
+			paths: []string{"lifetime_budget"},
+		},
+		{
+			name: "bad times",
+			modify: func(c *CampaignConfig) {
+				c.StartTime, c.EndTime = "2025-05-15T00:00:00Z", "2025-04-15T00:00:00Z"
+				c.AdSets[0].StartTime = "next monday"
+			},
+			paths: []string{"end_time", "adsets[0].start_time"},
+		},
+		{
+			name: "messaging destination",
+			modify: func(c *CampaignConfig) {
+				c.AdSets[0].DestinationType = "whatsapp"
+				c.Ads[0].Creative.LinkURL = ""
+			},
+			paths: []string{"adsets[0].destination_type"},
+		},
+		{
+			name: "reused creative",
+			modify: func(c *CampaignConfig) {
+				c.Ads[0].Creative = CreativeConfig{CreativeID: "301"}
+			},
+		},
+		{
+			name: "reused creative with inline content",
+			modify: func(c *CampaignConfig) {
+				c.Ads[0].Creative.CreativeID = "301"
+			},
+			paths: []string{"ads[0].creative.creative_id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid()
+			tt.modify(config)
+
+			var paths []string
+			for _, problem := range ValidateCampaignConfig(config).Errors() {
+				paths = append(paths, problem.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("Expected problems at %v, got %v", tt.paths, ValidateCampaignConfig(config))
+			}
+		})
+	}
+}
@@ -1,27 +1,38 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
+	"gopkg.in/yaml.v3"
 )
 
+// RuleMetricTypes are the metrics a deactivation rule can compare
+var RuleMetricTypes = []string{"CPA", "CTR", "ROAS", "CPC", "CPM"}
+
+// RuleOperators are the comparison operators a deactivation rule can use
+var RuleOperators = []string{">", "<", "=", ">=", "<="}
+
 // DeactivationRule represents a rule for deactivating campaigns
 type DeactivationRule struct {
-	ID                 string  `json:"id"`
-	Name               string  `json:"name"`
-	MetricType         string  `json:"metric_type"` // CPA, ROAS, CTR, etc.
-	Threshold          float64 `json:"threshold"`
-	ComparisonOperator string  `json:"comparison_operator"` // >, <, =, >=, <=
-	MinImpressions     int     `json:"min_impressions"`     // Minimum impressions before rule applies
-	MinSpend           float64 `json:"min_spend"`          // Minimum spend before rule applies
-	MinRuntime         int     `json:"min_runtime"`        // Minimum hours campaign should run before rule applies
+	ID                 string  `json:"id" yaml:"id"`
+	Name               string  `json:"name" yaml:"name"`
+	MetricType         string  `json:"metric_type" yaml:"metric_type"` // CPA, ROAS, CTR, etc.
+	Threshold          float64 `json:"threshold" yaml:"threshold"`
+	ComparisonOperator string  `json:"comparison_operator" yaml:"comparison_operator"` // >, <, =, >=, <=
+	MinImpressions     int     `json:"min_impressions" yaml:"min_impressions"`         // Minimum impressions before rule applies
+	MinSpend           float64 `json:"min_spend" yaml:"min_spend"`                     // Minimum spend before rule applies
+	MinRuntime         int     `json:"min_runtime" yaml:"min_runtime"`                 // Minimum hours campaign should run before rule applies
 }
 
 // DeactivationEvent represents a campaign deactivation event
@@ -31,6 +42,8 @@ type DeactivationEvent struct {
 	Name        string    `json:"name"`
 	RuleID      string    `json:"rule_id"`
 	RuleName    string    `json:"rule_name"`
+	MetricType  string    `json:"metric_type,omitempty"`
+	Operator    string    `json:"operator,omitempty"`
 	MetricValue float64   `json:"metric_value"`
 	Threshold   float64   `json:"threshold"`
 	Timestamp   time.Time `json:"timestamp"`
@@ -42,24 +55,102 @@ type Deactivator struct {
 	auth       *auth.FacebookAuth
 	accountID  string
 	rules      []DeactivationRule
+	values     models.ConversionValues
 }
 
 // NewDeactivator creates a new campaign deactivator
 func NewDeactivator(auth *auth.FacebookAuth, accountID string) *Deactivator {
 	return &Deactivator{
-		httpClient: &http.Client{},
+		httpClient: auth.HTTPClient(),
 		auth:       auth,
 		accountID:  accountID,
 		rules:      defaultRules(),
+		values:     models.ConversionValues{Default: models.DefaultConversionValue},
 	}
 }
 
-// LoadRules loads deactivation rules from a file
+// SetConversionValues sets the values of a conversion the ROAS rules use when Facebook
+// doesn't report conversion values
+func (d *Deactivator) SetConversionValues(values models.ConversionValues) {
+	d.values = values
+}
+
+// LoadRules replaces the rules with the list in a JSON or YAML file (by extension).
+// The rules are validated first, so a bad file leaves the current rules in place.
 func (d *Deactivator) LoadRules(filePath string) error {
-	// TODO: Implement rule loading from a configuration file
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading rules file: %w", err)
+	}
+
+	var rules []DeactivationRule
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &rules)
+	default:
+		err = json.Unmarshal(data, &rules)
+	}
+	if err != nil {
+		return fmt.Errorf("error parsing rules file %s: %w", filePath, err)
+	}
+
+	if err := ValidateRules(rules); err != nil {
+		return fmt.Errorf("invalid rules in %s: %w", filePath, err)
+	}
+
+	d.rules = rules
+	return nil
+}
+
+// Rules returns the rules campaigns are checked against
+func (d *Deactivator) Rules() []DeactivationRule {
+	return d.rules
+}
+
+// ValidateRules checks that every rule has a known metric type and operator, non-negative
+// thresholds and minimums, and a unique ID. Metric types are upper-cased and missing IDs
+// are numbered like the default rules.
+func ValidateRules(rules []DeactivationRule) error {
+	if len(rules) == 0 {
+		return fmt.Errorf("no rules defined")
+	}
+
+	seen := make(map[string]bool)
+	for i := range rules {
+		rule := &rules[i]
+		if rule.ID == "" {
+			rule.ID = fmt.Sprintf("rule%d", i+1)
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("duplicate rule ID %q", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		rule.MetricType = strings.ToUpper(strings.TrimSpace(rule.MetricType))
+		if !contains(RuleMetricTypes, rule.MetricType) {
+			return fmt.Errorf("rule %s: unknown metric type %q (use %s)", rule.ID, rule.MetricType, strings.Join(RuleMetricTypes, ", "))
+		}
+		if !contains(RuleOperators, rule.ComparisonOperator) {
+			return fmt.Errorf("rule %s: unknown operator %q (use %s)", rule.ID, rule.ComparisonOperator, strings.Join(RuleOperators, " "))
+		}
+		if rule.Threshold < 0 || rule.MinImpressions < 0 || rule.MinSpend < 0 || rule.MinRuntime < 0 {
+			return fmt.Errorf("rule %s: threshold and minimums cannot be negative", rule.ID)
+		}
+	}
+
 	return nil
 }
 
+// contains reports whether values holds value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // defaultRules returns a set of default deactivation rules
 func defaultRules() []DeactivationRule {
 	return []DeactivationRule{
@@ -96,7 +187,8 @@ func defaultRules() []DeactivationRule {
 	}
 }
 
-// CheckCampaigns checks all campaigns against deactivation rules
+// CheckCampaigns checks all campaigns against deactivation rules and pauses the ones
+// that trigger a rule
 func (d *Deactivator) CheckCampaigns() ([]DeactivationEvent, error) {
 	// Get campaign performance data
 	optimizer := NewOptimizer(d.auth, d.accountID, 10.0) // Target CPA doesn't matter here
@@ -104,83 +196,145 @@ func (d *Deactivator) CheckCampaigns() ([]DeactivationEvent, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error getting campaign performances: %w", err)
 	}
-	
+
+	events := d.CheckPerformances(performances, nil, time.Now())
+	for _, event := range events {
+		// Deactivate the campaign
+		if err := d.DeactivateCampaign(event.CampaignID); err != nil {
+			log.Printf("Error deactivating campaign %s: %v", event.CampaignID, err)
+		}
+	}
+
+	return events, nil
+}
+
+// CheckPerformances checks campaign performances against the rules without changing anything.
+// A campaign triggers at most one rule, the first in order. Runtime is measured from the
+// campaign's start time, or from LastUpdated for campaigns missing from startTimes.
+func (d *Deactivator) CheckPerformances(performances []CampaignPerformance, startTimes map[string]time.Time, now time.Time) []DeactivationEvent {
 	var events []DeactivationEvent
-	
+
 	for _, perf := range performances {
+		// ROAS rules judge reported revenue, or conversions valued at the conversion value
+		d.values.Apply(&perf)
+
+		started, ok := startTimes[perf.CampaignID]
+		if !ok {
+			started = perf.LastUpdated
+		}
+		runtimeHours := now.Sub(started).Hours()
+
 		// Check each rule
 		for _, rule := range d.rules {
 			// Skip if minimum requirements not met
 			if perf.Impressions < rule.MinImpressions || perf.Spend < rule.MinSpend {
 				continue
 			}
-			
+
 			// Check campaign runtime
-			campaignAge := time.Since(perf.LastUpdated).Hours()
-			if int(campaignAge) < rule.MinRuntime {
+			if int(runtimeHours) < rule.MinRuntime {
 				continue
 			}
-			
-			// Get metric value based on rule type
-			var metricValue float64
-			switch rule.MetricType {
-			case "CPA":
-				if perf.Conversions == 0 {
-					continue // Skip if no conversions
-				}
-				metricValue = perf.Spend / float64(perf.Conversions)
-			case "CTR":
-				if perf.Impressions == 0 {
-					continue // Skip if no impressions
-				}
-				metricValue = float64(perf.Clicks) / float64(perf.Impressions) * 100
-			case "ROAS":
-				if perf.Spend == 0 {
-					continue // Skip if no spend
-				}
-				metricValue = perf.ROAS
-			default:
-				continue // Skip unknown metric types
-			}
-			
-			// Check if rule is triggered
-			ruleTriggered := false
-			switch rule.ComparisonOperator {
-			case ">":
-				ruleTriggered = metricValue > rule.Threshold
-			case "<":
-				ruleTriggered = metricValue < rule.Threshold
-			case "=":
-				ruleTriggered = metricValue == rule.Threshold
-			case ">=":
-				ruleTriggered = metricValue >= rule.Threshold
-			case "<=":
-				ruleTriggered = metricValue <= rule.Threshold
+
+			metricValue, ok := ruleMetric(rule.MetricType, perf)
+			if !ok {
+				continue
 			}
-			
-			if ruleTriggered {
+
+			if compareMetric(metricValue, rule.ComparisonOperator, rule.Threshold) {
 				events = append(events, DeactivationEvent{
 					CampaignID:  perf.CampaignID,
 					Name:        perf.Name,
 					RuleID:      rule.ID,
 					RuleName:    rule.Name,
+					MetricType:  rule.MetricType,
+					Operator:    rule.ComparisonOperator,
 					MetricValue: metricValue,
 					Threshold:   rule.Threshold,
-					Timestamp:   time.Now(),
+					Timestamp:   now,
 				})
-				
-				// Deactivate the campaign
-				if err := d.DeactivateCampaign(perf.CampaignID); err != nil {
-					log.Printf("Error deactivating campaign %s: %v", perf.CampaignID, err)
-				}
-				
+
 				// Break after first triggered rule
 				break
 			}
 		}
 	}
-	
-	return events, nil
+
+	return events
+}
+
+// ruleMetric returns the value of a metric for a campaign, and false when the campaign has
+// no data to compute it from
+func ruleMetric(metricType string, perf CampaignPerformance) (float64, bool) {
+	switch metricType {
+	case "CPA":
+		if perf.Conversions == 0 {
+			return 0, false
+		}
+		return perf.Spend / float64(perf.Conversions), true
+	case "CTR":
+		if perf.Impressions == 0 {
+			return 0, false
+		}
+		return float64(perf.Clicks) / float64(perf.Impressions) * 100, true
+	case "ROAS":
+		if perf.Spend == 0 {
+			return 0, false
+		}
+		return perf.ROAS, true
+	case "CPC":
+		if perf.Clicks == 0 {
+			return 0, false
+		}
+		return perf.Spend / float64(perf.Clicks), true
+	case "CPM":
+		if perf.Impressions == 0 {
+			return 0, false
+		}
+		return perf.Spend / float64(perf.Impressions) * 1000, true
+	default:
+		return 0, false
+	}
+}
+
+// compareMetric applies a rule's comparison operator
+func compareMetric(value float64, operator string, threshold float64) bool {
+	switch operator {
+	case ">":
+		return value > threshold
+	case "<":
+		return value < threshold
+	case "=":
+		return value == threshold
+	case ">=":
+		return value >= threshold
+	case "<=":
+		return value <= threshold
+	default:
+		return false
+	}
+}
+
+// AppendDeactivationLog appends events to a log file, one JSON object per line
+func AppendDeactivationLog(path string, events []DeactivationEvent) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening deactivation log: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("error writing deactivation log: %w", err)
+		}
+	}
+
+	return nil
 }
 
 // DeactivateCampaign deactivates a campaign by setting its status to PAUSED
@@ -214,7 +368,7 @@ func (d *Deactivator) DeactivateCampaign(campaignID string) error {
 	// Check for errors
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+		return fmt.Errorf("API error: %w", auth.ParseAPIError(resp.StatusCode, body))
 	}
 	
 	return nil
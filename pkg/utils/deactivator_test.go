@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/fb-ads/pkg/auth"
+)
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "rules.yaml")
+	yamlRules := `
+- id: high-cpc
+  name: High CPC
+  metric_type: cpc
+  comparison_operator: ">="
+  threshold: 2.5
+  min_impressions: 500
+- name: Low CTR
+  metric_type: CTR
+  comparison_operator: "<"
+  threshold: 0.4
+`
+	if err := os.WriteFile(yamlPath, []byte(yamlRules), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deactivator := NewDeactivator(auth.NewFacebookAuth("app", "secret", "token", "v22.0"), "123")
+	if err := deactivator.LoadRules(yamlPath); err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+	rules := deactivator.Rules()
+	if len(rules) != 2 || rules[0].MetricType != "CPC" || rules[0].MinImpressions != 500 || rules[1].ID != "rule2" {
+		t.Errorf("Unexpected rules: %+v", rules)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		errText string
+	}{
+		{"unknown metric", `[{"id":"a","metric_type":"CPV","comparison_operator":">","threshold":1}]`, "unknown metric type"},
+		{"unknown operator", `[{"id":"a","metric_type":"CPA","comparison_operator":"!=","threshold":1}]`, "unknown operator"},
+		{"negative minimum", `[{"id":"a","metric_type":"CPA","comparison_operator":">","threshold":1,"min_spend":-5}]`, "cannot be negative"},
+		{"duplicate ID", `[{"id":"a","metric_type":"CPA","comparison_operator":">"},{"id":"a","metric_type":"CTR","comparison_operator":"<"}]`, "duplicate rule ID"},
+		{"empty", `[]`, "no rules"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, "bad.json")
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		err := deactivator.LoadRules(path)
+		if err == nil || !strings.Contains(err.Error(), tt.errText) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.errText, err)
+		}
+	}
+	if len(deactivator.Rules()) != 2 {
+		t.Errorf("Expected invalid files to leave the loaded rules in place")
+	}
+}
+
+func TestCheckPerformances(t *testing.T) {
+	deactivator := NewDeactivator(auth.NewFacebookAuth("app", "secret", "token", "v22.0"), "123")
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	performances := []CampaignPerformance{
+		// CPA of $30 after 5 days: triggers the high CPA rule
+		{CampaignID: "1", Name: "Expensive", Impressions: 5000, Clicks: 100, Spend: 60, Conversions: 2, ROAS: 3},
+		// Same numbers but started 12 hours ago: too young for the rule
+		{CampaignID: "2", Name: "New", Impressions: 5000, Clicks: 100, Spend: 60, Conversions: 2, ROAS: 3},
+		// Healthy campaign
+		{CampaignID: "3", Name: "Healthy", Impressions: 5000, Clicks: 100, Spend: 60, Conversions: 6, ROAS: 3},
+	}
+	startTimes := map[string]time.Time{
+		"1": now.AddDate(0, 0, -5),
+		"2": now.Add(-12 * time.Hour),
+		"3": now.AddDate(0, 0, -5),
+	}
+
+	events := deactivator.CheckPerformances(performances, startTimes, now)
+	if len(events) != 1 {
+		t.Fatalf("Expected one event, got %+v", events)
+	}
+	event := events[0]
+	if event.CampaignID != "1" || event.RuleID != "rule1" || event.MetricType != "CPA" || event.MetricValue != 30 || event.Threshold != 20 {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+}
+
+func TestAppendDeactivationLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "deactivations.log")
+	for _, id := range []string{"1", "2"} {
+		if err := AppendDeactivationLog(path, []DeactivationEvent{{CampaignID: id, RuleID: "rule1"}}); err != nil {
+			t.Fatalf("AppendDeactivationLog failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one line per event, got:\n%s", data)
+	}
+	var event DeactivationEvent
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil || event.CampaignID != "2" {
+		t.Errorf("Expected the second event on the second line, got %q (%v)", lines[1], err)
+	}
+}
@@ -7,23 +7,12 @@ import (
 	"time"
 
 	"github.com/user/fb-ads/pkg/auth"
+	"github.com/user/fb-ads/pkg/models"
 )
 
-// CampaignPerformance contains performance metrics for a campaign
-type CampaignPerformance struct {
-	CampaignID    string  `json:"campaign_id"`
-	Name          string  `json:"name"`
-	Spend         float64 `json:"spend"`
-	Impressions   int     `json:"impressions"`
-	Clicks        int     `json:"clicks"`
-	Conversions   int     `json:"conversions"`
-	CPC           float64 `json:"cpc"`
-	CPM           float64 `json:"cpm"`
-	CTR           float64 `json:"ctr"`
-	CPA           float64 `json:"cpa"`
-	ROAS          float64 `json:"roas"`
-	LastUpdated   time.Time `json:"last_updated"`
-}
+// CampaignPerformance contains performance metrics for a campaign.
+// It is an alias of models.CampaignPerformance, kept so existing callers compile unchanged.
+type CampaignPerformance = models.CampaignPerformance
 
 // BidAdjustment contains information about a bid adjustment
 type BidAdjustment struct {
@@ -50,7 +39,7 @@ type Optimizer struct {
 // NewOptimizer creates a new campaign optimizer
 func NewOptimizer(auth *auth.FacebookAuth, accountID string, targetCPA float64) *Optimizer {
 	return &Optimizer{
-		httpClient:      &http.Client{},
+		httpClient:      auth.HTTPClient(),
 		auth:            auth,
 		accountID:       accountID,
 		targetCPA:       targetCPA,